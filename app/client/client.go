@@ -0,0 +1,282 @@
+// Package client is the typed client of the REST API of the server, openapi.json is the description of the API,
+// which is generated from the server by go generate, so the contract of the client is checked by its tests
+package client
+
+//go:generate go run .. openapi --api-version v1 --out openapi.json
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// export formats
+const (
+	FormatCSV  = "csv"  // the solution as csv, GET /api/v1/solve.csv
+	FormatXLSX = "xlsx" // the workbook of errors and orders of methods, GET /api/export.xlsx
+)
+
+// Point is the point of the solution
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// SolveRequest describes the initial value problem to solve, fields are the ones of the SolveRequest schema
+type SolveRequest struct {
+	F           string             `json:"f,omitempty"`     // f(x,y) = y'
+	Exact       string             `json:"exact,omitempty"` // y(x,c), the exact solution
+	C           string             `json:"c,omitempty"`     // C(x0,y0), the constant for the exact solution
+	Params      map[string]float64 `json:"params,omitempty"`
+	X0          float64            `json:"x0"`
+	Y0          float64            `json:"y0"`
+	XEnd        float64            `json:"x_end"`
+	N           int                `json:"n,omitempty"`    // number of steps
+	Step        float64            `json:"step,omitempty"` // step size, used if n is not set
+	NByMethod   map[string]int     `json:"n_by_method,omitempty"`
+	Methods     []string           `json:"methods"`
+	Save        bool               `json:"save,omitempty"` // save the result to share it by id
+	Sensitivity bool               `json:"sensitivity,omitempty"`
+	DFDY        string             `json:"dfdy,omitempty"` // df/dy(x,y) for the sensitivity
+	Estimates   bool               `json:"estimates,omitempty"`
+	Errors      bool               `json:"errors,omitempty"` // local errors of methods against the exact solution
+	Preset      string             `json:"preset,omitempty"` // id of the built-in problem
+	OutputGrid  *OutputGrid        `json:"output_grid,omitempty"`
+}
+
+// OutputGrid gives lines at its x instead of points of internal steps of methods,
+// either Points or X is set
+type OutputGrid struct {
+	Points int       `json:"points,omitempty"` // number of evenly spaced x from x0 to x_end
+	X      []float64 `json:"x,omitempty"`      // x in the direction from x0 to x_end
+	Interp string    `json:"interp,omitempty"` // linear, cubic or hermite, cubic if empty
+}
+
+// SolveResponse describes the solutions of the initial value problem
+type SolveResponse struct {
+	Step        float64      `json:"step"`
+	GridsDiffer bool         `json:"grids_differ,omitempty"`
+	Lines       []Line       `json:"lines"`
+	Exact       *Line        `json:"exact,omitempty"`
+	Took        string       `json:"took"`
+	ID          string       `json:"id,omitempty"` // id of the saved result
+	Errors      []ErrorTable `json:"errors,omitempty"`
+}
+
+// Line is the solution by the method, the error is set, if the method failed
+type Line struct {
+	Method      string  `json:"method"`
+	Name        string  `json:"name"`
+	Step        float64 `json:"step,omitempty"`
+	Points      []Point `json:"points"`
+	Sensitivity []Point `json:"sensitivity,omitempty"`
+	Estimates   []Point `json:"estimates,omitempty"`
+	Stats       *Stats  `json:"stats,omitempty"`
+	Error       *Error  `json:"error,omitempty"`
+	Took        string  `json:"took"`
+}
+
+// Stats summarizes the solution by the method
+type Stats struct {
+	Points   int      `json:"points"`
+	Min      *Point   `json:"min,omitempty"`
+	Max      *Point   `json:"max,omitempty"`
+	Final    *Point   `json:"final,omitempty"`
+	Evals    int      `json:"evals"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ErrorTable is the local errors of the method against the exact solution
+type ErrorTable struct {
+	Method string  `json:"method"`
+	Name   string  `json:"name"`
+	Points []Point `json:"points"`
+}
+
+// Preset is the built-in problem with the request, that solves it at the endpoint
+type Preset struct {
+	ID          string          `json:"id"`
+	Description string          `json:"description"`
+	Endpoint    string          `json:"endpoint"`
+	Request     json.RawMessage `json:"request"` // SolveRequest for /api/v1/solve
+}
+
+// FieldError is the invalid field of the request
+type FieldError struct {
+	Field  string `json:"field"`
+	Msg    string `json:"msg"`
+	Column int    `json:"column,omitempty"`
+}
+
+// Error is the error response of the server, it is returned by methods of the client as error
+type Error struct {
+	Status  int          `json:"-"` // http status of the response
+	Code    int          `json:"code"`
+	Details string       `json:"details"`
+	Err     string       `json:"error"`
+	Errors  []FieldError `json:"errors,omitempty"` // invalid fields of the request, if any
+}
+
+// Error implements error interface
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s, status %d, code %d", e.Details, e.Err, e.Status, e.Code)
+}
+
+// Client calls the REST API of the server
+type Client struct {
+	BaseURL string       // url of the server, e.g. http://localhost:8080
+	HTTP    *http.Client // http.DefaultClient, if nil
+	APIKey  string       // sent as the bearer token, if set
+}
+
+// Solve solves the problem by POST /api/v1/solve
+func (c *Client) Solve(ctx context.Context, req SolveRequest) (SolveResponse, error) {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return SolveResponse{}, errors.Wrap(err, "failed to marshal request")
+	}
+	res := SolveResponse{}
+	if err = c.call(ctx, http.MethodPost, "/api/v1/solve", bytes.NewReader(b), &res); err != nil {
+		return SolveResponse{}, err
+	}
+	return res, nil
+}
+
+// Presets returns built-in problems by GET /api/presets
+func (c *Client) Presets(ctx context.Context) ([]Preset, error) {
+	var res []Preset
+	if err := c.call(ctx, http.MethodGet, "/api/presets", nil, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Export solves the problem and returns the file in the format, csv or xlsx
+func (c *Client) Export(ctx context.Context, req SolveRequest, format string) ([]byte, error) {
+	var path string
+	switch format {
+	case FormatCSV:
+		path = "/api/v1/solve.csv"
+	case FormatXLSX:
+		path = "/api/export.xlsx"
+	default:
+		return nil, errors.Errorf("unknown export format %q, only %s and %s are supported", format, FormatCSV, FormatXLSX)
+	}
+	buf := &bytes.Buffer{}
+	if err := c.call(ctx, http.MethodGet, path+"?"+solveQuery(req), nil, buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// call sends the request and decodes the json response to dst, or copies it, if dst is the buffer
+func (c *Client) call(ctx context.Context, method, path string, body io.Reader, dst interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.BaseURL, "/")+path, body)
+	if err != nil {
+		return errors.Wrap(err, "failed to make request")
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+	cl := c.HTTP
+	if cl == nil {
+		cl = http.DefaultClient
+	}
+	resp, err := cl.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to call %s %s", method, path)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := ioutil.ReadAll(resp.Body)
+		e := &Error{}
+		if err = json.Unmarshal(b, e); err != nil {
+			e = &Error{Details: "unexpected response", Err: strings.TrimSpace(string(b))}
+		}
+		e.Status = resp.StatusCode
+		return e
+	}
+	if buf, ok := dst.(*bytes.Buffer); ok {
+		_, err = buf.ReadFrom(resp.Body)
+		return errors.Wrap(err, "failed to read response")
+	}
+	return errors.Wrap(json.NewDecoder(resp.Body).Decode(dst), "failed to decode response")
+}
+
+// solveQuery encodes the request as query parameters of GET solve requests, spaces are escaped as %20,
+// as the server keeps the plus sign of formulas as is
+func solveQuery(req SolveRequest) string {
+	var parts []string
+	add := func(name, v string) {
+		parts = append(parts, name+"="+strings.ReplaceAll(url.QueryEscape(v), "+", "%20"))
+	}
+	num := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	for _, kv := range []struct{ name, v string }{{"f", req.F}, {"exact", req.Exact}, {"c", req.C},
+		{"dfdy", req.DFDY}, {"preset", req.Preset}} {
+		if kv.v != "" {
+			add(kv.name, kv.v)
+		}
+	}
+	add("x0", num(req.X0))
+	add("y0", num(req.Y0))
+	add("x1", num(req.XEnd))
+	if req.N != 0 {
+		add("n", strconv.Itoa(req.N))
+	}
+	if req.Step != 0 {
+		add("step", num(req.Step))
+	}
+	params := make([]string, 0, len(req.Params))
+	for name := range req.Params {
+		params = append(params, name)
+	}
+	sort.Strings(params)
+	for _, name := range params {
+		add("param", name+":"+num(req.Params[name]))
+	}
+	methods := make([]string, 0, len(req.NByMethod))
+	for m := range req.NByMethod {
+		methods = append(methods, m)
+	}
+	sort.Strings(methods)
+	for _, m := range methods {
+		add("n_by_method", m+":"+strconv.Itoa(req.NByMethod[m]))
+	}
+	for _, kv := range []struct {
+		name string
+		v    bool
+	}{{"sensitivity", req.Sensitivity}, {"estimates", req.Estimates}, {"errors", req.Errors}} {
+		if kv.v {
+			add(kv.name, "true")
+		}
+	}
+	if og := req.OutputGrid; og != nil {
+		if og.Points != 0 {
+			add("output_points", strconv.Itoa(og.Points))
+		}
+		for _, x := range og.X {
+			add("output_x", num(x))
+		}
+		if og.Interp != "" {
+			add("output_interp", og.Interp)
+		}
+	}
+	for _, m := range req.Methods {
+		add("method", m)
+	}
+	return strings.Join(parts, "&")
+}
@@ -0,0 +1,179 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/rest/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPI_Generated(t *testing.T) {
+	b, err := ioutil.ReadFile("openapi.json")
+	require.NoError(t, err)
+	buf := &bytes.Buffer{}
+	require.NoError(t, api.WriteOpenAPI(buf, "v1"))
+	assert.Equal(t, buf.String(), string(b), "openapi.json is outdated, run go generate ./client")
+}
+
+func TestOpenAPI_Contract(t *testing.T) {
+	b, err := ioutil.ReadFile("openapi.json")
+	require.NoError(t, err)
+	type schema struct {
+		Properties map[string]schema `json:"properties"`
+	}
+	doc := struct {
+		Paths      map[string]map[string]interface{} `json:"paths"`
+		Components struct {
+			Schemas map[string]schema `json:"schemas"`
+		} `json:"components"`
+	}{}
+	require.NoError(t, json.Unmarshal(b, &doc))
+
+	assert.Contains(t, doc.Paths["/api/v1/solve"], "post")
+	assert.Contains(t, doc.Paths["/api/presets"], "get")
+	assert.Contains(t, doc.Paths["/api/v1/solve.csv"], "get")
+	assert.Contains(t, doc.Paths["/api/export.xlsx"], "get")
+
+	schemas := doc.Components.Schemas
+	for _, tt := range []struct {
+		v      interface{}
+		schema schema
+	}{
+		{SolveRequest{}, schemas["SolveRequest"]},
+		{SolveResponse{}, schemas["SolveResponse"]},
+		{Line{}, schemas["Line"]},
+		{Stats{}, schemas["Line"].Properties["stats"]},
+		{OutputGrid{}, schemas["SolveRequest"].Properties["output_grid"]},
+		{ErrorTable{}, schemas["ErrorTable"]},
+		{Point{}, schemas["Point"]},
+		{Preset{}, schemas["Preset"]},
+		{Error{}, schemas["Error"]},
+		{FieldError{}, schemas["FieldError"]},
+	} {
+		typ := reflect.TypeOf(tt.v)
+		require.NotEmpty(t, tt.schema.Properties, typ.Name())
+		for i := 0; i < typ.NumField(); i++ {
+			name := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+			if name == "-" {
+				continue
+			}
+			assert.Contains(t, tt.schema.Properties, name, "%s.%s is not in the api", typ.Name(), typ.Field(i).Name)
+		}
+	}
+}
+
+func TestClient_Solve(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v1/solve", r.URL.Path)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		req := SolveRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, SolveRequest{F: "x + y", X0: 0, Y0: 1, XEnd: 1, N: 2, Methods: []string{"euler"}}, req)
+		_, _ = w.Write([]byte(`{"step": 0.5, "lines": [{"method": "euler", "name": "Euler's method",
+			"points": [{"x": 0, "y": 1}, {"x": 0.5, "y": 1.5}, {"x": 1, "y": 2.5}],
+			"stats": {"points": 3, "evals": 2, "final": {"x": 1, "y": 2.5}}, "took": "1ms"}], "took": "2ms"}`))
+	}))
+	defer ts.Close()
+
+	c := Client{BaseURL: ts.URL + "/", APIKey: "secret"}
+	resp, err := c.Solve(context.Background(), SolveRequest{F: "x + y", X0: 0, Y0: 1, XEnd: 1, N: 2,
+		Methods: []string{"euler"}})
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, resp.Step)
+	require.Len(t, resp.Lines, 1)
+	assert.Equal(t, []Point{{0, 1}, {0.5, 1.5}, {1, 2.5}}, resp.Lines[0].Points)
+	require.NotNil(t, resp.Lines[0].Stats)
+	assert.Equal(t, &Point{X: 1, Y: 2.5}, resp.Lines[0].Stats.Final)
+}
+
+func TestClient_Presets(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/presets", r.URL.Path)
+		_, _ = w.Write([]byte(`[{"id": "canonical", "description": "y' = y", "endpoint": "/api/v1/solve",
+			"request": {"f": "y", "x0": 0, "y0": 1, "x_end": 1, "methods": []}}]`))
+	}))
+	defer ts.Close()
+
+	presets, err := (&Client{BaseURL: ts.URL}).Presets(context.Background())
+	require.NoError(t, err)
+	require.Len(t, presets, 1)
+	assert.Equal(t, "canonical", presets[0].ID)
+	req := SolveRequest{}
+	require.NoError(t, json.Unmarshal(presets[0].Request, &req))
+	assert.Equal(t, SolveRequest{F: "y", XEnd: 1, Y0: 1, Methods: []string{}}, req)
+}
+
+func TestClient_Export(t *testing.T) {
+	var query string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		switch r.URL.Path {
+		case "/api/v1/solve.csv":
+			_, _ = w.Write([]byte("x,euler\n0,1\n"))
+		case "/api/export.xlsx":
+			_, _ = w.Write([]byte("PK"))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c := Client{BaseURL: ts.URL}
+	req := SolveRequest{F: "x + y", Exact: "c*exp(x) - x - 1", X0: 0, Y0: 1, XEnd: 1.5, N: 10,
+		Params: map[string]float64{"b": 2, "a": 1}, NByMethod: map[string]int{"rk4": 5}, Errors: true,
+		Methods: []string{"euler", "rk4"}}
+	b, err := c.Export(context.Background(), req, FormatCSV)
+	require.NoError(t, err)
+	assert.Equal(t, "x,euler\n0,1\n", string(b))
+	assert.Equal(t, "f=x%20%2B%20y&exact=c%2Aexp%28x%29%20-%20x%20-%201&x0=0&y0=1&x1=1.5&n=10&param=a%3A1&"+
+		"param=b%3A2&n_by_method=rk4%3A5&errors=true&method=euler&method=rk4", query)
+
+	b, err = c.Export(context.Background(), req, FormatXLSX)
+	require.NoError(t, err)
+	assert.Equal(t, "PK", string(b))
+
+	_, err = c.Export(context.Background(), req, "png")
+	assert.EqualError(t, err, `unknown export format "png", only csv and xlsx are supported`)
+}
+
+func TestClient_Error(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/presets" {
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"code": 2, "details": "invalid solve request", "error": "f: is empty",
+			"errors": [{"field": "f", "msg": "is empty"}]}`))
+	}))
+	defer ts.Close()
+
+	c := Client{BaseURL: ts.URL}
+	_, err := c.Solve(context.Background(), SolveRequest{Methods: []string{"euler"}})
+	require.Error(t, err)
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, http.StatusBadRequest, e.Status)
+	assert.Equal(t, 2, e.Code)
+	assert.Equal(t, []FieldError{{Field: "f", Msg: "is empty"}}, e.Errors)
+	assert.Equal(t, "invalid solve request: f: is empty, status 400, code 2", err.Error())
+
+	_, err = c.Presets(context.Background())
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, http.StatusBadGateway, e.Status)
+	assert.Equal(t, "bad gateway", e.Err)
+
+	_, err = (&Client{BaseURL: "http://127.0.0.1:0"}).Presets(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"bytes"
+	"github.com/Semior001/decompract/app/report"
+	"github.com/Semior001/decompract/app/solve"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Bench measures the performance of methods on the problem, without starting the server
+type Bench struct {
+	ProblemOpts
+	OutputOpts
+	Repeat int `long:"repeat" default:"5" description:"number of measured runs, after the warm up one"`
+
+	stdout io.Writer // stdout of the process, if nil
+
+	CommonOpts
+}
+
+// Execute measures methods and prints the report, all methods are measured, if none are set
+func (b *Bench) Execute(_ []string) error {
+	prob, _, err := b.problem()
+	if err != nil {
+		return err
+	}
+	out := b.output(report.Output{}, report.FormatTable)
+	if err = out.Validate(); err != nil {
+		return err
+	}
+	rep, err := solve.Bench(prob, b.Repeat)
+	if err != nil {
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+	if err = rep.Write(buf, out); err != nil {
+		return errors.Wrap(err, "failed to write report")
+	}
+	return writeStdout(b.stdout, buf)
+}
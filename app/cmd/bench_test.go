@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBench_Execute(t *testing.T) {
+	out := &bytes.Buffer{}
+	b := Bench{ProblemOpts: ProblemOpts{F: "x^2 - 2*y", Y0: 1, X1: 1, N: 10, Methods: []string{"euler", "rk4"}},
+		Repeat: 3, stdout: out}
+	require.NoError(t, b.Execute(nil))
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	require.Len(t, lines, 7, "header and three rows of each method")
+	assert.Regexp(t, `^method +stat +wall +points/s +evals/s +allocs +bytes$`, lines[0])
+	for i, expected := range []string{"euler +min", "euler +median", "euler +max", "rk4 +min", "rk4 +median", "rk4 +max"} {
+		assert.Regexp(t, `^`+expected+` +[0-9.]+(ns|µs|ms|s) +[0-9.e+]+ +[0-9.e+]+ +\d+ +\d+$`, lines[i+1])
+	}
+
+	out.Reset()
+	b.Format = "json"
+	require.NoError(t, b.Execute(nil))
+	var rep solve.BenchReport
+	require.NoError(t, json.Unmarshal(out.Bytes(), &rep))
+	require.Len(t, rep, 2)
+	for i, evalsPerStep := range []int{1, 4} {
+		res := rep[i]
+		assert.Equal(t, b.Methods[i], res.Method)
+		assert.Equal(t, 3, res.Runs)
+		assert.Equal(t, 11, res.Points)
+		assert.Equal(t, 10*evalsPerStep, res.Evals, "f is not evaluated past the last point")
+		for _, st := range []solve.BenchStat{res.Wall, res.PointsPerSec, res.EvalsPerSec, res.Allocs, res.Bytes} {
+			assert.LessOrEqual(t, st.Min, st.Median)
+			assert.LessOrEqual(t, st.Median, st.Max)
+		}
+		assert.Greater(t, res.Wall.Min, 0.0)
+		assert.Greater(t, res.PointsPerSec.Min, 0.0)
+	}
+}
+
+func TestBench_ExecuteErrors(t *testing.T) {
+	for _, tt := range []struct {
+		b   Bench
+		err string
+	}{
+		{Bench{ProblemOpts: ProblemOpts{F: "x", X1: 1, N: 10}}, "number of runs must be positive, got 0"},
+		{Bench{ProblemOpts: ProblemOpts{Preset: "canonical", Methods: []string{"exact"}}, Repeat: 1},
+			"exact solution can't be measured"},
+		{Bench{ProblemOpts: ProblemOpts{F: "x", X1: 1}, Repeat: 1}, "n: either n or step must be set"},
+		{Bench{ProblemOpts: ProblemOpts{F: "z", X1: 1, N: 10}, Repeat: 1}, `f: can't parse f(x,y): unknown variable "z"`},
+		{Bench{ProblemOpts: ProblemOpts{F: "x", X1: 1, N: 10}, OutputOpts: OutputOpts{Format: "xml"}, Repeat: 1},
+			`unknown format "xml", must be one of csv, json, table, markdown`},
+	} {
+		out := &bytes.Buffer{}
+		tt.b.stdout = out
+		err := tt.b.Execute(nil)
+		require.Error(t, err, tt.err)
+		assert.Contains(t, err.Error(), tt.err)
+		assert.Empty(t, out.String())
+	}
+}
@@ -10,11 +10,13 @@ type CommonOptionsCommander interface {
 
 // CommonOpts sets externally from main, shared across all commands
 type CommonOpts struct {
-	Version string
+	Version  string
+	Revision string
 }
 
 // SetCommon satisfies CommonOptionsCommander interface and sets common option fields
 // The method called by main for each command
 func (c *CommonOpts) SetCommon(opts CommonOpts) {
 	c.Version = opts.Version
+	c.Revision = opts.Revision
 }
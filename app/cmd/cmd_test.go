@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Semior001/decompract/app/rest"
+	flags "github.com/jessevdk/go-flags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommonOpts_SetCommon(t *testing.T) {
+	s := Server{}
+	var c CommonOptionsCommander = &s
+	c.SetCommon(CommonOpts{Version: "v1.0.0-1-gabc1234", Revision: "abc1234"})
+	assert.Equal(t, "v1.0.0-1-gabc1234", s.Version)
+	assert.Equal(t, "abc1234", s.Revision)
+}
+
+func TestServer_LoadAPIKeys(t *testing.T) {
+	dir, err := ioutil.TempDir("", "decompract")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	file := filepath.Join(dir, "keys")
+	content := "# keys of clients\n\n" + rest.HashAPIKey("file") + ":2:5\n"
+	require.NoError(t, ioutil.WriteFile(file, []byte(content), 0600))
+
+	s := Server{APIKeys: []string{rest.HashAPIKey("env")}, APIKeysFile: file}
+	keys, err := s.loadAPIKeys()
+	require.NoError(t, err)
+	assert.Equal(t, []rest.APIKey{{Hash: rest.HashAPIKey("env")}, {Hash: rest.HashAPIKey("file"), Rate: 2, Burst: 5}}, keys)
+
+	keys, err = (&Server{}).loadAPIKeys()
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	_, err = (&Server{APIKeys: []string{"plain"}}).loadAPIKeys()
+	assert.Error(t, err, "keys must be hashed")
+
+	_, err = (&Server{APIKeysFile: filepath.Join(dir, "unknown")}).loadAPIKeys()
+	assert.Error(t, err)
+}
+
+func TestServer_SetupTLS(t *testing.T) {
+	setup, err := (&Server{AutocertDir: "./var/autocert"}).setupTLS()
+	require.NoError(t, err)
+	assert.Nil(t, setup, "https is disabled without certificates")
+
+	_, err = (&Server{TLSCert: "cert.pem"}).setupTLS()
+	assert.Error(t, err, "key is required")
+
+	_, err = (&Server{TLSCert: "cert.pem", TLSKey: "key.pem", Autocert: []string{"example.com"}}).setupTLS()
+	assert.Error(t, err, "files and autocert are exclusive")
+}
+
+func TestServer_LoadAssets(t *testing.T) {
+	s := Server{WebEmbedded: true}
+	parse := func(args ...string) error {
+		_, err := flags.NewParser(&s, flags.None).ParseArgs(args)
+		return err
+	}
+	require.NoError(t, parse())
+	assert.True(t, s.WebEmbedded, "assets are embedded by default")
+	a, err := s.loadAssets()
+	require.NoError(t, err)
+	assert.NotNil(t, a)
+
+	t.Setenv("WEB_EMBEDDED", "false")
+	require.NoError(t, parse("--web_root=../web"))
+	assert.False(t, s.WebEmbedded, "embedded assets are turned off by the environment")
+	a, err = s.loadAssets()
+	require.NoError(t, err, "assets are read from the disk")
+	u, err := a.URL("view.css")
+	require.NoError(t, err)
+	assert.Regexp(t, `^/static/view\.[0-9a-f]{8}\.css$`, u)
+
+	s.WebRoot = ""
+	_, err = s.loadAssets()
+	assert.Error(t, err, "app/web is not in the directory of the test")
+
+	assert.Error(t, parse("--web_embedded=false"), "bool flags have no values")
+}
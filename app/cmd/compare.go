@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"github.com/Semior001/decompract/app/report"
+	"github.com/Semior001/decompract/app/solve"
+	"io"
+	"io/ioutil"
+	"math"
+
+	"github.com/Semior001/decompract/app/num/graph"
+	"github.com/pkg/errors"
+)
+
+// Compare compares errors and costs of methods on the problem and prints the report, without starting the server
+type Compare struct {
+	ProblemOpts
+	OutputOpts
+	CSV     string `long:"csv" description:"file to write the report to in csv, besides printing it"`
+	Chart   string `long:"chart" description:"file to write the chart of errors to in png"`
+	Width   int    `long:"width" default:"800" description:"width of the chart in pixels"`
+	Height  int    `long:"height" default:"600" description:"height of the chart in pixels"`
+	Workers int    `long:"workers" description:"methods, solved at once, 1 solves them one after another (default: GOMAXPROCS)"`
+
+	stdout io.Writer // stdout of the process, if nil
+
+	CommonOpts
+}
+
+// Execute compares methods and writes the report, all methods are compared, if none are set,
+// the report is written even if some methods fail, but the error of the first of them is returned
+func (c *Compare) Execute(_ []string) error {
+	prob, _, err := c.problem()
+	if err != nil {
+		return err
+	}
+	out := c.output(report.Output{}, report.FormatTable)
+	if err = out.Validate(); err != nil {
+		return err
+	}
+	if c.Chart != "" && (c.Width < 1 || c.Height < 1) {
+		return errors.Errorf("size of the chart %dx%d must be positive", c.Width, c.Height)
+	}
+
+	// the number of steps is up to the user, as there are no other clients to share resources with
+	cmp, err := solve.Compare(context.Background(), prob, solve.Limits{MaxSteps: math.MaxInt32}, c.Workers)
+	if err != nil {
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+	if err = cmp.Write(buf, out); err != nil {
+		return errors.Wrap(err, "failed to write report")
+	}
+	if err = writeStdout(c.stdout, buf); err != nil {
+		return err
+	}
+
+	if c.CSV != "" {
+		buf.Reset()
+		out.Format = report.FormatCSV
+		if err = cmp.Write(buf, out); err != nil {
+			return errors.Wrap(err, "failed to write report")
+		}
+		if err = ioutil.WriteFile(c.CSV, buf.Bytes(), 0600); err != nil {
+			return errors.Wrapf(err, "failed to write %s", c.CSV)
+		}
+	}
+
+	if c.Chart != "" {
+		b, err := cmp.Chart(graph.Image{Width: c.Width, Height: c.Height, Format: "png"})
+		if err != nil {
+			return errors.Wrap(err, "failed to plot chart")
+		}
+		if err = ioutil.WriteFile(c.Chart, b, 0600); err != nil {
+			return errors.Wrapf(err, "failed to write %s", c.Chart)
+		}
+	}
+
+	return cmp.Err()
+}
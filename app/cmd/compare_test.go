@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"flag"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// took matches the duration in the last column of the report, the only column, that differs between runs
+var took = regexp.MustCompile(`(?m)[0-9.]+(ns|µs|ms|s)$`)
+
+func TestCompare_Execute(t *testing.T) {
+	dir, err := ioutil.TempDir("", "decompract")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	out := &bytes.Buffer{}
+	c := Compare{ProblemOpts: ProblemOpts{Preset: "canonical"}, CSV: filepath.Join(dir, "report.csv"),
+		Chart: filepath.Join(dir, "chart.png"), Width: 400, Height: 300, stdout: out}
+	require.NoError(t, c.Execute(nil))
+
+	actual := took.ReplaceAll(out.Bytes(), []byte("<took>"))
+	path := filepath.Join("testdata", "compare_canonical.txt")
+	if *update {
+		require.NoError(t, ioutil.WriteFile(path, actual, 0600))
+	}
+	expected, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(actual))
+
+	f, err := os.Open(c.CSV)
+	require.NoError(t, err)
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 13, "header and all builtin methods")
+	assert.Equal(t, []string{"method", "max_gte", "mean", "rms", "l2", "evals", "took"}, rows[0])
+	assert.Equal(t, []string{"euler", "1.253e-01", "1.750e-02", "3.955e-02", "1.137e-01", "30"}, rows[6][:6])
+
+	img, err := os.Open(c.Chart)
+	require.NoError(t, err)
+	defer img.Close()
+	cfg, err := png.DecodeConfig(img)
+	require.NoError(t, err)
+	assert.Equal(t, 400, cfg.Width)
+	assert.Equal(t, 300, cfg.Height)
+}
+
+func TestCompare_ExecuteReference(t *testing.T) {
+	// without the exact solution methods are compared with the fine solution by rk4
+	out := &bytes.Buffer{}
+	c := Compare{ProblemOpts: ProblemOpts{F: "x^2 - 2*y", Y0: 1, X1: 1, N: 10, Methods: []string{"euler", "rk4"}},
+		stdout: out}
+	require.NoError(t, c.Execute(nil))
+	assert.Regexp(t, `^method +max_gte +mean +rms +l2 +evals +took\neuler +[0-9.]+e-02 .*\nrk4 +[0-9.]+e-0[5-7] .*\n$`, out.String())
+}
+
+func TestCompare_ExecuteFormat(t *testing.T) {
+	dir, err := ioutil.TempDir("", "decompract")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	out := &bytes.Buffer{}
+	c := Compare{ProblemOpts: ProblemOpts{Preset: "canonical", Methods: []string{"euler"}},
+		OutputOpts: OutputOpts{Format: "markdown", Precision: 2}, CSV: filepath.Join(dir, "report.csv"), stdout: out}
+	require.NoError(t, c.Execute(nil))
+	assert.Regexp(t, `^\| method \| max_gte \| mean    \| rms     \| l2      \| evals \| took +\|\n`+
+		`\| ------ \| ------- \| ------- \| ------- \| ------- \| ----- \| -+ \|\n`+
+		`\| euler  \| 1.3e-01 \| 1.8e-02 \| 4.0e-02 \| 1.1e-01 \| 30    \| [0-9.]+(ns|µs|ms|s) +\|\n$`, out.String())
+
+	b, err := ioutil.ReadFile(c.CSV)
+	require.NoError(t, err)
+	assert.Regexp(t, `^method,max_gte,mean,rms,l2,evals,took\neuler,1.3e-01,1.8e-02,4.0e-02,1.1e-01,30,`, string(b), "csv has the same precision")
+}
+
+func TestCompare_ExecuteErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "decompract")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	// y' = -y^3 from y0 = 10 blows up by euler with large steps, rk4 is stable
+	out := &bytes.Buffer{}
+	c := Compare{ProblemOpts: ProblemOpts{F: "-y*y*y", Exact: "(2*x + c)**(-0.5)", C: "1/(y0*y0) - 2*x0",
+		Y0: 10, X1: 1, N: 45, Methods: []string{"euler", "rk4"}}, CSV: filepath.Join(dir, "report.csv"), stdout: out}
+	err = c.Execute(nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to calculate errors of euler")
+	assert.Regexp(t, `\neuler +- +- +- +- +45 `, out.String(), "the report is written with the failed method")
+	assert.FileExists(t, c.CSV)
+
+	for _, tt := range []struct {
+		c   Compare
+		err string
+	}{
+		{Compare{ProblemOpts: ProblemOpts{Preset: "unknown"}}, `unknown preset "unknown", available: canonical, dahlquist, logistic, stiff-decay`},
+		{Compare{ProblemOpts: ProblemOpts{Preset: "canonical", File: "problem.yaml"}}, "mutually exclusive"},
+		{Compare{ProblemOpts: ProblemOpts{Preset: "canonical", Methods: []string{"exact"}}},
+			"methods: exact solution is the reference, it has no errors"},
+		{Compare{ProblemOpts: ProblemOpts{Preset: "canonical"}, Chart: "chart.png"}, "size of the chart 0x0 must be positive"},
+		{Compare{ProblemOpts: ProblemOpts{F: "z", X1: 1, N: 10}}, `f: can't parse f(x,y): unknown variable "z"`},
+	} {
+		out := &bytes.Buffer{}
+		tt.c.stdout = out
+		err := tt.c.Execute(nil)
+		require.Error(t, err, tt.err)
+		assert.Contains(t, err.Error(), tt.err)
+		assert.Empty(t, out.String())
+	}
+}
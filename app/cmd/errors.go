@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"github.com/Semior001/decompract/app/report"
+	"github.com/Semior001/decompract/app/solve"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/Semior001/decompract/app/num/graph"
+	"github.com/pkg/errors"
+)
+
+// Errors sweeps the number of steps and prints max global errors of methods with observed orders of convergence,
+// without starting the server
+type Errors struct {
+	ProblemOpts
+	OutputOpts
+	NFrom     count  `long:"n-from" default:"10" description:"the least number of steps"`
+	NTo       count  `long:"n-to" default:"320" description:"the largest number of steps"`
+	Geometric bool   `long:"geometric" description:"double the number of steps from row to row, the default spacing"`
+	Linear    bool   `long:"linear" description:"solve with each number of steps from n-from to n-to"`
+	MaxSteps  count  `long:"max-steps" default:"1e6" description:"max number of steps, larger sweeps are refused"`
+	Chart     string `long:"chart" description:"file to write the log-log chart of errors to in png"`
+	Width     int    `long:"width" default:"800" description:"width of the chart in pixels"`
+	Height    int    `long:"height" default:"600" description:"height of the chart in pixels"`
+	Workers   int    `long:"workers" description:"solutions with each method and n at once, 1 solves them one after another (default: GOMAXPROCS)"`
+
+	stdout io.Writer // stdout of the process, if nil
+
+	CommonOpts
+}
+
+// Execute runs the sweep and writes the report, all methods are swept, if none are set, the reference is
+// the exact solution, if it is set, or the fine solution, the report is written even if some methods fail,
+// but the error of the first of them is returned, the interrupted sweep writes the report of completed solutions
+func (e *Errors) Execute(_ []string) error {
+	if e.N != 0 || e.Step != 0 {
+		return errors.New("n and step are swept, set the range by n-from and n-to instead")
+	}
+	if e.Geometric && e.Linear {
+		return errors.New("geometric and linear spacings are mutually exclusive")
+	}
+	prob, _, err := e.problem()
+	if err != nil {
+		return err
+	}
+	// the number of steps is set by the sweep
+	prob.N, prob.Step = 0, 0
+	out := e.output(report.Output{}, report.FormatTable)
+	if err = out.Validate(); err != nil {
+		return err
+	}
+	if e.Chart != "" && (e.Width < 1 || e.Height < 1) {
+		return errors.Errorf("size of the chart %dx%d must be positive", e.Width, e.Height)
+	}
+
+	rng := solve.SweepRange{From: int(e.NFrom), To: int(e.NTo), Geometric: !e.Linear}
+	// each number of steps of the sweep is solved, so the sweep is limited only by the step cap
+	l := solve.Limits{MaxSteps: int(e.MaxSteps), MaxSweep: int(e.MaxSteps)}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	sw, err := solve.SweepErrors(ctx, prob, rng, l, e.Workers)
+	interrupted := errors.Is(err, context.Canceled)
+	if err != nil && !interrupted {
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+	if err = sw.Write(buf, out); err != nil {
+		return errors.Wrap(err, "failed to write report")
+	}
+	if err = writeStdout(e.stdout, buf); err != nil {
+		return err
+	}
+	if interrupted {
+		return errors.New("sweep is interrupted, the report has only completed solutions")
+	}
+
+	if e.Chart != "" {
+		b, err := sw.Chart(graph.Image{Width: e.Width, Height: e.Height, Format: "png"})
+		if err != nil {
+			return errors.Wrap(err, "failed to plot chart")
+		}
+		if err = ioutil.WriteFile(e.Chart, b, 0600); err != nil {
+			return errors.Wrapf(err, "failed to write %s", e.Chart)
+		}
+	}
+
+	return sw.Err()
+}
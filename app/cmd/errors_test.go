@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"bytes"
+	"image/png"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrors_Execute(t *testing.T) {
+	dir, err := ioutil.TempDir("", "decompract")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	out := &bytes.Buffer{}
+	e := Errors{ProblemOpts: ProblemOpts{Preset: "canonical", Methods: []string{"euler", "ieuler", "rk4"}},
+		NFrom: 10, NTo: 320, MaxSteps: 1000,
+		Chart: filepath.Join(dir, "errors.png"), Width: 400, Height: 300, stdout: out}
+	require.NoError(t, e.Execute(nil))
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	require.Len(t, lines, 19, "header and six rows of each method")
+	assert.Equal(t, []string{"method", "n", "h", "max_gte", "order"}, strings.Fields(lines[0]))
+	for i, expected := range []struct {
+		method string
+		order  float64
+	}{{"euler", 1}, {"ieuler", 2}, {"rk4", 4}} {
+		rows := lines[1+6*i : 7+6*i]
+		prevGTE, prevDiff := math.Inf(1), math.Inf(1)
+		for j, row := range rows {
+			cells := strings.Fields(row)
+			require.Len(t, cells, 5, row)
+			assert.Equal(t, expected.method, cells[0])
+			n := 10 << j
+			assert.Equal(t, strconv.Itoa(n), cells[1], "n is doubled")
+			assert.Equal(t, strconv.FormatFloat(8/float64(n), 'g', 4, 64), cells[2])
+			gte, err := strconv.ParseFloat(cells[3], 64)
+			require.NoError(t, err)
+			assert.Less(t, gte, prevGTE, row)
+			prevGTE = gte
+			if j == 0 {
+				assert.Equal(t, "-", cells[4], "the order is observed from the previous row")
+				continue
+			}
+			order, err := strconv.ParseFloat(cells[4], 64)
+			require.NoError(t, err)
+			if j > 1 {
+				assert.Less(t, math.Abs(order-expected.order), prevDiff, "orders converge, %s", row)
+			}
+			prevDiff = math.Abs(order - expected.order)
+		}
+		assert.Less(t, prevDiff, 0.1, "the order of %s at n=320", expected.method)
+	}
+
+	img, err := os.Open(e.Chart)
+	require.NoError(t, err)
+	defer img.Close()
+	cfg, err := png.DecodeConfig(img)
+	require.NoError(t, err)
+	assert.Equal(t, 400, cfg.Width)
+	assert.Equal(t, 300, cfg.Height)
+}
+
+func TestErrors_ExecuteReference(t *testing.T) {
+	// without the exact solution errors are measured against the fine solution by rk4
+	out := &bytes.Buffer{}
+	e := Errors{ProblemOpts: ProblemOpts{F: "x^2 - 2*y", Y0: 1, X1: 1, Methods: []string{"euler", "ieuler"}},
+		OutputOpts: OutputOpts{Format: "csv"}, NFrom: 10, NTo: 15, Linear: true, MaxSteps: 1000, stdout: out}
+	require.NoError(t, e.Execute(nil))
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	require.Len(t, lines, 13, "header and each n of both methods")
+	assert.Equal(t, "method,n,h,max_gte,order", lines[0])
+	assert.Regexp(t, `^euler,10,0.1,[0-9.]+e-02,-$`, lines[1])
+	assert.Regexp(t, `^euler,11,0.09091,[0-9.]+e-02,(0\.9|1\.0)[0-9]*$`, lines[2])
+	assert.Regexp(t, `^ieuler,15,0.06667,[0-9.]+e-0[34],(1\.9|2\.0)[0-9]*$`, lines[12])
+}
+
+func TestErrors_ExecuteErrors(t *testing.T) {
+	canonical := ProblemOpts{Preset: "canonical"}
+	for _, tt := range []struct {
+		e   Errors
+		err string
+	}{
+		{Errors{ProblemOpts: canonical, NFrom: 10, NTo: 2000, MaxSteps: 1000},
+			"n1: must not be more than max_steps=1000, got 2000"},
+		{Errors{ProblemOpts: canonical, NFrom: 1, NTo: 1000, Linear: true, MaxSteps: 100},
+			"n1: must not be more than max_steps=100, got 1000"},
+		{Errors{ProblemOpts: canonical, NFrom: 20, NTo: 10, MaxSteps: 1000}, "n1: must not be less than n0=20, got 10"},
+		{Errors{ProblemOpts: ProblemOpts{Preset: "canonical", N: 10}, NFrom: 10, NTo: 20, MaxSteps: 1000},
+			"n and step are swept"},
+		{Errors{ProblemOpts: canonical, NFrom: 10, NTo: 20, Geometric: true, Linear: true, MaxSteps: 1000},
+			"mutually exclusive"},
+		{Errors{ProblemOpts: ProblemOpts{Preset: "canonical", Methods: []string{"exact"}}, NFrom: 10, NTo: 20, MaxSteps: 1000},
+			"methods: exact solution is the reference, it has no errors"},
+		{Errors{ProblemOpts: canonical, OutputOpts: OutputOpts{Format: "xml"}, NFrom: 10, NTo: 20, MaxSteps: 1000},
+			`unknown format "xml"`},
+		{Errors{ProblemOpts: canonical, NFrom: 10, NTo: 20, MaxSteps: 1000, Chart: "errors.png"},
+			"size of the chart 0x0 must be positive"},
+	} {
+		out := &bytes.Buffer{}
+		tt.e.stdout = out
+		err := tt.e.Execute(nil)
+		require.Error(t, err, tt.err)
+		assert.Contains(t, err.Error(), tt.err)
+		assert.Empty(t, out.String())
+	}
+}
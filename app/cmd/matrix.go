@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"bytes"
+	"github.com/Semior001/decompract/app/report"
+	"github.com/Semior001/decompract/app/solve"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Matrix compares each of methods on each of presets and prints the matrix of errors and times,
+// without starting the server
+type Matrix struct {
+	OutputOpts
+	Presets []string `long:"preset" description:"name of the built-in problem, repeatable (default: all presets)"`
+	Methods []string `long:"method" description:"method to compare, repeatable (default: all methods)"`
+	N       count    `long:"n" description:"number of steps of each preset, might be in the scientific notation (default: steps of presets)"`
+
+	stdout io.Writer // stdout of the process, if nil
+
+	CommonOpts
+}
+
+// Execute compares methods on presets and writes the matrix, the matrix is written even if some cells fail,
+// but the error of the first of them is returned
+func (m *Matrix) Execute(_ []string) error {
+	probs, err := solve.PresetProblems(m.Presets...)
+	if err != nil {
+		return err
+	}
+	out := m.output(report.Output{}, report.FormatMarkdown)
+	if err = out.Validate(); err != nil {
+		return err
+	}
+
+	mx, err := solve.CompareAll(probs, m.Methods, int(m.N))
+	if err != nil {
+		return err
+	}
+	buf := &bytes.Buffer{}
+	if err = mx.Write(buf, out); err != nil {
+		return errors.Wrap(err, "failed to write matrix")
+	}
+	if err = writeStdout(m.stdout, buf); err != nil {
+		return err
+	}
+	return mx.Err()
+}
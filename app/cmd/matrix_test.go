@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatrix_Execute(t *testing.T) {
+	out := &bytes.Buffer{}
+	m := Matrix{Methods: []string{"euler", "rk4"}, stdout: out}
+	require.NoError(t, m.Execute(nil))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 4, "all presets are compared by default")
+	assert.Regexp(t, `^\| method +\| canonical +\| dahlquist +\| logistic +\| stiff-decay +\|$`, lines[0])
+	assert.Regexp(t, `^\| euler +\| 1\.253e-01 in \S+ +(\| \S+ in \S+ +){3}\|$`, lines[2])
+	assert.Regexp(t, `^\| rk4 +(\| \S+ in \S+ +){4}\|$`, lines[3])
+
+	out.Reset()
+	m = Matrix{OutputOpts: OutputOpts{Format: "json"}, Presets: []string{"canonical"}, Methods: []string{"rk4"},
+		N: 10, stdout: out}
+	require.NoError(t, m.Execute(nil))
+	res := struct {
+		N        int
+		Problems []string
+		Cells    [][]struct {
+			MaxGTE float64 `json:"max_gte"`
+			Evals  int
+		}
+	}{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &res))
+	assert.Equal(t, 10, res.N)
+	assert.Equal(t, []string{"canonical"}, res.Problems)
+	require.Len(t, res.Cells, 1)
+	require.Len(t, res.Cells[0], 1)
+	assert.Equal(t, 40, res.Cells[0][0].Evals, "four evaluations at each step")
+	assert.Greater(t, res.Cells[0][0].MaxGTE, 0.0)
+}
+
+func TestMatrix_ExecuteErrors(t *testing.T) {
+	for _, tt := range []struct {
+		m   Matrix
+		err string
+	}{
+		{Matrix{Presets: []string{"unknown"}}, `unknown preset "unknown", available: canonical, dahlquist, logistic, stiff-decay`},
+		{Matrix{Methods: []string{"rk5"}}, `unknown method "rk5"`},
+		{Matrix{OutputOpts: OutputOpts{Format: "xml"}}, `unknown format "xml"`},
+	} {
+		out := &bytes.Buffer{}
+		tt.m.stdout = out
+		err := tt.m.Execute(nil)
+		require.Error(t, err, tt.err)
+		assert.Contains(t, err.Error(), tt.err)
+		assert.Empty(t, out.String())
+	}
+}
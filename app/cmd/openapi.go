@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+
+	"github.com/Semior001/decompract/app/rest/api"
+	"github.com/pkg/errors"
+)
+
+// OpenAPI prints the OpenAPI description of the REST API, without starting the server, it is generated
+// at build time as the contract of the client package
+type OpenAPI struct {
+	Out        string `long:"out" default:"-" description:"file to write the description to, - for stdout"`
+	APIVersion string `long:"api-version" description:"version of the api in the description (default: version of the app)"`
+
+	stdout io.Writer // stdout of the process, if nil
+
+	CommonOpts
+}
+
+// Execute writes the description of the server without api keys
+func (o *OpenAPI) Execute(_ []string) error {
+	version := o.APIVersion
+	if version == "" {
+		version = o.Version
+	}
+	buf := &bytes.Buffer{}
+	if err := api.WriteOpenAPI(buf, version); err != nil {
+		return err
+	}
+	if o.Out == "" || o.Out == "-" {
+		return writeStdout(o.stdout, buf)
+	}
+	if err := ioutil.WriteFile(o.Out, buf.Bytes(), 0644); err != nil {
+		return errors.Wrapf(err, "failed to write %s", o.Out)
+	}
+	return nil
+}
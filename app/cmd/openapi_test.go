@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPI_Execute(t *testing.T) {
+	out := &bytes.Buffer{}
+	o := OpenAPI{stdout: out, CommonOpts: CommonOpts{Version: "v1.0.0-1-gabc1234"}}
+	require.NoError(t, o.Execute(nil))
+	doc := struct {
+		OpenAPI string
+		Info    struct{ Version string }
+		Paths   map[string]interface{}
+	}{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &doc))
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+	assert.Equal(t, "v1.0.0-1-gabc1234", doc.Info.Version, "the version of the app by default")
+	assert.Contains(t, doc.Paths, "/api/v1/solve")
+	assert.Contains(t, doc.Paths, "/api/presets")
+
+	dir, err := ioutil.TempDir("", "decompract")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	o = OpenAPI{Out: filepath.Join(dir, "openapi.json"), APIVersion: "v1", stdout: out}
+	out.Reset()
+	require.NoError(t, o.Execute(nil))
+	assert.Empty(t, out.String(), "the description is written to the file")
+	b, err := ioutil.ReadFile(o.Out)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(b, &doc))
+	assert.Equal(t, "v1", doc.Info.Version)
+
+	o.Out = filepath.Join(dir, "missing", "openapi.json")
+	assert.Error(t, o.Execute(nil))
+}
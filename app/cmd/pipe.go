@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"math"
+	"os"
+
+	"github.com/Semior001/decompract/app/solve"
+)
+
+// Pipe reads problems as newline-delimited json from stdin and writes their results to stdout line by line
+type Pipe struct {
+	Workers     int  `long:"workers" default:"4" description:"number of problems, solved concurrently"`
+	ErrorsFatal bool `long:"errors-fatal" description:"stop at the first problem, that fails"`
+
+	stdin  io.Reader // stdin of the process, if nil
+	stdout io.Writer // stdout of the process, if nil
+
+	CommonOpts
+}
+
+// Execute solves problems from stdin until its end, results are written in the order of problems
+func (p *Pipe) Execute(_ []string) error {
+	stdin, stdout := p.stdin, p.stdout
+	if stdin == nil {
+		stdin = os.Stdin
+	}
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	// the number of steps is up to the user, as there are no other clients to share resources with
+	return solve.Pipe(context.Background(), stdin, stdout, solve.PipeOpts{Workers: p.Workers, ErrorsFatal: p.ErrorsFatal,
+		Limits: solve.Limits{MaxSteps: math.MaxInt32}})
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pipeLine struct {
+	Line   int `json:"line"`
+	Result *struct {
+		Lines []struct {
+			Method string `json:"method"`
+			Points []struct {
+				X float64 `json:"x"`
+				Y float64 `json:"y"`
+			} `json:"points"`
+		} `json:"lines"`
+	} `json:"result"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Error   string `json:"error"`
+		Details string `json:"details"`
+	} `json:"error"`
+}
+
+func readPipe(t *testing.T, out string) []pipeLine {
+	var res []pipeLine
+	for _, s := range strings.SplitAfter(out, "\n") {
+		if s == "" {
+			continue
+		}
+		require.True(t, strings.HasSuffix(s, "\n"), "each result is written in its line")
+		l := pipeLine{}
+		require.NoError(t, json.Unmarshal([]byte(s), &l), s)
+		res = append(res, l)
+	}
+	return res
+}
+
+func TestPipe_Execute(t *testing.T) {
+	in := strings.Join([]string{
+		// the first problem is the slowest one, so the rest are solved before it
+		`{"f": "x", "x_end": 1, "n": 100000, "methods": ["rk4"]}`,
+		`{"f": "x", "x_end": 1, "n": 2, "methods": ["ieuler"]}`,
+		``,
+		`{"f": "x",`,
+		`{"f": "x", "x_end": 1, "n": 2, "methods": ["rk5"]}`,
+		`{"f": "x", "x_end": 1, "n": 2, "methods": ["rk4"], "x1": 2}`,
+		`{"f": "x", "x_end": 1, "n": 2, "methods": ["rk4"], "save": true}`,
+		`{"f": "2*x", "x_end": 1, "step": 0.5, "methods": ["euler"]}`,
+	}, "\n")
+	out := &bytes.Buffer{}
+	p := Pipe{Workers: 4, stdin: strings.NewReader(in), stdout: out}
+	require.NoError(t, p.Execute(nil))
+
+	lines := readPipe(t, out.String())
+	require.Len(t, lines, 7, "blank lines are skipped")
+	for i, line := range []int{1, 2, 4, 5, 6, 7, 8} {
+		assert.Equal(t, line, lines[i].Line, "results are in the order of input")
+	}
+
+	require.NotNil(t, lines[0].Result)
+	assert.Len(t, lines[0].Result.Lines[0].Points, 100001)
+	require.NotNil(t, lines[1].Result)
+	assert.Equal(t, "ieuler", lines[1].Result.Lines[0].Method)
+	assert.Equal(t, 0.125, lines[1].Result.Lines[0].Points[1].Y)
+
+	for i, expected := range []struct {
+		code    int
+		details string
+		err     string
+	}{
+		{1, "failed to decode problem", "unexpected EOF"},
+		{2, "invalid solve request", `methods: unknown method "rk5"`},
+		{1, "failed to decode problem", `json: unknown field "x1"`},
+		{2, "saving of results is disabled", "results can't be saved"},
+	} {
+		line := lines[i+2]
+		assert.Nil(t, line.Result)
+		require.NotNil(t, line.Error, line.Line)
+		assert.Equal(t, expected.code, line.Error.Code)
+		assert.Equal(t, expected.details, line.Error.Details)
+		assert.Equal(t, expected.err, line.Error.Error)
+	}
+	require.NotNil(t, lines[6].Result)
+	assert.Equal(t, 1.0, lines[6].Result.Lines[0].Points[2].X)
+}
+
+func TestPipe_ExecuteErrorsFatal(t *testing.T) {
+	in := strings.Join([]string{
+		`{"f": "x", "x_end": 1, "n": 2, "methods": ["rk4"]}`,
+		`{"f": "x", "x_end": 1, "methods": ["rk4"]}`,
+		`{"f": "x", "x_end": 1, "n": 2, "methods": ["rk4"]}`,
+	}, "\n")
+	out := &bytes.Buffer{}
+	p := Pipe{Workers: 2, ErrorsFatal: true, stdin: strings.NewReader(in), stdout: out}
+	err := p.Execute(nil)
+	require.EqualError(t, err, "problem at line 2 failed: n: either n or step must be set")
+
+	lines := readPipe(t, out.String())
+	require.Len(t, lines, 2, "the stream stops at the failed problem")
+	assert.NotNil(t, lines[0].Result)
+	assert.NotNil(t, lines[1].Error)
+}
+
+func TestPipe_ExecuteBackpressure(t *testing.T) {
+	// results are not read, so only workers problems are read ahead of the output
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	p := Pipe{Workers: 2, stdin: inR, stdout: outW}
+	done := make(chan error, 1)
+	go func() { done <- p.Execute(nil) }()
+
+	written := make(chan int, 10)
+	go func() {
+		for i := 0; i < 10; i++ {
+			if _, err := io.WriteString(inW, `{"f": "x", "x_end": 1, "n": 2, "methods": ["rk4"]}`+"\n"); err != nil {
+				return
+			}
+			written <- i + 1
+		}
+		_ = inW.Close()
+	}()
+
+	// the first result is blocked in the output, two more problems take the slots
+	time.Sleep(100 * time.Millisecond)
+	assert.LessOrEqual(t, len(written), 4, "reading stops, when the output is blocked")
+
+	// reading the output unblocks the input
+	go func() { _, _ = io.Copy(io.Discard, outR) }()
+	require.NoError(t, <-done)
+	assert.Len(t, written, 10)
+	_ = outW.Close()
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"github.com/Semior001/decompract/app/report"
+	"github.com/Semior001/decompract/app/solve"
+	"io"
+	"math"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// Refine doubles the number of steps of methods, until their solutions stop changing, and prints
+// the differences of levels, without starting the server
+type Refine struct {
+	ProblemOpts
+	OutputOpts
+	Tol          float64 `long:"tol" default:"1e-6" description:"max difference of successive solutions at nodes of the coarser one"`
+	MaxDoublings int     `long:"max-doublings" default:"20" description:"max number of doublings of n"`
+
+	stdout io.Writer // stdout of the process, if nil
+
+	CommonOpts
+}
+
+// Execute refines grids of methods from n of the problem and writes the report, all methods are refined,
+// if none are set, the report is written even if some methods fail or are not converged, but the error
+// of the first of them is returned
+func (r *Refine) Execute(_ []string) error {
+	prob, _, err := r.problem()
+	if err != nil {
+		return err
+	}
+	if prob.N == 0 {
+		return errors.New("n must be set, as it is doubled")
+	}
+	prob.RefineTol, prob.RefineDoublings = r.Tol, r.MaxDoublings
+	out := r.output(report.Output{}, report.FormatTable)
+	if err = out.Validate(); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// doublings are limited only by the count of steps, that fits into int32
+	ref, err := solve.Refine(ctx, prob, solve.Limits{MaxSteps: math.MaxInt32})
+	if err != nil {
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+	if err = ref.Write(buf, out); err != nil {
+		return errors.Wrap(err, "failed to write report")
+	}
+	if err = writeStdout(r.stdout, buf); err != nil {
+		return err
+	}
+	return ref.Err()
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefine_Execute(t *testing.T) {
+	out := &bytes.Buffer{}
+	r := Refine{ProblemOpts: ProblemOpts{Preset: "canonical", Methods: []string{"rk4"}}, Tol: 1e-6, MaxDoublings: 20,
+		stdout: out}
+	require.NoError(t, r.Execute(nil))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 5, "the header and four levels of rk4")
+	assert.Regexp(t, `^rk4 +240 +\S+e-07$`, lines[4])
+
+	// euler is not converged, but the report is written
+	out.Reset()
+	r = Refine{ProblemOpts: ProblemOpts{Preset: "canonical", Methods: []string{"euler"}},
+		OutputOpts: OutputOpts{Format: "json"}, Tol: 1e-6, MaxDoublings: 4, stdout: out}
+	err := r.Execute(nil)
+	assert.True(t, errors.Is(err, solver.ErrNotConverged), err)
+	res := struct {
+		Tol   float64
+		Lines []struct {
+			Method string
+			Refine struct {
+				N         int
+				Doublings int
+				Converged bool
+			}
+		}
+	}{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &res))
+	assert.Equal(t, 1e-6, res.Tol)
+	require.Len(t, res.Lines, 1)
+	assert.Equal(t, "euler", res.Lines[0].Method)
+	assert.Equal(t, 480, res.Lines[0].Refine.N)
+	assert.Equal(t, 4, res.Lines[0].Refine.Doublings)
+	assert.False(t, res.Lines[0].Refine.Converged)
+}
+
+func TestRefine_ExecuteErrors(t *testing.T) {
+	for _, tt := range []struct {
+		r   Refine
+		err string
+	}{
+		{Refine{ProblemOpts: ProblemOpts{F: "x", X0: 0, Y0: 1, X1: 1, Step: 0.1}, Tol: 1e-6, MaxDoublings: 5},
+			"n must be set, as it is doubled"},
+		{Refine{ProblemOpts: ProblemOpts{Preset: "canonical"}, Tol: -1, MaxDoublings: 5},
+			"tol must be positive and finite, got -1"},
+		{Refine{ProblemOpts: ProblemOpts{Preset: "canonical"}, OutputOpts: OutputOpts{Format: "xml"}, Tol: 1e-6},
+			`unknown format "xml"`},
+	} {
+		out := &bytes.Buffer{}
+		tt.r.stdout = out
+		err := tt.r.Execute(nil)
+		require.Error(t, err, tt.err)
+		assert.Contains(t, err.Error(), tt.err)
+		assert.Empty(t, out.String())
+	}
+}
@@ -1,13 +1,26 @@
 package cmd
 
 import (
+	"context"
+	"io/ioutil"
 	"math"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/Semior001/decompract/app/num/graph"
 	"github.com/Semior001/decompract/app/num/service"
+	"github.com/Semior001/decompract/app/solve"
 
 	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/rest"
 	"github.com/Semior001/decompract/app/rest/api"
+	"github.com/Semior001/decompract/app/store"
+	"github.com/Semior001/decompract/app/web"
+	log "github.com/go-pkgz/lgr"
+	"github.com/pkg/errors"
 )
 
 // Server runs REST API web server
@@ -15,7 +28,65 @@ type Server struct {
 	ServiceURL string `long:"service_url" env:"SERVICE_URL" description:"http service url" default:"http://localhost:8080"`
 	Port       int    `long:"service_port" env:"SERVICE_PORT" description:"http server port" default:"8080"`
 
-	WebRoot string `long:"web-root" env:"WEB_ROOT" default:"./web" description:"web root directory"`
+	// bool flags of go-flags can't default to true, so the command is made with embedded assets by main
+	// and WEB_EMBEDDED=false turns them off
+	WebRoot     string `long:"web_root" env:"WEB_ROOT" description:"directory with templates and static assets to override the embedded ones"`
+	WebEmbedded bool   `long:"web_embedded" env:"WEB_EMBEDDED" description:"serve embedded assets, false reads them from web_root (default: app/web) and reloads them on changes for development"`
+
+	MaxSteps      int     `long:"max_steps" env:"MAX_STEPS" default:"10000" description:"max number of steps in solve request"`
+	MaxWidth      float64 `long:"max_width" env:"MAX_WIDTH" default:"0" description:"max width of interval in solve request, 0 for unlimited"`
+	MaxBatch      int     `long:"max_batch" env:"MAX_BATCH" default:"100" description:"max number of problems in batch request"`
+	MaxConcurrent int     `long:"max_concurrent" env:"MAX_CONCURRENT" default:"0" description:"max number of lines solved at once, 0 for unlimited"`
+	MaxPoints     int     `long:"max_points" env:"MAX_POINTS" default:"0" description:"max number of points of line in response, 0 for unlimited"`
+	MaxSweep      int     `long:"max_sweep" env:"MAX_SWEEP" default:"200" description:"max number of values of n in error sweep"`
+	MaxPerClient  int     `long:"max_per_client" env:"MAX_PER_CLIENT" default:"0" description:"max number of solve requests of a client at once, 0 for unlimited"`
+	MaxJobSteps   int     `long:"max_job_steps" env:"MAX_JOB_STEPS" default:"1000000" description:"max number of steps in solve of job"`
+	BatchWorkers  int     `long:"batch_workers" env:"BATCH_WORKERS" default:"4" description:"number of concurrently solved problems in batch"`
+	SweepWorkers  int     `long:"sweep_workers" env:"SWEEP_WORKERS" default:"0" description:"number of concurrent solutions of error sweep, 0 for the number of CPUs"`
+	JobWorkers    int     `long:"job_workers" env:"JOB_WORKERS" default:"2" description:"number of jobs solved at once"`
+
+	SolveTimeout time.Duration `long:"solve_timeout" env:"SOLVE_TIMEOUT" default:"5s" description:"max duration of computations of a request"`
+	JobTimeout   time.Duration `long:"job_timeout" env:"JOB_TIMEOUT" default:"10m" description:"max duration of solve of job"`
+	DrainTimeout time.Duration `long:"drain_timeout" env:"DRAIN_TIMEOUT" default:"10s" description:"max duration to wait for requests in flight on shutdown"`
+
+	RateLimit  float64 `long:"rate_limit" env:"RATE_LIMIT" default:"0" description:"solve requests per second from a client, 0 for unlimited"`
+	RateBurst  int     `long:"rate_burst" env:"RATE_BURST" default:"10" description:"solve requests from a client at once"`
+	TrustProxy bool    `long:"trust_proxy" env:"TRUST_PROXY" description:"take client's address from X-Forwarded-For and X-Real-IP"`
+
+	CORSOrigins []string `long:"cors_origin" env:"CORS_ORIGINS" env-delim:"," description:"origin, allowed to call API from browser, * for any"`
+
+	CacheSize int           `long:"cache_size" env:"CACHE_SIZE" default:"1000" description:"max number of cached solutions, 0 to disable"`
+	CacheTTL  time.Duration `long:"cache_ttl" env:"CACHE_TTL" default:"10m" description:"time to live of cached solution"`
+
+	ResultsDB  string        `long:"results_db" env:"RESULTS_DB" description:"bolt db file to persist saved results, in memory if empty"`
+	ResultsMax int           `long:"results_max" env:"RESULTS_MAX" default:"10000" description:"max number of saved results"`
+	ResultsTTL time.Duration `long:"results_ttl" env:"RESULTS_TTL" default:"720h" description:"time to live of saved result"`
+
+	HistoryDB  string `long:"history_db" env:"HISTORY_DB" description:"bolt db file to persist runs of sessions, in memory if empty"`
+	HistoryMax int    `long:"history_max" env:"HISTORY_MAX" default:"10000" description:"max number of runs of all sessions"`
+
+	NumberDigits    int    `long:"number_digits" env:"NUMBER_DIGITS" default:"0" description:"significant digits of numbers in json and csv responses, 0 for the shortest"`
+	NumberNotation  string `long:"number_notation" env:"NUMBER_NOTATION" description:"notation of numbers in responses: g (default), e or f, f counts digits after the point"`
+	CanonicalOutput bool   `long:"canonical_output" env:"CANONICAL_OUTPUT" description:"byte-identical responses to identical requests, with sorted keys, zeroed durations and 12 digits, unless set"`
+
+	ChartThinAbove int `long:"chart_thin_above" env:"CHART_THIN_ABOVE" default:"1000" description:"lines of chart with more points are thinned by curvature, 0 to disable"`
+
+	RecordDir   string `long:"record_dir" env:"RECORD_DIR" description:"directory to record failed solves to replay them, disabled if empty"`
+	RecordMaxMB int    `long:"record_max_mb" env:"RECORD_MAX_MB" default:"100" description:"max total size of recorded solves in megabytes, the oldest ones are removed"`
+
+	SessionSecret string `long:"session_secret" env:"SESSION_SECRET" description:"key to sign session cookies and permalinks, random if empty"`
+
+	APIKeys     []string `long:"api_key" env:"API_KEYS" env-delim:"," description:"sha256 of api key as hash[:rate[:burst]], api is open if no keys"`
+	APIKeysFile string   `long:"api_keys_file" env:"API_KEYS_FILE" description:"file with api keys as hash[:rate[:burst]], one per line"`
+
+	TLSCert      string   `long:"tls_cert" env:"TLS_CERT" description:"certificate file to serve https"`
+	TLSKey       string   `long:"tls_key" env:"TLS_KEY" description:"private key file of the certificate"`
+	Autocert     []string `long:"autocert_domain" env:"AUTOCERT_DOMAINS" env-delim:"," description:"domain to obtain certificate from Let's Encrypt"`
+	AutocertDir  string   `long:"autocert_cache" env:"AUTOCERT_CACHE" default:"./var/autocert" description:"directory to keep obtained certificates"`
+	AutocertMail string   `long:"autocert_email" env:"AUTOCERT_EMAIL" description:"contact email of Let's Encrypt account"`
+	RedirectPort int      `long:"http_redirect_port" env:"HTTP_REDIRECT_PORT" default:"80" description:"port of http listener, redirecting to https, 0 to disable"`
+
+	GRPCPort int `long:"grpc_port" env:"GRPC_PORT" default:"0" description:"port of grpc api of the solver, 0 to disable"`
 
 	CommonOpts
 }
@@ -35,9 +106,85 @@ func (s *Server) Execute(_ []string) error {
 	//	return 4.0/x/x - y/x - y*y, nil
 	//}
 
+	assets, err := s.loadAssets()
+	if err != nil {
+		return errors.Wrap(err, "failed to load web assets")
+	}
+
+	tlsSetup, err := s.setupTLS()
+	if err != nil {
+		return errors.Wrap(err, "failed to setup tls")
+	}
+
+	apiKeys, err := s.loadAPIKeys()
+	if err != nil {
+		return errors.Wrap(err, "failed to load api keys")
+	}
+
+	numbers := rest.NumberFormat{Digits: s.NumberDigits, Notation: s.NumberNotation}
+	if err = numbers.Validate(); err != nil {
+		return errors.Wrap(err, "invalid format of numbers")
+	}
+
+	results, err := s.makeStore()
+	if err != nil {
+		return errors.Wrap(err, "failed to make store of results")
+	}
+	defer func() {
+		if err := results.Close(); err != nil {
+			log.Printf("[WARN] failed to close store of results, %v", err)
+		}
+	}()
+
+	runs, err := s.makeRuns()
+	if err != nil {
+		return errors.Wrap(err, "failed to make store of runs")
+	}
+	defer func() {
+		if err := runs.Close(); err != nil {
+			log.Printf("[WARN] failed to close store of runs, %v", err)
+		}
+	}()
+
 	srv := api.Rest{
-		Version: s.Version,
-		WebRoot: s.WebRoot,
+		Version:  s.Version,
+		Revision: s.Revision,
+		Web:      assets,
+		Limits: solve.Limits{
+			MaxSteps:      s.MaxSteps,
+			MaxWidth:      s.MaxWidth,
+			MaxBatch:      s.MaxBatch,
+			MaxConcurrent: s.MaxConcurrent,
+			MaxPoints:     s.MaxPoints,
+			MaxSweep:      s.MaxSweep,
+			MaxPerClient:  s.MaxPerClient,
+			MaxJobSteps:   s.MaxJobSteps,
+		},
+		BatchWorkers: s.BatchWorkers,
+		SweepWorkers: s.SweepWorkers,
+		JobWorkers:   s.JobWorkers,
+		SolveTimeout: s.SolveTimeout,
+		JobTimeout:   s.JobTimeout,
+		DrainTimeout: s.DrainTimeout,
+		RateLimit:    s.RateLimit,
+		RateBurst:    s.RateBurst,
+		TrustProxy:   s.TrustProxy,
+		CORSOrigins:  s.CORSOrigins,
+		CacheSize:    s.CacheSize,
+		CacheTTL:     s.CacheTTL,
+		Store:        results,
+		Runs:         runs,
+
+		Numbers:         numbers,
+		CanonicalOutput: s.CanonicalOutput,
+		Recorder:        s.makeRecorder(),
+		ChartThinAbove:  s.ChartThinAbove,
+
+		SessionSecret: s.SessionSecret,
+		APIKeys:       apiKeys,
+		TLS:           tlsSetup,
+		RedirectPort:  s.RedirectPort,
+		GRPCPort:      s.GRPCPort,
 		NumService: &service.Service{
 			Solvers: []solver.Interface{
 				&solver.RungeKutta{F: fxy},
@@ -66,6 +213,111 @@ func (s *Server) Execute(_ []string) error {
 			Plotter: graph.Plotter{},
 		},
 	}
-	srv.Run(s.Port)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+		sig := <-stop
+		log.Printf("[INFO] %s signal is received", sig)
+		cancel()
+	}()
+
+	if err := srv.Run(ctx, s.Port); err != nil {
+		return errors.Wrap(err, "failed to run server")
+	}
 	return nil
 }
+
+// defaultDevWebRoot is the directory of assets in the development mode, relative to the root of the repository
+const defaultDevWebRoot = "app/web"
+
+// loadAssets loads the embedded web assets, overridden by files of the web root, or, unless assets
+// are embedded, reads them only from the web root and reloads them on changes
+func (s *Server) loadAssets() (*web.Assets, error) {
+	if s.WebEmbedded {
+		return web.New(s.WebRoot)
+	}
+	root := s.WebRoot
+	if root == "" {
+		root = defaultDevWebRoot
+	}
+	log.Printf("[INFO] web assets are read from %s and reloaded on changes", root)
+	return web.Dev(root)
+}
+
+// setupTLS prepares https, if either certificate files or autocert domains are set, nil is returned otherwise
+func (s *Server) setupTLS() (*rest.TLSSetup, error) {
+	cfg := rest.TLS{CertFile: s.TLSCert, KeyFile: s.TLSKey, Domains: s.Autocert, CacheDir: s.AutocertDir, Email: s.AutocertMail}
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	setup, err := cfg.Setup()
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Autocert) > 0 {
+		log.Printf("[INFO] https is enabled, certificates of %s are obtained from Let's Encrypt", strings.Join(s.Autocert, ", "))
+		if s.RedirectPort == 0 {
+			log.Printf("[WARN] http redirect listener is disabled, http-01 challenges of Let's Encrypt can't be answered")
+		}
+		return setup, nil
+	}
+	log.Printf("[INFO] https is enabled with certificate %s", s.TLSCert)
+	return setup, nil
+}
+
+// makeStore makes the store of saved results, in bolt db file, if it is set, otherwise in memory
+func (s *Server) makeStore() (store.Interface, error) {
+	if s.ResultsDB == "" {
+		return &store.Memory{MaxEntries: s.ResultsMax, TTL: s.ResultsTTL}, nil
+	}
+	return store.NewBolt(s.ResultsDB, s.ResultsMax, s.ResultsTTL)
+}
+
+// makeRuns makes the store of runs of sessions, in bolt db file, if it is set, otherwise in memory
+func (s *Server) makeRuns() (store.Runs, error) {
+	if s.HistoryDB == "" {
+		return &store.MemoryRuns{MaxEntries: s.HistoryMax}, nil
+	}
+	return store.NewBoltRuns(s.HistoryDB, s.HistoryMax)
+}
+
+// makeRecorder makes the recorder of failed solves, if the directory is set, nil is returned otherwise
+func (s *Server) makeRecorder() *api.RunRecorder {
+	if s.RecordDir == "" {
+		return nil
+	}
+	log.Printf("[INFO] failed solves are recorded to %s, up to %d MB", s.RecordDir, s.RecordMaxMB)
+	return &api.RunRecorder{Dir: s.RecordDir, MaxSize: int64(s.RecordMaxMB) << 20}
+}
+
+// loadAPIKeys parses api keys from the options and the file, blank lines and lines, started with #, are skipped
+func (s *Server) loadAPIKeys() ([]rest.APIKey, error) {
+	entries := append([]string{}, s.APIKeys...)
+	if s.APIKeysFile != "" {
+		b, err := ioutil.ReadFile(s.APIKeysFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s", s.APIKeysFile)
+		}
+		for _, line := range strings.Split(string(b), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				entries = append(entries, line)
+			}
+		}
+	}
+
+	keys := make([]rest.APIKey, 0, len(entries))
+	for _, e := range entries {
+		key, err := rest.ParseAPIKey(e)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) > 0 {
+		log.Printf("[INFO] api keys are required, %d keys loaded", len(keys))
+	}
+	return keys, nil
+}
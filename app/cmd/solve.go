@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"github.com/Semior001/decompract/app/report"
+	"github.com/Semior001/decompract/app/solve"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ProblemOpts describes the problem by the preset or the problem file and flags,
+// flags, that are set, override values of the preset or the file
+type ProblemOpts struct {
+	Preset  string             `long:"preset" description:"name of the built-in problem, e.g. canonical"`
+	File    string             `long:"file" description:"problem file in yaml or json"`
+	F       string             `long:"f" description:"f(x,y) = y'"`
+	Exact   string             `long:"exact" description:"y(x,c), the exact solution"`
+	C       string             `long:"c" description:"C(x0,y0), the constant for the exact solution"`
+	Params  map[string]float64 `long:"param" description:"named constant of formulas as name:value, repeatable"`
+	X0      float64            `long:"x0" description:"start of the interval"`
+	Y0      float64            `long:"y0" description:"value of y at x0"`
+	X1      float64            `long:"x1" description:"end of the interval"`
+	XEnd    float64            `long:"xend" description:"end of the interval, the same as x1"`
+	N       count              `long:"n" description:"number of steps, might be in the scientific notation, e.g. 1e6"`
+	Step    float64            `long:"step" description:"step size, used if n is not set"`
+	Methods []string           `long:"method" description:"method to solve with, repeatable"`
+	// NByMethod solves each method on its own grid, e.g. to compare methods by the cost instead of the step
+	NByMethod map[string]int `long:"n-by-method" description:"number of steps of the method as method:n instead of n and step, repeatable"`
+}
+
+// OutputOpts describes the format of the report, printed by the command
+type OutputOpts struct {
+	Format    string `long:"format" description:"format of the report: csv, json, table or markdown (default: csv for solve, markdown for matrix, table for the rest)"`
+	Precision int    `long:"precision" description:"significant digits of numbers in the report (default: as the report prefers)"`
+}
+
+// output overrides the output with flags, that are set, the format is def, if neither of them sets it
+func (o OutputOpts) output(out report.Output, def string) report.Output {
+	if o.Format != "" {
+		out.Format = o.Format
+	}
+	if out.Format == "" {
+		out.Format = def
+	}
+	if o.Precision != 0 {
+		out.Precision = o.Precision
+	}
+	return out
+}
+
+// Solve solves the initial value problem and prints the solution, without starting the server
+type Solve struct {
+	ProblemOpts
+	OutputOpts
+	Out   string `long:"out" description:"file to write the solution to, - for stdout (default: -)"`
+	Stats bool   `long:"stats" description:"print the summary of each method after the solution, in table and markdown formats"`
+
+	stdout io.Writer // stdout of the process, if nil
+
+	CommonOpts
+}
+
+// Execute solves the problem and writes the solution, the output file is not touched, if solving fails
+func (s *Solve) Execute(_ []string) error {
+	prob, out, err := s.problem()
+	if err != nil {
+		return err
+	}
+	if len(prob.Methods) == 0 {
+		prob.Methods = []string{"rk4"}
+	}
+	out = s.output(out, report.FormatCSV)
+	if s.Out != "" {
+		out.Out = s.Out
+	}
+	if s.Stats {
+		out.Stats = true
+	}
+	if out.Out == "" {
+		out.Out = "-"
+	}
+
+	buf := &bytes.Buffer{}
+	// the number of steps is up to the user, as there are no other clients to share resources with
+	if err := solve.Solve(context.Background(), prob, solve.Limits{MaxSteps: math.MaxInt32}, out, buf); err != nil {
+		return err
+	}
+
+	if out.Out != "-" {
+		if err := ioutil.WriteFile(out.Out, buf.Bytes(), 0600); err != nil {
+			return errors.Wrapf(err, "failed to write %s", out.Out)
+		}
+		return nil
+	}
+	return writeStdout(s.stdout, buf)
+}
+
+// writeStdout writes the buffer to stdout, os.Stdout is used, if it is nil
+func writeStdout(stdout io.Writer, buf *bytes.Buffer) error {
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	if _, err := buf.WriteTo(stdout); err != nil {
+		return errors.Wrap(err, "failed to write to stdout")
+	}
+	return nil
+}
+
+// problem reads the preset or the problem file, if any of them is set, and overrides its values with flags
+func (o ProblemOpts) problem() (prob solve.Problem, out report.Output, err error) {
+	switch {
+	case o.X1 != 0 && o.XEnd != 0 && o.X1 != o.XEnd:
+		return solve.Problem{}, report.Output{}, errors.Errorf("x1 and xend are the same flag, got %v and %v", o.X1, o.XEnd)
+	case o.Preset != "" && o.File != "":
+		return solve.Problem{}, report.Output{}, errors.New("preset and problem file are mutually exclusive")
+	case o.Preset != "":
+		p, ok := solve.Presets[o.Preset]
+		if !ok {
+			return solve.Problem{}, report.Output{}, errors.Errorf("unknown preset %q, available: %s", o.Preset,
+				strings.Join(presetNames(), ", "))
+		}
+		prob = p
+		prob.Params = nil // the preset is shared, params of flags are copied below
+		for name, v := range p.Params {
+			setParam(&prob, name, v)
+		}
+	case o.File != "":
+		if prob, out, err = solve.LoadProblem(o.File); err != nil {
+			return solve.Problem{}, report.Output{}, err
+		}
+	}
+
+	for _, v := range []struct {
+		dst *string
+		val string
+	}{{&prob.F, o.F}, {&prob.Exact, o.Exact}, {&prob.C, o.C}} {
+		if v.val != "" {
+			*v.dst = v.val
+		}
+	}
+	for _, v := range []struct {
+		dst *float64
+		val float64
+	}{{&prob.X0, o.X0}, {&prob.Y0, o.Y0}, {&prob.XEnd, o.X1}, {&prob.XEnd, o.XEnd}} {
+		if v.val != 0 {
+			*v.dst = v.val
+		}
+	}
+	// n, step and numbers of steps of methods are exclusive, so the flag replaces any of them
+	if o.N != 0 || o.Step != 0 {
+		prob.N, prob.Step, prob.NByMethod = int(o.N), o.Step, nil
+	}
+	if len(o.NByMethod) > 0 {
+		prob.N, prob.Step, prob.NByMethod = 0, 0, o.NByMethod
+	}
+	if len(o.Methods) > 0 {
+		prob.Methods = o.Methods
+	}
+	for name, v := range o.Params {
+		setParam(&prob, name, v)
+	}
+
+	if prob.F == "" {
+		return solve.Problem{}, report.Output{}, errors.New("f must be set by the flag, the preset or the problem file")
+	}
+	return prob, out, nil
+}
+
+// count is the whole number, that might be in the scientific notation
+type count int
+
+// UnmarshalFlag parses the value of the flag
+func (c *count) UnmarshalFlag(value string) error {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return errors.Wrapf(err, "can't parse %q", value)
+	}
+	if v != math.Trunc(v) || math.Abs(v) > math.MaxInt32 {
+		return errors.Errorf("%q is not a whole number", value)
+	}
+	*c = count(v)
+	return nil
+}
+
+// setParam sets the parameter of the problem, making the map of parameters, if it is nil
+func setParam(prob *solve.Problem, name string, v float64) {
+	if prob.Params == nil {
+		prob.Params = map[string]float64{}
+	}
+	prob.Params[name] = v
+}
+
+// presetNames returns the sorted names of presets
+func presetNames() []string {
+	res := make([]string, 0, len(solve.Presets))
+	for name := range solve.Presets {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
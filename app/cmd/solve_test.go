@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSolve_Execute(t *testing.T) {
+	out := &bytes.Buffer{}
+	s := Solve{ProblemOpts: ProblemOpts{F: "x^2 - 2*y", X0: 0, Y0: 1, X1: 1, N: 10, Methods: []string{"rk4", "euler"}},
+		Out: "-", OutputOpts: OutputOpts{Format: "csv"}, stdout: out}
+	require.NoError(t, s.Execute(nil))
+
+	rows, err := csv.NewReader(out).ReadAll()
+	require.NoError(t, err)
+	require.NotEmpty(t, rows)
+	assert.Equal(t, []string{"x", "rk4", "euler"}, rows[0])
+
+	f := func(x, y float64) (float64, error) { return x*x - 2*y, nil }
+	step, err := num.CalculateStepSize(10, 0, 1)
+	require.NoError(t, err)
+	rk4, err := solver.Collect(&solver.RungeKutta{F: f}, step, 0, 1, 1)
+	require.NoError(t, err)
+	euler, err := solver.Collect(&solver.Euler{F: f}, step, 0, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, rows, len(rk4.Points)+1)
+	for i, pt := range rk4.Points {
+		assert.Equal(t, []float64{pt.X, pt.Y, euler.Points[i].Y}, parseRow(t, rows[i+1]), "row %d", i)
+	}
+}
+
+func TestSolve_ExecuteXEnd(t *testing.T) {
+	byX1, byXEnd := &bytes.Buffer{}, &bytes.Buffer{}
+	s := Solve{ProblemOpts: ProblemOpts{F: "x^2 - 2*y", Y0: 1, X1: 1, N: 10}, Out: "-", stdout: byX1}
+	require.NoError(t, s.Execute(nil))
+	s = Solve{ProblemOpts: ProblemOpts{F: "x^2 - 2*y", Y0: 1, XEnd: 1, N: 10}, Out: "-", stdout: byXEnd}
+	require.NoError(t, s.Execute(nil))
+	assert.NotEmpty(t, byX1.String())
+	assert.Equal(t, byX1.String(), byXEnd.String())
+}
+
+func TestSolve_ExecuteTable(t *testing.T) {
+	out := &bytes.Buffer{}
+	s := Solve{ProblemOpts: ProblemOpts{F: "y", Exact: "c*exp(x)", C: "y0/exp(x0)", X0: 0, Y0: 1, X1: 1, Step: 0.5,
+		Methods: []string{"euler"}}, Out: "-", OutputOpts: OutputOpts{Format: "table"}, stdout: out}
+	require.NoError(t, s.Execute(nil))
+	assert.Equal(t, "x    euler  exact\n0    1      1\n0.5  1.5    1.6487212707001282\n1    2.25   2.718281828459045\n",
+		out.String())
+}
+
+func TestSolve_ExecuteStats(t *testing.T) {
+	out := &bytes.Buffer{}
+	s := Solve{ProblemOpts: ProblemOpts{F: "x^2 - 2*y", X0: 0, Y0: 1, X1: 1, N: 10, Methods: []string{"euler"}}, Out: "-",
+		OutputOpts: OutputOpts{Format: "table", Precision: 5}, Stats: true, stdout: out}
+	require.NoError(t, s.Execute(nil))
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	require.Len(t, lines, 15)
+	assert.Equal(t, "", lines[12], "the summary is separated by the blank line")
+	assert.Equal(t, []string{"method", "points", "min_x", "min", "max_x", "max", "final_x", "final", "evals", "took",
+		"warnings"}, strings.Fields(lines[13]))
+	assert.Equal(t, []string{"euler", "11", "0.8", "0.27502", "0", "1", "1", "0.30821", "10"}, strings.Fields(lines[14])[:9])
+
+	s.Format = "csv"
+	assert.EqualError(t, s.Execute(nil), "stats are not supported in csv format, as it has the single table")
+}
+
+func TestSolve_ExecuteFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "decompract")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	out := &bytes.Buffer{}
+	file := filepath.Join(dir, "solution.csv")
+	s := Solve{ProblemOpts: ProblemOpts{F: "x", X1: 1, N: 2, Methods: []string{"ieuler"}}, Out: file, OutputOpts: OutputOpts{Format: "csv"}, stdout: out}
+	require.NoError(t, s.Execute(nil))
+	assert.Empty(t, out.String(), "stdout is not used, if the file is set")
+	b, err := ioutil.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "x,ieuler\n0,0\n0.5,0.125\n1,0.5\n", string(b))
+
+	s.Out = filepath.Join(dir, "unknown", "solution.csv")
+	assert.Error(t, s.Execute(nil))
+}
+
+func TestSolve_ExecuteProblemFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "decompract")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	problem := filepath.Join(dir, "problem.yaml")
+	solution := filepath.Join(dir, "solution.txt")
+	require.NoError(t, ioutil.WriteFile(problem, []byte(`# y' = ky, y(0) = 1
+f: k*y
+exact: c*exp(k*x)
+c: y0/exp(k*x0)
+params:
+  k: 1
+x0: 0
+y0: 1
+x_end: 1
+step: 0.5
+methods:
+  - euler
+output:
+  format: table
+  out: `+solution+`
+`), 0600))
+
+	out := &bytes.Buffer{}
+	s := Solve{ProblemOpts: ProblemOpts{File: problem}, stdout: out}
+	require.NoError(t, s.Execute(nil))
+	assert.Empty(t, out.String())
+	b, err := ioutil.ReadFile(solution)
+	require.NoError(t, err)
+	assert.Equal(t, "x    euler  exact\n0    1      1\n0.5  1.5    1.6487212707001282\n1    2.25   2.718281828459045\n", string(b))
+
+	// flags override values of the file
+	s = Solve{ProblemOpts: ProblemOpts{File: problem, N: 1, Params: map[string]float64{"k": 2}}, Out: "-", OutputOpts: OutputOpts{Format: "csv"}, stdout: out}
+	require.NoError(t, s.Execute(nil))
+	assert.Equal(t, "x,euler,exact\n0,1,1\n1,3,7.38905609893065\n", out.String())
+
+	// minimal json file relies on defaults: rk4 written to stdout in csv
+	problem = filepath.Join(dir, "problem.json")
+	require.NoError(t, ioutil.WriteFile(problem, []byte(`{"f": "x", "x_end": 1, "n": 2}`), 0600))
+	out.Reset()
+	s = Solve{ProblemOpts: ProblemOpts{File: problem}, stdout: out}
+	require.NoError(t, s.Execute(nil))
+	assert.Equal(t, "x,rk4\n0,0\n0.5,0.125\n1,0.5\n", out.String())
+}
+
+func TestSolve_ExecuteProblemFileErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "decompract")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	tbl := []struct {
+		file    string
+		content string
+		err     string
+	}{
+		{"unknown.yaml", "f: x\nx_end: 1\nn: 2\nmethod: rk4\n", "line 4: field method not found"},
+		{"output.yml", "f: x\nx_end: 1\nn: 2\noutput:\n  fmt: csv\n", "line 5: field fmt not found"},
+		{"unknown.json", `{"f": "x", "x_end": 1, "n": 2, "x1": 1}`, `json: unknown field "x1"`},
+		{"broken.json", `{"f": "x",`, "failed to decode"},
+		{"save.yaml", "f: x\nx_end: 1\nn: 2\nsave: true\n", "save is not supported"},
+		{"params.yaml", "f: x\nx_end: 1\nn: 2\nparams:\n  y: 1\n", `params: "y" is reserved`},
+		{"format.yaml", "f: x\nx_end: 1\nn: 2\noutput:\n  format: xml\n", `unknown format "xml"`},
+		{"nof.yaml", "x_end: 1\nn: 2\n", "f must be set"},
+	}
+	for _, tt := range tbl {
+		file := filepath.Join(dir, tt.file)
+		require.NoError(t, ioutil.WriteFile(file, []byte(tt.content), 0600))
+		out := &bytes.Buffer{}
+		s := Solve{ProblemOpts: ProblemOpts{File: file}, stdout: out}
+		err := s.Execute(nil)
+		require.Error(t, err, tt.file)
+		assert.Contains(t, err.Error(), tt.err, tt.file)
+		assert.Empty(t, out.String())
+	}
+
+	s := Solve{ProblemOpts: ProblemOpts{File: filepath.Join(dir, "missing.yaml")}}
+	assert.EqualError(t, s.Execute(nil), "failed to read problem file: open "+filepath.Join(dir, "missing.yaml")+
+		": no such file or directory")
+}
+
+func TestSolve_ExecuteErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "decompract")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	tbl := []struct {
+		s   Solve
+		err string
+	}{
+		{Solve{ProblemOpts: ProblemOpts{F: "x", X1: 1, N: 10, Methods: []string{"rk5"}}}, `methods: unknown method "rk5"`},
+		{Solve{ProblemOpts: ProblemOpts{F: "x", X1: 1, Methods: []string{"rk4"}}}, "n: either n or step must be set"},
+		{Solve{ProblemOpts: ProblemOpts{F: "x +* y", X1: 1, N: 10, Methods: []string{"rk4"}}}, "f: can't parse f(x,y)"},
+		{Solve{ProblemOpts: ProblemOpts{F: "z", X1: 1, N: 10, Methods: []string{"rk4"}}},
+			`f: can't parse f(x,y): unknown variable "z", available: x, y at position 1`},
+		{Solve{ProblemOpts: ProblemOpts{F: "x", Exact: "z", C: "y0", X1: 1, N: 10, Methods: []string{"rk4"}}},
+			`exact: can't parse y(x,c): unknown variable "z", available: x, c at position 1`},
+		{Solve{ProblemOpts: ProblemOpts{F: "x", X1: 1, N: 10, Methods: []string{"rk4"}}, OutputOpts: OutputOpts{Format: "xml"}}, `unknown format "xml"`},
+		{Solve{ProblemOpts: ProblemOpts{F: "x", X1: 1, XEnd: 2, N: 10, Methods: []string{"rk4"}}}, "x1 and xend are the same flag, got 1 and 2"},
+	}
+	for _, tt := range tbl {
+		out := &bytes.Buffer{}
+		tt.s.stdout = out
+		tt.s.Out = filepath.Join(dir, "solution.csv")
+		if tt.s.Format == "" {
+			tt.s.Format = "csv"
+		}
+		err := tt.s.Execute(nil)
+		require.Error(t, err, tt.err)
+		assert.Contains(t, err.Error(), tt.err)
+		assert.Empty(t, out.String())
+		assert.NoFileExists(t, tt.s.Out, "the file is not written, if solving fails")
+	}
+}
+
+func TestCount_UnmarshalFlag(t *testing.T) {
+	var c count
+	require.NoError(t, c.UnmarshalFlag("1e6"))
+	assert.Equal(t, count(1000000), c)
+	require.NoError(t, c.UnmarshalFlag("42"))
+	assert.Equal(t, count(42), c)
+
+	assert.EqualError(t, c.UnmarshalFlag("1.5"), `"1.5" is not a whole number`)
+	assert.EqualError(t, c.UnmarshalFlag("1e20"), `"1e20" is not a whole number`)
+	assert.Error(t, c.UnmarshalFlag("ten"))
+}
+
+func parseRow(t *testing.T, row []string) []float64 {
+	res := make([]float64, 0, len(row))
+	for _, v := range row {
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		require.NoError(t, err)
+		res = append(res, f)
+	}
+	return res
+}
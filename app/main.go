@@ -4,6 +4,7 @@ package main
 //noinspection GoRedundantImportAlias
 import (
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/Semior001/decompract/app/cmd"
@@ -13,42 +14,60 @@ import (
 
 // Opts describes cli arguments and flags to execute a command
 type Opts struct {
-	cmd.Server
+	ServerCmd  cmd.Server  `command:"server" description:"run the web server"`
+	SolveCmd   cmd.Solve   `command:"solve" description:"solve the problem and print the solution without the server"`
+	CompareCmd cmd.Compare `command:"compare" description:"compare methods on the problem and print the report without the server"`
+	BenchCmd   cmd.Bench   `command:"bench" description:"measure the performance of methods on the problem"`
+	PipeCmd    cmd.Pipe    `command:"pipe" description:"solve problems from stdin as json lines and write results to stdout"`
+	ErrorsCmd  cmd.Errors  `command:"errors" description:"sweep the number of steps and print errors and orders of convergence of methods"`
+	MatrixCmd  cmd.Matrix  `command:"matrix" description:"compare methods on presets and print the matrix of errors and times"`
+	RefineCmd  cmd.Refine  `command:"refine" description:"double the number of steps of methods until their solutions converge"`
+	OpenAPICmd cmd.OpenAPI `command:"openapi" description:"print the OpenAPI description of the REST API"`
+
 	Dbg bool `long:"dbg" env:"DEBUG" description:"turn on debug mode"`
 }
 
-var version = "unknown"
+var (
+	version  = "unknown"
+	revision = "unknown"
+)
 
 func main() {
-	fmt.Printf("decompract version: %s\n", version)
-	var opts Opts
+	// the solution might be printed to stdout, so the rest of output goes to stderr
+	fmt.Fprintf(os.Stderr, "decompract version: %s, revision: %s\n", version, revision)
+	// assets are embedded, unless WEB_EMBEDDED=false
+	opts := Opts{ServerCmd: cmd.Server{WebEmbedded: true}}
 	p := flags.NewParser(&opts, flags.Default)
+	p.CommandHandler = func(command flags.Commander, args []string) error {
+		out := io.Writer(os.Stdout)
+		switch command.(type) {
+		case *cmd.Solve, *cmd.Compare, *cmd.Bench, *cmd.Pipe, *cmd.Errors, *cmd.Matrix, *cmd.Refine, *cmd.OpenAPI:
+			out = os.Stderr
+		}
+		setupLog(opts.Dbg, out)
+
+		// commands implements CommonOptionsCommander to allow passing set of extra options defined for all commands
+		c := command.(cmd.CommonOptionsCommander)
+		c.SetCommon(cmd.CommonOpts{
+			Version:  version,
+			Revision: revision,
+		})
+		return c.Execute(args)
+	}
 
-	// after failure command does not return non-zero code
+	// errors of commands are printed by the parser
 	if _, err := p.Parse(); err != nil {
 		if flagsErr, ok := err.(*flags.Error); ok && flagsErr.Type == flags.ErrHelp {
 			os.Exit(0)
-		} else {
-			os.Exit(1)
 		}
-	}
-
-	setupLog(opts.Dbg)
-
-	// commands implements CommonOptionsCommander to allow passing set of extra options defined for all commands
-	opts.SetCommon(cmd.CommonOpts{
-		Version: version,
-	})
-
-	if err := opts.Execute(os.Args[1:]); err != nil {
-		log.Printf("[ERROR] failed to execute command %+v", err)
+		os.Exit(1)
 	}
 }
 
-func setupLog(dbg bool) {
+func setupLog(dbg bool, out io.Writer) {
 	if dbg {
-		log.Setup(log.Debug, log.CallerFile, log.CallerFunc, log.Msec, log.LevelBraces)
+		log.Setup(log.Debug, log.CallerFile, log.CallerFunc, log.Msec, log.LevelBraces, log.Out(out))
 		return
 	}
-	log.Setup(log.Msec, log.LevelBraces)
+	log.Setup(log.Msec, log.LevelBraces, log.Out(out))
 }
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersion_Default(t *testing.T) {
+	// version and revision are injected with ldflags, test binary is built without them
+	assert.Equal(t, "unknown", version)
+	assert.Equal(t, "unknown", revision)
+}
@@ -0,0 +1,156 @@
+// Package analyzer measures truncation errors of numerical methods against the exact solution of the problem,
+// local errors of single steps and global errors of whole solutions, by x and by the number of steps,
+// errors are lines, so they are drawn alongside the solution
+package analyzer
+
+import (
+	"math"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/pkg/errors"
+)
+
+// Exact is the exact solution y(x) of the problem, e.g. the general solution with its constant,
+// or the interpolated reference solution
+type Exact func(x float64) (float64, error)
+
+// ExactOf returns the exact solution of the solver, that passes through (x0, y0), the constant,
+// that is not finite, is solver.ErrConstant
+func ExactOf(e *solver.Exact, x0, y0 float64) (Exact, error) {
+	c, err := e.Constant(x0, y0)
+	if err != nil {
+		return nil, err
+	}
+	return func(x float64) (float64, error) { return e.F(x, c) }, nil
+}
+
+// Errors are truncation errors of the method, lines are named after the method
+type Errors struct {
+	// Local are errors of single steps, each of them is made from the exact solution at the previous node,
+	// so they are not accumulated, e.g. O(h^5) for Runge-Kutta's method
+	Local num.Line
+	// Global are differences between the solution and the exact one, they are accumulated by steps,
+	// e.g. O(h^4) for Runge-Kutta's method
+	Global num.Line
+}
+
+// Analyze solves the problem with the step and measures errors of the solution at its nodes,
+// the first node has no errors, as the solution starts at the exact initial value
+func Analyze(s solver.Interface, exact Exact, step, x0, y0, xEnd float64) (Errors, error) {
+	line, err := solver.Collect(s, step, x0, y0, xEnd)
+	if err != nil {
+		return Errors{}, errors.Wrapf(err, "failed to solve with %s", s.Name())
+	}
+	gte, err := Global(line.Points, exact)
+	if err != nil {
+		return Errors{}, errors.Wrapf(err, "failed to calculate global errors of %s", s.Name())
+	}
+	lte, err := Local(s, exact, line.Points)
+	if err != nil {
+		return Errors{}, errors.Wrapf(err, "failed to calculate local errors of %s", s.Name())
+	}
+	return Errors{
+		Local:  num.Line{Name: s.Name(), Points: lte},
+		Global: num.Line{Name: s.Name(), Points: gte},
+	}, nil
+}
+
+// Global returns absolute differences between points of the solution and the exact solution,
+// the solution, that diverged, has no errors
+func Global(pts []num.Point, exact Exact) ([]num.Point, error) {
+	res := make([]num.Point, 0, len(pts))
+	for _, pt := range pts {
+		if !isFinite(pt.Y) {
+			return nil, errors.Errorf("diverged at x=%.4f", pt.X)
+		}
+		e, err := diff(exact, pt)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, num.Point{X: pt.X, Y: e})
+	}
+	return res, nil
+}
+
+// Local returns local errors of steps of the solver between nodes of the solution, each step is solved
+// from the exact solution at the node to the next one, only x of nodes are used, so nodes of the solution,
+// that diverged, are fine as well
+func Local(s solver.Interface, exact Exact, nodes []num.Point) ([]num.Point, error) {
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+	res := make([]num.Point, 0, len(nodes))
+	res = append(res, num.Point{X: nodes[0].X})
+	for i := 1; i < len(nodes); i++ {
+		x0, x1 := nodes[i-1].X, nodes[i].X
+		y0, err := exact(x0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to calculate exact solution at x=%.4f", x0)
+		}
+		line, err := solver.Collect(s, x1-x0, x0, y0, x1)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to make step %d", i)
+		}
+		if len(line.Points) != 2 {
+			return nil, errors.Wrapf(solver.ErrPointsCount, "step %d drew %d points, expected 2", i, len(line.Points))
+		}
+		e, err := diff(exact, num.Point{X: x1, Y: line.Points[1].Y})
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, num.Point{X: x1, Y: e})
+	}
+	return res, nil
+}
+
+// ByN measures max errors of solutions with each number of steps from nMin to nMax, points of lines
+// are (n, max error), e.g. to draw them in log-log scale, where the slope of the global error is the order
+// of the method
+func ByN(s solver.Interface, exact Exact, nMin, nMax int, x0, y0, xEnd float64) (Errors, error) {
+	if nMin < 1 || nMax < nMin {
+		return Errors{}, errors.Errorf("numbers of steps must be 1 <= n_min <= n_max, got n_min=%d and n_max=%d",
+			nMin, nMax)
+	}
+	res := Errors{
+		Local:  num.Line{Name: s.Name(), Points: make([]num.Point, 0, nMax-nMin+1)},
+		Global: num.Line{Name: s.Name(), Points: make([]num.Point, 0, nMax-nMin+1)},
+	}
+	for n := nMin; n <= nMax; n++ {
+		step, err := num.CalculateStepSize(n, x0, xEnd)
+		if err != nil {
+			return Errors{}, errors.Wrapf(err, "failed to calculate step for n=%d", n)
+		}
+		errs, err := Analyze(s, exact, step, x0, y0, xEnd)
+		if err != nil {
+			return Errors{}, errors.Wrapf(err, "n=%d", n)
+		}
+		res.Local.Points = append(res.Local.Points, num.Point{X: float64(n), Y: maxOf(errs.Local.Points)})
+		res.Global.Points = append(res.Global.Points, num.Point{X: float64(n), Y: maxOf(errs.Global.Points)})
+	}
+	return res, nil
+}
+
+// diff returns the absolute difference between the point and the exact solution at its x
+func diff(exact Exact, pt num.Point) (float64, error) {
+	y, err := exact(pt.X)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to calculate exact solution at x=%.4f", pt.X)
+	}
+	e := math.Abs(pt.Y - y)
+	if !isFinite(e) {
+		return 0, errors.Errorf("error is not finite at x=%.4f", pt.X)
+	}
+	return e, nil
+}
+
+// maxOf returns the max y of points, zero for no points
+func maxOf(pts []num.Point) float64 {
+	res := 0.0
+	for _, p := range pts {
+		res = math.Max(res, p.Y)
+	}
+	return res
+}
+
+func isFinite(v float64) bool { return !math.IsNaN(v) && !math.IsInf(v, 0) }
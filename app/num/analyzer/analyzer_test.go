@@ -0,0 +1,105 @@
+package analyzer
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// growth is y' = y with the exact solution y = exp(x) through (0, 1)
+func growth(_, y float64) (float64, error) { return y, nil }
+
+func exp(x float64) (float64, error) { return math.Exp(x), nil }
+
+func TestAnalyze(t *testing.T) {
+	errs, err := Analyze(&solver.Euler{F: growth}, exp, 0.1, 0, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Euler's method", errs.Local.Name)
+	assert.Equal(t, "Euler's method", errs.Global.Name)
+	require.Len(t, errs.Local.Points, 11)
+	require.Len(t, errs.Global.Points, 11)
+	assert.Equal(t, num.Point{X: 0, Y: 0}, errs.Local.Points[0])
+	assert.Equal(t, num.Point{X: 0, Y: 0}, errs.Global.Points[0])
+
+	// each step of Euler's method from exp(x) gives exp(x)*1.1 instead of exp(x+0.1)
+	for i := 1; i <= 10; i++ {
+		x := errs.Local.Points[i].X
+		assert.InDelta(t, 0.1*float64(i), x, 1e-12)
+		assert.InDelta(t, math.Exp(x-0.1)*(math.Exp(0.1)-1.1), errs.Local.Points[i].Y, 1e-12, "x=%v", x)
+	}
+	assert.InDelta(t, math.E-math.Pow(1.1, 10), errs.Global.Points[10].Y, 1e-12)
+	assert.Greater(t, errs.Global.Points[10].Y, errs.Local.Points[10].Y, "global errors are accumulated")
+}
+
+func TestByN(t *testing.T) {
+	for _, tt := range []struct {
+		s             solver.Interface
+		local, global float64 // orders of errors
+	}{
+		{&solver.Euler{F: growth}, 2, 1},
+		{&solver.ImprovedEuler{F: growth}, 3, 2},
+		{&solver.RungeKutta{F: growth}, 5, 4},
+	} {
+		errs, err := ByN(tt.s, exp, 10, 20, 0, 1, 1)
+		require.NoError(t, err)
+		require.Len(t, errs.Global.Points, 11)
+		assert.Equal(t, 10.0, errs.Global.Points[0].X)
+		assert.Equal(t, 20.0, errs.Local.Points[10].X)
+		order := func(l num.Line) float64 { return math.Log2(l.Points[0].Y / l.Points[10].Y) }
+		assert.InDelta(t, tt.local, order(errs.Local), 0.1, "%s: local order", tt.s.Name())
+		assert.InDelta(t, tt.global, order(errs.Global), 0.1, "%s: global order", tt.s.Name())
+	}
+
+	_, err := ByN(&solver.Euler{F: growth}, exp, 0, 10, 0, 1, 1)
+	assert.EqualError(t, err, "numbers of steps must be 1 <= n_min <= n_max, got n_min=0 and n_max=10")
+	_, err = ByN(&solver.Euler{F: growth}, exp, 10, 5, 0, 1, 1)
+	assert.Error(t, err)
+}
+
+func TestAnalyze_Errors(t *testing.T) {
+	errF := errors.New("failed")
+	_, err := Analyze(&solver.Euler{F: func(x, y float64) (float64, error) { return 0, errF }}, exp, 0.1, 0, 1, 1)
+	assert.True(t, errors.Is(err, errF))
+	assert.Contains(t, err.Error(), "failed to solve with Euler's method")
+
+	_, err = Analyze(&solver.Euler{F: func(x, y float64) (float64, error) { return 1 / (0.5 - x), nil }},
+		exp, 0.1, 0, 1, 1)
+	assert.EqualError(t, err, "failed to calculate global errors of Euler's method: diverged at x=0.6000")
+
+	_, err = Analyze(&solver.Euler{F: growth}, func(x float64) (float64, error) {
+		if x > 0.5 {
+			return 0, errF
+		}
+		return math.Exp(x), nil
+	}, 0.1, 0, 1, 1)
+	assert.True(t, errors.Is(err, errF))
+	assert.Contains(t, err.Error(), "failed to calculate exact solution at x=0.6000")
+
+	_, err = Analyze(&solver.Euler{F: growth}, func(x float64) (float64, error) { return math.Inf(1), nil }, 0.1, 0, 1, 1)
+	assert.EqualError(t, err, "failed to calculate global errors of Euler's method: error is not finite at x=0.0000")
+
+	pts, err := Local(&solver.Euler{F: growth}, exp, nil)
+	require.NoError(t, err)
+	assert.Empty(t, pts)
+}
+
+func TestExactOf(t *testing.T) {
+	e := &solver.Exact{
+		F: func(x, c float64) (float64, error) { return c * math.Exp(x), nil },
+		C: func(x0, y0 float64) (float64, error) { return y0 / math.Exp(x0), nil },
+	}
+	exact, err := ExactOf(e, 1, 2)
+	require.NoError(t, err)
+	y, err := exact(0)
+	require.NoError(t, err)
+	assert.InDelta(t, 2/math.E, y, 1e-12)
+
+	e.C = func(x0, y0 float64) (float64, error) { return 1 / y0, nil }
+	_, err = ExactOf(e, 0, 0)
+	assert.True(t, errors.Is(err, solver.ErrConstant))
+}
@@ -0,0 +1,118 @@
+// Package export provides writers of solutions to the formats of documents and reports.
+package export
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// LaTeXTable writes lines as a booktabs-styled tabular environment,
+// first column contains x values, each next column contains y values of a line
+type LaTeXTable struct {
+	ColumnSpec string // column spec of the tabular environment, "r" for each column by default
+	Precision  int    // number of digits after the decimal point
+}
+
+// Write the table with the given lines to the writer, all lines must have the same x values
+func (t LaTeXTable) Write(w io.Writer, lines []num.Line) error {
+	if len(lines) == 0 {
+		return errors.New("no lines to export")
+	}
+	for _, line := range lines[1:] {
+		if len(line.Points) != len(lines[0].Points) {
+			return errors.Errorf("number of points are different for %s and %s", lines[0].Name, line.Name)
+		}
+	}
+
+	spec := t.ColumnSpec
+	if spec == "" {
+		spec = strings.Repeat("r", len(lines)+1)
+	}
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "\\begin{tabular}{%s}\n\\toprule\n$x$", spec)
+	for _, line := range lines {
+		fmt.Fprintf(b, " & %s", escape(line.Name))
+	}
+	b.WriteString(" \\\\\n\\midrule\n")
+
+	for i, pt := range lines[0].Points {
+		var gaps []string
+		b.WriteString(formatFloat(pt.X, t.Precision))
+		for _, line := range lines {
+			if line.Points[i].X != pt.X {
+				return errors.Errorf("x coord are different for %s and %s at i=%d", lines[0].Name, line.Name, i)
+			}
+			b.WriteString(" & ")
+			if y := line.Points[i].Y; isSpecial(y) {
+				gaps = append(gaps, fmt.Sprintf("%s is %v", line.Name, y))
+				continue
+			}
+			b.WriteString(formatFloat(line.Points[i].Y, t.Precision))
+		}
+		b.WriteString(" \\\\")
+		if len(gaps) > 0 {
+			fmt.Fprintf(b, " %% gap: %s", strings.Join(gaps, ", "))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\\bottomrule\n\\end{tabular}\n")
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return errors.Wrap(err, "failed to write table")
+	}
+	return nil
+}
+
+// PGFPlots writes each line as an \addplot coordinates block with the legend entry
+type PGFPlots struct {
+	Precision  int  // number of digits after the decimal point
+	Scientific bool // coordinates are written in the scientific notation, e.g. for logarithmic axes
+}
+
+// Write the plots of the given lines to the writer
+func (p PGFPlots) Write(w io.Writer, lines []num.Line) error {
+	b := &strings.Builder{}
+	for _, line := range lines {
+		b.WriteString("\\addplot coordinates {\n")
+		for _, pt := range line.Points {
+			if isSpecial(pt.X) || isSpecial(pt.Y) {
+				fmt.Fprintf(b, "    %% gap: (%v, %v) omitted\n", pt.X, pt.Y)
+				continue
+			}
+			if p.Scientific {
+				fmt.Fprintf(b, "    (%.*e, %.*e)\n", p.Precision, pt.X, p.Precision, pt.Y)
+				continue
+			}
+			fmt.Fprintf(b, "    (%s, %s)\n", formatFloat(pt.X, p.Precision), formatFloat(pt.Y, p.Precision))
+		}
+		fmt.Fprintf(b, "};\n\\addlegendentry{%s}\n", escape(line.Name))
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return errors.Wrap(err, "failed to write plots")
+	}
+	return nil
+}
+
+// isSpecial returns true if the value can't be written to the document
+func isSpecial(v float64) bool {
+	return math.IsNaN(v) || math.IsInf(v, 0)
+}
+
+func formatFloat(v float64, precision int) string {
+	return fmt.Sprintf("%.*f", precision, v)
+}
+
+// escape the LaTeX special characters in the text
+func escape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\textbackslash{}`, `&`, `\&`, `%`, `\%`, `$`, `\$`,
+		`#`, `\#`, `_`, `\_`, `{`, `\{`, `}`, `\}`, `^`, `\textasciicircum{}`, `~`, `\textasciitilde{}`,
+	).Replace(s)
+}
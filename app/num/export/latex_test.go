@@ -0,0 +1,76 @@
+package export
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/solver"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// canonicalLines solves y' = x^2 - 2y, y(0) = 1 on [0, 1] with h = 0.1
+func canonicalLines(t *testing.T) []num.Line {
+	f := func(x, y float64) (float64, error) { return x*x - 2.0*y, nil }
+	var lines []num.Line
+	for _, s := range []solver.Interface{&solver.Euler{F: f}, &solver.ImprovedEuler{F: f}, &solver.RungeKutta{F: f}} {
+		line, err := solver.Collect(s, 0.1, 0, 1, 1)
+		require.NoError(t, err)
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func checkGolden(t *testing.T, name string, actual []byte) {
+	path := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, ioutil.WriteFile(path, actual, 0600))
+	}
+	expected, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(actual))
+}
+
+func TestLaTeXTable_Write(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, LaTeXTable{Precision: 5}.Write(buf, canonicalLines(t)))
+	checkGolden(t, "table.tex", buf.Bytes())
+
+	// different number of points
+	err := LaTeXTable{}.Write(buf, []num.Line{{Points: []num.Point{{X: 1}}}, {}})
+	assert.Error(t, err)
+}
+
+func TestLaTeXTable_WriteGaps(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := LaTeXTable{ColumnSpec: "l|r", Precision: 1}.Write(buf, []num.Line{{
+		Name:   "y_1",
+		Points: []num.Point{{X: 0, Y: 1}, {X: 1, Y: math.NaN()}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "\\begin{tabular}{l|r}\n\\toprule\n$x$ & y\\_1 \\\\\n\\midrule\n"+
+		"0.0 & 1.0 \\\\\n1.0 &  \\\\ % gap: y_1 is NaN\n\\bottomrule\n\\end{tabular}\n", buf.String())
+}
+
+func TestPGFPlots_Write(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, PGFPlots{Precision: 5}.Write(buf, canonicalLines(t)))
+	checkGolden(t, "coordinates.tex", buf.Bytes())
+
+	buf.Reset()
+	err := PGFPlots{Precision: 2}.Write(buf, []num.Line{{
+		Name:   "blow-up",
+		Points: []num.Point{{X: 0, Y: 1}, {X: 0.5, Y: math.Inf(1)}, {X: 1, Y: 3}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "\\addplot coordinates {\n    (0.00, 1.00)\n    % gap: (0.5, +Inf) omitted\n"+
+		"    (1.00, 3.00)\n};\n\\addlegendentry{blow-up}\n", buf.String())
+}
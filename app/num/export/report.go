@@ -0,0 +1,152 @@
+package export
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// Report is the document about the solved problem, it is written as markdown or as the LaTeX article
+type Report struct {
+	Title    string
+	Problem  []Field // describes the problem, e.g. f(x,y), the interval and methods
+	Sections []Section
+}
+
+// Field is the named value of the problem
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Section is the titled table of the report with the optional chart, cells are written as they are, escaped
+type Section struct {
+	Title  string
+	Text   string // paragraph before the table
+	Header []string
+	Rows   [][]string
+	Chart  *Chart
+}
+
+// Chart is the plot of the section, lines are plotted by pgfplots in LaTeX, markdown embeds the rendered image
+type Chart struct {
+	XLabel, YLabel string
+	LogLog         bool
+	Lines          []num.Line
+	PNG            []byte // embedded into markdown as the data uri, the chart is omitted in markdown, if empty
+}
+
+// WriteMarkdown writes the report as the markdown document with tables and embedded charts
+func (r Report) WriteMarkdown(w io.Writer) error {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "# %s\n\n", r.Title)
+	for _, f := range r.Problem {
+		fmt.Fprintf(b, "- **%s**: `%s`\n", f.Name, f.Value)
+	}
+	for _, s := range r.Sections {
+		fmt.Fprintf(b, "\n## %s\n\n", s.Title)
+		if s.Text != "" {
+			fmt.Fprintf(b, "%s\n\n", s.Text)
+		}
+		if err := s.checkRows(); err != nil {
+			return err
+		}
+		if len(s.Header) > 0 {
+			writeMarkdownRow(b, s.Header)
+			b.WriteString("|" + strings.Repeat(" ---: |", len(s.Header)) + "\n")
+			for _, row := range s.Rows {
+				writeMarkdownRow(b, row)
+			}
+		}
+		if s.Chart != nil && len(s.Chart.PNG) > 0 {
+			fmt.Fprintf(b, "\n![%s](data:image/png;base64,%s)\n", s.Title, base64.StdEncoding.EncodeToString(s.Chart.PNG))
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return errors.Wrap(err, "failed to write report")
+	}
+	return nil
+}
+
+// WriteLaTeX writes the report as the standalone LaTeX article with booktabs tables, that break across pages,
+// and pgfplots charts, coordinates of charts are written in the scientific notation with precision digits
+func (r Report) WriteLaTeX(w io.Writer, precision int) error {
+	b := &strings.Builder{}
+	b.WriteString("\\documentclass{article}\n\\usepackage{booktabs}\n\\usepackage{longtable}\n" +
+		"\\usepackage{pgfplots}\n\\pgfplotsset{compat=1.16}\n")
+	fmt.Fprintf(b, "\\title{%s}\n\\date{}\n\\begin{document}\n\\maketitle\n", escape(r.Title))
+	if len(r.Problem) > 0 {
+		b.WriteString("\\begin{description}\n")
+		for _, f := range r.Problem {
+			fmt.Fprintf(b, "\\item[%s] \\texttt{%s}\n", escape(f.Name), escape(f.Value))
+		}
+		b.WriteString("\\end{description}\n")
+	}
+	for _, s := range r.Sections {
+		fmt.Fprintf(b, "\n\\section*{%s}\n", escape(s.Title))
+		if s.Text != "" {
+			fmt.Fprintf(b, "%s\n\n", escape(s.Text))
+		}
+		if err := s.checkRows(); err != nil {
+			return err
+		}
+		if len(s.Header) > 0 {
+			fmt.Fprintf(b, "\\begin{longtable}{%s}\n\\toprule\n", strings.Repeat("r", len(s.Header)))
+			writeLaTeXRow(b, s.Header)
+			b.WriteString("\\midrule\n\\endhead\n")
+			for _, row := range s.Rows {
+				writeLaTeXRow(b, row)
+			}
+			b.WriteString("\\bottomrule\n\\end{longtable}\n")
+		}
+		if s.Chart != nil && len(s.Chart.Lines) > 0 {
+			axis := "axis"
+			if s.Chart.LogLog {
+				axis = "loglogaxis"
+			}
+			fmt.Fprintf(b, "\\begin{center}\n\\begin{tikzpicture}\n\\begin{%s}[width=0.8\\textwidth, "+
+				"xlabel={%s}, ylabel={%s}, legend pos=outer north east]\n", axis, escape(s.Chart.XLabel), escape(s.Chart.YLabel))
+			_ = PGFPlots{Precision: precision, Scientific: true}.Write(b, s.Chart.Lines) // the builder doesn't fail
+			fmt.Fprintf(b, "\\end{%s}\n\\end{tikzpicture}\n\\end{center}\n", axis)
+		}
+	}
+	b.WriteString("\\end{document}\n")
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return errors.Wrap(err, "failed to write report")
+	}
+	return nil
+}
+
+// checkRows returns the error, if the row of the section has more or less cells, than the header
+func (s Section) checkRows() error {
+	for i, row := range s.Rows {
+		if len(row) != len(s.Header) {
+			return errors.Errorf("row %d of %s has %d cells, header has %d", i, s.Title, len(row), len(s.Header))
+		}
+	}
+	return nil
+}
+
+func writeMarkdownRow(b *strings.Builder, cells []string) {
+	b.WriteString("|")
+	for _, c := range cells {
+		fmt.Fprintf(b, " %s |", strings.ReplaceAll(c, "|", `\|`))
+	}
+	b.WriteString("\n")
+}
+
+func writeLaTeXRow(b *strings.Builder, cells []string) {
+	for i, c := range cells {
+		if i > 0 {
+			b.WriteString(" & ")
+		}
+		b.WriteString(escape(c))
+	}
+	b.WriteString(" \\\\\n")
+}
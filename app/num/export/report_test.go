@@ -0,0 +1,56 @@
+package export
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testReport() Report {
+	return Report{
+		Title:   "Solution of y' = x^2 - 2*y",
+		Problem: []Field{{Name: "f(x,y)", Value: "x^2 - 2*y"}, {Name: "interval", Value: "[0, 1]"}},
+		Sections: []Section{
+			{
+				Title:  "Solutions",
+				Header: []string{"x", "euler", "rk4"},
+				Rows:   [][]string{{"0", "1", "1"}, {"0.5", "0", "0.3834"}, {"1", "0.25", "0.3383"}},
+				Chart: &Chart{XLabel: "X", YLabel: "Y", PNG: []byte("png"), Lines: []num.Line{
+					{Name: "euler", Points: []num.Point{{X: 0, Y: 1}, {X: 0.5, Y: 0}, {X: 1, Y: 0.25}}},
+				}},
+			},
+			{
+				Title:  "Convergence",
+				Text:   "Reference: exact, fitted orders: euler 1.000",
+				Header: []string{"method", "n", "max_gte"},
+				Rows:   [][]string{{"euler", "10", "1.2e-02"}, {"euler|explicit", "20", "6.1e-03"}},
+				Chart: &Chart{XLabel: "N", YLabel: "GTE", LogLog: true, Lines: []num.Line{
+					{Name: "euler", Points: []num.Point{{X: 10, Y: 0.012}, {X: 20, Y: math.NaN()}}},
+				}},
+			},
+		},
+	}
+}
+
+func TestReport_WriteMarkdown(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, testReport().WriteMarkdown(buf))
+	checkGolden(t, "report.md", buf.Bytes())
+
+	r := Report{Sections: []Section{{Title: "broken", Header: []string{"x"}, Rows: [][]string{{"1", "2"}}}}}
+	assert.EqualError(t, r.WriteMarkdown(buf), "row 0 of broken has 2 cells, header has 1")
+}
+
+func TestReport_WriteLaTeX(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, testReport().WriteLaTeX(buf, 3))
+	checkGolden(t, "report.tex", buf.Bytes())
+
+	r := Report{Sections: []Section{{Title: "broken", Header: []string{"x", "y"}, Rows: [][]string{{"1"}}}}}
+	assert.EqualError(t, r.WriteLaTeX(buf, 3), "row 0 of broken has 1 cells, header has 2")
+}
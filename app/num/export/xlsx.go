@@ -0,0 +1,193 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// maxSheetName is the maximal length of the name of the sheet in the workbook
+const maxSheetName = 31
+
+// Workbook writes sheets as the xlsx workbook, the office open xml spreadsheet,
+// texts are written inline, so the workbook has no shared strings and no styles
+type Workbook struct {
+	Sheets []Sheet
+}
+
+// Sheet is the named table of the workbook, the header goes to the first row
+type Sheet struct {
+	Name   string // up to 31 characters, except []:*?/\
+	Header []string
+	Rows   [][]Cell
+}
+
+// Cell is the number or the text, not finite numbers are written as empty cells
+type Cell struct {
+	Text   string
+	Number float64
+	IsText bool
+}
+
+// Number makes the numeric cell
+func Number(v float64) Cell { return Cell{Number: v} }
+
+// Text makes the text cell
+func Text(s string) Cell { return Cell{Text: s, IsText: true} }
+
+// Write the workbook to the writer, sheets must have unique names
+func (wb Workbook) Write(w io.Writer) error {
+	if len(wb.Sheets) == 0 {
+		return errors.New("no sheets to export")
+	}
+	seen := map[string]bool{}
+	for _, sh := range wb.Sheets {
+		switch {
+		case sh.Name == "" || len([]rune(sh.Name)) > maxSheetName:
+			return errors.Errorf("name of the sheet %q must have from 1 to %d characters", sh.Name, maxSheetName)
+		case strings.ContainsAny(sh.Name, `[]:*?/\`):
+			return errors.Errorf("name of the sheet %q must not contain []:*?/\\", sh.Name)
+		case seen[strings.ToLower(sh.Name)]:
+			return errors.Errorf("sheet %q is repeated", sh.Name)
+		}
+		seen[strings.ToLower(sh.Name)] = true
+	}
+
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	files := []part{
+		{"[Content_Types].xml", wb.contentTypes()},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" ` +
+			`Target="xl/workbook.xml"/></Relationships>`},
+		{"xl/workbook.xml", wb.workbook()},
+		{"xl/_rels/workbook.xml.rels", wb.relationships()},
+	}
+	for i, sh := range wb.Sheets {
+		content, err := sh.xml()
+		if err != nil {
+			return errors.Wrapf(err, "failed to export sheet %s", sh.Name)
+		}
+		files = append(files, part{name: fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), content: content})
+	}
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create %s", f.name)
+		}
+		if _, err = io.WriteString(fw, f.content); err != nil {
+			return errors.Wrapf(err, "failed to write %s", f.name)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return errors.Wrap(err, "failed to close workbook")
+	}
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return errors.Wrap(err, "failed to write workbook")
+	}
+	return nil
+}
+
+// part is the file of the workbook package
+type part struct {
+	name    string
+	content string
+}
+
+func (wb Workbook) contentTypes() string {
+	b := &strings.Builder{}
+	b.WriteString(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ` +
+		`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	for i := range wb.Sheets {
+		fmt.Fprintf(b, `<Override PartName="/xl/worksheets/sheet%d.xml" `+
+			`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+	}
+	b.WriteString(`</Types>`)
+	return b.String()
+}
+
+func (wb Workbook) workbook() string {
+	b := &strings.Builder{}
+	b.WriteString(xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, sh := range wb.Sheets {
+		fmt.Fprintf(b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escapeXML(sh.Name), i+1, i+1)
+	}
+	b.WriteString(`</sheets></workbook>`)
+	return b.String()
+}
+
+func (wb Workbook) relationships() string {
+	b := &strings.Builder{}
+	b.WriteString(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := range wb.Sheets {
+		fmt.Fprintf(b, `<Relationship Id="rId%d" `+
+			`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" `+
+			`Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	b.WriteString(`</Relationships>`)
+	return b.String()
+}
+
+// xml returns the worksheet with the header in the first row, numbers are written without the loss of precision
+func (sh Sheet) xml() (string, error) {
+	b := &strings.Builder{}
+	b.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	row := 0
+	writeRow := func(cells []Cell) {
+		row++
+		fmt.Fprintf(b, `<row r="%d">`, row)
+		for i, c := range cells {
+			ref := column(i) + strconv.Itoa(row)
+			switch {
+			case c.IsText:
+				fmt.Fprintf(b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, escapeXML(c.Text))
+			case isSpecial(c.Number):
+			default:
+				fmt.Fprintf(b, `<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(c.Number, 'g', -1, 64))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	if len(sh.Header) > 0 {
+		header := make([]Cell, len(sh.Header))
+		for i, h := range sh.Header {
+			header[i] = Text(h)
+		}
+		writeRow(header)
+	}
+	for i, cells := range sh.Rows {
+		if len(sh.Header) > 0 && len(cells) > len(sh.Header) {
+			return "", errors.Errorf("row %d has %d cells, header has %d", i, len(cells), len(sh.Header))
+		}
+		writeRow(cells)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String(), nil
+}
+
+// column returns the letters of the column by its index from zero, e.g. A, Z, AA
+func column(i int) string {
+	var res []byte
+	for i++; i > 0; i = (i - 1) / 26 {
+		res = append([]byte{byte('A' + (i-1)%26)}, res...)
+	}
+	return string(res)
+}
+
+// escapeXML escapes the text of the element or the attribute
+func escapeXML(s string) string {
+	b := &strings.Builder{}
+	_ = xml.EscapeText(b, []byte(s)) // the builder doesn't fail
+	return b.String()
+}
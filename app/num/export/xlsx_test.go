@@ -0,0 +1,63 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkbook_Write(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := Workbook{Sheets: []Sheet{
+		{Name: "summary", Header: []string{"method", "order"}, Rows: [][]Cell{
+			{Text("euler"), Number(0.98)},
+			{Text("a < b & c"), Number(math.NaN())},
+		}},
+		{Name: "rk4", Header: []string{"x", "y"}, Rows: [][]Cell{{Number(0), Number(1)}, {Number(0.1), Number(1.1051708333333332)}}},
+	}}.Write(buf)
+	require.NoError(t, err)
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		files[f.Name] = string(b)
+	}
+	require.Len(t, files, 6)
+	assert.Contains(t, files["[Content_Types].xml"], `<Override PartName="/xl/worksheets/sheet2.xml"`)
+	assert.Contains(t, files["xl/workbook.xml"], `<sheet name="summary" sheetId="1" r:id="rId1"/><sheet name="rk4" sheetId="2" r:id="rId2"/>`)
+	assert.Contains(t, files["xl/_rels/workbook.xml.rels"], `Target="worksheets/sheet2.xml"`)
+	assert.Contains(t, files["_rels/.rels"], `Target="xl/workbook.xml"`)
+	assert.Contains(t, files["xl/worksheets/sheet1.xml"], `<row r="3"><c r="A3" t="inlineStr"><is><t xml:space="preserve">`+
+		`a &lt; b &amp; c</t></is></c></row>`)
+	assert.Contains(t, files["xl/worksheets/sheet2.xml"], `<row r="3"><c r="A3"><v>0.1</v></c><c r="B3"><v>1.1051708333333332</v></c></row>`)
+}
+
+func TestWorkbook_WriteInvalid(t *testing.T) {
+	for name, wb := range map[string]Workbook{
+		"no sheets to export": {},
+		`name of the sheet "" must have from 1 to 31 characters`: {Sheets: []Sheet{{}}},
+		`name of the sheet "a/b" must not contain []:*?/\`:       {Sheets: []Sheet{{Name: "a/b"}}},
+		`sheet "RK4" is repeated`:                                {Sheets: []Sheet{{Name: "rk4"}, {Name: "RK4"}}},
+		"failed to export sheet s: row 0 has 2 cells, header has 1": {Sheets: []Sheet{{Name: "s", Header: []string{"x"},
+			Rows: [][]Cell{{Number(1), Number(2)}}}}},
+	} {
+		assert.EqualError(t, wb.Write(&bytes.Buffer{}), name)
+	}
+}
+
+func TestColumn(t *testing.T) {
+	for i, expected := range map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		assert.Equal(t, expected, column(i), i)
+	}
+}
@@ -0,0 +1,270 @@
+// Package expr parses formulas of real variables, e.g. the right-hand side f(x,y) of the equation,
+// and compiles them into functions, that are evaluated without parsing them again
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Func evaluates the compiled formula with values of variables in the order of their names
+type Func func(vals ...float64) (float64, error)
+
+// SyntaxError describes why the formula can't be compiled, the position is of the offending token,
+// counted in characters from 1
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+// Error returns the message with the position
+func (e *SyntaxError) Error() string { return fmt.Sprintf("%s at position %d", e.Msg, e.Pos) }
+
+// maxDepth is the max nesting of parentheses, operators and calls of the formula, so the deep formula
+// doesn't exhaust the stack of the parser
+const maxDepth = 256
+
+// constants are named constants of formulas, variables and constants of the caller shadow them
+var constants = map[string]float64{"pi": math.Pi, "e": math.E}
+
+// function is the function of formulas of 0, 1 or 2 arguments, s1 and s2 evaluate truncated Taylor series
+// of f1 and f2 for derivatives
+type function struct {
+	f0 func() float64
+	f1 func(float64) float64
+	f2 func(a, b float64) float64
+	s1 func([]float64) []float64
+	s2 func(a, b []float64) []float64
+}
+
+// arity returns the number of arguments of the function
+func (fn function) arity() int {
+	switch {
+	case fn.f0 != nil:
+		return 0
+	case fn.f1 != nil:
+		return 1
+	}
+	return 2
+}
+
+// functions are functions of formulas by their names, pi() is kept along with the constant pi
+var functions = map[string]function{
+	"pi":    {f0: func() float64 { return math.Pi }},
+	"abs":   {f1: math.Abs, s1: absSeries},
+	"sqrt":  {f1: math.Sqrt, s1: sqrtSeries},
+	"cbrt":  {f1: math.Cbrt, s1: cbrtSeries},
+	"exp":   {f1: math.Exp, s1: expSeries},
+	"ln":    {f1: math.Log, s1: lnSeries},
+	"log":   {f1: math.Log, s1: lnSeries},
+	"log2":  {f1: math.Log2, s1: func(a []float64) []float64 { return scaleSeries(lnSeries(a), 1/math.Ln2) }},
+	"log10": {f1: math.Log10, s1: func(a []float64) []float64 { return scaleSeries(lnSeries(a), 1/math.Ln10) }},
+	"sin":   {f1: math.Sin, s1: sinSeries},
+	"cos":   {f1: math.Cos, s1: cosSeries},
+	"tan":   {f1: math.Tan, s1: tanSeries},
+	"asin":  {f1: math.Asin, s1: asinSeries},
+	"acos":  {f1: math.Acos, s1: acosSeries},
+	"atan":  {f1: math.Atan, s1: atanSeries},
+	"sinh":  {f1: math.Sinh, s1: sinhSeries},
+	"cosh":  {f1: math.Cosh, s1: coshSeries},
+	"tanh":  {f1: math.Tanh, s1: tanhSeries},
+	"floor": {f1: math.Floor, s1: constOf(math.Floor)},
+	"ceil":  {f1: math.Ceil, s1: constOf(math.Ceil)},
+	"sign":  {f1: sign, s1: constOf(sign)},
+	"pow":   {f2: math.Pow, s2: powSeries},
+	"atan2": {f2: math.Atan2, s2: atan2Series},
+	"hypot": {f2: math.Hypot, s2: hypotSeries},
+	"min":   {f2: math.Min, s2: minSeries},
+	"max":   {f2: math.Max, s2: maxSeries},
+}
+
+// sign returns the sign of v, zero and NaN are returned as is
+func sign(v float64) float64 {
+	if v == 0 || math.IsNaN(v) {
+		return v
+	}
+	return math.Copysign(1, v)
+}
+
+// IsFunc checks whether the name is the function of formulas
+func IsFunc(name string) bool {
+	_, ok := functions[name]
+	return ok
+}
+
+// Functions returns sorted names of functions of formulas
+func Functions() []string {
+	res := make([]string, 0, len(functions))
+	for name := range functions {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Parse compiles the formula of variables with the given names, named constants are available in it along
+// with pi and e, operators are +, -, *, / and the power ^ (or **), which is right-associative and binds
+// tighter than the unary minus, so -x^2 is -(x^2). Unknown variables and functions, as well as calls
+// with the wrong number of arguments, are syntax errors, the result fails only on the wrong number of values
+func Parse(s string, consts map[string]float64, names ...string) (Func, error) {
+	n, err := compile(s, consts, names)
+	if err != nil {
+		return nil, err
+	}
+	prog := compileProgram(n)
+	return func(vals ...float64) (float64, error) {
+		if len(vals) != len(names) {
+			return 0, fmt.Errorf("expected %d values of %s, got %d", len(names), strings.Join(names, ", "), len(vals))
+		}
+		return prog.eval(vals), nil
+	}, nil
+}
+
+// Parse2 compiles the formula of two variables with the given names as Parse does, e.g. f(x,y)
+func Parse2(s string, consts map[string]float64, a, b string) (func(a, b float64) (float64, error), error) {
+	n, err := compile(s, consts, []string{a, b})
+	if err != nil {
+		return nil, err
+	}
+	prog := compileProgram(n)
+	return func(av, bv float64) (float64, error) {
+		vals := [2]float64{av, bv}
+		return prog.eval(vals[:]), nil
+	}, nil
+}
+
+// SeriesFunc evaluates truncated Taylor series of the compiled formula, given series of variables in the order
+// of their names, the k-th coefficient of the series is the k-th derivative by t divided by k!
+type SeriesFunc func(vals ...[]float64) ([]float64, error)
+
+// ParseSeries compiles the formula as Parse does, but the result differentiates it automatically: given series
+// of variables along the path, e.g. [x, 1] and [y, y'], it returns the series of the formula of the same length,
+// e.g. [f, df/dt], so derivatives of any order are exact up to the round-off, without finite differences.
+// Series of variables must be of the same non-zero length, the formula without variables gives the value only.
+// The derivative of abs at zero and of floor, ceil and sign is zero, min and max follow the chosen argument
+func ParseSeries(s string, consts map[string]float64, names ...string) (SeriesFunc, error) {
+	n, err := compile(s, consts, names)
+	if err != nil {
+		return nil, err
+	}
+	return func(vals ...[]float64) ([]float64, error) {
+		if len(vals) != len(names) {
+			return nil, fmt.Errorf("expected %d series of %s, got %d", len(names), strings.Join(names, ", "), len(vals))
+		}
+		size, err := seriesLen(vals)
+		if err != nil {
+			return nil, err
+		}
+		return n.series(vals, size), nil
+	}, nil
+}
+
+// ParseSeries2 compiles the formula of two variables with the given names as ParseSeries does, e.g. f(x,y)
+func ParseSeries2(s string, consts map[string]float64, a, b string) (func(a, b []float64) ([]float64, error), error) {
+	n, err := compile(s, consts, []string{a, b})
+	if err != nil {
+		return nil, err
+	}
+	return func(as, bs []float64) ([]float64, error) {
+		vals := [][]float64{as, bs}
+		size, err := seriesLen(vals)
+		if err != nil {
+			return nil, err
+		}
+		return n.series(vals, size), nil
+	}, nil
+}
+
+// Derivative compiles the partial derivative of the formula by the variable wrt, one of names, e.g. df/dy
+// of f(x,y), it is differentiated automatically, as ParseSeries does, so the derivative is exact up to the round-off
+// without finite differences and the formula is parsed once, the result is evaluated with values of all variables
+func Derivative(s string, consts map[string]float64, wrt string, names ...string) (Func, error) {
+	n, i, err := compileDerivative(s, consts, wrt, names)
+	if err != nil {
+		return nil, err
+	}
+	return func(vals ...float64) (float64, error) {
+		if len(vals) != len(names) {
+			return 0, fmt.Errorf("expected %d values of %s, got %d", len(names), strings.Join(names, ", "), len(vals))
+		}
+		return partial(n, i, vals), nil
+	}, nil
+}
+
+// Derivative2 compiles the partial derivative of the formula of two variables as Derivative does, e.g. df/dx
+// and df/dy of f(x,y)
+func Derivative2(s string, consts map[string]float64, wrt, a, b string) (func(a, b float64) (float64, error), error) {
+	n, i, err := compileDerivative(s, consts, wrt, []string{a, b})
+	if err != nil {
+		return nil, err
+	}
+	return func(av, bv float64) (float64, error) {
+		vals := [2]float64{av, bv}
+		return partial(n, i, vals[:]), nil
+	}, nil
+}
+
+// compileDerivative compiles the formula and finds the index of the variable of the derivative
+func compileDerivative(s string, consts map[string]float64, wrt string, names []string) (node, int, error) {
+	i := -1
+	for j, name := range names {
+		if name == wrt {
+			i = j
+		}
+	}
+	if i < 0 {
+		return node{}, 0, fmt.Errorf("unknown variable %q of the derivative, available: %s", wrt, strings.Join(names, ", "))
+	}
+	n, err := compile(s, consts, names)
+	if err != nil {
+		return node{}, 0, err
+	}
+	return n, i, nil
+}
+
+// partial evaluates the first coefficient of the series of the node, where only the i-th variable changes
+func partial(n node, i int, vals []float64) float64 {
+	vs := make([][]float64, len(vals))
+	for j, v := range vals {
+		vs[j] = []float64{v, 0}
+	}
+	vs[i][1] = 1
+	return n.series(vs, 2)[1]
+}
+
+// seriesLen checks that series are of the same non-zero length and returns it, 1 if there are no series
+func seriesLen(vals [][]float64) (int, error) {
+	if len(vals) == 0 {
+		return 1, nil
+	}
+	for _, v := range vals {
+		if len(v) == 0 || len(v) != len(vals[0]) {
+			return 0, errors.New("series must be of the same non-zero length")
+		}
+	}
+	return len(vals[0]), nil
+}
+
+// compile parses the whole formula into the tree of nodes
+func compile(s string, consts map[string]float64, names []string) (node, error) {
+	toks, err := lex(s)
+	if err != nil {
+		return node{}, err
+	}
+	p := &parser{toks: toks, consts: consts, names: names}
+	n, err := p.sum()
+	if err != nil {
+		return node{}, err
+	}
+	switch t := p.peek(); t.kind {
+	case tokEOF:
+		return n, nil
+	case tokRParen:
+		return node{}, &SyntaxError{Pos: t.pos, Msg: `unexpected ")", no parenthesis is opened`}
+	default:
+		return node{}, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("unexpected %q", t.text)}
+	}
+}
@@ -0,0 +1,100 @@
+package expr
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want float64
+	}{
+		{"x^2 - 2*y + sin(x)", 4 - 6 + math.Sin(2)},
+		{"x**2 - 2*y", -2},
+		{"-x^2", -4},
+		{"2^-1", 0.5},
+		{"x^y^2", 512},
+		{"(x - y) * (x + y)", -5},
+		{"x / y / 2", 1.0 / 3},
+		{"1e3 + 2.5E-1 + .5", 1000.75},
+		{"- -x + +y", 5},
+		{"pi + pi() + e", 2*math.Pi + math.E},
+		{"k*x", 10},
+		{"exp(ln(x)) + sqrt(abs(-16)) + log10(1000) + log2(8) + cbrt(27)", 2 + 4 + 3 + 3 + 3},
+		{"max(x, y) + min(x, y) + pow(x, y) + hypot(3, 4) + atan2(0, 1)", 3 + 2 + 8 + 5},
+		{"sign(-x) + floor(2.5) + ceil(2.5) + tanh(0) + sinh(0) + cosh(0)", -1 + 2 + 3 + 1},
+		{"asin(1) + acos(1) + atan(1) + tan(0) + cos(0) + log(1)", math.Pi/2 + math.Pi/4 + 1},
+		{"1/(x-2)", math.Inf(1)},
+	} {
+		f, err := Parse2(tt.s, map[string]float64{"k": 5}, "x", "y")
+		require.NoError(t, err, tt.s)
+		v, err := f(2, 3)
+		require.NoError(t, err, tt.s)
+		assert.InDelta(t, tt.want, v, 1e-12, tt.s)
+	}
+
+	// variables shadow constants of the caller, which shadow named constants
+	f, err := Parse("e*k + x", map[string]float64{"e": 10, "x": 100}, "x", "k")
+	require.NoError(t, err)
+	v, err := f(1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 21.0, v)
+	_, err = f(1)
+	assert.EqualError(t, err, "expected 2 values of x, k, got 1")
+
+	assert.True(t, IsFunc("sin"))
+	assert.False(t, IsFunc("x"))
+	assert.Equal(t, "abs", Functions()[0])
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, tt := range []struct{ s, err string }{
+		{"x +* y", `unexpected "*", the operand is missing at position 4`},
+		{"x +", "unexpected end of formula, the operand is missing at position 4"},
+		{"", "unexpected end of formula, the operand is missing at position 1"},
+		{"(x + y", "unclosed parenthesis at position 1"},
+		{"sin(x", "unclosed parenthesis at position 4"},
+		{"x + y)", `unexpected ")", no parenthesis is opened at position 6`},
+		{"()", `unexpected ")", the operand is missing at position 2`},
+		{"2x", `missing operator before "x", e.g. 2*x at position 2`},
+		{"x y", `missing operator before "y", e.g. 2*x at position 3`},
+		{"x (y)", `missing operator before "(", e.g. 2*x at position 3`},
+		{"z*x", `unknown variable "z", available: x, y, a, b at position 1`},
+		{"sqr(x)", `unknown function "sqr", available: ` + strings.Join(Functions(), ", ") + " at position 1"},
+		{"ln(x, y)", "ln takes 1 argument, got 2 at position 1"},
+		{"pow(x)", "pow takes 2 arguments, got 1 at position 1"},
+		{"pi(1)", "pi takes 0 arguments, got 1 at position 1"},
+		{"sin(x,)", `unexpected ")", the operand is missing at position 7`},
+		{"1.2.3", `bad number "1.2.3" at position 1`},
+		{"x = 1", `unexpected character '=' at position 3`},
+		{"x ≥ 1", `unexpected character '≥' at position 3`},
+		{"sin(x y)", `missing operator before "y", e.g. 2*x at position 7`},
+		{"(x, y)", `unexpected ",", expected ")" at position 3`},
+		{"x, y", `unexpected "," at position 2`},
+		{strings.Repeat("(", 300) + "x" + strings.Repeat(")", 300), "formula is nested deeper than 256 levels at position 257"},
+		{strings.Repeat("-", 300) + "x", "formula is nested deeper than 256 levels at position 256"},
+	} {
+		_, err := Parse2(tt.s, map[string]float64{"b": 1, "a": 2}, "x", "y")
+		require.Error(t, err, tt.s)
+		assert.EqualError(t, err, tt.err, tt.s)
+		var se *SyntaxError
+		assert.True(t, errors.As(err, &se), tt.s)
+	}
+}
+
+var benchSink float64
+
+func BenchmarkParse2(b *testing.B) {
+	f, err := Parse2("y*y*exp(x) - 2*y", nil, "x", "y")
+	require.NoError(b, err)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchSink, _ = f(0.5, float64(i))
+	}
+}
@@ -0,0 +1,338 @@
+package expr
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// node is the compiled part of the formula, it is evaluated with values of variables, or with truncated Taylor
+// series of variables of the length n for derivatives, see series.go, emit appends its instructions to the program,
+// which evaluates the formula faster, see program.go
+type node struct {
+	eval   func(v []float64) float64
+	series func(v [][]float64, n int) []float64
+	emit   func(p *program)
+}
+
+// kinds of tokens of formulas
+const (
+	tokEOF = iota
+	tokNum
+	tokIdent
+	tokOp // operators +, -, *, / and ^, the power ** is the same as ^
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+// token is the lexeme of the formula at the position, counted in characters from 1
+type token struct {
+	kind int
+	text string
+	op   string  // the operator of tokOp
+	num  float64 // the value of tokNum
+	pos  int
+}
+
+// lex splits the formula into tokens, the last one is tokEOF past the end of the formula
+func lex(s string) ([]token, error) {
+	rs := []rune(s)
+	var toks []token
+	for i := 0; i < len(rs); {
+		r, pos := rs[i], i+1
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case isDigit(r) || r == '.':
+			j := i
+			for j < len(rs) && (isDigit(rs[j]) || rs[j] == '.') {
+				j++
+			}
+			// the exponent, e.g. 1e-3, the letter without digits after it is the variable, e.g. 2e is 2 and e
+			if k := j + 1; j < len(rs) && (rs[j] == 'e' || rs[j] == 'E') {
+				if k < len(rs) && (rs[k] == '+' || rs[k] == '-') {
+					k++
+				}
+				if k < len(rs) && isDigit(rs[k]) {
+					for j = k; j < len(rs) && isDigit(rs[j]); j++ {
+					}
+				}
+			}
+			text := string(rs[i:j])
+			v, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, &SyntaxError{Pos: pos, Msg: fmt.Sprintf("bad number %q", text)}
+			}
+			toks = append(toks, token{kind: tokNum, text: text, num: v, pos: pos})
+			i = j
+		case isLetter(r):
+			j := i
+			for j < len(rs) && (isLetter(rs[j]) || isDigit(rs[j])) {
+				j++
+			}
+			toks = append(toks, token{kind: tokIdent, text: string(rs[i:j]), pos: pos})
+			i = j
+		case r == '*' && i+1 < len(rs) && rs[i+1] == '*':
+			toks = append(toks, token{kind: tokOp, text: "**", op: "^", pos: pos})
+			i += 2
+		case strings.ContainsRune("+-*/^", r):
+			toks = append(toks, token{kind: tokOp, text: string(r), op: string(r), pos: pos})
+			i++
+		case r == '(':
+			toks = append(toks, token{kind: tokLParen, text: "(", pos: pos})
+			i++
+		case r == ')':
+			toks = append(toks, token{kind: tokRParen, text: ")", pos: pos})
+			i++
+		case r == ',':
+			toks = append(toks, token{kind: tokComma, text: ",", pos: pos})
+			i++
+		default:
+			return nil, &SyntaxError{Pos: pos, Msg: fmt.Sprintf("unexpected character %q", r)}
+		}
+	}
+	return append(toks, token{kind: tokEOF, pos: len(rs) + 1}), nil
+}
+
+func isDigit(r rune) bool  { return r >= '0' && r <= '9' }
+func isLetter(r rune) bool { return r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' }
+
+// parser is the recursive descent parser of tokens of the formula, each level of precedence is the method:
+// sum of products of unary minuses of powers of atoms
+type parser struct {
+	toks   []token
+	i      int
+	depth  int
+	consts map[string]float64
+	names  []string
+}
+
+func (p *parser) peek() token { return p.toks[p.i] }
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+// enter checks the nesting of the formula, the caller must call leave after parsing the nested part
+func (p *parser) enter() error {
+	if p.depth++; p.depth > maxDepth {
+		return &SyntaxError{Pos: p.peek().pos, Msg: fmt.Sprintf("formula is nested deeper than %d levels", maxDepth)}
+	}
+	return nil
+}
+
+func (p *parser) leave() { p.depth-- }
+
+// isOp checks whether the next token is one of operators
+func (p *parser) isOp(ops ...string) (token, bool) {
+	t := p.peek()
+	if t.kind != tokOp {
+		return t, false
+	}
+	for _, op := range ops {
+		if t.op == op {
+			return t, true
+		}
+	}
+	return t, false
+}
+
+// sum parses terms, joined by + and -
+func (p *parser) sum() (node, error) {
+	if err := p.enter(); err != nil {
+		return node{}, err
+	}
+	defer p.leave()
+
+	left, err := p.product()
+	if err != nil {
+		return node{}, err
+	}
+	for {
+		t, ok := p.isOp("+", "-")
+		if !ok {
+			return left, nil
+		}
+		p.next()
+		right, err := p.product()
+		if err != nil {
+			return node{}, err
+		}
+		left = binary(t.op, left, right)
+	}
+}
+
+// product parses factors, joined by * and /, the operand right after the operand is the missing operator
+func (p *parser) product() (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return node{}, err
+	}
+	for {
+		t, ok := p.isOp("*", "/")
+		if !ok {
+			if t.kind == tokNum || t.kind == tokIdent || t.kind == tokLParen {
+				return node{}, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("missing operator before %q, e.g. 2*x", t.text)}
+			}
+			return left, nil
+		}
+		p.next()
+		right, err := p.unary()
+		if err != nil {
+			return node{}, err
+		}
+		left = binary(t.op, left, right)
+	}
+}
+
+// unary parses the unary minus or plus of the power
+func (p *parser) unary() (node, error) {
+	t, ok := p.isOp("-", "+")
+	if !ok {
+		return p.power()
+	}
+	if err := p.enter(); err != nil {
+		return node{}, err
+	}
+	defer p.leave()
+
+	p.next()
+	n, err := p.unary()
+	if err != nil || t.op == "+" {
+		return n, err
+	}
+	return negate(n), nil
+}
+
+// power parses the atom raised to the power, the exponent is the unary, so powers are right-associative
+// and might be negative, e.g. 2^-x^2 is 2^(-(x^2))
+func (p *parser) power() (node, error) {
+	base, err := p.atom()
+	if err != nil {
+		return node{}, err
+	}
+	if _, ok := p.isOp("^"); !ok {
+		return base, nil
+	}
+	p.next()
+	exp, err := p.unary()
+	if err != nil {
+		return node{}, err
+	}
+	return binary("^", base, exp), nil
+}
+
+// atom parses the number, the variable, the call of the function or the formula in parentheses
+func (p *parser) atom() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNum:
+		return constant(t.num), nil
+	case tokIdent:
+		// the variable before the parenthesis is the missing operator, e.g. x(x+1)
+		if _, isVar := p.lookup(t.text); p.peek().kind == tokLParen && (IsFunc(t.text) || !isVar) {
+			return p.call(t)
+		}
+		return p.variable(t)
+	case tokLParen:
+		n, err := p.sum()
+		if err != nil {
+			return node{}, err
+		}
+		if err = p.closing(t); err != nil {
+			return node{}, err
+		}
+		return n, nil
+	case tokEOF:
+		return node{}, &SyntaxError{Pos: t.pos, Msg: "unexpected end of formula, the operand is missing"}
+	default:
+		return node{}, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("unexpected %q, the operand is missing", t.text)}
+	}
+}
+
+// closing consumes the closing parenthesis of the opening one
+func (p *parser) closing(open token) error {
+	switch t := p.next(); t.kind {
+	case tokRParen:
+		return nil
+	case tokEOF:
+		return &SyntaxError{Pos: open.pos, Msg: "unclosed parenthesis"}
+	default:
+		return &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("unexpected %q, expected \")\"", t.text)}
+	}
+}
+
+// lookup resolves the name into the variable, the constant of the caller or the named constant
+func (p *parser) lookup(name string) (node, bool) {
+	for i, n := range p.names {
+		if n == name {
+			return variable(i), true
+		}
+	}
+	c, ok := p.consts[name]
+	if !ok {
+		c, ok = constants[name]
+	}
+	if !ok {
+		return node{}, false
+	}
+	return constant(c), true
+}
+
+// variable resolves the name of the token, the unknown name is the syntax error with available names
+func (p *parser) variable(t token) (node, error) {
+	if n, ok := p.lookup(t.text); ok {
+		return n, nil
+	}
+	consts := make([]string, 0, len(p.consts))
+	for name := range p.consts {
+		consts = append(consts, name)
+	}
+	sort.Strings(consts)
+	available := append(append([]string(nil), p.names...), consts...)
+	return node{}, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("unknown variable %q, available: %s",
+		t.text, strings.Join(available, ", "))}
+}
+
+// call parses arguments of the call of the function, their number must be the arity of the function
+func (p *parser) call(name token) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return node{}, &SyntaxError{Pos: name.pos, Msg: fmt.Sprintf("unknown function %q, available: %s",
+			name.text, strings.Join(Functions(), ", "))}
+	}
+	open := p.next()
+	var args []node
+	if p.peek().kind != tokRParen {
+		for {
+			arg, err := p.sum()
+			if err != nil {
+				return node{}, err
+			}
+			args = append(args, arg)
+			if p.peek().kind != tokComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if err := p.closing(open); err != nil {
+		return node{}, err
+	}
+	if len(args) != fn.arity() {
+		word := "arguments"
+		if fn.arity() == 1 {
+			word = "argument"
+		}
+		return node{}, &SyntaxError{Pos: name.pos, Msg: fmt.Sprintf("%s takes %d %s, got %d", name.text, fn.arity(),
+			word, len(args))}
+	}
+	return apply(fn, args), nil
+}
@@ -0,0 +1,201 @@
+package expr
+
+import "math"
+
+// Formulas are evaluated by the flat program in the postfix order instead of walking the tree of nodes:
+// each instruction pushes the operand to the stack or replaces operands on its top with the result, so the
+// evaluation is the single loop without calls of closures per node. Operations of constants are folded,
+// while the program is emitted, the result is the same as of the tree, bit for bit, but small integer
+// powers, which are raised by multiplications in the same order, as math.Pow does, they differ only if powers
+// are subnormal
+
+// opcode is the operation of the instruction of the program
+type opcode uint8
+
+// operations of the program, binary ones replace two values on the top of the stack with the result,
+// the right operand is on the top, ops with the suffix C and V take the right operand from the instruction
+const (
+	opConst opcode = iota // pushes the constant
+	opVar                 // pushes the value of the variable
+	opAdd
+	opSub
+	opMul
+	opDiv
+	opPow
+	opAddC
+	opSubC
+	opMulC
+	opDivC
+	opAddV
+	opSubV
+	opMulV
+	opDivV
+	opPowInt // raises the top to the integer power of the instruction, from 2 to maxIntPower
+	opNeg
+	opCall1 // replaces the top with the function of one argument of it
+	opCall2 // replaces two values on the top with the function of two arguments of them
+)
+
+// instr is the instruction of the program
+type instr struct {
+	op opcode
+	i  int     // index of the variable of opVar and ops with the suffix V, the power of opPowInt
+	c  float64 // the constant of opConst and ops with the suffix C
+	f1 func(float64) float64
+	f2 func(a, b float64) float64
+}
+
+// depths of stacks of programs, that are kept on the stack of the goroutine, as the zeroed stack costs
+// about as much as a few instructions, short programs get the short one, deeper programs allocate the stack
+// on each evaluation
+const (
+	shortStack = 8
+	longStack  = 64
+)
+
+// program is the formula, compiled into instructions in the postfix order
+type program struct {
+	code  []instr
+	depth int // max depth of the stack
+	sp    int // depth of the stack after emitted instructions
+}
+
+// compileProgram emits instructions of the tree of nodes
+func compileProgram(n node) *program {
+	p := &program{}
+	n.emit(p)
+	return p
+}
+
+// push emits the instruction, that pushes the value to the stack
+func (p *program) push(in instr) {
+	p.code = append(p.code, in)
+	if p.sp++; p.sp > p.depth {
+		p.depth = p.sp
+	}
+}
+
+// binaryOps are opcodes of operators: of two values on the stack, of the constant and of the variable
+// on the right, the power takes only values on the stack
+var binaryOps = map[string][3]opcode{"+": {opAdd, opAddC, opAddV}, "-": {opSub, opSubC, opSubV},
+	"*": {opMul, opMulC, opMulV}, "/": {opDiv, opDivC, opDivV}, "^": {opPow, opPow, opPow}}
+
+// binary emits the operator of two operands, that are emitted already, operators of constants are folded,
+// and the right operand, that is the constant or the variable, is taken by the operator itself
+func (p *program) binary(op string, f func(a, b float64) float64) {
+	n := len(p.code)
+	l, r := p.code[n-2], p.code[n-1]
+	ops := binaryOps[op]
+	switch {
+	case l.op == opConst && r.op == opConst:
+		p.code = p.code[:n-1]
+		p.code[n-2].c = f(l.c, r.c)
+	case op == "^" && r.op == opConst && r.c == math.Trunc(r.c) && r.c >= 2 && r.c <= maxIntPower:
+		p.code[n-1] = instr{op: opPowInt, i: int(r.c)}
+	case r.op == opConst && ops[1] != opPow:
+		p.code[n-1] = instr{op: ops[1], c: r.c}
+	case r.op == opVar && ops[2] != opPow:
+		p.code[n-1] = instr{op: ops[2], i: r.i}
+	default:
+		p.code = append(p.code, instr{op: ops[0]})
+	}
+	p.sp--
+}
+
+// unary emits the function of one argument, that is emitted already, the function of the constant is folded
+func (p *program) unary(op opcode, f func(float64) float64) {
+	if n := len(p.code); n > 0 && p.code[n-1].op == opConst {
+		p.code[n-1].c = f(p.code[n-1].c)
+		return
+	}
+	p.code = append(p.code, instr{op: op, f1: f})
+}
+
+// call2 emits the function of two arguments, that are emitted already, the function of constants is folded
+func (p *program) call2(f func(a, b float64) float64) {
+	n := len(p.code)
+	if l, r := p.code[n-2], p.code[n-1]; l.op == opConst && r.op == opConst {
+		p.code = p.code[:n-1]
+		p.code[n-2].c = f(l.c, r.c)
+	} else {
+		p.code = append(p.code, instr{op: opCall2, f2: f})
+	}
+	p.sp--
+}
+
+// eval evaluates the program with values of variables, the stack is allocated only for deep programs
+func (p *program) eval(vals []float64) float64 {
+	switch {
+	case p.depth <= shortStack:
+		var st [shortStack]float64
+		return p.run(st[:], vals)
+	case p.depth <= longStack:
+		var st [longStack]float64
+		return p.run(st[:], vals)
+	}
+	return p.run(make([]float64, p.depth), vals)
+}
+
+// run evaluates the program on the stack
+func (p *program) run(st, vals []float64) float64 {
+	sp := -1
+	for k := range p.code {
+		in := &p.code[k]
+		switch in.op {
+		case opConst:
+			sp++
+			st[sp] = in.c
+		case opVar:
+			sp++
+			st[sp] = vals[in.i]
+		case opAdd:
+			sp--
+			st[sp] += st[sp+1]
+		case opSub:
+			sp--
+			st[sp] -= st[sp+1]
+		case opMul:
+			sp--
+			st[sp] *= st[sp+1]
+		case opDiv:
+			sp--
+			st[sp] /= st[sp+1]
+		case opPow:
+			sp--
+			st[sp] = math.Pow(st[sp], st[sp+1])
+		case opAddC:
+			st[sp] += in.c
+		case opSubC:
+			st[sp] -= in.c
+		case opMulC:
+			st[sp] *= in.c
+		case opDivC:
+			st[sp] /= in.c
+		case opAddV:
+			st[sp] += vals[in.i]
+		case opSubV:
+			st[sp] -= vals[in.i]
+		case opMulV:
+			st[sp] *= vals[in.i]
+		case opDivV:
+			st[sp] /= vals[in.i]
+		case opPowInt:
+			v, res := st[sp], 1.0
+			for k := in.i; k != 0; k >>= 1 {
+				if k&1 == 1 {
+					res *= v
+				}
+				v *= v
+			}
+			st[sp] = res
+		case opNeg:
+			st[sp] = -st[sp]
+		case opCall1:
+			st[sp] = in.f1(st[sp])
+		case opCall2:
+			sp--
+			st[sp] = in.f2(st[sp], st[sp+1])
+		}
+	}
+	return st[0]
+}
@@ -0,0 +1,110 @@
+package expr
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// programFormulas are formulas of x and y, that cover all instructions of the program
+var programFormulas = []string{
+	"x^2 - 2*y",
+	"y*y*exp(x) - 2*y",
+	"-x^3 + x^y - 2^-x^2 + (x - y) / (x + y) / 2 + x^7 - y^64 + x^2.5 + y^65",
+	"sin(x)*cos(y) + tan(x/4) + sqrt(abs(y)) + ln(1 + x^2) + log10(2 + y^2) + cbrt(x - y)",
+	"atan2(y, x) + hypot(x, y) + pow(abs(x), 1.5) + min(x, y) * max(x, y) - sign(y) * floor(x) + ceil(y)",
+	"2*3 + x - pi*e + sin(pi/2) * y - pow(2, 10) / x",
+	"1/(x - y) + y/x - x*y - y - x + 7 - k*x + -k",
+	"- -x + +y - (-(x*y))",
+}
+
+func TestProgram_SameAsTree(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, s := range programFormulas {
+		n, err := compile(s, map[string]float64{"k": 5}, []string{"x", "y"})
+		require.NoError(t, err, s)
+		prog := compileProgram(n)
+		assert.Equal(t, 1, prog.sp, "the program of %q leaves the single value", s)
+		for i := 0; i < 1000; i++ {
+			vals := []float64{rnd.Float64()*20 - 10, rnd.Float64()*20 - 10}
+			want, got := n.eval(vals), prog.eval(vals)
+			if math.IsNaN(want) {
+				assert.True(t, math.IsNaN(got), "%s at %v", s, vals)
+				continue
+			}
+			require.Equal(t, math.Float64bits(want), math.Float64bits(got), "%s at %v: %v != %v", s, vals, want, got)
+		}
+	}
+}
+
+func TestProgram_Folding(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		code []instr
+	}{
+		{"2*3 + x", []instr{{op: opConst, c: 6}, {op: opAddV, i: 0}}},
+		{"sin(pi/2) * y", []instr{{op: opConst, c: 1}, {op: opMulV, i: 1}}},
+		{"-(2^2) + max(1, 2)", []instr{{op: opConst, c: -2}}},
+		{"x^2 - 2*y", []instr{{op: opVar, i: 0}, {op: opPowInt, i: 2}, {op: opConst, c: 2}, {op: opMulV, i: 1}, {op: opSub}}},
+		{"y - 0.5", []instr{{op: opVar, i: 1}, {op: opSubC, c: 0.5}}},
+	} {
+		n, err := compile(tt.s, nil, []string{"x", "y"})
+		require.NoError(t, err, tt.s)
+		assert.Equal(t, tt.code, compileProgram(n).code, tt.s)
+	}
+}
+
+func TestProgram_Deep(t *testing.T) {
+	// x + (x + (x + ...)) keeps all x on the stack, so it is deeper than stacks, kept on the goroutine stack
+	s := strings.Repeat("x + (", 100) + "x" + strings.Repeat(")", 100)
+	f, err := Parse(s, nil, "x")
+	require.NoError(t, err)
+	v, err := f(2)
+	require.NoError(t, err)
+	assert.Equal(t, 202.0, v)
+
+	n, err := compile(s, nil, []string{"x"})
+	require.NoError(t, err)
+	assert.Equal(t, 101, compileProgram(n).depth)
+}
+
+func TestParse2_NoAllocs(t *testing.T) {
+	for _, s := range programFormulas {
+		f, err := Parse2(s, map[string]float64{"k": 5}, "x", "y")
+		require.NoError(t, err, s)
+		allocs := testing.AllocsPerRun(100, func() { benchSink, _ = f(0.5, 1.5) })
+		assert.Zero(t, allocs, "evaluation of %q allocates", s)
+	}
+}
+
+// BenchmarkEval compares the evaluation of formulas by walking the tree of nodes and by the program
+func BenchmarkEval(b *testing.B) {
+	for _, bb := range []struct{ name, s string }{
+		{"poly", "x^2 - 2*y"},
+		{"exp", "y*y*exp(x) - 2*y"},
+		{"long", "sin(x)*cos(y) + (x - y) / (x + y) / 2 + 3*x*y - x^3 + 2*y^2 - 1"},
+	} {
+		n, err := compile(bb.s, nil, []string{"x", "y"})
+		require.NoError(b, err)
+		prog := compileProgram(n)
+		vals := []float64{0.5, 1.5}
+		b.Run(bb.name+"/tree", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				vals[1] = float64(i)
+				benchSink = n.eval(vals)
+			}
+		})
+		b.Run(bb.name+"/program", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				vals[1] = float64(i)
+				benchSink = prog.eval(vals)
+			}
+		})
+	}
+}
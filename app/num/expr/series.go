@@ -0,0 +1,331 @@
+package expr
+
+import "math"
+
+// Formulas are differentiated automatically by truncated Taylor series: each variable is the series in t,
+// e.g. x + t, and each node evaluates the series of its value, the k-th coefficient is the k-th derivative
+// by t divided by k!, so derivatives of any order along the path of variables are exact up to the round-off.
+// Elementary functions are composed by the rule g(a)' = g'(a) * a', so the series of g(a) is integrated
+// from the series of g'(a) of the lower order
+
+// maxIntPower is the max integer power, raised by repeated multiplications, so x^2 is differentiated at x = 0
+const maxIntPower = 64
+
+// constant returns the node of the constant value
+func constant(c float64) node {
+	return node{
+		eval:   func([]float64) float64 { return c },
+		series: func(_ [][]float64, n int) []float64 { return constSeries(c, n) },
+		emit:   func(p *program) { p.push(instr{op: opConst, c: c}) },
+	}
+}
+
+// variable returns the node of the i-th variable
+func variable(i int) node {
+	return node{
+		eval:   func(v []float64) float64 { return v[i] },
+		series: func(v [][]float64, _ int) []float64 { return v[i] },
+		emit:   func(p *program) { p.push(instr{op: opVar, i: i}) },
+	}
+}
+
+// binary returns the node of the operator +, -, *, / or ^ of operands
+func binary(op string, l, r node) node {
+	le, re := l.eval, r.eval
+	var res node
+	var f func(a, b float64) float64 // folds operands of the program
+	switch op {
+	case "+":
+		res = node{eval: func(v []float64) float64 { return le(v) + re(v) }}
+		res.series, f = seriesOf(addSeries, l, r), func(a, b float64) float64 { return a + b }
+	case "-":
+		res = node{eval: func(v []float64) float64 { return le(v) - re(v) }}
+		res.series, f = seriesOf(subSeries, l, r), func(a, b float64) float64 { return a - b }
+	case "*":
+		res = node{eval: func(v []float64) float64 { return le(v) * re(v) }}
+		res.series, f = seriesOf(mulSeries, l, r), func(a, b float64) float64 { return a * b }
+	case "/":
+		res = node{eval: func(v []float64) float64 { return le(v) / re(v) }}
+		res.series, f = seriesOf(divSeries, l, r), func(a, b float64) float64 { return a / b }
+	default:
+		res = node{eval: func(v []float64) float64 { return math.Pow(le(v), re(v)) }}
+		res.series, f = seriesOf(powSeries, l, r), math.Pow
+	}
+	res.emit = func(p *program) {
+		l.emit(p)
+		r.emit(p)
+		p.binary(op, f)
+	}
+	return res
+}
+
+// seriesOf returns the series of the node of two operands
+func seriesOf(s func(a, b []float64) []float64, l, r node) func(v [][]float64, n int) []float64 {
+	return func(v [][]float64, n int) []float64 { return s(l.series(v, n), r.series(v, n)) }
+}
+
+// negate returns the node of the unary minus
+func negate(a node) node {
+	return node{
+		eval:   func(v []float64) float64 { return -a.eval(v) },
+		series: func(v [][]float64, n int) []float64 { return scaleSeries(a.series(v, n), -1) },
+		emit: func(p *program) {
+			a.emit(p)
+			p.unary(opNeg, func(v float64) float64 { return -v })
+		},
+	}
+}
+
+// apply returns the node of the call of the function with arguments, their number is the arity of the function
+func apply(fn function, args []node) node {
+	switch fn.arity() {
+	case 0:
+		return constant(fn.f0())
+	case 1:
+		f, s, a := fn.f1, fn.s1, args[0]
+		return node{
+			eval:   func(v []float64) float64 { return f(a.eval(v)) },
+			series: func(v [][]float64, n int) []float64 { return s(a.series(v, n)) },
+			emit: func(p *program) {
+				a.emit(p)
+				p.unary(opCall1, f)
+			},
+		}
+	default:
+		f, a, b := fn.f2, args[0], args[1]
+		return node{
+			eval:   func(v []float64) float64 { return f(a.eval(v), b.eval(v)) },
+			series: seriesOf(fn.s2, a, b),
+			emit: func(p *program) {
+				a.emit(p)
+				b.emit(p)
+				p.call2(f)
+			},
+		}
+	}
+}
+
+func constSeries(c float64, n int) []float64 {
+	res := make([]float64, n)
+	res[0] = c
+	return res
+}
+
+func addSeries(a, b []float64) []float64 {
+	res := make([]float64, len(a))
+	for k := range res {
+		res[k] = a[k] + b[k]
+	}
+	return res
+}
+
+func subSeries(a, b []float64) []float64 {
+	res := make([]float64, len(a))
+	for k := range res {
+		res[k] = a[k] - b[k]
+	}
+	return res
+}
+
+func scaleSeries(a []float64, c float64) []float64 {
+	res := make([]float64, len(a))
+	for k := range res {
+		res[k] = a[k] * c
+	}
+	return res
+}
+
+// mulSeries returns the Cauchy product of series
+func mulSeries(a, b []float64) []float64 {
+	res := make([]float64, len(a))
+	for k := range res {
+		for j := 0; j <= k; j++ {
+			res[k] += a[j] * b[k-j]
+		}
+	}
+	return res
+}
+
+// divSeries returns the quotient q of series, such that q * b = a
+func divSeries(a, b []float64) []float64 {
+	res := make([]float64, len(a))
+	for k := range res {
+		s := a[k]
+		for j := 1; j <= k; j++ {
+			s -= b[j] * res[k-j]
+		}
+		res[k] = s / b[0]
+	}
+	return res
+}
+
+// derivSeries returns the series of the derivative by t, it is shorter by one coefficient
+func derivSeries(a []float64) []float64 {
+	res := make([]float64, len(a)-1)
+	for k := range res {
+		res[k] = float64(k+1) * a[k+1]
+	}
+	return res
+}
+
+// integrateSeries returns the series with the value v0, whose derivative by t is d, it is longer by one coefficient
+func integrateSeries(v0 float64, d []float64) []float64 {
+	res := make([]float64, len(d)+1)
+	res[0] = v0
+	for k := range d {
+		res[k+1] = d[k] / float64(k+1)
+	}
+	return res
+}
+
+// compose returns the series of g(a), given g and the series of g'(b) for the series b of the lower order,
+// g of the constant is constant, even if g' is not finite at it, e.g. sqrt(y) by x at y = 0
+func compose(a []float64, g func(float64) float64, dg func(b []float64) []float64) []float64 {
+	if isConst(a) {
+		return constSeries(g(a[0]), len(a))
+	}
+	return integrateSeries(g(a[0]), mulSeries(dg(a[:len(a)-1]), derivSeries(a)))
+}
+
+// isConst checks whether the series is constant along the path
+func isConst(a []float64) bool {
+	for _, c := range a[1:] {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// constOf returns the function of series, that is constant near the value, e.g. floor, its derivatives are zero
+func constOf(g func(float64) float64) func([]float64) []float64 {
+	return func(a []float64) []float64 { return constSeries(g(a[0]), len(a)) }
+}
+
+func expSeries(a []float64) []float64 { return compose(a, math.Exp, expSeries) }
+
+func lnSeries(a []float64) []float64 {
+	return compose(a, math.Log, func(b []float64) []float64 { return divSeries(constSeries(1, len(b)), b) })
+}
+
+func sinSeries(a []float64) []float64 { return compose(a, math.Sin, cosSeries) }
+
+func cosSeries(a []float64) []float64 {
+	return compose(a, math.Cos, func(b []float64) []float64 { return scaleSeries(sinSeries(b), -1) })
+}
+
+func tanSeries(a []float64) []float64 {
+	return compose(a, math.Tan, func(b []float64) []float64 {
+		c := cosSeries(b)
+		return divSeries(constSeries(1, len(b)), mulSeries(c, c))
+	})
+}
+
+func sinhSeries(a []float64) []float64 { return compose(a, math.Sinh, coshSeries) }
+
+func coshSeries(a []float64) []float64 { return compose(a, math.Cosh, sinhSeries) }
+
+func tanhSeries(a []float64) []float64 {
+	return compose(a, math.Tanh, func(b []float64) []float64 {
+		c := coshSeries(b)
+		return divSeries(constSeries(1, len(b)), mulSeries(c, c))
+	})
+}
+
+// asinSeries and acosSeries have derivatives ±1/sqrt(1 - a^2)
+func asinSeries(a []float64) []float64 {
+	return compose(a, math.Asin, func(b []float64) []float64 { return asinDeriv(b, 1) })
+}
+
+func acosSeries(a []float64) []float64 {
+	return compose(a, math.Acos, func(b []float64) []float64 { return asinDeriv(b, -1) })
+}
+
+func asinDeriv(b []float64, c float64) []float64 {
+	return scaleSeries(powConstSeries(subSeries(constSeries(1, len(b)), mulSeries(b, b)), -0.5), c)
+}
+
+func atanSeries(a []float64) []float64 {
+	return compose(a, math.Atan, func(b []float64) []float64 {
+		return divSeries(constSeries(1, len(b)), addSeries(constSeries(1, len(b)), mulSeries(b, b)))
+	})
+}
+
+func sqrtSeries(a []float64) []float64 {
+	return compose(a, math.Sqrt, func(b []float64) []float64 {
+		return divSeries(constSeries(0.5, len(b)), sqrtSeries(b))
+	})
+}
+
+func cbrtSeries(a []float64) []float64 {
+	return compose(a, math.Cbrt, func(b []float64) []float64 {
+		c := cbrtSeries(b)
+		return divSeries(constSeries(1.0/3, len(b)), mulSeries(c, c))
+	})
+}
+
+// absSeries is differentiated as sign(a) * a, so its derivatives at zero are zero
+func absSeries(a []float64) []float64 {
+	if a[0] == 0 {
+		return constSeries(0, len(a))
+	}
+	return scaleSeries(a, math.Copysign(1, a[0]))
+}
+
+// powSeries returns the series of a^b, the exponent, that is constant along the path, keeps the power
+// of the negative base, otherwise it is exp(b * ln(a))
+func powSeries(a, b []float64) []float64 {
+	if !isConst(b) {
+		return expSeries(mulSeries(b, lnSeries(a)))
+	}
+	return powConstSeries(a, b[0])
+}
+
+// powConstSeries returns the series of a^r, small integer powers are products, so they have derivatives at zero
+func powConstSeries(a []float64, r float64) []float64 {
+	if r == math.Trunc(r) && r >= 0 && r <= maxIntPower {
+		res, sq := constSeries(1, len(a)), a
+		for p := int(r); p > 0; p >>= 1 {
+			if p&1 == 1 {
+				res = mulSeries(res, sq)
+			}
+			if p > 1 {
+				sq = mulSeries(sq, sq)
+			}
+		}
+		return res
+	}
+	return compose(a, func(v float64) float64 { return math.Pow(v, r) }, func(b []float64) []float64 {
+		return scaleSeries(powConstSeries(b, r-1), r)
+	})
+}
+
+// atan2Series has the derivative (b*a' - a*b') / (a^2 + b^2)
+func atan2Series(a, b []float64) []float64 {
+	v0 := math.Atan2(a[0], b[0])
+	if isConst(a) && isConst(b) {
+		return constSeries(v0, len(a))
+	}
+	at, bt := a[:len(a)-1], b[:len(b)-1]
+	num := subSeries(mulSeries(bt, derivSeries(a)), mulSeries(at, derivSeries(b)))
+	return integrateSeries(v0, divSeries(num, addSeries(mulSeries(at, at), mulSeries(bt, bt))))
+}
+
+func hypotSeries(a, b []float64) []float64 {
+	return sqrtSeries(addSeries(mulSeries(a, a), mulSeries(b, b)))
+}
+
+// minSeries and maxSeries follow the argument, that is chosen by values
+func minSeries(a, b []float64) []float64 {
+	if b[0] < a[0] {
+		return b
+	}
+	return a
+}
+
+func maxSeries(a, b []float64) []float64 {
+	if b[0] > a[0] {
+		return b
+	}
+	return a
+}
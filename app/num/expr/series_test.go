@@ -0,0 +1,141 @@
+package expr
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSeries(t *testing.T) {
+	ln2, ln10 := math.Ln2, math.Ln10
+	for _, tt := range []struct {
+		s    string
+		x    float64
+		want []float64 // the series of the formula at x + t
+	}{
+		{"x^2", 0, []float64{0, 0, 1, 0}},
+		{"(-x)^3", 1, []float64{-1, -3, -3, -1}},
+		{"1/x", 1, []float64{1, -1, 1, -1}},
+		{"exp(x)", 0, []float64{1, 1, 1.0 / 2, 1.0 / 6}},
+		{"ln(x)", 1, []float64{0, 1, -1.0 / 2, 1.0 / 3}},
+		{"log2(x) + log10(x)", 1, []float64{0, 1/ln2 + 1/ln10, -1/(2*ln2) - 1/(2*ln10), 1/(3*ln2) + 1/(3*ln10)}},
+		{"sqrt(x)", 1, []float64{1, 1.0 / 2, -1.0 / 8, 1.0 / 16}},
+		{"cbrt(x)", -1, []float64{-1, 1.0 / 3, 1.0 / 9, 5.0 / 81}},
+		{"sin(x)", 0, []float64{0, 1, 0, -1.0 / 6}},
+		{"cos(x)", 0, []float64{1, 0, -1.0 / 2, 0}},
+		{"tan(x)", 0, []float64{0, 1, 0, 1.0 / 3}},
+		{"asin(x)", 0, []float64{0, 1, 0, 1.0 / 6}},
+		{"acos(x)", 0, []float64{math.Pi / 2, -1, 0, -1.0 / 6}},
+		{"atan(x)", 0, []float64{0, 1, 0, -1.0 / 3}},
+		{"sinh(x) + cosh(x)", 0, []float64{1, 1, 1.0 / 2, 1.0 / 6}},
+		{"tanh(x)", 0, []float64{0, 1, 0, -1.0 / 3}},
+		{"2^x", 0, []float64{1, ln2, ln2 * ln2 / 2, ln2 * ln2 * ln2 / 6}},
+		{"x^x", 1, []float64{1, 1, 1, 1.0 / 2}},
+		{"pow(x, 0.5)", 4, []float64{2, 1.0 / 4, -1.0 / 64, 1.0 / 512}},
+		{"abs(x)", -2, []float64{2, -1, 0, 0}},
+		{"floor(x) + ceil(x) + sign(x)", 2.5, []float64{6, 0, 0, 0}},
+		{"atan2(x, 1)", 0, []float64{0, 1, 0, -1.0 / 3}},
+		{"hypot(x, 0)", 2, []float64{2, 1, 0, 0}},
+		{"min(x, 1) + max(x, 1)", 2, []float64{3, 1, 0, 0}},
+		{"k*x + pi()", 0, []float64{math.Pi, 5, 0, 0}},
+	} {
+		f, err := ParseSeries(tt.s, map[string]float64{"k": 5}, "x")
+		require.NoError(t, err, tt.s)
+		got, err := f([]float64{tt.x, 1, 0, 0})
+		require.NoError(t, err, tt.s)
+		require.Len(t, got, 4, tt.s)
+		for k := range tt.want {
+			assert.InDelta(t, tt.want[k], got[k], 1e-12, "%s, coefficient %d", tt.s, k)
+		}
+	}
+}
+
+func TestParseSeries2(t *testing.T) {
+	f, err := ParseSeries2("x*y - y^2", nil, "x", "y")
+	require.NoError(t, err)
+
+	// partial derivatives by each variable, the other one is constant
+	got, err := f([]float64{2, 1}, []float64{3, 0})
+	require.NoError(t, err)
+	assert.Equal(t, []float64{-3, 3}, got)
+	got, err = f([]float64{2, 0}, []float64{3, 1})
+	require.NoError(t, err)
+	assert.Equal(t, []float64{-3, -4}, got)
+
+	// the path x = 2 + t, y = 3 + t gives (2+t)(3+t) - (3+t)^2 = -3 - t
+	got, err = f([]float64{2, 1, 0}, []float64{3, 1, 0})
+	require.NoError(t, err)
+	assert.Equal(t, []float64{-3, -1, 0}, got)
+
+	_, err = f([]float64{2, 1}, []float64{3})
+	assert.EqualError(t, err, "series must be of the same non-zero length")
+	_, err = f(nil, nil)
+	assert.Error(t, err)
+
+	_, err = ParseSeries2("x +", nil, "x", "y")
+	assert.Error(t, err)
+}
+
+func TestParseSeries_Values(t *testing.T) {
+	// values of series are the ones of compiled formulas
+	for _, s := range []string{"x^2 - 2*y + sin(x)", "x^y^2", "exp(ln(x)) + sqrt(abs(-16)) + log10(1000) + cbrt(27)",
+		"max(x, y) + min(x, y) + pow(x, y) + hypot(3, 4) + atan2(x, y)", "asin(x/3) + acos(y/4) + tanh(x)"} {
+		f, err := Parse2(s, nil, "x", "y")
+		require.NoError(t, err, s)
+		fs, err := ParseSeries2(s, nil, "x", "y")
+		require.NoError(t, err, s)
+		want, err := f(2, 3)
+		require.NoError(t, err)
+		got, err := fs([]float64{2, 1}, []float64{3, 1})
+		require.NoError(t, err)
+		assert.InDelta(t, want, got[0], 1e-12, s)
+	}
+
+	f, err := ParseSeries("2*pi", nil)
+	require.NoError(t, err)
+	got, err := f()
+	require.NoError(t, err)
+	assert.Equal(t, []float64{2 * math.Pi}, got)
+	_, err = f([]float64{1})
+	assert.Error(t, err)
+}
+
+func TestDerivative(t *testing.T) {
+	fx, err := Derivative2("k*x^2*y + sin(y)", map[string]float64{"k": 3}, "x", "x", "y")
+	require.NoError(t, err)
+	fy, err := Derivative2("k*x^2*y + sin(y)", map[string]float64{"k": 3}, "y", "x", "y")
+	require.NoError(t, err)
+	v, err := fx(2, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, v)
+	v, err = fx(2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 12.0, v)
+	v, err = fy(2, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 13.0, v)
+
+	f, err := Derivative("x*y*z", nil, "z", "x", "y", "z")
+	require.NoError(t, err)
+	v, err = f(2, 3, 4)
+	require.NoError(t, err)
+	assert.Equal(t, 6.0, v)
+	_, err = f(2, 3)
+	assert.EqualError(t, err, "expected 3 values of x, y, z, got 2")
+
+	// the derivative by the other variable is zero, where the one of the formula is not finite
+	fx, err = Derivative2("sqrt(y) + atan2(y, y)", nil, "x", "x", "y")
+	require.NoError(t, err)
+	v, err = fx(1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, v)
+
+	_, err = Derivative2("x", nil, "z", "x", "y")
+	assert.EqualError(t, err, `unknown variable "z" of the derivative, available: x, y`)
+	_, err = Derivative2("x +", nil, "x", "x", "y")
+	var se *SyntaxError
+	assert.True(t, errors.As(err, &se))
+}
@@ -0,0 +1,61 @@
+// Package field provides the direction (slope) field of the differential equation.
+package field
+
+import (
+	"math"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/pkg/errors"
+)
+
+// Segment is a unit-length segment of the slope field,
+// centered at the grid node and directed along the slope at it
+type Segment struct {
+	Center num.Point `json:"center"`
+	From   num.Point `json:"from"`
+	To     num.Point `json:"to"`
+	Angle  float64   `json:"angle"` // angle to the x axis, in radians
+}
+
+// SlopeField evaluates f on the nx*ny grid over the given rectangle and returns
+// the segments at nodes, nodes where f fails or gives non-finite value are skipped
+func SlopeField(f solver.Func, xMin, xMax, yMin, yMax float64, nx, ny int) ([]Segment, error) {
+	if nx < 1 || ny < 1 {
+		return nil, errors.Errorf("grid size must be positive, got %dx%d", nx, ny)
+	}
+	if !(xMin < xMax) || !(yMin < yMax) {
+		return nil, errors.Errorf("invalid rectangle [%.4f, %.4f]x[%.4f, %.4f]", xMin, xMax, yMin, yMax)
+	}
+
+	segs := make([]Segment, 0, nx*ny)
+	for i := 0; i < nx; i++ {
+		x := node(xMin, xMax, i, nx)
+		for j := 0; j < ny; j++ {
+			y := node(yMin, yMax, j, ny)
+
+			slope, err := f(x, y)
+			if err != nil || math.IsNaN(slope) || math.IsInf(slope, 0) {
+				continue
+			}
+
+			angle := math.Atan(slope)
+			dx, dy := math.Cos(angle)/2, math.Sin(angle)/2
+			segs = append(segs, Segment{
+				Center: num.Point{X: x, Y: y},
+				From:   num.Point{X: x - dx, Y: y - dy},
+				To:     num.Point{X: x + dx, Y: y + dy},
+				Angle:  angle,
+			})
+		}
+	}
+	return segs, nil
+}
+
+// node returns the i-th of n grid nodes on [min, max], the single node is in the middle
+func node(min, max float64, i, n int) float64 {
+	if n == 1 {
+		return (min + max) / 2
+	}
+	return min + (max-min)*float64(i)/float64(n-1)
+}
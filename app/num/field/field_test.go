@@ -0,0 +1,54 @@
+package field
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlopeField(t *testing.T) {
+	segs, err := SlopeField(func(x, y float64) (float64, error) { return 1, nil }, -1, 1, -2, 2, 5, 4)
+	require.NoError(t, err)
+	require.Len(t, segs, 20)
+
+	for _, s := range segs {
+		assert.InDelta(t, math.Pi/4, s.Angle, 1e-12)
+		assert.InDelta(t, 1, math.Hypot(s.To.X-s.From.X, s.To.Y-s.From.Y), 1e-12, "segment must be unit-length")
+		assert.InDelta(t, s.Center.X, (s.To.X+s.From.X)/2, 1e-12, "segment must be centered at node")
+		assert.InDelta(t, s.Center.Y, (s.To.Y+s.From.Y)/2, 1e-12, "segment must be centered at node")
+	}
+	assert.Equal(t, -1.0, segs[0].Center.X)
+	assert.Equal(t, -2.0, segs[0].Center.Y)
+	assert.Equal(t, 1.0, segs[19].Center.X)
+	assert.Equal(t, 2.0, segs[19].Center.Y)
+}
+
+func TestSlopeField_SkipsDomainErrors(t *testing.T) {
+	segs, err := SlopeField(func(x, y float64) (float64, error) {
+		if x == 0 {
+			return 0, errors.New("division by zero")
+		}
+		if y < 0 {
+			return math.Log(y), nil
+		}
+		return y / x, nil
+	}, -1, 1, -1, 1, 3, 3)
+	require.NoError(t, err)
+	// x = 0 column and y = -1 row are skipped
+	assert.Len(t, segs, 4)
+	for _, s := range segs {
+		assert.NotEqual(t, 0.0, s.Center.X)
+		assert.True(t, s.Center.Y >= 0)
+	}
+}
+
+func TestSlopeField_Invalid(t *testing.T) {
+	f := func(x, y float64) (float64, error) { return 0, nil }
+	_, err := SlopeField(f, 0, 1, 0, 1, 0, 1)
+	assert.Error(t, err)
+	_, err = SlopeField(f, 1, 0, 0, 1, 2, 2)
+	assert.Error(t, err)
+}
@@ -0,0 +1,64 @@
+package graph
+
+import (
+	"bytes"
+	"image/color"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/plotutil"
+	"gonum.org/v1/plot/vg"
+)
+
+// PlotBand plots the mean curve over the shaded band between the lower and the upper curves to the image
+// of the given size and format, curves must be aligned by x
+func (pl *Plotter) PlotBand(title, xTitle, yTitle string, mean, lower, upper []num.Point, img Image) ([]byte, error) {
+	if img.Format != "png" && img.Format != "svg" {
+		return nil, errors.Errorf("unsupported format %q", img.Format)
+	}
+	if len(lower) != len(mean) || len(upper) != len(mean) {
+		return nil, errors.Errorf("band of %d and %d points doesn't fit the mean of %d points",
+			len(lower), len(upper), len(mean))
+	}
+
+	p, err := plot.New()
+	if err != nil {
+		return nil, errors.Wrap(err, "can't create new plot")
+	}
+	p.Title.Text = title
+	p.X.Label.Text = xTitle
+	p.Y.Label.Text = yTitle
+
+	// the band is the polygon from the lower curve forward and the upper one backward
+	outline := ptsToXYs(lower)
+	for i := len(upper) - 1; i >= 0; i-- {
+		outline = append(outline, plotter.XY{X: upper[i].X, Y: upper[i].Y})
+	}
+	band, err := plotter.NewPolygon(outline)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't create band")
+	}
+	mc := plotutil.Color(0)
+	r, g, b, _ := mc.RGBA()
+	band.Color = color.NRGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: 64}
+	band.LineStyle.Width = 0
+
+	l, err := plotter.NewLine(ptsToXYs(mean))
+	if err != nil {
+		return nil, errors.Wrap(err, "can't create mean")
+	}
+	l.Color = mc
+	l.Width = vg.Points(1.5)
+
+	p.Add(band, l)
+	p.Legend.Add("mean", l)
+	p.Legend.Add("band", band)
+
+	buf := &bytes.Buffer{}
+	if err = encode(buf, p.Draw, length(img.Width), length(img.Height), img.Format); err != nil {
+		return nil, errors.Wrapf(err, "failed to write plot %s", title)
+	}
+	return buf.Bytes(), nil
+}
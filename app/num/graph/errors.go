@@ -0,0 +1,65 @@
+package graph
+
+import (
+	"io"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/field"
+	"github.com/pkg/errors"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// errorsShare is the share of the height of the image, taken by the subplot of errors
+const errorsShare = 1.0 / 3
+
+// WriteWithErrors plots the set of lines over the slope field, as WriteFieldImage does, with the subplot of errors
+// below, the subplot shares the range of x with the plot of lines, the error line has the style of the line
+// of the same name
+func (pl *Plotter) WriteWithErrors(wr io.Writer, title, xTitle, yTitle string, segs []field.Segment,
+	lines, errLines []num.Line, img Image) error {
+	if img.Format != "png" && img.Format != "svg" {
+		return errors.Errorf("unsupported format %q", img.Format)
+	}
+
+	p, err := plot.New()
+	if err != nil {
+		return errors.Wrap(err, "can't create new plot")
+	}
+	p.Title.Text = title
+	p.Y.Label.Text = yTitle
+	if len(segs) > 0 {
+		p.Add(newFieldPlotter(segs))
+	}
+	styles := map[string]int{}
+	for i, line := range lines {
+		if err = addLine(p, i, line); err != nil {
+			return errors.Wrapf(err, "can't add line %s to plot %s", line.Name, title)
+		}
+		styles[line.Name] = i
+	}
+
+	ep, err := plot.New()
+	if err != nil {
+		return errors.Wrap(err, "can't create new plot")
+	}
+	ep.X.Label.Text = xTitle
+	ep.Y.Label.Text = "|" + yTitle + " - exact|"
+	for i, line := range errLines {
+		style, ok := styles[line.Name]
+		if !ok {
+			style = len(lines) + i
+		}
+		if err = addLine(ep, style, line); err != nil {
+			return errors.Wrapf(err, "can't add errors of %s to plot %s", line.Name, title)
+		}
+	}
+	ep.X.Min, ep.X.Max = p.X.Min, p.X.Max
+
+	height := length(img.Height)
+	err = encode(wr, func(c draw.Canvas) {
+		p.Draw(draw.Crop(c, 0, 0, height*errorsShare, 0))
+		ep.Draw(draw.Crop(c, 0, 0, 0, -height*(1-errorsShare)))
+	}, length(img.Width), height, img.Format)
+	return errors.Wrapf(err, "failed to write plot %s", title)
+}
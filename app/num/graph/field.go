@@ -0,0 +1,71 @@
+package graph
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/Semior001/decompract/app/num/field"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// fieldPlotter draws the segments of the slope field,
+// scaled to fit the grid cells
+type fieldPlotter struct {
+	segs  []field.Segment
+	scale float64
+	style draw.LineStyle
+}
+
+func newFieldPlotter(segs []field.Segment) *fieldPlotter {
+	xs, ys := map[float64]bool{}, map[float64]bool{}
+	for _, s := range segs {
+		xs[s.Center.X], ys[s.Center.Y] = true, true
+	}
+
+	fp := &fieldPlotter{
+		segs:  segs,
+		style: draw.LineStyle{Color: color.Gray{Y: 160}, Width: vg.Points(1)},
+	}
+	xmin, xmax, ymin, ymax := fp.centersRange()
+	cell := math.Inf(1)
+	if len(xs) > 1 {
+		cell = math.Min(cell, (xmax-xmin)/float64(len(xs)-1))
+	}
+	if len(ys) > 1 {
+		cell = math.Min(cell, (ymax-ymin)/float64(len(ys)-1))
+	}
+	fp.scale = 1
+	if !math.IsInf(cell, 1) {
+		fp.scale = 0.8 * cell
+	}
+	return fp
+}
+
+// Plot implements plot.Plotter to draw the segments on the canvas
+func (fp *fieldPlotter) Plot(c draw.Canvas, plt *plot.Plot) {
+	trX, trY := plt.Transforms(&c)
+	for _, s := range fp.segs {
+		dx, dy := (s.To.X-s.Center.X)*fp.scale, (s.To.Y-s.Center.Y)*fp.scale
+		c.StrokeLine2(fp.style,
+			trX(s.Center.X-dx), trY(s.Center.Y-dy),
+			trX(s.Center.X+dx), trY(s.Center.Y+dy),
+		)
+	}
+}
+
+// DataRange implements plot.DataRanger to fit the field into the plot
+func (fp *fieldPlotter) DataRange() (xmin, xmax, ymin, ymax float64) {
+	return fp.centersRange()
+}
+
+func (fp *fieldPlotter) centersRange() (xmin, xmax, ymin, ymax float64) {
+	xmin, ymin = math.Inf(1), math.Inf(1)
+	xmax, ymax = math.Inf(-1), math.Inf(-1)
+	for _, s := range fp.segs {
+		xmin, xmax = math.Min(xmin, s.Center.X), math.Max(xmax, s.Center.X)
+		ymin, ymax = math.Min(ymin, s.Center.Y), math.Max(ymax, s.Center.Y)
+	}
+	return xmin, xmax, ymin, ymax
+}
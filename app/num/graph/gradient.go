@@ -0,0 +1,128 @@
+package graph
+
+import (
+	"bytes"
+	"image/color"
+	"math"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/palette"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// colorBarShare is the share of the height of the image, taken by the color bar
+const colorBarShare = 0.15
+
+// PlotGradient plots the family of lines by values of the parameter to the image of the given size and format,
+// lines are colored along the gradient from blue for the least value to red for the largest one, and the color bar
+// with the name of the parameter is drawn below the plot instead of the legend
+func (pl *Plotter) PlotGradient(title, xTitle, yTitle, param string, values []float64, lines [][]num.Point,
+	img Image) ([]byte, error) {
+	if img.Format != "png" && img.Format != "svg" {
+		return nil, errors.Errorf("unsupported format %q", img.Format)
+	}
+	if len(values) != len(lines) {
+		return nil, errors.Errorf("%d values are given for %d lines", len(values), len(lines))
+	}
+
+	p, err := plot.New()
+	if err != nil {
+		return nil, errors.Wrap(err, "can't create new plot")
+	}
+	p.Title.Text = title
+	p.X.Label.Text = xTitle
+	p.Y.Label.Text = yTitle
+
+	cm := newGradient(values)
+	for i, pts := range lines {
+		l, err := plotter.NewLine(ptsToXYs(pts))
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't create line of %s=%v", param, values[i])
+		}
+		if l.Color, err = cm.At(values[i]); err != nil {
+			return nil, errors.Wrapf(err, "can't color line of %s=%v", param, values[i])
+		}
+		l.Width = vg.Points(1.5)
+		p.Add(l)
+	}
+
+	bar, err := plot.New()
+	if err != nil {
+		return nil, errors.Wrap(err, "can't create color bar")
+	}
+	bar.HideY()
+	bar.X.Label.Text = param
+	bar.X.Min, bar.X.Max = cm.Min(), cm.Max()
+	bar.Add(&plotter.ColorBar{ColorMap: cm})
+
+	drawFn := func(c draw.Canvas) {
+		barH := (c.Max.Y - c.Min.Y) * colorBarShare
+		p.Draw(draw.Crop(c, 0, 0, barH, 0))
+		bar.Draw(draw.Crop(c, 0, 0, 0, barH-(c.Max.Y-c.Min.Y)))
+	}
+
+	b := &bytes.Buffer{}
+	if err = encode(b, drawFn, length(img.Width), length(img.Height), img.Format); err != nil {
+		return nil, errors.Wrapf(err, "failed to write plot %s", title)
+	}
+	return b.Bytes(), nil
+}
+
+// gradient is the color map from blue to red by hue, the range of the single value is widened,
+// so the color bar is not empty
+type gradient struct {
+	min, max, alpha float64
+}
+
+func newGradient(values []float64) *gradient {
+	g := &gradient{min: math.Inf(1), max: math.Inf(-1), alpha: 1}
+	for _, v := range values {
+		g.min, g.max = math.Min(g.min, v), math.Max(g.max, v)
+	}
+	if len(values) == 0 {
+		g.min, g.max = 0, 1
+	}
+	if g.min == g.max {
+		g.min, g.max = g.min-0.5, g.max+0.5
+	}
+	return g
+}
+
+// At returns the color of the value, hue goes from 2/3 (blue) at min to 0 (red) at max
+func (g *gradient) At(v float64) (color.Color, error) {
+	if !(v >= g.min && v <= g.max) {
+		return nil, errors.Errorf("value %v is out of range [%v, %v]", v, g.min, g.max)
+	}
+	t := (v - g.min) / (g.max - g.min)
+	return palette.HSVA{H: 2.0 / 3 * (1 - t), S: 1, V: 0.9, A: g.alpha}, nil
+}
+
+func (g *gradient) Max() float64       { return g.max }
+func (g *gradient) SetMax(v float64)   { g.max = v }
+func (g *gradient) Min() float64       { return g.min }
+func (g *gradient) SetMin(v float64)   { g.min = v }
+func (g *gradient) Alpha() float64     { return g.alpha }
+func (g *gradient) SetAlpha(v float64) { g.alpha = v }
+
+// Palette returns n colors of the gradient from min to max
+func (g *gradient) Palette(n int) palette.Palette {
+	res := make(colors, n)
+	for i := range res {
+		t := 0.0
+		if n > 1 {
+			t = float64(i) / float64(n-1)
+		}
+		res[i], _ = g.At(g.min + t*(g.max-g.min))
+	}
+	return res
+}
+
+// colors is the palette of the gradient
+type colors []color.Color
+
+// Colors returns colors of the palette
+func (c colors) Colors() []color.Color { return c }
@@ -0,0 +1,54 @@
+package graph
+
+import (
+	"bytes"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/plotutil"
+)
+
+// PlotPhase plots trajectories of the phase portrait to the image of the given size and format, trajectories
+// are drawn as polylines without points and the legend, as there are many of them, the start of each
+// trajectory is marked
+func (pl *Plotter) PlotPhase(title, xTitle, yTitle string, trs [][]num.Point, img Image) ([]byte, error) {
+	if img.Format != "png" && img.Format != "svg" {
+		return nil, errors.Errorf("unsupported format %q", img.Format)
+	}
+	p, err := plot.New()
+	if err != nil {
+		return nil, errors.Wrap(err, "can't create new plot")
+	}
+	p.Title.Text = title
+	p.X.Label.Text = xTitle
+	p.Y.Label.Text = yTitle
+
+	starts := make(plotter.XYs, 0, len(trs))
+	for i, tr := range trs {
+		if len(tr) == 0 {
+			continue
+		}
+		l, err := plotter.NewLine(ptsToXYs(tr))
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't create trajectory %d", i)
+		}
+		l.Color = plotutil.Color(i)
+		p.Add(l)
+		starts = append(starts, plotter.XY{X: tr[0].X, Y: tr[0].Y})
+	}
+	if len(starts) > 0 {
+		s, err := plotter.NewScatter(starts)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't create starts of trajectories")
+		}
+		p.Add(s)
+	}
+
+	b := &bytes.Buffer{}
+	if err = encode(b, p.Draw, length(img.Width), length(img.Height), img.Format); err != nil {
+		return nil, errors.Wrapf(err, "failed to write plot %s", title)
+	}
+	return b.Bytes(), nil
+}
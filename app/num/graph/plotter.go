@@ -2,13 +2,22 @@ package graph
 
 import (
 	"bytes"
+	"image"
+	"image/png"
+	"io"
+	"sync"
 
 	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/field"
+	"github.com/Semior001/decompract/app/num/interp"
+	"github.com/fogleman/gg"
 	"gonum.org/v1/plot/plotter"
 
 	"gonum.org/v1/plot/plotutil"
 
 	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
 
 	"github.com/pkg/errors"
 	"gonum.org/v1/plot"
@@ -20,44 +29,216 @@ const h = w
 // Plotter plots the image and saves it in temporary directory
 type Plotter struct{}
 
+// Image describes the size of the rendered image in pixels and its format, png or svg
+type Image struct {
+	Width  int
+	Height int
+	Format string
+}
+
+// length converts the number of pixels to the length on the canvas
+func length(px int) vg.Length {
+	return vg.Length(px) * vg.Inch / vgimg.DefaultDPI
+}
+
 // Plot the set of lines and get the reader of the result plot
 func (pl *Plotter) Plot(title, xTitle, yTitle string, lines []num.Line) ([]byte, error) {
+	return pl.PlotField(title, xTitle, yTitle, nil, lines)
+}
+
+// PlotField plots the set of lines over the slope field
+func (pl *Plotter) PlotField(title, xTitle, yTitle string, segs []field.Segment, lines []num.Line) ([]byte, error) {
+	return pl.render(title, xTitle, yTitle, segs, lines, w, h, "png", false)
+}
+
+// PlotImage plots the set of lines to the image of the given size and format
+func (pl *Plotter) PlotImage(title, xTitle, yTitle string, lines []num.Line, img Image) ([]byte, error) {
+	b := &bytes.Buffer{}
+	if err := pl.WriteImage(b, title, xTitle, yTitle, lines, img); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// WriteImage plots the set of lines to the image of the given size and format, as PlotImage does,
+// and writes it to wr, so the caller might reuse the buffer of the image
+func (pl *Plotter) WriteImage(wr io.Writer, title, xTitle, yTitle string, lines []num.Line, img Image) error {
+	return pl.WriteFieldImage(wr, title, xTitle, yTitle, nil, lines, img)
+}
+
+// WriteFieldImage plots the set of lines over the slope field, as WriteImage does, the field is not drawn, if it is empty
+func (pl *Plotter) WriteFieldImage(wr io.Writer, title, xTitle, yTitle string, segs []field.Segment, lines []num.Line,
+	img Image) error {
+	if img.Format != "png" && img.Format != "svg" {
+		return errors.Errorf("unsupported format %q", img.Format)
+	}
+	return pl.write(wr, title, xTitle, yTitle, segs, lines, length(img.Width), length(img.Height), img.Format, false)
+}
+
+// PlotLogLog plots the set of lines with logarithmic axes, as PlotImage does, values must be positive
+func (pl *Plotter) PlotLogLog(title, xTitle, yTitle string, lines []num.Line, img Image) ([]byte, error) {
+	if img.Format != "png" && img.Format != "svg" {
+		return nil, errors.Errorf("unsupported format %q", img.Format)
+	}
+	for _, line := range lines {
+		for _, pt := range line.Points {
+			if pt.X <= 0 || pt.Y <= 0 {
+				return nil, errors.Errorf("point (%g, %g) of line %s can't be drawn on logarithmic axes", pt.X, pt.Y, line.Name)
+			}
+		}
+	}
+	return pl.render(title, xTitle, yTitle, nil, lines, length(img.Width), length(img.Height), img.Format, true)
+}
+
+// render plots the set of lines over the slope field to the image of the given size and format,
+// both axes are logarithmic, if logLog is set
+func (pl *Plotter) render(title, xTitle, yTitle string, segs []field.Segment, lines []num.Line,
+	width, height vg.Length, format string, logLog bool) ([]byte, error) {
+	b := &bytes.Buffer{}
+	if err := pl.write(b, title, xTitle, yTitle, segs, lines, width, height, format, logLog); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// write plots the set of lines over the slope field, as render does, and writes the image to wr
+func (pl *Plotter) write(wr io.Writer, title, xTitle, yTitle string, segs []field.Segment, lines []num.Line,
+	width, height vg.Length, format string, logLog bool) error {
 	p, err := plot.New()
 	if err != nil {
-		return nil, errors.Wrap(err, "can't create new plot")
+		return errors.Wrap(err, "can't create new plot")
 	}
 
 	p.Title.Text = title
 	p.X.Label.Text = xTitle
 	p.Y.Label.Text = yTitle
+	if logLog {
+		p.X.Scale, p.Y.Scale = plot.LogScale{}, plot.LogScale{}
+		p.X.Tick.Marker, p.Y.Tick.Marker = plot.LogTicks{}, plot.LogTicks{}
+	}
 
-	// combining lines in one slice to satisfy idiotic plotutil's interface
-	var ls []interface{}
-	for _, line := range lines {
-		ls = append(ls, line.Name, ptsToXYs(line.Points))
+	if len(segs) > 0 {
+		p.Add(newFieldPlotter(segs))
 	}
 
-	if err = plotutil.AddLinePoints(p, ls...); err != nil {
-		return nil, errors.Wrapf(err, "can't add lines to plot %s", title)
+	for i, line := range lines {
+		if err = addLine(p, i, line); err != nil {
+			return errors.Wrapf(err, "can't add line %s to plot %s", line.Name, title)
+		}
 	}
 
-	b := &bytes.Buffer{}
+	if format == "png" {
+		return errors.Wrapf(writePNG(wr, p, width, height), "failed to write plot %s", title)
+	}
 
-	wt, err := p.WriterTo(w, h, "png")
+	wt, err := p.WriterTo(width, height, format)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to instantiate writer for the plot %s", title)
+		return errors.Wrapf(err, "failed to instantiate writer for the plot %s", title)
 	}
 
-	if _, err := wt.WriteTo(b); err != nil {
-		return nil, errors.Wrapf(err, "failed to write plot to buffer for %s", title)
+	if _, err := wt.WriteTo(wr); err != nil {
+		return errors.Wrapf(err, "failed to write plot to buffer for %s", title)
 	}
 
-	return b.Bytes(), nil
+	return nil
+}
+
+// pixels are buffers of pixels of png images, each render takes the buffer from the pool,
+// so the image of the same or the smaller size doesn't allocate its pixels
+var pixels = sync.Pool{}
+
+// pngEncoder encodes png images with the reused state of the encoder
+var pngEncoder = png.Encoder{BufferPool: &pngBuffers{}}
+
+// pngBuffers is the pool of states of the png encoder
+type pngBuffers struct{ pool sync.Pool }
+
+// Get returns the state from the pool or nil, so the encoder makes the new one
+func (b *pngBuffers) Get() *png.EncoderBuffer {
+	buf, _ := b.pool.Get().(*png.EncoderBuffer)
+	return buf
+}
+
+// Put returns the state to the pool
+func (b *pngBuffers) Put(buf *png.EncoderBuffer) { b.pool.Put(buf) }
+
+// writePNG draws the plot on the canvas with pixels from the pool and writes it as png, the canvas
+// fills the whole image with the background before drawing, so nothing is left from the previous image
+func writePNG(wr io.Writer, p *plot.Plot, width, height vg.Length) error {
+	return writePNGWith(wr, p.Draw, width, height)
+}
+
+// encode draws the image by fn on the canvas of the format, png or svg, and writes it to wr
+func encode(wr io.Writer, fn func(c draw.Canvas), width, height vg.Length, format string) error {
+	if format == "png" {
+		return writePNGWith(wr, fn, width, height)
+	}
+	c, err := draw.NewFormattedCanvas(width, height, format)
+	if err != nil {
+		return errors.Wrap(err, "failed to instantiate canvas")
+	}
+	fn(draw.New(c))
+	_, err = c.WriteTo(wr)
+	return err
+}
+
+// writePNGWith draws the image by fn on the canvas, as writePNG does, and writes it as png
+func writePNGWith(wr io.Writer, fn func(c draw.Canvas), width, height vg.Length) error {
+	wpx := int(width/vg.Inch*vgimg.DefaultDPI + 0.5)
+	hpx := int(height/vg.Inch*vgimg.DefaultDPI + 0.5)
+	pix, _ := pixels.Get().(*[]uint8)
+	if pix == nil || cap(*pix) < 4*wpx*hpx {
+		buf := make([]uint8, 4*wpx*hpx)
+		pix = &buf
+	}
+	defer pixels.Put(pix)
+
+	img := &image.RGBA{Pix: (*pix)[:4*wpx*hpx], Stride: 4 * wpx, Rect: image.Rect(0, 0, wpx, hpx)}
+	// the context is made as the canvas does, but without the copy of the image
+	ctx := gg.NewContextForRGBA(img)
+	ctx.SetLineCapButt()
+	ctx.InvertY()
+	fn(draw.New(vgimg.NewWith(vgimg.UseImageWithContext(img, ctx))))
+	return pngEncoder.Encode(wr, img)
+}
+
+// smoothSamples is the number of points in the interpolated curve of the smooth line
+const smoothSamples = 500
+
+// addLine adds the line with its points to the plot, with the style of i-th line
+func addLine(p *plot.Plot, i int, line num.Line) error {
+	pts := ptsToXYs(line.Points)
+	linePts := pts
+	if line.Smooth && len(line.Points) > 2 {
+		spl, err := interp.NewSpline(line.Points)
+		if err != nil {
+			return errors.Wrap(err, "can't interpolate line")
+		}
+		linePts = ptsToXYs(spl.Sample(smoothSamples))
+	}
+
+	l, err := plotter.NewLine(linePts)
+	if err != nil {
+		return errors.Wrap(err, "can't create line")
+	}
+	s, err := plotter.NewScatter(pts)
+	if err != nil {
+		return errors.Wrap(err, "can't create points")
+	}
+
+	l.Color = plotutil.Color(i)
+	l.Dashes = plotutil.Dashes(i)
+	s.Color = plotutil.Color(i)
+	s.Shape = plotutil.Shape(i)
+
+	p.Add(l, s)
+	p.Legend.Add(line.Name, l, s)
+	return nil
 }
 
 // ptsToXYs converts the service-layer points to plotter's interpretation
 func ptsToXYs(pts []num.Point) plotter.XYs {
-	var res plotter.XYs
+	res := make(plotter.XYs, 0, len(pts))
 	for _, p := range pts {
 		res = append(res, plotter.XY{X: p.X, Y: p.Y})
 	}
@@ -0,0 +1,90 @@
+package graph
+
+import (
+	"bytes"
+	"math"
+
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/pkg/errors"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/plotutil"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// PlotStability plots boundaries |R(z)| = 1 of stability regions of methods to the image of the given size
+// and format, axes of the complex plane are drawn through the origin, points, e.g. hλ of the problem, are marked,
+// unless there are none
+func (pl *Plotter) PlotStability(title string, regions []solver.Region, pts []complex128, img Image) ([]byte, error) {
+	if img.Format != "png" && img.Format != "svg" {
+		return nil, errors.Errorf("unsupported format %q", img.Format)
+	}
+	p, err := plot.New()
+	if err != nil {
+		return nil, errors.Wrap(err, "can't create new plot")
+	}
+	p.Title.Text = title
+	p.X.Label.Text = "Re(hλ)"
+	p.Y.Label.Text = "Im(hλ)"
+
+	for i, reg := range regions {
+		if len(reg.Re) == 0 || len(reg.Im) == 0 {
+			continue
+		}
+		style := draw.LineStyle{Color: plotutil.Color(i), Width: vg.Points(1.5), Dashes: plotutil.Dashes(i)}
+		c := plotter.NewContour(regionGrid(reg), []float64{1}, nil)
+		c.LineStyles = []draw.LineStyle{style}
+		p.Add(c)
+		p.Legend.Add(reg.Method, &plotter.Line{LineStyle: style})
+		if i == 0 {
+			p.X.Min, p.X.Max, p.Y.Min, p.Y.Max = reg.Re[0], reg.Re[len(reg.Re)-1], reg.Im[0], reg.Im[len(reg.Im)-1]
+		}
+	}
+
+	axes := draw.LineStyle{Color: plotter.DefaultLineStyle.Color, Width: vg.Points(0.5)}
+	re := plotter.NewFunction(func(float64) float64 { return 0 })
+	re.LineStyle = axes
+	p.Add(re, &imAxis{style: axes}, plotter.NewGrid())
+
+	if len(pts) > 0 {
+		xys := make(plotter.XYs, 0, len(pts))
+		for _, z := range pts {
+			xys = append(xys, plotter.XY{X: real(z), Y: imag(z)})
+		}
+		s, err := plotter.NewScatter(xys)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't create points")
+		}
+		s.Shape = plotutil.Shape(1)
+		p.Add(s)
+		p.Legend.Add("hλ", s)
+	}
+
+	b := &bytes.Buffer{}
+	if err = encode(b, p.Draw, length(img.Width), length(img.Height), img.Format); err != nil {
+		return nil, errors.Wrapf(err, "failed to write plot %s", title)
+	}
+	return b.Bytes(), nil
+}
+
+// regionGrid is the grid of |R(z)| of the stability region for the contour
+type regionGrid solver.Region
+
+func (g regionGrid) Dims() (c, r int)   { return len(g.Re), len(g.Im) }
+func (g regionGrid) Z(c, r int) float64 { return math.Min(g.Abs[r][c], math.MaxFloat64) }
+func (g regionGrid) X(c int) float64    { return g.Re[c] }
+func (g regionGrid) Y(r int) float64    { return g.Im[r] }
+
+// imAxis draws the imaginary axis through the origin over the whole height of the plot
+type imAxis struct{ style draw.LineStyle }
+
+// Plot draws the vertical line at x = 0
+func (a *imAxis) Plot(c draw.Canvas, p *plot.Plot) {
+	trX, _ := p.Transforms(&c)
+	x := trX(0)
+	if x < c.Min.X || x > c.Max.X {
+		return
+	}
+	c.StrokeLine2(a.style, x, c.Min.Y, x, c.Max.Y)
+}
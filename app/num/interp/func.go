@@ -0,0 +1,106 @@
+package interp
+
+import (
+	"sort"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// kinds of interpolation of AsFunc
+const (
+	KindLinear  = "linear"
+	KindCubic   = "cubic"   // natural cubic spline
+	KindHermite = "hermite" // piecewise cubic Hermite, requires slopes, see HermiteFunc
+)
+
+// Func is the interpolated solution, it fails for x out of the range of nodes
+type Func func(x float64) (float64, error)
+
+// AsFunc interpolates the points of the solution by the kind, linear or cubic, points must be sorted by x,
+// increasing or decreasing, as the backward solution goes, and must not contain duplicate x values, the hermite interpolation requires slopes at points,
+// so it is made by HermiteFunc
+func AsFunc(points []num.Point, kind string) (Func, error) {
+	switch kind {
+	case KindLinear:
+		xs, ys, err := nodes(points)
+		if err != nil {
+			return nil, err
+		}
+		return func(x float64) (float64, error) {
+			i, err := locate(xs, x)
+			if err != nil || xs[i] == x {
+				return ys[i], err
+			}
+			t := (x - xs[i-1]) / (xs[i] - xs[i-1])
+			return ys[i-1] + t*(ys[i]-ys[i-1]), nil
+		}, nil
+	case KindCubic:
+		s, err := NewSpline(points)
+		if err != nil {
+			return nil, err
+		}
+		return s.At, nil
+	case KindHermite:
+		return nil, errors.New("hermite interpolation requires slopes, use HermiteFunc")
+	default:
+		return nil, errors.Errorf("unknown kind of interpolation %q, must be %s, %s or %s", kind, KindLinear, KindCubic, KindHermite)
+	}
+}
+
+// HermiteFunc interpolates the points of the solution with slopes y' at them, e.g. f(x,y) of the equation,
+// by the piecewise cubic Hermite interpolant, as AsFunc does
+func HermiteFunc(points []num.Point, slopes []float64) (Func, error) {
+	h, err := NewHermite(points, slopes)
+	if err != nil {
+		return nil, err
+	}
+	return h.At, nil
+}
+
+// nodes splits the points into x and y of nodes in the increasing order, points must be sorted by x
+// without duplicates
+func nodes(points []num.Point) (xs, ys []float64, err error) {
+	if len(points) < 2 {
+		return nil, nil, errors.New("at least two points are required to build an interpolant")
+	}
+	points, _ = ascending(points, nil)
+	xs, ys = make([]float64, len(points)), make([]float64, len(points))
+	for i, p := range points {
+		if i > 0 && p.X <= points[i-1].X {
+			return nil, nil, errors.Errorf("points are unsorted or contain duplicate x at i=%d, x=%.4f", i, p.X)
+		}
+		xs[i], ys[i] = p.X, p.Y
+	}
+	return xs, ys, nil
+}
+
+// ascending returns points in the order of increasing x along with their slopes, points of the backward
+// solution go with decreasing x, so they are reversed into copies, slopes are nil, if there are no ones
+func ascending(points []num.Point, slopes []float64) ([]num.Point, []float64) {
+	n := len(points)
+	if n < 2 || points[0].X <= points[n-1].X {
+		return points, slopes
+	}
+	pts := make([]num.Point, n)
+	for i, p := range points {
+		pts[n-1-i] = p
+	}
+	if slopes == nil {
+		return pts, nil
+	}
+	ds := make([]float64, n)
+	for i, d := range slopes {
+		ds[n-1-i] = d
+	}
+	return pts, ds
+}
+
+// locate returns the index of the first node, that is not less than x, x must be inside the range of nodes
+func locate(xs []float64, x float64) (int, error) {
+	n := len(xs)
+	if !(x >= xs[0] && x <= xs[n-1]) {
+		return 0, errors.Errorf("x=%.4f is out of the range [%.4f, %.4f]", x, xs[0], xs[n-1])
+	}
+	return sort.SearchFloat64s(xs, x), nil
+}
@@ -0,0 +1,110 @@
+package interp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsFunc_RoundTrip(t *testing.T) {
+	f := func(x, y float64) (float64, error) { return x*x - 2*y, nil }
+	exact := &solver.Exact{
+		F: func(x, c float64) (float64, error) { return x*x/2 - x/2 + 0.25 + c*math.Exp(-2*x), nil },
+		C: func(x0, y0 float64) (float64, error) { return (y0 - x0*x0/2 + x0/2 - 0.25) * math.Exp(2*x0), nil },
+	}
+	c, err := exact.Constant(0, 1)
+	require.NoError(t, err)
+	ref := exact.Memo(c, 0)
+
+	rk := &solver.Collector{}
+	require.NoError(t, (&solver.RungeKutta{F: f}).Solve(0.1, 0, 1, 1, rk))
+	require.Len(t, rk.Points, 11)
+	slopes := make([]float64, len(rk.Points))
+	for i, p := range rk.Points {
+		slopes[i], err = f(p.X, p.Y)
+		require.NoError(t, err)
+	}
+
+	lin, err := AsFunc(rk.Points, KindLinear)
+	require.NoError(t, err)
+	cub, err := AsFunc(rk.Points, KindCubic)
+	require.NoError(t, err)
+	her, err := HermiteFunc(rk.Points, slopes)
+	require.NoError(t, err)
+
+	funcs := []struct {
+		name  string
+		fn    Func
+		delta float64
+	}{
+		{name: KindLinear, fn: lin, delta: 5e-3}, // h^2/8*max|y''|, y'' is 4 at most
+		{name: KindCubic, fn: cub, delta: 3e-3},  // natural ends, y'' is 4 there instead of zero
+		{name: KindHermite, fn: her, delta: 1e-5},
+	}
+	for _, tt := range funcs {
+		for _, p := range rk.Points {
+			y, err := tt.fn(p.X)
+			require.NoError(t, err, tt.name)
+			assert.InDelta(t, p.Y, y, 1e-12, "%s, x=%.4f", tt.name, p.X)
+		}
+		for _, x := range []float64{0.05, 0.33, 0.5, 0.71, 0.95} {
+			y, err := tt.fn(x)
+			require.NoError(t, err, tt.name)
+			want, err := ref.At(x)
+			require.NoError(t, err)
+			assert.InDelta(t, want, y, tt.delta, "%s, x=%.4f", tt.name, x)
+		}
+		_, err = tt.fn(1.5)
+		assert.EqualError(t, err, "x=1.5000 is out of the range [0.0000, 1.0000]", tt.name)
+		_, err = tt.fn(-0.1)
+		assert.Error(t, err, tt.name)
+	}
+}
+
+func TestAsFunc_Backward(t *testing.T) {
+	// points of the backward solution go with decreasing x, they are interpolated the same
+	pts := []num.Point{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 4}, {X: 3, Y: 9}}
+	back := []num.Point{pts[3], pts[2], pts[1], pts[0]}
+	for _, kind := range []string{KindLinear, KindCubic} {
+		fwd, err := AsFunc(pts, kind)
+		require.NoError(t, err, kind)
+		bwd, err := AsFunc(back, kind)
+		require.NoError(t, err, kind)
+		for _, x := range []float64{0, 0.5, 1.7, 3} {
+			want, err := fwd(x)
+			require.NoError(t, err, kind)
+			got, err := bwd(x)
+			require.NoError(t, err, kind)
+			assert.Equal(t, want, got, "%s, x=%v", kind, x)
+		}
+	}
+	her, err := HermiteFunc(back, []float64{6, 4, 2, 0})
+	require.NoError(t, err)
+	y, err := her(1.5)
+	require.NoError(t, err)
+	assert.InDelta(t, 2.25, y, 1e-12, "slopes are reversed along with points")
+	assert.Equal(t, []num.Point{{X: 3, Y: 9}, {X: 2, Y: 4}, {X: 1, Y: 1}, {X: 0, Y: 0}}, back, "points are not changed")
+}
+
+func TestAsFunc_Invalid(t *testing.T) {
+	pts := []num.Point{{X: 0, Y: 1}, {X: 1, Y: 2}, {X: 1, Y: 3}}
+	for _, kind := range []string{KindLinear, KindCubic} {
+		_, err := AsFunc(pts, kind)
+		assert.EqualError(t, err, "points are unsorted or contain duplicate x at i=2, x=1.0000", kind)
+		_, err = AsFunc([]num.Point{{X: 0, Y: 2}, {X: 2, Y: 1}, {X: 1, Y: 1}}, kind)
+		assert.EqualError(t, err, "points are unsorted or contain duplicate x at i=2, x=1.0000", kind)
+		_, err = AsFunc(pts[:1], kind)
+		assert.Error(t, err, kind)
+	}
+
+	_, err := AsFunc(pts[:2], KindHermite)
+	assert.EqualError(t, err, "hermite interpolation requires slopes, use HermiteFunc")
+	_, err = AsFunc(pts[:2], "quadratic")
+	assert.EqualError(t, err, `unknown kind of interpolation "quadratic", must be linear, cubic or hermite`)
+	_, err = HermiteFunc(pts, []float64{0, 0, 0})
+	assert.Error(t, err)
+}
@@ -0,0 +1,62 @@
+package interp
+
+import (
+	"sort"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// Hermite is a piecewise cubic Hermite interpolant, built through the set of nodes with known slopes,
+// unlike the spline, it is local, so its error is of the fourth order on each interval, including the ends
+type Hermite struct {
+	xs []float64
+	ys []float64
+	ds []float64 // slopes at nodes
+}
+
+// NewHermite builds the interpolant through the given points with the given slopes,
+// points must be sorted by x, increasing or decreasing, and must not contain duplicate x values
+func NewHermite(points []num.Point, slopes []float64) (*Hermite, error) {
+	if len(points) < 2 {
+		return nil, errors.New("at least two points are required to build an interpolant")
+	}
+	if len(slopes) != len(points) {
+		return nil, errors.Errorf("got %d slopes for %d points", len(slopes), len(points))
+	}
+	points, slopes = ascending(points, slopes)
+
+	h := &Hermite{xs: make([]float64, len(points)), ys: make([]float64, len(points)), ds: slopes}
+	for i, p := range points {
+		if i > 0 && p.X <= points[i-1].X {
+			return nil, errors.Errorf("points are unsorted or contain duplicate x at i=%d, x=%.4f", i, p.X)
+		}
+		h.xs[i], h.ys[i] = p.X, p.Y
+	}
+	return h, nil
+}
+
+// Range returns the first and the last x of nodes
+func (h *Hermite) Range() (lo, hi float64) {
+	return h.xs[0], h.xs[len(h.xs)-1]
+}
+
+// At returns the value of the interpolant at x, x must be inside the range of nodes
+func (h *Hermite) At(x float64) (float64, error) {
+	n := len(h.xs)
+	if x < h.xs[0] || x > h.xs[n-1] {
+		return 0, errors.Errorf("x=%.4f is out of the range [%.4f, %.4f]", x, h.xs[0], h.xs[n-1])
+	}
+
+	i := sort.SearchFloat64s(h.xs, x)
+	if h.xs[i] == x {
+		return h.ys[i], nil
+	}
+
+	// x is in the interval (x[i-1], x[i])
+	w := h.xs[i] - h.xs[i-1]
+	t := (x - h.xs[i-1]) / w
+	t2, t3 := t*t, t*t*t
+	return (2*t3-3*t2+1)*h.ys[i-1] + (t3-2*t2+t)*w*h.ds[i-1] +
+		(-2*t3+3*t2)*h.ys[i] + (t3-t2)*w*h.ds[i], nil
+}
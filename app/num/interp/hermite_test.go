@@ -0,0 +1,51 @@
+package interp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHermite_At(t *testing.T) {
+	var pts []num.Point
+	var slopes []float64
+	for i := 0; i <= 10; i++ {
+		x := float64(i) * 0.3
+		pts = append(pts, num.Point{X: x, Y: math.Sin(x)})
+		slopes = append(slopes, math.Cos(x))
+	}
+	h, err := NewHermite(pts, slopes)
+	require.NoError(t, err)
+
+	lo, hi := h.Range()
+	assert.Equal(t, 0.0, lo)
+	assert.Equal(t, 3.0, hi)
+
+	for _, p := range pts {
+		y, err := h.At(p.X)
+		require.NoError(t, err)
+		assert.Equal(t, p.Y, y, "x=%.4f", p.X)
+	}
+
+	// the error is of the fourth order, h^4/384*max|y''''| at most, the ends are as precise as the middle
+	for _, x := range []float64{0.05, 1.05, 2.95} {
+		y, err := h.At(x)
+		require.NoError(t, err)
+		assert.InDelta(t, math.Sin(x), y, 0.3*0.3*0.3*0.3/384, "x=%.4f", x)
+	}
+
+	_, err = h.At(3.5)
+	assert.Error(t, err)
+}
+
+func TestNewHermite_Invalid(t *testing.T) {
+	_, err := NewHermite([]num.Point{{X: 0, Y: 0}}, []float64{0})
+	assert.Error(t, err)
+	_, err = NewHermite([]num.Point{{X: 0, Y: 0}, {X: 1, Y: 1}}, []float64{0})
+	assert.Error(t, err)
+	_, err = NewHermite([]num.Point{{X: 0, Y: 0}, {X: 0, Y: 1}}, []float64{0, 0})
+	assert.Error(t, err)
+}
@@ -0,0 +1,106 @@
+// Package interp provides interpolation of the calculated points of solutions.
+package interp
+
+import (
+	"sort"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// Spline is a natural cubic spline, built through the set of nodes
+type Spline struct {
+	xs []float64
+	ys []float64
+	ms []float64 // second derivatives at nodes
+}
+
+// NewSpline builds the natural cubic spline through the given points,
+// points must be sorted by x, increasing or decreasing, and must not contain duplicate x values
+func NewSpline(points []num.Point) (*Spline, error) {
+	if len(points) < 2 {
+		return nil, errors.New("at least two points are required to build a spline")
+	}
+	points, _ = ascending(points, nil)
+
+	s := &Spline{xs: make([]float64, len(points)), ys: make([]float64, len(points))}
+	for i, p := range points {
+		if i > 0 && p.X <= points[i-1].X {
+			return nil, errors.Errorf("points are unsorted or contain duplicate x at i=%d, x=%.4f", i, p.X)
+		}
+		s.xs[i], s.ys[i] = p.X, p.Y
+	}
+
+	s.ms = s.secondDerivatives()
+	return s, nil
+}
+
+// secondDerivatives solves the tridiagonal system for the second derivatives
+// of the spline at the nodes with the Thomas algorithm, ends are natural (zero)
+func (s *Spline) secondDerivatives() []float64 {
+	n := len(s.xs)
+	ms := make([]float64, n)
+	if n < 3 {
+		return ms
+	}
+
+	// c' and d' coefficients of the forward sweep
+	cp := make([]float64, n)
+	dp := make([]float64, n)
+	for i := 1; i < n-1; i++ {
+		hPrev, h := s.xs[i]-s.xs[i-1], s.xs[i+1]-s.xs[i]
+		a, b, c := hPrev, 2*(hPrev+h), h
+		d := 6 * ((s.ys[i+1]-s.ys[i])/h - (s.ys[i]-s.ys[i-1])/hPrev)
+
+		denom := b - a*cp[i-1]
+		cp[i] = c / denom
+		dp[i] = (d - a*dp[i-1]) / denom
+	}
+
+	for i := n - 2; i > 0; i-- {
+		ms[i] = dp[i] - cp[i]*ms[i+1]
+	}
+	return ms
+}
+
+// At returns the value of the spline at x, x must be inside the range of nodes
+func (s *Spline) At(x float64) (float64, error) {
+	n := len(s.xs)
+	if x < s.xs[0] || x > s.xs[n-1] {
+		return 0, errors.Errorf("x=%.4f is out of the range [%.4f, %.4f]", x, s.xs[0], s.xs[n-1])
+	}
+
+	i := sort.SearchFloat64s(s.xs, x)
+	if s.xs[i] == x {
+		return s.ys[i], nil
+	}
+
+	// x is in the interval (x[i-1], x[i])
+	xl, xr := s.xs[i-1], s.xs[i]
+	h := xr - xl
+	a, b := xr-x, x-xl
+	return s.ms[i-1]*a*a*a/(6*h) + s.ms[i]*b*b*b/(6*h) +
+		(s.ys[i-1]/h-s.ms[i-1]*h/6)*a + (s.ys[i]/h-s.ms[i]*h/6)*b, nil
+}
+
+// Sample returns n evenly spaced points of the spline, from the first to the last node
+func (s *Spline) Sample(n int) []num.Point {
+	if n < 1 {
+		return nil
+	}
+	x0, xEnd := s.xs[0], s.xs[len(s.xs)-1]
+	if n == 1 {
+		return []num.Point{{X: x0, Y: s.ys[0]}}
+	}
+
+	res := make([]num.Point, n)
+	for i := range res {
+		x := x0 + (xEnd-x0)*float64(i)/float64(n-1)
+		if i == n-1 {
+			x = xEnd
+		}
+		y, _ := s.At(x) // x is always in range
+		res[i] = num.Point{X: x, Y: y}
+	}
+	return res
+}
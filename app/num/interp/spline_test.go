@@ -0,0 +1,59 @@
+package interp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpline_PassesThroughNodes(t *testing.T) {
+	var pts []num.Point
+	for i := 0; i <= 10; i++ {
+		x := float64(i) * 0.3
+		pts = append(pts, num.Point{X: x, Y: math.Sin(x)})
+	}
+	s, err := NewSpline(pts)
+	require.NoError(t, err)
+
+	for _, p := range pts {
+		y, err := s.At(p.X)
+		require.NoError(t, err)
+		assert.Equal(t, p.Y, y, "x=%.4f", p.X)
+	}
+
+	// between nodes spline is close to the original function
+	y, err := s.At(1.05)
+	require.NoError(t, err)
+	assert.InDelta(t, math.Sin(1.05), y, 0.001)
+
+	_, err = s.At(3.5)
+	assert.Error(t, err)
+}
+
+func TestSpline_SampleLinear(t *testing.T) {
+	pts := []num.Point{{X: -1, Y: -1}, {X: 0, Y: 1}, {X: 0.5, Y: 2}, {X: 2, Y: 5}}
+	s, err := NewSpline(pts)
+	require.NoError(t, err)
+
+	smpl := s.Sample(100)
+	require.Len(t, smpl, 100)
+	assert.Equal(t, -1.0, smpl[0].X)
+	assert.Equal(t, 2.0, smpl[99].X)
+	for _, p := range smpl {
+		assert.InDelta(t, 2*p.X+1, p.Y, 1e-12, "x=%.4f", p.X)
+	}
+}
+
+func TestNewSpline_Invalid(t *testing.T) {
+	_, err := NewSpline([]num.Point{{X: 0, Y: 0}})
+	assert.Error(t, err)
+	_, err = NewSpline([]num.Point{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 1, Y: 2}})
+	assert.Error(t, err)
+	_, err = NewSpline([]num.Point{{X: 0, Y: 0}, {X: 2, Y: 1}, {X: 1, Y: 2}})
+	assert.Error(t, err)
+	_, err = NewSpline([]num.Point{{X: 2, Y: 0}, {X: 1, Y: 1}, {X: 1, Y: 2}})
+	assert.Error(t, err, "decreasing points must not contain duplicate x as well")
+}
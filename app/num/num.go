@@ -1,25 +1,92 @@
 package num
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// errors of intervals of solutions
+var (
+	// ErrEmptyInterval is returned, if the interval of the single point is split into steps
+	ErrEmptyInterval = errors.New("x0 equals x_end, the interval of zero length can't be split into steps")
+	// ErrBadStep is returned for the step, that is not positive or not finite, solvers can't go with it
+	ErrBadStep = errors.New("step must be positive and finite")
+	// ErrStepTooSmall is returned for the step, that doesn't advance x in floating point, or gives too many steps
+	ErrStepTooSmall = errors.New("step is too small to advance x")
+)
 
 // Line describes a particular line on a plot
 type Line struct {
 	Name   string
 	Points []Point
+	Smooth bool // draw the curve interpolated through points instead of the polyline
+}
+
+// Float is the numeric type of points and solvers, float64 or float32, or the type, defined over them
+type Float interface {
+	~float32 | ~float64
 }
 
-// Point describes a particular point on a plane
-type Point struct {
-	X float64
-	Y float64
+// PointOf describes a particular point on a plane with coordinates of the numeric type T
+type PointOf[T Float] struct {
+	X T `json:"x"`
+	Y T `json:"y"`
 }
 
+// Point describes a particular point on a plane, the api, drawers and analyzers work with float64 points
+type Point = PointOf[float64]
+
 // String implements fmt.Stringer to properly print points
-func (p Point) String() string {
+func (p PointOf[T]) String() string {
 	return fmt.Sprintf("(%.4f, %.4f)", p.X, p.Y)
 }
 
-// CalculateStepSize from the given number of steps
-func CalculateStepSize(n int, x0, x float64) float64 {
-	return (x - x0) / float64(n)
+// VectorPoint describes the point of the solution of the system, y has a component for each equation
+type VectorPoint struct {
+	X float64   `json:"x"`
+	Y []float64 `json:"y"`
+}
+
+// String implements fmt.Stringer to properly print points
+func (p VectorPoint) String() string {
+	ys := make([]string, len(p.Y))
+	for i, y := range p.Y {
+		ys[i] = fmt.Sprintf("%.4f", y)
+	}
+	return fmt.Sprintf("(%.4f, [%s])", p.X, strings.Join(ys, ", "))
+}
+
+// CalculateStepSize from the given number of steps, bounds must be finite, the interval must not be empty,
+// and the step must be finite, so the interval is not too wide. The step is the positive size of the step,
+// if x is less than x0, the solution goes backward with it
+func CalculateStepSize(n int, x0, x float64) (float64, error) {
+	if n < 1 {
+		return 0, errors.Errorf("number of steps must be positive, got %d", n)
+	}
+	if !isFinite(x0) || !isFinite(x) {
+		return 0, errors.Errorf("bounds of the interval must be finite, got x0=%v, x_end=%v", x0, x)
+	}
+	if x == x0 {
+		return 0, ErrEmptyInterval
+	}
+	h := math.Abs(x-x0) / float64(n)
+	if err := CheckStep(h); err != nil {
+		return 0, errors.Wrapf(err, "the interval [%v, %v] is too wide", x0, x)
+	}
+	return h, nil
+}
+
+// CheckStep returns ErrBadStep, if the step is not positive or not finite
+func CheckStep(h float64) error {
+	if !(h > 0) || math.IsInf(h, 1) {
+		return errors.Wrapf(ErrBadStep, "got %v", h)
+	}
+	return nil
+}
+
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
 }
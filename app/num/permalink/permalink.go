@@ -0,0 +1,285 @@
+// Package permalink encodes the definition of the problem into the short signed token,
+// so the problem is shared by the link without storing it on the server.
+package permalink
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// limits of the problem, so the token stays short enough to be the part of the url
+const (
+	MaxFormula = 1024    // length of each formula
+	MaxMethods = 16      // number of methods
+	MaxParams  = 16      // number of named constants
+	MaxSteps   = 1000000 // number of steps
+	MaxToken   = 4096    // length of the token
+)
+
+const (
+	version    = 1    // version of the encoding, the lower bits of the header
+	compressed = 0x80 // flag of the header, set if the payload is deflated
+	sigSize    = 8    // bytes of the signature at the end of the token
+)
+
+// ErrInvalidToken is returned, if the token is malformed or its signature doesn't match
+var ErrInvalidToken = errors.New("invalid token")
+
+// Problem is the shared definition of the problem
+type Problem struct {
+	F       string             // f(x,y) = y'
+	Exact   string             // y(x,c), the exact solution, optional
+	C       string             // C(x0,y0), the constant for the exact solution, optional
+	Params  map[string]float64 // named constants of formulas
+	X0      float64
+	Y0      float64
+	XEnd    float64
+	N       int // number of steps
+	Methods []string
+}
+
+// Validate checks that the problem is complete and fits the limits, formulas are not parsed
+func (p Problem) Validate() error {
+	switch {
+	case p.F == "":
+		return errors.New("f is required")
+	case len(p.F) > MaxFormula || len(p.Exact) > MaxFormula || len(p.C) > MaxFormula:
+		return errors.Errorf("formulas must be up to %d bytes", MaxFormula)
+	case !finite(p.X0) || !finite(p.Y0) || !finite(p.XEnd):
+		return errors.New("x0, y0 and x_end must be finite")
+	case p.X0 == p.XEnd:
+		return errors.New("x_end must differ from x0")
+	case p.N < 1 || p.N > MaxSteps:
+		return errors.Errorf("n must be between 1 and %d, got %d", MaxSteps, p.N)
+	case len(p.Methods) == 0 || len(p.Methods) > MaxMethods:
+		return errors.Errorf("between 1 and %d methods are required, got %d", MaxMethods, len(p.Methods))
+	case len(p.Params) > MaxParams:
+		return errors.Errorf("up to %d params are allowed, got %d", MaxParams, len(p.Params))
+	}
+	for _, m := range p.Methods {
+		if m == "" || len(m) > MaxFormula {
+			return errors.Errorf("invalid method %q", m)
+		}
+	}
+	for name, v := range p.Params {
+		if name == "" || len(name) > MaxFormula || !finite(v) {
+			return errors.Errorf("invalid param %q", name)
+		}
+	}
+	return nil
+}
+
+// Codec signs tokens with the secret, tokens of the other secret are invalid
+type Codec struct {
+	Secret []byte
+}
+
+// Encode validates the problem and encodes it into the token, safe for urls
+func (c Codec) Encode(p Problem) (string, error) {
+	if err := p.Validate(); err != nil {
+		return "", errors.Wrap(err, "invalid problem")
+	}
+
+	payload := marshal(p)
+	header := byte(version)
+	buf := &bytes.Buffer{}
+	fw, err := flate.NewWriter(buf, flate.BestCompression)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to make compressor")
+	}
+	if _, err = fw.Write(payload); err != nil {
+		return "", errors.Wrap(err, "failed to compress problem")
+	}
+	if err = fw.Close(); err != nil {
+		return "", errors.Wrap(err, "failed to compress problem")
+	}
+	if buf.Len() < len(payload) {
+		header, payload = header|compressed, buf.Bytes()
+	}
+
+	data := append([]byte{header}, payload...)
+	data = append(data, c.sign(data)...)
+	tok := base64.RawURLEncoding.EncodeToString(data)
+	if len(tok) > MaxToken {
+		return "", errors.Errorf("problem is too large to share, token must be up to %d bytes, got %d", MaxToken, len(tok))
+	}
+	return tok, nil
+}
+
+// Decode checks the signature of the token and decodes the problem, the malformed token is ErrInvalidToken
+func (c Codec) Decode(token string) (Problem, error) {
+	if len(token) > MaxToken {
+		return Problem{}, ErrInvalidToken
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(data) < 1+sigSize {
+		return Problem{}, ErrInvalidToken
+	}
+	data, sig := data[:len(data)-sigSize], data[len(data)-sigSize:]
+	if !hmac.Equal(sig, c.sign(data)) {
+		return Problem{}, ErrInvalidToken
+	}
+
+	header, payload := data[0], data[1:]
+	if header&^compressed != version {
+		return Problem{}, errors.Wrapf(ErrInvalidToken, "unknown version %d", header&^compressed)
+	}
+	if header&compressed != 0 {
+		// the signed payload can't be crafted, still, its size is limited
+		fr := flate.NewReader(bytes.NewReader(payload))
+		defer fr.Close()
+		if payload, err = ioutil.ReadAll(&limitedReader{r: fr, n: 4 * MaxToken}); err != nil {
+			return Problem{}, errors.Wrap(ErrInvalidToken, err.Error())
+		}
+	}
+
+	p, err := unmarshal(payload)
+	if err != nil {
+		return Problem{}, errors.Wrap(ErrInvalidToken, err.Error())
+	}
+	if err = p.Validate(); err != nil {
+		return Problem{}, errors.Wrap(ErrInvalidToken, err.Error())
+	}
+	return p, nil
+}
+
+// sign returns the truncated hmac of the data
+func (c Codec) sign(data []byte) []byte {
+	mac := hmac.New(sha256.New, c.Secret)
+	_, _ = mac.Write(data)
+	return mac.Sum(nil)[:sigSize]
+}
+
+// marshal writes strings with their lengths as uvarints, numbers as the shortest decimal strings,
+// which are shorter than eight bytes for numbers, typed by hand
+func marshal(p Problem) []byte {
+	var b []byte
+	uvarint := func(v uint64) {
+		var buf [binary.MaxVarintLen64]byte
+		b = append(b, buf[:binary.PutUvarint(buf[:], v)]...)
+	}
+	str := func(s string) {
+		uvarint(uint64(len(s)))
+		b = append(b, s...)
+	}
+	float := func(v float64) { str(strconv.FormatFloat(v, 'g', -1, 64)) }
+
+	str(p.F)
+	str(p.Exact)
+	str(p.C)
+	float(p.X0)
+	float(p.Y0)
+	float(p.XEnd)
+	uvarint(uint64(p.N))
+	uvarint(uint64(len(p.Methods)))
+	for _, m := range p.Methods {
+		str(m)
+	}
+	names := make([]string, 0, len(p.Params))
+	for name := range p.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	uvarint(uint64(len(names)))
+	for _, name := range names {
+		str(name)
+		float(p.Params[name])
+	}
+	return b
+}
+
+// unmarshal reads the problem, written by marshal
+func unmarshal(b []byte) (p Problem, err error) {
+	uvarint := func() uint64 {
+		if err != nil {
+			return 0
+		}
+		v, n := binary.Uvarint(b)
+		if n <= 0 {
+			err = errors.New("malformed number")
+			return 0
+		}
+		b = b[n:]
+		return v
+	}
+	str := func() string {
+		l := uvarint()
+		if err != nil {
+			return ""
+		}
+		if l > uint64(len(b)) {
+			err = errors.New("truncated string")
+			return ""
+		}
+		s := string(b[:l])
+		b = b[l:]
+		return s
+	}
+	float := func() float64 {
+		s := str()
+		if err != nil {
+			return 0
+		}
+		v, perr := strconv.ParseFloat(s, 64)
+		if perr != nil {
+			err = errors.Wrapf(perr, "malformed float %q", s)
+		}
+		return v
+	}
+	// count reads the number of entries or steps, which is up to max
+	count := func(max int) int {
+		n := uvarint()
+		if err == nil && n > uint64(max) {
+			err = errors.Errorf("%d is out of range, up to %d", n, max)
+		}
+		return int(n)
+	}
+
+	p.F, p.Exact, p.C = str(), str(), str()
+	p.X0, p.Y0, p.XEnd = float(), float(), float()
+	p.N = count(MaxSteps)
+	for i, n := 0, count(MaxMethods); i < n && err == nil; i++ {
+		p.Methods = append(p.Methods, str())
+	}
+	for i, n := 0, count(MaxParams); i < n && err == nil; i++ {
+		if p.Params == nil {
+			p.Params = map[string]float64{}
+		}
+		name := str()
+		p.Params[name] = float()
+	}
+	if err != nil {
+		return Problem{}, err
+	}
+	if len(b) > 0 {
+		return Problem{}, errors.Errorf("%d trailing bytes", len(b))
+	}
+	return p, nil
+}
+
+// limitedReader fails, if the reader has more than n bytes
+type limitedReader struct {
+	r io.Reader
+	n int
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	if l.n -= n; l.n < 0 {
+		return n, errors.New("payload is too large")
+	}
+	return n, err
+}
+
+func finite(v float64) bool { return !math.IsNaN(v) && !math.IsInf(v, 0) }
@@ -0,0 +1,106 @@
+package permalink
+
+import (
+	"encoding/base64"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testProblem = Problem{F: "x^2-2*y", Exact: "c*exp(-2*x) + x^2/2 - x/2 + 1/4",
+	C: "(y0 - x0^2/2 + x0/2 - 1/4)*exp(2*x0)", Params: map[string]float64{"k": 0.5, "a": -3},
+	X0: 0, Y0: 1, XEnd: 1.5, N: 10, Methods: []string{"euler", "rk4"}}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	c := Codec{Secret: []byte("secret")}
+	for _, p := range []Problem{
+		testProblem,
+		{F: "x", X0: -1e-300, Y0: math.MaxFloat64, XEnd: 2, N: MaxSteps, Methods: []string{"rk4"}},
+		{F: strings.Repeat("sin(x) + ", 100) + "y", X0: 0, Y0: 1, XEnd: 1, N: 1, Methods: []string{"euler"}},
+	} {
+		tok, err := c.Encode(p)
+		require.NoError(t, err)
+		assert.NotContains(t, tok, "/")
+		assert.NotContains(t, tok, "=")
+
+		decoded, err := c.Decode(tok)
+		require.NoError(t, err)
+		assert.Equal(t, p, decoded)
+	}
+
+	// the long repetitive formula is compressed
+	long := Problem{F: strings.Repeat("sin(x) + ", 100) + "y", X0: 0, Y0: 1, XEnd: 1, N: 1, Methods: []string{"euler"}}
+	tok, err := c.Encode(long)
+	require.NoError(t, err)
+	assert.Less(t, len(tok), len(long.F)/2)
+
+	// the same problem makes the same token
+	again, err := c.Encode(testProblem)
+	require.NoError(t, err)
+	tok, err = c.Encode(testProblem)
+	require.NoError(t, err)
+	assert.Equal(t, tok, again)
+}
+
+func TestCodec_DecodeInvalid(t *testing.T) {
+	c := Codec{Secret: []byte("secret")}
+	tok, err := c.Encode(testProblem)
+	require.NoError(t, err)
+
+	_, err = Codec{Secret: []byte("other")}.Decode(tok)
+	assert.True(t, errors.Is(err, ErrInvalidToken), "token of the other secret")
+
+	data, err := base64.RawURLEncoding.DecodeString(tok)
+	require.NoError(t, err)
+	data[3] ^= 1
+	_, err = c.Decode(base64.RawURLEncoding.EncodeToString(data))
+	assert.True(t, errors.Is(err, ErrInvalidToken), "tampered token")
+
+	for _, tok := range []string{"", "abc", "!!!", strings.Repeat("a", MaxToken+1), tok[:len(tok)-1]} {
+		_, err = c.Decode(tok)
+		assert.True(t, errors.Is(err, ErrInvalidToken), "token %q", tok)
+	}
+
+	// the signed payload is still checked
+	signed := func(payload []byte) string {
+		data := append([]byte{version}, payload...)
+		return base64.RawURLEncoding.EncodeToString(append(data, c.sign(data)...))
+	}
+	bad := testProblem
+	bad.N = 0
+	for name, payload := range map[string][]byte{
+		"trailing bytes": append(marshal(testProblem), 0),
+		"truncated":      marshal(testProblem)[:10],
+		"invalid":        marshal(bad),
+	} {
+		_, err = c.Decode(signed(payload))
+		assert.True(t, errors.Is(err, ErrInvalidToken), name)
+	}
+}
+
+func TestProblem_Validate(t *testing.T) {
+	assert.NoError(t, testProblem.Validate())
+
+	for name, mod := range map[string]func(p *Problem){
+		"no f":          func(p *Problem) { p.F = "" },
+		"long formula":  func(p *Problem) { p.Exact = strings.Repeat("x", MaxFormula+1) },
+		"nan":           func(p *Problem) { p.Y0 = math.NaN() },
+		"inf":           func(p *Problem) { p.XEnd = math.Inf(1) },
+		"empty":         func(p *Problem) { p.XEnd = p.X0 },
+		"no steps":      func(p *Problem) { p.N = 0 },
+		"many steps":    func(p *Problem) { p.N = MaxSteps + 1 },
+		"no methods":    func(p *Problem) { p.Methods = nil },
+		"empty method":  func(p *Problem) { p.Methods = []string{"rk4", ""} },
+		"invalid param": func(p *Problem) { p.Params = map[string]float64{"k": math.NaN()} },
+	} {
+		p := testProblem
+		mod(&p)
+		assert.Error(t, p.Validate(), name)
+		_, err := Codec{}.Encode(p)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,114 @@
+// Package phase provides phase portraits of autonomous and non-autonomous systems of two equations.
+package phase
+
+import (
+	"math"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/pkg/errors"
+)
+
+// DefaultBound is the bound of the phase plane, the trajectory beyond it is considered blown up
+const DefaultBound = 1e6
+
+// System calculates derivatives of the system y1' = f1(t,y1,y2), y2' = f2(t,y1,y2)
+type System func(t, y1, y2 float64) (d1, d2 float64, err error)
+
+// Portrait integrates the system from each initial condition from T0 to T1 with N steps of Runge-Kutta's method
+// and gives trajectories in the (y1, y2) plane, as there is no solver of systems, the system is integrated here,
+// trajectories go backward in t, if T1 is less than T0
+type Portrait struct {
+	F      System
+	T0, T1 float64
+	N      int
+	Bound  float64 // |y1| and |y2| of trajectories, that are not blown up, DefaultBound if not set
+}
+
+// Trajectories returns the trajectory from each seed as points (y1, y2), in the order of seeds, trajectories,
+// that blow up, i.e. leave the bound, get non-finite values or fail to evaluate the system, are skipped
+func (p Portrait) Trajectories(seeds []num.Point) ([][]num.Point, error) {
+	h, err := num.CalculateStepSize(p.N, p.T0, p.T1)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to calculate step")
+	}
+	bound := p.Bound
+	if bound <= 0 {
+		bound = DefaultBound
+	}
+
+	g := solver.NewGrid(p.T0, p.T1, h)
+	var res [][]num.Point
+	for _, seed := range seeds {
+		if tr, ok := p.trajectory(g, seed, bound); ok {
+			res = append(res, tr)
+		}
+	}
+	return res, nil
+}
+
+// trajectory integrates the system from the seed on the grid, false is returned if the trajectory blows up
+func (p Portrait) trajectory(g solver.Grid, seed num.Point, bound float64) ([]num.Point, bool) {
+	res := make([]num.Point, 0, g.N+1)
+	y1, y2 := seed.X, seed.Y
+	for i := 0; i <= g.N; i++ {
+		if !within(y1, bound) || !within(y2, bound) {
+			return nil, false
+		}
+		res = append(res, num.Point{X: y1, Y: y2})
+		if i == g.N {
+			break
+		}
+		t, h := g.X(i), g.Step(i+1)
+
+		k11, k12, err := p.F(t, y1, y2)
+		if err != nil {
+			return nil, false
+		}
+		k21, k22, err := p.F(t+h/2, y1+h/2*k11, y2+h/2*k12)
+		if err != nil {
+			return nil, false
+		}
+		k31, k32, err := p.F(t+h/2, y1+h/2*k21, y2+h/2*k22)
+		if err != nil {
+			return nil, false
+		}
+		k41, k42, err := p.F(t+h, y1+h*k31, y2+h*k32)
+		if err != nil {
+			return nil, false
+		}
+		y1 += h / 6 * (k11 + 2*k21 + 2*k31 + k41)
+		y2 += h / 6 * (k12 + 2*k22 + 2*k32 + k42)
+	}
+	return res, true
+}
+
+// within checks, that the value is finite and doesn't exceed the bound
+func within(v, bound float64) bool {
+	return !math.IsNaN(v) && math.Abs(v) <= bound
+}
+
+// Seeds returns the nx*ny grid of initial conditions over the rectangle of the phase plane
+func Seeds(y1Min, y1Max, y2Min, y2Max float64, nx, ny int) ([]num.Point, error) {
+	if nx < 1 || ny < 1 {
+		return nil, errors.Errorf("grid size must be positive, got %dx%d", nx, ny)
+	}
+	if !(y1Min <= y1Max) || !(y2Min <= y2Max) {
+		return nil, errors.Errorf("invalid rectangle [%.4f, %.4f]x[%.4f, %.4f]", y1Min, y1Max, y2Min, y2Max)
+	}
+	res := make([]num.Point, 0, nx*ny)
+	for i := 0; i < nx; i++ {
+		for j := 0; j < ny; j++ {
+			res = append(res, num.Point{X: node(y1Min, y1Max, i, nx), Y: node(y2Min, y2Max, j, ny)})
+		}
+	}
+	return res, nil
+}
+
+// node returns the i-th of n grid nodes on [min, max], the single node is in the middle
+func node(min, max float64, i, n int) float64 {
+	if n == 1 {
+		return (min + max) / 2
+	}
+	return min + (max-min)*float64(i)/float64(n-1)
+}
@@ -0,0 +1,76 @@
+package phase
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPortrait_Circles(t *testing.T) {
+	// x' = y, y' = -x, trajectories are circles around the origin
+	p := Portrait{F: func(t, y1, y2 float64) (float64, float64, error) { return y2, -y1, nil },
+		T0: 0, T1: 2 * math.Pi, N: 200}
+	seeds, err := Seeds(-2, 2, -2, 2, 3, 3)
+	require.NoError(t, err)
+	trs, err := p.Trajectories(seeds)
+	require.NoError(t, err)
+	require.Len(t, trs, 9)
+
+	for i, tr := range trs {
+		require.Len(t, tr, 201)
+		assert.Equal(t, seeds[i], tr[0])
+		r := math.Hypot(seeds[i].X, seeds[i].Y)
+		for _, pt := range tr {
+			// the error of Runge-Kutta's method is about h^4 per the period
+			assert.InDelta(t, r, math.Hypot(pt.X, pt.Y), 1e-7*math.Max(r, 1), "seed %v", seeds[i])
+		}
+		// the trajectory is closed after the period
+		assert.InDelta(t, seeds[i].X, tr[200].X, 1e-6)
+		assert.InDelta(t, seeds[i].Y, tr[200].Y, 1e-6)
+	}
+}
+
+func TestPortrait_BlowUp(t *testing.T) {
+	// y1' = y1^2 blows up at t = 1/y1(0) for positive y1(0)
+	errF := errors.New("f failed")
+	p := Portrait{F: func(t, y1, y2 float64) (float64, float64, error) {
+		if y2 > 1 {
+			return 0, 0, errF
+		}
+		return y1 * y1, 0, nil
+	}, T0: 0, T1: 2, N: 1000}
+	trs, err := p.Trajectories([]num.Point{{X: 1, Y: 0}, {X: -1, Y: 0}, {X: 0, Y: 2}, {X: 0.1, Y: 1}})
+	require.NoError(t, err)
+	require.Len(t, trs, 2, "the blown up trajectory and the failed one are skipped")
+	assert.Equal(t, num.Point{X: -1, Y: 0}, trs[0][0])
+	assert.InDelta(t, -1.0/3, trs[0][1000].X, 1e-9)
+	assert.Equal(t, num.Point{X: 0.1, Y: 1}, trs[1][0])
+
+	// the trajectory backward in t returns to the seed of the forward one
+	trs, err = Portrait{F: p.F, T0: 2, T1: 0, N: 1000}.Trajectories([]num.Point{{X: -1.0 / 3, Y: 0}})
+	require.NoError(t, err)
+	require.Len(t, trs, 1)
+	assert.InDelta(t, -1, trs[0][1000].X, 1e-9)
+
+	_, err = Portrait{F: p.F, T0: 1, T1: 1, N: 10}.Trajectories(nil)
+	assert.True(t, errors.Is(err, num.ErrEmptyInterval))
+}
+
+func TestSeeds(t *testing.T) {
+	seeds, err := Seeds(-1, 1, 0, 2, 2, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []num.Point{{X: -1, Y: 0}, {X: -1, Y: 1}, {X: -1, Y: 2}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 1, Y: 2}}, seeds)
+
+	seeds, err = Seeds(0, 0, 0, 2, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []num.Point{{X: 0, Y: 1}}, seeds)
+
+	_, err = Seeds(0, 1, 0, 1, 0, 1)
+	assert.EqualError(t, err, "grid size must be positive, got 0x1")
+	_, err = Seeds(1, 0, 0, 1, 1, 1)
+	assert.Error(t, err)
+}
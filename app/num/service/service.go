@@ -26,7 +26,7 @@ func (s *Service) solve(stepSize, x0, y0, xEnd float64) ([]num.Line, error) {
 	var lines []num.Line
 	// solving equation
 	for _, slvr := range s.Solvers {
-		line, err := slvr.Solve(stepSize, x0, y0, xEnd)
+		line, err := solver.Collect(slvr, stepSize, x0, y0, xEnd)
 		if err != nil {
 			return nil, errors.Wrap(err, "can't solve")
 		}
@@ -45,7 +45,7 @@ func (s *Service) PlotSolutions(stepSize, x0, y0, xEnd float64) (plot []byte, er
 	}
 
 	// adding exact solution to the graph
-	line, err := s.ExactSolver.Solve(stepSize, x0, y0, xEnd)
+	line, err := solver.Collect(s.ExactSolver, stepSize, x0, y0, xEnd)
 	if err != nil {
 		return nil, errors.Wrap(err, "can't solve with exact solution")
 	}
@@ -82,7 +82,7 @@ func (s *Service) getLTE(stepSize, x0, y0, xEnd float64) ([]num.Line, error) {
 	}
 
 	// getting the exact solution
-	exactLine, err := s.ExactSolver.Solve(stepSize, x0, y0, xEnd)
+	exactLine, err := solver.Collect(s.ExactSolver, stepSize, x0, y0, xEnd)
 	if err != nil {
 		return nil, errors.Wrap(err, "can't solve with exact solution")
 	}
@@ -115,7 +115,11 @@ func (s *Service) PlotGlobalErrors(nmin, nmax int, x0, y0, xEnd float64) (plot [
 	for i := 0; i <= nmax-nmin; i++ {
 		n := nmin + i
 
-		lines, err := s.getLTE(num.CalculateStepSize(n, x0, xEnd), x0, y0, xEnd)
+		step, err := num.CalculateStepSize(n, x0, xEnd)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to calculate step size for n=%d", n)
+		}
+		lines, err := s.getLTE(step, x0, y0, xEnd)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to calculate LTEs for n=%d", n)
 		}
@@ -0,0 +1,203 @@
+package solver
+
+import (
+	"strconv"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// adamsBashforth are coefficients of Adams-Bashforth's methods by their order, from f_i back to f_{i-order+1},
+// over their common denominator
+var adamsBashforth = map[int]struct {
+	b   []float64
+	den float64
+}{
+	2: {[]float64{3, -1}, 2},
+	3: {[]float64{23, -16, 5}, 12},
+	4: {[]float64{55, -59, 37, -9}, 24},
+}
+
+// AdamsBashforth is the explicit multistep Adams-Bashforth's method, the step extrapolates f by its values
+// at previous nodes, so it takes the single evaluation of f, while Runge-Kutta's method takes four.
+// Nodes before the history is gathered, as well as the shortened last step, are calculated by Runge-Kutta's method
+type AdamsBashforth struct {
+	F     Func // calculator for f(x,y) = y'
+	Order int  // order of the method from 2 to 4, it's the number of previous nodes of the step, 4 if zero
+}
+
+// Name returns the name of the method
+func (a *AdamsBashforth) Name() string {
+	return "Adams-Bashforth's method of order " + strconv.Itoa(a.order())
+}
+
+func (a *AdamsBashforth) order() int {
+	if a.Order == 0 {
+		return 4
+	}
+	return a.Order
+}
+
+// Solve the initial value problem with Adams-Bashforth's method
+func (a *AdamsBashforth) Solve(stepSize, x0, y0, xEnd float64, d Drawer) error {
+	if err := checkArgs(stepSize, x0, xEnd); err != nil {
+		return err
+	}
+	coef, ok := adamsBashforth[a.order()]
+	if !ok {
+		return errors.Errorf("order must be from 2 to 4, got %d", a.Order)
+	}
+
+	logger(d).Logf("[DEBUG] starting solving the equation with Adams-Bashforth's "+
+		"method of order %d with stepsz = %.4f, x0 = %.4f, y0 = %.4f, xend = %.4f", a.order(), stepSize, x0, y0, xEnd)
+
+	return solveAdams(a.Name(), a.F, NewGrid(x0, xEnd, stepSize), 0, y0, nil, a.order(), d, a.step(coef.b, coef.den))
+}
+
+// Resume continues the solution from the checkpoint with the history of f up to xEnd
+func (a *AdamsBashforth) Resume(cp Checkpoint, xEnd float64, d Drawer) error {
+	coef, ok := adamsBashforth[a.order()]
+	if !ok {
+		return errors.Errorf("order must be from 2 to 4, got %d", a.Order)
+	}
+	g, err := cp.grid(xEnd)
+	if err != nil {
+		return err
+	}
+	fs, err := cp.history(a.order())
+	if err != nil {
+		return err
+	}
+	return solveAdams(a.Name(), a.F, g, cp.Node, cp.Y, fs, a.order(), d, a.step(coef.b, coef.den))
+}
+
+// step returns the step of the method with coefficients b over the denominator
+func (a *AdamsBashforth) step(b []float64, den float64) adamsStep {
+	return func(_ int, _, h, y float64, fs []float64) (float64, error) {
+		return y + h*dot(b, fs)/den, nil
+	}
+}
+
+// AdamsBashforthMoulton is the predictor-corrector of the 4th order: Adams-Bashforth's method of the 4th order
+// predicts y at the next node, the implicit Adams-Moulton's method of the 4th order corrects it by f at the
+// predicted point, the next step evaluates f at the corrected one, so each step takes two evaluations of f.
+// Nodes before the history is gathered, as well as the shortened last step, are calculated by Runge-Kutta's method
+type AdamsBashforthMoulton struct {
+	F Func // calculator for f(x,y) = y'
+}
+
+// Name returns the name of the method
+func (a *AdamsBashforthMoulton) Name() string { return "Adams-Bashforth-Moulton's method" }
+
+// Solve the initial value problem with Adams-Bashforth-Moulton's predictor-corrector
+func (a *AdamsBashforthMoulton) Solve(stepSize, x0, y0, xEnd float64, d Drawer) error {
+	if err := checkArgs(stepSize, x0, xEnd); err != nil {
+		return err
+	}
+
+	logger(d).Logf("[DEBUG] starting solving the equation with Adams-Bashforth-Moulton's "+
+		"method with stepsz = %.4f, x0 = %.4f, y0 = %.4f, xend = %.4f", stepSize, x0, y0, xEnd)
+
+	return solveAdams(a.Name(), a.F, NewGrid(x0, xEnd, stepSize), 0, y0, nil, 4, d, a.step)
+}
+
+// Resume continues the solution from the checkpoint with the history of f up to xEnd
+func (a *AdamsBashforthMoulton) Resume(cp Checkpoint, xEnd float64, d Drawer) error {
+	g, err := cp.grid(xEnd)
+	if err != nil {
+		return err
+	}
+	fs, err := cp.history(4)
+	if err != nil {
+		return err
+	}
+	return solveAdams(a.Name(), a.F, g, cp.Node, cp.Y, fs, 4, d, a.step)
+}
+
+// step predicts y by Adams-Bashforth's method and corrects it by Adams-Moulton's one
+func (a *AdamsBashforthMoulton) step(i int, x, h, y float64, fs []float64) (float64, error) {
+	ab := adamsBashforth[4]
+	p := y + h*dot(ab.b, fs)/ab.den
+	fp, err := a.F(x+h, p)
+	if err != nil {
+		return 0, &StepError{Method: a.Name(), Step: i, Stage: "corrector", X: x + h, Y: p, Err: err}
+	}
+	return y + h*(9*fp+19*fs[0]-5*fs[1]+fs[2])/24, nil
+}
+
+// adamsStep makes the step of the multistep method from the i-th node x, where the solution is y, fs[0] is f at it
+type adamsStep func(i int, x, h, y float64, fs []float64) (float64, error)
+
+// solveAdams solves the problem on the grid from the node, where the solution is y and fs are values of f at
+// previous nodes, by the multistep method, that uses values of f at the node and at previous ones, fs[0] is f
+// at the node, fs[1] is at the previous one and so on, the first steps, until steps nodes are known,
+// and the shortened last step are made by Runge-Kutta's method
+func solveAdams(method string, f Func, g Grid, from int, y float64, fs []float64, steps int, d Drawer,
+	step adamsStep) error {
+	out := sinkOf(method, d)
+	if fs == nil {
+		fs = make([]float64, 0, steps)
+	}
+	cd := checkpointer(d)
+	for i := from; i <= g.N; i++ {
+		x := g.X(i)
+		if cd != nil {
+			cd.fs = fs // the history at the node goes to its checkpoint
+		}
+		if err := out.put(i, g.Step(i), num.Point{X: x, Y: y}); err != nil {
+			return err
+		}
+		if i == g.N {
+			break // the point is the last one, so the next step is not calculated
+		}
+		h := g.Step(i + 1) // the last step might be shortened to end at xEnd
+
+		fi, err := f(x, y)
+		if err != nil {
+			return out.fail(&StepError{Method: method, Step: i, Stage: "f", X: x, Y: y, Err: err})
+		}
+		// the newest value goes first, the oldest one is dropped, if the history is full
+		if len(fs) < steps {
+			fs = append(fs, 0)
+		}
+		copy(fs[1:], fs)
+		fs[0] = fi
+
+		if len(fs) < steps || h != g.H {
+			y, err = rungeKuttaStep(method, f, i, x, h, y, fi)
+		} else {
+			y, err = step(i, x, h, y, fs)
+		}
+		if err != nil {
+			return out.fail(err)
+		}
+	}
+	return out.flush()
+}
+
+// rungeKuttaStep makes the step of Runge-Kutta's method of the 4th order with k1, that is f at the node,
+// failures of f are located at stages of the step of the method
+func rungeKuttaStep(method string, f Func, i int, x, h, y, k1 float64) (float64, error) {
+	k2, err := f(x+h/2.0, y+(h/2.0)*k1)
+	if err != nil {
+		return 0, &StepError{Method: method, Step: i, Stage: "k2", X: x + h/2.0, Y: y + (h/2.0)*k1, Err: err}
+	}
+	k3, err := f(x+h/2.0, y+(h/2.0)*k2)
+	if err != nil {
+		return 0, &StepError{Method: method, Step: i, Stage: "k3", X: x + h/2.0, Y: y + (h/2.0)*k2, Err: err}
+	}
+	k4, err := f(x+h, y+h*k3)
+	if err != nil {
+		return 0, &StepError{Method: method, Step: i, Stage: "k4", X: x + h, Y: y + h*k3, Err: err}
+	}
+	return y + h/6.0*(k1+2*k2+2*k3+k4), nil
+}
+
+// dot returns the dot product of coefficients and values
+func dot(coef, vals []float64) float64 {
+	res := 0.0
+	for i, c := range coef {
+		res += c * vals[i]
+	}
+	return res
+}
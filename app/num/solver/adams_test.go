@@ -0,0 +1,110 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdams_Order(t *testing.T) {
+	exact := &Exact{
+		F: func(x, c float64) (float64, error) { return math.Exp(-x) / (c*math.Exp(x) + 1), nil },
+		C: func(x0, y0 float64) (float64, error) { return (math.Exp(-x0) - y0) / (y0 * math.Exp(x0)), nil },
+	}
+	for _, tt := range []struct {
+		s     Interface
+		name  string
+		order float64
+	}{
+		{&AdamsBashforth{F: canonical, Order: 2}, "Adams-Bashforth's method of order 2", 2},
+		{&AdamsBashforth{F: canonical, Order: 3}, "Adams-Bashforth's method of order 3", 3},
+		{&AdamsBashforth{F: canonical}, "Adams-Bashforth's method of order 4", 4},
+		{&AdamsBashforthMoulton{F: canonical}, "Adams-Bashforth-Moulton's method", 4},
+	} {
+		assert.Equal(t, tt.name, tt.s.Name())
+		c := &Collector{}
+		require.NoError(t, ConvergenceStudy(tt.s, exact, 200, 400, -4, 1, 4, c), tt.s.Name())
+		order := math.Log2(c.Points[0].Y / c.Points[200].Y)
+		assert.InDelta(t, tt.order, order, 0.2, tt.s.Name())
+	}
+}
+
+func TestAdams_Solve(t *testing.T) {
+	rk4, err := Collect(&RungeKutta{F: canonical}, 0.1, -4, 1, 4)
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		s     func(f Func) Interface
+		evals int
+	}{
+		{func(f Func) Interface { return &AdamsBashforth{F: f} }, 80 + 3*3},
+		{func(f Func) Interface { return &AdamsBashforthMoulton{F: f} }, 2*80 - 3 + 3*3},
+	} {
+		evals := &CountingFunc{F: canonical}
+		s := tt.s(evals.Eval)
+		line, err := Collect(s, 0.1, -4, 1, 4)
+		require.NoError(t, err)
+		require.Len(t, line.Points, 81)
+		assert.Equal(t, rk4.Points[:4], line.Points[:4], "%s: first nodes are Runge-Kutta's, bit for bit", s.Name())
+		assert.Equal(t, 4.0, line.Points[80].X)
+		assert.InDelta(t, rk4.Points[80].Y, line.Points[80].Y, 1e-4, s.Name())
+		assert.Equal(t, tt.evals, evals.Calls(), "%s takes fewer evaluations than Runge-Kutta's method", s.Name())
+	}
+
+	// the shortened last step is made by Runge-Kutta's method, as the history is of the whole step
+	line, err := Collect(&AdamsBashforth{F: canonical}, 0.03, -4, 1, 4)
+	require.NoError(t, err)
+	require.Len(t, line.Points, 268)
+	prev := line.Points[266]
+	y, err := rungeKuttaStep("", canonical, 266, prev.X, 4-prev.X, prev.Y, mustF(t, prev.X, prev.Y))
+	require.NoError(t, err)
+	assert.Equal(t, num.Point{X: 4, Y: y}, line.Points[267])
+}
+
+func mustF(t *testing.T, x, y float64) float64 {
+	v, err := canonical(x, y)
+	require.NoError(t, err)
+	return v
+}
+
+func TestAdams_Errors(t *testing.T) {
+	assert.EqualError(t, (&AdamsBashforth{F: canonical, Order: 5}).Solve(0.1, 0, 1, 1, &Collector{}),
+		"order must be from 2 to 4, got 5")
+
+	errF := errors.New("failed")
+	var se *StepError
+	for _, tt := range []struct {
+		fail  func(x, y float64) bool
+		s     func(f Func) Interface
+		stage string
+		step  int
+	}{
+		{func(x, y float64) bool { return x > 0.15 && x < 0.2 }, func(f Func) Interface { return &AdamsBashforth{F: f} },
+			"k2", 1},
+		{func(x, y float64) bool { return x > 0.55 }, func(f Func) Interface { return &AdamsBashforth{F: f} }, "f", 6},
+		{func(x, y float64) bool { return x > 0.55 }, func(f Func) Interface { return &AdamsBashforthMoulton{F: f} },
+			"corrector", 5},
+	} {
+		c := &Collector{}
+		s := tt.s(func(x, y float64) (float64, error) {
+			if tt.fail(x, y) {
+				return 0, errF
+			}
+			return canonical(x, y)
+		})
+		err := s.Solve(0.1, 0, 1, 1, c)
+		assert.True(t, errors.Is(err, errF), s.Name())
+		require.True(t, errors.As(err, &se), s.Name())
+		assert.Equal(t, tt.stage, se.Stage, s.Name())
+		assert.Equal(t, tt.step, se.Step, s.Name())
+		assert.Len(t, c.Points, tt.step+1, "%s: points before the failure are drawn", s.Name())
+	}
+}
+
+func BenchmarkAdamsBashforth(b *testing.B) { benchSolver(b, &AdamsBashforth{F: benchF}) }
+
+func BenchmarkAdamsBashforthMoulton(b *testing.B) { benchSolver(b, &AdamsBashforthMoulton{F: benchF}) }
@@ -0,0 +1,266 @@
+package solver
+
+import (
+	"math"
+	"sync"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// limits of the order and of changes of the step of BDF
+const (
+	maxBDFOrder  = 5
+	bdfGrow      = 2.0  // steps of higher orders lose the stability, if they grow faster
+	bdfNotSolved = 0.25 // scale of the step, that Newton's method doesn't converge with
+)
+
+// BDF is the variable-order, variable-step method of backward differentiation formulas of orders from 1
+// to MaxOrder: the polynomial through previous points and the next one has the derivative f(x,y) at the next one,
+// its equation is solved by Newton's method with DFDY, or the central difference of f, if it's not set.
+// The local error is estimated by the difference of the solution and the extrapolation of previous points,
+// the step is rejected and shrunk, while it exceeds Tol, and the order, that allows the largest next step,
+// is taken after steps of the current one, so the solver starts as the backward Euler's method
+// and raises the order on smooth parts. Stiff equations are solved with steps,
+// that are limited by the accuracy only, not by the stability. The meta drawer receives orders and estimates
+// of local errors of steps, along with rejected ones, except the ones, where Newton's method doesn't converge
+type BDF struct {
+	F        Func    // calculator for f(x,y) = y'
+	DFDY     Func    // calculator for df/dy, estimated by the central difference of f if not set
+	Tol      float64 // max estimated local error of the step, 1e-6 if zero
+	MinStep  float64 // the least step, the solution fails, if the error exceeds the tolerance with it
+	MaxStep  float64 // the largest step, the whole interval if zero
+	MaxOrder int     // the highest order, from 1 to 5, 5 if zero
+	MaxIter  int     // max iterations of Newton's method by the step, 50 if zero
+
+	mu     sync.Mutex
+	orders []int // orders of taken steps of the last solution
+}
+
+// Name returns the name of the method
+func (b *BDF) Name() string { return "BDF method" }
+
+// Solve the differential equation from x0 with the initial step size, which is adapted to the tolerance
+// along with the order, the last step is shortened to end at xEnd, the solution goes backward,
+// if xEnd is less than x0
+func (b *BDF) Solve(stepSize, x0, y0, xEnd float64, d Drawer) error {
+	if err := checkArgs(stepSize, x0, xEnd); err != nil {
+		return err
+	}
+	tol, minStep, maxStep, maxOrder := b.Tol, b.MinStep, b.MaxStep, b.MaxOrder
+	if tol == 0 {
+		tol = defaultRKF45Tol
+	}
+	if maxStep == 0 {
+		maxStep = math.Abs(xEnd - x0)
+	}
+	if maxOrder == 0 {
+		maxOrder = maxBDFOrder
+	}
+	if !(tol > 0) || math.IsInf(tol, 1) {
+		return errors.Errorf("tolerance must be positive and finite, got %v", tol)
+	}
+	if !(minStep >= 0) || !(maxStep >= minStep) {
+		return errors.Errorf("steps must be 0 <= min_step <= max_step, got min_step=%v and max_step=%v", minStep, maxStep)
+	}
+	if maxOrder < 1 || maxOrder > maxBDFOrder {
+		return errors.Errorf("order must be from 1 to %d, got %d", maxBDFOrder, maxOrder)
+	}
+	nt, err := newtonOf(b.F, b.DFDY, 0, b.MaxIter)
+	if err != nil {
+		return err
+	}
+
+	logger(d).Logf("[DEBUG] starting solving the equation with the BDF method with stepsz = %.4f, "+
+		"tol = %v, max order = %d, x0 = %.4f, y0 = %.4f, xend = %.4f", stepSize, tol, maxOrder, x0, y0, xEnd)
+
+	var orders []int
+	defer func() { b.setOrders(orders) }()
+
+	// h is the size of the step, the solution goes by dir*h, backward on the reversed interval
+	h, dir := math.Max(minStep, math.Min(stepSize, maxStep)), direction(x0, xEnd)
+	out := sinkOf(b.Name(), d)
+	if err := out.put(0, 0, num.Point{X: x0, Y: y0}); err != nil {
+		return err
+	}
+	// previous points, the latest is the last one, the estimate of the error of the order above the current one
+	// takes the point more, than the formula
+	hist := []num.Point{{X: x0, Y: y0}}
+	k, sinceChange := 1, 0
+	for i := 0; dir*(xEnd-hist[len(hist)-1].X) > 0; {
+		cur := hist[len(hist)-1]
+		last := h*(1+gridTolerance) >= dir*(xEnd-cur.X) // the rounding error of x doesn't leave the tiny last step
+		if last {
+			h = dir * (xEnd - cur.X)
+		}
+		// the order is limited by previous points, the extrapolation of the order needs one more of them,
+		// except the first step
+		q := k
+		if q > len(hist)-1 && len(hist) > 1 {
+			q = len(hist) - 1
+		}
+		next, errEst, err := b.step(nt, i, hist, q, dir*h)
+		if err != nil && !errors.Is(err, ErrNotConverged) {
+			return out.fail(err)
+		}
+
+		meta := Meta{Step: i + 1, H: dir * h, Order: q, LocalErr: errEst}
+		if err != nil || !(errEst <= tol) { // the not finite estimate is rejected too
+			// the step, that is not converged, has no point to report
+			if err == nil {
+				if rerr := out.reject(next, meta); rerr != nil {
+					return out.fail(rerr)
+				}
+			}
+			if h <= minStep || cur.X+h*rkf45Shrink == cur.X {
+				if err == nil {
+					err = &StepError{Method: b.Name(), Step: i, Stage: "adapt", X: cur.X, Y: cur.Y,
+						Err: errors.Wrapf(num.ErrStepTooSmall, "local error %g exceeds the tolerance %g with the step %v",
+							errEst, tol, h)}
+				}
+				return out.fail(err)
+			}
+			scale := bdfNotSolved
+			if err == nil {
+				scale = math.Max(rkf45Shrink, rkf45Safety*math.Pow(tol/errEst, 1/float64(q+1)))
+			}
+			h = math.Max(minStep, h*scale)
+			continue
+		}
+
+		if last {
+			next.X = xEnd
+		}
+		if hist = append(hist, next); len(hist) > maxOrder+2 {
+			hist = hist[1:]
+		}
+		orders = append(orders, q)
+		i++
+		if err = out.putMeta(next, meta); err != nil {
+			return err
+		}
+
+		// the order, that allows the largest next step, is taken, the higher one is tried, as the current one
+		// makes enough steps to estimate it
+		sinceChange++
+		best, scale := q, bdfScale(tol, errEst, q)
+		if q > 1 {
+			if s := bdfScale(tol, bdfError(hist, q-1), q-1); s > scale {
+				best, scale = q-1, s
+			}
+		}
+		if q == k && q < maxOrder && sinceChange > q && len(hist) >= q+3 {
+			if s := bdfScale(tol, bdfError(hist, q+1), q+1); s > scale {
+				best, scale = q+1, s
+			}
+		}
+		if best != k {
+			logger(d).Logf("[DEBUG] changing the order of the BDF method from %d to %d at x = %.4f", k, best, next.X)
+			k, sinceChange = best, 0
+		}
+		h = math.Max(minStep, math.Min(maxStep, h*scale))
+	}
+
+	return out.flush()
+}
+
+// step makes the i-th step of the order q and the size h from the last of previous points, the first step
+// of the solution is estimated by the explicit Euler's step, the rest ones by the extrapolation of points
+func (b *BDF) step(nt newton, i int, hist []num.Point, q int, h float64) (num.Point, float64, error) {
+	cur := hist[len(hist)-1]
+	x := cur.X + h
+	pts := hist[len(hist)-q:]
+
+	var pred float64
+	if len(hist) == 1 {
+		f, err := b.F(cur.X, cur.Y)
+		if err != nil {
+			return num.Point{}, 0, &StepError{Method: b.Name(), Step: i, Stage: "f", X: cur.X, Y: cur.Y, Err: err}
+		}
+		pred = cur.Y + h*f
+	} else {
+		pred = extrapolate(hist[len(hist)-q-1:], x)
+	}
+
+	// the derivative of the polynomial through points and (x, y) is sum of w_j * y_j + w * y = f(x, y)
+	w, ws := bdfWeights(pts, x)
+	base := 0.0
+	for j, p := range pts {
+		base -= ws[j] * p.Y / w
+	}
+	y, err := nt.solve(b.Name(), i, x, 1/w, base, pred)
+	if err != nil {
+		return num.Point{}, 0, err
+	}
+
+	next := num.Point{X: x, Y: y}
+	if len(hist) == 1 {
+		// the errors of explicit and backward Euler's steps are opposite, h^2 * y'' / 2
+		return next, math.Abs(y-pred) / 2, nil
+	}
+	// the difference with the extrapolation of the order q is q+2 times the error of the formula
+	return next, math.Abs(y-pred) / float64(q+2), nil
+}
+
+// bdfWeights returns weights of the derivative at x of the polynomial through points and x,
+// w is the weight of the value at x, ws are weights of values at points
+func bdfWeights(pts []num.Point, x float64) (w float64, ws []float64) {
+	ws = make([]float64, len(pts))
+	for j, pj := range pts {
+		w += 1 / (x - pj.X)
+		prod, den := 1.0, pj.X-x
+		for m, pm := range pts {
+			if m != j {
+				prod *= x - pm.X
+				den *= pj.X - pm.X
+			}
+		}
+		ws[j] = prod / den
+	}
+	return w, ws
+}
+
+// extrapolate evaluates the polynomial through points at x by Lagrange's form
+func extrapolate(pts []num.Point, x float64) float64 {
+	res := 0.0
+	for j, pj := range pts {
+		l := 1.0
+		for m, pm := range pts {
+			if m != j {
+				l *= (x - pm.X) / (pj.X - pm.X)
+			}
+		}
+		res += l * pj.Y
+	}
+	return res
+}
+
+// bdfError estimates the local error of the formula of the order q at the last point by q+2 last points,
+// as the step estimates it, the difference of the last point and the extrapolation of the previous ones
+// is their divided difference times the product of distances from the last point
+func bdfError(hist []num.Point, q int) float64 {
+	pts := hist[len(hist)-q-2:]
+	last := pts[len(pts)-1]
+	return math.Abs(last.Y-extrapolate(pts[:len(pts)-1], last.X)) / float64(q+2)
+}
+
+// bdfScale returns the scale of the next step of the order q by the error of the taken one
+func bdfScale(tol, errEst float64, q int) float64 {
+	if errEst == 0 {
+		return bdfGrow
+	}
+	return math.Min(bdfGrow, math.Max(rkf45Shrink, rkf45Safety*math.Pow(tol/errEst, 1/float64(q+1))))
+}
+
+// Orders returns orders of taken steps of the last solution
+func (b *BDF) Orders() []int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]int(nil), b.orders...)
+}
+
+func (b *BDF) setOrders(orders []int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.orders = orders
+}
@@ -0,0 +1,158 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBDF_Stiff(t *testing.T) {
+	// y' = -k(y - cos(x)) relaxes to the slow solution near cos(x) in 1/k
+	const k = 1e4
+	stiff := func(x, y float64) (float64, error) { return -k * (y - math.Cos(x)), nil }
+	exact := func(x float64) float64 {
+		return (k*k*math.Cos(x)+k*math.Sin(x))/(k*k+1) - k*k/(k*k+1)*math.Exp(-k*x)
+	}
+
+	evals := &CountingFunc{F: stiff}
+	b := &BDF{F: evals.Eval, Tol: 1e-7}
+	line, err := Collect(b, 1e-5, 0, 0, 10)
+	require.NoError(t, err)
+	last := line.Points[len(line.Points)-1]
+	assert.Equal(t, 10.0, last.X)
+	for _, p := range line.Points {
+		assert.InDelta(t, exact(p.X), p.Y, 1e-5, "x=%v", p.X)
+	}
+	// rk4 is stable with steps below 2.78/k only, i.e. 36000 steps
+	assert.Less(t, len(line.Points), 1000, "steps are limited by the accuracy")
+	assert.Less(t, evals.Calls(), 20000)
+
+	orders := b.Orders()
+	require.Len(t, orders, len(line.Points)-1)
+	assert.Equal(t, 1, orders[0], "the solution starts by the backward Euler's method")
+	highest := 0
+	for _, o := range orders {
+		highest = int(math.Max(float64(highest), float64(o)))
+	}
+	assert.GreaterOrEqual(t, highest, 3, "the order is raised on the slow part")
+	assert.LessOrEqual(t, highest, 5)
+}
+
+func TestBDF_Backward(t *testing.T) {
+	// backward y' = k*y decays as fast as y' = -k*y forward, so it's stiff and the step is limited by the accuracy
+	const k = 1e3
+	growth := func(_, y float64) (float64, error) { return k * y, nil }
+	b := &BDF{F: growth, Tol: 1e-8}
+	d := &metaCollector{}
+	require.NoError(t, b.Solve(1e-4, 1, 1, 0, d))
+	last := d.Points[len(d.Points)-1]
+	assert.Equal(t, 0.0, last.X)
+	assert.InDelta(t, 0, last.Y, 1e-6, "exp(-1000) is zero up to the tolerance")
+	assert.Less(t, len(d.Points), 1000, "the decayed solution is followed with large steps")
+	for i, p := range d.Points[1:] {
+		assert.Less(t, p.X, d.Points[i].X, "the solution goes backward")
+		assert.InDelta(t, math.Exp(k*(p.X-1)), p.Y, 1e-6, "x=%v", p.X)
+	}
+	highest := 0
+	for _, o := range b.Orders() {
+		highest = int(math.Max(float64(highest), float64(o)))
+	}
+	assert.Greater(t, highest, 2, "the order is raised backward as well")
+}
+
+func TestBDF_Meta(t *testing.T) {
+	stiff := func(x, y float64) (float64, error) { return -1e3 * (y - math.Cos(x)), nil }
+	b := &BDF{F: stiff, Tol: 1e-6}
+	d := &metaCollector{}
+	require.NoError(t, b.Solve(1e-2, 0, 0, 2, d))
+
+	accepted := d.accepted()
+	require.Len(t, accepted, len(d.Points))
+	orders := make([]int, 0, len(accepted)-1)
+	for i, m := range accepted[1:] {
+		assert.Equal(t, i+1, m.Step)
+		assert.LessOrEqual(t, m.LocalErr, 1e-6)
+		assert.Nil(t, m.Stages, "BDF has no stages")
+		orders = append(orders, m.Order)
+	}
+	assert.Equal(t, b.Orders(), orders)
+	require.NotEmpty(t, d.rejected, "the initial step is too large for the fast transient")
+	for _, m := range d.metas {
+		if m.Rejected {
+			assert.Greater(t, m.LocalErr, 1e-6)
+		}
+	}
+}
+
+func TestBDF_Accuracy(t *testing.T) {
+	// the canonical problem from y(0) = 1 is solved by exp(-x)
+	for _, tt := range []struct {
+		tol, delta float64
+	}{{1e-4, 1e-3}, {1e-6, 1e-5}, {1e-8, 1e-7}} {
+		b := &BDF{F: canonical, Tol: tt.tol}
+		line, err := Collect(b, 0.01, 0, 1, 0.5)
+		require.NoError(t, err)
+		last := line.Points[len(line.Points)-1]
+		assert.Equal(t, 0.5, last.X)
+		assert.InDelta(t, math.Exp(-0.5), last.Y, tt.delta, "tol=%v", tt.tol)
+	}
+
+	// the order is limited
+	b := &BDF{F: canonical, Tol: 1e-8, MaxOrder: 2}
+	line, err := Collect(b, 0.01, 0, 1, 0.5)
+	require.NoError(t, err)
+	for _, o := range b.Orders() {
+		assert.LessOrEqual(t, o, 2)
+	}
+	assert.Len(t, b.Orders(), len(line.Points)-1)
+
+	// the linear problem with the exact derivative
+	line, err = Collect(&BDF{F: func(x, y float64) (float64, error) { return x - 2*y, nil },
+		DFDY: func(_, _ float64) (float64, error) { return -2, nil }, Tol: 1e-8}, 0.01, 0, 1, 1)
+	require.NoError(t, err)
+	last := line.Points[len(line.Points)-1]
+	assert.InDelta(t, 0.5-0.25+1.25*math.Exp(-2), last.Y, 1e-6)
+}
+
+func TestBDF_Errors(t *testing.T) {
+	stiff := func(_, y float64) (float64, error) { return -1000 * y, nil }
+	assert.EqualError(t, (&BDF{F: stiff, Tol: -1}).Solve(0.1, 0, 1, 1, &Collector{}),
+		"tolerance must be positive and finite, got -1")
+	assert.EqualError(t, (&BDF{F: stiff, MaxOrder: 6}).Solve(0.1, 0, 1, 1, &Collector{}),
+		"order must be from 1 to 5, got 6")
+	assert.EqualError(t, (&BDF{F: stiff, MinStep: 1, MaxStep: 0.5}).Solve(0.1, 0, 1, 1, &Collector{}),
+		"steps must be 0 <= min_step <= max_step, got min_step=1 and max_step=0.5")
+	assert.EqualError(t, (&BDF{F: stiff, MaxIter: -1}).Solve(0.1, 0, 1, 1, &Collector{}),
+		"number of iterations must be positive, got -1")
+	errF := errors.New("failed")
+	c := &Collector{}
+	err := (&BDF{F: func(x, y float64) (float64, error) {
+		if x > 0.25 {
+			return 0, errF
+		}
+		return stiff(x, y)
+	}}).Solve(0.01, 0, 1, 1, c)
+	assert.True(t, errors.Is(err, errF), err)
+	var se *StepError
+	require.True(t, errors.As(err, &se))
+	assert.Equal(t, "newton", se.Stage)
+	assert.Greater(t, len(c.Points), 1, "points before the failure are drawn")
+
+	// the step with the least step doesn't reach the tolerance
+	err = (&BDF{F: canonical, Tol: 1e-12, MinStep: 0.1}).Solve(0.1, 0, 1, 1, &Collector{})
+	assert.True(t, errors.Is(err, num.ErrStepTooSmall), err)
+	require.True(t, errors.As(err, &se))
+	assert.Equal(t, "adapt", se.Stage)
+
+	// y' = y^2 from 1 has the pole at x=1, Newton's method doesn't converge beyond it with any step
+	err = (&BDF{F: func(_, y float64) (float64, error) { return y * y, nil }, MinStep: 0.01}).
+		Solve(0.5, 0, 1, 2, &Collector{})
+	assert.Error(t, err)
+}
+
+// BenchmarkBDF measures steps of the max size, so their number is the same as of other solvers
+func BenchmarkBDF(b *testing.B) { benchSolver(b, &BDF{F: benchF, MaxStep: 1.0 / benchSteps}) }
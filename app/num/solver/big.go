@@ -0,0 +1,183 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// defaultBigPrec is the default precision of big solvers in bits of the mantissa, about 77 decimal digits
+const defaultBigPrec = 256
+
+// BigFunc calculates f(x,y) = y' with the precision of its arguments, the result must be finite
+type BigFunc func(x, y *big.Float) (*big.Float, error)
+
+// BigOf adapts f to BigFunc, f is evaluated in float64 at x and y, rounded to float64, so each evaluation
+// makes only its own error of the rounding, which doesn't accumulate, as x, y and stages of the method keep
+// the precision of arguments
+func BigOf(f Func) BigFunc {
+	return func(x, y *big.Float) (*big.Float, error) {
+		xf, _ := x.Float64()
+		yf, _ := y.Float64()
+		v, err := f(xf, yf)
+		if err != nil {
+			return nil, err
+		}
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, errors.Errorf("f is not finite, got %v", v)
+		}
+		return new(big.Float).SetPrec(x.Prec()).SetFloat64(v), nil
+	}
+}
+
+// BigEuler is Euler's method, that keeps x, y and the step with the precision of Prec bits, so the round-off
+// of the sum of small increments doesn't hide the truncation error of the method with very small steps
+type BigEuler struct {
+	F    BigFunc // calculator for f(x,y) = y'
+	Prec uint    // bits of the mantissa, 256 if zero
+}
+
+// Name returns the name of the method with its precision
+func (e *BigEuler) Name() string {
+	return fmt.Sprintf("Euler's method with %d-bit precision", bigPrec(e.Prec))
+}
+
+// Solve the initial value problem with Euler's method with the precision
+func (e *BigEuler) Solve(stepSize, x0, y0, xEnd float64, d Drawer) error {
+	if err := checkArgs(stepSize, x0, xEnd); err != nil {
+		return err
+	}
+
+	logger(d).Logf("[DEBUG] starting solving the equation with Euler's method with %d-bit precision "+
+		"with stepsz = %.4f, x0 = %.4f, y0 = %.4f, xend = %.4f", bigPrec(e.Prec), stepSize, x0, y0, xEnd)
+
+	c := bigCalc{prec: bigPrec(e.Prec)}
+	return solveBig(e.Name(), c, NewGrid(x0, xEnd, stepSize), y0, d, func(i int, x, h, y *big.Float) (*big.Float, error) {
+		f, err := c.eval(e.Name(), e.F, i, "f", x, y)
+		if err != nil {
+			return nil, err
+		}
+		return c.add(y, c.mul(h, f)), nil
+	})
+}
+
+// BigRungeKutta is Runge-Kutta's method of the 4th order, that keeps x, y and stages of the step with
+// the precision of Prec bits, its truncation error falls below the round-off of float64 already with
+// moderate steps, so the precision shows the convergence further
+type BigRungeKutta struct {
+	F    BigFunc // calculator for f(x,y) = y'
+	Prec uint    // bits of the mantissa, 256 if zero
+}
+
+// Name returns the name of the method with its precision
+func (r *BigRungeKutta) Name() string {
+	return fmt.Sprintf("Runge-Kutta's method with %d-bit precision", bigPrec(r.Prec))
+}
+
+// Solve the initial value problem with Runge-Kutta's method with the precision
+func (r *BigRungeKutta) Solve(stepSize, x0, y0, xEnd float64, d Drawer) error {
+	if err := checkArgs(stepSize, x0, xEnd); err != nil {
+		return err
+	}
+
+	logger(d).Logf("[DEBUG] starting solving the equation with Runge-Kutta's method with %d-bit precision "+
+		"with stepsz = %.4f, x0 = %.4f, y0 = %.4f, xend = %.4f", bigPrec(r.Prec), stepSize, x0, y0, xEnd)
+
+	c := bigCalc{prec: bigPrec(r.Prec)}
+	two, six := c.of(2), c.of(6)
+	return solveBig(r.Name(), c, NewGrid(x0, xEnd, stepSize), y0, d, func(i int, x, h, y *big.Float) (*big.Float, error) {
+		half := c.quo(h, two)
+		xm := c.add(x, half)
+		k1, err := c.eval(r.Name(), r.F, i, "k1", x, y)
+		if err != nil {
+			return nil, err
+		}
+		k2, err := c.eval(r.Name(), r.F, i, "k2", xm, c.add(y, c.mul(half, k1)))
+		if err != nil {
+			return nil, err
+		}
+		k3, err := c.eval(r.Name(), r.F, i, "k3", xm, c.add(y, c.mul(half, k2)))
+		if err != nil {
+			return nil, err
+		}
+		k4, err := c.eval(r.Name(), r.F, i, "k4", c.add(x, h), c.add(y, c.mul(h, k3)))
+		if err != nil {
+			return nil, err
+		}
+		sum := c.add(c.add(k1, c.mul(two, k2)), c.add(c.mul(two, k3), k4))
+		return c.add(y, c.mul(c.quo(h, six), sum)), nil
+	})
+}
+
+// bigStep makes the step h of the method from the i-th node x, where the solution is y
+type bigStep func(i int, x, h, y *big.Float) (*big.Float, error)
+
+// solveBig solves the problem on the grid by the method with the precision, nodes are calculated from x0
+// by their indexes with the precision, so they don't accumulate the round-off, points are drawn at nodes
+// of the grid with y, rounded to float64
+func solveBig(method string, c bigCalc, g Grid, y0 float64, d Drawer, step bigStep) error {
+	if math.IsNaN(y0) || math.IsInf(y0, 0) {
+		return errors.Errorf("y0 must be finite, got %v", y0)
+	}
+	out := sinkOf(method, d)
+	x0, h, y := c.of(g.X0), c.of(g.H), c.of(y0)
+	x := x0
+	for i := 0; i <= g.N; i++ {
+		yf, _ := y.Float64()
+		if err := out.put(i, g.Step(i), num.Point{X: g.X(i), Y: yf}); err != nil {
+			return err
+		}
+		if i == g.N {
+			break // the point is the last one, so the next step is not calculated
+		}
+
+		// the last node is xEnd itself, so the last step ends exactly at it
+		next := c.add(x0, c.mul(c.of(float64(i+1)), h))
+		if i+1 == g.N {
+			next = c.of(g.X(g.N))
+		}
+		var err error
+		if y, err = step(i, x, c.sub(next, x), y); err != nil {
+			return out.fail(err)
+		}
+		x = next
+	}
+	return out.flush()
+}
+
+// bigCalc makes arithmetic of big floats with the precision, each operation returns the new value
+type bigCalc struct {
+	prec uint
+}
+
+func (c bigCalc) of(v float64) *big.Float        { return new(big.Float).SetPrec(c.prec).SetFloat64(v) }
+func (c bigCalc) add(a, b *big.Float) *big.Float { return new(big.Float).SetPrec(c.prec).Add(a, b) }
+func (c bigCalc) sub(a, b *big.Float) *big.Float { return new(big.Float).SetPrec(c.prec).Sub(a, b) }
+func (c bigCalc) mul(a, b *big.Float) *big.Float { return new(big.Float).SetPrec(c.prec).Mul(a, b) }
+func (c bigCalc) quo(a, b *big.Float) *big.Float { return new(big.Float).SetPrec(c.prec).Quo(a, b) }
+
+// eval evaluates f at the stage of the i-th step, the failure of f is located at the stage, f must be finite,
+// as the arithmetic of big floats panics on NaN
+func (c bigCalc) eval(method string, f BigFunc, i int, stage string, x, y *big.Float) (*big.Float, error) {
+	v, err := f(x, y)
+	if err == nil && v.IsInf() {
+		err = errors.Errorf("f is not finite, got %v", v)
+	}
+	if err != nil {
+		xf, _ := x.Float64()
+		yf, _ := y.Float64()
+		return nil, &StepError{Method: method, Step: i, Stage: stage, X: xf, Y: yf, Err: err}
+	}
+	return v, nil
+}
+
+// bigPrec returns the precision of the big solver, the default one if zero
+func bigPrec(prec uint) uint {
+	if prec == 0 {
+		return defaultBigPrec
+	}
+	return prec
+}
@@ -0,0 +1,83 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBig_SameMethod(t *testing.T) {
+	for _, tt := range []struct {
+		s, big Interface
+	}{
+		{&Euler{F: benchF}, &BigEuler{F: BigOf(benchF)}},
+		{&RungeKutta{F: benchF}, &BigRungeKutta{F: BigOf(benchF), Prec: 128}},
+	} {
+		want := &Collector{}
+		require.NoError(t, tt.s.Solve(0.3, -1.1, 1, 4.2, want))
+		got := &Collector{}
+		require.NoError(t, tt.big.Solve(0.3, -1.1, 1, 4.2, got), tt.big.Name())
+		require.Len(t, got.Points, len(want.Points), tt.big.Name())
+		for i, p := range got.Points {
+			assert.Equal(t, want.Points[i].X, p.X, "%s draws nodes of the grid", tt.big.Name())
+			assert.InDelta(t, want.Points[i].Y, p.Y, 1e-12, "%s at x=%v", tt.big.Name(), p.X)
+		}
+	}
+	assert.Equal(t, "Euler's method with 256-bit precision", (&BigEuler{}).Name())
+	assert.Equal(t, "Runge-Kutta's method with 128-bit precision", (&BigRungeKutta{Prec: 128}).Name())
+}
+
+func TestBig_RoundOff(t *testing.T) {
+	// y' = 1 is solved by Euler's method exactly, so its error is the round-off only
+	one := func(x, y float64) (float64, error) { return 1, nil }
+	c := &Collector{}
+	require.NoError(t, (&Euler{F: one}).Solve(0.001, 0, 0, 100, c))
+	floatErr := math.Abs(c.Points[len(c.Points)-1].Y - 100)
+
+	bc := &Collector{}
+	require.NoError(t, (&BigEuler{F: BigOf(one)}).Solve(0.001, 0, 0, 100, bc))
+	assert.Equal(t, 100.0, bc.Points[len(bc.Points)-1].Y)
+	assert.Greater(t, floatErr, 1e-11, "float64 accumulates the round-off")
+}
+
+func TestBig_Errors(t *testing.T) {
+	failed := errors.New("failed")
+	f := func(x, y float64) (float64, error) {
+		if x > 0.45 {
+			return 0, failed
+		}
+		return math.Inf(1), nil
+	}
+	c := &Collector{}
+	err := (&BigRungeKutta{F: BigOf(f)}).Solve(0.1, 0, 1, 1, c)
+	assert.EqualError(t, err, "Runge-Kutta's method with 256-bit precision failed at step 0, k1 at x=0.0000 y=1.0000: "+
+		"f is not finite, got +Inf")
+	assert.Len(t, c.Points, 1)
+
+	bf := func(x, y *big.Float) (*big.Float, error) {
+		if xf, _ := x.Float64(); xf > 0.45 {
+			return nil, failed
+		}
+		return new(big.Float).SetInt64(1), nil
+	}
+	err = (&BigEuler{F: bf}).Solve(0.1, 0, 1, 1, c)
+	assert.True(t, errors.Is(err, failed), err)
+	var se *StepError
+	require.True(t, errors.As(err, &se))
+	assert.Equal(t, 5, se.Step)
+
+	assert.EqualError(t, (&BigEuler{F: bf}).Solve(0.1, 0, math.NaN(), 1, c), "y0 must be finite, got NaN")
+	assert.Error(t, (&BigEuler{F: bf}).Solve(0, 0, 1, 1, c))
+}
+
+// BenchmarkBigEuler measures fewer steps, as the arithmetic of big floats is slow
+func BenchmarkBigEuler(b *testing.B) { benchSolverN(b, &BigEuler{F: BigOf(benchF)}, benchSteps/100) }
+
+// BenchmarkBigRungeKutta measures fewer steps as BenchmarkBigEuler does
+func BenchmarkBigRungeKutta(b *testing.B) {
+	benchSolverN(b, &BigRungeKutta{F: BigOf(benchF)}, benchSteps/100)
+}
@@ -0,0 +1,240 @@
+package solver
+
+import (
+	"math"
+	"math/cmplx"
+	"sort"
+	"strings"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// ComplexFunc calculates y' as f(x,y) for the complex-valued solution
+type ComplexFunc func(x float64, y complex128) (complex128, error)
+
+// tags of parts of the complex solution, drawn by Complex
+const (
+	PartRe  = "re"  // the real part
+	PartIm  = "im"  // the imaginary part
+	PartAbs = "abs" // the magnitude |y|
+	PartArg = "arg" // the phase, unwrapped, so it is continuous along the solution
+)
+
+// complexParts are parts of the complex solution in the order of drawing
+var complexParts = []struct {
+	tag string
+	val func(y complex128) float64
+}{
+	{PartRe, func(y complex128) float64 { return real(y) }},
+	{PartIm, func(y complex128) float64 { return imag(y) }},
+	{PartAbs, cmplx.Abs},
+	{PartArg, cmplx.Phase},
+}
+
+// complexStep makes the step h of the method from (x, y), the failure of f is returned with its stage
+// and the point, at which f is evaluated
+type complexStep func(f ComplexFunc, x, h float64, y complex128) (next complex128, fail *complexFail)
+
+// complexFail is the failure of f at the stage of the step
+type complexFail struct {
+	stage string
+	x     float64
+	y     complex128
+	err   error
+}
+
+// complexMethods are methods of Complex by the names of methods in requests
+var complexMethods = map[string]struct {
+	name string
+	step complexStep
+}{
+	"euler": {name: "Euler's method", step: func(f ComplexFunc, x, h float64, y complex128) (complex128, *complexFail) {
+		k, err := f(x, y)
+		if err != nil {
+			return 0, &complexFail{stage: "f", x: x, y: y, err: err}
+		}
+		return y + complex(h, 0)*k, nil
+	}},
+	"ieuler": {name: "Improved Euler's method", step: func(f ComplexFunc, x, h float64, y complex128) (complex128, *complexFail) {
+		hc := complex(h, 0)
+		k1, err := f(x, y)
+		if err != nil {
+			return 0, &complexFail{stage: "k1", x: x, y: y, err: err}
+		}
+		ym := y + hc/2*k1
+		k2, err := f(x+h/2, ym)
+		if err != nil {
+			return 0, &complexFail{stage: "k2", x: x + h/2, y: ym, err: err}
+		}
+		return y + hc*k2, nil
+	}},
+	"rk4": {name: "Runge-Kutta's method", step: func(f ComplexFunc, x, h float64, y complex128) (complex128, *complexFail) {
+		hc := complex(h, 0)
+		k1, err := f(x, y)
+		if err != nil {
+			return 0, &complexFail{stage: "k1", x: x, y: y, err: err}
+		}
+		y2 := y + hc/2*k1
+		k2, err := f(x+h/2, y2)
+		if err != nil {
+			return 0, &complexFail{stage: "k2", x: x + h/2, y: y2, err: err}
+		}
+		y3 := y + hc/2*k2
+		k3, err := f(x+h/2, y3)
+		if err != nil {
+			return 0, &complexFail{stage: "k3", x: x + h/2, y: y3, err: err}
+		}
+		y4 := y + hc*k3
+		k4, err := f(x+h, y4)
+		if err != nil {
+			return 0, &complexFail{stage: "k4", x: x + h, y: y4, err: err}
+		}
+		return y + hc/6*(k1+2*k2+2*k3+k4), nil
+	}},
+}
+
+// Complex solves the complex-valued initial value problem y' = f(x,y) by the method as the coupled system
+// of its real and imaginary parts on the single grid, parts of the solution are drawn at each node
+// to the drawers, tagged by parts, e.g. re and im, or abs and arg
+type Complex struct {
+	F      ComplexFunc
+	Method string // euler, ieuler or rk4, rk4 if empty
+}
+
+// method returns the method of the solver
+func (c *Complex) method() (string, complexStep, error) {
+	name := c.Method
+	if name == "" {
+		name = "rk4"
+	}
+	m, ok := complexMethods[name]
+	if !ok {
+		return "", nil, errors.Errorf("unknown method %q, must be euler, ieuler or rk4", c.Method)
+	}
+	return m.name, m.step, nil
+}
+
+// Name returns the name of the method
+func (c *Complex) Name() string {
+	name, _, err := c.method()
+	if err != nil {
+		return "Complex solution"
+	}
+	return name + " for complex y"
+}
+
+// Solve solves the problem from y0 with the step and draws the parts of the solution to drawers by their tags,
+// parts without drawers are not drawn, since the phase is unwrapped, it might go beyond [-π, π]
+func (c *Complex) Solve(step, x0 float64, y0 complex128, xEnd float64, d map[string]Drawer) error {
+	if err := checkArgs(step, x0, xEnd); err != nil {
+		return err
+	}
+	method, next, err := c.method()
+	if err != nil {
+		return err
+	}
+	if len(d) == 0 {
+		return errors.New("no drawers of parts of the solution")
+	}
+	var parts []partSink
+	for _, part := range complexParts {
+		if pd, ok := d[part.tag]; ok {
+			parts = append(parts, partSink{tag: part.tag, val: part.val, sink: sinkOf(method, pd)})
+		}
+	}
+	if len(parts) < len(d) {
+		return errors.Errorf("unknown parts of the solution %s, must be %s, %s, %s or %s", unknownParts(d),
+			PartRe, PartIm, PartAbs, PartArg)
+	}
+
+	// fail flushes points of all parts before the failure
+	fail := func(err error) error {
+		for i := range parts {
+			err = parts[i].sink.fail(err)
+		}
+		return err
+	}
+
+	g := NewGrid(x0, xEnd, step)
+	y := y0
+	for i := 0; i <= g.N; i++ {
+		x := g.X(i)
+		for j := range parts {
+			if err = parts[j].put(i, g.Step(i), x, y); err != nil {
+				return fail(err)
+			}
+		}
+		if i == g.N {
+			break
+		}
+		h := g.Step(i + 1)
+
+		var cf *complexFail
+		if y, cf = next(c.F, x, h, y); cf != nil {
+			// the step error keeps the real part, the whole y is in the message of the cause
+			return fail(&StepError{Method: method, Step: i, Stage: cf.stage, X: cf.x, Y: real(cf.y),
+				Err: errors.Wrapf(cf.err, "complex y=%.4f", cf.y)})
+		}
+	}
+
+	for i := range parts {
+		if err = parts[i].sink.flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// partSink draws the part of the complex solution
+type partSink struct {
+	tag     string
+	val     func(y complex128) float64
+	sink    sink[float64]
+	lastArg float64 // the previous unwrapped phase
+}
+
+// put draws the part of y at x, the phase is unwrapped to be within π from the previous one
+func (ps *partSink) put(i int, h, x float64, y complex128) error {
+	v := ps.val(y)
+	if ps.tag == PartArg && i > 0 && isFinite(v) && isFinite(ps.lastArg) {
+		v += 2 * math.Pi * math.Round((ps.lastArg-v)/(2*math.Pi))
+	}
+	ps.lastArg = v
+	return ps.sink.put(i, h, num.Point{X: x, Y: v})
+}
+
+// unknownParts returns the sorted tags of drawers, which are not parts of the solution
+func unknownParts(d map[string]Drawer) string {
+	var res []string
+	for tag := range d {
+		known := false
+		for _, part := range complexParts {
+			known = known || part.tag == tag
+		}
+		if !known {
+			res = append(res, tag)
+		}
+	}
+	sort.Strings(res)
+	return strings.Join(res, ", ")
+}
+
+// CollectComplex solves the complex problem and collects the parts of the solution by their tags,
+// lines are named by tags of parts
+func CollectComplex(c *Complex, step, x0 float64, y0 complex128, xEnd float64, parts ...string) (map[string]num.Line, error) {
+	cs := make(map[string]*Collector, len(parts))
+	d := make(map[string]Drawer, len(parts))
+	for _, tag := range parts {
+		cs[tag] = &Collector{}
+		d[tag] = cs[tag]
+	}
+	if err := c.Solve(step, x0, y0, xEnd, d); err != nil {
+		return nil, err
+	}
+	res := make(map[string]num.Line, len(cs))
+	for tag, col := range cs {
+		res[tag] = num.Line{Name: tag, Points: col.Points}
+	}
+	return res, nil
+}
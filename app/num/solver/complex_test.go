@@ -0,0 +1,91 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rotation is y' = i·y, the solution from y(0) = 1 is cos(x) + i·sin(x)
+func rotation(_ float64, y complex128) (complex128, error) { return 1i * y, nil }
+
+func TestComplex(t *testing.T) {
+	c := &Complex{F: rotation}
+	assert.Equal(t, "Runge-Kutta's method for complex y", c.Name())
+	lines, err := CollectComplex(c, 0.1, 0, 1, 2*math.Pi, PartRe, PartIm, PartAbs, PartArg)
+	require.NoError(t, err)
+	require.Len(t, lines, 4)
+	for tag, line := range lines {
+		assert.Equal(t, tag, line.Name)
+		require.Len(t, line.Points, 64, tag)
+	}
+
+	for i := range lines[PartRe].Points {
+		x := lines[PartRe].Points[i].X
+		for _, tag := range []string{PartIm, PartAbs, PartArg} {
+			assert.Equal(t, x, lines[tag].Points[i].X, "parts share the grid")
+		}
+		assert.InDelta(t, math.Cos(x), lines[PartRe].Points[i].Y, 1e-5, "re at x=%v", x)
+		assert.InDelta(t, math.Sin(x), lines[PartIm].Points[i].Y, 1e-5, "im at x=%v", x)
+		assert.InDelta(t, 1, lines[PartAbs].Points[i].Y, 1e-6, "|y| is conserved up to h^6 per step at x=%v", x)
+		assert.InDelta(t, x, lines[PartArg].Points[i].Y, 1e-5, "the phase is unwrapped at x=%v", x)
+	}
+}
+
+func TestComplex_Methods(t *testing.T) {
+	// |y| of explicit methods on y' = i·y grows by the modulus of the stability function at each step
+	tbl := []struct {
+		method string
+		growth float64
+	}{
+		{"euler", math.Sqrt(1 + 0.01)},
+		{"ieuler", math.Sqrt(1 + 0.0001/4)},
+		{"rk4", math.Sqrt(1 - 1e-6/72 + 1e-8/576)},
+	}
+	for _, tt := range tbl {
+		lines, err := CollectComplex(&Complex{F: rotation, Method: tt.method}, 0.1, 0, 1, 1, PartAbs)
+		require.NoError(t, err, tt.method)
+		require.Len(t, lines[PartAbs].Points, 11)
+		for i, p := range lines[PartAbs].Points {
+			assert.InDelta(t, math.Pow(tt.growth, float64(i)), p.Y, 1e-12, "%s at step %d", tt.method, i)
+		}
+	}
+}
+
+func TestComplex_Errors(t *testing.T) {
+	c := &Complex{F: rotation}
+	assert.EqualError(t, c.Solve(0.1, 0, 1, 1, map[string]Drawer{"re": &Collector{}, "mod": &Collector{}, "a": &Collector{}}),
+		"unknown parts of the solution a, mod, must be re, im, abs or arg")
+	assert.EqualError(t, c.Solve(0.1, 0, 1, 1, nil), "no drawers of parts of the solution")
+	assert.True(t, errors.Is(c.Solve(0, 0, 1, 1, map[string]Drawer{"re": &Collector{}}), num.ErrBadStep))
+
+	c = &Complex{F: rotation, Method: "exact"}
+	assert.Equal(t, "Complex solution", c.Name())
+	assert.EqualError(t, c.Solve(0.1, 0, 1, 1, map[string]Drawer{"re": &Collector{}}),
+		`unknown method "exact", must be euler, ieuler or rk4`)
+
+	// the failure of f is located at the stage of the step, points before it are drawn
+	failed := errors.New("failed")
+	c = &Complex{F: func(x float64, y complex128) (complex128, error) {
+		if x > 0.25 {
+			return 0, failed
+		}
+		return 1i * y, nil
+	}}
+	re, im := &Collector{}, &Collector{}
+	err := c.Solve(0.1, 0, 1, 1, map[string]Drawer{PartRe: re, PartIm: im})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, failed))
+	se := &StepError{}
+	require.True(t, errors.As(err, &se))
+	assert.Equal(t, 2, se.Step)
+	assert.Equal(t, "k4", se.Stage)
+	assert.InDelta(t, 0.3, se.X, 1e-12)
+	assert.Contains(t, err.Error(), "complex y=(")
+	assert.Len(t, re.Points, 3)
+	assert.Len(t, im.Points, 3)
+}
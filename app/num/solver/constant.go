@@ -0,0 +1,65 @@
+package solver
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// maxConstantIter is the most bisections of the bracket of the constant, the bracket of floats can't be halved
+// more than about two thousand times
+const maxConstantIter = 2200
+
+// NumericC makes C of the exact solution, that finds the constant, for which the solution passes through (x0, y0),
+// by bisection of F(x0, c) - y0 over the bracket [lo, hi], until the residual is within tol, so the exact solution,
+// whose constant has no closed form, is used as the reference. Residuals at ends of the bracket must differ in sign,
+// the root, that is not within tol, when the bracket can't be halved further, is ErrNotConverged
+func NumericC(f func(x, c float64) (float64, error), lo, hi, tol float64) func(x0, y0 float64) (float64, error) {
+	return func(x0, y0 float64) (float64, error) {
+		if !isFinite(lo) || !isFinite(hi) || !(lo < hi) {
+			return 0, errors.Errorf("bracket of the constant must be finite with lo < hi, got [%v, %v]", lo, hi)
+		}
+		if !(tol > 0) || math.IsInf(tol, 1) {
+			return 0, errors.Errorf("tolerance must be positive and finite, got %v", tol)
+		}
+		residual := func(c float64) (float64, error) {
+			y, err := f(x0, c)
+			if err != nil {
+				return 0, errors.Wrapf(err, "failed to calculate the solution with c=%v", c)
+			}
+			return y - y0, nil
+		}
+
+		rlo, err := residual(lo)
+		if err != nil || math.Abs(rlo) <= tol {
+			return lo, err
+		}
+		rhi, err := residual(hi)
+		if err != nil || math.Abs(rhi) <= tol {
+			return hi, err
+		}
+		if !(rlo*rhi < 0) {
+			return 0, errors.Errorf("residuals %.3g and %.3g at ends of the bracket [%v, %v] don't differ in sign",
+				rlo, rhi, lo, hi)
+		}
+		mid, rmid := lo, rlo
+		for iter := 0; iter < maxConstantIter; iter++ {
+			mid = lo + (hi-lo)/2
+			if mid == lo || mid == hi {
+				break // the bracket can't be halved further
+			}
+			if rmid, err = residual(mid); err != nil {
+				return 0, err
+			}
+			if math.Abs(rmid) <= tol {
+				return mid, nil
+			}
+			if (rmid < 0) == (rlo < 0) {
+				lo, rlo = mid, rmid
+			} else {
+				hi = mid
+			}
+		}
+		return 0, errors.Wrapf(ErrNotConverged, "residual %.3g with c=%v, tolerance is %g", rmid, mid, tol)
+	}
+}
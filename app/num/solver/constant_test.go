@@ -0,0 +1,46 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNumericC(t *testing.T) {
+	// y = (c + c^3) exp(x), c has no simple closed form by (x0, y0)
+	f := func(x, c float64) (float64, error) { return (c + c*c*c) * math.Exp(x), nil }
+	c, err := NumericC(f, -10, 10, 1e-12)(1, 10*math.E)
+	require.NoError(t, err)
+	assert.InDelta(t, 2, c, 1e-12)
+
+	// the exact solution with the numeric constant solves the problem
+	exact := &Exact{F: f, C: NumericC(f, -10, 10, 1e-12)}
+	line, err := Collect(exact, 0.5, 1, 10*math.E, 2)
+	require.NoError(t, err)
+	require.Len(t, line.Points, 3)
+	assert.InDelta(t, 10*math.Exp(2), line.Points[2].Y, 1e-9)
+
+	// ends of the bracket are roots too
+	c, err = NumericC(f, 2, 3, 1e-9)(0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, c)
+
+	_, err = NumericC(f, 3, 4, 1e-9)(0, 10)
+	assert.EqualError(t, err, "residuals 20 and 58 at ends of the bracket [3, 4] don't differ in sign")
+	_, err = NumericC(f, 1, -1, 1e-9)(0, 10)
+	assert.EqualError(t, err, "bracket of the constant must be finite with lo < hi, got [1, -1]")
+	_, err = NumericC(f, -1, 1, 0)(0, 10)
+	assert.EqualError(t, err, "tolerance must be positive and finite, got 0")
+
+	// the solution jumps over y0 with c, so the residual is never within the tolerance
+	_, err = NumericC(func(x, c float64) (float64, error) { return math.Floor(c), nil }, -1, 2, 1e-3)(0, 0.5)
+	assert.True(t, errors.Is(err, ErrNotConverged), "%v", err)
+
+	errF := errors.New("f failed")
+	_, err = NumericC(func(x, c float64) (float64, error) { return 0, errF }, -1, 1, 1e-9)(0, 1)
+	assert.True(t, errors.Is(err, errF), "%v", err)
+	assert.Contains(t, err.Error(), "failed to calculate the solution with c=-1")
+}
@@ -0,0 +1,48 @@
+package solver
+
+import (
+	"math"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// ConvergenceStudy solves the problem by the solver with each number of steps from nMin to nMax and draws
+// points (n, max global error) of solutions against the exact solution at their nodes, e.g. to plot them
+// in log-log scale, where the slope is the order of the method. The solution, that diverged, as well as
+// the failed one, fails the study, points of smaller n are drawn before it
+func ConvergenceStudy(s Interface, exact *Exact, nMin, nMax int, x0, y0, xEnd float64, d Drawer) error {
+	if nMin < 1 || nMax < nMin {
+		return errors.Errorf("numbers of steps must be 1 <= n_min <= n_max, got n_min=%d and n_max=%d", nMin, nMax)
+	}
+	c, err := exact.Constant(x0, y0)
+	if err != nil {
+		return err
+	}
+	for n := nMin; n <= nMax; n++ {
+		step, err := num.CalculateStepSize(n, x0, xEnd)
+		if err != nil {
+			return errors.Wrapf(err, "failed to calculate step for n=%d", n)
+		}
+		line, err := Collect(s, step, x0, y0, xEnd)
+		if err != nil {
+			return errors.Wrapf(err, "failed to solve with n=%d", n)
+		}
+		gte := 0.0
+		for _, p := range line.Points {
+			y, err := exact.F(p.X, c)
+			if err != nil {
+				return errors.Wrapf(err, "failed to calculate exact solution at x=%.4f with n=%d", p.X, n)
+			}
+			e := math.Abs(p.Y - y)
+			if !isFinite(e) {
+				return errors.Errorf("error is not finite at x=%.4f with n=%d", p.X, n)
+			}
+			gte = math.Max(gte, e)
+		}
+		if err = d.Draw(num.Point{X: float64(n), Y: gte}); err != nil {
+			return errors.Wrapf(err, "failed to draw the error with n=%d", n)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvergenceStudy(t *testing.T) {
+	exact := &Exact{
+		F: func(x, c float64) (float64, error) { return math.Exp(-x) / (c*math.Exp(x) + 1), nil },
+		C: func(x0, y0 float64) (float64, error) { return (math.Exp(-x0) - y0) / (y0 * math.Exp(x0)), nil },
+	}
+	for _, tt := range []struct {
+		s     Interface
+		order float64
+	}{
+		{&Euler{F: canonical}, 1},
+		{&ImprovedEuler{F: canonical}, 2},
+		{&RungeKutta{F: canonical}, 4},
+	} {
+		c := &Collector{}
+		require.NoError(t, ConvergenceStudy(tt.s, exact, 100, 200, -4, 1, 4, c), tt.s.Name())
+		require.Len(t, c.Points, 101)
+		assert.Equal(t, 100.0, c.Points[0].X)
+		assert.Equal(t, 200.0, c.Points[100].X)
+		order := math.Log2(c.Points[0].Y / c.Points[100].Y)
+		assert.InDelta(t, tt.order, order, 0.2, "%s: the error falls by 2^order, as n doubles", tt.s.Name())
+	}
+
+	c := &Collector{}
+	err := ConvergenceStudy(&Euler{F: canonical}, exact, 0, 10, -4, 1, 4, c)
+	assert.EqualError(t, err, "numbers of steps must be 1 <= n_min <= n_max, got n_min=0 and n_max=10")
+	err = ConvergenceStudy(&Euler{F: canonical}, exact, 10, 20, -4, 0, 4, c)
+	assert.True(t, errors.Is(err, ErrConstant), err)
+
+	errF := errors.New("failed")
+	err = ConvergenceStudy(&Euler{F: func(x, y float64) (float64, error) {
+		if x > 3.9 {
+			return 0, errF
+		}
+		return canonical(x, y)
+	}}, exact, 1, 100, -4, 1, 4, c)
+	assert.True(t, errors.Is(err, errF))
+	assert.Contains(t, err.Error(), "failed to solve with n=")
+	assert.NotEmpty(t, c.Points, "points of coarse grids, that step over the failure, are drawn before it")
+
+	err = ConvergenceStudy(&RungeKutta{F: canonical}, exact, 30, 40, -4, 1, 4, DrawerFunc(func(p num.Point) error {
+		if p.X > 32 {
+			return errF
+		}
+		return nil
+	}))
+	assert.True(t, errors.Is(err, errF))
+	assert.Contains(t, err.Error(), "failed to draw the error with n=33")
+}
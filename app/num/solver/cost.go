@@ -0,0 +1,68 @@
+package solver
+
+import (
+	"sync"
+	"time"
+)
+
+// Cost is the cost of the solve, so methods are compared by the work, that they do, e.g. Runge-Kutta's method
+// evaluates f four times per step, while Euler's method does it once
+type Cost struct {
+	Evals  int           // number of evaluations of f(x,y)
+	Points int           // number of drawn points
+	Took   time.Duration // wall-clock time of the solve, including drawing
+}
+
+// EvalsPerStep returns the mean number of evaluations of f by each step, zero, if no step is made
+func (c Cost) EvalsPerStep() float64 {
+	if c.Points < 2 {
+		return 0
+	}
+	return float64(c.Evals) / float64(c.Points-1)
+}
+
+// Metered is the solver, that counts evaluations of f and measures the time of each solve,
+// the cost of the last solve is reported by Stats, solves must not run at once
+type Metered struct {
+	Solver Interface
+	evals  *CountingFunc
+
+	mu   sync.Mutex
+	last Cost
+}
+
+// Meter builds the solver of the method on f, counting its evaluations
+func Meter(method Builder, f Func) *Metered {
+	evals := &CountingFunc{F: f}
+	return &Metered{Solver: method(evals.Eval), evals: evals}
+}
+
+// Name returns the name of the solver, as the solution is the same
+func (m *Metered) Name() string { return m.Solver.Name() }
+
+// Solve solves the problem by the solver and accounts its cost, the failed solve is accounted as well
+func (m *Metered) Solve(stepSize, x0, y0, xEnd float64, d Drawer) error {
+	m.evals.Reset()
+	points := 0
+	st := time.Now()
+	err := m.Solver.Solve(stepSize, x0, y0, xEnd, wrap(d, func(c call) error {
+		if err := c.do(); err != nil {
+			return err
+		}
+		points++
+		return nil
+	}))
+	cost := Cost{Evals: m.evals.Calls(), Points: points, Took: time.Since(st)}
+
+	m.mu.Lock()
+	m.last = cost
+	m.mu.Unlock()
+	return err
+}
+
+// Stats returns the cost of the last solve, zero before the first one
+func (m *Metered) Stats() Cost {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.last
+}
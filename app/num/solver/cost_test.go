@@ -0,0 +1,54 @@
+package solver
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeter(t *testing.T) {
+	tbl := []struct {
+		method  string
+		perStep float64
+	}{{"euler", 1}, {"ieuler", 2}, {"rk4", 4}}
+	for _, tt := range tbl {
+		b, ok := Lookup(tt.method)
+		require.True(t, ok)
+		m := Meter(b, canonical)
+		assert.Equal(t, Cost{}, m.Stats(), "nothing is solved yet")
+
+		c := &Collector{}
+		require.NoError(t, m.Solve(0.1, 0, 1, 1, c), tt.method)
+		assert.Equal(t, b(canonical).Name(), m.Name())
+		cost := m.Stats()
+		assert.Equal(t, 11, cost.Points, tt.method)
+		assert.Equal(t, int(tt.perStep)*10, cost.Evals, tt.method)
+		assert.Equal(t, tt.perStep, cost.EvalsPerStep(), tt.method)
+		assert.GreaterOrEqual(t, int64(cost.Took), int64(0), tt.method)
+
+		// the cost is of the last solve only
+		require.NoError(t, m.Solve(0.2, 0, 1, 1, c), tt.method)
+		assert.Equal(t, 6, m.Stats().Points, tt.method)
+		assert.Equal(t, int(tt.perStep)*5, m.Stats().Evals, tt.method)
+	}
+}
+
+func TestMeter_Failed(t *testing.T) {
+	b, _ := Lookup("rk4")
+	m := Meter(b, canonical)
+	drawn := 0
+	err := m.Solve(0.1, 0, 1, 1, DrawerFunc(func(num.Point) error {
+		if drawn == 3 {
+			return errors.New("failed")
+		}
+		drawn++
+		return nil
+	}))
+	require.Error(t, err)
+	assert.Equal(t, 3, m.Stats().Points, "the failed point is not drawn")
+	assert.Equal(t, 12, m.Stats().Evals, "evaluations of the failed solve are accounted")
+	assert.Equal(t, 0.0, Cost{Points: 1, Evals: 1}.EvalsPerStep(), "no step is made")
+}
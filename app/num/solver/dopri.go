@@ -0,0 +1,219 @@
+package solver
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// ErrNotSolved is returned by Interpolate, if the solver has no solution at x
+var ErrNotSolved = errors.New("x is out of the solution")
+
+// DormandPrince is the Dormand-Prince method of the 5th order with the adaptive step, the error is estimated
+// by the embedded solution of the 4th order, the step is controlled as the one of RKF45, the last stage of
+// the step is the first stage of the next one, so the accepted step takes six evaluations of f.
+// Along with drawn points of taken steps, the solution is the polynomial of the 4th order between them,
+// that Interpolate evaluates at any x of the last solution
+type DormandPrince struct {
+	F       Func    // calculator for f(x,y) = y'
+	Tol     float64 // max estimated local error of the step, 1e-6 if zero
+	MinStep float64 // the least step, the solution fails, if the error exceeds the tolerance with it
+	MaxStep float64 // the largest step, the whole interval if zero
+
+	mu    sync.Mutex
+	dense []denseStep // interpolants of taken steps of the last solution
+}
+
+// denseStep is the interpolant of the taken step from x with the size h, the polynomial of θ = (x'-x)/h
+// is r0 + θ(r1 + (1-θ)(r2 + θ(r3 + (1-θ)r4)))
+type denseStep struct {
+	x, h float64
+	r    [5]float64
+}
+
+// dpStages are nodes of stages of the step and coefficients of previous stages of the Dormand-Prince's tableau,
+// the last stage is the solution of the 5th order at the end of the step
+var dpStages = [...]struct {
+	c float64
+	a []float64
+}{
+	{0, nil},
+	{1.0 / 5, []float64{1.0 / 5}},
+	{3.0 / 10, []float64{3.0 / 40, 9.0 / 40}},
+	{4.0 / 5, []float64{44.0 / 45, -56.0 / 15, 32.0 / 9}},
+	{8.0 / 9, []float64{19372.0 / 6561, -25360.0 / 2187, 64448.0 / 6561, -212.0 / 729}},
+	{1, []float64{9017.0 / 3168, -355.0 / 33, 46732.0 / 5247, 49.0 / 176, -5103.0 / 18656}},
+	{1, []float64{35.0 / 384, 0, 500.0 / 1113, 125.0 / 192, -2187.0 / 6784, 11.0 / 84}},
+}
+
+// dpErr are differences of weights of solutions of the 5th and the 4th orders
+var dpErr = [7]float64{71.0 / 57600, 0, -71.0 / 16695, 71.0 / 1920, -17253.0 / 339200, 22.0 / 525, -1.0 / 40}
+
+// dpDense are weights of stages of the interpolant of the step
+var dpDense = [7]float64{-12715105075.0 / 11282082432, 0, 87487479700.0 / 32700410799, -10690763975.0 / 1880347072,
+	701980252875.0 / 199316789632, -1453857185.0 / 822651844, 69997945.0 / 29380423}
+
+// Name returns the name of the method
+func (dp *DormandPrince) Name() string { return "Dormand-Prince's method" }
+
+// Solve the differential equation from x0 with the initial step size, which is adapted to the tolerance,
+// the last step is shortened to end at xEnd, interpolants of taken steps replace the ones of the previous solution,
+// the solution goes backward, if xEnd is less than x0
+func (dp *DormandPrince) Solve(stepSize, x0, y0, xEnd float64, d Drawer) error {
+	if err := checkArgs(stepSize, x0, xEnd); err != nil {
+		return err
+	}
+	tol, minStep, maxStep := dp.Tol, dp.MinStep, dp.MaxStep
+	if tol == 0 {
+		tol = defaultRKF45Tol
+	}
+	if maxStep == 0 {
+		maxStep = math.Abs(xEnd - x0)
+	}
+	if !(tol > 0) || math.IsInf(tol, 1) {
+		return errors.Errorf("tolerance must be positive and finite, got %v", tol)
+	}
+	if !(minStep >= 0) || !(maxStep >= minStep) {
+		return errors.Errorf("steps must be 0 <= min_step <= max_step, got min_step=%v and max_step=%v", minStep, maxStep)
+	}
+
+	logger(d).Logf("[DEBUG] starting solving the equation with Dormand-Prince's "+
+		"method with stepsz = %.4f, tol = %v, x0 = %.4f, y0 = %.4f, xend = %.4f", stepSize, tol, x0, y0, xEnd)
+
+	var dense []denseStep
+	defer func() { dp.setDense(dense) }()
+
+	// h is the size of the step, the solution goes by dir*h, backward on the reversed interval
+	h, dir := math.Max(minStep, math.Min(stepSize, maxStep)), direction(x0, xEnd)
+	out := sinkOf(dp.Name(), d)
+	if err := out.put(0, 0, num.Point{X: x0, Y: y0}); err != nil {
+		return err
+	}
+	if x0 == xEnd {
+		return out.flush()
+	}
+	x, y := x0, y0
+	k1, err := dp.F(x, y)
+	if err != nil {
+		return out.fail(&StepError{Method: dp.Name(), Step: 0, Stage: "k1", X: x, Y: y, Err: err})
+	}
+	var stages [7]float64 // stages of the step, passed to the meta drawer
+	for i := 0; dir*(xEnd-x) > 0; {
+		last := h*(1+gridTolerance) >= dir*(xEnd-x) // the rounding error of x doesn't leave the tiny last step
+		if last {
+			h = dir * (xEnd - x)
+		}
+		sh := dir * h // the signed step
+		k, err := dp.step(i, x, y, sh, k1)
+		if err != nil {
+			return out.fail(err)
+		}
+		errEst := 0.0
+		for s, e := range dpErr {
+			errEst += e * k[s]
+		}
+		errEst = math.Abs(sh * errEst)
+		next := y
+		for s, a := range dpStages[6].a {
+			next += sh * a * k[s]
+		}
+		stages = k
+		meta := Meta{Step: i + 1, H: sh, Order: 5, LocalErr: errEst, Stages: stages[:]}
+
+		if !(errEst <= tol) { // the not finite estimate is rejected too
+			if err = out.reject(num.Point{X: x + sh, Y: next}, meta); err != nil {
+				return out.fail(err)
+			}
+			if h <= minStep || x+h*rkf45Shrink == x {
+				err := errors.Wrapf(num.ErrStepTooSmall, "local error %g exceeds the tolerance %g with the step %v",
+					errEst, tol, h)
+				return out.fail(&StepError{Method: dp.Name(), Step: i, Stage: "adapt", X: x, Y: y, Err: err})
+			}
+			h = math.Max(minStep, h*math.Max(rkf45Shrink, rkf45Safety*math.Pow(tol/errEst, 0.2)))
+			continue
+		}
+
+		dense = append(dense, denseStepOf(x, sh, y, next, k))
+
+		if x, y, k1 = x+sh, next, k[6]; last {
+			x = xEnd
+		}
+		i++
+		if err = out.putMeta(num.Point{X: x, Y: y}, meta); err != nil {
+			return err
+		}
+
+		scale := rkf45Grow
+		if errEst > 0 {
+			scale = math.Min(rkf45Grow, rkf45Safety*math.Pow(tol/errEst, 0.2))
+		}
+		h = math.Max(minStep, math.Min(maxStep, h*math.Max(rkf45Shrink, scale)))
+	}
+
+	return out.flush()
+}
+
+// step makes stages of the i-th step of the size h from (x, y), k1 is f at (x, y), the last stage is f
+// at the end of the step by the solution of the 5th order
+func (dp *DormandPrince) step(i int, x, y, h, k1 float64) (k [7]float64, err error) {
+	k[0] = k1
+	for s := 1; s < len(dpStages); s++ {
+		st := dpStages[s]
+		ys := y
+		for j, a := range st.a {
+			ys += h * a * k[j]
+		}
+		if k[s], err = dp.F(x+st.c*h, ys); err != nil {
+			return k, &StepError{Method: dp.Name(), Step: i, Stage: "k" + strconv.Itoa(s+1), X: x + st.c*h, Y: ys,
+				Err: err}
+		}
+	}
+	return k, nil
+}
+
+// denseStepOf makes the interpolant of the step from (x, y) to next by its stages
+func denseStepOf(x, h, y, next float64, k [7]float64) denseStep {
+	diff := next - y
+	bspl := h*k[0] - diff
+	r4 := 0.0
+	for s, w := range dpDense {
+		r4 += w * k[s]
+	}
+	return denseStep{x: x, h: h, r: [5]float64{y, diff, bspl, diff - h*k[6] - bspl, h * r4}}
+}
+
+// Interpolate returns y(x) of the last solution by the interpolant of the step, that contains x,
+// at ends of steps it is the drawn point, up to the rounding error, x out of the solution is ErrNotSolved
+func (dp *DormandPrince) Interpolate(x float64) (float64, error) {
+	dp.mu.Lock()
+	defer dp.mu.Unlock()
+	n := len(dp.dense)
+	if n == 0 {
+		return 0, errors.Wrapf(ErrNotSolved, "x=%v, no step is taken", x)
+	}
+	first, last := dp.dense[0], dp.dense[n-1]
+	dir := direction(first.x, first.x+first.h) // steps of the backward solution go with decreasing x
+	if !(dir*(x-first.x) >= 0 && dir*(last.x+last.h-x) >= 0) {
+		return 0, errors.Wrapf(ErrNotSolved, "x=%v, the solution is on [%v, %v]", x,
+			math.Min(first.x, last.x+last.h), math.Max(first.x, last.x+last.h))
+	}
+	i := sort.Search(n, func(i int) bool { return dir*(dp.dense[i].x+dp.dense[i].h-x) >= 0 })
+	if i == n {
+		i = n - 1 // x is the end of the last step up to the rounding error
+	}
+	st := dp.dense[i]
+	t := (x - st.x) / st.h
+	t1 := 1 - t
+	r := st.r
+	return r[0] + t*(r[1]+t1*(r[2]+t*(r[3]+t1*r[4]))), nil
+}
+
+func (dp *DormandPrince) setDense(dense []denseStep) {
+	dp.mu.Lock()
+	defer dp.mu.Unlock()
+	dp.dense = dense
+}
@@ -0,0 +1,140 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDormandPrince_Solve(t *testing.T) {
+	exact := func(x float64) float64 {
+		c := (math.Exp(4) - 1) / math.Exp(-4)
+		return math.Exp(-x) / (c*math.Exp(x) + 1)
+	}
+	evals := &CountingFunc{F: canonical}
+	dp := &DormandPrince{F: evals.Eval, Tol: 1e-9}
+	_, err := dp.Interpolate(0)
+	assert.True(t, errors.Is(err, ErrNotSolved), err)
+
+	d := &stepCollector{}
+	require.NoError(t, dp.Solve(0.1, -4, 1, 4, d))
+	n := len(d.Points)
+	assert.Equal(t, num.Point{X: -4, Y: 1}, d.Points[0])
+	assert.Equal(t, 4.0, d.Points[n-1].X)
+	assert.InDelta(t, exact(4), d.Points[n-1].Y, 1e-8)
+	assert.Equal(t, 0.0, d.hs[0])
+	assert.Zero(t, (evals.Calls()-1)%6, "f at x0 is reused, so the accepted or rejected step takes six evaluations")
+
+	rkf := &stepCollector{}
+	require.NoError(t, (&RKF45{F: canonical, Tol: 1e-9}).Solve(0.1, -4, 1, 4, rkf))
+	assert.Less(t, n, len(rkf.Points), "the method of the 5th order takes larger steps")
+
+	// the interpolant passes through drawn points and approximates the solution between them
+	for i, p := range d.Points {
+		y, err := dp.Interpolate(p.X)
+		require.NoError(t, err)
+		assert.InDelta(t, p.Y, y, 1e-12, "x=%v", p.X)
+		if i == 0 {
+			continue
+		}
+		mid := d.Points[i-1].X + d.hs[i]/2
+		y, err = dp.Interpolate(mid)
+		require.NoError(t, err)
+		assert.InDelta(t, exact(mid), y, 1e-7, "x=%v", mid)
+	}
+
+	for _, x := range []float64{-4.1, 4.1, math.NaN()} {
+		_, err = dp.Interpolate(x)
+		assert.True(t, errors.Is(err, ErrNotSolved), err)
+	}
+	assert.Contains(t, err.Error(), "the solution is on [-4, 4]")
+
+	// the empty interval is the single point without interpolants
+	c := &Collector{}
+	require.NoError(t, dp.Solve(0.1, 1, 1, 1, c))
+	assert.Equal(t, []num.Point{{X: 1, Y: 1}}, c.Points)
+	_, err = dp.Interpolate(1)
+	assert.True(t, errors.Is(err, ErrNotSolved), err)
+}
+
+func TestDormandPrince_Backward(t *testing.T) {
+	// y' = y backward from y(2) = e^2 decays to y(-2) = e^-2
+	exp := func(_, y float64) (float64, error) { return y, nil }
+	dp := &DormandPrince{F: exp, Tol: 1e-9}
+	d := &stepCollector{}
+	require.NoError(t, dp.Solve(0.1, 2, math.Exp(2), -2, d))
+	n := len(d.Points)
+	assert.Equal(t, -2.0, d.Points[n-1].X)
+	assert.InDelta(t, math.Exp(-2), d.Points[n-1].Y, 1e-8)
+
+	// interpolants of steps back go with decreasing x as well
+	for i := 1; i < n; i++ {
+		assert.Less(t, d.hs[i], 0.0)
+		mid := d.Points[i-1].X + d.hs[i]/2
+		y, err := dp.Interpolate(mid)
+		require.NoError(t, err)
+		assert.InDelta(t, math.Exp(mid), y, 1e-7, "x=%v", mid)
+	}
+	y, err := dp.Interpolate(2)
+	require.NoError(t, err)
+	assert.Equal(t, math.Exp(2), y)
+	_, err = dp.Interpolate(2.1)
+	assert.True(t, errors.Is(err, ErrNotSolved), err)
+	assert.Contains(t, err.Error(), "the solution is on [-2, 2]")
+}
+
+func TestDormandPrince_Meta(t *testing.T) {
+	exp := func(x, y float64) (float64, error) { return y, nil }
+	const tol = 1e-8
+	d := &metaCollector{}
+	require.NoError(t, (&DormandPrince{F: exp, Tol: tol}).Solve(1, 0, 1, 2, d))
+	want, err := Collect(&DormandPrince{F: exp, Tol: tol}, 1, 0, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, want.Points, d.Points, "rejected steps are not points of the solution")
+	require.NotEmpty(t, d.rejected, "the initial step is too large")
+
+	for i, m := range d.metas[1:] {
+		assert.Equal(t, 5, m.Order)
+		require.Len(t, m.Stages, 7)
+		if m.Rejected {
+			assert.Greater(t, m.LocalErr, tol, "meta %d", i+1)
+			continue
+		}
+		assert.LessOrEqual(t, m.LocalErr, tol, "meta %d", i+1)
+		assert.InDelta(t, d.Points[m.Step].Y, m.Stages[6], 1e-12, "the last stage is f at the end of the step")
+	}
+}
+
+func TestDormandPrince_Errors(t *testing.T) {
+	assert.EqualError(t, (&DormandPrince{F: canonical, Tol: -1}).Solve(0.1, 0, 1, 1, &Collector{}),
+		"tolerance must be positive and finite, got -1")
+	assert.EqualError(t, (&DormandPrince{F: canonical, MinStep: 2, MaxStep: 1}).Solve(0.1, 0, 1, 1, &Collector{}),
+		"steps must be 0 <= min_step <= max_step, got min_step=2 and max_step=1")
+
+	errF := errors.New("failed")
+	err := (&DormandPrince{F: func(x, y float64) (float64, error) {
+		if x > 0.5 {
+			return 0, errF
+		}
+		return y, nil
+	}}).Solve(0.1, 0, 1, 1, &Collector{})
+	assert.True(t, errors.Is(err, errF))
+	var se *StepError
+	require.True(t, errors.As(err, &se))
+	assert.Contains(t, se.Stage, "k")
+
+	err = (&DormandPrince{F: func(x, y float64) (float64, error) { return 1 / (x - 0.5), nil }, MinStep: 0.01}).
+		Solve(0.1, 0, 1, 1, &Collector{})
+	assert.True(t, errors.Is(err, num.ErrStepTooSmall), err)
+	require.True(t, errors.As(err, &se))
+	assert.Equal(t, "adapt", se.Stage)
+}
+
+// BenchmarkDormandPrince measures steps of the max size, so their number is the same as of other solvers
+func BenchmarkDormandPrince(b *testing.B) {
+	benchSolver(b, &DormandPrince{F: benchF, MaxStep: 1.0 / benchSteps})
+}
@@ -0,0 +1,113 @@
+package solver
+
+import (
+	"math"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// StepDoubling wraps the one-step solver of the given order to estimate the local error of each step by the step
+// doubling: the step from the previous node is repeated as two halves, and the local error of the whole one
+// is |y_{h/2,h/2} - y_h| * 2^p / (2^p - 1), so error bars are drawn without the exact solution, at the cost
+// of three more steps per step
+type StepDoubling struct {
+	Solver Interface
+	Order  int    // order p of the global error of the solver, e.g. 4 for Runge-Kutta's method
+	Errors Drawer // receives the estimate of the local error of the step, that led to each node, zero at x0
+}
+
+// Name returns the name of the solver, as the solution is the same
+func (s *StepDoubling) Name() string { return s.Solver.Name() }
+
+// Solve solves the problem by the solver and draws the estimate of the local error of each step, as soon as
+// the point of the step is drawn
+func (s *StepDoubling) Solve(stepSize, x0, y0, xEnd float64, d Drawer) error {
+	if s.Order < 1 {
+		return errors.Errorf("order of the method must be positive, got %d", s.Order)
+	}
+	if s.Errors == nil {
+		return s.Solver.Solve(stepSize, x0, y0, xEnd, d)
+	}
+
+	factor := doublingFactor(s.Order)
+	errs := sinkOf(s.Name(), s.Errors)
+	var prev num.Point
+	i := 0
+	err := s.Solver.Solve(stepSize, x0, y0, xEnd, wrap(d, func(c call) error {
+		if err := c.do(); err != nil {
+			return err
+		}
+		est, h := 0.0, 0.0
+		if i > 0 {
+			h = c.p.X - prev.X
+			var err error
+			if est, err = estimateStep(s.Solver, factor, prev, c.p.X); err != nil {
+				return errors.Wrapf(err, "failed to estimate the error of the step to x=%v", c.p.X)
+			}
+		}
+		if err := errs.put(i, h, num.Point{X: c.p.X, Y: est}); err != nil {
+			return err
+		}
+		prev = c.p
+		i++
+		return nil
+	}))
+	if err != nil {
+		return err
+	}
+	return errs.flush()
+}
+
+// EstimateLocal estimates local errors of steps of the solution by the one-step solver of the given order
+// by the step doubling, each step is repeated from the previous point of the solution, the estimate
+// at the first point is zero, as the initial value is exact
+func EstimateLocal(s Interface, order int, pts []num.Point) ([]num.Point, error) {
+	if order < 1 {
+		return nil, errors.Errorf("order of the method must be positive, got %d", order)
+	}
+	if len(pts) == 0 {
+		return nil, nil
+	}
+	factor := doublingFactor(order)
+	res := make([]num.Point, len(pts))
+	res[0] = num.Point{X: pts[0].X}
+	for i := 1; i < len(pts); i++ {
+		est, err := estimateStep(s, factor, pts[i-1], pts[i].X)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to estimate the error of the step to x=%v", pts[i].X)
+		}
+		res[i] = num.Point{X: pts[i].X, Y: est}
+	}
+	return res, nil
+}
+
+// doublingFactor scales the difference of the whole step and two halves to the error of the whole step
+func doublingFactor(order int) float64 {
+	return math.Exp2(float64(order)) / (math.Exp2(float64(order)) - 1)
+}
+
+// estimateStep makes the step of the solver from the point a to x as the whole one and as two halves
+// and returns their scaled difference
+func estimateStep(s Interface, factor float64, a num.Point, x float64) (float64, error) {
+	h := math.Abs(x - a.X) // the size of the step, the solver goes backward, if x is less than a.X
+	whole, err := lastY(s, h, a, x)
+	if err != nil {
+		return 0, err
+	}
+	halves, err := lastY(s, h/2, a, x)
+	if err != nil {
+		return 0, err
+	}
+	return math.Abs(halves-whole) * factor, nil
+}
+
+// lastY returns y at the end of the solution by the solver from the point a to x with the step h
+func lastY(s Interface, h float64, a num.Point, x float64) (float64, error) {
+	y := a.Y
+	err := s.Solve(h, a.X, a.Y, x, DrawerFunc(func(p num.Point) error {
+		y = p.Y
+		return nil
+	}))
+	return y, err
+}
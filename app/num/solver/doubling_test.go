@@ -0,0 +1,64 @@
+package solver
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateLocal(t *testing.T) {
+	grow := func(x, y float64) (float64, error) { return y, nil }
+	for _, tt := range []struct {
+		s     Interface
+		order int
+		step  float64
+		lte   func(y, h float64) float64 // the local error of the step from y
+	}{
+		{&Euler{F: grow}, 1, 0.01, func(y, h float64) float64 { return y * (math.Exp(h) - 1 - h) }},
+		{&ImprovedEuler{F: grow}, 2, 0.01, func(y, h float64) float64 { return y * (math.Exp(h) - 1 - h - h*h/2) }},
+		{&RungeKutta{F: grow}, 4, 0.1, func(y, h float64) float64 {
+			return y * (math.Exp(h) - 1 - h - h*h/2 - h*h*h/6 - h*h*h*h/24)
+		}},
+	} {
+		line, err := Collect(tt.s, tt.step, 0, 1, 1)
+		require.NoError(t, err)
+		est, err := EstimateLocal(tt.s, tt.order, line.Points)
+		require.NoError(t, err, tt.s.Name())
+		require.Len(t, est, len(line.Points))
+		assert.Equal(t, 0.0, est[0].Y, "the initial value is exact")
+		for i := 1; i < len(est); i++ {
+			assert.Equal(t, line.Points[i].X, est[i].X)
+			lte := tt.lte(line.Points[i-1].Y, line.Points[i].X-line.Points[i-1].X)
+			assert.InEpsilon(t, lte, est[i].Y, 0.05, "%s at x=%v", tt.s.Name(), est[i].X)
+		}
+	}
+
+	_, err := EstimateLocal(&Euler{F: benchF}, 0, nil)
+	assert.EqualError(t, err, "order of the method must be positive, got 0")
+}
+
+func TestStepDoubling(t *testing.T) {
+	s := &RungeKutta{F: benchF}
+	want, err := Collect(s, 0.3, -1.1, 1, 4.2)
+	require.NoError(t, err)
+	wantEst, err := EstimateLocal(s, 4, want.Points)
+	require.NoError(t, err)
+
+	errs := &Collector{}
+	sd := &StepDoubling{Solver: s, Order: 4, Errors: errs}
+	got, err := Collect(sd, 0.3, -1.1, 1, 4.2)
+	require.NoError(t, err)
+	assert.Equal(t, s.Name(), got.Name)
+	assert.Equal(t, want.Points, got.Points, "the solution is the same")
+	assert.Equal(t, wantEst, errs.Points, "estimates are drawn along with points, the last step is shortened")
+
+	// without the drawer of errors the solver is called as is
+	got, err = Collect(&StepDoubling{Solver: s, Order: 4}, 0.3, -1.1, 1, 4.2)
+	require.NoError(t, err)
+	assert.Equal(t, want.Points, got.Points)
+
+	_, err = Collect(&StepDoubling{Solver: s}, 0.3, -1.1, 1, 4.2)
+	assert.EqualError(t, err, "order of the method must be positive, got 0")
+}
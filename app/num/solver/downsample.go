@@ -0,0 +1,174 @@
+package solver
+
+import (
+	"math"
+
+	"github.com/Semior001/decompract/app/num"
+)
+
+// Downsampler is the drawer, that buffers points of the solution and passes about Target of them to the wrapped
+// one by Reduce, LTTB by default, so millions of points stay plottable with their peaks. Not finite points are
+// gaps, they are kept, and runs between them are reduced separately, to their shares of Target by lengths,
+// but at least to both ends of each run. Points are passed only by Flush, that must be called after the solution
+type Downsampler struct {
+	Target int                                      // number of points to pass, all points are passed, if it is less than 3
+	Reduce func(pts []num.Point, n int) []num.Point // reduces the finite run to n points, LTTB, if nil
+
+	next Drawer
+	buf  []num.Point
+}
+
+// NewDownsampler makes the downsampler of points, drawn to d, to about target points by LTTB
+func NewDownsampler(d Drawer, target int) *Downsampler {
+	return &Downsampler{Target: target, next: d}
+}
+
+// Draw buffers the point
+func (ds *Downsampler) Draw(p num.Point) error {
+	ds.buf = append(ds.buf, p)
+	return nil
+}
+
+// DrawBatch buffers points of the batch
+func (ds *Downsampler) DrawBatch(pts []num.Point) error {
+	ds.buf = append(ds.buf, pts...)
+	return nil
+}
+
+// Flush reduces buffered points and passes them to the wrapped drawer, the buffer is emptied,
+// so the downsampler is reused by the next solution
+func (ds *Downsampler) Flush() error {
+	pts := ds.buf
+	ds.buf = ds.buf[:0]
+	for _, p := range ds.reduce(pts) {
+		if err := ds.next.Draw(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reduce splits points into finite runs by gaps and reduces each run to its share of the target
+func (ds *Downsampler) reduce(pts []num.Point) []num.Point {
+	if ds.Target < 3 || len(pts) <= ds.Target {
+		return pts
+	}
+	reduceRun := ds.Reduce
+	if reduceRun == nil {
+		reduceRun = LTTB
+	}
+
+	finite := 0
+	for _, p := range pts {
+		if isFinite(p.X) && isFinite(p.Y) {
+			finite++
+		}
+	}
+	avail := ds.Target - (len(pts) - finite) // gaps are kept as they are
+	res := make([]num.Point, 0, ds.Target)
+	start := 0
+	for i := 0; i <= len(pts); i++ {
+		if i < len(pts) && isFinite(pts[i].X) && isFinite(pts[i].Y) {
+			continue
+		}
+		if run := pts[start:i]; len(run) > 0 {
+			share := int(math.Round(float64(avail) * float64(len(run)) / float64(finite)))
+			if share < 2 {
+				share = 2
+			}
+			res = append(res, reduceRun(run, share)...)
+		}
+		if i < len(pts) {
+			res = append(res, pts[i])
+		}
+		start = i + 1
+	}
+	return res
+}
+
+// LTTB reduces points to n by the largest triangle three buckets algorithm: interior points are split into
+// n-2 buckets, and the point of each bucket, that makes the largest triangle with the point, chosen from the
+// previous bucket, and the average of the next one, is kept, so peaks and the shape of the line are preserved.
+// The first and the last points are always kept, points are returned as they are, if there are less than n
+func LTTB(pts []num.Point, n int) []num.Point {
+	if n >= len(pts) {
+		return pts
+	}
+	if n < 3 {
+		return []num.Point{pts[0], pts[len(pts)-1]}
+	}
+
+	res := make([]num.Point, 0, n)
+	res = append(res, pts[0])
+	every := float64(len(pts)-2) / float64(n-2)
+	bucket := func(b int) (int, int) {
+		from, to := int(float64(b)*every)+1, int(float64(b+1)*every)+1
+		if to > len(pts)-1 {
+			to = len(pts) - 1
+		}
+		return from, to
+	}
+	a := pts[0]
+	for b := 0; b < n-2; b++ {
+		// the average of the next bucket, the last point is the next bucket of the last one
+		nf, nt := bucket(b + 1)
+		if b == n-3 {
+			nf, nt = len(pts)-1, len(pts)
+		}
+		var avg num.Point
+		for _, p := range pts[nf:nt] {
+			avg.X, avg.Y = avg.X+p.X, avg.Y+p.Y
+		}
+		avg.X, avg.Y = avg.X/float64(nt-nf), avg.Y/float64(nt-nf)
+
+		from, to := bucket(b)
+		best, bestArea := pts[from], -1.0
+		for _, p := range pts[from:to] {
+			area := math.Abs((a.X-avg.X)*(p.Y-a.Y) - (a.X-p.X)*(avg.Y-a.Y))
+			if area > bestArea {
+				best, bestArea = p, area
+			}
+		}
+		res = append(res, best)
+		a = best
+	}
+	return append(res, pts[len(pts)-1])
+}
+
+// MinMax reduces points to n by the min/max decimation: interior points are split into (n-2)/2 buckets,
+// and points with the least and the largest y of each bucket are kept in their order, so the envelope
+// of the line is preserved exactly. The first and the last points are always kept, points are returned
+// as they are, if there are less than n
+func MinMax(pts []num.Point, n int) []num.Point {
+	if n >= len(pts) {
+		return pts
+	}
+	buckets := (n - 2) / 2
+	res := make([]num.Point, 0, n)
+	res = append(res, pts[0])
+	interior := pts[1 : len(pts)-1]
+	for b := 0; b < buckets; b++ {
+		from, to := b*len(interior)/buckets, (b+1)*len(interior)/buckets
+		if from == to {
+			continue
+		}
+		lo, hi := from, from
+		for i := from; i < to; i++ {
+			if interior[i].Y < interior[lo].Y {
+				lo = i
+			}
+			if interior[i].Y > interior[hi].Y {
+				hi = i
+			}
+		}
+		switch {
+		case lo == hi:
+			res = append(res, interior[lo])
+		case lo < hi:
+			res = append(res, interior[lo], interior[hi])
+		default:
+			res = append(res, interior[hi], interior[lo])
+		}
+	}
+	return append(res, pts[len(pts)-1])
+}
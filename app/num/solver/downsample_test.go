@@ -0,0 +1,89 @@
+package solver
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// spiky returns n points of the sine with the single spike
+func spiky(n int) []num.Point {
+	pts := make([]num.Point, n)
+	for i := range pts {
+		x := float64(i) / float64(n-1)
+		pts[i] = num.Point{X: x, Y: math.Sin(2 * math.Pi * x)}
+	}
+	pts[n/3].Y = 10
+	return pts
+}
+
+func TestLTTB(t *testing.T) {
+	pts := spiky(100001)
+	res := LTTB(pts, 500)
+	require.Len(t, res, 500)
+	assert.Equal(t, pts[0], res[0])
+	assert.Equal(t, pts[len(pts)-1], res[499])
+	assert.Contains(t, res, pts[len(pts)/3], "the spike is kept")
+	for i := 1; i < len(res); i++ {
+		assert.Less(t, res[i-1].X, res[i].X)
+	}
+
+	assert.Equal(t, pts[:10], LTTB(pts[:10], 20))
+	assert.Equal(t, []num.Point{pts[0], pts[9]}, LTTB(pts[:10], 2))
+}
+
+func TestMinMax(t *testing.T) {
+	pts := spiky(100001)
+	res := MinMax(pts, 500)
+	assert.LessOrEqual(t, len(res), 500)
+	assert.Equal(t, pts[0], res[0])
+	assert.Equal(t, pts[len(pts)-1], res[len(res)-1])
+	assert.Contains(t, res, pts[len(pts)/3], "the spike is kept")
+	minY := math.Inf(1)
+	for i, p := range res {
+		minY = math.Min(minY, p.Y)
+		if i > 0 {
+			assert.Less(t, res[i-1].X, p.X)
+		}
+	}
+	assert.InDelta(t, -1, minY, 1e-8, "the envelope is kept")
+}
+
+func TestDownsampler(t *testing.T) {
+	c := &Collector{}
+	ds := NewDownsampler(c, 300)
+	require.NoError(t, (&RungeKutta{F: canonical}).Solve(1e-4, 0, 0.5, 1, ds))
+	assert.Empty(t, c.Points, "points are passed by flush")
+	require.NoError(t, ds.Flush())
+	assert.InDelta(t, 300, len(c.Points), 2)
+	assert.Equal(t, 0.0, c.Points[0].X)
+	assert.Equal(t, 1.0, c.Points[len(c.Points)-1].X)
+
+	// gaps are kept between runs, that are reduced separately
+	c = &Collector{}
+	ds = &Downsampler{Target: 100, Reduce: MinMax, next: c}
+	pts := spiky(10001)
+	pts[5000].Y = math.NaN()
+	require.NoError(t, ds.DrawBatch(pts))
+	require.NoError(t, ds.Flush())
+	assert.LessOrEqual(t, len(c.Points), 101)
+	gaps := 0
+	for i, p := range c.Points {
+		if math.IsNaN(p.Y) {
+			gaps++
+			assert.Equal(t, pts[4999], c.Points[i-1], "the run ends at the gap")
+			assert.Equal(t, pts[5001], c.Points[i+1], "the run starts after the gap")
+		}
+	}
+	assert.Equal(t, 1, gaps)
+
+	// short solutions are passed as they are
+	c = &Collector{}
+	ds = NewDownsampler(c, 300)
+	require.NoError(t, (&Euler{F: canonical}).Solve(0.1, 0, 0.5, 1, ds))
+	require.NoError(t, ds.Flush())
+	assert.Len(t, c.Points, 11)
+}
@@ -0,0 +1,388 @@
+package solver
+
+import (
+	"bufio"
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// batchSize is the number of points, that solvers buffer before passing them to the BatchDrawer
+const batchSize = 1024
+
+// DrawerOf receives the points of the solution over the numeric type T one by one, as soon as they are calculated
+type DrawerOf[T num.Float] interface {
+	Draw(p num.PointOf[T]) error
+}
+
+// Drawer receives the points of the solution one by one, as soon as they are calculated
+type Drawer = DrawerOf[float64]
+
+// StepDrawerOf is a DrawerOf that also wants to know the index of the point
+// and the step size, that led to it (zero for the initial point)
+type StepDrawerOf[T num.Float] interface {
+	DrawerOf[T]
+	DrawStep(i int, h float64, p num.PointOf[T]) error
+}
+
+// StepDrawer is the StepDrawerOf float64 points
+type StepDrawer = StepDrawerOf[float64]
+
+// BatchDrawerOf is a DrawerOf that receives points in batches, in the order of the solution, solvers detect it
+// and pass points by DrawBatch instead of Draw, the slice is reused by the solver after the call,
+// so the drawer must not retain it. The drawer, that fails on the point of the batch, must not draw
+// the points after it, the solution is stopped with its error, located at the point by BatchError
+type BatchDrawerOf[T num.Float] interface {
+	DrawerOf[T]
+	DrawBatch(pts []num.PointOf[T]) error
+}
+
+// BatchDrawer is the BatchDrawerOf float64 points
+type BatchDrawer = BatchDrawerOf[float64]
+
+// BatchError is the failure of the BatchDrawer on the particular point of the batch, drawers, that know
+// the failed point, return it, so the failure is located at that point instead of the first point of the batch
+type BatchError struct {
+	Index int // index of the failed point in the batch
+	Err   error
+}
+
+// Error returns the message of the cause, the index locates the failure, but doesn't describe it
+func (e *BatchError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the error of the drawer
+func (e *BatchError) Unwrap() error { return e.Err }
+
+// Meta describes the internals of the solver at the point, fields, unknown to the solver, are zero
+type Meta struct {
+	Step     int       // index of the point, the index of the next accepted point for the rejected step
+	H        float64   // the step size, that led to the point, zero for the initial point
+	Order    int       // order of the formula of the step, e.g. of the variable-order method, zero if unknown
+	LocalErr float64   // estimate of the local error of the step, zero if not estimated
+	Stages   []float64 // values of f at stages of the step, the slice is reused by the solver after the call
+	Rejected bool      // the step is rejected by the error control, so the point is not the point of the solution
+}
+
+// MetaDrawerOf is a DrawerOf that also wants the internals of the solver, solvers detect it and pass points
+// by DrawMeta instead of Draw, DrawStep and DrawBatch, adaptive solvers pass rejected steps with the flag
+// Rejected set as well, they are not points of the solution, so they don't advance it. Solvers, that know
+// nothing of their internals, pass the index and the step of the point only
+type MetaDrawerOf[T num.Float] interface {
+	DrawerOf[T]
+	DrawMeta(p num.PointOf[T], m Meta) error
+}
+
+// MetaDrawer is the MetaDrawerOf float64 points
+type MetaDrawer = MetaDrawerOf[float64]
+
+// Annotation marks the point of the solution with the note, e.g. the switch of the method
+type Annotation struct {
+	X    float64 `json:"x"`
+	Text string  `json:"text"`
+}
+
+// Annotator is a Drawer that also receives annotations of the solution, solvers find it anywhere in the chain
+// of wrapped drawers and pass annotations after the points up to their x are drawn
+type Annotator interface {
+	Drawer
+	Annotate(a Annotation) error
+}
+
+// MetaDrawerFunc is an adapter to allow the use of ordinary functions as MetaDrawer, Draw passes
+// the point without the metadata
+type MetaDrawerFunc func(p num.Point, m Meta) error
+
+// Draw calls f(p, Meta{})
+func (f MetaDrawerFunc) Draw(p num.Point) error { return f(p, Meta{}) }
+
+// DrawMeta calls f(p, m)
+func (f MetaDrawerFunc) DrawMeta(p num.Point, m Meta) error { return f(p, m) }
+
+// DrawerFuncOf is an adapter to allow the use of ordinary functions as DrawerOf
+type DrawerFuncOf[T num.Float] func(p num.PointOf[T]) error
+
+// Draw calls f(p)
+func (f DrawerFuncOf[T]) Draw(p num.PointOf[T]) error { return f(p) }
+
+// DrawerFunc is an adapter to allow the use of ordinary functions as Drawer
+type DrawerFunc = DrawerFuncOf[float64]
+
+// ErrPointsCount is returned by drawers, that expect the number of points, if the solution drew the other number
+var ErrPointsCount = errors.New("number of drawn points differs from the expected one")
+
+// maxPrealloc is the maximal number of points, preallocated by drawers
+const maxPrealloc = 1 << 20
+
+// Collector stores all drawn points in memory
+type Collector struct {
+	Points   []num.Point
+	expected int
+}
+
+// Expect preallocates points of the solution with n points, e.g. given by StepsCount,
+// and makes Check to verify, that the solution drew exactly n points
+func (c *Collector) Expect(n int) {
+	c.expected = n
+	if n > cap(c.Points)-len(c.Points) {
+		pts := make([]num.Point, len(c.Points), len(c.Points)+min(n, maxPrealloc))
+		copy(pts, c.Points)
+		c.Points = pts
+	}
+}
+
+// Check returns ErrPointsCount, if the number of collected points differs from the expected one
+func (c *Collector) Check() error {
+	return checkCount(len(c.Points), c.expected)
+}
+
+// Draw appends the point to the list of collected points
+func (c *Collector) Draw(p num.Point) error {
+	c.Points = append(c.Points, p)
+	return nil
+}
+
+// DrawBatch appends the points to the list of collected points
+func (c *Collector) DrawBatch(pts []num.Point) error {
+	c.Points = append(c.Points, pts...)
+	return nil
+}
+
+// CSVDrawer writes points as rows "x,y" of csv, numbers are written in the shortest representation
+// without the loss of precision, unless Prec is set, rows are buffered, so Flush must be called
+// after the solution, the header, if it is set, is written before the first row, or by Flush
+// of the empty solution
+type CSVDrawer struct {
+	Header []string // names of columns, no header if empty
+	Prec   int      // significant digits of numbers, the shortest exact representation if zero
+	Delim  byte     // delimiter of columns, the comma if zero
+
+	w        *bufio.Writer
+	buf      []byte
+	rows     int
+	expected int
+	started  bool // the header is written
+}
+
+// maxRowLen is the length of the longest row of the csv, two shortest representations of floats with the comma
+const maxRowLen = 2*24 + 2
+
+// Expect preallocates rows of the batch of the solution with n points, e.g. given by StepsCount,
+// and makes Flush to verify, that the solution drew exactly n points
+func (c *CSVDrawer) Expect(n int) {
+	c.expected = n
+	if rows := min(n, batchSize); cap(c.buf) < rows*maxRowLen {
+		c.buf = make([]byte, 0, rows*maxRowLen)
+	}
+}
+
+// NewCSVDrawer makes CSVDrawer, that writes to the writer
+func NewCSVDrawer(w io.Writer) *CSVDrawer { return &CSVDrawer{w: bufio.NewWriter(w)} }
+
+// Draw writes the point as the row
+func (c *CSVDrawer) Draw(p num.Point) error { return c.DrawBatch([]num.Point{p}) }
+
+// DrawBatch writes the points as rows
+func (c *CSVDrawer) DrawBatch(pts []num.Point) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	prec, delim := c.Prec, c.Delim
+	if prec == 0 {
+		prec = -1
+	}
+	if delim == 0 {
+		delim = ','
+	}
+	c.buf = c.buf[:0]
+	for _, p := range pts {
+		c.buf = strconv.AppendFloat(c.buf, p.X, 'g', prec, 64)
+		c.buf = append(c.buf, delim)
+		c.buf = strconv.AppendFloat(c.buf, p.Y, 'g', prec, 64)
+		c.buf = append(c.buf, '\n')
+	}
+	if _, err := c.w.Write(c.buf); err != nil {
+		return errors.Wrap(err, "failed to write points")
+	}
+	c.rows += len(pts)
+	return nil
+}
+
+// writeHeader writes the header once, names are quoted as csv requires
+func (c *CSVDrawer) writeHeader() error {
+	if c.started || len(c.Header) == 0 {
+		return nil
+	}
+	c.started = true
+	cw := csv.NewWriter(c.w)
+	if c.Delim != 0 {
+		cw.Comma = rune(c.Delim)
+	}
+	if err := cw.Write(c.Header); err != nil {
+		return errors.Wrap(err, "failed to write header")
+	}
+	cw.Flush()
+	return errors.Wrap(cw.Error(), "failed to write header")
+}
+
+// Flush writes the buffered rows to the underlying writer, it returns ErrPointsCount, if the number
+// of rows differs from the expected one
+func (c *CSVDrawer) Flush() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	if err := c.w.Flush(); err != nil {
+		return errors.Wrap(err, "failed to flush points")
+	}
+	return checkCount(c.rows, c.expected)
+}
+
+// checkCount returns ErrPointsCount, if the number of points differs from the expected one,
+// the count is not checked, if nothing is expected
+func checkCount(got, expected int) error {
+	if expected > 0 && got != expected {
+		return errors.Wrapf(ErrPointsCount, "got %d points, expected %d", got, expected)
+	}
+	return nil
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// sink passes points of the solution to the drawer, one by one, or in batches, if the drawer is
+// the BatchDrawer, along with the metadata, if it is the MetaDrawer, solvers must flush the sink before returning, so the buffered points are drawn,
+// failures of the drawer are returned as StepError of the method. The sink is the backstop against
+// the solution, that doesn't advance x in the direction of its step, it fails with num.ErrStepTooSmall,
+// as soon as x is repeated
+type sink[T num.Float] struct {
+	method string
+	draw   func(i int, h float64, p num.PointOf[T]) error
+	batch  BatchDrawerOf[T]
+	meta   MetaDrawerOf[T]
+	buf    []num.PointOf[T]
+	first  int  // index of the first buffered point
+	lastX  T    // x of the previous point
+	drawn  bool // the previous point is put, the resumed solution starts past the first node
+}
+
+// sinkOf makes the sink of the method for the drawer, the step and the meta drawers receive points one by one,
+// as they want the step data
+func sinkOf[T num.Float](method string, d DrawerOf[T]) sink[T] {
+	if md, ok := d.(MetaDrawerOf[T]); ok {
+		return sink[T]{method: method, meta: md}
+	}
+	if bd, ok := d.(BatchDrawerOf[T]); ok {
+		if _, isStep := d.(StepDrawerOf[T]); !isStep {
+			return sink[T]{method: method, batch: bd, buf: make([]num.PointOf[T], 0, batchSize)}
+		}
+	}
+	return sink[T]{method: method, draw: drawerOf(d)}
+}
+
+// put passes the i-th point, that is calculated with the step h, to the drawer, or buffers it
+func (s *sink[T]) put(i int, h float64, p num.PointOf[T]) error {
+	return s.putMeta(p, Meta{Step: i, H: h})
+}
+
+// putMeta passes the point with the metadata of the solver, the index and the step of the point are taken from it
+func (s *sink[T]) putMeta(p num.PointOf[T], m Meta) error {
+	i, h := m.Step, m.H
+	if s.drawn && !(h >= 0 && p.X > s.lastX || h < 0 && p.X < s.lastX) {
+		err := errors.Wrapf(num.ErrStepTooSmall, "x is not advanced from %v by the step %v", s.lastX, h)
+		return s.fail(&StepError{Method: s.method, Step: i, Stage: "advance", X: float64(p.X), Y: float64(p.Y), Err: err})
+	}
+	s.lastX, s.drawn = p.X, true
+	if s.meta != nil {
+		m.Rejected = false
+		if err := s.meta.DrawMeta(p, m); err != nil {
+			return &StepError{Method: s.method, Step: i, Stage: "draw", X: float64(p.X), Y: float64(p.Y), Err: err}
+		}
+		return nil
+	}
+	if s.batch == nil {
+		if err := s.draw(i, h, p); err != nil {
+			return &StepError{Method: s.method, Step: i, Stage: "draw", X: float64(p.X), Y: float64(p.Y), Err: err}
+		}
+		return nil
+	}
+	if len(s.buf) == 0 {
+		s.first = i
+	}
+	s.buf = append(s.buf, p)
+	if len(s.buf) == cap(s.buf) {
+		return s.flush()
+	}
+	return nil
+}
+
+// reject passes the point of the rejected step to the meta drawer, nothing is passed to the rest of drawers
+func (s *sink[T]) reject(p num.PointOf[T], m Meta) error {
+	if s.meta == nil {
+		return nil
+	}
+	m.Rejected = true
+	if err := s.meta.DrawMeta(p, m); err != nil {
+		return &StepError{Method: s.method, Step: m.Step, Stage: "draw rejected", X: float64(p.X), Y: float64(p.Y), Err: err}
+	}
+	return nil
+}
+
+// flush passes the buffered points to the batch drawer, the failure of the batch is located at the point,
+// reported by BatchError, or at the first point of the batch, if the failed point is not known
+func (s *sink[T]) flush() error {
+	if len(s.buf) == 0 {
+		return nil
+	}
+	err := s.batch.DrawBatch(s.buf)
+	if err == nil {
+		s.buf = s.buf[:0]
+		return nil
+	}
+	i := 0
+	var be *BatchError
+	if errors.As(err, &be) && be.Index >= 0 && be.Index < len(s.buf) {
+		i = be.Index
+	}
+	p := s.buf[i]
+	s.buf = s.buf[:0]
+	return &StepError{Method: s.method, Step: s.first + i, Stage: "draw batch", X: float64(p.X), Y: float64(p.Y), Err: err}
+}
+
+// annotate passes the annotation to the annotator after the buffered points, the annotator is nil,
+// if the drawer doesn't accept annotations
+func (s *sink[T]) annotate(ann Annotator, a Annotation) error {
+	if ann == nil {
+		return nil
+	}
+	if err := s.flush(); err != nil {
+		return err
+	}
+	if err := ann.Annotate(a); err != nil {
+		return errors.Wrapf(err, "failed to annotate %v", a.X)
+	}
+	return nil
+}
+
+// fail flushes the buffered points, calculated before the failure, and returns the error,
+// or the error of the drawer, as it failed on the earlier point
+func (s *sink[T]) fail(err error) error {
+	if ferr := s.flush(); ferr != nil {
+		return ferr
+	}
+	return err
+}
+
+// drawerOf returns the function, that passes the point to the drawer, including the step data,
+// if the drawer accepts it, the drawer is inspected once, not on each point
+func drawerOf[T num.Float](d DrawerOf[T]) func(i int, h float64, p num.PointOf[T]) error {
+	if sd, ok := d.(StepDrawerOf[T]); ok {
+		return sd.DrawStep
+	}
+	return func(_ int, _ float64, p num.PointOf[T]) error { return d.Draw(p) }
+}
@@ -0,0 +1,279 @@
+package solver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingBatch draws points into the collector by batches and fails on the point with the index,
+// the failed point is reported by BatchError, if located is set
+type failingBatch struct {
+	Collector
+	failAt  int
+	located bool
+	batches []int
+}
+
+func (f *failingBatch) DrawBatch(pts []num.Point) error {
+	f.batches = append(f.batches, len(pts))
+	for i, p := range pts {
+		if len(f.Points) == f.failAt {
+			if f.located {
+				return &BatchError{Index: i, Err: errors.New("drawer failed")}
+			}
+			return errors.New("drawer failed")
+		}
+		f.Points = append(f.Points, p)
+	}
+	return nil
+}
+
+// metaCollector collects accepted points and metadata of all points, including rejected ones
+type metaCollector struct {
+	Collector
+	metas    []Meta
+	rejected []num.Point
+}
+
+func (m *metaCollector) DrawMeta(p num.Point, meta Meta) error {
+	meta.Stages = append([]float64(nil), meta.Stages...)
+	m.metas = append(m.metas, meta)
+	if meta.Rejected {
+		m.rejected = append(m.rejected, p)
+		return nil
+	}
+	return m.Draw(p)
+}
+
+// accepted returns metadata of accepted points
+func (m *metaCollector) accepted() []Meta {
+	var res []Meta
+	for _, meta := range m.metas {
+		if !meta.Rejected {
+			res = append(res, meta)
+		}
+	}
+	return res
+}
+
+// batchMetaCollector is the meta drawer, that accepts batches too
+type batchMetaCollector struct {
+	metaCollector
+}
+
+func (b *batchMetaCollector) DrawBatch([]num.Point) error {
+	return errors.New("batches are not expected")
+}
+
+func TestMetaDrawer(t *testing.T) {
+	for _, s := range batchSolvers(2) {
+		d := &batchMetaCollector{}
+		require.NoError(t, s.Solve(0.25, 0, 1, 1, d), "meta drawer is preferred to the batch one by %s", s.Name())
+		want, err := Collect(s, 0.25, 0, 1, 1)
+		require.NoError(t, err)
+		assert.Equal(t, want.Points, d.Points, s.Name())
+		assert.Equal(t, []Meta{{Step: 0}, {Step: 1, H: 0.25}, {Step: 2, H: 0.25}, {Step: 3, H: 0.25}, {Step: 4, H: 0.25}},
+			d.metas, "grid solvers know only steps, %s", s.Name())
+	}
+
+	d := &metaCollector{}
+	require.NoError(t, (&Euler{F: benchF}).Solve(0.5, 0, 1, 1, WithContext(context.Background(), d)))
+	assert.Len(t, d.metas, 3, "wrapped meta drawer receives metadata")
+
+	err := (&Euler{F: benchF}).Solve(0.5, 0, 1, 1, MetaDrawerFunc(func(p num.Point, m Meta) error {
+		if m.Step == 1 {
+			return errors.New("drawer failed")
+		}
+		return nil
+	}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed at step 1, draw at x=0.5")
+
+	var got []Meta
+	f := MetaDrawerFunc(func(_ num.Point, m Meta) error {
+		got = append(got, m)
+		return nil
+	})
+	require.NoError(t, f.Draw(num.Point{X: 1, Y: 2}))
+	require.NoError(t, f.DrawMeta(num.Point{X: 1, Y: 2}, Meta{Step: 3, Order: 2}))
+	assert.Equal(t, []Meta{{}, {Step: 3, Order: 2}}, got, "Draw passes the point without the metadata")
+}
+
+// batchSolvers are the solvers of y' = x^2 - 2y with f, that fails after x exceeds failX
+func batchSolvers(failX float64) []Interface {
+	f := func(x, y float64) (float64, error) {
+		if x > failX {
+			return 0, errors.New("f failed")
+		}
+		return benchF(x, y)
+	}
+	return []Interface{&Euler{F: f}, &ImprovedEuler{F: f}, &RungeKutta{F: f}, &Exact{
+		F: func(x, c float64) (float64, error) { return f(x, c) },
+		C: func(float64, float64) (float64, error) { return 1, nil },
+	}}
+}
+
+func TestBatchDrawer_Order(t *testing.T) {
+	// 3000 steps are two full batches and the partial one
+	const steps = 3000
+	for _, s := range batchSolvers(2) {
+		batched := &failingBatch{failAt: -1}
+		require.NoError(t, s.Solve(1.0/steps, 0, 1, 1, batched))
+		perPoint := &Collector{}
+		require.NoError(t, s.Solve(1.0/steps, 0, 1, 1, DrawerFunc(perPoint.Draw)))
+
+		assert.Equal(t, perPoint.Points, batched.Points, "points of %s are drawn in order", s.Name())
+		require.Len(t, batched.batches, 3, s.Name())
+		assert.Equal(t, []int{batchSize, batchSize, len(perPoint.Points) - 2*batchSize}, batched.batches, s.Name())
+	}
+}
+
+func TestBatchDrawer_Errors(t *testing.T) {
+	const steps = 3000
+	t.Run("drawer", func(t *testing.T) {
+		for _, s := range batchSolvers(2) {
+			d := &failingBatch{failAt: 1500}
+			err := s.Solve(1.0/steps, 0, 1, 1, d)
+			require.Error(t, err, s.Name())
+			assert.Contains(t, err.Error(), "failed at step 1024, draw batch at x=0.3413", s.Name())
+			var se *StepError
+			require.True(t, errors.As(err, &se), s.Name())
+			assert.Equal(t, s.Name(), se.Method)
+			assert.Equal(t, "drawer failed", se.Err.Error(), "the failed batch is located at its first point")
+			assert.Len(t, d.Points, 1500, "points after the failed one are not drawn by %s", s.Name())
+			assert.Equal(t, []int{batchSize, batchSize}, d.batches, "%s stops at the failed batch", s.Name())
+		}
+	})
+
+	t.Run("located", func(t *testing.T) {
+		for _, s := range batchSolvers(2) {
+			d := &failingBatch{failAt: 1500, located: true}
+			err := s.Solve(1.0/steps, 0, 1, 1, d)
+			require.Error(t, err, s.Name())
+			assert.Contains(t, err.Error(), "failed at step 1500, draw batch at x=0.5000", s.Name())
+			var se *StepError
+			require.True(t, errors.As(err, &se), s.Name())
+			assert.InDelta(t, d.Points[len(d.Points)-1].X+1.0/steps, se.X, 1e-12, "the failed point of %s is located", s.Name())
+			assert.Equal(t, "drawer failed", se.Err.Error())
+			assert.Len(t, d.Points, 1500, s.Name())
+		}
+	})
+
+	t.Run("solver", func(t *testing.T) {
+		for _, s := range batchSolvers(0.5) {
+			d := &failingBatch{failAt: -1}
+			err := s.Solve(1.0/steps, 0, 1, 1, d)
+			require.Error(t, err, s.Name())
+			assert.Contains(t, err.Error(), "f failed", s.Name())
+
+			perPoint := &Collector{}
+			require.Error(t, s.Solve(1.0/steps, 0, 1, 1, DrawerFunc(perPoint.Draw)))
+			assert.Equal(t, perPoint.Points, d.Points, "points before the failure are drawn by %s", s.Name())
+			assert.Greater(t, len(d.Points), batchSize, s.Name())
+		}
+	})
+}
+
+func TestCSVDrawer(t *testing.T) {
+	buf := &bytes.Buffer{}
+	d := NewCSVDrawer(buf)
+	require.NoError(t, (&Euler{F: benchF}).Solve(0.25, 0, 1, 1, d))
+	assert.Empty(t, buf.String(), "rows are buffered")
+	require.NoError(t, d.Flush())
+	assert.Equal(t, "0,1\n0.25,0.5\n0.5,0.265625\n0.75,0.1953125\n1,0.23828125\n", buf.String())
+
+	require.NoError(t, d.Draw(num.Point{X: 1.5, Y: -2}))
+	require.NoError(t, d.Flush())
+	assert.True(t, strings.HasSuffix(buf.String(), "\n1.5,-2\n"))
+}
+
+func TestCSVDrawer_Format(t *testing.T) {
+	buf := &bytes.Buffer{}
+	d := NewCSVDrawer(buf)
+	d.Header, d.Prec, d.Delim = []string{"x", "y; Euler's"}, 3, ';'
+	require.NoError(t, (&Euler{F: benchF}).Solve(0.25, 0, 1, 1, d))
+	require.NoError(t, d.Flush())
+	assert.Equal(t, "x;\"y; Euler's\"\n0;1\n0.25;0.5\n0.5;0.266\n0.75;0.195\n1;0.238\n", buf.String())
+
+	// the header is written once, even if nothing is drawn
+	buf.Reset()
+	d = NewCSVDrawer(buf)
+	d.Header = []string{"x", "y"}
+	require.NoError(t, d.Flush())
+	require.NoError(t, d.Draw(num.Point{X: 1, Y: 2}))
+	require.NoError(t, d.Flush())
+	assert.Equal(t, "x,y\n1,2\n", buf.String())
+}
+
+func TestCollector_Expect(t *testing.T) {
+	c := &Collector{}
+	n, err := StepsCount(0.1, 0, 1)
+	require.NoError(t, err)
+	c.Expect(n)
+	assert.Equal(t, 11, cap(c.Points), "points are preallocated")
+	require.NoError(t, (&Euler{F: benchF}).Solve(0.1, 0, 1, 1, c))
+	assert.NoError(t, c.Check())
+	assert.Equal(t, 11, cap(c.Points), "the collector doesn't grow")
+
+	c = &Collector{}
+	c.Expect(12)
+	require.NoError(t, (&Euler{F: benchF}).Solve(0.1, 0, 1, 1, c))
+	err = c.Check()
+	assert.True(t, errors.Is(err, ErrPointsCount))
+	assert.EqualError(t, err, "got 11 points, expected 12: number of drawn points differs from the expected one")
+
+	assert.NoError(t, (&Collector{}).Check(), "nothing is expected")
+}
+
+func TestCSVDrawer_Expect(t *testing.T) {
+	buf := &bytes.Buffer{}
+	d := NewCSVDrawer(buf)
+	d.Expect(5)
+	require.NoError(t, (&Euler{F: benchF}).Solve(0.25, 0, 1, 1, d))
+	require.NoError(t, d.Flush())
+	assert.Equal(t, 5, strings.Count(buf.String(), "\n"))
+
+	d = NewCSVDrawer(io.Discard)
+	d.Expect(4)
+	require.NoError(t, (&Euler{F: benchF}).Solve(0.25, 0, 1, 1, d))
+	assert.True(t, errors.Is(d.Flush(), ErrPointsCount))
+}
+
+// benchPoints is the number of steps of solutions in benchmarks of drawers, that keep all points
+const benchPoints = 100000
+
+func BenchmarkBatchDrawer(b *testing.B) {
+	for _, bb := range []struct {
+		name string
+		d    func() Drawer
+	}{
+		{"collector/batch", func() Drawer { return &Collector{Points: make([]num.Point, 0, benchPoints+1)} }},
+		{"collector/point", func() Drawer {
+			c := &Collector{Points: make([]num.Point, 0, benchPoints+1)}
+			return DrawerFunc(c.Draw)
+		}},
+		{"csv/batch", func() Drawer { return NewCSVDrawer(io.Discard) }},
+		{"csv/point", func() Drawer { return DrawerFunc(NewCSVDrawer(io.Discard).Draw) }},
+	} {
+		b.Run(bb.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				d := bb.d()
+				b.StartTimer()
+				if err := (&Euler{F: benchF}).Solve(1.0/benchPoints, 0, 1, 1, d); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -1,44 +1,70 @@
 package solver
 
-import (
-	"github.com/Semior001/decompract/app/num"
-	log "github.com/go-pkgz/lgr"
-	"github.com/pkg/errors"
-)
+import "github.com/Semior001/decompract/app/num"
 
-// Euler method for solving initial value problem for differential equations
-type Euler struct {
-	F func(x, y float64) (float64, error) // calculator for f(x,y) = y'
+// EulerOf is Euler's method for solving initial value problem for differential equations
+// in the numeric type T, nodes of the grid are calculated in float64 and rounded to T
+type EulerOf[T num.Float] struct {
+	F FuncOf[T] // calculator for f(x,y) = y'
 }
 
+// Euler method for solving initial value problem for differential equations
+type Euler = EulerOf[float64]
+
+// Name returns the name of the method
+func (e *EulerOf[T]) Name() string { return "Euler's method" }
+
 // Solve the initial value problem with Euler method
-func (e *Euler) Solve(stepSize, x0, y0, xEnd float64) (num.Line, error) {
-	x := x0
-	y := y0
-	var f float64
-	var err error
+func (e *EulerOf[T]) Solve(stepSize, x0, y0, xEnd T, d DrawerOf[T]) error {
+	g, err := gridOf(stepSize, x0, xEnd)
+	if err != nil {
+		return err
+	}
 
-	log.Printf("[DEBUG] starting solving the equation with Euler's "+
+	logger(d).Logf("[DEBUG] starting solving the equation with Euler's "+
 		"method with stepsz = %.4f, x0 = %.4f, y0 = %.4f, xend = %.4f", stepSize, x0, y0, xEnd)
 
-	var pts []num.Point
-	for x <= xEnd {
-		pts = append(pts, num.Point{X: x, Y: y})
+	return e.solve(g, 0, y0, d)
+}
+
+// Resume continues the solution from the checkpoint up to xEnd
+func (e *EulerOf[T]) Resume(cp Checkpoint, xEnd float64, d DrawerOf[T]) error {
+	g, err := cp.grid(xEnd)
+	if err != nil {
+		return err
+	}
+	return e.solve(g, cp.Node, T(cp.Y), d)
+}
+
+// solve solves the problem on the grid from the node, where the solution is y
+func (e *EulerOf[T]) solve(g Grid, from int, y T, d DrawerOf[T]) error {
+	var f T
+	var err error
+
+	out := sinkOf(e.Name(), d)
+	for i := from; i <= g.N; i++ {
+		x := T(g.X(i))
+		if err = out.put(i, g.Step(i), num.PointOf[T]{X: x, Y: y}); err != nil {
+			return err
+		}
+		if i == g.N {
+			break // the point is the last one, so the next step is not calculated
+		}
+		h := T(g.Step(i + 1)) // the last step might be shortened to end at xEnd
 
 		if f, err = e.F(x, y); err != nil {
-			return num.Line{}, errors.Wrapf(err, "failed to calculate f for x=%.4f y=%.4f", x, y)
+			return out.fail(&StepError{Method: e.Name(), Step: i, Stage: "f", X: float64(x), Y: float64(y), Err: err})
 		}
 
 		// calculating the next x, y values
-		y = e.calculateY(y, stepSize*f)
-		x += stepSize
+		y = e.calculateY(y, h*f)
 	}
 
-	return num.Line{Name: "Euler's method", Points: pts}, nil
+	return out.flush()
 }
 
 // calculate y value as
 // y_{i+1} = y_i + h * f(x_i, y_i)
-func (e *Euler) calculateY(yi, hf float64) float64 {
+func (e *EulerOf[T]) calculateY(yi, hf T) T {
 	return yi + hf
 }
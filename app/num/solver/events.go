@@ -0,0 +1,114 @@
+package solver
+
+import (
+	"math"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// errEventStop stops the solution at the terminal event, it doesn't leave Events.Solve
+var errEventStop = errors.New("solution is stopped at the event")
+
+// Events wraps the solver to detect events, points, where the event function g(x, y) changes its sign, e.g. y = 0
+// with g = y or y = h(x) with g = y - h(x). The sign is checked at nodes, the event between nodes is located
+// by bisection of the step, each y inside the step is solved by the solver from the previous node with the single
+// step, so the solver must be safe to solve again, while it draws, as one-step methods are
+type Events struct {
+	Solver   Interface
+	G        func(x, y float64) float64 // event function, the initial point is not checked
+	Tol      float64                    // width of the bracket of the located event, 1e-9 of the step, if zero
+	Terminal bool                       // stop the solution at the first event, the event is the last drawn point
+	Events   Drawer                     // receives located events, if set
+}
+
+// WithEvent wraps the solver to detect events of g, events are not terminal and not drawn, until it is set
+func WithEvent(s Interface, g func(x, y float64) float64) *Events {
+	return &Events{Solver: s, G: g}
+}
+
+// Name returns the name of the method
+func (e *Events) Name() string { return e.Solver.Name() }
+
+// Solve solves the problem by the solver and locates events between its nodes, the terminal event ends
+// the solution without the error
+func (e *Events) Solve(stepSize, x0, y0, xEnd float64, d Drawer) error {
+	if err := checkArgs(stepSize, x0, xEnd); err != nil {
+		return err
+	}
+	if e.G == nil {
+		return errors.New("event function must be set")
+	}
+	tol := e.Tol
+	if tol <= 0 {
+		tol = stepSize * 1e-9
+	}
+
+	var prev num.Point
+	var gPrev float64
+	started := false
+	err := e.Solver.Solve(stepSize, x0, y0, xEnd, wrap(d, func(c call) error {
+		g := e.G(c.p.X, c.p.Y)
+		if !started {
+			prev, gPrev, started = c.p, g, true
+			return c.do()
+		}
+		if !(g == 0 || gPrev*g < 0) {
+			prev, gPrev = c.p, g
+			return c.do()
+		}
+
+		ev := c.p
+		if g != 0 {
+			var err error
+			if ev, err = e.locate(prev, gPrev, c.p, tol); err != nil {
+				return err
+			}
+		}
+		if e.Events != nil {
+			if err := e.Events.Draw(ev); err != nil {
+				return errors.Wrapf(err, "failed to draw the event at x=%v", ev.X)
+			}
+		}
+		if e.Terminal {
+			c.h, c.p = ev.X-prev.X, ev
+			if err := c.do(); err != nil {
+				return err
+			}
+			return errEventStop
+		}
+		prev, gPrev = c.p, g
+		return c.do()
+	}))
+	if errors.Is(err, errEventStop) {
+		return nil
+	}
+	return err
+}
+
+// locate bisects the step from a to b, where g changes its sign from ga, until it is narrower than tol,
+// the event is the end of the bracket, where the sign is changed, the step goes backward, if b precedes a
+func (e *Events) locate(a num.Point, ga float64, b num.Point, tol float64) (num.Point, error) {
+	lo, hi := a.X, b
+	for math.Abs(hi.X-lo) > tol {
+		x := lo + (hi.X-lo)/2
+		if x == lo || x == hi.X {
+			break // the bracket can't be split in floating point
+		}
+		line, err := Collect(e.Solver, math.Abs(x-a.X), a.X, a.Y, x)
+		if err != nil {
+			return num.Point{}, errors.Wrapf(err, "failed to locate the event between %v and %v", a.X, b.X)
+		}
+		p := line.Points[len(line.Points)-1]
+		g := e.G(p.X, p.Y)
+		if g == 0 {
+			return p, nil
+		}
+		if math.Signbit(g) == math.Signbit(ga) {
+			lo = p.X
+			continue
+		}
+		hi = p
+	}
+	return hi, nil
+}
@@ -0,0 +1,65 @@
+package solver
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvents_Solve(t *testing.T) {
+	// y = sin(x) crosses zero at multiples of pi
+	cosF := func(x, _ float64) (float64, error) { return math.Cos(x), nil }
+	events := &Collector{}
+	e := WithEvent(&RungeKutta{F: cosF}, func(_, y float64) float64 { return y })
+	e.Events = events
+	assert.Equal(t, "Runge-Kutta's method", e.Name())
+	line, err := Collect(e, 0.1, 0, 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, line.Points, 101, "events don't change the solution")
+	require.Len(t, events.Points, 3, "the initial point is not checked")
+	for i, p := range events.Points {
+		assert.InDelta(t, float64(i+1)*math.Pi, p.X, 1e-6)
+		assert.InDelta(t, 0, p.Y, 1e-6)
+	}
+
+	// y = x - 0.55 reaches 0.2 at x = 0.75, the terminal event is the last point
+	e = &Events{Solver: &Euler{F: func(_, _ float64) (float64, error) { return 1, nil }},
+		G: func(x, y float64) float64 { return y - 0.2 }, Terminal: true, Tol: 1e-12}
+	line, err = Collect(e, 0.1, 0, -0.55, 2)
+	require.NoError(t, err)
+	require.Len(t, line.Points, 9)
+	last := line.Points[8]
+	assert.InDelta(t, 0.75, last.X, 1e-11)
+	assert.InDelta(t, 0.2, last.Y, 1e-11)
+	assert.InDelta(t, 0.7, line.Points[7].X, 1e-12)
+
+	// the event at the node is not bisected
+	e.G = func(x, _ float64) float64 { return x - 0.5 }
+	line, err = Collect(e, 0.25, 0, 0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, num.Point{X: 0.5, Y: 0.5}, line.Points[len(line.Points)-1])
+
+	// backward from x = 2, y = x - 0.55 reaches 0.2 at x = 0.75 as well
+	e.G = func(x, y float64) float64 { return y - 0.2 }
+	line, err = Collect(e, 0.1, 2, 1.45, 0)
+	require.NoError(t, err)
+	require.Len(t, line.Points, 14)
+	last = line.Points[13]
+	assert.InDelta(t, 0.75, last.X, 1e-11)
+	assert.InDelta(t, 0.2, last.Y, 1e-11)
+	assert.InDelta(t, 0.8, line.Points[12].X, 1e-12)
+}
+
+func TestEvents_Errors(t *testing.T) {
+	err := (&Events{Solver: &Euler{F: canonical}}).Solve(0.1, 0, 1, 1, &Collector{})
+	assert.EqualError(t, err, "event function must be set")
+
+	e := WithEvent(&Euler{F: func(_, _ float64) (float64, error) { return 1, nil }}, func(_, y float64) float64 { return y })
+	e.Events = DrawerFunc(func(p num.Point) error { return assert.AnError })
+	err = e.Solve(0.1, 0, -0.55, 1, &Collector{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to draw the event")
+}
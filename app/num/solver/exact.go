@@ -1,10 +1,17 @@
 package solver
 
 import (
+	"math"
+	"sync"
+	"sync/atomic"
+
 	"github.com/Semior001/decompract/app/num"
 	"github.com/pkg/errors"
 )
 
+// ErrConstant is returned, if initial values are incompatible with the general solution, so its constant is not finite
+var ErrConstant = errors.New("initial values are incompatible with the general solution, the constant is not finite")
+
 // Exact solver just draws the exact solution directly,
 // without applying any specific algorithm
 type Exact struct {
@@ -13,25 +20,215 @@ type Exact struct {
 	F func(x, c float64) (float64, error)
 	// C calculates the constant for the F
 	C func(x0, y0 float64) (float64, error)
+	// JumpRatio is the growth of the solution towards the step, across which it changes its sign,
+	// that makes the step the discontinuity, defaultJumpRatio if not set
+	JumpRatio float64
+}
+
+// defaultJumpRatio is the least growth of the solution towards the discontinuity, the solution with the simple pole
+// inside the step grows towards it about three times, or twice at ends of the interval, where the step has the single
+// neighbour, while it decreases towards the smooth change of the sign
+const defaultJumpRatio = 1.5
+
+// Bracket is the step of the grid, that contains the discontinuity of the solution, e.g. its pole
+type Bracket struct {
+	Left  float64 `json:"left"`
+	Right float64 `json:"right"`
+}
+
+// DiscontinuityDrawer is a Drawer that also receives discontinuities of the exact solution, the exact solver finds
+// it anywhere in the chain of wrapped drawers and passes steps of the solution, that contain discontinuities,
+// once its points are drawn, nil, if the solution is continuous. The step is the discontinuity, if the solution
+// changes its sign across it and grows towards it by JumpRatio, like around the simple pole, or if the solution
+// is not finite at its end. Poles of even order, where the solution doesn't change its sign, are not detected,
+// as values at nodes don't jump there. Discontinuities are detected, but the solution is neither refined
+// at them nor failed
+type DiscontinuityDrawer interface {
+	Drawer
+	DrawDiscontinuities(b []Bracket) error
 }
 
+// Name returns the name of the solution
+func (e *Exact) Name() string { return "Exact solution" }
+
 // Solve just plots the graph, without applying any algorithm
-func (e *Exact) Solve(stepSize, x0, y0, xEnd float64) (num.Line, error) {
-	x := x0
+func (e *Exact) Solve(stepSize, x0, y0, xEnd float64, d Drawer) error {
+	if err := checkArgs(stepSize, x0, xEnd); err != nil {
+		return err
+	}
 	y := y0
-	c, err := e.C(x0, y0)
+	c, err := e.Constant(x0, y0)
 	if err != nil {
-		return num.Line{}, errors.Wrapf(err, "failed to calculate constant for x0=%.4f, y0=%.4f", x0, y0)
+		return err
 	}
 
-	var pts []num.Point
-	for x <= xEnd {
-		pts = append(pts, num.Point{X: x, Y: y})
-		x += stepSize
+	g := NewGrid(x0, xEnd, stepSize)
+	out := sinkOf(e.Name(), d)
+	jumps := jumpDetector{ratio: e.JumpRatio}
+	if jumps.ratio <= 0 {
+		jumps.ratio = defaultJumpRatio
+	}
+	for i := 0; i <= g.N; i++ {
+		p := num.Point{X: g.X(i), Y: y}
+		if err = out.put(i, g.Step(i), p); err != nil {
+			return err
+		}
+		jumps.add(p)
+		if i == g.N {
+			break // the point is the last one, so the next step is not calculated
+		}
+		x := g.X(i + 1)
 		if y, err = e.F(x, c); err != nil {
-			return num.Line{}, errors.Wrapf(err, "failed to calculate y for x=%.4f, c=%.4f", x, c)
+			return out.fail(&StepError{Method: e.Name(), Step: i, Stage: "y", X: x, Y: c, Err: err})
+		}
+	}
+
+	if err = out.flush(); err != nil {
+		return err
+	}
+	if dd := discontinuityDrawer(d); dd != nil {
+		if err = dd.DrawDiscontinuities(jumps.finish()); err != nil {
+			return errors.Wrap(err, "failed to draw discontinuities")
 		}
 	}
+	return nil
+}
+
+// Constant calculates the constant of the solution, that passes through (x0, y0), the constant,
+// that is not finite, e.g. for y0 = 0 in the family with c in the denominator, is ErrConstant,
+// as initial values are incompatible with the general solution
+func (e *Exact) Constant(x0, y0 float64) (float64, error) {
+	c, err := e.C(x0, y0)
+	if err != nil {
+		return 0, &StepError{Method: e.Name(), Step: 0, Stage: "c", X: x0, Y: y0, Err: err}
+	}
+	if !isFinite(c) {
+		return 0, &StepError{Method: e.Name(), Step: 0, Stage: "c", X: x0, Y: y0, Err: errors.Wrapf(ErrConstant, "got %v", c)}
+	}
+	return c, nil
+}
+
+// jumpDetector finds discontinuities of the solution by its nodes, one by one, the step is checked,
+// when the node after it is known, as the growth of the solution towards the step is measured by neighbours
+type jumpDetector struct {
+	ratio float64
+	last  [4]num.Point // last nodes, the newest is the last one
+	n     int          // number of added nodes
+	found []Bracket
+}
+
+// add the next node of the solution
+func (j *jumpDetector) add(p num.Point) {
+	copy(j.last[:], j.last[1:])
+	j.last[3] = p
+	j.n++
+	if j.n > 1 && !isFinite(p.Y) && isFinite(j.last[2].Y) {
+		j.found = append(j.found, Bracket{Left: j.last[2].X, Right: p.X})
+	}
+	if j.n > 2 {
+		j.check(j.n > 3, true)
+	}
+}
+
+// finish checks the last step and returns found discontinuities
+func (j *jumpDetector) finish() []Bracket {
+	if j.n > 1 {
+		// the last step has no node after it
+		copy(j.last[:], j.last[1:])
+		j.check(j.n > 2, false)
+	}
+	return j.found
+}
+
+// check the step between last[1] and last[2] with the node before it in last[0] and after it in last[3]
+func (j *jumpDetector) check(hasBefore, hasAfter bool) {
+	a, b := j.last[1], j.last[2]
+	if !isFinite(a.Y) || !isFinite(b.Y) || a.Y*b.Y >= 0 {
+		return // the sign is not changed
+	}
+	growth := func(near, far num.Point) float64 {
+		if !isFinite(far.Y) || far.Y == 0 {
+			return 0
+		}
+		return math.Abs(near.Y / far.Y)
+	}
+	g := 0.0
+	if hasBefore {
+		g = growth(a, j.last[0])
+	}
+	if hasAfter {
+		g = math.Max(g, growth(b, j.last[3]))
+	}
+	if g > j.ratio {
+		j.found = append(j.found, Bracket{Left: a.X, Right: b.X})
+	}
+}
+
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// Memo evaluates the exact solution with the fixed constant and caches its values by x, values are
+// the same as of F bit for bit, failed evaluations are not cached. At most size values are kept,
+// the oldest one is evicted first. Memo is safe for concurrent use
+type Memo struct {
+	f    func(x, c float64) (float64, error)
+	c    float64
+	size int
+
+	mu   sync.RWMutex
+	vals map[uint64]float64 // values by bits of x, so -0 and +0 are different keys
+	keys []uint64           // ring of cached keys in the order of insertion
+	next int                // index of the oldest key in the ring, when it is full
+
+	hits, misses int64
+}
+
+// MemoStats are counters of evaluations of Memo
+type MemoStats struct {
+	Hits   int // values, taken from the cache
+	Misses int // values, evaluated by F
+}
+
+// Memo makes the cache of the solution with the constant c, that keeps at most size values
+func (e *Exact) Memo(c float64, size int) *Memo {
+	return &Memo{f: e.F, c: c, size: size, vals: make(map[uint64]float64), keys: make([]uint64, 0, size)}
+}
+
+// At returns the value of the solution at x
+func (m *Memo) At(x float64) (float64, error) {
+	key := math.Float64bits(x)
+	m.mu.RLock()
+	y, ok := m.vals[key]
+	m.mu.RUnlock()
+	if ok {
+		atomic.AddInt64(&m.hits, 1)
+		return y, nil
+	}
+
+	atomic.AddInt64(&m.misses, 1)
+	y, err := m.f(x, m.c)
+	if err != nil || m.size < 1 {
+		return y, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok = m.vals[key]; ok {
+		return y, nil // evaluated concurrently by another caller
+	}
+	if len(m.keys) < m.size {
+		m.keys = append(m.keys, key)
+	} else {
+		delete(m.vals, m.keys[m.next])
+		m.keys[m.next] = key
+		m.next = (m.next + 1) % m.size
+	}
+	m.vals[key] = y
+	return y, nil
+}
 
-	return num.Line{Name: "Exact solution", Points: pts}, nil
+// Stats returns counters of evaluations since the creation of the memo
+func (m *Memo) Stats() MemoStats {
+	return MemoStats{Hits: int(atomic.LoadInt64(&m.hits)), Misses: int(atomic.LoadInt64(&m.misses))}
 }
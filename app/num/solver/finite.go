@@ -0,0 +1,57 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNonFinite is matched by errors.Is with *NonFiniteError, e.g. to tell singularities from other failures
+var ErrNonFinite = errors.New("value is not finite")
+
+// NonFiniteError is returned, if the value at the point is not finite, e.g. f at the singularity,
+// solvers wrap it into StepError with the step and the stage, at which it is met
+type NonFiniteError struct {
+	What  string  // what is not finite, f or y
+	X, Y  float64 // the point
+	Value float64 // the value, that is not finite
+}
+
+// Error returns the message of the error
+func (e *NonFiniteError) Error() string {
+	return fmt.Sprintf("%s is not finite at x=%v, y=%v: %v", e.What, e.X, e.Y, e.Value)
+}
+
+// Is reports whether the target is ErrNonFinite
+func (e *NonFiniteError) Is(target error) bool { return target == ErrNonFinite }
+
+// FiniteFunc wraps f to fail with *NonFiniteError, if its value is not finite, so the solver stops at the stage
+// of the step, that met the singularity, instead of drawing not finite points after it
+func FiniteFunc(f Func) Func {
+	return func(x, y float64) (float64, error) {
+		v, err := f(x, y)
+		if err == nil && !isFinite(v) {
+			return 0, &NonFiniteError{What: "f", X: x, Y: y, Value: v}
+		}
+		return v, err
+	}
+}
+
+// WithFinite wraps the drawer to check drawn points, the not finite y fails the solution with *NonFiniteError,
+// if clip is zero, otherwise y is clipped to [-clip, clip], so points stay finite and charts show both branches
+// at the pole, points with y = NaN are skipped, as they have no side to clip them to, and the solution goes on,
+// while the solver can make next steps
+func WithFinite(d Drawer, clip float64) Drawer {
+	return wrap(d, func(c call) error {
+		switch {
+		case clip > 0 && math.IsNaN(c.p.Y):
+			return nil
+		case clip > 0 && math.Abs(c.p.Y) > clip:
+			c.p.Y = math.Copysign(clip, c.p.Y)
+		case clip <= 0 && !isFinite(c.p.Y):
+			return &NonFiniteError{What: "y", X: c.p.X, Y: c.p.Y, Value: c.p.Y}
+		}
+		return c.do()
+	})
+}
@@ -0,0 +1,72 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFiniteFunc(t *testing.T) {
+	// f is singular at x = 1, right on the node
+	f := FiniteFunc(func(x, _ float64) (float64, error) { return 1 / (x - 1), nil })
+	_, err := Collect(&Euler{F: f}, 0.25, 0, 0, 2)
+	var se *StepError
+	require.True(t, errors.As(err, &se), err)
+	assert.Equal(t, 4, se.Step)
+	assert.Equal(t, "f", se.Stage)
+	var nfe *NonFiniteError
+	require.True(t, errors.As(err, &nfe), err)
+	assert.Equal(t, NonFiniteError{What: "f", X: 1, Y: se.Y, Value: math.Inf(1)}, *nfe)
+	assert.Contains(t, err.Error(), "f is not finite at x=1")
+	assert.True(t, errors.Is(err, ErrNonFinite), "the sentinel matches the error")
+	assert.False(t, errors.Is(errors.New("other"), ErrNonFinite))
+
+	errF := errors.New("failed")
+	_, err = FiniteFunc(func(_, _ float64) (float64, error) { return 0, errF })(0, 0)
+	assert.Equal(t, errF, err)
+}
+
+func TestWithFinite(t *testing.T) {
+	// y = 1/(1 - x) has the pole at x = 1
+	sq := func(_, y float64) (float64, error) { return y * y, nil }
+	c := &Collector{}
+	err := (&RungeKutta{F: sq}).Solve(0.01, 0, 1, 2, WithFinite(c, 0))
+	var nfe *NonFiniteError
+	require.True(t, errors.As(err, &nfe), err)
+	assert.Equal(t, "y", nfe.What)
+	assert.Greater(t, nfe.X, 1.0)
+	for _, p := range c.Points {
+		assert.False(t, math.IsInf(p.Y, 0) || math.IsNaN(p.Y), "x=%v", p.X)
+	}
+
+	c = &Collector{}
+	require.NoError(t, (&RungeKutta{F: sq}).Solve(0.01, 0, 1, 2, WithFinite(c, 1e3)))
+	clipped := 0
+	for _, p := range c.Points {
+		require.False(t, math.IsInf(p.Y, 0) || math.IsNaN(p.Y), "x=%v", p.X)
+		assert.LessOrEqual(t, math.Abs(p.Y), 1e3)
+		if math.Abs(p.Y) == 1e3 {
+			clipped++
+		}
+	}
+	assert.NotZero(t, clipped, "points past the pole are clipped")
+	require.Len(t, c.Points, 201)
+	assert.Equal(t, 0.5, c.Points[50].X)
+	assert.InDelta(t, 2, c.Points[50].Y, 1e-8)
+	for i, p := range c.Points[:100] {
+		assert.Less(t, p.X, 1.0, "point %d", i)
+		assert.Greater(t, p.Y, 0.0, "the branch before the pole is kept")
+	}
+
+	c = &Collector{}
+	d := WithFinite(c, 1)
+	for _, y := range []float64{math.NaN(), math.Inf(-1), 0.5} {
+		require.NoError(t, d.Draw(num.Point{X: 1, Y: y}))
+	}
+	assert.Equal(t, []num.Point{{X: 1, Y: -1}, {X: 1, Y: 0.5}}, c.Points, "points with y = NaN are skipped")
+}
@@ -0,0 +1,161 @@
+package solver
+
+import (
+	"math"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// gridTolerance is the relative tolerance of the number of steps of the grid, the interval, that is
+// shorter than n steps by the rounding error, is still split into n steps
+const gridTolerance = 1e-9
+
+// gridTolerance32 is the relative tolerance of the number of steps of grids of solvers in float32, the step,
+// rounded to float32, splits the interval into the whole number of steps up to its relative error about 6e-8
+const gridTolerance32 = 1e-6
+
+// maxGridSteps is the limit of the number of steps of the grid, as the count of nodes must fit into int
+const maxGridSteps = 1 << 53
+
+// Grid is the uniform grid of nodes from X0 with the step H, nodes are calculated by their indexes,
+// not accumulated, so the rounding error doesn't grow with the number of steps and all solvers
+// give the same x, bit for bit, at the same node. H is negative on the reversed interval, so solvers,
+// that step by the grid, go backward from X0 without changes
+type Grid struct {
+	X0 float64
+	H  float64
+	N  int // number of steps, nodes are indexed from 0 to N, the grid is empty, if N is negative
+
+	xEnd  float64 // the last node
+	short bool    // the last step is shorter than H
+}
+
+// NewGrid makes the grid of the interval from x0 to xEnd with the size of the step h, the last node is always
+// xEnd itself, if the interval is not the whole number of steps up to the rounding error, the last step is
+// shortened, so it ends at xEnd, the grid of the empty interval is the single node x0, whatever the step is,
+// otherwise the grid is empty, if the size of the step is not positive and finite. The grid goes backward
+// with the step -h, if xEnd is less than x0
+func NewGrid(x0, xEnd, h float64) Grid {
+	return newGrid(x0, xEnd, h, gridTolerance)
+}
+
+// newGrid makes the grid with the relative tolerance of the number of steps
+func newGrid(x0, xEnd, h, tol float64) Grid {
+	g := Grid{X0: x0, H: h, N: -1, xEnd: xEnd}
+	if x0 == xEnd {
+		g.N = 0
+		return g
+	}
+	steps := math.Abs(xEnd-x0) / h
+	if !(h > 0) || math.IsInf(h, 1) || !(steps >= 0) {
+		return g
+	}
+	steps = math.Min(steps, maxGridSteps)
+	if xEnd < x0 {
+		g.H = -h
+	}
+
+	// the slack is kept below the step, otherwise the tolerance of billions of steps swallows the whole step,
+	// and the last node precedes the last but one
+	slack := math.Min(steps*tol, 0.5)
+	g.N = int(math.Floor(steps + slack))
+	if math.Abs(steps-float64(g.N)) > slack {
+		g.N++
+		g.short = true
+	}
+	return g
+}
+
+// checkArgs checks, that the size of the step is positive and finite, and the step advances x at both ends
+// of the interval and gives the number of steps, that fits into the grid, so the solver doesn't spin forever,
+// errors are num.ErrBadStep and num.ErrStepTooSmall. The interval might be reversed, as the grid goes backward
+func checkArgs(h, x0, xEnd float64) error {
+	if err := num.CheckStep(h); err != nil {
+		return err
+	}
+	if x0+h == x0 || xEnd+h == xEnd {
+		return errors.Wrapf(num.ErrStepTooSmall, "step %v is lost in x=%v", h, math.Max(math.Abs(x0), math.Abs(xEnd)))
+	}
+	if steps := math.Abs(xEnd-x0) / h; steps > maxGridSteps {
+		return errors.Wrapf(num.ErrStepTooSmall, "step %v gives %.3g steps, more than %d", h, steps, int64(maxGridSteps))
+	}
+	return nil
+}
+
+// gridOf checks arguments of the solver in the numeric type T and makes the grid of the interval, nodes
+// are calculated in float64 and rounded to T, the tolerance of the number of steps is widened, if T has
+// the precision of float32, so the rounding of the step to T doesn't leave the last step, lost in x
+func gridOf[T num.Float](h, x0, xEnd T) (Grid, error) {
+	if err := checkArgs(float64(h), float64(x0), float64(xEnd)); err != nil {
+		return Grid{}, err
+	}
+	if x0+h == x0 || xEnd+h == xEnd {
+		return Grid{}, errors.Wrapf(num.ErrStepTooSmall, "step %v is lost in x=%v", h, math.Max(math.Abs(float64(x0)),
+			math.Abs(float64(xEnd))))
+	}
+	tol := gridTolerance
+	if one := T(1); one+T(gridTolerance) == one {
+		tol = gridTolerance32
+	}
+	return newGrid(float64(x0), float64(xEnd), float64(h), tol), nil
+}
+
+// direction returns the sign of steps from x0 to xEnd, solvers with the adaptive step control sizes of steps
+// and step by them times the sign, as NewGrid does with the negative H
+func direction(x0, xEnd float64) float64 {
+	if xEnd < x0 {
+		return -1
+	}
+	return 1
+}
+
+// StepsCount returns the number of points, that each solver draws for the step and the interval,
+// as they are nodes of the grid, the count includes both ends of the interval
+func StepsCount(step, x0, xEnd float64) (int, error) {
+	if err := checkArgs(step, x0, xEnd); err != nil {
+		return 0, err
+	}
+	return NewGrid(x0, xEnd, step).N + 1, nil
+}
+
+// X returns the i-th node of the grid
+func (g Grid) X(i int) float64 {
+	if i == g.N {
+		return g.xEnd
+	}
+	return g.X0 + float64(i)*g.H
+}
+
+// LastFull returns the index of the last node, reached by the whole step, it precedes the last node,
+// if the last step is shortened
+func (g Grid) LastFull() int {
+	if g.short {
+		return g.N - 1
+	}
+	return g.N
+}
+
+// Step returns the size of the step, that led to the i-th node, zero for the first node
+func (g Grid) Step(i int) float64 {
+	switch {
+	case i == 0:
+		return 0
+	case i == g.N && g.short:
+		return g.xEnd - g.X(i-1)
+	default:
+		return g.H
+	}
+}
+
+// Nodes returns all nodes of the grid
+func (g Grid) Nodes() []float64 {
+	if g.N < 0 {
+		return nil
+	}
+	res := make([]float64, g.N+1)
+	for i := range res {
+		res[i] = g.X(i)
+	}
+	return res
+}
@@ -0,0 +1,182 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGrid(t *testing.T) {
+	for _, tt := range []struct {
+		x0, xEnd, h float64
+		nodes       []float64
+	}{
+		{0, 1, 0.25, []float64{0, 0.25, 0.5, 0.75, 1}},
+		{0, 1, 0.3, []float64{0, 0.3, 0.6, 0.8999999999999999, 1}},
+		{-1, 1, 2, []float64{-1, 1}},
+		{1, 1, 0.1, []float64{1}},
+		{1, 0, 0.25, []float64{1, 0.75, 0.5, 0.25, 0}},
+		{1, -1, 2, []float64{1, -1}},
+		{0, 1, 0, nil},
+		{0, 1, -0.1, nil},
+		{0, 1, math.NaN(), nil},
+		{0, 1, math.Inf(1), nil},
+	} {
+		assert.Equal(t, tt.nodes, NewGrid(tt.x0, tt.xEnd, tt.h).Nodes(), "x0=%v, x_end=%v, h=%v", tt.x0, tt.xEnd, tt.h)
+	}
+
+	// the step, calculated from the number of steps, might not split the interval exactly,
+	// but the interval is still split into n steps and ends at x_end
+	for _, n := range []int{3, 7, 10, 30, 49, 1000, 100000} {
+		g := NewGrid(-4, 4.1, stepSize(n, -4, 4.1))
+		assert.Equal(t, n, g.N)
+		assert.Equal(t, 4.1, g.X(g.N))
+	}
+}
+
+func TestNewGrid_ManySteps(t *testing.T) {
+	// the relative tolerance of 3e9 steps is 3 steps, it must not take the whole step
+	for _, tt := range []struct{ x0, xEnd, h float64 }{{0, 3, 1e-9}, {3, 0, 1e-9}, {0, 3.0000000005, 1e-9}} {
+		g := NewGrid(tt.x0, tt.xEnd, tt.h)
+		dir := direction(tt.x0, tt.xEnd)
+		assert.Less(t, dir*g.X(g.N-1), dir*tt.xEnd, "x0=%v, x_end=%v", tt.x0, tt.xEnd)
+		assert.Greater(t, dir*g.Step(g.N), 0.0, "x0=%v, x_end=%v", tt.x0, tt.xEnd)
+		assert.LessOrEqual(t, math.Abs(g.Step(g.N)), tt.h, "x0=%v, x_end=%v", tt.x0, tt.xEnd)
+		assert.Equal(t, tt.xEnd, g.X(g.N))
+	}
+}
+
+func TestSolvers_LastPoint(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		x0, xEnd, h float64
+		points      int
+		lastStep    float64
+	}{
+		{"shortened", 0, 1, 0.3, 5, 0.1},
+		{"the step of n=30 rounded", -4, 4, 0.26667, 31, 8 - 29*0.26667},
+		{"exact multiple", 0, 1, 0.25, 5, 0.25},
+		{"n steps", -4, 4, stepSize(30, -4, 4), 31, stepSize(30, -4, 4)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, s := range batchSolvers(10) {
+				d := &stepCollector{}
+				require.NoError(t, s.Solve(tt.h, tt.x0, 1, tt.xEnd, d))
+				require.Len(t, d.Points, tt.points, s.Name())
+				last := len(d.Points) - 1
+				assert.Equal(t, tt.xEnd, d.Points[last].X, "%s ends exactly at x_end", s.Name())
+				assert.Less(t, d.Points[last-1].X, d.Points[last].X, "%s has no duplicate of the end", s.Name())
+				assert.InDelta(t, tt.lastStep, d.hs[last], 1e-12, "the last step of %s", s.Name())
+				assert.Equal(t, 0.0, d.hs[0])
+				for i := 1; i < last; i++ {
+					require.Equal(t, tt.h, d.hs[i], "step %d of %s", i, s.Name())
+				}
+			}
+		})
+	}
+
+	// the shortened step is taken by the method, so euler reaches x_end with the slope at the previous node
+	line, err := Collect(&Euler{F: func(x, y float64) (float64, error) { return 1, nil }}, 0.3, 0, 0, 1)
+	require.NoError(t, err)
+	assert.InDelta(t, 1, line.Points[4].Y, 1e-12, "y = x is solved exactly with the shortened step")
+}
+
+func TestGrid_Drift(t *testing.T) {
+	// accumulated 0.1 drifts from the nodes, e.g. 70 additions give 6.999999999999991
+	acc := 0.0
+	for i := 0; i < 70; i++ {
+		acc += 0.1
+	}
+	require.NotEqual(t, 7.0, acc)
+
+	g := NewGrid(0, 7, 0.1)
+	assert.Equal(t, 70, g.N)
+	for i := 0; i <= g.N; i++ {
+		assert.Equal(t, float64(i)*0.1, g.X(i), "node %d", i)
+	}
+	assert.Equal(t, 7.0, g.X(70))
+
+	for _, s := range batchSolvers(10) {
+		line, err := Collect(s, 0.1, 0, 1, 7)
+		require.NoError(t, err)
+		require.Len(t, line.Points, 71, s.Name())
+		assert.Equal(t, 3.5, line.Points[35].X, s.Name())
+		assert.Equal(t, 7.0, line.Points[70].X, "the end is reached by %s", s.Name())
+	}
+}
+
+func TestSolvers_SameGrid(t *testing.T) {
+	for _, tt := range []struct{ x0, xEnd, h float64 }{
+		{0, 7, 0.1},
+		{-4, 4, stepSize(30, -4, 4)},
+		{0.3, 2.9, 0.013},
+	} {
+		var expected []float64
+		for _, s := range batchSolvers(10) {
+			line, err := Collect(s, tt.h, tt.x0, 1, tt.xEnd)
+			require.NoError(t, err)
+			xs := make([]float64, len(line.Points))
+			for i, pt := range line.Points {
+				xs[i] = pt.X
+			}
+			if expected == nil {
+				expected = xs
+				continue
+			}
+			// values are compared by bits, so even the last bit of x is the same
+			assert.Equal(t, bits(expected), bits(xs), "x of %s, x0=%v, h=%v", s.Name(), tt.x0, tt.h)
+		}
+		assert.Equal(t, NewGrid(tt.x0, tt.xEnd, tt.h).Nodes(), expected)
+	}
+}
+
+// bits returns bits of the values
+func bits(vals []float64) []uint64 {
+	res := make([]uint64, len(vals))
+	for i, v := range vals {
+		res[i] = math.Float64bits(v)
+	}
+	return res
+}
+
+func TestStepsCount(t *testing.T) {
+	for _, tt := range []struct {
+		h, x0, xEnd float64
+		points      int
+	}{
+		{0.1, 0, 1, 11},
+		{0.25, 0, 1, 5},
+		{0.1, 0, 7, 71}, // x accumulated by steps drifts from 7, the grid doesn't
+		{0.3, 0, 1, 5},  // the last step is shortened
+		{0.26667, -4, 4, 31},
+		{5, 0, 1, 2},
+		{0.1, 2, 2, 1},
+		{0, 2, 2, 0}, // the bad step is refused, whatever the interval is
+	} {
+		n, err := StepsCount(tt.h, tt.x0, tt.xEnd)
+		if tt.points == 0 {
+			assert.True(t, errors.Is(err, num.ErrBadStep), "h=%v", tt.h)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, tt.points, n, "h=%v on [%v, %v]", tt.h, tt.x0, tt.xEnd)
+		for _, s := range batchSolvers(10) {
+			line, err := Collect(s, tt.h, tt.x0, 1, tt.xEnd)
+			require.NoError(t, err)
+			assert.Len(t, line.Points, n, "%s with h=%v on [%v, %v]", s.Name(), tt.h, tt.x0, tt.xEnd)
+		}
+	}
+
+	for _, h := range []float64{-5, math.NaN(), math.Inf(1)} {
+		_, err := StepsCount(h, 0, 1)
+		assert.True(t, errors.Is(err, num.ErrBadStep), "h=%v", h)
+	}
+	n, err := StepsCount(0.1, 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 11, n, "the reversed interval is split as well")
+}
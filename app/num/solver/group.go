@@ -0,0 +1,53 @@
+package solver
+
+import (
+	"context"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+// Group solves the same problem by its solvers concurrently, each one draws to its own drawer,
+// the failure of any solver stops the rest of them
+type Group struct {
+	Solvers []Interface
+	Drawer  func(s Interface) Drawer // drawer of the solver, points are collected for the result anyway, none if nil
+}
+
+// Solve solves the problem by each solver and returns lines of solutions by names of methods, the first failure
+// of the solver, or the error of the context, is returned, other solvers are stopped by the context of the group
+func (g *Group) Solve(ctx context.Context, stepSize, x0, y0, xEnd float64) (map[string]num.Line, error) {
+	collectors := make([]*Collector, len(g.Solvers))
+	seen := map[string]bool{}
+	for i, s := range g.Solvers {
+		if seen[s.Name()] {
+			return nil, errors.Errorf("solvers of the group must have distinct names, %q is repeated", s.Name())
+		}
+		seen[s.Name()] = true
+		collectors[i] = &Collector{}
+	}
+
+	eg, gctx := errgroup.WithContext(ctx)
+	for i, s := range g.Solvers {
+		s, c := s, collectors[i]
+		var d Drawer = c
+		if g.Drawer != nil {
+			d = WithObserver(g.Drawer(s), func(p num.Point, err error) {
+				if err == nil {
+					c.Points = append(c.Points, p)
+				}
+			})
+		}
+		eg.Go(func() error { return s.Solve(stepSize, x0, y0, xEnd, WithContext(gctx, d)) })
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]num.Line, len(g.Solvers))
+	for i, s := range g.Solvers {
+		res[s.Name()] = num.Line{Name: s.Name(), Points: collectors[i].Points}
+	}
+	return res, nil
+}
@@ -0,0 +1,74 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroup_Solve(t *testing.T) {
+	solvers := []Interface{&Euler{F: canonical}, &ImprovedEuler{F: canonical}, &RungeKutta{F: canonical}}
+	var mu sync.Mutex
+	drawn := map[string]int{}
+	g := &Group{Solvers: solvers, Drawer: func(s Interface) Drawer {
+		return DrawerFunc(func(p num.Point) error {
+			mu.Lock()
+			defer mu.Unlock()
+			drawn[s.Name()]++
+			return nil
+		})
+	}}
+	lines, err := g.Solve(context.Background(), 0.03, -4, 1, 4)
+	require.NoError(t, err)
+	require.Len(t, lines, 3)
+	for _, s := range solvers {
+		want, err := Collect(s, 0.03, -4, 1, 4)
+		require.NoError(t, err)
+		assert.Equal(t, want, lines[s.Name()])
+		assert.Equal(t, len(want.Points), drawn[s.Name()], "points of %s are drawn to its own drawer", s.Name())
+	}
+
+	lines, err = (&Group{Solvers: solvers[:1]}).Solve(context.Background(), 0.1, 0, 1, 1)
+	require.NoError(t, err)
+	assert.Len(t, lines["Euler's method"].Points, 11)
+}
+
+func TestGroup_Errors(t *testing.T) {
+	errF := errors.New("failed")
+	failing := &Euler{F: func(x, y float64) (float64, error) {
+		if x > 0.5 {
+			return 0, errF
+		}
+		return y, nil
+	}}
+	// each point of the long solution takes a millisecond, so it would take minutes to be solved
+	drawn := 0
+	long := &RungeKutta{F: canonical}
+	g := &Group{Solvers: []Interface{failing, long}, Drawer: func(s Interface) Drawer {
+		if s != long {
+			return &Collector{}
+		}
+		return DrawerFunc(func(p num.Point) error {
+			time.Sleep(time.Millisecond)
+			drawn++
+			return nil
+		})
+	}}
+	_, err := g.Solve(context.Background(), 1e-5, 0, 1, 1)
+	assert.True(t, errors.Is(err, errF), err)
+	assert.Less(t, drawn, 10000, "the rest of solvers are stopped")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = (&Group{Solvers: []Interface{long}}).Solve(ctx, 0.1, 0, 1, 1)
+	assert.True(t, errors.Is(err, context.Canceled), err)
+
+	_, err = (&Group{Solvers: []Interface{&Euler{F: canonical}, &Euler{F: benchF}}}).Solve(context.Background(), 0.1, 0, 1, 1)
+	assert.EqualError(t, err, `solvers of the group must have distinct names, "Euler's method" is repeated`)
+}
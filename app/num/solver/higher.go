@@ -0,0 +1,58 @@
+package solver
+
+// FuncN calculates y^(n) = f(x, y, y', ..., y^(n-1)) of the equation of the n-th order, ys are y and its
+// derivatives up to the (n-1)-th one, f must not retain ys
+type FuncN func(x float64, ys []float64) (float64, error)
+
+// Reduce makes the system of first order equations of y, y', ..., y^(order-1) from the equation of the order,
+// each component is the derivative of the previous one, the last one is f
+func Reduce(f FuncN, order int) SystemFunc {
+	dy := make([]float64, order) // solvers copy the result, so it is reused
+	return func(x float64, y []float64) ([]float64, error) {
+		copy(dy, y[1:])
+		v, err := f(x, y)
+		dy[order-1] = v
+		return dy, err
+	}
+}
+
+// HigherOrder solves the equation of the order, higher than the first one, by the solver of systems
+// of its reduction, y0 of Solve is y(x0), initial values of derivatives are in DY0, points of y
+// are drawn as the ones of Scalar, SolveAll draws derivatives along with y
+type HigherOrder struct {
+	F      FuncN
+	DY0    []float64                          // y'(x0), ..., y^(n-1)(x0), the order of the equation is len(DY0)+1
+	System func(f SystemFunc) SystemInterface // solver of the reduced system, Runge-Kutta's method if nil
+}
+
+// Order returns the order of the equation
+func (h *HigherOrder) Order() int { return len(h.DY0) + 1 }
+
+// Name returns the name of the method of the system
+func (h *HigherOrder) Name() string { return h.system().Name() }
+
+// Solve the equation with the given initial values
+func (h *HigherOrder) Solve(stepSize, x0, y0, xEnd float64, d Drawer) error {
+	s := h.system()
+	return drawFirst(s.Name(), x0, d, func(vd VectorDrawer) error {
+		return s.Solve(stepSize, x0, h.initial(y0), xEnd, vd)
+	})
+}
+
+// SolveAll solves the equation with the given initial values and draws y along with its derivatives
+// up to the (n-1)-th one as components of points of the reduced system
+func (h *HigherOrder) SolveAll(stepSize, x0, y0, xEnd float64, d VectorDrawer) error {
+	return h.system().Solve(stepSize, x0, h.initial(y0), xEnd, d)
+}
+
+func (h *HigherOrder) system() SystemInterface {
+	f := Reduce(h.F, h.Order())
+	if h.System == nil {
+		return &SystemRungeKutta{F: f}
+	}
+	return h.System(f)
+}
+
+func (h *HigherOrder) initial(y0 float64) []float64 {
+	return append([]float64{y0}, h.DY0...)
+}
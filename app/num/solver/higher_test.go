@@ -0,0 +1,74 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHigherOrder_Solve(t *testing.T) {
+	// y'' = -y from y(0) = 0 and y'(0) = 1 is sin(x)
+	osc := func(_ float64, ys []float64) (float64, error) { return -ys[0], nil }
+	h := &HigherOrder{F: osc, DY0: []float64{1}}
+	assert.Equal(t, 2, h.Order())
+	assert.Equal(t, "Runge-Kutta's method", h.Name())
+
+	line, err := Collect(h, math.Pi/100, 0, 0, math.Pi)
+	require.NoError(t, err)
+	require.Len(t, line.Points, 101)
+	for _, p := range line.Points {
+		assert.InDelta(t, math.Sin(p.X), p.Y, 1e-7, "x=%v", p.X)
+	}
+
+	pts := &VectorCollector{}
+	require.NoError(t, h.SolveAll(math.Pi/100, 0, 0, math.Pi, pts))
+	require.Len(t, pts.Points, 101)
+	for i, p := range pts.Points {
+		assert.Equal(t, line.Points[i].Y, p.Y[0])
+		assert.InDelta(t, math.Cos(p.X), p.Y[1], 1e-7, "x=%v", p.X)
+	}
+
+	// y''' = y from ones is exp(x), the equation is solved as the reduced system
+	grow := &HigherOrder{F: func(_ float64, ys []float64) (float64, error) { return ys[0], nil }, DY0: []float64{1, 1},
+		System: func(f SystemFunc) SystemInterface { return &SystemEuler{F: f} }}
+	assert.Equal(t, "Euler's method", grow.Name())
+	line, err = Collect(grow, 0.01, 0, 1, 1)
+	require.NoError(t, err)
+	want, err := CollectSystem(&SystemEuler{F: func(x float64, y []float64) ([]float64, error) {
+		return []float64{y[1], y[2], y[0]}, nil
+	}}, 0.01, 0, []float64{1, 1, 1}, 1)
+	require.NoError(t, err)
+	require.Len(t, line.Points, len(want))
+	for i, p := range want {
+		assert.Equal(t, num.Point{X: p.X, Y: p.Y[0]}, line.Points[i])
+	}
+	assert.InDelta(t, math.E, line.Points[100].Y, 0.02)
+
+	// the equation of the first order is the scalar problem
+	line, err = Collect(&HigherOrder{F: func(x float64, ys []float64) (float64, error) { return canonical(x, ys[0]) }},
+		0.03, -4, 1, 4)
+	require.NoError(t, err)
+	rk4, err := Collect(&RungeKutta{F: canonical}, 0.03, -4, 1, 4)
+	require.NoError(t, err)
+	assert.Equal(t, rk4.Points, line.Points)
+}
+
+func TestHigherOrder_Errors(t *testing.T) {
+	errF := errors.New("failed")
+	c := &Collector{}
+	err := (&HigherOrder{F: func(x float64, ys []float64) (float64, error) {
+		if x > 0.25 {
+			return 0, errF
+		}
+		return -ys[0], nil
+	}, DY0: []float64{1}}).Solve(0.1, 0, 0, 1, c)
+	assert.True(t, errors.Is(err, errF))
+	var se *StepError
+	require.True(t, errors.As(err, &se))
+	assert.Equal(t, 2, se.Step)
+	assert.Len(t, c.Points, 3, "points before the failure are drawn")
+}
@@ -1,49 +1,76 @@
 package solver
 
-import (
-	"github.com/Semior001/decompract/app/num"
-	log "github.com/go-pkgz/lgr"
-	"github.com/pkg/errors"
-)
+import "github.com/Semior001/decompract/app/num"
 
-// ImprovedEuler method for solving initial value problem for differential equations
-type ImprovedEuler struct {
-	F func(x, y float64) (float64, error) // calculator for f(x,y) = y'
+// ImprovedEulerOf is the improved Euler's method for solving initial value problem for differential equations
+// in the numeric type T, nodes of the grid are calculated in float64 and rounded to T
+type ImprovedEulerOf[T num.Float] struct {
+	F FuncOf[T] // calculator for f(x,y) = y'
 }
 
+// ImprovedEuler method for solving initial value problem for differential equations
+type ImprovedEuler = ImprovedEulerOf[float64]
+
+// Name returns the name of the method
+func (i *ImprovedEulerOf[T]) Name() string { return "Improved Euler's method" }
+
 // Solve the differential equations with the given initial data
-func (i *ImprovedEuler) Solve(stepSize, x0, y0, xEnd float64) (num.Line, error) {
-	x := x0
-	y := y0
+func (i *ImprovedEulerOf[T]) Solve(stepSize, x0, y0, xEnd T, d DrawerOf[T]) error {
+	g, err := gridOf(stepSize, x0, xEnd)
+	if err != nil {
+		return err
+	}
 
-	log.Printf("[DEBUG] starting solving the equation with Improved Euler's "+
+	logger(d).Logf("[DEBUG] starting solving the equation with Improved Euler's "+
 		"method with stepsz = %.4f, x0 = %.4f, y0 = %.4f, xend = %.4f", stepSize, x0, y0, xEnd)
 
-	var pts []num.Point
-	for x <= xEnd {
-		pts = append(pts, num.Point{X: x, Y: y})
+	return i.solve(g, 0, y0, d)
+}
+
+// Resume continues the solution from the checkpoint up to xEnd
+func (i *ImprovedEulerOf[T]) Resume(cp Checkpoint, xEnd float64, d DrawerOf[T]) error {
+	g, err := cp.grid(xEnd)
+	if err != nil {
+		return err
+	}
+	return i.solve(g, cp.Node, T(cp.Y), d)
+}
+
+// solve solves the problem on the grid from the node, where the solution is y
+func (i *ImprovedEulerOf[T]) solve(g Grid, from int, y T, d DrawerOf[T]) error {
+	out := sinkOf(i.Name(), d)
+	for n := from; n <= g.N; n++ {
+		x := T(g.X(n))
+		if err := out.put(n, g.Step(n), num.PointOf[T]{X: x, Y: y}); err != nil {
+			return err
+		}
+		if n == g.N {
+			break // the point is the last one, so the next step is not calculated
+		}
+		h := T(g.Step(n + 1)) // the last step might be shortened to end at xEnd
 
-		dy, err := i.calculateDeltaY(stepSize, x, y)
+		dy, err := i.calculateDeltaY(n, h, x, y)
 		if err != nil {
-			return num.Line{}, errors.Wrap(err, "failed to calculate delta y")
+			return out.fail(err)
 		}
 		y = y + dy
-		x += stepSize
 	}
 
-	return num.Line{Name: "Improved Euler's method", Points: pts}, nil
+	return out.flush()
 }
 
 // calculateDeltaY calculates:
-// \delta{y_i} = h*f(x_i + h/2, y_i + f(x_i, y_i) * h/2)
-func (i *ImprovedEuler) calculateDeltaY(stepsz, xi, yi float64) (float64, error) {
+// \delta{y_i} = h*f(x_i + h/2, y_i + f(x_i, y_i) * h/2),
+// failures of f are located at the n-th step as stages k1 and k2
+func (i *ImprovedEulerOf[T]) calculateDeltaY(n int, stepsz, xi, yi T) (T, error) {
 	fxiyi, err := i.F(xi, yi)
 	if err != nil {
-		return 0, errors.Wrapf(err, "failed to calculate f(%4.f, %.4f)", xi, yi)
+		return 0, &StepError{Method: i.Name(), Step: n, Stage: "k1", X: float64(xi), Y: float64(yi), Err: err}
 	}
-	f, err := i.F(xi+stepsz/2.0, yi+(fxiyi/2.0)*stepsz)
+	xm, ym := xi+stepsz/2.0, yi+(fxiyi/2.0)*stepsz
+	f, err := i.F(xm, ym)
 	if err != nil {
-		return 0, errors.Wrapf(err, "failed to calculate complex f for h=%.4f, xi=%.4f, yi=%4.f", stepsz, xi, yi)
+		return 0, &StepError{Method: i.Name(), Step: n, Stage: "k2", X: float64(xm), Y: float64(ym), Err: err}
 	}
 	return stepsz * f, nil
 }
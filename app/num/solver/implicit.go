@@ -0,0 +1,213 @@
+package solver
+
+import (
+	"math"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// defaults of the Newton's iteration of implicit solvers, if they are not set
+const (
+	defaultImplicitTol   = 1e-10
+	defaultImplicitIters = 50
+)
+
+// BackwardEuler is the implicit Euler's method y_{i+1} = y_i + h * f(x_{i+1}, y_{i+1}), it is stable
+// for any step on decaying problems, so it solves stiff equations, e.g. y' = -1000y, with steps,
+// that explicit methods blow up with. The equation of the step is solved by Newton's method
+type BackwardEuler struct {
+	F       Func    // calculator for f(x,y) = y'
+	DFDY    Func    // calculator for df/dy, estimated by the central difference of f if not set
+	Tol     float64 // max change of y in the last iteration, relative to max(1, |y|), 1e-10 if zero
+	MaxIter int     // max iterations of the step, 50 if zero
+}
+
+// Name returns the name of the method
+func (b *BackwardEuler) Name() string { return "Backward Euler's method" }
+
+// Solve the initial value problem with the backward Euler's method
+func (b *BackwardEuler) Solve(stepSize, x0, y0, xEnd float64, d Drawer) error {
+	if err := checkArgs(stepSize, x0, xEnd); err != nil {
+		return err
+	}
+	nt, err := newtonOf(b.F, b.DFDY, b.Tol, b.MaxIter)
+	if err != nil {
+		return err
+	}
+
+	logger(d).Logf("[DEBUG] starting solving the equation with backward Euler's "+
+		"method with stepsz = %.4f, tol = %v, x0 = %.4f, y0 = %.4f, xend = %.4f", stepSize, nt.tol, x0, y0, xEnd)
+
+	return solveImplicit(b.Name(), NewGrid(x0, xEnd, stepSize), 0, y0, d, func(i int, x, h, y float64) (float64, error) {
+		return nt.solve(b.Name(), i, x+h, h, y, y)
+	})
+}
+
+// Resume continues the solution from the checkpoint up to xEnd
+func (b *BackwardEuler) Resume(cp Checkpoint, xEnd float64, d Drawer) error {
+	g, err := cp.grid(xEnd)
+	if err != nil {
+		return err
+	}
+	nt, err := newtonOf(b.F, b.DFDY, b.Tol, b.MaxIter)
+	if err != nil {
+		return err
+	}
+	return solveImplicit(b.Name(), g, cp.Node, cp.Y, d, func(i int, x, h, y float64) (float64, error) {
+		return nt.solve(b.Name(), i, x+h, h, y, y)
+	})
+}
+
+// Trapezoidal is the implicit trapezoidal rule y_{i+1} = y_i + h/2 * (f(x_i, y_i) + f(x_{i+1}, y_{i+1})),
+// i.e. Crank-Nicolson's method, of the second order, it is stable for any step on decaying problems,
+// but, unlike BackwardEuler, it doesn't damp fast components, so they oscillate with large steps.
+// The equation of the step is solved by Newton's method
+type Trapezoidal struct {
+	F       Func    // calculator for f(x,y) = y'
+	DFDY    Func    // calculator for df/dy, estimated by the central difference of f if not set
+	Tol     float64 // max change of y in the last iteration, relative to max(1, |y|), 1e-10 if zero
+	MaxIter int     // max iterations of the step, 50 if zero
+}
+
+// Name returns the name of the method
+func (t *Trapezoidal) Name() string { return "Trapezoidal method" }
+
+// Solve the initial value problem with the trapezoidal rule
+func (t *Trapezoidal) Solve(stepSize, x0, y0, xEnd float64, d Drawer) error {
+	if err := checkArgs(stepSize, x0, xEnd); err != nil {
+		return err
+	}
+	nt, err := newtonOf(t.F, t.DFDY, t.Tol, t.MaxIter)
+	if err != nil {
+		return err
+	}
+
+	logger(d).Logf("[DEBUG] starting solving the equation with the trapezoidal "+
+		"method with stepsz = %.4f, tol = %v, x0 = %.4f, y0 = %.4f, xend = %.4f", stepSize, nt.tol, x0, y0, xEnd)
+
+	return solveImplicit(t.Name(), NewGrid(x0, xEnd, stepSize), 0, y0, d, t.step(nt))
+}
+
+// Resume continues the solution from the checkpoint up to xEnd
+func (t *Trapezoidal) Resume(cp Checkpoint, xEnd float64, d Drawer) error {
+	g, err := cp.grid(xEnd)
+	if err != nil {
+		return err
+	}
+	nt, err := newtonOf(t.F, t.DFDY, t.Tol, t.MaxIter)
+	if err != nil {
+		return err
+	}
+	return solveImplicit(t.Name(), g, cp.Node, cp.Y, d, t.step(nt))
+}
+
+// step returns the step of the trapezoidal rule, the half of the explicit part is the base of the equation
+func (t *Trapezoidal) step(nt newton) func(i int, x, h, y float64) (float64, error) {
+	return func(i int, x, h, y float64) (float64, error) {
+		f, err := t.F(x, y)
+		if err != nil {
+			return 0, &StepError{Method: t.Name(), Step: i, Stage: "f", X: x, Y: y, Err: err}
+		}
+		return nt.solve(t.Name(), i, x+h, h/2, y+h/2*f, y)
+	}
+}
+
+// solveImplicit solves the problem on the grid from the node, where the solution is y, each step
+// returns y at the next node
+func solveImplicit(method string, g Grid, from int, y float64, d Drawer,
+	step func(i int, x, h, y float64) (float64, error)) error {
+	out := sinkOf(method, d)
+	for i := from; i <= g.N; i++ {
+		x := g.X(i)
+		if err := out.put(i, g.Step(i), num.Point{X: x, Y: y}); err != nil {
+			return err
+		}
+		if i == g.N {
+			break // the point is the last one, so the next step is not calculated
+		}
+		var err error
+		// the last step might be shortened to end at xEnd
+		if y, err = step(i, x, g.Step(i+1), y); err != nil {
+			return out.fail(err)
+		}
+	}
+	return out.flush()
+}
+
+// newton solves equations z = base + a * f(x, z) of steps of implicit methods by Newton's method
+type newton struct {
+	f, dfdy Func
+	tol     float64
+	iters   int
+}
+
+// newtonOf makes Newton's method of the solver with defaults of tolerance and iterations
+func newtonOf(f, dfdy Func, tol float64, iters int) (newton, error) {
+	if tol == 0 {
+		tol = defaultImplicitTol
+	}
+	if iters == 0 {
+		iters = defaultImplicitIters
+	}
+	if !(tol > 0) || math.IsInf(tol, 1) {
+		return newton{}, errors.Errorf("tolerance must be positive and finite, got %v", tol)
+	}
+	if iters < 0 {
+		return newton{}, errors.Errorf("number of iterations must be positive, got %d", iters)
+	}
+	nt := newton{f: f, dfdy: dfdy, tol: tol, iters: iters}
+	if nt.dfdy == nil {
+		nt.dfdy = nt.centralDiff
+	}
+	return nt, nil
+}
+
+// centralDiff estimates df/dy by the central difference, the step is scaled to the magnitude of y
+func (nt newton) centralDiff(x, y float64) (float64, error) {
+	return centralDiff(func(v float64) (float64, error) { return nt.f(x, v) }, y)
+}
+
+// centralDiff estimates the derivative of g at v by the central difference, the step is scaled to the magnitude of v
+func centralDiff(g func(v float64) (float64, error), v float64) (float64, error) {
+	d := sensitivityEps * math.Max(1, math.Abs(v))
+	gp, err := g(v + d)
+	if err != nil {
+		return 0, err
+	}
+	gm, err := g(v - d)
+	if err != nil {
+		return 0, err
+	}
+	return (gp - gm) / (2 * d), nil
+}
+
+// solve solves the equation of the i-th step at x, starting with the guess z, the iteration, that doesn't
+// converge within iterations, or reaches the zero derivative, is ErrNotConverged
+func (nt newton) solve(method string, i int, x, a, base, z float64) (float64, error) {
+	fail := func(err error) (float64, error) {
+		return 0, &StepError{Method: method, Step: i, Stage: "newton", X: x, Y: z, Err: err}
+	}
+	for it := 1; it <= nt.iters; it++ {
+		f, err := nt.f(x, z)
+		if err != nil {
+			return fail(errors.Wrapf(err, "f at iteration %d", it))
+		}
+		df, err := nt.dfdy(x, z)
+		if err != nil {
+			return fail(errors.Wrapf(err, "df/dy at iteration %d", it))
+		}
+		dg := 1 - a*df
+		if dg == 0 || !isFinite(dg) {
+			return fail(errors.Wrapf(ErrNotConverged, "derivative of the step equation is %v at iteration %d", dg, it))
+		}
+		dz := (z - base - a*f) / dg
+		if z -= dz; !isFinite(z) {
+			return fail(errors.Wrapf(ErrNotConverged, "y is not finite at iteration %d", it))
+		}
+		if math.Abs(dz) <= nt.tol*math.Max(1, math.Abs(z)) {
+			return z, nil
+		}
+	}
+	return fail(errors.Wrapf(ErrNotConverged, "%d iterations of Newton's method", nt.iters))
+}
@@ -0,0 +1,108 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImplicit_Stiff(t *testing.T) {
+	stiff := func(_, y float64) (float64, error) { return -1000 * y, nil }
+
+	line, err := Collect(&Euler{F: stiff}, 0.01, 0, 1, 1)
+	require.NoError(t, err)
+	assert.Greater(t, math.Abs(line.Points[100].Y), 1e90, "Euler's method blows up with h|df/dy| = 10")
+
+	// backward Euler's method damps each step by 1/(1+10)
+	line, err = Collect(&BackwardEuler{F: stiff}, 0.01, 0, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, line.Points, 101)
+	for i := 1; i < len(line.Points); i++ {
+		assert.InDelta(t, line.Points[i-1].Y/11, line.Points[i].Y, 1e-12, "x=%v", line.Points[i].X)
+	}
+
+	// the trapezoidal rule multiplies each step by (1-5)/(1+5), so it decays, but oscillates
+	line, err = Collect(&Trapezoidal{F: stiff, DFDY: func(_, _ float64) (float64, error) { return -1000, nil }},
+		0.01, 0, 1, 1)
+	require.NoError(t, err)
+	for i := 1; i < len(line.Points); i++ {
+		assert.InDelta(t, -line.Points[i-1].Y*2/3, line.Points[i].Y, 1e-12, "x=%v", line.Points[i].X)
+	}
+	assert.Less(t, math.Abs(line.Points[100].Y), 1e-17)
+}
+
+func TestImplicit_Order(t *testing.T) {
+	exact := &Exact{
+		F: func(x, c float64) (float64, error) { return math.Exp(-x) / (c*math.Exp(x) + 1), nil },
+		C: func(x0, y0 float64) (float64, error) { return (math.Exp(-x0) - y0) / (y0 * math.Exp(x0)), nil },
+	}
+	for _, tt := range []struct {
+		s     Interface
+		order float64
+	}{
+		{&BackwardEuler{F: canonical}, 1},
+		{&Trapezoidal{F: canonical}, 2},
+	} {
+		c := &Collector{}
+		require.NoError(t, ConvergenceStudy(tt.s, exact, 100, 200, -4, 1, 4, c), tt.s.Name())
+		order := math.Log2(c.Points[0].Y / c.Points[100].Y)
+		assert.InDelta(t, tt.order, order, 0.2, tt.s.Name())
+	}
+
+	// the linear equation is solved by the single iteration with the exact derivative, the second one checks it
+	counting := &CountingFunc{F: func(x, y float64) (float64, error) { return x - 2*y, nil }}
+	_, err := Collect(&BackwardEuler{F: counting.Eval, DFDY: func(_, _ float64) (float64, error) { return -2, nil }},
+		0.1, 0, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 20, counting.Calls())
+}
+
+func TestImplicit_Errors(t *testing.T) {
+	stiff := func(_, y float64) (float64, error) { return -1000 * y, nil }
+	assert.EqualError(t, (&BackwardEuler{F: stiff, Tol: -1}).Solve(0.1, 0, 1, 1, &Collector{}),
+		"tolerance must be positive and finite, got -1")
+	assert.EqualError(t, (&Trapezoidal{F: stiff, MaxIter: -1}).Solve(0.1, 0, 1, 1, &Collector{}),
+		"number of iterations must be positive, got -1")
+
+	errF := errors.New("failed")
+	c := &Collector{}
+	err := (&BackwardEuler{F: func(x, y float64) (float64, error) {
+		if x > 0.25 {
+			return 0, errF
+		}
+		return stiff(x, y)
+	}}).Solve(0.1, 0, 1, 1, c)
+	assert.True(t, errors.Is(err, errF))
+	var se *StepError
+	require.True(t, errors.As(err, &se))
+	assert.Equal(t, "newton", se.Stage)
+	assert.Equal(t, 2, se.Step)
+	assert.Contains(t, err.Error(), "Backward Euler's method failed at step 2, newton at x=0.3000")
+	assert.Len(t, c.Points, 3, "points before the failure are drawn")
+
+	// the explicit part of the trapezoidal rule fails at the node itself
+	err = (&Trapezoidal{F: func(x, y float64) (float64, error) { return 0, errF }}).Solve(0.1, 0, 1, 1, &Collector{})
+	require.True(t, errors.As(err, &se))
+	assert.Equal(t, "f", se.Stage)
+
+	// y' = y^2 from 1 has the pole at x=1, the step over it has no solution
+	err = (&BackwardEuler{F: func(_, y float64) (float64, error) { return y * y, nil }}).Solve(0.5, 0, 1, 1, &Collector{})
+	assert.True(t, errors.Is(err, ErrNotConverged), err)
+
+	err = (&BackwardEuler{F: canonical, MaxIter: 1, Tol: 1e-15}).Solve(0.1, 0, 1, 1, &Collector{})
+	assert.True(t, errors.Is(err, ErrNotConverged), err)
+	assert.Contains(t, err.Error(), "1 iterations of Newton's method")
+
+	// the derivative of the step equation 1 - h*df/dy vanishes
+	err = (&BackwardEuler{F: stiff, DFDY: func(_, _ float64) (float64, error) { return 10, nil }}).
+		Solve(0.1, 0, 1, 1, &Collector{})
+	assert.True(t, errors.Is(err, ErrNotConverged), err)
+	assert.Contains(t, err.Error(), "derivative of the step equation is 0 at iteration 1")
+}
+
+func BenchmarkBackwardEuler(b *testing.B) { benchSolver(b, &BackwardEuler{F: benchF}) }
+
+func BenchmarkTrapezoidal(b *testing.B) { benchSolver(b, &Trapezoidal{F: benchF}) }
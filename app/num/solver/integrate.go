@@ -0,0 +1,64 @@
+package solver
+
+import (
+	"math"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// Builder makes the solver of the equation with the right-hand side f
+type Builder func(f Func) Interface
+
+// Integrate calculates the definite integral of g over [a, b] with n steps as the solution of y' = g(x), y(a) = 0
+// at b, the solver is made by the method, e.g. Runge-Kutta's method gives Simpson's rule, the integral over
+// the reversed interval is the negated integral over [b, a], as solvers go only forward
+func Integrate(g func(x float64) (float64, error), a, b float64, n int, method Builder) (float64, error) {
+	if a == b {
+		return 0, nil
+	}
+	if b < a {
+		res, err := Integrate(g, b, a, n, method)
+		return -res, err
+	}
+	h, err := num.CalculateStepSize(n, a, b)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to calculate step")
+	}
+
+	f := func(x, _ float64) (float64, error) { return g(x) }
+	var last num.Point
+	d := DrawerFunc(func(p num.Point) error {
+		last = p
+		return nil
+	})
+	if err = method(f).Solve(h, a, 0, b, d); err != nil {
+		return 0, errors.Wrap(err, "failed to integrate")
+	}
+	return last.Y, nil
+}
+
+// IntegrateTol calculates the definite integral of g over [a, b] as Integrate does, doubling the number of steps
+// from n, until two integrals differ by at most tol, but the number of steps doesn't exceed maxN, the integral
+// with the larger number of steps is returned along with the number, as there is no solver with the adaptive step
+func IntegrateTol(g func(x float64) (float64, error), a, b float64, n, maxN int, tol float64,
+	method Builder) (res float64, steps int, err error) {
+	if !(tol > 0) {
+		return 0, 0, errors.Errorf("tolerance must be positive, got %v", tol)
+	}
+	prev, err := Integrate(g, a, b, n, method)
+	if err != nil {
+		return 0, 0, err
+	}
+	for n*2 <= maxN {
+		n *= 2
+		if res, err = Integrate(g, a, b, n, method); err != nil {
+			return 0, 0, err
+		}
+		if math.Abs(res-prev) <= tol {
+			return res, n, nil
+		}
+		prev = res
+	}
+	return 0, 0, errors.Errorf("integral is not converged to %v with %d steps, the last estimate is %v", tol, n, prev)
+}
@@ -0,0 +1,67 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var integrand = func(x float64) (float64, error) { return math.Sin(x), nil }
+
+func rk4Builder(f Func) Interface { return &RungeKutta{F: f} }
+
+func TestIntegrate(t *testing.T) {
+	res, err := Integrate(integrand, 0, math.Pi, 100, rk4Builder)
+	require.NoError(t, err)
+	assert.InDelta(t, 2, res, 1e-8)
+
+	res, err = Integrate(integrand, math.Pi, 0, 100, rk4Builder)
+	require.NoError(t, err)
+	assert.InDelta(t, -2, res, 1e-8, "the reversed interval negates the integral")
+
+	res, err = Integrate(integrand, 1, 1, 100, rk4Builder)
+	require.NoError(t, err)
+	assert.Zero(t, res)
+
+	// the error of Euler's method is of the first order
+	res, err = Integrate(integrand, 0, math.Pi, 1000, func(f Func) Interface { return &Euler{F: f} })
+	require.NoError(t, err)
+	assert.InDelta(t, 2, res, 1e-2)
+
+	_, err = Integrate(integrand, 0, 1, 0, rk4Builder)
+	assert.EqualError(t, err, "failed to calculate step: number of steps must be positive, got 0")
+
+	errG := errors.New("g failed")
+	_, err = Integrate(func(x float64) (float64, error) { return 0, errG }, 0, 1, 10, rk4Builder)
+	assert.True(t, errors.Is(err, errG))
+}
+
+func TestIntegrate_Order(t *testing.T) {
+	// errors of Runge-Kutta's method decrease 16 times with each doubling of the number of steps
+	var prev float64
+	for n := 4; n <= 64; n *= 2 {
+		res, err := Integrate(integrand, 0, math.Pi, n, rk4Builder)
+		require.NoError(t, err)
+		e := math.Abs(res - 2)
+		if prev != 0 {
+			assert.InDelta(t, 4, math.Log2(prev/e), 0.05, "n=%d", n)
+		}
+		prev = e
+	}
+}
+
+func TestIntegrateTol(t *testing.T) {
+	res, n, err := IntegrateTol(integrand, 0, math.Pi, 4, 1<<16, 1e-10, rk4Builder)
+	require.NoError(t, err)
+	assert.InDelta(t, 2, res, 1e-10)
+	assert.Equal(t, 512, n)
+
+	_, _, err = IntegrateTol(integrand, 0, math.Pi, 4, 16, 1e-10, rk4Builder)
+	assert.Contains(t, err.Error(), "integral is not converged to 1e-10 with 16 steps")
+
+	_, _, err = IntegrateTol(integrand, 0, math.Pi, 4, 16, 0, rk4Builder)
+	assert.EqualError(t, err, "tolerance must be positive, got 0")
+}
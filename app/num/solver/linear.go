@@ -0,0 +1,86 @@
+package solver
+
+import (
+	"math"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ExactLinear makes the exact solution of the linear equation y' + p(x)y = q(x) by the integrating factor
+// mu(x) = exp(P(x)), P(x) = int_a^x p(t)dt, so y(x) = (c + Q(x)) / mu(x), Q(x) = int_a^x mu(t)q(t)dt, and
+// c = y0 mu(x0) - Q(x0). Integrals are calculated by the quadrature with the step h at most, continued from
+// the last evaluated x, if it is between a and the next one, so the solution on the grid takes a single pass
+func ExactLinear(p, q func(x float64) (float64, error), a, h float64) *Exact {
+	l := &linearExact{p: p, q: q, a: a, h: h, last: a}
+	return &Exact{
+		F: func(x, c float64) (float64, error) {
+			pi, qi, err := l.integrals(x)
+			if err != nil {
+				return 0, err
+			}
+			return (c + qi) / math.Exp(pi), nil
+		},
+		C: func(x0, y0 float64) (float64, error) {
+			pi, qi, err := l.integrals(x0)
+			if err != nil {
+				return 0, err
+			}
+			return y0*math.Exp(pi) - qi, nil
+		},
+	}
+}
+
+// linearExact calculates integrals P and Q of the linear equation, it is safe for concurrent use
+type linearExact struct {
+	p, q func(x float64) (float64, error)
+	a, h float64
+
+	mu           sync.Mutex
+	last         float64 // the last evaluated x
+	lastP, lastQ float64 // integrals at last
+}
+
+// integrals returns P(x) and Q(x), integrated by Simpson's rule from a or from the last evaluated x
+func (l *linearExact) integrals(x float64) (pi, qi float64, err error) {
+	if !(l.h > 0) {
+		return 0, 0, errors.Errorf("step of the quadrature must be positive, got %v", l.h)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	from, pi, qi := l.a, 0.0, 0.0
+	if (l.last-l.a)*(x-l.last) >= 0 { // x is beyond the last one on the same side of a
+		from, pi, qi = l.last, l.lastP, l.lastQ
+	}
+	n := int(math.Ceil(math.Abs(x-from) / l.h))
+	for i := 0; i < n; i++ {
+		t0 := from + (x-from)*float64(i)/float64(n)
+		t1 := from + (x-from)*float64(i+1)/float64(n)
+		if pi, qi, err = l.step(t0, t1, pi, qi); err != nil {
+			return 0, 0, err
+		}
+	}
+	l.last, l.lastP, l.lastQ = x, pi, qi
+	return pi, qi, nil
+}
+
+// step integrates P and Q from t0 to t1 by Simpson's rule, P at the midpoint is integrated by the parabola
+// through values of p at the ends and the midpoint
+func (l *linearExact) step(t0, t1, pi, qi float64) (float64, float64, error) {
+	h, tm := t1-t0, t0+(t1-t0)/2
+	var ps, qs [3]float64
+	for i, t := range [3]float64{t0, tm, t1} {
+		var err error
+		if ps[i], err = l.p(t); err != nil {
+			return 0, 0, errors.Wrapf(err, "failed to calculate p at x=%v", t)
+		}
+		if qs[i], err = l.q(t); err != nil {
+			return 0, 0, errors.Wrapf(err, "failed to calculate q at x=%v", t)
+		}
+	}
+	pm := pi + h/24*(5*ps[0]+8*ps[1]-ps[2])
+	p1 := pi + h/6*(ps[0]+4*ps[1]+ps[2])
+	q1 := qi + h/6*(math.Exp(pi)*qs[0]+4*math.Exp(pm)*qs[1]+math.Exp(p1)*qs[2])
+	return p1, q1, nil
+}
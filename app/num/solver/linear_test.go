@@ -0,0 +1,56 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExactLinear(t *testing.T) {
+	// y' + 2xy = x, y = 1/2 + (y0 - 1/2) exp(-x^2) from y(0) = y0
+	p := func(x float64) (float64, error) { return 2 * x, nil }
+	q := func(x float64) (float64, error) { return x, nil }
+	exact := ExactLinear(p, q, 0, 0.01)
+	line, err := Collect(exact, 0.1, 0, 2, 2)
+	require.NoError(t, err)
+	require.Len(t, line.Points, 21)
+	for _, pt := range line.Points {
+		assert.InDelta(t, 0.5+1.5*math.Exp(-pt.X*pt.X), pt.Y, 1e-8, "x=%v", pt.X)
+	}
+
+	// the initial point differs from the reference one, x is evaluated back and forth
+	c, err := exact.Constant(1, 1)
+	require.NoError(t, err)
+	for _, x := range []float64{1.5, 0.5, -1, 1} {
+		y, err := exact.F(x, c)
+		require.NoError(t, err)
+		assert.InDelta(t, 0.5+0.5*math.Exp(1-x*x), y, 1e-8, "x=%v", x)
+	}
+
+	// y' = x^2 - 2y, p = 2 and q = x^2, y = x^2/2 - x/2 + 1/4 + 3/4 exp(-2x) from y(0) = 1
+	exact = ExactLinear(func(float64) (float64, error) { return 2, nil },
+		func(x float64) (float64, error) { return x * x, nil }, 0, 0.01)
+	line, err = Collect(exact, 0.1, 0, 1, 1)
+	require.NoError(t, err)
+	for _, pt := range line.Points {
+		x := pt.X
+		assert.InDelta(t, x*x/2-x/2+0.25+0.75*math.Exp(-2*x), pt.Y, 1e-8, "x=%v", x)
+	}
+
+	errQ := errors.New("q failed")
+	exact = ExactLinear(p, func(x float64) (float64, error) {
+		if x > 1 {
+			return 0, errQ
+		}
+		return x, nil
+	}, 0, 0.01)
+	_, err = Collect(exact, 0.1, 0, 2, 2)
+	assert.True(t, errors.Is(err, errQ), "%v", err)
+	assert.Contains(t, err.Error(), "failed to calculate q at x=")
+
+	_, err = ExactLinear(p, q, 0, 0).Constant(0, 1)
+	assert.Contains(t, err.Error(), "step of the quadrature must be positive, got 0")
+}
@@ -0,0 +1,357 @@
+package solver
+
+import (
+	"context"
+	"time"
+
+	"github.com/Semior001/decompract/app/num"
+	log "github.com/go-pkgz/lgr"
+)
+
+// hook is called around each call to the wrapped drawer, c is the call, that passes the point to it
+type hook func(c call) error
+
+// call is the pending call to the wrapped drawer, hooks get it by value, so drawing the point
+// through the chain of wrapped drawers doesn't allocate
+type call struct {
+	next  Drawer
+	step  StepDrawer  // set, if the point is drawn with its step data
+	meta  MetaDrawer  // set, if the point is drawn with the metadata
+	batch BatchDrawer // set, if points of pts are drawn at once, p is the first of them
+	pts   []num.Point
+	i     int
+	h     float64
+	p     num.Point
+	m     Meta
+}
+
+// do passes the point or the batch to the wrapped drawer
+func (c call) do() error {
+	if c.batch != nil {
+		return c.batch.DrawBatch(c.pts)
+	}
+	if c.meta != nil {
+		return c.meta.DrawMeta(c.p, c.m)
+	}
+	if c.step != nil {
+		return c.step.DrawStep(c.i, c.h, c.p)
+	}
+	return c.next.Draw(c.p)
+}
+
+// wrap returns the drawer that calls h around each drawn point of d, the returned drawer implements
+// the same optional interfaces as d, but the batch one, so the hook sees points one by one
+func wrap(d Drawer, h hook) Drawer { return wrapDrawer(d, h, false) }
+
+// wrapBatches returns the drawer that calls h around each call to d, as wrap does, but batches of
+// the batch drawer are passed through, the hook is called once per batch then
+func wrapBatches(d Drawer, h hook) Drawer { return wrapDrawer(d, h, true) }
+
+// wrapDrawer wraps d with the hook, the batch drawer interface is kept, if batches are passed through,
+// and the drawer is neither the step nor the meta one, as solvers pass points to them one by one anyway
+func wrapDrawer(d Drawer, h hook, batches bool) Drawer {
+	hd := &hookedDrawer{next: d, hook: h}
+	sd, isStep := d.(StepDrawer)
+	md, isMeta := d.(MetaDrawer)
+	bd, isBatch := d.(BatchDrawer)
+	switch {
+	case isStep && isMeta:
+		return &hookedStepMetaDrawer{hookedStepDrawer: &hookedStepDrawer{hookedDrawer: hd, next: sd}, meta: md}
+	case isStep:
+		return &hookedStepDrawer{hookedDrawer: hd, next: sd}
+	case isMeta:
+		return &hookedMetaDrawer{hookedDrawer: hd, next: md}
+	case isBatch && batches:
+		return &hookedBatchDrawer{hookedDrawer: hd, next: bd}
+	}
+	return hd
+}
+
+type hookedDrawer struct {
+	next Drawer
+	hook hook
+}
+
+// unwrap returns the wrapped drawer
+func (hd *hookedDrawer) unwrap() Drawer { return hd.next }
+
+// Draw passes the point to the wrapped drawer through the hook
+func (hd *hookedDrawer) Draw(p num.Point) error {
+	return hd.hook(call{next: hd.next, p: p})
+}
+
+type hookedStepDrawer struct {
+	*hookedDrawer
+	next StepDrawer
+}
+
+// DrawStep passes the point with its step data to the wrapped drawer through the hook
+func (hd *hookedStepDrawer) DrawStep(i int, h float64, p num.Point) error {
+	return hd.hook(call{next: hd.next, step: hd.next, i: i, h: h, p: p})
+}
+
+// drawMeta passes the point with the metadata to the wrapped drawer through the hook, rejected steps
+// are not points of the solution, so they bypass the hook
+func (hd *hookedDrawer) drawMeta(md MetaDrawer, p num.Point, m Meta) error {
+	if m.Rejected {
+		return md.DrawMeta(p, m)
+	}
+	return hd.hook(call{next: hd.next, meta: md, i: m.Step, h: m.H, p: p, m: m})
+}
+
+type hookedMetaDrawer struct {
+	*hookedDrawer
+	next MetaDrawer
+}
+
+// DrawMeta passes the point with the metadata to the wrapped drawer through the hook
+func (hd *hookedMetaDrawer) DrawMeta(p num.Point, m Meta) error { return hd.drawMeta(hd.next, p, m) }
+
+type hookedStepMetaDrawer struct {
+	*hookedStepDrawer
+	meta MetaDrawer
+}
+
+// DrawMeta passes the point with the metadata to the wrapped drawer through the hook
+func (hd *hookedStepMetaDrawer) DrawMeta(p num.Point, m Meta) error {
+	return hd.drawMeta(hd.meta, p, m)
+}
+
+type hookedBatchDrawer struct {
+	*hookedDrawer
+	next BatchDrawer
+}
+
+// DrawBatch passes the batch to the wrapped drawer through the hook, the hook is called once per batch
+func (hd *hookedBatchDrawer) DrawBatch(pts []num.Point) error {
+	if len(pts) == 0 {
+		return nil
+	}
+	return hd.hook(call{next: hd.next, batch: hd.next, pts: pts, p: pts[0]})
+}
+
+// WithLogging wraps the drawer to log each every-th point at DEBUG level
+func WithLogging(d Drawer, l log.L, every int) Drawer {
+	if every < 1 {
+		every = 1
+	}
+	cnt := 0
+	logPoint := func(p num.Point) {
+		// arguments of the message escape, so they are made only for the logged points
+		if cnt%every == 0 {
+			l.Logf("[DEBUG] drawing point #%d %s", cnt, p)
+		}
+		cnt++
+	}
+	return wrapBatches(d, func(c call) error {
+		if c.batch == nil {
+			logPoint(c.p)
+			return c.do()
+		}
+		for _, p := range c.pts {
+			logPoint(p)
+		}
+		return c.do()
+	})
+}
+
+// WithLogger wraps the drawer to make solvers log with the given logger instead of the default one,
+// e.g. to tag messages with the id of the request
+func WithLogger(d Drawer, l log.L) Drawer {
+	ld := &loggedDrawer{Drawer: d, l: l}
+	ld.step, _ = d.(StepDrawer)
+	if md, ok := d.(MetaDrawer); ok {
+		return &loggedMetaDrawer{loggedDrawer: ld, meta: md}
+	}
+	if ld.step != nil {
+		return &loggedStepDrawer{ld}
+	}
+	if bd, ok := d.(BatchDrawer); ok {
+		return &loggedBatchDrawer{loggedDrawer: ld, batch: bd}
+	}
+	return ld
+}
+
+type loggedDrawer struct {
+	Drawer
+	step StepDrawer // the wrapped drawer, if it wants step data
+	l    log.L
+}
+
+// unwrap returns the wrapped drawer
+func (ld *loggedDrawer) unwrap() Drawer { return ld.Drawer }
+
+// logs returns the logger of the drawer
+func (ld *loggedDrawer) logs() log.L { return ld.l }
+
+// drawStep passes the point with its step data to the wrapped drawer
+func (ld *loggedDrawer) drawStep(i int, h float64, p num.Point) error {
+	if ld.step != nil {
+		return ld.step.DrawStep(i, h, p)
+	}
+	return ld.Drawer.Draw(p)
+}
+
+type loggedStepDrawer struct{ *loggedDrawer }
+
+// DrawStep passes the point with its step data to the wrapped drawer
+func (ld *loggedStepDrawer) DrawStep(i int, h float64, p num.Point) error {
+	return ld.drawStep(i, h, p)
+}
+
+type loggedBatchDrawer struct {
+	*loggedDrawer
+	batch BatchDrawer
+}
+
+// DrawBatch passes the batch to the wrapped drawer
+func (ld *loggedBatchDrawer) DrawBatch(pts []num.Point) error { return ld.batch.DrawBatch(pts) }
+
+type loggedMetaDrawer struct {
+	*loggedDrawer
+	meta MetaDrawer
+}
+
+// DrawStep passes the point with its step data to the wrapped drawer
+func (ld *loggedMetaDrawer) DrawStep(i int, h float64, p num.Point) error {
+	return ld.drawStep(i, h, p)
+}
+
+// DrawMeta passes the point with the metadata to the wrapped drawer
+func (ld *loggedMetaDrawer) DrawMeta(p num.Point, m Meta) error { return ld.meta.DrawMeta(p, m) }
+
+// logger returns the logger of the drawer, set by WithLogger anywhere in the chain of wrapped drawers,
+// the default logger is returned, if there is no one, drawers of points of any numeric type are taken
+func logger(d interface{}) log.L {
+	for d != nil {
+		if ld, ok := d.(interface{ logs() log.L }); ok {
+			return ld.logs()
+		}
+		u, ok := d.(interface{ unwrap() Drawer })
+		if !ok {
+			break
+		}
+		d = u.unwrap()
+	}
+	return log.Default()
+}
+
+// annotator returns the annotator of the drawer anywhere in the chain of wrapped drawers, nil, if there is no one
+func annotator(d Drawer) Annotator {
+	for d != nil {
+		if a, ok := d.(Annotator); ok {
+			return a
+		}
+		u, ok := d.(interface{ unwrap() Drawer })
+		if !ok {
+			break
+		}
+		d = u.unwrap()
+	}
+	return nil
+}
+
+// discontinuityDrawer returns the discontinuity drawer anywhere in the chain of wrapped drawers, nil, if there is no one
+func discontinuityDrawer(d Drawer) DiscontinuityDrawer {
+	for d != nil {
+		if dd, ok := d.(DiscontinuityDrawer); ok {
+			return dd
+		}
+		u, ok := d.(interface{ unwrap() Drawer })
+		if !ok {
+			break
+		}
+		d = u.unwrap()
+	}
+	return nil
+}
+
+// WithSkip wraps the drawer to drop the first n points of the solution, e.g. points, that are drawn
+// by the previous solution of the interval, rejected steps are not points, so they are passed through
+func WithSkip(d Drawer, n int) Drawer {
+	skipped := 0
+	return wrap(d, func(c call) error {
+		if skipped < n {
+			skipped++
+			return nil
+		}
+		return c.do()
+	})
+}
+
+// WithObserver wraps the drawer to call fn with each drawn point and the result of drawing it, the failed
+// batch is observed as the failure at its first point, as the failed point is not known
+func WithObserver(d Drawer, fn func(p num.Point, err error)) Drawer {
+	return wrapBatches(d, func(c call) error {
+		err := c.do()
+		if c.batch == nil || err != nil {
+			fn(c.p, err)
+			return err
+		}
+		for _, p := range c.pts {
+			fn(p, nil)
+		}
+		return nil
+	})
+}
+
+// WithContext wraps the drawer to stop solving with the error of the context, as soon as it is done
+func WithContext(ctx context.Context, d Drawer) Drawer {
+	// polling the channel is cheaper, than locking the context for its error on each point
+	done := ctx.Done()
+	return wrapBatches(d, func(c call) error {
+		select {
+		case <-done:
+			return ctx.Err()
+		default:
+		}
+		return c.do()
+	})
+}
+
+// ContextFunc wraps f to fail with the error of the context, as soon as it is done, so the solver, that evaluates
+// f many times between drawn points, e.g. the expensive f or the adaptive solver, that rejects steps, is stopped too
+func ContextFunc(ctx context.Context, f Func) Func {
+	done := ctx.Done()
+	return func(x, y float64) (float64, error) {
+		select {
+		case <-done:
+			return 0, ctx.Err()
+		default:
+		}
+		return f(x, y)
+	}
+}
+
+// SolveContext solves the problem with the solver until the context is done, the solver is stopped at the next
+// drawn point with the error of the context, nothing is solved, if the context is already done
+func SolveContext(ctx context.Context, s Interface, stepSize, x0, y0, xEnd float64, d Drawer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.Solve(stepSize, x0, y0, xEnd, WithContext(ctx, d))
+}
+
+// Timing describes the time spent by the drawer for drawing points
+type Timing struct {
+	Total time.Duration // total time spent in all calls
+	Count int           // number of calls, the batch of points is drawn by the single call
+	Max   time.Duration // the longest single call
+}
+
+// WithTiming wraps the drawer to measure the time spent in it,
+// the returned Timing is updated on each call
+func WithTiming(d Drawer) (*Timing, Drawer) {
+	t := &Timing{}
+	return t, wrapBatches(d, func(c call) error {
+		st := time.Now()
+		err := c.do()
+		since := time.Since(st)
+		t.Total += since
+		t.Count++
+		if since > t.Max {
+			t.Max = since
+		}
+		return err
+	})
+}
@@ -0,0 +1,282 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Semior001/decompract/app/num"
+
+	log "github.com/go-pkgz/lgr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stepCollector struct {
+	Collector
+	idx []int
+	hs  []float64
+}
+
+func (s *stepCollector) DrawStep(i int, h float64, p num.Point) error {
+	s.idx = append(s.idx, i)
+	s.hs = append(s.hs, h)
+	return s.Draw(p)
+}
+
+func TestWithLogging(t *testing.T) {
+	var logged []string
+	l := log.Func(func(format string, args ...interface{}) { logged = append(logged, fmt.Sprintf(format, args...)) })
+
+	c := &Collector{}
+	d := WithLogging(c, l, 3)
+	_, isStep := d.(StepDrawer)
+	assert.False(t, isStep, "plain drawer must not become a step drawer")
+
+	err := (&Euler{F: func(x, y float64) (float64, error) { return x*x - 2.0*y, nil }}).Solve(0.1, 0, 1, 1, d)
+	require.NoError(t, err)
+	assert.Len(t, c.Points, 11)
+	assert.Equal(t, []string{
+		"[DEBUG] drawing point #0 (0.0000, 1.0000)",
+		"[DEBUG] drawing point #3 (0.3000, 0.5168)",
+		"[DEBUG] drawing point #6 (0.6000, 0.3082)",
+		"[DEBUG] drawing point #9 (0.9000, 0.2840)",
+	}, logged)
+}
+
+func TestWithTiming(t *testing.T) {
+	sc := &stepCollector{}
+	tm, d := WithTiming(sc)
+	_, isStep := d.(StepDrawer)
+	require.True(t, isStep, "step drawer interface must be preserved")
+
+	err := (&RungeKutta{F: func(x, y float64) (float64, error) { return x*x - 2.0*y, nil }}).Solve(0.1, 0, 1, 1, d)
+	require.NoError(t, err)
+	assert.Equal(t, 11, tm.Count)
+	assert.Len(t, sc.Points, 11)
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, sc.idx)
+	assert.Equal(t, 0.0, sc.hs[0])
+	assert.Equal(t, 0.1, sc.hs[1])
+	assert.True(t, tm.Max <= tm.Total)
+}
+
+func TestMiddleware_ErrorPropagation(t *testing.T) {
+	errDraw := errors.New("draw failed")
+	inner := DrawerFunc(func(p num.Point) error {
+		if p.X > 0.5 {
+			return errDraw
+		}
+		return nil
+	})
+
+	tm, d := WithTiming(WithLogging(inner, log.NoOp, 1))
+	assert.NoError(t, d.Draw(num.Point{X: 0.1}))
+	assert.Equal(t, errDraw, d.Draw(num.Point{X: 0.6}))
+	assert.Equal(t, 2, tm.Count)
+
+	err := (&Euler{F: func(x, y float64) (float64, error) { return 0, nil }}).Solve(0.1, 0, 1, 1, d)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, errDraw), "solver must keep the drawer's error")
+}
+
+func TestWithObserver(t *testing.T) {
+	sc := &stepCollector{}
+	var observed []num.Point
+	d := WithObserver(sc, func(p num.Point, err error) {
+		assert.NoError(t, err)
+		observed = append(observed, p)
+	})
+	_, isStep := d.(StepDrawer)
+	require.True(t, isStep, "step drawer interface must be preserved")
+
+	err := (&Euler{F: func(x, y float64) (float64, error) { return 1, nil }}).Solve(0.5, 0, 0, 1, d)
+	require.NoError(t, err)
+	assert.Equal(t, []num.Point{{X: 0, Y: 0}, {X: 0.5, Y: 0.5}, {X: 1, Y: 1}}, observed)
+	assert.Equal(t, observed, sc.Points)
+
+	errDraw := errors.New("draw failed")
+	var observedErr error
+	d = WithObserver(DrawerFunc(func(num.Point) error { return errDraw }), func(_ num.Point, err error) { observedErr = err })
+	assert.Equal(t, errDraw, d.Draw(num.Point{}))
+	assert.Equal(t, errDraw, observedErr)
+}
+
+// pointCollector collects points, drawn one by one, it is not the batch drawer
+type pointCollector struct{ Points []num.Point }
+
+func (c *pointCollector) Draw(p num.Point) error {
+	c.Points = append(c.Points, p)
+	return nil
+}
+
+func TestWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &pointCollector{}
+	d := WithContext(ctx, c)
+
+	err := (&Euler{F: func(x, y float64) (float64, error) {
+		if x >= 0.2 {
+			cancel()
+		}
+		return 1, nil
+	}}).Solve(0.1, 0, 0, 1, d)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled), "solver must keep the context's error")
+	assert.Len(t, c.Points, 3)
+}
+
+func TestSolveContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &pointCollector{}
+	err := SolveContext(ctx, &Euler{F: func(x, y float64) (float64, error) {
+		if x >= 0.2 {
+			cancel()
+		}
+		return 1, nil
+	}}, 0.1, 0, 0, 1, c)
+	assert.True(t, errors.Is(err, context.Canceled), err)
+	assert.Len(t, c.Points, 3)
+
+	c = &pointCollector{}
+	assert.Equal(t, context.Canceled, SolveContext(ctx, &Euler{F: benchF}, 0.1, 0, 0, 1, c))
+	assert.Empty(t, c.Points, "nothing is solved with the done context")
+
+	// the adaptive solver, that never takes the step, is stopped by f
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	f := ContextFunc(ctx, func(x, y float64) (float64, error) {
+		time.Sleep(time.Millisecond)
+		return 1 / (x - 0.5), nil
+	})
+	c = &pointCollector{}
+	err = SolveContext(ctx, &RKF45{F: f, Tol: 1e-300}, 0.1, 0, 1, 1, c)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+	var se *StepError
+	require.True(t, errors.As(err, &se))
+	assert.Len(t, c.Points, 1)
+}
+
+func TestWithLogger(t *testing.T) {
+	var logged []string
+	l := log.Func(func(format string, args ...interface{}) { logged = append(logged, fmt.Sprintf(format, args...)) })
+	f := func(x, y float64) (float64, error) { return 1, nil }
+
+	// the logger is found through the other wrappers
+	sc := &stepCollector{}
+	d := WithContext(context.Background(), WithLogger(WithObserver(sc, func(num.Point, error) {}), l))
+	for _, slvr := range []Interface{&Euler{F: f}, &ImprovedEuler{F: f}, &RungeKutta{F: f}} {
+		logged = nil
+		require.NoError(t, slvr.Solve(0.5, 0, 0, 1, d))
+		require.Len(t, logged, 1)
+		assert.Contains(t, logged[0], "[DEBUG] starting solving the equation with "+slvr.Name())
+	}
+	assert.Equal(t, []int{0, 1, 2, 0, 1, 2, 0, 1, 2}, sc.idx, "step data must be passed through")
+
+	assert.Equal(t, log.Default(), logger(WithContext(context.Background(), &Collector{})))
+	assert.Equal(t, log.Default(), logger(DrawerFunc(func(num.Point) error { return nil })))
+}
+
+// stepMetaCollector is the meta drawer, that wants step data too
+type stepMetaCollector struct {
+	metaCollector
+	steps int
+}
+
+func (s *stepMetaCollector) DrawStep(_ int, _ float64, p num.Point) error {
+	s.steps++
+	return s.Draw(p)
+}
+
+func TestMiddleware_MetaDrawer(t *testing.T) {
+	var logged []string
+	l := log.Func(func(format string, args ...interface{}) { logged = append(logged, fmt.Sprintf(format, args...)) })
+	exp := func(x, y float64) (float64, error) { return y, nil }
+
+	for _, mc := range []interface {
+		MetaDrawer
+		accepted() []Meta
+	}{&metaCollector{}, &stepMetaCollector{}} {
+		var observed []num.Point
+		d := WithObserver(WithLogger(WithContext(context.Background(), mc), l), func(p num.Point, err error) {
+			observed = append(observed, p)
+		})
+		_, isMeta := d.(MetaDrawer)
+		require.True(t, isMeta, "meta drawer interface must be preserved")
+		_, isStep := WithContext(context.Background(), mc).(StepDrawer)
+		_, wantStep := mc.(StepDrawer)
+		assert.Equal(t, wantStep, isStep, "step drawer interface must be preserved")
+
+		logged = nil
+		require.NoError(t, (&RKF45{F: exp, Tol: 1e-8}).Solve(1, 0, 1, 2, d))
+		require.NotEmpty(t, logged, "the logger is found through the meta drawer")
+		assert.Len(t, observed, len(mc.accepted()), "rejected steps bypass hooks")
+	}
+
+	sc := &stepMetaCollector{}
+	_, td := WithTiming(sc)
+	require.NoError(t, (&Euler{F: exp}).Solve(0.5, 0, 1, 1, td))
+	assert.Zero(t, sc.steps, "points are passed by DrawMeta")
+	assert.Len(t, sc.Points, 3)
+}
+
+// batchCounter collects points by batches and counts them
+type batchCounter struct {
+	Collector
+	batches int
+}
+
+func (b *batchCounter) DrawBatch(pts []num.Point) error {
+	b.batches++
+	return b.Collector.DrawBatch(pts)
+}
+
+func TestMiddleware_BatchDrawer(t *testing.T) {
+	var logged []string
+	l := log.Func(func(format string, args ...interface{}) { logged = append(logged, fmt.Sprintf(format, args...)) })
+	n := 3*batchSize + 10
+
+	bc := &batchCounter{}
+	observed := 0
+	timing, d := WithTiming(WithObserver(WithLogging(WithLogger(WithContext(context.Background(), bc), l), l, 1000),
+		func(_ num.Point, err error) {
+			require.NoError(t, err)
+			observed++
+		}))
+	_, isBatch := d.(BatchDrawer)
+	require.True(t, isBatch, "batch drawer interface must be preserved")
+	require.NoError(t, (&RungeKutta{F: benchF}).Solve(1.0/float64(n), 0, 1, 1, d))
+	assert.Len(t, bc.Points, n+1)
+	assert.Equal(t, 4, bc.batches, "points are passed by batches through all wrappers")
+	assert.Equal(t, n+1, observed, "each point of batches is observed")
+	assert.Equal(t, 4, timing.Count, "the batch is drawn by the single call")
+	assert.Len(t, logged, 1+4, "the logger is found and each 1000th point is logged")
+
+	// the step drawer keeps getting points one by one
+	_, isBatch = WithContext(context.Background(), &stepCollector{}).(BatchDrawer)
+	assert.False(t, isBatch)
+
+	// the context is checked once per batch, nothing of the batch is drawn after it is done
+	ctx, cancel := context.WithCancel(context.Background())
+	bc = &batchCounter{}
+	err := (&Euler{F: func(x, y float64) (float64, error) {
+		if x >= 0.2 {
+			cancel()
+		}
+		return 1, nil
+	}}).Solve(0.1, 0, 0, 1, WithContext(ctx, bc))
+	assert.True(t, errors.Is(err, context.Canceled), err)
+	assert.Empty(t, bc.Points)
+
+	// the failed batch is observed at its first point
+	var failedAt []num.Point
+	fb := &failingBatch{failAt: 5}
+	err = (&Euler{F: benchF}).Solve(0.1, 0, 1, 1, WithObserver(fb, func(p num.Point, err error) {
+		if err != nil {
+			failedAt = append(failedAt, p)
+		}
+	}))
+	require.Error(t, err)
+	assert.Equal(t, []num.Point{{X: 0, Y: 1}}, failedAt)
+}
@@ -0,0 +1,111 @@
+package solver
+
+import (
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// maxSamples is the limit of the number of samples of the Monte Carlo propagation
+const maxSamples = 1 << 20
+
+// Band is the distribution of solutions from random initial values at nodes of the grid, mean and
+// standard deviation are aligned with nodes, quantiles are calculated from kept samples by Quantile
+type Band struct {
+	Mean    []num.Point
+	Std     []num.Point
+	Samples int
+
+	nodes [][]float64 // sorted values of solutions at each node
+}
+
+// MonteCarlo propagates the uncertainty of the initial value to the solution, it solves the problem
+// by the method for each of samples of y0 from y0Dist on the grid of the step, samples are drawn from
+// the source with the seed in order, so the band is deterministic given the seed, and solved concurrently
+// by GOMAXPROCS workers at most, the failure of any sample fails the propagation
+func MonteCarlo(method Builder, f Func, step, x0, xEnd float64, y0Dist func(*rand.Rand) float64,
+	samples int, seed int64) (*Band, error) {
+	if samples < 2 || samples > maxSamples {
+		return nil, errors.Errorf("number of samples must be between 2 and %d, got %d", maxSamples, samples)
+	}
+	n, err := StepsCount(step, x0, xEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	rnd := rand.New(rand.NewSource(seed))
+	y0s := make([]float64, samples)
+	for i := range y0s {
+		y0s[i] = y0Dist(rnd)
+	}
+
+	sols := make([][]num.Point, samples)
+	errs := make([]error, samples)
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	for i := range y0s {
+		i := i
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem }()
+			defer wg.Done()
+			c := &Collector{}
+			c.Expect(n)
+			if errs[i] = method(f).Solve(step, x0, y0s[i], xEnd, c); errs[i] == nil {
+				errs[i] = c.Check()
+			}
+			sols[i] = c.Points
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to solve sample %d with y0=%v", i, y0s[i])
+		}
+	}
+
+	b := &Band{Mean: make([]num.Point, n), Std: make([]num.Point, n), Samples: samples, nodes: make([][]float64, n)}
+	for j := 0; j < n; j++ {
+		vals := make([]float64, samples)
+		mean := 0.0
+		for i, sol := range sols {
+			vals[i] = sol[j].Y
+			mean += vals[i]
+		}
+		mean /= float64(samples)
+		ss := 0.0
+		for _, v := range vals {
+			ss += (v - mean) * (v - mean)
+		}
+		sort.Float64s(vals)
+		x := sols[0][j].X
+		b.Mean[j] = num.Point{X: x, Y: mean}
+		b.Std[j] = num.Point{X: x, Y: math.Sqrt(ss / float64(samples-1))}
+		b.nodes[j] = vals
+	}
+	return b, nil
+}
+
+// Quantile returns the q-th quantile of solutions at each node, linearly interpolated between samples
+func (b *Band) Quantile(q float64) ([]num.Point, error) {
+	if !(q >= 0 && q <= 1) {
+		return nil, errors.Errorf("quantile must be between 0 and 1, got %v", q)
+	}
+	res := make([]num.Point, len(b.nodes))
+	for j, vals := range b.nodes {
+		pos := q * float64(len(vals)-1)
+		i := int(math.Floor(pos))
+		y := vals[i]
+		if i+1 < len(vals) {
+			y += (pos - float64(i)) * (vals[i+1] - vals[i])
+		}
+		res[j] = num.Point{X: b.Mean[j].X, Y: y}
+	}
+	return res, nil
+}
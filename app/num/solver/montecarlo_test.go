@@ -0,0 +1,77 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonteCarlo(t *testing.T) {
+	// the solution of y' = x^2 - 2y is linear in y0, y(x) = x^2/2 - x/2 + 1/4 + (y0 - 1/4) exp(-2x),
+	// so y0 ~ N(1, 0.1^2) gives y(x) ~ N(y(x; 1), (0.1 exp(-2x))^2)
+	const samples, sigma = 4000, 0.1
+	dist := func(r *rand.Rand) float64 { return 1 + sigma*r.NormFloat64() }
+	b, err := MonteCarlo(rk4Builder, linearF, 0.1, 0, 1, dist, samples, 42)
+	require.NoError(t, err)
+	assert.Equal(t, samples, b.Samples)
+	require.Len(t, b.Mean, 11)
+	require.Len(t, b.Std, 11)
+
+	median, err := b.Quantile(0.5)
+	require.NoError(t, err)
+	upper, err := b.Quantile(0.975)
+	require.NoError(t, err)
+	for i, m := range b.Mean {
+		x := m.X
+		exact := x*x/2 - x/2 + 0.25 + 0.75*math.Exp(-2*x)
+		std := sigma * math.Exp(-2*x)
+		// errors of the sample mean and of the sample deviation are within four standard errors
+		se := std / math.Sqrt(samples)
+		assert.InDelta(t, exact, m.Y, 4*se+1e-5, "mean at x=%v", x)
+		assert.InDelta(t, std, b.Std[i].Y, 4*std/math.Sqrt(2*samples), "std at x=%v", x)
+		assert.InDelta(t, exact, median[i].Y, 6*se+1e-5, "median at x=%v", x)
+		assert.InDelta(t, exact+1.96*std, upper[i].Y, 0.1*std, "97.5%% quantile at x=%v", x)
+	}
+
+	// the band is deterministic given the seed
+	again, err := MonteCarlo(rk4Builder, linearF, 0.1, 0, 1, dist, samples, 42)
+	require.NoError(t, err)
+	assert.Equal(t, b.Mean, again.Mean)
+	assert.Equal(t, b.Std, again.Std)
+	other, err := MonteCarlo(rk4Builder, linearF, 0.1, 0, 1, dist, samples, 43)
+	require.NoError(t, err)
+	assert.NotEqual(t, b.Mean, other.Mean)
+}
+
+func TestMonteCarlo_Errors(t *testing.T) {
+	dist := func(r *rand.Rand) float64 { return r.Float64() - 0.5 }
+	_, err := MonteCarlo(rk4Builder, linearF, 0.1, 0, 1, dist, 1, 0)
+	assert.EqualError(t, err, "number of samples must be between 2 and 1048576, got 1")
+
+	errF := errors.New("f failed")
+	_, err = MonteCarlo(rk4Builder, func(x, y float64) (float64, error) {
+		if y < 0 {
+			return 0, errF
+		}
+		return 0, nil
+	}, 0.1, 0, 1, dist, 100, 0)
+	assert.True(t, errors.Is(err, errF))
+	assert.Contains(t, err.Error(), "failed to solve sample")
+
+	b, err := MonteCarlo(rk4Builder, linearF, 0.1, 0, 1, dist, 10, 0)
+	require.NoError(t, err)
+	_, err = b.Quantile(1.5)
+	assert.EqualError(t, err, "quantile must be between 0 and 1, got 1.5")
+	lo, err := b.Quantile(0)
+	require.NoError(t, err)
+	hi, err := b.Quantile(1)
+	require.NoError(t, err)
+	for i := range lo {
+		assert.LessOrEqual(t, lo[i].Y, b.Mean[i].Y)
+		assert.GreaterOrEqual(t, hi[i].Y, b.Mean[i].Y)
+	}
+}
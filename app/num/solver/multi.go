@@ -0,0 +1,148 @@
+package solver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// MultiError lists failures of drawers of MultiCollect by their indices in the arguments
+type MultiError struct {
+	Errs map[int]error
+}
+
+// Error returns failures in the order of drawers
+func (e *MultiError) Error() string {
+	idxs := make([]int, 0, len(e.Errs))
+	for i := range e.Errs {
+		idxs = append(idxs, i)
+	}
+	sort.Ints(idxs)
+	msgs := make([]string, 0, len(idxs))
+	for _, i := range idxs {
+		msgs = append(msgs, fmt.Sprintf("drawer #%d: %v", i, e.Errs[i]))
+	}
+	return fmt.Sprintf("%d drawers failed, %s", len(idxs), strings.Join(msgs, "; "))
+}
+
+// Err returns the error, nil if no drawer failed
+func (e *MultiError) Err() error {
+	if len(e.Errs) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Multi returns the drawer, that passes each point to all drawers in the order of arguments, so the single solve
+// feeds e.g. the chart, the csv and the stats at once. Multi fails fast: the first failure of the drawer stops
+// the solution with its error, annotated by the index of the drawer, the point is not passed to the rest of drawers.
+// The drawer receives step data, if any of drawers wants it, and batches, if all of drawers accept them,
+// then drawers before the failed one have drawn the whole batch
+func Multi(drawers ...Drawer) Drawer {
+	return multiOf(&multiDrawer{drawers: drawers})
+}
+
+// MultiCollect returns the drawer, that passes each point to all drawers as Multi does, but collects failures
+// instead of stopping: the failed drawer is detached and receives no more points, while the rest go on,
+// the solution is stopped with MultiError only after all drawers failed. The returned MultiError
+// is updated on each call, its Err must be checked after the solution
+func MultiCollect(drawers ...Drawer) (*MultiError, Drawer) {
+	failed := &MultiError{Errs: map[int]error{}}
+	return failed, multiOf(&multiDrawer{drawers: drawers, failed: failed})
+}
+
+// multiOf returns the drawer, that implements optional interfaces, supported by drawers of md,
+// the step drawer is preferred, as the sink passes points to it one by one anyway
+func multiOf(md *multiDrawer) Drawer {
+	batch := len(md.drawers) > 0
+	for _, d := range md.drawers {
+		md.steps = append(md.steps, drawerOf(d))
+		if _, ok := d.(StepDrawer); ok {
+			return &multiStepDrawer{md}
+		}
+		if _, ok := d.(BatchDrawer); !ok {
+			batch = false
+		}
+	}
+	if batch {
+		return &multiBatchDrawer{md}
+	}
+	return md
+}
+
+type multiDrawer struct {
+	drawers []Drawer
+	steps   []func(i int, h float64, p num.Point) error // drawers with step data, made by drawerOf
+	failed  *MultiError                                 // failures, nil if the drawer fails fast
+}
+
+// Draw passes the point to each drawer
+func (md *multiDrawer) Draw(p num.Point) error {
+	for i, d := range md.drawers {
+		if md.detached(i) {
+			continue
+		}
+		if err := md.fail(i, d.Draw(p)); err != nil {
+			return err
+		}
+	}
+	return md.check()
+}
+
+// detached checks whether the i-th drawer failed before and receives no more points
+func (md *multiDrawer) detached(i int) bool {
+	return md.failed != nil && md.failed.Errs[i] != nil
+}
+
+// fail returns the error of the i-th drawer to stop the solution, or records it, if failures are collected
+func (md *multiDrawer) fail(i int, err error) error {
+	if err == nil {
+		return nil
+	}
+	if md.failed == nil {
+		return errors.Wrapf(err, "drawer #%d failed", i)
+	}
+	md.failed.Errs[i] = err
+	return nil
+}
+
+// check stops the solution, once all drawers failed
+func (md *multiDrawer) check() error {
+	if md.failed != nil && len(md.drawers) > 0 && len(md.failed.Errs) == len(md.drawers) {
+		return md.failed
+	}
+	return nil
+}
+
+type multiStepDrawer struct{ *multiDrawer }
+
+// DrawStep passes the point to each drawer, along with the step data, if the drawer wants it
+func (md *multiStepDrawer) DrawStep(i int, h float64, p num.Point) error {
+	for j := range md.drawers {
+		if md.detached(j) {
+			continue
+		}
+		if err := md.fail(j, md.steps[j](i, h, p)); err != nil {
+			return err
+		}
+	}
+	return md.check()
+}
+
+type multiBatchDrawer struct{ *multiDrawer }
+
+// DrawBatch passes the batch to each drawer
+func (md *multiBatchDrawer) DrawBatch(pts []num.Point) error {
+	for i, d := range md.drawers {
+		if md.detached(i) {
+			continue
+		}
+		if err := md.fail(i, d.(BatchDrawer).DrawBatch(pts)); err != nil {
+			return err
+		}
+	}
+	return md.check()
+}
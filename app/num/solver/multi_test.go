@@ -0,0 +1,107 @@
+package solver
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failAfter returns the drawer, that fails on the point with the index n
+func failAfter(n int) Drawer {
+	drawn := 0
+	return DrawerFunc(func(num.Point) error {
+		if drawn++; drawn > n {
+			return errors.New("drawer failed")
+		}
+		return nil
+	})
+}
+
+func TestMulti(t *testing.T) {
+	slvr := rk4Builder(func(x, y float64) (float64, error) { return x*x - 2*y, nil })
+	want := &Collector{}
+	require.NoError(t, slvr.Solve(0.1, 0, 1, 1, want))
+
+	// the chart, the csv and the stats are fed by the single solve
+	c, buf := &Collector{}, &bytes.Buffer{}
+	csv := NewCSVDrawer(buf)
+	st, stats := WithStats(DrawerFunc(func(num.Point) error { return nil }))
+	require.NoError(t, slvr.Solve(0.1, 0, 1, 1, Multi(c, csv, stats)))
+	require.NoError(t, csv.Flush())
+	assert.Equal(t, want.Points, c.Points)
+	assert.Len(t, strings.Split(strings.TrimSpace(buf.String()), "\n"), 11)
+	assert.Equal(t, 11, st.Points)
+	assert.Equal(t, want.Points[10], st.Final)
+
+	// optional interfaces are the ones of all drawers
+	_, isBatch := Multi(&Collector{}, NewCSVDrawer(buf)).(BatchDrawer)
+	assert.True(t, isBatch, "batches are passed, if all drawers accept them")
+	_, isBatch = Multi(&Collector{}, DrawerFunc(c.Draw)).(BatchDrawer)
+	assert.False(t, isBatch)
+	_, isBatch = Multi().(BatchDrawer)
+	assert.False(t, isBatch)
+
+	sc := &stepCollector{}
+	c = &Collector{}
+	d := Multi(c, sc)
+	_, isStep := d.(StepDrawer)
+	require.True(t, isStep, "step data is passed, if any drawer wants it")
+	require.NoError(t, slvr.Solve(0.1, 0, 1, 1, d))
+	assert.Equal(t, want.Points, c.Points)
+	assert.Equal(t, want.Points, sc.Points)
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, sc.idx)
+}
+
+func TestMulti_FailFast(t *testing.T) {
+	slvr := rk4Builder(func(x, y float64) (float64, error) { return x*x - 2*y, nil })
+	first, last := &Collector{}, &Collector{}
+	err := slvr.Solve(0.1, 0, 1, 1, Multi(first, failAfter(3), last))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "drawer #1 failed: drawer failed")
+	var se *StepError
+	require.True(t, errors.As(err, &se), "the failure is located by the solver")
+	assert.Equal(t, 3, se.Step)
+	assert.Len(t, first.Points, 4, "drawers before the failed one draw the point")
+	assert.Len(t, last.Points, 3, "the failed point is not passed to the rest of drawers")
+
+	// batches stop at the failed one
+	const steps = 3000
+	first, failing := &Collector{}, &failingBatch{failAt: 1500}
+	err = slvr.Solve(1.0/steps, 0, 1, 1, Multi(first, failing))
+	require.Error(t, err)
+	assert.Len(t, first.Points, 2*batchSize)
+	assert.Len(t, failing.Points, 1500)
+}
+
+func TestMultiCollect(t *testing.T) {
+	slvr := rk4Builder(func(x, y float64) (float64, error) { return x*x - 2*y, nil })
+	c := &Collector{}
+	failed, d := MultiCollect(c, failAfter(3), DrawerFunc(func(p num.Point) error {
+		if p.X > 0.45 {
+			return errors.New("too far")
+		}
+		return nil
+	}))
+	require.NoError(t, slvr.Solve(0.1, 0, 1, 1, d), "the solution goes on, while any drawer works")
+	assert.Len(t, c.Points, 11)
+	require.Error(t, failed.Err())
+	assert.EqualError(t, failed, "2 drawers failed, drawer #1: drawer failed; drawer #2: too far")
+
+	// the solution is stopped, once all drawers failed
+	failed, d = MultiCollect(failAfter(2), failAfter(5))
+	err := slvr.Solve(0.1, 0, 1, 1, d)
+	require.Error(t, err)
+	var me *MultiError
+	require.True(t, errors.As(err, &me))
+	assert.Len(t, me.Errs, 2)
+	assert.Equal(t, failed, me)
+
+	failed, d = MultiCollect(c)
+	require.NoError(t, slvr.Solve(0.1, 0, 1, 1, d))
+	assert.NoError(t, failed.Err())
+}
@@ -0,0 +1,58 @@
+package solver
+
+import (
+	"math"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// OnGrid draws the solution of the solver with the adaptive step at nodes of the uniform grid, as other solvers
+// do, each step of the grid is solved by the solver from the previous node with the step of the grid as the first
+// one, so its own steps are controlled within the step of the grid, and lines of all methods share nodes
+type OnGrid struct {
+	Solver Interface
+}
+
+// Name returns the name of the wrapped method
+func (o *OnGrid) Name() string { return o.Solver.Name() }
+
+// Solve the initial value problem by the wrapped solver step by step of the grid
+func (o *OnGrid) Solve(stepSize, x0, y0, xEnd float64, d Drawer) error {
+	if err := checkArgs(stepSize, x0, xEnd); err != nil {
+		return err
+	}
+
+	g := NewGrid(x0, xEnd, stepSize)
+	out := sinkOf(o.Name(), d)
+	y := y0
+	for i := 0; i <= g.N; i++ {
+		x := g.X(i)
+		if i > 0 {
+			var err error
+			if y, err = o.step(i-1, g.X(i-1), y, x, math.Abs(g.Step(i))); err != nil {
+				return out.fail(err)
+			}
+		}
+		if err := out.put(i, g.Step(i), num.Point{X: x, Y: y}); err != nil {
+			return err
+		}
+	}
+
+	return out.flush()
+}
+
+// step solves the i-th step of the grid from x, where the solution is y, up to xEnd, the failure is located
+// at the step of the grid
+func (o *OnGrid) step(i int, x, y, xEnd, h float64) (float64, error) {
+	last := y
+	err := o.Solver.Solve(h, x, y, xEnd, DrawerFunc(func(p num.Point) error {
+		last = p.Y
+		return nil
+	}))
+	var se *StepError
+	if errors.As(err, &se) {
+		se.Step = i
+	}
+	return last, err
+}
@@ -0,0 +1,205 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// ParamFunc calculates y' as f(x,y) with the named parameters
+type ParamFunc func(x, y float64, params map[string]float64) (float64, error)
+
+// SweepError lists failures of solutions of the parameter sweep by the values of the parameter
+type SweepError struct {
+	Param string
+	Errs  map[float64]error
+}
+
+// Error returns failures in the ascending order of values
+func (e *SweepError) Error() string {
+	vals := make([]float64, 0, len(e.Errs))
+	for v := range e.Errs {
+		vals = append(vals, v)
+	}
+	sort.Float64s(vals)
+	msgs := make([]string, 0, len(vals))
+	for _, v := range vals {
+		msgs = append(msgs, fmt.Sprintf("%s=%v: %v", e.Param, v, e.Errs[v]))
+	}
+	return fmt.Sprintf("%d solutions failed, %s", len(vals), strings.Join(msgs, "; "))
+}
+
+// Sweep solves the problem by the method once per value of the parameter, other parameters are fixed,
+// solutions are solved concurrently by GOMAXPROCS workers at most and returned by values of the parameter,
+// the failure of the solution doesn't stop others, solutions of the rest of values are returned
+// along with SweepError
+func Sweep(f ParamFunc, param string, values []float64, fixed map[string]float64, method Builder,
+	step, x0, y0, xEnd float64) (map[float64][]num.Point, error) {
+	if err := checkArgs(step, x0, xEnd); err != nil {
+		return nil, err
+	}
+	return sweep(param, values, func(v float64) (num.Line, error) {
+		params := make(map[string]float64, len(fixed)+1)
+		for name, val := range fixed {
+			params[name] = val
+		}
+		params[param] = v
+		fxy := func(x, y float64) (float64, error) { return f(x, y, params) }
+		return Collect(method(fxy), step, x0, y0, xEnd)
+	})
+}
+
+// SweepInitial solves the problem by the method once per initial value y0, e.g. to draw the family of solutions,
+// concurrently, as Sweep does, solutions are returned by initial values, failures are listed by SweepError of y0
+func SweepInitial(f Func, values []float64, method Builder, step, x0, xEnd float64) (map[float64][]num.Point, error) {
+	if err := checkArgs(step, x0, xEnd); err != nil {
+		return nil, err
+	}
+	return sweep("y0", values, func(y0 float64) (num.Line, error) {
+		return Collect(method(f), step, x0, y0, xEnd)
+	})
+}
+
+// GridLine is the solution of the grid sweep with the combination of values of swept parameters,
+// the failed solution has the error and no points
+type GridLine struct {
+	Params map[string]float64 // values of swept parameters
+	Points []num.Point
+	Err    error
+}
+
+// Label returns values of swept parameters in the order of names, e.g. "a=1, b=2"
+func (l GridLine) Label() string {
+	names := make([]string, 0, len(l.Params))
+	for name := range l.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s=%v", name, l.Params[name])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// SweepGrid solves the problem by the method once per combination of values of swept parameters of the grid,
+// other parameters are fixed, concurrently, as Sweep does. Lines go in the order of combinations, parameters are
+// ordered by names and values of the last one change first, the failure of the solution is in its line, the error
+// is returned only for invalid arguments
+func SweepGrid(f ParamFunc, grid map[string][]float64, fixed map[string]float64, method Builder,
+	step, x0, y0, xEnd float64) ([]GridLine, error) {
+	if err := checkArgs(step, x0, xEnd); err != nil {
+		return nil, err
+	}
+	if len(grid) == 0 {
+		return nil, errors.New("grid must have at least one parameter")
+	}
+	names := make([]string, 0, len(grid))
+	total := 1
+	for name, values := range grid {
+		if _, ok := fixed[name]; ok {
+			return nil, errors.Errorf("%s is swept, it can't be fixed", name)
+		}
+		if len(values) == 0 {
+			return nil, errors.Errorf("%s must have at least one value", name)
+		}
+		if err := checkValues(name, values); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		total *= len(values)
+	}
+	sort.Strings(names)
+
+	lines := make([]GridLine, total)
+	for i := range lines {
+		params := make(map[string]float64, len(names))
+		for j, k := len(names)-1, i; j >= 0; j-- {
+			values := grid[names[j]]
+			params[names[j]] = values[k%len(values)]
+			k /= len(values)
+		}
+		lines[i].Params = params
+	}
+	parallel(total, func(i int) {
+		params := make(map[string]float64, len(fixed)+len(names))
+		for name, val := range fixed {
+			params[name] = val
+		}
+		for name, val := range lines[i].Params {
+			params[name] = val
+		}
+		fxy := func(x, y float64) (float64, error) { return f(x, y, params) }
+		line, err := Collect(method(fxy), step, x0, y0, xEnd)
+		if err != nil {
+			lines[i].Err = err
+			return
+		}
+		lines[i].Points = line.Points
+	})
+	return lines, nil
+}
+
+// sweep solves once per distinct finite value of the parameter by GOMAXPROCS workers at most
+func sweep(param string, values []float64, solve func(v float64) (num.Line, error)) (map[float64][]num.Point, error) {
+	if err := checkValues(param, values); err != nil {
+		return nil, err
+	}
+
+	res := make(map[float64][]num.Point, len(values))
+	serr := &SweepError{Param: param, Errs: map[float64]error{}}
+	var mu sync.Mutex
+	parallel(len(values), func(i int) {
+		line, err := solve(values[i])
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			serr.Errs[values[i]] = err
+			return
+		}
+		res[values[i]] = line.Points
+	})
+
+	if len(serr.Errs) > 0 {
+		return res, serr
+	}
+	return res, nil
+}
+
+// checkValues checks, that values of the parameter are finite and distinct
+func checkValues(param string, values []float64) error {
+	seen := make(map[float64]bool, len(values))
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return errors.Errorf("value of %s must be finite, got %v", param, v)
+		}
+		if seen[v] {
+			return errors.Errorf("value %v of %s is repeated", v, param)
+		}
+		seen[v] = true
+	}
+	return nil
+}
+
+// parallel calls fn for each index below n by GOMAXPROCS workers at most and waits for all of them
+func parallel(n int, fn func(i int)) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	for i := 0; i < n; i++ {
+		i := i
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem }()
+			defer wg.Done()
+			fn(i)
+		}()
+	}
+	wg.Wait()
+}
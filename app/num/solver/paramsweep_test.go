@@ -0,0 +1,119 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSweep(t *testing.T) {
+	// y' = x^2 - b*y from y(0) = 1 decays faster with the larger b
+	f := func(x, y float64, params map[string]float64) (float64, error) { return x*x - params["b"]*y, nil }
+	values := []float64{3, 1, 2}
+	res, err := Sweep(f, "b", values, nil, rk4Builder, 0.1, 0, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, res, 3)
+	for _, v := range values {
+		require.Len(t, res[v], 11)
+		assert.Equal(t, 1.0, res[v][10].X)
+	}
+	assert.Greater(t, res[1][10].Y, res[2][10].Y)
+	assert.Greater(t, res[2][10].Y, res[3][10].Y)
+
+	// fixed parameters are passed along with the swept one
+	f = func(x, y float64, params map[string]float64) (float64, error) { return params["k"] * params["b"], nil }
+	res, err = Sweep(f, "b", []float64{1, 2}, map[string]float64{"k": 3}, rk4Builder, 0.5, 0, 0, 1)
+	require.NoError(t, err)
+	assert.InDelta(t, 3, res[1][2].Y, 1e-12)
+	assert.InDelta(t, 6, res[2][2].Y, 1e-12)
+}
+
+func TestSweep_Failures(t *testing.T) {
+	errF := errors.New("f failed")
+	f := func(x, y float64, params map[string]float64) (float64, error) {
+		if params["b"] < 0 {
+			return 0, errF
+		}
+		return -params["b"] * y, nil
+	}
+	res, err := Sweep(f, "b", []float64{1, -1, -2}, nil, rk4Builder, 0.1, 0, 1, 1)
+	var se *SweepError
+	require.True(t, errors.As(err, &se), "%v", err)
+	assert.Len(t, res, 1, "the solution of b=1 is kept")
+	require.Len(t, se.Errs, 2)
+	assert.True(t, errors.Is(se.Errs[-1], errF))
+	assert.Contains(t, err.Error(), "2 solutions failed, b=-2: ")
+
+	_, err = Sweep(f, "b", []float64{1, 1}, nil, rk4Builder, 0.1, 0, 1, 1)
+	assert.EqualError(t, err, "value 1 of b is repeated")
+}
+
+func TestSweepInitial(t *testing.T) {
+	// y' = -y from y0 is y0*exp(-x)
+	f := func(_, y float64) (float64, error) { return -y, nil }
+	res, err := SweepInitial(f, []float64{-1, 0, 1, 2}, rk4Builder, 0.1, 0, 1)
+	require.NoError(t, err)
+	require.Len(t, res, 4)
+	for y0, pts := range res {
+		require.Len(t, pts, 11)
+		assert.Equal(t, y0, pts[0].Y)
+		assert.InDelta(t, y0*math.Exp(-1), pts[10].Y, 1e-6, "y0=%v", y0)
+	}
+
+	errF := errors.New("f failed")
+	res, err = SweepInitial(func(_, y float64) (float64, error) {
+		if y < 0 {
+			return 0, errF
+		}
+		return -y, nil
+	}, []float64{-1, 1}, rk4Builder, 0.1, 0, 1)
+	var se *SweepError
+	require.True(t, errors.As(err, &se), "%v", err)
+	assert.Equal(t, "y0", se.Param)
+	assert.True(t, errors.Is(se.Errs[-1], errF))
+	assert.Len(t, res, 1)
+
+	_, err = SweepInitial(f, []float64{math.NaN()}, rk4Builder, 0.1, 0, 1)
+	assert.EqualError(t, err, "value of y0 must be finite, got NaN")
+}
+
+func TestSweepGrid(t *testing.T) {
+	// y' = a - b*y from y(0) = 0 tends to a/b
+	errF := errors.New("negative b")
+	f := func(_, y float64, params map[string]float64) (float64, error) {
+		if params["b"] < 0 {
+			return 0, errF
+		}
+		return params["k"]*params["a"] - params["b"]*y, nil
+	}
+	grid := map[string][]float64{"b": {1, -1, 2}, "a": {1, 2}}
+	lines, err := SweepGrid(f, grid, map[string]float64{"k": 1}, rk4Builder, 0.1, 0, 0, 1)
+	require.NoError(t, err)
+	require.Len(t, lines, 6)
+	labels := make([]string, len(lines))
+	for i, line := range lines {
+		labels[i] = line.Label()
+	}
+	assert.Equal(t, []string{"a=1, b=1", "a=1, b=-1", "a=1, b=2", "a=2, b=1", "a=2, b=-1", "a=2, b=2"}, labels)
+	for _, line := range lines {
+		if line.Params["b"] < 0 {
+			assert.True(t, errors.Is(line.Err, errF), "%v", line.Err)
+			assert.Empty(t, line.Points)
+			continue
+		}
+		require.NoError(t, line.Err)
+		require.Len(t, line.Points, 11)
+		a, b := line.Params["a"], line.Params["b"]
+		assert.InDelta(t, a/b*(1-math.Exp(-b)), line.Points[10].Y, 1e-5, line.Label())
+	}
+
+	_, err = SweepGrid(f, map[string][]float64{"a": {1}}, map[string]float64{"a": 1}, rk4Builder, 0.1, 0, 0, 1)
+	assert.EqualError(t, err, "a is swept, it can't be fixed")
+	_, err = SweepGrid(f, map[string][]float64{"a": {}}, nil, rk4Builder, 0.1, 0, 0, 1)
+	assert.EqualError(t, err, "a must have at least one value")
+	_, err = SweepGrid(f, nil, nil, rk4Builder, 0.1, 0, 0, 1)
+	assert.EqualError(t, err, "grid must have at least one parameter")
+}
@@ -0,0 +1,7 @@
+//go:build race
+// +build race
+
+package solver
+
+// raceEnabled tells, that tests run with the race detector, which allocates on its own
+const raceEnabled = true
@@ -0,0 +1,26 @@
+package solver
+
+import (
+	"github.com/Semior001/decompract/app/num"
+)
+
+// Recording is the run of the solver, recorded by the drawer, points are kept as they are passed to it,
+// including the one, it failed to draw
+type Recording struct {
+	Points []num.Point
+	Err    error // the first error of drawing, nil if all points are drawn
+}
+
+// WithRecorder wraps the drawer to record every point, passed to it, and the first error of drawing,
+// the returned Recording is updated on each call
+func WithRecorder(d Drawer) (*Recording, Drawer) {
+	rec := &Recording{}
+	return rec, wrap(d, func(c call) error {
+		rec.Points = append(rec.Points, c.p)
+		err := c.do()
+		if err != nil && rec.Err == nil {
+			rec.Err = err
+		}
+		return err
+	})
+}
@@ -0,0 +1,36 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRecorder(t *testing.T) {
+	c := &Collector{}
+	rec, d := WithRecorder(c)
+	slvr := rk4Builder(func(x, y float64) (float64, error) { return x*x - 2*y, nil })
+	require.NoError(t, slvr.Solve(0.1, 0, 1, 1, d))
+	assert.Equal(t, c.Points, rec.Points, "points are recorded with full precision")
+	assert.NoError(t, rec.Err)
+
+	// the failed point is recorded along with the error
+	drawn := 0
+	rec, d = WithRecorder(DrawerFunc(func(p num.Point) error {
+		if drawn++; drawn == 2 {
+			return errors.New("failed")
+		}
+		return nil
+	}))
+	pts := []num.Point{{X: 0, Y: 1}, {X: 1, Y: math.NaN()}, {X: 2, Y: 3}}
+	for _, p := range pts {
+		_ = d.Draw(p)
+	}
+	require.Len(t, rec.Points, 3)
+	assert.True(t, math.IsNaN(rec.Points[1].Y))
+	assert.EqualError(t, rec.Err, "failed", "the first error is kept")
+}
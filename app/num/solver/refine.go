@@ -0,0 +1,77 @@
+package solver
+
+import (
+	"math"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// ErrNotConverged is returned by Refine, if successive solutions still differ by more than the tolerance
+// after all doublings of the number of steps, and by implicit solvers, if Newton's method of the step diverges
+// or doesn't converge within iterations
+var ErrNotConverged = errors.New("solution is not converged")
+
+// RefineResult is the solution on the finest grid of the refinement with differences between levels
+type RefineResult struct {
+	Line      num.Line
+	N         int       // number of steps of the finest grid
+	Doublings int       // number of doublings of the initial number of steps
+	Diffs     []float64 // max differences of each level from the previous one at nodes of the previous one
+}
+
+// Refine solves the problem by the method with nStart steps and doubles the number of steps, until the max
+// difference of successive solutions at nodes of the coarser one drops below tol, the not finite difference
+// never does. If maxDoublings are exhausted, the finest solution is returned along with ErrNotConverged
+func Refine(method Builder, f Func, x0, y0, xEnd float64, nStart int, tol float64, maxDoublings int) (*RefineResult, error) {
+	if nStart < 1 {
+		return nil, errors.Errorf("initial number of steps must be positive, got %d", nStart)
+	}
+	if !(tol > 0) || math.IsInf(tol, 1) {
+		return nil, errors.Errorf("tolerance must be positive and finite, got %v", tol)
+	}
+	if maxDoublings < 1 {
+		return nil, errors.Errorf("number of doublings must be positive, got %d", maxDoublings)
+	}
+
+	solve := func(n int) (num.Line, error) {
+		step, err := num.CalculateStepSize(n, x0, xEnd)
+		if err != nil {
+			return num.Line{}, err
+		}
+		line, err := Collect(method(f), step, x0, y0, xEnd)
+		return line, errors.Wrapf(err, "failed to solve with n=%d", n)
+	}
+
+	res := &RefineResult{N: nStart}
+	prev, err := solve(nStart)
+	if err != nil {
+		return nil, err
+	}
+	for res.Doublings < maxDoublings {
+		cur, err := solve(res.N * 2)
+		if err != nil {
+			return nil, err
+		}
+		if len(cur.Points) != 2*len(prev.Points)-1 {
+			return nil, errors.Wrapf(ErrPointsCount, "solutions with n=%d and n=%d have %d and %d points",
+				res.N, res.N*2, len(prev.Points), len(cur.Points))
+		}
+		diff := 0.0
+		for i, p := range prev.Points {
+			d := math.Abs(cur.Points[2*i].Y - p.Y)
+			if !isFinite(d) {
+				d = math.Inf(1)
+			}
+			diff = math.Max(diff, d)
+		}
+		res.N, res.Doublings, res.Diffs, prev = res.N*2, res.Doublings+1, append(res.Diffs, diff), cur
+		if diff < tol {
+			res.Line = cur
+			return res, nil
+		}
+	}
+	res.Line = prev
+	return res, errors.Wrapf(ErrNotConverged, "max difference %g with n=%d, tolerance is %g",
+		res.Diffs[len(res.Diffs)-1], res.N, tol)
+}
@@ -0,0 +1,78 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// canonical is the problem of the practicum, solved on [-4, 4] from y(-4) = 1
+func canonical(x, y float64) (float64, error) { return y*y*math.Exp(x) - 2*y, nil }
+
+func TestRefine(t *testing.T) {
+	rk4 := func(f Func) Interface { return &RungeKutta{F: f} }
+	res, err := Refine(rk4, canonical, -4, 1, 4, 30, 1e-6, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 3, res.Doublings)
+	assert.Equal(t, 240, res.N)
+	require.Len(t, res.Diffs, 3)
+	for i := 1; i < len(res.Diffs); i++ {
+		ratio := res.Diffs[i-1] / res.Diffs[i]
+		assert.True(t, ratio > 12 && ratio < 24, "rk4 differences drop 16 times, got %v", ratio)
+	}
+	assert.Less(t, res.Diffs[2], 1e-6)
+	assert.Len(t, res.Line.Points, 241)
+	assert.Equal(t, "Runge-Kutta's method", res.Line.Name)
+
+	// euler needs many more doublings, its differences only halve
+	euler := func(f Func) Interface { return &Euler{F: f} }
+	res, err = Refine(euler, canonical, -4, 1, 4, 30, 1e-6, 8)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrNotConverged), err)
+	require.NotNil(t, res, "the finest solution is returned")
+	assert.Equal(t, 8, res.Doublings)
+	assert.Equal(t, 30*256, res.N)
+	assert.Len(t, res.Line.Points, 30*256+1)
+	for i := 2; i < len(res.Diffs); i++ {
+		assert.InDelta(t, 2, res.Diffs[i-1]/res.Diffs[i], 0.25)
+	}
+	res, err = Refine(euler, canonical, -4, 1, 4, 30, 1e-6, 20)
+	require.NoError(t, err)
+	assert.Equal(t, 17, res.Doublings)
+}
+
+func TestRefine_Errors(t *testing.T) {
+	rk4 := func(f Func) Interface { return &RungeKutta{F: f} }
+	for _, tt := range []struct {
+		n, doublings int
+		tol          float64
+		err          string
+	}{
+		{0, 5, 1e-6, "initial number of steps must be positive, got 0"},
+		{10, 5, 0, "tolerance must be positive and finite, got 0"},
+		{10, 5, math.Inf(1), "tolerance must be positive and finite, got +Inf"},
+		{10, 0, 1e-6, "number of doublings must be positive, got 0"},
+	} {
+		_, err := Refine(rk4, canonical, -4, 1, 4, tt.n, tt.tol, tt.doublings)
+		assert.EqualError(t, err, tt.err)
+	}
+
+	// the blown up solution never converges, as its differences are not finite
+	res, err := Refine(rk4, func(x, y float64) (float64, error) { return y * y, nil }, 0, 1, 2, 4, 1e-6, 3)
+	assert.True(t, errors.Is(err, ErrNotConverged), err)
+	require.NotNil(t, res)
+	assert.True(t, math.IsInf(res.Diffs[2], 1))
+
+	failed := errors.New("failed")
+	_, err = Refine(rk4, func(x, y float64) (float64, error) {
+		if x > 1 {
+			return 0, failed
+		}
+		return y, nil
+	}, 0, 1, 2, 4, 1e-6, 3)
+	assert.True(t, errors.Is(err, failed))
+	assert.Contains(t, err.Error(), "failed to solve with n=4")
+}
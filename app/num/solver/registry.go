@@ -0,0 +1,77 @@
+package solver
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// methodName matches the valid name of the registered method, the slug, used in requests and flags
+var methodName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// registry keeps builders of solvers by names of methods, builtin methods are registered from the start,
+// adaptive ones are solved on the grid of the requested step with the default tolerance, so lines of all methods
+// share nodes
+var registry = struct {
+	sync.RWMutex
+	builders map[string]Builder
+}{builders: map[string]Builder{
+	"euler":     func(f Func) Interface { return &Euler{F: f} },
+	"ieuler":    func(f Func) Interface { return &ImprovedEuler{F: f} },
+	"rk4":       func(f Func) Interface { return &RungeKutta{F: f} },
+	"ab4":       func(f Func) Interface { return &AdamsBashforth{F: f} },
+	"abm4":      func(f Func) Interface { return &AdamsBashforthMoulton{F: f} },
+	"beuler":    func(f Func) Interface { return &BackwardEuler{F: f} },
+	"trapezoid": func(f Func) Interface { return &Trapezoidal{F: f} },
+	"rkf45":     func(f Func) Interface { return &OnGrid{Solver: &RKF45{F: f}} },
+	"dopri5":    func(f Func) Interface { return &OnGrid{Solver: &DormandPrince{F: f}} },
+	"bdf":       func(f Func) Interface { return &OnGrid{Solver: &BDF{F: f}} },
+	"switching": func(f Func) Interface { return &Switching{F: f} },
+	// f is given by values only, so its derivatives along the solution are estimated by differences
+	"taylor2": func(f Func) Interface { return &Taylor{Series: DiffPartials(f).Series} },
+}}
+
+// Register makes the method available by its name to Lookup and Methods, so the server and commands
+// solve with it, e.g. from init of the custom build, it panics, if the name is invalid or taken,
+// or the builder is nil
+func Register(name string, factory Builder) {
+	registry.Lock()
+	defer registry.Unlock()
+	switch _, taken := registry.builders[name]; {
+	case !methodName.MatchString(name):
+		panic(fmt.Sprintf("solver: invalid name of the method %q", name))
+	case factory == nil:
+		panic(fmt.Sprintf("solver: nil builder of the method %q", name))
+	case taken:
+		panic(fmt.Sprintf("solver: method %q is registered twice", name))
+	}
+	registry.builders[name] = factory
+}
+
+// Unregister removes the method, e.g. registered by the test, unknown names are ignored
+func Unregister(name string) {
+	registry.Lock()
+	defer registry.Unlock()
+	delete(registry.builders, name)
+}
+
+// Lookup returns the builder of the solver by the name of the method
+func Lookup(name string) (Builder, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	b, ok := registry.builders[name]
+	return b, ok
+}
+
+// Methods returns the sorted names of registered methods
+func Methods() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	res := make([]string, 0, len(registry.builders))
+	for name := range registry.builders {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
@@ -0,0 +1,105 @@
+package solver
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegister(t *testing.T) {
+	builtin := []string{"ab4", "abm4", "bdf", "beuler", "dopri5", "euler", "ieuler", "rk4", "rkf45", "switching",
+		"taylor2", "trapezoid"}
+	assert.Equal(t, builtin, Methods())
+	b, ok := Lookup("rk4")
+	require.True(t, ok)
+	assert.Equal(t, "Runge-Kutta's method", b(nil).Name())
+	_, ok = Lookup("rk5")
+	assert.False(t, ok)
+
+	Register("midpoint", func(f Func) Interface { return &ImprovedEuler{F: f} })
+	defer Unregister("midpoint")
+	assert.Equal(t, append(builtin[:7:7], append([]string{"midpoint"}, builtin[7:]...)...), Methods())
+	b, ok = Lookup("midpoint")
+	require.True(t, ok)
+	want, err := Collect(&ImprovedEuler{F: canonical}, 0.1, 0, 1, 1)
+	require.NoError(t, err)
+	got, err := Collect(b(canonical), 0.1, 0, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	assert.PanicsWithValue(t, `solver: method "rk4" is registered twice`, func() {
+		Register("rk4", func(f Func) Interface { return &Euler{F: f} })
+	})
+	assert.PanicsWithValue(t, `solver: invalid name of the method "Runge Kutta"`, func() {
+		Register("Runge Kutta", func(f Func) Interface { return &Euler{F: f} })
+	})
+	assert.PanicsWithValue(t, `solver: nil builder of the method "nil"`, func() { Register("nil", nil) })
+
+	Unregister("midpoint")
+	_, ok = Lookup("midpoint")
+	assert.False(t, ok)
+}
+
+func TestRegistry_Builtin(t *testing.T) {
+	for _, tt := range []struct {
+		method, name string
+		order        int // of the convergence at nodes of the grid, zero for adaptive methods
+	}{
+		{"euler", "Euler's method", 1},
+		{"ieuler", "Improved Euler's method", 2},
+		{"rk4", "Runge-Kutta's method", 4},
+		{"ab4", "Adams-Bashforth's method of order 4", 4},
+		{"abm4", "Adams-Bashforth-Moulton's method", 4},
+		{"beuler", "Backward Euler's method", 1},
+		{"trapezoid", "Trapezoidal method", 2},
+		{"switching", "Runge-Kutta's method with switching to backward Euler's", 4},
+		{"taylor2", "Taylor's method of order 2", 2},
+		{"rkf45", "Runge-Kutta-Fehlberg's method", 0},
+		{"dopri5", "Dormand-Prince's method", 0},
+		{"bdf", "BDF method", 0},
+	} {
+		t.Run(tt.method, func(t *testing.T) {
+			b, ok := Lookup(tt.method)
+			require.True(t, ok)
+			s := b(canonical)
+			assert.Equal(t, tt.name, s.Name())
+
+			// each method draws nodes of the grid, so lines of methods are compared node by node
+			maxErr := func(n int) float64 {
+				line, err := Collect(b(canonical), 1/float64(n), 0, 1, 1)
+				require.NoError(t, err)
+				require.Len(t, line.Points, n+1)
+				res := 0.0
+				for i, p := range line.Points {
+					assert.Equal(t, NewGrid(0, 1, 1/float64(n)).X(i), p.X)
+					res = math.Max(res, math.Abs(p.Y-math.Exp(-p.X))) // the exact solution from y0 = 1
+				}
+				return res
+			}
+			coarse, fine := maxErr(20), maxErr(40)
+			if tt.order == 0 {
+				// local errors of steps are within the default tolerance, bdf restarts from the first order at each node
+				assert.Less(t, fine, 1e-3)
+				return
+			}
+			assert.InDelta(t, float64(tt.order), math.Log2(coarse/fine), 0.3)
+		})
+	}
+}
+
+func TestRegistry_BuiltinBackward(t *testing.T) {
+	// y' = y backward from y(1) = e, nodes of the grid go with decreasing x
+	exp := func(_, y float64) (float64, error) { return y, nil }
+	for _, method := range Methods() {
+		b, _ := Lookup(method)
+		line, err := Collect(b(exp), 0.1, 1, math.E, 0)
+		require.NoError(t, err, method)
+		require.Len(t, line.Points, 11, method)
+		for i, p := range line.Points {
+			assert.Equal(t, NewGrid(1, 0, 0.1).X(i), p.X, method)
+		}
+		assert.InDelta(t, 1, line.Points[10].Y, 0.06, method)
+	}
+}
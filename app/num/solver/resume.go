@@ -0,0 +1,138 @@
+package solver
+
+import (
+	"math"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// ErrCheckpoint is returned, if the solution can't be resumed from the checkpoint up to the end of the interval,
+// as its node is not the node of the grid of the interval, reached by the whole step
+var ErrCheckpoint = errors.New("checkpoint is not the node of the grid of the interval")
+
+// Checkpoint is the state of the solution at the node of its grid, one-step methods keep no history, so the node
+// and y at it are the whole state, multistep methods keep values of f at previous nodes as well. Nodes are
+// calculated by their indexes, so the solution, resumed from the checkpoint, gives the same points, bit for bit,
+// as the solution from x0, the checkpoint is encoded to json to resume the solution later
+type Checkpoint struct {
+	X0   float64   `json:"x0"`          // the grid of the solution
+	H    float64   `json:"h"`           // the step of the grid, it is negative, if the solution goes backward
+	Node int       `json:"node"`        // index of the node, reached by the whole step
+	Y    float64   `json:"y"`           // the solution at the node
+	F    []float64 `json:"f,omitempty"` // f at previous nodes of the multistep method, the newest one first
+}
+
+// Resumer is the solver, that continues the solution from the checkpoint
+type Resumer interface {
+	Interface
+	// Resume solves the problem from the node of the checkpoint up to xEnd, the point at the node
+	// is drawn first, as its x might differ from the end of the shorter interval, that ended at it
+	Resume(cp Checkpoint, xEnd float64, d Drawer) error
+}
+
+// CheckpointOf returns the checkpoint of the solution of [x0, xEnd] with the step at its last node, reached
+// by the whole step, i.e. before the shortened last step, pts are points of the solution from the node from
+func CheckpointOf(pts []num.Point, from int, step, x0, xEnd float64) (Checkpoint, error) {
+	if err := checkArgs(step, x0, xEnd); err != nil {
+		return Checkpoint{}, err
+	}
+	g := NewGrid(x0, xEnd, step)
+	if err := checkCount(from+len(pts), g.N+1); err != nil {
+		return Checkpoint{}, err
+	}
+	node := g.LastFull()
+	if node < from {
+		return Checkpoint{}, errors.Wrapf(ErrCheckpoint, "node %d is not drawn, points start at %d", node, from)
+	}
+	return Checkpoint{X0: x0, H: g.H, Node: node, Y: pts[node-from].Y}, nil
+}
+
+// history returns values of f at previous nodes, that the multistep method of steps nodes needs at the node
+// of the checkpoint, the copy is returned, so the resumed solution doesn't change the checkpoint
+func (cp Checkpoint) history(steps int) ([]float64, error) {
+	want := cp.Node
+	if want > steps {
+		want = steps
+	}
+	if len(cp.F) != want {
+		return nil, errors.Wrapf(ErrCheckpoint, "%d values of f at node %d, the method needs %d", len(cp.F), cp.Node, want)
+	}
+	fs := make([]float64, len(cp.F), steps)
+	copy(fs, cp.F)
+	return fs, nil
+}
+
+// grid returns the grid of the interval from x0 of the checkpoint to xEnd, that has the node of the checkpoint
+// and goes in the same direction
+func (cp Checkpoint) grid(xEnd float64) (Grid, error) {
+	h := math.Abs(cp.H)
+	if err := checkArgs(h, cp.X0, xEnd); err != nil {
+		return Grid{}, err
+	}
+	g := NewGrid(cp.X0, xEnd, h)
+	if cp.Node < 0 || cp.Node > g.LastFull() || g.N > 0 && g.H != cp.H {
+		return Grid{}, errors.Wrapf(ErrCheckpoint, "node %d of the step %v, xend=%v", cp.Node, cp.H, xEnd)
+	}
+	return g, nil
+}
+
+// WithCheckpoints wraps the drawer to save the checkpoint of the solution with the step from x0 at each every-th
+// node of its grid, reached by the whole step, after the point at the node is drawn, so the long solution
+// might be resumed from the last saved checkpoint, multistep methods put their history into checkpoints.
+// The failure to save the checkpoint stops the solution with its error
+func WithCheckpoints(d Drawer, x0, step float64, every int, save func(cp Checkpoint) error) Drawer {
+	if every < 1 {
+		every = 1
+	}
+	return &checkpointDrawer{next: d, draw: drawerOf(d), x0: x0, h: step, every: every, save: save}
+}
+
+// checkpointDrawer saves checkpoints of the solution, it is the step drawer, as it needs indexes of nodes
+type checkpointDrawer struct {
+	next  Drawer
+	draw  func(i int, h float64, p num.Point) error
+	x0, h float64
+	every int
+	save  func(cp Checkpoint) error
+	fs    []float64 // history of f of the multistep method at the next drawn node
+}
+
+// unwrap returns the wrapped drawer
+func (cd *checkpointDrawer) unwrap() Drawer { return cd.next }
+
+// Draw passes the point to the wrapped drawer, the point without its index is not checkpointed
+func (cd *checkpointDrawer) Draw(p num.Point) error { return cd.next.Draw(p) }
+
+// DrawStep passes the point to the wrapped drawer and saves the checkpoint at it
+func (cd *checkpointDrawer) DrawStep(i int, h float64, p num.Point) error {
+	if err := cd.draw(i, h, p); err != nil {
+		return err
+	}
+	if i == 0 || i%cd.every != 0 || math.Abs(h) != cd.h {
+		return nil
+	}
+	cp := Checkpoint{X0: cd.x0, H: h, Node: i, Y: p.Y}
+	if len(cd.fs) > 0 {
+		cp.F = append([]float64(nil), cd.fs...)
+	}
+	if err := cd.save(cp); err != nil {
+		return errors.Wrapf(err, "failed to save checkpoint at node %d", i)
+	}
+	return nil
+}
+
+// checkpointer returns the checkpoint drawer anywhere in the chain of wrapped drawers, nil if there is no one
+func checkpointer(d Drawer) *checkpointDrawer {
+	for d != nil {
+		if cd, ok := d.(*checkpointDrawer); ok {
+			return cd
+		}
+		u, ok := d.(interface{ unwrap() Drawer })
+		if !ok {
+			break
+		}
+		d = u.unwrap()
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package solver
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResumer(t *testing.T) {
+	for _, s := range []Resumer{&Euler{F: benchF}, &ImprovedEuler{F: benchF}, &RungeKutta{F: benchF},
+		&BackwardEuler{F: benchF}, &Trapezoidal{F: benchF}, &Taylor{Series: seriesOf(t, "x^2 - 2*y"), Order: 3}} {
+		full := &Collector{}
+		require.NoError(t, s.Solve(0.3, -1.1, 1, 4.2, full), s.Name())
+
+		// the shorter interval ends with the shortened step, so it's resumed from the node before it
+		part := &Collector{}
+		require.NoError(t, s.Solve(0.3, -1.1, 1, 1.1, part), s.Name())
+		cp, err := CheckpointOf(part.Points, 0, 0.3, -1.1, 1.1)
+		require.NoError(t, err, s.Name())
+		assert.Equal(t, 7, cp.Node, s.Name())
+		assert.Equal(t, part.Points[7].Y, cp.Y, s.Name())
+
+		for _, d := range []interface {
+			Drawer
+			points() []num.Point
+		}{&testCollector{}, &testPerPoint{}} {
+			require.NoError(t, s.Resume(cp, 4.2, d), s.Name())
+			assert.Equal(t, full.Points[cp.Node:], d.points(), "%s resumed is the same, bit for bit", s.Name())
+		}
+
+		// the checkpoint of the resumed solution continues it further
+		rest := &Collector{}
+		require.NoError(t, s.Resume(cp, 2.9, rest), s.Name())
+		cp, err = CheckpointOf(rest.Points, 7, 0.3, -1.1, 2.9)
+		require.NoError(t, err, s.Name())
+		assert.Equal(t, 13, cp.Node, "2.9 is the node of the grid up to rounding")
+		tail := &Collector{}
+		require.NoError(t, s.Resume(cp, 4.2, tail), s.Name())
+		assert.Equal(t, full.Points[13:], tail.Points, s.Name())
+
+		// the backward solution is resumed backward only
+		back := &Collector{}
+		require.NoError(t, s.Solve(0.3, 4.2, 1, -1.1, back), s.Name())
+		cp, err = CheckpointOf(back.Points[:8], 0, 0.3, 4.2, 2.1)
+		require.NoError(t, err, s.Name())
+		assert.Equal(t, -0.3, cp.H, s.Name())
+		resumed := &Collector{}
+		require.NoError(t, s.Resume(cp, -1.1, resumed), s.Name())
+		assert.Equal(t, back.Points[cp.Node:], resumed.Points, s.Name())
+		assert.True(t, errors.Is(s.Resume(cp, 6, &Collector{}), ErrCheckpoint), s.Name())
+	}
+}
+
+func TestResumer_Errors(t *testing.T) {
+	s := &RungeKutta{F: benchF}
+	cp := Checkpoint{X0: 0, H: 0.1, Node: 10, Y: 1}
+	for _, xEnd := range []float64{0.95, 0.5} {
+		d := &Collector{}
+		err := s.Resume(cp, xEnd, d)
+		assert.True(t, errors.Is(err, ErrCheckpoint), "xend=%v: %v", xEnd, err)
+		assert.Empty(t, d.Points)
+	}
+	assert.True(t, errors.Is(s.Resume(cp, -1, &Collector{}), ErrCheckpoint), "the grid back to -1 has no node 1")
+	assert.True(t, errors.Is(s.Resume(Checkpoint{X0: 0, Node: 1}, 1, &Collector{}), num.ErrBadStep))
+	assert.True(t, errors.Is(s.Resume(Checkpoint{X0: 0, H: 0.1, Node: -1}, 1, &Collector{}), ErrCheckpoint))
+
+	_, err := CheckpointOf([]num.Point{{X: 0, Y: 1}}, 0, 0.1, 0, 1)
+	assert.True(t, errors.Is(err, ErrPointsCount), err)
+	_, err = CheckpointOf([]num.Point{{X: 1, Y: 1}}, 4, 0.3, 0, 1)
+	assert.True(t, errors.Is(err, ErrCheckpoint), "the node before the shortened step is not drawn: %v", err)
+	cp, err = CheckpointOf([]num.Point{{X: 0, Y: 1}}, 0, 0.1, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, Checkpoint{X0: 0, H: 0.1, Node: 0, Y: 1}, cp, "the empty interval is resumed from x0")
+}
+
+// testCollector collects points by batches
+type testCollector struct{ Collector }
+
+func (c *testCollector) points() []num.Point { return c.Points }
+
+// testPerPoint collects points one by one with steps
+type testPerPoint struct{ pts []num.Point }
+
+func (c *testPerPoint) Draw(p num.Point) error { c.pts = append(c.pts, p); return nil }
+
+func (c *testPerPoint) DrawStep(_ int, _ float64, p num.Point) error { return c.Draw(p) }
+
+func (c *testPerPoint) points() []num.Point { return c.pts }
+
+func TestWithCheckpoints(t *testing.T) {
+	for _, s := range []Resumer{&RungeKutta{F: benchF}, &AdamsBashforth{F: benchF, Order: 3},
+		&AdamsBashforthMoulton{F: benchF}} {
+		full := &Collector{}
+		require.NoError(t, s.Solve(0.1, -1, 1, 2.05, full), s.Name())
+
+		var saved [][]byte
+		d := &Collector{}
+		err := s.Solve(0.1, -1, 1, 2.05, WithCheckpoints(d, -1, 0.1, 5, func(cp Checkpoint) error {
+			b, err := json.Marshal(cp)
+			saved = append(saved, b)
+			return err
+		}))
+		require.NoError(t, err, s.Name())
+		assert.Equal(t, full.Points, d.Points, "%s draws the same points", s.Name())
+		require.Len(t, saved, 6, "nodes 5, 10, ..., 30, the last step is shortened")
+
+		for _, b := range saved {
+			var cp Checkpoint
+			require.NoError(t, json.Unmarshal(b, &cp))
+			rest := &Collector{}
+			require.NoError(t, s.Resume(cp, 2.05, rest), "%s from %d", s.Name(), cp.Node)
+			assert.Equal(t, full.Points[cp.Node:], rest.Points, "%s from %d is the same, bit for bit", s.Name(), cp.Node)
+		}
+	}
+
+	// the failure to save stops the solution
+	d := &Collector{}
+	err := (&Euler{F: benchF}).Solve(0.1, 0, 1, 1, WithCheckpoints(d, 0, 0.1, 3, func(Checkpoint) error {
+		return errors.New("disk is full")
+	}))
+	assert.EqualError(t, err, "Euler's method failed at step 3, draw at x=0.3000 y=0.5168: "+
+		"failed to save checkpoint at node 3: disk is full")
+	assert.Len(t, d.Points, 4)
+}
+
+func TestAdams_ResumeErrors(t *testing.T) {
+	// the history is lost, e.g. the checkpoint is made by CheckpointOf
+	err := (&AdamsBashforthMoulton{F: benchF}).Resume(Checkpoint{X0: 0, H: 0.1, Node: 5, Y: 1}, 1, &Collector{})
+	assert.True(t, errors.Is(err, ErrCheckpoint), err)
+	assert.EqualError(t, err, "0 values of f at node 5, the method needs 4: "+ErrCheckpoint.Error())
+
+	err = (&AdamsBashforth{F: benchF, Order: 2}).Resume(Checkpoint{X0: 0, H: 0.1, Node: 1, Y: 1, F: []float64{1}}, 1,
+		&Collector{})
+	assert.NoError(t, err, "the single value is gathered at the first node")
+	err = (&AdamsBashforth{F: benchF, Order: 5}).Resume(Checkpoint{X0: 0, H: 0.1, Node: 1, Y: 1}, 1, &Collector{})
+	assert.EqualError(t, err, "order must be from 2 to 4, got 5")
+}
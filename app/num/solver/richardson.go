@@ -0,0 +1,72 @@
+package solver
+
+import (
+	"math"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// Richardson wraps the fixed step solver of the given order and extrapolates its solutions with the step and
+// the half of it, y = y_{h/2} + (y_{h/2} - y_h) / (2^p - 1), so the order of the result is higher, at the cost
+// of three solutions by the step h
+type Richardson struct {
+	Solver Interface
+	Order  int    // order p of the global error of the solver, e.g. 4 for Runge-Kutta's method
+	Errors Drawer // receives the estimate |y_{h/2} - y_h| / (2^p - 1) of the error at each node, if set
+}
+
+// Name returns the name of the method
+func (r *Richardson) Name() string { return r.Solver.Name() + " with Richardson's extrapolation" }
+
+// Solve solves the problem by the solver with the step and the half of it and draws extrapolated values
+// at nodes of the step
+func (r *Richardson) Solve(stepSize, x0, y0, xEnd float64, d Drawer) error {
+	if err := checkArgs(stepSize, x0, xEnd); err != nil {
+		return err
+	}
+	if r.Order < 1 {
+		return errors.Errorf("order of the method must be positive, got %d", r.Order)
+	}
+
+	coarse, err := Collect(r.Solver, stepSize, x0, y0, xEnd)
+	if err != nil {
+		return errors.Wrapf(err, "failed to solve with the step %v", stepSize)
+	}
+	fine, err := Collect(r.Solver, stepSize/2, x0, y0, xEnd)
+	if err != nil {
+		return errors.Wrapf(err, "failed to solve with the step %v", stepSize/2)
+	}
+	g := NewGrid(x0, xEnd, stepSize)
+	// nodes of the step are every second node of the half step, except the last one, that ends both grids
+	if len(coarse.Points) != g.N+1 || len(fine.Points) < 2*g.N {
+		return errors.Wrapf(ErrPointsCount, "solutions with steps %v and %v have %d and %d points",
+			stepSize, stepSize/2, len(coarse.Points), len(fine.Points))
+	}
+
+	denom := math.Exp2(float64(r.Order)) - 1
+	out, errs := sinkOf(r.Name(), d), sinkOf(r.Name(), r.Errors)
+	for i, p := range coarse.Points {
+		fp := fine.Points[len(fine.Points)-1]
+		if i < g.N {
+			fp = fine.Points[2*i]
+		}
+		diff := (fp.Y - p.Y) / denom
+		if err = out.put(i, g.Step(i), num.Point{X: p.X, Y: fp.Y + diff}); err != nil {
+			return err
+		}
+		if r.Errors == nil {
+			continue
+		}
+		if err = errs.put(i, g.Step(i), num.Point{X: p.X, Y: math.Abs(diff)}); err != nil {
+			return err
+		}
+	}
+	if err = out.flush(); err != nil {
+		return err
+	}
+	if r.Errors == nil {
+		return nil
+	}
+	return errs.flush()
+}
@@ -0,0 +1,76 @@
+package solver
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRichardson_Solve(t *testing.T) {
+	exact := &Exact{
+		F: func(x, c float64) (float64, error) { return math.Exp(-x) / (c*math.Exp(x) + 1), nil },
+		C: func(x0, y0 float64) (float64, error) { return (math.Exp(-x0) - y0) / (y0 * math.Exp(x0)), nil },
+	}
+	maxErr := func(s Interface, step float64) float64 {
+		line, err := Collect(s, step, 0, 1, 1)
+		require.NoError(t, err)
+		ex, err := Collect(exact, step, 0, 1, 1)
+		require.NoError(t, err)
+		require.Len(t, line.Points, len(ex.Points))
+		res := 0.0
+		for i, p := range line.Points {
+			assert.Equal(t, ex.Points[i].X, p.X)
+			res = math.Max(res, math.Abs(p.Y-ex.Points[i].Y))
+		}
+		return res
+	}
+
+	for _, tt := range []struct {
+		s     Interface
+		order int
+	}{{&Euler{F: canonical}, 1}, {&ImprovedEuler{F: canonical}, 2}} {
+		r := &Richardson{Solver: tt.s, Order: tt.order}
+		assert.Equal(t, tt.s.Name()+" with Richardson's extrapolation", r.Name())
+		assert.Less(t, maxErr(r, 0.01), maxErr(tt.s, 0.005)/10, "the extrapolation is more accurate than the half step")
+		order := math.Log2(maxErr(r, 0.02) / maxErr(r, 0.01))
+		assert.Greater(t, order, float64(tt.order)+0.8, "%s: the order is increased", tt.s.Name())
+	}
+
+	// the last step is shortened, as the step doesn't divide the interval
+	errs := &Collector{}
+	r := &Richardson{Solver: &Euler{F: canonical}, Order: 1, Errors: errs}
+	line, err := Collect(r, 0.03, 0, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, line.Points, 35)
+	require.Len(t, errs.Points, 35)
+	assert.Equal(t, 1.0, line.Points[34].X)
+	fine, err := Collect(&Euler{F: canonical}, 0.015, 0, 1, 1)
+	require.NoError(t, err)
+	ex, err := Collect(exact, 0.015, 0, 1, 1)
+	require.NoError(t, err)
+	last := len(fine.Points) - 1
+	actual := math.Abs(fine.Points[last].Y - ex.Points[last].Y)
+	assert.InDelta(t, actual, errs.Points[34].Y, actual/5, "the estimate is close to the error of the half step")
+	assert.Zero(t, errs.Points[0].Y)
+}
+
+func TestRichardson_Errors(t *testing.T) {
+	err := (&Richardson{Solver: &Euler{F: canonical}}).Solve(0.1, 0, 1, 1, &Collector{})
+	assert.EqualError(t, err, "order of the method must be positive, got 0")
+
+	r := &Richardson{Solver: &Euler{F: func(x, y float64) (float64, error) {
+		if x > 0.5 {
+			return 0, assert.AnError
+		}
+		return y, nil
+	}}, Order: 1}
+	err = r.Solve(0.1, 0, 1, 1, &Collector{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to solve with the step 0.1")
+}
+
+func BenchmarkRichardson(b *testing.B) {
+	benchSolver(b, &Richardson{Solver: &RungeKutta{F: benchF}, Order: 4})
+}
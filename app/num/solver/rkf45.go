@@ -0,0 +1,140 @@
+package solver
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// defaultRKF45Tol is the tolerance of the local error of RKF45, if it is not set
+const defaultRKF45Tol = 1e-6
+
+// RKF45 is the Runge-Kutta-Fehlberg method with the adaptive step, each step is made by the embedded pair
+// of the 4th and 5th orders, their difference estimates the local error, the step is rejected and retried
+// with the smaller one, if the error exceeds the tolerance, and grows after the accepted one, so drawn
+// points are the taken steps, not nodes of the uniform grid, the step drawer receives each taken step,
+// the meta drawer receives rejected steps too, with stages and estimates of local errors
+type RKF45 struct {
+	F       Func    // calculator for f(x,y) = y'
+	Tol     float64 // max estimated local error of the step, 1e-6 if zero
+	MinStep float64 // the least step, the solution fails, if the error exceeds the tolerance with it
+	MaxStep float64 // the largest step, the whole interval if zero
+}
+
+// factors of RKF45, the step is scaled by the safety factor of the ideal one, but not more than
+// by the max growth and not less than by the max shrink
+const (
+	rkf45Safety = 0.9
+	rkf45Grow   = 5.0
+	rkf45Shrink = 0.2
+)
+
+// rkf45Stages are nodes of stages of the step and coefficients of previous stages of the Fehlberg's tableau
+var rkf45Stages = [...]struct {
+	c float64
+	a []float64
+}{
+	{0, nil},
+	{1.0 / 4, []float64{1.0 / 4}},
+	{3.0 / 8, []float64{3.0 / 32, 9.0 / 32}},
+	{12.0 / 13, []float64{1932.0 / 2197, -7200.0 / 2197, 7296.0 / 2197}},
+	{1, []float64{439.0 / 216, -8, 3680.0 / 513, -845.0 / 4104}},
+	{1.0 / 2, []float64{-8.0 / 27, 2, -3544.0 / 2565, 1859.0 / 4104, -11.0 / 40}},
+}
+
+// Name returns the name of the method
+func (r *RKF45) Name() string { return "Runge-Kutta-Fehlberg's method" }
+
+// Solve the differential equation from x0 with the initial step size, which is adapted to the tolerance,
+// the last step is shortened to end at xEnd, the solution goes backward, if xEnd is less than x0
+func (r *RKF45) Solve(stepSize, x0, y0, xEnd float64, d Drawer) error {
+	if err := checkArgs(stepSize, x0, xEnd); err != nil {
+		return err
+	}
+	tol, minStep, maxStep := r.Tol, r.MinStep, r.MaxStep
+	if tol == 0 {
+		tol = defaultRKF45Tol
+	}
+	if maxStep == 0 {
+		maxStep = math.Abs(xEnd - x0)
+	}
+	if !(tol > 0) || math.IsInf(tol, 1) {
+		return errors.Errorf("tolerance must be positive and finite, got %v", tol)
+	}
+	if !(minStep >= 0) || !(maxStep >= minStep) {
+		return errors.Errorf("steps must be 0 <= min_step <= max_step, got min_step=%v and max_step=%v", minStep, maxStep)
+	}
+
+	logger(d).Logf("[DEBUG] starting solving the equation with Runge-Kutta-Fehlberg's "+
+		"method with stepsz = %.4f, tol = %v, x0 = %.4f, y0 = %.4f, xend = %.4f", stepSize, tol, x0, y0, xEnd)
+
+	// h is the size of the step, the solution goes by dir*h, backward on the reversed interval
+	h, dir := math.Max(minStep, math.Min(stepSize, maxStep)), direction(x0, xEnd)
+	out := sinkOf(r.Name(), d)
+	if err := out.put(0, 0, num.Point{X: x0, Y: y0}); err != nil {
+		return err
+	}
+	x, y := x0, y0
+	var k [6]float64
+	for i := 0; dir*(xEnd-x) > 0; {
+		last := h*(1+gridTolerance) >= dir*(xEnd-x) // the rounding error of x doesn't leave the tiny last step
+		if last {
+			h = dir * (xEnd - x)
+		}
+		next, errEst, err := r.step(i, x, y, dir*h, &k)
+		if err != nil {
+			return out.fail(err)
+		}
+		meta := Meta{Step: i + 1, H: dir * h, Order: 4, LocalErr: errEst, Stages: k[:]}
+
+		if !(errEst <= tol) { // the not finite estimate is rejected too
+			if err = out.reject(num.Point{X: x + dir*h, Y: next}, meta); err != nil {
+				return out.fail(err)
+			}
+			if h <= minStep || x+h*rkf45Shrink == x {
+				err := errors.Wrapf(num.ErrStepTooSmall, "local error %g exceeds the tolerance %g with the step %v",
+					errEst, tol, h)
+				return out.fail(&StepError{Method: r.Name(), Step: i, Stage: "adapt", X: x, Y: y, Err: err})
+			}
+			h = math.Max(minStep, h*math.Max(rkf45Shrink, rkf45Safety*math.Pow(tol/errEst, 0.25)))
+			continue
+		}
+
+		if x, y = x+dir*h, next; last {
+			x = xEnd
+		}
+		i++
+		if err = out.putMeta(num.Point{X: x, Y: y}, meta); err != nil {
+			return err
+		}
+
+		scale := rkf45Grow
+		if errEst > 0 {
+			scale = math.Min(rkf45Grow, rkf45Safety*math.Pow(tol/errEst, 0.2))
+		}
+		h = math.Max(minStep, math.Min(maxStep, h*math.Max(rkf45Shrink, scale)))
+	}
+
+	return out.flush()
+}
+
+// step makes the i-th step of the size h from (x, y) with stages k and returns y of the 4th order at its end
+// and the estimate of the local error, the difference with the 5th order
+func (r *RKF45) step(i int, x, y, h float64, k *[6]float64) (next, errEst float64, err error) {
+	for s, st := range rkf45Stages {
+		ys := y
+		for j, a := range st.a {
+			ys += h * a * k[j]
+		}
+		if k[s], err = r.F(x+st.c*h, ys); err != nil {
+			return 0, 0, &StepError{Method: r.Name(), Step: i, Stage: "k" + strconv.Itoa(s+1), X: x + st.c*h, Y: ys,
+				Err: err}
+		}
+	}
+
+	next = y + h*(25.0/216*k[0]+1408.0/2565*k[2]+2197.0/4104*k[3]-1.0/5*k[4])
+	errEst = math.Abs(h * (1.0/360*k[0] - 128.0/4275*k[2] - 2197.0/75240*k[3] + 1.0/50*k[4] + 2.0/55*k[5]))
+	return next, errEst, nil
+}
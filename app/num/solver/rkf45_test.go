@@ -0,0 +1,163 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRKF45_Solve(t *testing.T) {
+	exp := func(x, y float64) (float64, error) { return y, nil }
+	for _, tol := range []float64{1e-4, 1e-8} {
+		d := &stepCollector{}
+		require.NoError(t, (&RKF45{F: exp, Tol: tol}).Solve(0.5, 0, 1, 2, d))
+		last := d.Points[len(d.Points)-1]
+		assert.Equal(t, 2.0, last.X, "the last step ends at x_end")
+		assert.InDelta(t, math.Exp(2), last.Y, 100*tol, "tol=%v", tol)
+
+		sum := 0.0
+		for i, h := range d.hs {
+			assert.Equal(t, i, d.idx[i])
+			if i > 0 {
+				assert.Greater(t, d.Points[i].X, d.Points[i-1].X)
+				assert.InDelta(t, d.Points[i].X-d.Points[i-1].X, h, 1e-12)
+			}
+			sum += h
+		}
+		assert.InDelta(t, 2, sum, 1e-12, "steps cover the interval")
+	}
+
+	// the step shrinks, where the canonical solution changes fast, and grows, where it is flat
+	d := &stepCollector{}
+	require.NoError(t, (&RKF45{F: canonical, Tol: 1e-8}).Solve(0.1, -4, 1, 4, d))
+	hs := d.hs[1 : len(d.hs)-1] // the last step is shortened to end at x_end
+	least, largest := hs[0], hs[0]
+	for _, h := range hs {
+		least, largest = math.Min(least, h), math.Max(largest, h)
+	}
+	assert.Greater(t, largest/least, 5.0, "steps are adapted, from %v to %v", least, largest)
+	rk, err := Collect(&RungeKutta{F: canonical}, 8.0/4096, -4, 1, 4)
+	require.NoError(t, err)
+	assert.InDelta(t, rk.Points[len(rk.Points)-1].Y, d.Points[len(d.Points)-1].Y, 1e-6)
+	assert.Less(t, len(d.Points), 400, "far fewer points than the fine grid")
+
+	// steps are clamped by max_step
+	d = &stepCollector{}
+	require.NoError(t, (&RKF45{F: exp, Tol: 1e-2, MaxStep: 0.1}).Solve(1, 0, 1, 1, d))
+	for _, h := range d.hs {
+		assert.LessOrEqual(t, h, 0.1+1e-15)
+	}
+	assert.Len(t, d.Points, 11)
+
+	line, err := Collect(&RKF45{F: exp}, 0.1, 2, 3, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []num.Point{{X: 2, Y: 3}}, line.Points, "the empty interval is the single point")
+	assert.Equal(t, "Runge-Kutta-Fehlberg's method", line.Name)
+}
+
+func TestRKF45_Backward(t *testing.T) {
+	// the backward solution from the end of the forward one returns to y0 up to the tolerance
+	r := &RKF45{F: canonical, Tol: 1e-9}
+	fwd, err := Collect(r, 0.1, 0, 1, 2)
+	require.NoError(t, err)
+	end := fwd.Points[len(fwd.Points)-1]
+
+	d := &metaCollector{}
+	require.NoError(t, r.Solve(0.1, end.X, end.Y, 0, d))
+	last := d.Points[len(d.Points)-1]
+	assert.Equal(t, 0.0, last.X, "the last step back is shortened to end at x_end")
+	assert.InDelta(t, 1, last.Y, 1e-7)
+	for _, m := range d.accepted()[1:] {
+		assert.Less(t, m.H, 0.0, "steps back are negative")
+		assert.LessOrEqual(t, m.LocalErr, 1e-9)
+	}
+	for _, m := range d.metas {
+		assert.GreaterOrEqual(t, m.H, -2.0, "steps don't exceed the interval")
+	}
+}
+
+func TestRKF45_Meta(t *testing.T) {
+	exp := func(x, y float64) (float64, error) { return y, nil }
+	const tol = 1e-8
+	d := &metaCollector{}
+	require.NoError(t, (&RKF45{F: exp, Tol: tol}).Solve(1, 0, 1, 2, d))
+	want, err := Collect(&RKF45{F: exp, Tol: tol}, 1, 0, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, want.Points, d.Points, "rejected steps are not points of the solution")
+	require.NotEmpty(t, d.rejected, "the initial step is too large")
+	assert.Equal(t, 1.0, d.rejected[0].X, "the first step is rejected")
+
+	accepted := d.accepted()
+	require.Len(t, accepted, len(d.Points))
+	assert.Equal(t, Meta{}, accepted[0], "nothing is known of the initial point")
+	for i, m := range d.metas {
+		if i == 0 {
+			continue
+		}
+		assert.Equal(t, 4, m.Order)
+		assert.Len(t, m.Stages, 6)
+		assert.Greater(t, m.H, 0.0)
+		if m.Rejected {
+			assert.Greater(t, m.LocalErr, tol, "step %d", m.Step)
+			continue
+		}
+		assert.LessOrEqual(t, m.LocalErr, tol, "step %d", m.Step)
+		assert.Equal(t, d.Points[m.Step-1].Y, m.Stages[0], "the first stage is f at the start of the step")
+	}
+}
+
+func TestRKF45_Errors(t *testing.T) {
+	exp := func(x, y float64) (float64, error) { return y, nil }
+	for _, tt := range []struct {
+		s   *RKF45
+		err string
+	}{
+		{&RKF45{F: exp, Tol: -1}, "tolerance must be positive and finite, got -1"},
+		{&RKF45{F: exp, Tol: math.NaN()}, "tolerance must be positive and finite, got NaN"},
+		{&RKF45{F: exp, MinStep: 0.5, MaxStep: 0.1}, "steps must be 0 <= min_step <= max_step, got min_step=0.5 and max_step=0.1"},
+		{&RKF45{F: exp, MinStep: -1}, "steps must be 0 <= min_step <= max_step, got min_step=-1 and max_step=1"},
+	} {
+		d := &Collector{}
+		assert.EqualError(t, tt.s.Solve(0.1, 0, 1, 1, d), tt.err)
+		assert.Empty(t, d.Points)
+	}
+
+	err := (&RKF45{F: exp}).Solve(0, 0, 1, 1, &Collector{})
+	assert.True(t, errors.Is(err, num.ErrBadStep), err)
+
+	// the tolerance can't be met with the least step
+	d := &Collector{}
+	err = (&RKF45{F: exp, Tol: 1e-12, MinStep: 0.25}).Solve(0.5, 0, 1, 1, d)
+	assert.True(t, errors.Is(err, num.ErrStepTooSmall), err)
+	var se *StepError
+	require.True(t, errors.As(err, &se))
+	assert.Equal(t, "adapt", se.Stage)
+	assert.Equal(t, 0, se.Step)
+	assert.Len(t, d.Points, 1, "the initial point is drawn")
+
+	// the blown up solution can't be followed past its pole at x=1
+	d = &Collector{}
+	err = (&RKF45{F: func(x, y float64) (float64, error) { return y * y, nil }}).Solve(0.1, 0, 1, 2, d)
+	assert.True(t, errors.Is(err, num.ErrStepTooSmall), err)
+	assert.InDelta(t, 1, d.Points[len(d.Points)-1].X, 1e-2)
+
+	errF := errors.New("failed")
+	calls := 0
+	err = (&RKF45{F: func(x, y float64) (float64, error) {
+		if calls++; calls == 9 {
+			return 0, errF
+		}
+		return y, nil
+	}}).Solve(0.1, 0, 1, 1, &Collector{})
+	assert.True(t, errors.Is(err, errF))
+	require.True(t, errors.As(err, &se))
+	assert.Equal(t, "k3", se.Stage, "the third stage of the second step fails")
+	assert.Equal(t, 1, se.Step)
+}
+
+// BenchmarkRKF45 measures steps of the max size, so their number is the same as of other solvers
+func BenchmarkRKF45(b *testing.B) { benchSolver(b, &RKF45{F: benchF, MaxStep: 1.0 / benchSteps}) }
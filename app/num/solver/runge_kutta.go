@@ -1,52 +1,82 @@
 package solver
 
-import (
-	"github.com/Semior001/decompract/app/num"
-	log "github.com/go-pkgz/lgr"
-	"github.com/pkg/errors"
-)
+import "github.com/Semior001/decompract/app/num"
 
-// RungeKutta  method for solving initial value problem for differential equations
-type RungeKutta struct {
-	F func(x, y float64) (float64, error) // calculator for f(x,y) = y'
+// RungeKuttaOf is Runge-Kutta's method for solving initial value problem for differential equations
+// in the numeric type T, nodes of the grid are calculated in float64 and rounded to T
+type RungeKuttaOf[T num.Float] struct {
+	F FuncOf[T] // calculator for f(x,y) = y'
 }
 
+// RungeKutta  method for solving initial value problem for differential equations
+type RungeKutta = RungeKuttaOf[float64]
+
+// Name returns the name of the method
+func (r *RungeKuttaOf[T]) Name() string { return "Runge-Kutta's method" }
+
 // Solve the differential equation with the given initial values
-func (r *RungeKutta) Solve(stepSize, x0, y0, xEnd float64) (num.Line, error) {
-	x := x0
-	y := y0
-	var k1, k2, k3, k4 float64
-	var err error
+func (r *RungeKuttaOf[T]) Solve(stepSize, x0, y0, xEnd T, d DrawerOf[T]) error {
+	g, err := gridOf(stepSize, x0, xEnd)
+	if err != nil {
+		return err
+	}
 
-	log.Printf("[DEBUG] starting solving the equation with Runge-Kutta's "+
+	logger(d).Logf("[DEBUG] starting solving the equation with Runge-Kutta's "+
 		"method with stepsz = %.4f, x0 = %.4f, y0 = %.4f, xend = %.4f", stepSize, x0, y0, xEnd)
 
-	var pts []num.Point
+	return r.solve(g, 0, y0, d)
+}
+
+// Resume continues the solution from the checkpoint up to xEnd
+func (r *RungeKuttaOf[T]) Resume(cp Checkpoint, xEnd float64, d DrawerOf[T]) error {
+	g, err := cp.grid(xEnd)
+	if err != nil {
+		return err
+	}
+	return r.solve(g, cp.Node, T(cp.Y), d)
+}
+
+// solve solves the problem on the grid from the node, where the solution is y
+func (r *RungeKuttaOf[T]) solve(g Grid, from int, y T, d DrawerOf[T]) error {
+	var k1, k2, k3, k4 T
+	var err error
 
-	for x <= xEnd {
-		pts = append(pts, num.Point{X: x, Y: y})
+	out := sinkOf(r.Name(), d)
+	for i := from; i <= g.N; i++ {
+		x := T(g.X(i))
+		if err = out.put(i, g.Step(i), num.PointOf[T]{X: x, Y: y}); err != nil {
+			return err
+		}
+		if i == g.N {
+			break // the point is the last one, so the next step is not calculated
+		}
+		h := T(g.Step(i + 1)) // the last step might be shortened to end at xEnd
 
 		if k1, err = r.F(x, y); err != nil {
-			return num.Line{}, errors.Wrapf(err, "failed to calculate k1 for x=%.4f y=%.4f", x, y)
+			return out.fail(r.stepError(i, "k1", x, y, err))
 		}
 
-		if k2, err = r.F(x+stepSize/2.0, y+(stepSize/2.0)*k1); err != nil {
-			return num.Line{}, errors.Wrapf(err, "failed to calculate k2 for h=%.4f, x=%4.f, y=%.4f, k1=%.4f", stepSize, x, y, k1)
+		if k2, err = r.F(x+h/2.0, y+(h/2.0)*k1); err != nil {
+			return out.fail(r.stepError(i, "k2", x+h/2.0, y+(h/2.0)*k1, err))
 		}
 
-		if k3, err = r.F(x+stepSize/2.0, y+(stepSize/2.0)*k2); err != nil {
-			return num.Line{}, errors.Wrapf(err, "failed to calculate k3 for h=%.4f, x=%4.f, y=%.4f, k2=%.4f", stepSize, x, y, k2)
+		if k3, err = r.F(x+h/2.0, y+(h/2.0)*k2); err != nil {
+			return out.fail(r.stepError(i, "k3", x+h/2.0, y+(h/2.0)*k2, err))
 		}
 
-		if k4, err = r.F(x+stepSize, y+stepSize*k3); err != nil {
-			return num.Line{}, errors.Wrapf(err, "failed to calculate k4 for h=%.4f, x=%4.f, y=%.4f, k3=%.4f", stepSize, x, y, k3)
+		if k4, err = r.F(x+h, y+h*k3); err != nil {
+			return out.fail(r.stepError(i, "k4", x+h, y+h*k3, err))
 		}
 
-		deltaY := stepSize / 6.0 * (k1 + 2*k2 + 2*k3 + k4)
+		deltaY := h / 6.0 * (k1 + 2*k2 + 2*k3 + k4)
 
 		y = y + deltaY
-		x += stepSize
 	}
 
-	return num.Line{Name: "Runge-Kutta's method", Points: pts}, nil
+	return out.flush()
+}
+
+// stepError locates the failure of f at the stage of the i-th step
+func (r *RungeKuttaOf[T]) stepError(i int, stage string, x, y T, err error) error {
+	return &StepError{Method: r.Name(), Step: i, Stage: stage, X: float64(x), Y: float64(y), Err: err}
 }
@@ -0,0 +1,98 @@
+package solver
+
+import (
+	"math"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// sensitivityEps is the relative perturbation of y0 for the finite-difference sensitivity, the cube root
+// of the machine epsilon balances the truncation and the rounding errors of central differences
+var sensitivityEps = math.Cbrt(0x1p-52)
+
+// Sensitivity calculates the sensitivity dy(x)/dy0 of the solution by the method to its initial value
+// by the central difference of solutions from y0+eps and y0-eps on the grid of the step, eps is scaled
+// to the magnitude of y0, if zero is passed
+func Sensitivity(method Builder, f Func, step, x0, y0, xEnd, eps float64) ([]num.Point, error) {
+	if eps == 0 {
+		eps = sensitivityEps * math.Max(1, math.Abs(y0))
+	}
+	if !(eps > 0) || math.IsInf(eps, 1) {
+		return nil, errors.Errorf("perturbation of y0 must be positive and finite, got %v", eps)
+	}
+
+	up, err := Collect(method(f), step, x0, y0+eps, xEnd)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to solve from y0+%v", eps)
+	}
+	down, err := Collect(method(f), step, x0, y0-eps, xEnd)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to solve from y0-%v", eps)
+	}
+	if len(up.Points) != len(down.Points) {
+		return nil, errors.Wrapf(ErrPointsCount, "solutions have %d and %d points", len(up.Points), len(down.Points))
+	}
+
+	res := make([]num.Point, len(up.Points))
+	for i := range res {
+		res[i] = num.Point{X: up.Points[i].X, Y: (up.Points[i].Y - down.Points[i].Y) / (2 * eps)}
+	}
+	return res, nil
+}
+
+// Variational calculates the sensitivity dy(x)/dy0 as Sensitivity does, but by the variational equation
+// s' = df/dy(x,y) s, s(x0) = 1, solved along with the problem by Runge-Kutta's method, so the sensitivity
+// has no error of the finite difference, dfdy is the partial derivative of f by y
+func Variational(f, dfdy Func, step, x0, y0, xEnd float64) ([]num.Point, error) {
+	if err := checkArgs(step, x0, xEnd); err != nil {
+		return nil, err
+	}
+	const name = "Variational equation"
+	fail := func(i int, stage string, x, y float64, err error) error {
+		return &StepError{Method: name, Step: i, Stage: stage, X: x, Y: y, Err: err}
+	}
+	// ev evaluates the right-hand side of the augmented system
+	ev := func(x, y, s float64) (dy, ds float64, err error) {
+		if dy, err = f(x, y); err != nil {
+			return 0, 0, err
+		}
+		fy, err := dfdy(x, y)
+		if err != nil {
+			return 0, 0, errors.Wrap(err, "failed to evaluate df/dy")
+		}
+		return dy, fy * s, nil
+	}
+
+	g := NewGrid(x0, xEnd, step)
+	res := make([]num.Point, 0, g.N+1)
+	y, s := y0, 1.0
+	for i := 0; i <= g.N; i++ {
+		x := g.X(i)
+		res = append(res, num.Point{X: x, Y: s})
+		if i == g.N {
+			break
+		}
+		h := g.Step(i + 1)
+
+		k1y, k1s, err := ev(x, y, s)
+		if err != nil {
+			return nil, fail(i, "k1", x, y, err)
+		}
+		k2y, k2s, err := ev(x+h/2, y+h/2*k1y, s+h/2*k1s)
+		if err != nil {
+			return nil, fail(i, "k2", x+h/2, y+h/2*k1y, err)
+		}
+		k3y, k3s, err := ev(x+h/2, y+h/2*k2y, s+h/2*k2s)
+		if err != nil {
+			return nil, fail(i, "k3", x+h/2, y+h/2*k2y, err)
+		}
+		k4y, k4s, err := ev(x+h, y+h*k3y, s+h*k3s)
+		if err != nil {
+			return nil, fail(i, "k4", x+h, y+h*k3y, err)
+		}
+		y += h / 6 * (k1y + 2*k2y + 2*k3y + k4y)
+		s += h / 6 * (k1s + 2*k2s + 2*k3s + k4s)
+	}
+	return res, nil
+}
@@ -0,0 +1,71 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// y' = x^2 - 2y is linear in y, so its sensitivity to y0 is exp(-2(x-x0)), whatever y0 is
+var linearF = func(x, y float64) (float64, error) { return x*x - 2*y, nil }
+
+func TestSensitivity(t *testing.T) {
+	pts, err := Sensitivity(rk4Builder, linearF, 0.1, 0, 1, 1, 0)
+	require.NoError(t, err)
+	require.Len(t, pts, 11)
+	for _, p := range pts {
+		assert.InDelta(t, math.Exp(-2*p.X), p.Y, 1e-5, "x=%v", p.X)
+	}
+
+	// the discrete sensitivity of Euler's method is (1-2h)^i, its error from exp(-2x) is the correction
+	// of the first order in h
+	pts, err = Sensitivity(func(f Func) Interface { return &Euler{F: f} }, linearF, 0.1, 0, 5, 1, 1e-3)
+	require.NoError(t, err)
+	for i, p := range pts {
+		assert.InDelta(t, math.Pow(0.8, float64(i)), p.Y, 1e-9, "x=%v", p.X)
+	}
+
+	_, err = Sensitivity(rk4Builder, linearF, 0.1, 0, 1, 1, -1)
+	assert.EqualError(t, err, "perturbation of y0 must be positive and finite, got -1")
+
+	// backward from x = 1 the sensitivity grows as exp(-2(x-1))
+	pts, err = Sensitivity(rk4Builder, linearF, 0.1, 1, 1, 0, 1e-3)
+	require.NoError(t, err)
+	require.Len(t, pts, 11)
+	for _, p := range pts {
+		assert.InDelta(t, math.Exp(-2*(p.X-1)), p.Y, 1e-3, "x=%v", p.X)
+	}
+}
+
+func TestVariational(t *testing.T) {
+	dfdy := func(x, y float64) (float64, error) { return -2, nil }
+	pts, err := Variational(linearF, dfdy, 0.1, 0, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, pts, 11)
+	assert.Equal(t, num.Point{X: 0, Y: 1}, pts[0])
+	fd, err := Sensitivity(rk4Builder, linearF, 0.1, 0, 1, 1, 0)
+	require.NoError(t, err)
+	for i, p := range pts {
+		assert.InDelta(t, math.Exp(-2*p.X), p.Y, 1e-5, "x=%v", p.X)
+		assert.InDelta(t, fd[i].Y, p.Y, 1e-8, "both ways give the discrete sensitivity of rk4")
+	}
+
+	// y' = y^2 from y0 = 1 is 1/(1-x), its sensitivity is 1/(1-x)^2
+	pts, err = Variational(func(x, y float64) (float64, error) { return y * y, nil },
+		func(x, y float64) (float64, error) { return 2 * y, nil }, 0.001, 0, 1, 0.5)
+	require.NoError(t, err)
+	for _, p := range pts {
+		assert.InDelta(t, 1/((1-p.X)*(1-p.X)), p.Y, 1e-8, "x=%v", p.X)
+	}
+
+	errD := errors.New("dfdy failed")
+	_, err = Variational(linearF, func(x, y float64) (float64, error) { return 0, errD }, 0.1, 0, 1, 1)
+	assert.True(t, errors.Is(err, errD))
+	var se *StepError
+	require.True(t, errors.As(err, &se))
+	assert.Equal(t, "k1", se.Stage)
+}
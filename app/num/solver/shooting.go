@@ -0,0 +1,249 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// Func2 calculates the second derivative of y as f(x,y,y')
+type Func2 func(x, y, dy float64) (float64, error)
+
+// defaults of the shooting method
+const (
+	defaultShootingTol  = 1e-9
+	defaultShootingIter = 50
+)
+
+// ShootingError is returned, if the shooting method doesn't hit the boundary value
+type ShootingError struct {
+	Iterations int     // iterations of the root finder
+	Slope      float64 // the last guess of y'(x0)
+	Residual   float64 // y(xEnd) - Beta with the last guess
+}
+
+// Error returns the last guess with its residual
+func (e *ShootingError) Error() string {
+	return fmt.Sprintf("shooting is not converged in %d iterations, y'(x0)=%.6g misses y(x_end) by %.3g",
+		e.Iterations, e.Slope, e.Residual)
+}
+
+// Shooting method solves the two-point boundary value problem with the second derivative f(x, y, y'),
+// y(x0) = y0 and y(xEnd) = Beta, it guesses the initial slope y'(x0), integrates the problem, reduced
+// to the first order system, up to xEnd with Runge-Kutta's method, or with System, on the grid of other solvers
+// and refines the slope by the secant method, or bisects the bracket of slopes, if it is set, until y(xEnd)
+// hits Beta, only the trajectory with the final slope is drawn
+type Shooting struct {
+	F       Func2   // calculator for the second derivative f(x,y,y')
+	Beta    float64 // the boundary value y(xEnd)
+	Tol     float64 // tolerance of |y(xEnd) - Beta|, defaultShootingTol if not set
+	MaxIter int     // max iterations of the root finder, defaultShootingIter if not set
+	// the bracket of y'(x0), bisected instead of the secant method, if SlopeMin < SlopeMax,
+	// residuals at its ends must differ in sign
+	SlopeMin, SlopeMax float64
+	System             func(f SystemFunc) SystemInterface // solver of the reduced problem, Runge-Kutta's method if nil
+
+	mu    sync.Mutex
+	iters int // iterations of the root finder of the last solution
+}
+
+// Name returns the name of the method
+func (s *Shooting) Name() string { return "Shooting method" }
+
+// Iterations returns the number of iterations of the root finder of the last solution, either secant
+// or bisection ones, zero if the first guess hits the boundary value
+func (s *Shooting) Iterations() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.iters
+}
+
+// Solve the boundary value problem, y0 is the boundary value at x0, the problem, that is not solved
+// in MaxIter iterations, fails with ShootingError and draws nothing
+func (s *Shooting) Solve(stepSize, x0, y0, xEnd float64, d Drawer) error {
+	if err := checkArgs(stepSize, x0, xEnd); err != nil {
+		return err
+	}
+	tol, maxIter := s.Tol, s.MaxIter
+	if tol <= 0 {
+		tol = defaultShootingTol
+	}
+	if maxIter <= 0 {
+		maxIter = defaultShootingIter
+	}
+	if math.IsNaN(s.SlopeMin) || math.IsNaN(s.SlopeMax) || s.SlopeMin > s.SlopeMax {
+		return errors.Errorf("bracket of slopes must be slope_min <= slope_max, got [%v, %v]", s.SlopeMin, s.SlopeMax)
+	}
+
+	logger(d).Logf("[DEBUG] starting solving the boundary value problem with the shooting "+
+		"method with stepsz = %.4f, x0 = %.4f, y0 = %.4f, xend = %.4f, beta = %.4f", stepSize, x0, y0, xEnd, s.Beta)
+
+	g := NewGrid(x0, xEnd, stepSize)
+	shoot := func(slope float64) (float64, error) {
+		if s.System == nil {
+			return s.shoot(g, y0, slope, nil)
+		}
+		last := y0
+		err := s.reduced(slope).SolveAll(stepSize, x0, y0, xEnd, VectorDrawerFunc(func(p num.VectorPoint) error {
+			last = p.Y[0]
+			return nil
+		}))
+		return last - s.Beta, err
+	}
+
+	find := s.secant
+	if s.SlopeMin < s.SlopeMax {
+		find = s.bisect
+	}
+	// the first guess of the secant method is the slope of the chord
+	s0 := 0.0
+	if xEnd != x0 {
+		s0 = (s.Beta - y0) / (xEnd - x0)
+	}
+	slope, res, iter, err := find(shoot, s0, tol, maxIter)
+	s.mu.Lock()
+	s.iters = iter
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	logger(d).Logf("[DEBUG] shooting is converged with y'(x0) = %.6g, residual = %.3g in %d iterations",
+		slope, res, iter)
+	if s.System != nil {
+		return drawFirst(s.Name(), x0, d, func(vd VectorDrawer) error {
+			return s.reduced(slope).SolveAll(stepSize, x0, y0, xEnd, vd)
+		})
+	}
+	out := sinkOf(s.Name(), d)
+	if _, err = s.shoot(g, y0, slope, &out); err != nil {
+		return err
+	}
+	return out.flush()
+}
+
+// secant refines the slope from s0 by the secant method, until the residual is within the tolerance
+func (s *Shooting) secant(shoot func(slope float64) (float64, error), s0, tol float64,
+	maxIter int) (slope, res float64, iter int, err error) {
+	r0, err := shoot(s0)
+	if err != nil || math.Abs(r0) <= tol {
+		return s0, r0, 0, err
+	}
+	s1 := s0 + 1
+	r1, err := shoot(s1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	for ; math.Abs(r1) > tol && iter < maxIter; iter++ {
+		if r1 == r0 || !isFinite(r1) {
+			break // the boundary value doesn't depend on the slope, or the solution diverged
+		}
+		s0, s1 = s1, s1-r1*(s1-s0)/(r1-r0)
+		r0 = r1
+		if r1, err = shoot(s1); err != nil {
+			return 0, 0, iter, err
+		}
+	}
+	if !(math.Abs(r1) <= tol) {
+		return 0, 0, iter, &ShootingError{Iterations: iter, Slope: s1, Residual: r1}
+	}
+	return s1, r1, iter, nil
+}
+
+// bisect halves the bracket of slopes, keeping the half, where the residual changes its sign,
+// until the residual at the middle is within the tolerance
+func (s *Shooting) bisect(shoot func(slope float64) (float64, error), _, tol float64,
+	maxIter int) (slope, res float64, iter int, err error) {
+	lo, hi := s.SlopeMin, s.SlopeMax
+	rlo, err := shoot(lo)
+	if err != nil || math.Abs(rlo) <= tol {
+		return lo, rlo, 0, err
+	}
+	rhi, err := shoot(hi)
+	if err != nil || math.Abs(rhi) <= tol {
+		return hi, rhi, 0, err
+	}
+	if !(rlo*rhi < 0) {
+		return 0, 0, 0, errors.Errorf("residuals %.3g and %.3g at ends of the bracket [%v, %v] don't differ in sign",
+			rlo, rhi, lo, hi)
+	}
+	mid, rmid := lo, rlo
+	for iter < maxIter {
+		mid = lo + (hi-lo)/2
+		iter++
+		if rmid, err = shoot(mid); err != nil {
+			return 0, 0, iter, err
+		}
+		if math.Abs(rmid) <= tol || mid == lo || mid == hi {
+			break // the bracket can't be halved further
+		}
+		if (rmid < 0) == (rlo < 0) {
+			lo, rlo = mid, rmid
+		} else {
+			hi = mid
+		}
+	}
+	if !(math.Abs(rmid) <= tol) {
+		return 0, 0, iter, &ShootingError{Iterations: iter, Slope: mid, Residual: rmid}
+	}
+	return mid, rmid, iter, nil
+}
+
+// reduced returns the problem with the initial slope, reduced to the first order system, solved by System
+func (s *Shooting) reduced(slope float64) *HigherOrder {
+	f := func(x float64, ys []float64) (float64, error) { return s.F(x, ys[0], ys[1]) }
+	return &HigherOrder{F: f, DY0: []float64{slope}, System: s.System}
+}
+
+// shoot integrates the problem with the initial slope and returns the residual y(xEnd) - Beta,
+// points are drawn to the sink, if it is set
+func (s *Shooting) shoot(g Grid, y0, slope float64, out *sink[float64]) (float64, error) {
+	y, z := y0, slope // z = y'
+	var k1y, k1z, k2y, k2z, k3y, k3z, k4y, k4z float64
+	var err error
+	for i := 0; i <= g.N; i++ {
+		x := g.X(i)
+		if out != nil {
+			if err = out.put(i, g.Step(i), num.Point{X: x, Y: y}); err != nil {
+				return 0, err
+			}
+		}
+		if i == g.N {
+			break // the point is the last one, so the next step is not calculated
+		}
+		h := g.Step(i + 1) // the last step might be shortened to end at xEnd
+
+		k1y = z
+		if k1z, err = s.F(x, y, z); err != nil {
+			return 0, s.fail(out, i, "k1", x, y, err)
+		}
+		k2y = z + h/2*k1z
+		if k2z, err = s.F(x+h/2, y+h/2*k1y, k2y); err != nil {
+			return 0, s.fail(out, i, "k2", x+h/2, y+h/2*k1y, err)
+		}
+		k3y = z + h/2*k2z
+		if k3z, err = s.F(x+h/2, y+h/2*k2y, k3y); err != nil {
+			return 0, s.fail(out, i, "k3", x+h/2, y+h/2*k2y, err)
+		}
+		k4y = z + h*k3z
+		if k4z, err = s.F(x+h, y+h*k3y, k4y); err != nil {
+			return 0, s.fail(out, i, "k4", x+h, y+h*k3y, err)
+		}
+
+		y += h / 6 * (k1y + 2*k2y + 2*k3y + k4y)
+		z += h / 6 * (k1z + 2*k2z + 2*k3z + k4z)
+	}
+	return y - s.Beta, nil
+}
+
+// fail locates the failure of f at the stage of the i-th step, points before it are flushed to the sink, if any
+func (s *Shooting) fail(out *sink[float64], i int, stage string, x, y float64, err error) error {
+	serr := &StepError{Method: s.Name(), Step: i, Stage: stage, X: x, Y: y, Err: err}
+	if out == nil {
+		return serr
+	}
+	return out.fail(serr)
+}
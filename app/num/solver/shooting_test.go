@@ -0,0 +1,120 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShooting_Solve(t *testing.T) {
+	// y'' = -y, y(0) = 0, y(pi/2) = 1 is solved by sin(x)
+	s := &Shooting{F: func(x, y, dy float64) (float64, error) { return -y, nil }, Beta: 1}
+	line, err := Collect(s, math.Pi/200, 0, 0, math.Pi/2)
+	require.NoError(t, err)
+	assert.Equal(t, "Shooting method", line.Name)
+	require.Len(t, line.Points, 101)
+	for _, p := range line.Points {
+		assert.InDelta(t, math.Sin(p.X), p.Y, 1e-8, "x=%v", p.X)
+	}
+	assert.Equal(t, math.Pi/2, line.Points[100].X)
+	assert.InDelta(t, 1, line.Points[100].Y, defaultShootingTol)
+
+	// the nonlinear problem y'' = 3/2 y^2, y(0) = 4, y(1) = 1 is solved by 4/(1+x)^2
+	s = &Shooting{F: func(x, y, dy float64) (float64, error) { return 1.5 * y * y, nil }, Beta: 1}
+	line, err = Collect(s, 0.01, 0, 4, 1)
+	require.NoError(t, err)
+	for _, p := range line.Points {
+		assert.InDelta(t, 4/((1+p.X)*(1+p.X)), p.Y, 1e-6, "x=%v", p.X)
+	}
+}
+
+func TestShooting_Iterations(t *testing.T) {
+	// the linear problem is hit by the single secant iteration
+	s := &Shooting{F: func(x, y, dy float64) (float64, error) { return -y, nil }, Beta: 1}
+	_, err := Collect(s, math.Pi/200, 0, 0, math.Pi/2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, s.Iterations())
+
+	// the bracket is bisected, it takes more iterations, but ends at the same trajectory
+	want, err := Collect(&Shooting{F: func(x, y, dy float64) (float64, error) { return 1.5 * y * y, nil }, Beta: 1},
+		0.01, 0, 4, 1)
+	require.NoError(t, err)
+	s = &Shooting{F: func(x, y, dy float64) (float64, error) { return 1.5 * y * y, nil }, Beta: 1,
+		SlopeMin: -10, SlopeMax: 0}
+	line, err := Collect(s, 0.01, 0, 4, 1)
+	require.NoError(t, err)
+	assert.Greater(t, s.Iterations(), 10)
+	require.Len(t, line.Points, len(want.Points))
+	for i, p := range line.Points {
+		assert.InDelta(t, want.Points[i].Y, p.Y, 1e-6, "x=%v", p.X)
+	}
+
+	// the reduced problem is solved by the given solver of systems, Euler's method is less accurate
+	s = &Shooting{F: func(x, y, dy float64) (float64, error) { return -y, nil }, Beta: 1,
+		System: func(f SystemFunc) SystemInterface { return &SystemEuler{F: f} }}
+	line, err = Collect(s, math.Pi/200, 0, 0, math.Pi/2)
+	require.NoError(t, err)
+	assert.Equal(t, "Shooting method", line.Name)
+	require.Len(t, line.Points, 101)
+	assert.InDelta(t, 1, line.Points[100].Y, defaultShootingTol)
+	assert.InDelta(t, math.Sin(math.Pi/4), line.Points[50].Y, 1e-2)
+	assert.Greater(t, math.Abs(math.Sin(math.Pi/4)-line.Points[50].Y), 1e-6)
+}
+
+func TestShooting_NotConverged(t *testing.T) {
+	// the tolerance below the rounding error is never hit, the last residual is reported
+	d := &Collector{}
+	s := &Shooting{F: func(x, y, dy float64) (float64, error) { return -y, nil }, Beta: 1, Tol: 1e-300}
+	err := s.Solve(math.Pi/100, 0, 0, math.Pi/2, d)
+	var se *ShootingError
+	require.True(t, errors.As(err, &se), "%v", err)
+	assert.Less(t, math.Abs(se.Residual), 1e-12, "the slope is found up to the rounding error")
+	assert.InDelta(t, 1, se.Slope, 1e-6)
+	assert.Empty(t, d.Points, "the trajectory is drawn only after the convergence")
+
+	// the nonlinear problem needs more than the single iteration
+	s = &Shooting{F: func(x, y, dy float64) (float64, error) { return 1.5 * y * y, nil }, Beta: 1, MaxIter: 1}
+	err = s.Solve(0.01, 0, 4, 1, d)
+	require.True(t, errors.As(err, &se), "%v", err)
+	assert.Equal(t, 1, se.Iterations)
+	assert.Greater(t, math.Abs(se.Residual), defaultShootingTol)
+	assert.Contains(t, err.Error(), "shooting is not converged in 1 iterations")
+
+	// the bracket is not halved enough
+	s = &Shooting{F: func(x, y, dy float64) (float64, error) { return -y, nil }, Beta: 1, MaxIter: 3,
+		SlopeMin: 0, SlopeMax: 3}
+	err = s.Solve(0.1, 0, 0, 1, d)
+	require.True(t, errors.As(err, &se), "%v", err)
+	assert.Equal(t, 3, se.Iterations)
+	assert.Equal(t, 3, s.Iterations())
+	assert.Empty(t, d.Points)
+}
+
+func TestShooting_Errors(t *testing.T) {
+	errF := errors.New("f failed")
+	s := &Shooting{F: func(x, y, dy float64) (float64, error) {
+		if x > 0.5 {
+			return 0, errF
+		}
+		return -y, nil
+	}, Beta: 1}
+	err := s.Solve(0.1, 0, 0, 1, &Collector{})
+	assert.True(t, errors.Is(err, errF))
+	var se *StepError
+	require.True(t, errors.As(err, &se))
+	assert.Equal(t, "Shooting method", se.Method)
+	assert.Equal(t, "k2", se.Stage, "the midpoint of the step from x=0.5 fails first")
+
+	assert.True(t, errors.Is(s.Solve(0, 0, 0, 1, &Collector{}), num.ErrBadStep))
+
+	s = &Shooting{F: func(x, y, dy float64) (float64, error) { return -y, nil }, Beta: 1, SlopeMin: 1, SlopeMax: -1}
+	assert.EqualError(t, s.Solve(0.1, 0, 0, 1, &Collector{}), "bracket of slopes must be slope_min <= slope_max, got [1, -1]")
+	s.SlopeMin, s.SlopeMax = 2, 3
+	assert.EqualError(t, s.Solve(0.1, 0, 0, 1, &Collector{}),
+		"residuals 0.683 and 1.52 at ends of the bracket [2, 3] don't differ in sign")
+}
@@ -1,11 +1,79 @@
 package solver
 
 import (
+	"fmt"
+	"sync/atomic"
+
 	"github.com/Semior001/decompract/app/num"
 )
 
-// Interface describes methods that the solver should implement
+// FuncOf calculates the value of f(x,y) = y' in the numeric type T
+type FuncOf[T num.Float] func(x, y T) (T, error)
+
+// Func calculates the value of f(x,y) = y'
+type Func = FuncOf[float64]
+
+// CountingFunc counts evaluations of the wrapped function, e.g. to measure the cost of the method,
+// it is safe for concurrent use
+type CountingFunc struct {
+	F     Func
+	calls int64
+}
+
+// Eval evaluates the wrapped function and counts the call, it is passed to solvers as Func
+func (c *CountingFunc) Eval(x, y float64) (float64, error) {
+	atomic.AddInt64(&c.calls, 1)
+	return c.F(x, y)
+}
+
+// Calls returns the number of evaluations since the creation or the last reset
+func (c *CountingFunc) Calls() int { return int(atomic.LoadInt64(&c.calls)) }
+
+// Reset zeroes the number of evaluations
+func (c *CountingFunc) Reset() { atomic.StoreInt64(&c.calls, 0) }
+
+// InterfaceOf describes methods that the solver over the numeric type T should implement
 // in order to solve the Initial Value problem
-type Interface interface {
-	Solve(stepSize, x0, y0, xEnd float64) (line num.Line, err error)
+type InterfaceOf[T num.Float] interface {
+	// Name returns the human-readable name of the method
+	Name() string
+	// Solve calculates the solution with the given step size
+	// and passes each calculated point to the drawer
+	Solve(stepSize, x0, y0, xEnd T, d DrawerOf[T]) error
+}
+
+// Interface is the solver over float64, the registry, the api and analyzers work with it
+type Interface = InterfaceOf[float64]
+
+// Collect solves the initial value problem with the given solver
+// and collects all calculated points into the line
+func Collect(s Interface, stepSize, x0, y0, xEnd float64) (num.Line, error) {
+	c := &Collector{}
+	if err := s.Solve(stepSize, x0, y0, xEnd, c); err != nil {
+		return num.Line{}, err
+	}
+	return num.Line{Name: s.Name(), Points: c.Points}, nil
+}
+
+// StepError is the failure of the solver at the step of the solution, it keeps the point,
+// at which f or the drawer failed, and unwraps to the error of f or of the drawer
+type StepError struct {
+	Method string  // name of the method
+	Step   int     // index of the node of the grid, from which the step is made, or which is drawn
+	Stage  string  // the failed stage of the step, e.g. k2 of Runge-Kutta's method, or draw
+	X, Y   float64 // the point, at which f is evaluated, y is the constant c for the exact solution
+	Err    error
+}
+
+// Error returns the location of the failure with the message of the cause
+func (e *StepError) Error() string {
+	return fmt.Sprintf("%s failed at step %d, %s at x=%.4f y=%.4f: %v", e.Method, e.Step, e.Stage, e.X, e.Y, e.Err)
+}
+
+// Unwrap returns the error of f or of the drawer
+func (e *StepError) Unwrap() error { return e.Err }
+
+// Locate returns the location of the failure, so the api might report it without the dependency on solvers
+func (e *StepError) Locate() (method string, step int, stage string, x, y float64) {
+	return e.Method, e.Step, e.Stage, e.X, e.Y
 }
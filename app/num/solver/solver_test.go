@@ -1,10 +1,16 @@
 package solver
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"math"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/expr"
 
 	log "github.com/go-pkgz/lgr"
 	"github.com/stretchr/testify/assert"
@@ -23,17 +29,17 @@ func TestSolvers(t *testing.T) {
 			solver: &Euler{F: func(x, y float64) (float64, error) { return x*x - 2.0*y, nil }},
 			name:   "Euler's method",
 			points: []num.Point{
-				{0.0, 1.00000},
-				{0.1, 0.80000},
-				{0.2, 0.64100},
-				{0.3, 0.51680},
-				{0.4, 0.42244},
-				{0.5, 0.35395},
-				{0.6, 0.30816},
-				{0.7, 0.28253},
-				{0.8, 0.27502},
-				{0.9, 0.28402},
-				{1.0, 0.30821},
+				{X: 0.0, Y: 1.00000},
+				{X: 0.1, Y: 0.80000},
+				{X: 0.2, Y: 0.64100},
+				{X: 0.3, Y: 0.51680},
+				{X: 0.4, Y: 0.42244},
+				{X: 0.5, Y: 0.35395},
+				{X: 0.6, Y: 0.30816},
+				{X: 0.7, Y: 0.28253},
+				{X: 0.8, Y: 0.27502},
+				{X: 0.9, Y: 0.28402},
+				{X: 1.0, Y: 0.30821},
 			},
 			precision: 0.00001,
 		},
@@ -41,17 +47,17 @@ func TestSolvers(t *testing.T) {
 			solver: &ImprovedEuler{F: func(x, y float64) (float64, error) { return x*x - 2.0*y, nil }},
 			name:   "Improved Euler's method",
 			points: []num.Point{
-				{0.0, 1.000000},
-				{0.1, 0.820250},
-				{0.2, 0.674755},
-				{0.3, 0.559149},
-				{0.4, 0.469852},
-				{0.5, 0.403929},
-				{0.6, 0.358972},
-				{0.7, 0.333007},
-				{0.8, 0.324416},
-				{0.9, 0.331871},
-				{1.0, 0.354284},
+				{X: 0.0, Y: 1.000000},
+				{X: 0.1, Y: 0.820250},
+				{X: 0.2, Y: 0.674755},
+				{X: 0.3, Y: 0.559149},
+				{X: 0.4, Y: 0.469852},
+				{X: 0.5, Y: 0.403929},
+				{X: 0.6, Y: 0.358972},
+				{X: 0.7, Y: 0.333007},
+				{X: 0.8, Y: 0.324416},
+				{X: 0.9, Y: 0.331871},
+				{X: 1.0, Y: 0.354284},
 			},
 			precision: 0.000001,
 		},
@@ -59,24 +65,24 @@ func TestSolvers(t *testing.T) {
 			solver: &RungeKutta{F: func(x, y float64) (float64, error) { return x*x - 2.0*y, nil }},
 			name:   "Runge-Kutta's method",
 			points: []num.Point{
-				{0.0, 1.000000},
-				{0.1, 0.819051},
-				{0.2, 0.672745},
-				{0.3, 0.556615},
-				{0.4, 0.467004},
-				{0.5, 0.400917},
-				{0.6, 0.355903},
-				{0.7, 0.329955},
-				{0.8, 0.321430},
-				{0.9, 0.328982},
-				{1.0, 0.351509},
+				{X: 0.0, Y: 1.000000},
+				{X: 0.1, Y: 0.819051},
+				{X: 0.2, Y: 0.672745},
+				{X: 0.3, Y: 0.556615},
+				{X: 0.4, Y: 0.467004},
+				{X: 0.5, Y: 0.400917},
+				{X: 0.6, Y: 0.355903},
+				{X: 0.7, Y: 0.329955},
+				{X: 0.8, Y: 0.321430},
+				{X: 0.9, Y: 0.328982},
+				{X: 1.0, Y: 0.351509},
 			},
 			precision: 0.000001,
 		},
 	}
 
 	for _, entry := range tbl {
-		line, err := entry.solver.Solve(0.1, 0, 1, 1)
+		line, err := Collect(entry.solver, 0.1, 0, 1, 1)
 		require.NoError(t, err)
 		assert.Equal(t, len(entry.points), len(line.Points), "len are different for method %s", entry.name)
 		assert.Equal(t, entry.name, line.Name, "names, method: %s", entry.name)
@@ -86,32 +92,86 @@ func TestSolvers(t *testing.T) {
 		}
 	}
 }
+
+// single is the custom numeric type over float32
+type single float32
+
+// singleBatch collects points of the solution in float32, that are passed in batches
+type singleBatch struct{ pts []num.PointOf[single] }
+
+func (b *singleBatch) Draw(p num.PointOf[single]) error { return b.DrawBatch([]num.PointOf[single]{p}) }
+
+func (b *singleBatch) DrawBatch(pts []num.PointOf[single]) error {
+	b.pts = append(b.pts, pts...)
+	return nil
+}
+
+func TestSolvers_Generic(t *testing.T) {
+	f64 := func(x, y float64) (float64, error) { return x*x - 2.0*y, nil }
+	f32 := func(x, y single) (single, error) { return x*x - 2.0*y, nil }
+	for _, tt := range []struct {
+		single InterfaceOf[single]
+		double Interface
+	}{
+		{&EulerOf[single]{F: f32}, &Euler{F: f64}},
+		{&ImprovedEulerOf[single]{F: f32}, &ImprovedEuler{F: f64}},
+		{&RungeKuttaOf[single]{F: f32}, &RungeKutta{F: f64}},
+	} {
+		want, err := Collect(tt.double, 0.01, 0, 1, 1)
+		require.NoError(t, err)
+
+		var pts []num.PointOf[single]
+		err = tt.single.Solve(0.01, 0, 1, 1, DrawerFuncOf[single](func(p num.PointOf[single]) error {
+			pts = append(pts, p)
+			return nil
+		}))
+		require.NoError(t, err)
+		require.Len(t, pts, len(want.Points), tt.single.Name())
+		batch := &singleBatch{}
+		require.NoError(t, tt.single.Solve(0.01, 0, 1, 1, batch))
+		assert.Equal(t, pts, batch.pts, "points in batches are the same, %s", tt.single.Name())
+
+		// the step 0.01 in float32 differs from the float64 one, the rounding in float32 accumulates far below
+		// the error of the method
+		for i, p := range pts {
+			assert.InDelta(t, want.Points[i].X, float64(p.X), 1e-6, tt.single.Name())
+			assert.InDelta(t, want.Points[i].Y, float64(p.Y), 1e-5, tt.single.Name())
+		}
+	}
+
+	err := (&RungeKuttaOf[float32]{F: func(x, y float32) (float32, error) { return 0, errors.New("failed") }}).
+		Solve(0.1, 0, 1, 1, DrawerFuncOf[float32](func(num.PointOf[float32]) error { return nil }))
+	var se *StepError
+	require.True(t, errors.As(err, &se))
+	assert.Equal(t, "k1", se.Stage)
+}
+
 func TestExact_Solve(t *testing.T) {
 	e := &Exact{
 		F: func(x, c float64) (float64, error) { return math.Exp(-x) / (c*math.Exp(x) + 1), nil },
 		C: func(x0, y0 float64) (float64, error) { return (math.Exp(-x0) - y0) / (y0 * math.Exp(x0)), nil }, // 2926.3598370085842
 	}
 	points := []num.Point{
-		{-4.0, 1.00000000},
-		{-3.5, 0.37054986},
-		{-3.0, 0.13692051},
-		{-2.5, 0.05050571},
-		{-2.0, 0.01861037},
-		{-1.5, 0.00685316},
-		{-1.0, 0.00252266},
-		{-0.5, 0.00092837},
-		{+0.0, 0.00034160},
-		{+0.5, 0.00012569},
-		{+1.0, 0.00004624},
-		{+1.5, 0.00001701},
-		{+2.0, 0.00000626},
-		{+2.5, 0.00000230},
-		{+3.0, 0.00000085},
-		{+3.5, 0.00000031},
-		{+4.0, 0.00000011},
-	}
-
-	line, err := e.Solve(0.5, -4, 1, 4)
+		{X: -4.0, Y: 1.00000000},
+		{X: -3.5, Y: 0.37054986},
+		{X: -3.0, Y: 0.13692051},
+		{X: -2.5, Y: 0.05050571},
+		{X: -2.0, Y: 0.01861037},
+		{X: -1.5, Y: 0.00685316},
+		{X: -1.0, Y: 0.00252266},
+		{X: -0.5, Y: 0.00092837},
+		{X: +0.0, Y: 0.00034160},
+		{X: +0.5, Y: 0.00012569},
+		{X: +1.0, Y: 0.00004624},
+		{X: +1.5, Y: 0.00001701},
+		{X: +2.0, Y: 0.00000626},
+		{X: +2.5, Y: 0.00000230},
+		{X: +3.0, Y: 0.00000085},
+		{X: +3.5, Y: 0.00000031},
+		{X: +4.0, Y: 0.00000011},
+	}
+
+	line, err := Collect(e, 0.5, -4, 1, 4)
 	require.NoError(t, err)
 	assert.Equal(t, len(points), len(line.Points), "len are different")
 	assert.Equal(t, "Exact solution", line.Name, "names")
@@ -121,10 +181,520 @@ func TestExact_Solve(t *testing.T) {
 	}
 }
 
+// bracketCollector collects points and discontinuities of the exact solution
+type bracketCollector struct {
+	Collector
+	found []Bracket
+}
+
+func (b *bracketCollector) DrawDiscontinuities(found []Bracket) error {
+	b.found = found
+	return nil
+}
+
+func TestExact_Discontinuities(t *testing.T) {
+	family := func(x, c float64) (float64, error) { return math.Exp(-x) / (c*math.Exp(x) + 1), nil }
+	// c*e^x + 1 = 0 at x = -ln(-c), so the constant places the pole
+	pole := func(at float64) func(float64, float64) (float64, error) {
+		return func(float64, float64) (float64, error) { return -math.Exp(-at), nil }
+	}
+	discontinuities := func(e *Exact, h, x0, xEnd float64) []Bracket {
+		bc := &bracketCollector{}
+		require.NoError(t, e.Solve(h, x0, 1, xEnd, bc), "the solution with the pole is not failed")
+		return bc.found
+	}
+
+	for _, tt := range []struct {
+		at, h float64
+	}{{1.23, 0.1}, {1.25, 0.1}, {2.99, 0.1}, {0.01, 0.1}, {1.23, 0.003}, {1.5, 0.5}} {
+		found := discontinuities(&Exact{F: family, C: pole(tt.at)}, tt.h, 0, 3)
+		require.Len(t, found, 1, "pole at %v with h=%v", tt.at, tt.h)
+		assert.LessOrEqual(t, found[0].Left, tt.at, "pole at %v with h=%v", tt.at, tt.h)
+		assert.GreaterOrEqual(t, found[0].Right, tt.at, "pole at %v with h=%v", tt.at, tt.h)
+		assert.InDelta(t, tt.h, found[0].Right-found[0].Left, 1e-9, "the bracket is the single step")
+	}
+
+	// the smooth solution reports none
+	e := &Exact{F: family, C: func(x0, y0 float64) (float64, error) { return (math.Exp(-x0) - y0) / (y0 * math.Exp(x0)), nil }}
+	assert.Empty(t, discontinuities(e, 0.1, -4, 4))
+
+	// the smooth change of the sign is not the jump
+	e = &Exact{F: func(x, c float64) (float64, error) { return math.Sin(x * c), nil },
+		C: func(float64, float64) (float64, error) { return 3, nil }}
+	assert.Empty(t, discontinuities(e, 0.05, 0, 10))
+
+	// the solution, that is not finite at the node, is discontinuous there
+	e = &Exact{F: func(x, c float64) (float64, error) { return 1 / (x - c), nil },
+		C: func(float64, float64) (float64, error) { return 0.5, nil }}
+	assert.Equal(t, []Bracket{{Left: 0.25, Right: 0.5}}, discontinuities(e, 0.25, 0, 1))
+
+	// the ratio is configurable
+	e = &Exact{F: family, C: pole(1.25), JumpRatio: 10}
+	assert.Empty(t, discontinuities(e, 0.1, 0, 3), "the solution grows three times towards the pole in the middle of the step")
+
+	// discontinuities are found through wrapped drawers
+	bc := &bracketCollector{}
+	_, d := WithStats(WithSkip(bc, 5))
+	require.NoError(t, (&Exact{F: family, C: pole(1.23)}).Solve(0.1, 0, 1, 3, d))
+	assert.Len(t, bc.Points, 26, "first points are skipped")
+	assert.Len(t, bc.found, 1)
+}
+
+func TestExact_DiscontinuitiesConcurrent(t *testing.T) {
+	// the constant places the pole at x0 + 1.23, so concurrent solutions from different x0 have different poles
+	e := &Exact{F: func(x, c float64) (float64, error) { return 1 / (c - x), nil },
+		C: func(x0, y0 float64) (float64, error) { return x0 + 1/y0, nil }}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(x0 float64) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				bc := &bracketCollector{}
+				assert.NoError(t, e.Solve(0.25, x0, 1/1.23, x0+3, bc))
+				if assert.Len(t, bc.found, 1) {
+					assert.True(t, bc.found[0].Left <= x0+1.23 && x0+1.23 <= bc.found[0].Right,
+						"the pole at %v is in %+v", x0+1.23, bc.found[0])
+				}
+			}
+		}(float64(i))
+	}
+	wg.Wait()
+}
+
+func TestExact_Memo(t *testing.T) {
+	evals := 0
+	e := &Exact{F: func(x, c float64) (float64, error) {
+		evals++
+		if x > 10 {
+			return 0, errors.New("out of range")
+		}
+		return math.Exp(-x) / (c*math.Exp(x) + 1), nil
+	}}
+	const c = 2926.3598370085842
+	m := e.Memo(c, 3)
+
+	for _, x := range []float64{0.1, 0.2, 0.1, 1.0 / 3, 0.1} {
+		y, err := m.At(x)
+		require.NoError(t, err)
+		expected, _ := e.F(x, c)
+		assert.Equal(t, math.Float64bits(expected), math.Float64bits(y), "x=%v", x)
+	}
+	assert.Equal(t, MemoStats{Hits: 2, Misses: 3}, m.Stats())
+	assert.Equal(t, 8, evals, "three values by the memo and five expected values")
+
+	// the 0.1 is evicted as the oldest value
+	_, err := m.At(0.4)
+	require.NoError(t, err)
+	_, err = m.At(0.1)
+	require.NoError(t, err)
+	assert.Equal(t, MemoStats{Hits: 2, Misses: 5}, m.Stats())
+
+	// failures are not cached
+	for i := 0; i < 2; i++ {
+		_, err = m.At(11)
+		assert.EqualError(t, err, "out of range")
+	}
+	assert.Equal(t, MemoStats{Hits: 2, Misses: 7}, m.Stats())
+
+	// -0 and +0 are different keys, as the solution might differ at them
+	sign := (&Exact{F: func(x, _ float64) (float64, error) { return 1 / x, nil }}).Memo(0, 10)
+	pos, err := sign.At(0)
+	require.NoError(t, err)
+	neg, err := sign.At(math.Copysign(0, -1))
+	require.NoError(t, err)
+	assert.True(t, math.IsInf(pos, 1))
+	assert.True(t, math.IsInf(neg, -1))
+}
+
+func TestExact_MemoConcurrent(t *testing.T) {
+	e := &Exact{F: func(x, c float64) (float64, error) { return x * c, nil }}
+	m := e.Memo(2, 50)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				x := float64(i % 40)
+				y, err := m.At(x)
+				assert.NoError(t, err)
+				assert.Equal(t, 2*x, y)
+			}
+		}()
+	}
+	wg.Wait()
+	st := m.Stats()
+	assert.Equal(t, 8000, st.Hits+st.Misses)
+	assert.Greater(t, st.Hits, 0)
+}
+
 func TestPoint_String(t *testing.T) {
-	assert.Equal(t, "(0.0003, 0.1235)", num.Point{0.0003, 0.123456789}.String())
+	assert.Equal(t, "(0.0003, 0.1235)", num.Point{X: 0.0003, Y: 0.123456789}.String())
 }
 
 func TestCalculateStepSize(t *testing.T) {
-	assert.InDelta(t, 0.26667, num.CalculateStepSize(30, -4.0, 4.0), 0.00001)
+	h, err := num.CalculateStepSize(30, -4.0, 4.0)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.26667, h, 0.00001)
+
+	_, err = num.CalculateStepSize(10, 1, 1)
+	assert.Equal(t, num.ErrEmptyInterval, err)
+	h, err = num.CalculateStepSize(10, 1, 0)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.1, h, 1e-15, "the size of the step backward is positive")
+	_, err = num.CalculateStepSize(0, 0, 1)
+	assert.EqualError(t, err, "number of steps must be positive, got 0")
+	_, err = num.CalculateStepSize(-5, 0, 1)
+	assert.EqualError(t, err, "number of steps must be positive, got -5")
+	_, err = num.CalculateStepSize(10, math.NaN(), 1)
+	assert.EqualError(t, err, "bounds of the interval must be finite, got x0=NaN, x_end=1")
+	_, err = num.CalculateStepSize(10, 0, math.Inf(1))
+	assert.EqualError(t, err, "bounds of the interval must be finite, got x0=0, x_end=+Inf")
+	// both bounds are finite, but the interval is wider than the largest float
+	_, err = num.CalculateStepSize(1, -math.MaxFloat64, math.MaxFloat64)
+	assert.True(t, errors.Is(err, num.ErrBadStep))
+	assert.Contains(t, err.Error(), "is too wide: got +Inf: step must be positive and finite")
+
+	assert.NoError(t, num.CheckStep(0.1))
+	for _, h := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		assert.True(t, errors.Is(num.CheckStep(h), num.ErrBadStep), "h=%v", h)
+	}
+}
+
+// stepSize returns the size of the step of the interval, split into n steps
+func stepSize(n int, x0, x float64) float64 {
+	h, err := num.CalculateStepSize(n, x0, x)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+func TestSolvers_EmptyInterval(t *testing.T) {
+	for _, s := range batchSolvers(10) {
+		for _, h := range []float64{0.1, 5} {
+			// by batches and one by one
+			batched, perPoint := &Collector{}, &Collector{}
+			require.NoError(t, s.Solve(h, 2, 3, 2, batched), "%s with h=%v", s.Name(), h)
+			require.NoError(t, s.Solve(h, 2, 3, 2, DrawerFunc(perPoint.Draw)), "%s with h=%v", s.Name(), h)
+			assert.Equal(t, []num.Point{{X: 2, Y: 3}}, batched.Points, "%s with h=%v", s.Name(), h)
+			assert.Equal(t, []num.Point{{X: 2, Y: 3}}, perPoint.Points, "%s with h=%v", s.Name(), h)
+		}
+	}
+}
+
+func TestSolvers_Backward(t *testing.T) {
+	// y' = y from y(1) = e back to x = 0 recovers y(0) = 1
+	exp := func(_, y float64) (float64, error) { return y, nil }
+	for _, tt := range []struct {
+		s   Interface
+		tol float64
+	}{
+		{&Euler{F: exp}, 0.06},
+		{&ImprovedEuler{F: exp}, 2e-3},
+		{&RungeKutta{F: exp}, 1e-6},
+		{&BackwardEuler{F: exp}, 0.06},
+		{&Trapezoidal{F: exp}, 2e-3},
+		{&AdamsBashforth{F: exp, Order: 3}, 1e-4},
+		{&AdamsBashforthMoulton{F: exp}, 1e-6},
+		{&Taylor{Series: Partials{F: exp, Fx: func(_, _ float64) (float64, error) { return 0, nil },
+			Fy: func(_, _ float64) (float64, error) { return 1, nil }}.Series}, 2e-3},
+		{&Exact{F: func(x, c float64) (float64, error) { return c * math.Exp(x), nil },
+			C: func(x0, y0 float64) (float64, error) { return y0 / math.Exp(x0), nil }}, 1e-12},
+	} {
+		d := &stepCollector{}
+		require.NoError(t, tt.s.Solve(0.05, 1, math.E, 0, d), tt.s.Name())
+		require.Len(t, d.Points, 21, tt.s.Name())
+		assert.Equal(t, num.Point{X: 1, Y: math.E}, d.Points[0], tt.s.Name())
+		for i := 1; i < len(d.Points); i++ {
+			assert.Less(t, d.Points[i].X, d.Points[i-1].X, "%s goes backward", tt.s.Name())
+			assert.InDelta(t, -0.05, d.hs[i], 1e-12, "%s has the negative step", tt.s.Name())
+		}
+		assert.Equal(t, 0.0, d.Points[20].X, "%s ends exactly at x_end", tt.s.Name())
+		assert.InDelta(t, 1, d.Points[20].Y, tt.tol, tt.s.Name())
+	}
+
+	// the backward solution from the end of the forward one returns to y0 up to the error of the method
+	fwd, err := Collect(&RungeKutta{F: benchF}, 0.3, 0, 1, 1)
+	require.NoError(t, err)
+	end := fwd.Points[len(fwd.Points)-1]
+	back, err := Collect(&RungeKutta{F: benchF}, 0.3, end.X, end.Y, 0)
+	require.NoError(t, err)
+	require.Len(t, back.Points, 5, "the last step back is shortened as well")
+	for i, x := range []float64{1, 0.7, 0.4, 0.1, 0} {
+		assert.InDelta(t, x, back.Points[i].X, 1e-12)
+	}
+	assert.InDelta(t, 1, back.Points[4].Y, 5e-3)
+}
+
+func TestSolvers_AdaptiveBackward(t *testing.T) {
+	// y' = y from y(1) = e back to x = 0, the adaptive step is controlled by its size and goes with decreasing x
+	exp := func(_, y float64) (float64, error) { return y, nil }
+	for _, s := range []Interface{&RKF45{F: exp}, &DormandPrince{F: exp}, &BDF{F: exp}} {
+		d := &metaCollector{}
+		require.NoError(t, s.Solve(0.1, 1, math.E, 0, d), s.Name())
+		require.Greater(t, len(d.Points), 2, s.Name())
+		assert.Equal(t, num.Point{X: 1, Y: math.E}, d.Points[0], s.Name())
+		metas := d.accepted()
+		for i := 1; i < len(d.Points); i++ {
+			assert.Less(t, d.Points[i].X, d.Points[i-1].X, "%s goes backward", s.Name())
+			assert.Less(t, metas[i].H, 0.0, "%s has the negative step", s.Name())
+		}
+		last := d.Points[len(d.Points)-1]
+		assert.Equal(t, 0.0, last.X, "%s ends exactly at x_end", s.Name())
+		assert.InDelta(t, 1, last.Y, 1e-5, s.Name())
+	}
+}
+
+func TestSolvers_BadStep(t *testing.T) {
+	for _, s := range batchSolvers(10) {
+		for _, h := range []float64{0, -5, math.NaN(), math.Inf(1), math.Inf(-1)} {
+			for _, xEnd := range []float64{1, 0} {
+				d := &Collector{}
+				err := s.Solve(h, 0, 1, xEnd, d)
+				assert.True(t, errors.Is(err, num.ErrBadStep), "%s with h=%v: %v", s.Name(), h, err)
+				assert.Empty(t, d.Points, "%s draws nothing", s.Name())
+			}
+		}
+	}
+}
+
+func TestExact_Constant(t *testing.T) {
+	// the family y = e^{-x}/(c*e^{x} + 1) has no member through y0 = 0
+	family := func(x, c float64) (float64, error) { return math.Exp(-x) / (c*math.Exp(x) + 1), nil }
+	e := &Exact{F: family, C: func(x0, y0 float64) (float64, error) { return (math.Exp(-x0) - y0) / (y0 * math.Exp(x0)), nil }}
+
+	c, err := e.Constant(0, 0.5)
+	require.NoError(t, err)
+	assert.InDelta(t, 1, c, 1e-12)
+
+	d := &Collector{}
+	err = e.Solve(0.1, 0, 0, 1, d)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrConstant), err.Error())
+	assert.Contains(t, err.Error(), "Exact solution failed at step 0, c at x=0.0000 y=0.0000: got +Inf: initial values are incompatible")
+	assert.Empty(t, d.Points, "garbage is not drawn")
+
+	_, err = (&Exact{F: family, C: func(float64, float64) (float64, error) { return math.NaN(), nil }}).Constant(0, 1)
+	assert.True(t, errors.Is(err, ErrConstant))
+
+	errC := errors.New("c failed")
+	e.C = func(float64, float64) (float64, error) { return 0, errC }
+	err = e.Solve(0.1, 0, 1, 1, d)
+	assert.True(t, errors.Is(err, errC))
+	assert.False(t, errors.Is(err, ErrConstant), "the failure of c is not the incompatible initial value")
+	assert.Empty(t, d.Points)
+}
+
+func TestSolvers_StepTooSmall(t *testing.T) {
+	for _, s := range batchSolvers(10) {
+		for _, tt := range []struct{ h, x0, xEnd float64 }{
+			{1e-300, 0, 1},
+			{5e-324, 0, 1},  // the least denormal
+			{1e-310, 0, 0},  // the denormal step advances x0 = 0, so the empty interval is the single point
+			{1, 1e20, 2e20}, // x0 + h == x0
+			{1, -1e20, 0},
+			{1e-3, 0, 1e17}, // lost at the end of the interval
+		} {
+			d := &Collector{}
+			err := s.Solve(tt.h, tt.x0, 1, tt.xEnd, d)
+			if tt.x0 == tt.xEnd {
+				require.NoError(t, err, "%s with h=%v", s.Name(), tt.h)
+				assert.Len(t, d.Points, 1)
+				continue
+			}
+			assert.True(t, errors.Is(err, num.ErrStepTooSmall), "%s with h=%v on [%v, %v]: %v",
+				s.Name(), tt.h, tt.x0, tt.xEnd, err)
+			assert.Empty(t, d.Points, "%s draws nothing", s.Name())
+		}
+
+		// the legitimate small step still works
+		line, err := Collect(s, 1e-6, 0, 1, 0.1)
+		require.NoError(t, err, s.Name())
+		assert.Len(t, line.Points, 100001, s.Name())
+		assert.Equal(t, 0.1, line.Points[len(line.Points)-1].X)
+	}
+
+	// the sink is the backstop, if x is not advanced
+	c := &Collector{}
+	out := sinkOf[float64]("Euler's method", c)
+	require.NoError(t, out.put(0, 0, num.Point{X: 1, Y: 1}))
+	require.NoError(t, out.put(1, 1e-17, num.Point{X: 1.0000000000000002, Y: 1}))
+	err := out.put(2, 1e-17, num.Point{X: 1.0000000000000002, Y: 1})
+	assert.True(t, errors.Is(err, num.ErrStepTooSmall))
+	assert.Contains(t, err.Error(), "Euler's method failed at step 2, advance at x=1.0000")
+	assert.Len(t, c.Points, 2, "points before the stall are drawn")
+}
+
+func TestSolvers_StepError(t *testing.T) {
+	errF := errors.New("log of negative")
+	f := func(x, y float64) (float64, error) {
+		if x > 0.5 {
+			return 0, errF
+		}
+		return benchF(x, y)
+	}
+	exact := &Exact{
+		F: func(x, c float64) (float64, error) { return f(x, c) },
+		C: func(float64, float64) (float64, error) { return 1, nil },
+	}
+	for _, tt := range []struct {
+		s     Interface
+		step  int
+		stage string
+		x     float64
+	}{
+		{&Euler{F: f}, 6, "f", 0.6},
+		{&ImprovedEuler{F: f}, 5, "k2", 0.55}, // the midpoint of the step from x=0.5 fails first
+		{&RungeKutta{F: f}, 5, "k2", 0.55},
+		{exact, 5, "y", 0.6},
+	} {
+		d := &Collector{}
+		err := tt.s.Solve(0.1, 0, 1, 1, d)
+		require.Error(t, err, tt.s.Name())
+		assert.True(t, errors.Is(err, errF), "%s unwraps to the error of f", tt.s.Name())
+		var se *StepError
+		require.True(t, errors.As(err, &se), tt.s.Name())
+		assert.Equal(t, tt.s.Name(), se.Method)
+		assert.Equal(t, tt.step, se.Step, tt.s.Name())
+		assert.Equal(t, tt.stage, se.Stage, tt.s.Name())
+		assert.InDelta(t, tt.x, se.X, 1e-12, tt.s.Name())
+		assert.Len(t, d.Points, tt.step+1, "points before the failure are drawn by %s", tt.s.Name())
+		assert.Contains(t, err.Error(), fmt.Sprintf("%s failed at step %d, %s at x=%.4f", tt.s.Name(), tt.step, tt.stage, tt.x))
+		assert.True(t, strings.HasSuffix(err.Error(), ": log of negative"), err.Error())
+	}
+
+	// each stage of the Runge-Kutta's method is located
+	for stage := 1; stage <= 4; stage++ {
+		calls := 0
+		rk := &RungeKutta{F: func(x, y float64) (float64, error) {
+			if calls++; calls == stage {
+				return 0, errF
+			}
+			return benchF(x, y)
+		}}
+		err := rk.Solve(0.1, 0, 1, 1, &Collector{})
+		var se *StepError
+		require.True(t, errors.As(err, &se), "stage %d", stage)
+		assert.Equal(t, fmt.Sprintf("k%d", stage), se.Stage)
+		assert.Equal(t, 0, se.Step)
+	}
+
+	t.Run("drawer", func(t *testing.T) {
+		errDraw := errors.New("closed")
+		err := (&Euler{F: benchF}).Solve(0.1, 0, 1, 1, DrawerFunc(func(p num.Point) error {
+			if p.X > 0.25 {
+				return errDraw
+			}
+			return nil
+		}))
+		assert.True(t, errors.Is(err, errDraw))
+		assert.EqualError(t, err, "Euler's method failed at step 3, draw at x=0.3000 y=0.5168: closed")
+	})
+
+	t.Run("constant", func(t *testing.T) {
+		e := &Exact{F: exact.F, C: func(float64, float64) (float64, error) { return 0, errF }}
+		err := e.Solve(0.1, 2, 3, 4, &Collector{})
+		assert.True(t, errors.Is(err, errF))
+		assert.EqualError(t, err, "Exact solution failed at step 0, c at x=2.0000 y=3.0000: log of negative")
+	})
+}
+
+// benchSteps is the number of steps of solutions in benchmarks
+const benchSteps = 1000000
+
+var benchF = func(x, y float64) (float64, error) { return x*x - 2.0*y, nil }
+
+// nopDrawer discards points, so benchmarks measure solvers only
+var nopDrawer = DrawerFunc(func(num.Point) error { return nil })
+
+func BenchmarkEuler(b *testing.B)         { benchSolver(b, &Euler{F: benchF}) }
+func BenchmarkImprovedEuler(b *testing.B) { benchSolver(b, &ImprovedEuler{F: benchF}) }
+func BenchmarkRungeKutta(b *testing.B)    { benchSolver(b, &RungeKutta{F: benchF}) }
+
+func BenchmarkExact(b *testing.B) {
+	benchSolver(b, &Exact{
+		F: func(x, c float64) (float64, error) { return x*x/2 - x/2 + 0.25 + c*math.Exp(-2*x), nil },
+		C: func(x0, y0 float64) (float64, error) { return (y0 - x0*x0/2 + x0/2 - 0.25) * math.Exp(2*x0), nil },
+	})
+}
+
+// BenchmarkRungeKutta_Formula measures the solution of f, parsed from the formula, as the server does,
+// against f, compiled by Go
+func BenchmarkRungeKutta_Formula(b *testing.B) {
+	f, err := expr.Parse2("x^2 - 2*y", nil, "x", "y")
+	require.NoError(b, err)
+	b.Run("go", func(b *testing.B) { benchSolver(b, &RungeKutta{F: benchF}) })
+	b.Run("formula", func(b *testing.B) { benchSolver(b, &RungeKutta{F: f}) })
+}
+
+// benchSolver measures the solution with benchSteps steps by the solver with the no-op drawer
+// and with the drawer, wrapped as the server does
+func benchSolver(b *testing.B, s Interface) { benchSolverN(b, s, benchSteps) }
+
+// benchSolverN measures the solution with n steps as benchSolver does, e.g. for slow solvers
+func benchSolverN(b *testing.B, s Interface, n int) {
+	for _, bb := range []struct {
+		name string
+		d    Drawer
+	}{
+		{"nop", nopDrawer},
+		{"wrapped", WithLogger(WithContext(context.Background(), nopDrawer), log.Default())},
+	} {
+		b.Run(bb.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := s.Solve(1/float64(n), 0, 1, 1, bb.d); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestSolvers_NoAllocsPerStep(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector allocates on steps")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	wrapped := WithObserver(WithLogger(WithContext(ctx, nopDrawer), log.Default()), func(num.Point, error) {})
+	meta := WithObserver(WithLogger(WithContext(ctx, MetaDrawerFunc(func(num.Point, Meta) error { return nil })),
+		log.Default()), func(num.Point, error) {})
+	for _, s := range []Interface{&Euler{F: benchF}, &ImprovedEuler{F: benchF}, &RungeKutta{F: benchF}} {
+		for _, d := range []Drawer{nopDrawer, wrapped, meta} {
+			allocs := func(n int) float64 {
+				return testing.AllocsPerRun(10, func() { require.NoError(t, s.Solve(1/float64(n), 0, 1, 1, d)) })
+			}
+			assert.Equal(t, allocs(10), allocs(10000), "allocations of %s don't depend on the number of steps", s.Name())
+		}
+	}
+}
+
+func TestSolvers_Evaluations(t *testing.T) {
+	for _, tt := range []struct {
+		solver       func(f Func) Interface
+		evalsPerStep int
+	}{
+		{func(f Func) Interface { return &Euler{F: f} }, 1},
+		{func(f Func) Interface { return &ImprovedEuler{F: f} }, 2},
+		{func(f Func) Interface { return &RungeKutta{F: f} }, 4},
+	} {
+		f := &CountingFunc{F: benchF}
+		s := tt.solver(f.Eval)
+
+		// 0.125 is exact in binary, so the interval is split into exactly 8 steps
+		c := &Collector{}
+		require.NoError(t, s.Solve(0.125, 0, 1, 1, c))
+		require.Len(t, c.Points, 9)
+		assert.Equal(t, 8*tt.evalsPerStep, f.Calls(), "evaluations of %s", s.Name())
+
+		// the step, calculated from the number of steps, is not exact in binary, but gives the same steps
+		f.Reset()
+		c = &Collector{}
+		require.NoError(t, s.Solve(stepSize(30, -4, 4), -4, 1, 4, c))
+		require.Len(t, c.Points, 31)
+		assert.Equal(t, 30*tt.evalsPerStep, f.Calls(), "evaluations of %s", s.Name())
+	}
 }
@@ -0,0 +1,115 @@
+package solver
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/pkg/errors"
+)
+
+// the bound of the stability interval is scanned along the negative real axis and refined by the bisection
+const (
+	stabilityScan     = 0.01  // step of the scan
+	maxStabilityScan  = 100   // the longest stability interval, that the scan looks for
+	stabilityBisectTo = 1e-12 // width of the bracket of the bound, where the bisection stops
+)
+
+// StabilityFunc returns the stability function R(z) of the method, the step h of the method for the test
+// equation y' = λy multiplies y by R(hλ), so the method is stable for hλ, where |R(hλ)| <= 1, methods are
+// the ones of Complex
+func StabilityFunc(method string) (func(z complex128) complex128, error) {
+	m, ok := complexMethods[method]
+	if !ok {
+		return nil, errors.Errorf("unknown method %q, must be euler, ieuler or rk4", method)
+	}
+	return func(z complex128) complex128 {
+		// the step of the test equation with h = 1 from y = 1, f doesn't fail
+		y, _ := m.step(func(_ float64, y complex128) (complex128, error) { return z * y, nil }, 0, 1, 1)
+		return y
+	}, nil
+}
+
+// Stable tells, whether the step of the method is stable for hλ, |R(hλ)| is returned as well
+func Stable(method string, z complex128) (bool, float64, error) {
+	r, err := StabilityFunc(method)
+	if err != nil {
+		return false, 0, err
+	}
+	abs := cmplx.Abs(r(z))
+	return abs <= 1, abs, nil
+}
+
+// StabilityInterval returns the length of the stability interval of the method on the negative real axis,
+// the method is stable for real hλ within [-length, 0], the interval is looked for up to 100
+func StabilityInterval(method string) (float64, error) {
+	r, err := StabilityFunc(method)
+	if err != nil {
+		return 0, err
+	}
+	stable := func(x float64) bool { return cmplx.Abs(r(complex(-x, 0))) <= 1 }
+
+	lo := 0.0
+	for lo < maxStabilityScan && stable(lo+stabilityScan) {
+		lo += stabilityScan
+	}
+	if lo >= maxStabilityScan {
+		return math.Inf(1), nil
+	}
+	// the bound is bracketed by the stable lo and the unstable hi
+	hi := lo + stabilityScan
+	for hi-lo > stabilityBisectTo {
+		if mid := (lo + hi) / 2; stable(mid) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo, nil
+}
+
+// Region is |R(z)| of the method over the rectangular grid of the complex plane, the method is stable,
+// where it doesn't exceed one
+type Region struct {
+	Method string
+	Re, Im []float64   // nodes of the grid along the real and the imaginary axes
+	Abs    [][]float64 // |R(z)| at nodes, by rows of Im and columns of Re
+}
+
+// Stable tells, whether the method is stable at the node in the i-th row and the j-th column
+func (r Region) Stable(i, j int) bool { return r.Abs[i][j] <= 1 }
+
+// StabilityRegion computes |R(z)| of the method at n×n nodes of the rectangle [re0, re1]×[im0, im1]
+// of the complex plane of hλ, the values, that overflow, are infinite
+func StabilityRegion(method string, re0, re1, im0, im1 float64, n int) (Region, error) {
+	switch {
+	case n < 2:
+		return Region{}, errors.Errorf("the grid must have at least 2 nodes by each axis, got %d", n)
+	case !isFinite(re0) || !isFinite(re1) || !(re1 > re0):
+		return Region{}, errors.Errorf("re1=%v must be greater than re0=%v, both finite", re1, re0)
+	case !isFinite(im0) || !isFinite(im1) || !(im1 > im0):
+		return Region{}, errors.Errorf("im1=%v must be greater than im0=%v, both finite", im1, im0)
+	}
+	r, err := StabilityFunc(method)
+	if err != nil {
+		return Region{}, err
+	}
+
+	res := Region{Method: method, Re: evenNodes(re0, re1, n), Im: evenNodes(im0, im1, n), Abs: make([][]float64, n)}
+	for i, im := range res.Im {
+		res.Abs[i] = make([]float64, n)
+		for j, re := range res.Re {
+			res.Abs[i][j] = cmplx.Abs(r(complex(re, im)))
+		}
+	}
+	return res, nil
+}
+
+// evenNodes returns n evenly spaced nodes from a to b, both ends included
+func evenNodes(a, b float64, n int) []float64 {
+	res := make([]float64, n)
+	for i := range res {
+		res[i] = a + (b-a)*float64(i)/float64(n-1)
+	}
+	res[n-1] = b
+	return res
+}
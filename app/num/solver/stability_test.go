@@ -0,0 +1,83 @@
+package solver
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStabilityFunc(t *testing.T) {
+	z := complex(-0.3, 0.7)
+	for method, want := range map[string]complex128{
+		"euler":  1 + z,
+		"ieuler": 1 + z + z*z/2,
+		"rk4":    1 + z + z*z/2 + z*z*z/6 + z*z*z*z/24,
+	} {
+		r, err := StabilityFunc(method)
+		require.NoError(t, err)
+		assert.InDelta(t, 0, cmplx.Abs(r(z)-want), 1e-15, method)
+	}
+
+	_, err := StabilityFunc("unknown")
+	assert.EqualError(t, err, `unknown method "unknown", must be euler, ieuler or rk4`)
+}
+
+func TestStable(t *testing.T) {
+	ok, abs, err := Stable("euler", -1.5)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.InDelta(t, 0.5, abs, 1e-15)
+
+	ok, abs, err = Stable("euler", complex(0, 1))
+	require.NoError(t, err)
+	assert.False(t, ok, "euler is unstable on the imaginary axis")
+	assert.InDelta(t, math.Sqrt2, abs, 1e-15)
+
+	ok, _, err = Stable("rk4", complex(0, 2.5))
+	require.NoError(t, err)
+	assert.True(t, ok, "rk4 is stable on the imaginary axis up to 2.83")
+}
+
+func TestStabilityInterval(t *testing.T) {
+	for method, want := range map[string]float64{"euler": 2, "ieuler": 2, "rk4": 2.785293563405} {
+		l, err := StabilityInterval(method)
+		require.NoError(t, err)
+		assert.InDelta(t, want, l, 1e-9, method)
+	}
+	_, err := StabilityInterval("unknown")
+	assert.Error(t, err)
+}
+
+func TestStabilityRegion(t *testing.T) {
+	reg, err := StabilityRegion("euler", -3, 1, -2, 2, 5)
+	require.NoError(t, err)
+	assert.Equal(t, "euler", reg.Method)
+	assert.Equal(t, []float64{-3, -2, -1, 0, 1}, reg.Re)
+	assert.Equal(t, []float64{-2, -1, 0, 1, 2}, reg.Im)
+	require.Len(t, reg.Abs, 5)
+
+	// the region of euler is the disk of the radius 1 around -1
+	for i, im := range reg.Im {
+		require.Len(t, reg.Abs[i], 5)
+		for j, re := range reg.Re {
+			assert.InDelta(t, math.Hypot(1+re, im), reg.Abs[i][j], 1e-15)
+			assert.Equal(t, math.Hypot(1+re, im) <= 1, reg.Stable(i, j), "z=%v%+vi", re, im)
+		}
+	}
+
+	for _, tt := range []struct {
+		re0, re1, im0, im1 float64
+		n                  int
+		err                string
+	}{
+		{-3, 1, -2, 2, 1, "the grid must have at least 2 nodes by each axis, got 1"},
+		{1, 1, -2, 2, 5, "re1=1 must be greater than re0=1, both finite"},
+		{-3, 1, 0, math.Inf(1), 5, "im1=+Inf must be greater than im0=0, both finite"},
+	} {
+		_, err = StabilityRegion("euler", tt.re0, tt.re1, tt.im0, tt.im1, tt.n)
+		assert.EqualError(t, err, tt.err)
+	}
+}
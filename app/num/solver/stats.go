@@ -0,0 +1,53 @@
+package solver
+
+import (
+	"math"
+
+	"github.com/Semior001/decompract/app/num"
+)
+
+// Stats summarizes the drawn points of the solution, extrema are taken over finite values of y only,
+// the first point wins the tie, Min, Max and Final are zero, if nothing is drawn, extrema are zero,
+// if no value is finite, so both must be checked by Points and NonFinite
+type Stats struct {
+	Points    int       // number of drawn points
+	NonFinite int       // number of points with NaN or infinite y, skipped by extrema
+	Min, Max  num.Point // points of the least and the largest finite y
+	Final     num.Point // the last drawn point, its y might be not finite
+}
+
+// HasExtrema checks whether any finite value is drawn, so Min and Max are set
+func (s *Stats) HasExtrema() bool { return s.Points > s.NonFinite }
+
+// add accounts the point in the summary
+func (s *Stats) add(p num.Point) {
+	s.Final = p
+	s.Points++
+	if math.IsNaN(p.Y) || math.IsInf(p.Y, 0) {
+		s.NonFinite++
+		return
+	}
+	if s.Points-s.NonFinite == 1 {
+		s.Min, s.Max = p, p
+		return
+	}
+	if p.Y < s.Min.Y {
+		s.Min = p
+	}
+	if p.Y > s.Max.Y {
+		s.Max = p
+	}
+}
+
+// WithStats wraps the drawer to summarize points, that are drawn by it successfully, in a single pass,
+// the returned Stats is updated on each call
+func WithStats(d Drawer) (*Stats, Drawer) {
+	s := &Stats{}
+	return s, wrap(d, func(c call) error {
+		if err := c.do(); err != nil {
+			return err
+		}
+		s.add(c.p)
+		return nil
+	})
+}
@@ -0,0 +1,68 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithStats(t *testing.T) {
+	tbl := []struct {
+		method     Builder
+		evals      int
+		min, final num.Point
+	}{
+		{method: func(f Func) Interface { return &Euler{F: f} }, evals: 10,
+			min: num.Point{X: 0.8, Y: 0.27502}, final: num.Point{X: 1, Y: 0.30821}},
+		{method: func(f Func) Interface { return &ImprovedEuler{F: f} }, evals: 20,
+			min: num.Point{X: 0.8, Y: 0.324416}, final: num.Point{X: 1, Y: 0.354284}},
+		{method: rk4Builder, evals: 40,
+			min: num.Point{X: 0.8, Y: 0.321430}, final: num.Point{X: 1, Y: 0.351509}},
+	}
+	for _, tt := range tbl {
+		f := &CountingFunc{F: func(x, y float64) (float64, error) { return x*x - 2*y, nil }}
+		slvr := tt.method(f.Eval)
+		c := &Collector{}
+		st, d := WithStats(c)
+		require.NoError(t, slvr.Solve(0.1, 0, 1, 1, d), slvr.Name())
+
+		assert.Equal(t, 11, st.Points, slvr.Name())
+		assert.Equal(t, 0, st.NonFinite, slvr.Name())
+		assert.True(t, st.HasExtrema(), slvr.Name())
+		assert.Equal(t, num.Point{X: 0, Y: 1}, st.Max, slvr.Name())
+		assert.InDelta(t, tt.min.X, st.Min.X, 1e-9, slvr.Name())
+		assert.InDelta(t, tt.min.Y, st.Min.Y, 1e-5, slvr.Name())
+		assert.InDelta(t, tt.final.X, st.Final.X, 1e-9, slvr.Name())
+		assert.InDelta(t, tt.final.Y, st.Final.Y, 1e-5, slvr.Name())
+		assert.Equal(t, c.Points[len(c.Points)-1], st.Final, slvr.Name())
+		assert.Equal(t, tt.evals, f.Calls(), slvr.Name())
+	}
+}
+
+func TestWithStats_NonFinite(t *testing.T) {
+	st, d := WithStats(&Collector{})
+	assert.False(t, st.HasExtrema(), "nothing is drawn")
+	assert.Equal(t, Stats{}, *st)
+
+	for _, p := range []num.Point{{X: 0, Y: math.NaN()}, {X: 1, Y: 2}, {X: 2, Y: math.Inf(1)}, {X: 3, Y: 2}, {X: 4, Y: -1}} {
+		require.NoError(t, d.Draw(p))
+	}
+	assert.Equal(t, 5, st.Points)
+	assert.Equal(t, 2, st.NonFinite)
+	assert.Equal(t, num.Point{X: 1, Y: 2}, st.Max, "the first point wins the tie")
+	assert.Equal(t, num.Point{X: 4, Y: -1}, st.Min)
+	assert.Equal(t, num.Point{X: 4, Y: -1}, st.Final)
+
+	st, d = WithStats(DrawerFunc(func(p num.Point) error { return errors.New("failed") }))
+	assert.Error(t, d.Draw(num.Point{X: 0, Y: math.NaN()}))
+	assert.Equal(t, 0, st.Points, "failed points are not accounted")
+
+	st, d = WithStats(&Collector{})
+	require.NoError(t, d.Draw(num.Point{X: 0, Y: math.NaN()}))
+	assert.False(t, st.HasExtrema(), "no finite values")
+	assert.True(t, math.IsNaN(st.Final.Y))
+}
@@ -0,0 +1,134 @@
+package solver
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// defaultStiffLimit is h*|df/dy| of the decaying solution, beyond which Switching takes the implicit method,
+// it is within the stability interval of Runge-Kutta's method, 2.785
+const defaultStiffLimit = 2.5
+
+// Switch is the change of the method of Switching at the node of the grid
+type Switch struct {
+	X     float64 `json:"x"`
+	Node  int     `json:"node"`  // index of the node, from which the method is taken
+	Stiff bool    `json:"stiff"` // the implicit method is taken, otherwise the explicit one
+	Ratio float64 `json:"ratio"` // -h*df/dy at the node, that the stiffness is detected by
+}
+
+// String describes the switch
+func (s Switch) String() string {
+	if s.Stiff {
+		return fmt.Sprintf("stiff at x=%.4g, h*|df/dy| = %.3g, switched to backward Euler's method", s.X, s.Ratio)
+	}
+	return fmt.Sprintf("not stiff at x=%.4g, h*|df/dy| = %.3g, switched to Runge-Kutta's method", s.X, s.Ratio)
+}
+
+// Switching is the meta-solver, that solves with Runge-Kutta's method, while the problem isn't stiff,
+// and switches to the implicit backward Euler's method, once it is: before each step the stiffness is estimated
+// by -h*df/dy, the local Lipschitz constant of the decaying solution by the step, the step beyond Limit is stiff,
+// and the solution returns to the explicit method below the half of Limit, so it doesn't switch back and forth
+// at the limit. Switches are logged, passed to the Annotator of the drawer and reported by Switches,
+// the meta drawer receives the order of the method of each step, 4 or 1
+type Switching struct {
+	F       Func    // calculator for f(x,y) = y'
+	DFDY    Func    // calculator for df/dy, estimated by the central difference of f if not set
+	Limit   float64 // -h*df/dy, beyond which the step is stiff, 2.5 if zero
+	Tol     float64 // max change of y in the last iteration of Newton's method, 1e-10 if zero
+	MaxIter int     // max iterations of Newton's method, 50 if zero
+
+	mu       sync.Mutex
+	switches []Switch // switches of the last solution
+}
+
+// Name returns the name of the method
+func (s *Switching) Name() string { return "Runge-Kutta's method with switching to backward Euler's" }
+
+// Solve the initial value problem, switching methods by the stiffness
+func (s *Switching) Solve(stepSize, x0, y0, xEnd float64, d Drawer) error {
+	if err := checkArgs(stepSize, x0, xEnd); err != nil {
+		return err
+	}
+	limit := s.Limit
+	if limit == 0 {
+		limit = defaultStiffLimit
+	}
+	if !(limit > 0) || !isFinite(limit) {
+		return errors.Errorf("limit must be positive and finite, got %v", s.Limit)
+	}
+	nt, err := newtonOf(s.F, s.DFDY, s.Tol, s.MaxIter)
+	if err != nil {
+		return err
+	}
+
+	logger(d).Logf("[DEBUG] starting solving the equation with Runge-Kutta's method, switching by the stiffness "+
+		"h*|df/dy| > %v, with stepsz = %.4f, x0 = %.4f, y0 = %.4f, xend = %.4f", limit, stepSize, x0, y0, xEnd)
+
+	var switches []Switch
+	defer func() { s.setSwitches(switches) }()
+
+	g, stiff := NewGrid(x0, xEnd, stepSize), false
+	ann := annotator(d)
+	out := sinkOf(s.Name(), d)
+	y, order := y0, 0 // order of the method of the step to the point
+	for i := 0; i <= g.N; i++ {
+		x := g.X(i)
+		if err = out.putMeta(num.Point{X: x, Y: y}, Meta{Step: i, H: g.Step(i), Order: order}); err != nil {
+			return err
+		}
+		if i == g.N {
+			break // the point is the last one, so the next step is not calculated
+		}
+		h := g.Step(i + 1) // the last step might be shortened to end at xEnd
+
+		df, err := nt.dfdy(x, y)
+		if err != nil {
+			return out.fail(&StepError{Method: s.Name(), Step: i, Stage: "df/dy", X: x, Y: y, Err: err})
+		}
+		ratio := -h * df
+		if now := ratio > limit || stiff && ratio >= limit/2; now != stiff {
+			stiff = now
+			sw := Switch{X: x, Node: i, Stiff: stiff, Ratio: ratio}
+			switches = append(switches, sw)
+			logger(d).Logf("[INFO] %s", sw)
+			if err = out.annotate(ann, Annotation{X: x, Text: sw.String()}); err != nil {
+				return out.fail(&StepError{Method: s.Name(), Step: i, Stage: "annotate", X: x, Y: y, Err: err})
+			}
+		}
+
+		if stiff {
+			order = 1
+			y, err = nt.solve(s.Name(), i, x+h, h, y, y)
+		} else {
+			order = 4
+			var f float64
+			if f, err = s.F(x, y); err != nil {
+				return out.fail(&StepError{Method: s.Name(), Step: i, Stage: "k1", X: x, Y: y, Err: err})
+			}
+			y, err = rungeKuttaStep(s.Name(), s.F, i, x, h, y, f)
+		}
+		if err != nil {
+			return out.fail(err)
+		}
+	}
+
+	return out.flush()
+}
+
+// Switches returns switches of methods of the last solution in the order of x, nil, if the method isn't switched,
+// the solution starts with Runge-Kutta's method
+func (s *Switching) Switches() []Switch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.switches
+}
+
+func (s *Switching) setSwitches(sw []Switch) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.switches = sw
+}
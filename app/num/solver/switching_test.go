@@ -0,0 +1,110 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	log "github.com/go-pkgz/lgr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// annotatingCollector collects points and annotations, along with the number of points drawn before each one
+type annotatingCollector struct {
+	Collector
+	anns  []Annotation
+	drawn []int
+	err   error
+}
+
+func (c *annotatingCollector) Annotate(a Annotation) error {
+	c.anns = append(c.anns, a)
+	c.drawn = append(c.drawn, len(c.Points))
+	return c.err
+}
+
+func TestSwitching(t *testing.T) {
+	// y' = -4000x(1-x)(y - cos(x)) is stiff in the middle of [0, 1], the fine solution is stable
+	f := func(x, y float64) (float64, error) { return -4000 * x * (1 - x) * (y - math.Cos(x)), nil }
+	dfdy := func(x, y float64) (float64, error) { return -4000 * x * (1 - x), nil }
+
+	rk, err := Collect(&RungeKutta{F: f}, 0.01, 0, 1, 1)
+	require.NoError(t, err)
+	fine, err := Collect(&RungeKutta{F: f}, 0.0001, 0, 1, 1)
+	require.NoError(t, err)
+	assert.False(t, math.Abs(rk.Points[50].Y) < 10, "explicit method blows up with the step")
+
+	for _, s := range []*Switching{{F: f, DFDY: dfdy}, {F: f}} {
+		c := &annotatingCollector{}
+		require.NoError(t, s.Solve(0.01, 0, 1, 1, WithLogger(c, log.Default())))
+		require.Len(t, c.Points, 101)
+		for i, p := range c.Points {
+			assert.InDelta(t, fine.Points[100*i].Y, p.Y, 0.01, "at %v", p.X)
+		}
+
+		sw := s.Switches()
+		require.Len(t, sw, 2)
+		assert.True(t, sw[0].Stiff)
+		assert.InDelta(t, 0.07, sw[0].X, 1e-9, "h*|df/dy| = 40x(1-x) exceeds 2.5")
+		assert.Equal(t, 7, sw[0].Node)
+		assert.False(t, sw[1].Stiff)
+		assert.InDelta(t, 0.97, sw[1].X, 1e-9, "it is less than 1.25 near the end")
+		assert.Equal(t, "stiff at x=0.07, h*|df/dy| = 2.6, switched to backward Euler's method", sw[0].String())
+
+		require.Len(t, c.anns, 2)
+		assert.Equal(t, Annotation{X: sw[0].X, Text: sw[0].String()}, c.anns[0])
+		assert.Equal(t, []int{8, 98}, c.drawn, "points up to the switch are drawn before the annotation")
+	}
+
+	// the problem, that isn't stiff, is solved by Runge-Kutta's method
+	s := &Switching{F: benchF}
+	got, err := Collect(s, 0.1, 0, 1, 1)
+	require.NoError(t, err)
+	want, err := Collect(&RungeKutta{F: benchF}, 0.1, 0, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, want.Points, got.Points)
+	assert.Empty(t, s.Switches())
+}
+
+func TestSwitching_Meta(t *testing.T) {
+	f := func(x, y float64) (float64, error) { return -4000 * x * (1 - x) * (y - math.Cos(x)), nil }
+	d := &metaCollector{}
+	s := &Switching{F: f}
+	require.NoError(t, s.Solve(0.01, 0, 1, 1, d))
+	require.Len(t, d.metas, 101)
+	sw := s.Switches()
+	require.Len(t, sw, 2)
+	for i, m := range d.metas {
+		switch {
+		case i == 0:
+			assert.Equal(t, 0, m.Order, "the initial point isn't stepped")
+		case i > sw[0].Node && i <= sw[1].Node:
+			assert.Equal(t, 1, m.Order, "the step to %d is made by the backward Euler's method", i)
+		default:
+			assert.Equal(t, 4, m.Order, "the step to %d is made by Runge-Kutta's method", i)
+		}
+	}
+}
+
+func TestSwitching_Errors(t *testing.T) {
+	f := func(x, y float64) (float64, error) { return -1000 * y, nil }
+	assert.EqualError(t, (&Switching{F: f, Limit: -1}).Solve(0.1, 0, 1, 1, &Collector{}),
+		"limit must be positive and finite, got -1")
+
+	fail := errors.New("fail")
+	err := (&Switching{F: f, DFDY: func(x, y float64) (float64, error) { return 0, fail }}).Solve(0.1, 0, 1, 1, &Collector{})
+	var se *StepError
+	require.True(t, errors.As(err, &se))
+	assert.Equal(t, "df/dy", se.Stage)
+	assert.True(t, errors.Is(err, fail))
+
+	c := &annotatingCollector{err: fail}
+	err = (&Switching{F: f}).Solve(0.1, 0, 1, 1, c)
+	require.True(t, errors.As(err, &se))
+	assert.Equal(t, "annotate", se.Stage)
+	assert.Equal(t, []num.Point{{X: 0, Y: 1}}, c.Points)
+}
+
+func BenchmarkSwitching(b *testing.B) { benchSolver(b, &Switching{F: benchF}) }
@@ -0,0 +1,282 @@
+package solver
+
+import (
+	"strconv"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// SystemFunc calculates y' = f(x,y) of the system of first order equations, the result has a component
+// for each component of y, solvers copy it, so f might reuse it, but f must not retain y
+type SystemFunc func(x float64, y []float64) ([]float64, error)
+
+// VectorDrawer receives points of the solution of the system one by one, as soon as they are calculated,
+// y of the point is not reused by the solver, so the drawer might retain it
+type VectorDrawer interface {
+	DrawVector(p num.VectorPoint) error
+}
+
+// VectorDrawerFunc is an adapter to allow the use of ordinary functions as VectorDrawer
+type VectorDrawerFunc func(p num.VectorPoint) error
+
+// DrawVector calls f(p)
+func (f VectorDrawerFunc) DrawVector(p num.VectorPoint) error { return f(p) }
+
+// VectorCollector stores all drawn points of the system in memory
+type VectorCollector struct {
+	Points []num.VectorPoint
+}
+
+// DrawVector appends the point to the list of collected points
+func (c *VectorCollector) DrawVector(p num.VectorPoint) error {
+	c.Points = append(c.Points, p)
+	return nil
+}
+
+// SystemInterface describes methods that the solver of the initial value problem of the system
+// of first order equations should implement, e.g. predator-prey or the spring-mass, reduced to the first order
+type SystemInterface interface {
+	// Name returns the human-readable name of the method
+	Name() string
+	// Solve calculates the solution from y0 with the given step size
+	// and passes each calculated point to the drawer
+	Solve(stepSize, x0 float64, y0 []float64, xEnd float64, d VectorDrawer) error
+}
+
+// SystemEuler is Euler's method for systems, on the system of the single equation it gives
+// the same points, bit for bit, as Euler
+type SystemEuler struct {
+	F SystemFunc // calculator for f(x,y) = y'
+}
+
+// Name returns the name of the method
+func (e *SystemEuler) Name() string { return "Euler's method" }
+
+// Solve the system with the given initial values
+func (e *SystemEuler) Solve(stepSize, x0 float64, y0 []float64, xEnd float64, d VectorDrawer) error {
+	return solveSystem(e.Name(), e.F, 1, stepSize, x0, y0, xEnd, d, eulerSystemStep)
+}
+
+// SystemImprovedEuler is the Improved Euler's method for systems, on the system of the single equation
+// it gives the same points, bit for bit, as ImprovedEuler
+type SystemImprovedEuler struct {
+	F SystemFunc // calculator for f(x,y) = y'
+}
+
+// Name returns the name of the method
+func (i *SystemImprovedEuler) Name() string { return "Improved Euler's method" }
+
+// Solve the system with the given initial values
+func (i *SystemImprovedEuler) Solve(stepSize, x0 float64, y0 []float64, xEnd float64, d VectorDrawer) error {
+	return solveSystem(i.Name(), i.F, 2, stepSize, x0, y0, xEnd, d, improvedEulerSystemStep)
+}
+
+// SystemRungeKutta is Runge-Kutta's method for systems, on the system of the single equation
+// it gives the same points, bit for bit, as RungeKutta
+type SystemRungeKutta struct {
+	F SystemFunc // calculator for f(x,y) = y'
+}
+
+// Name returns the name of the method
+func (r *SystemRungeKutta) Name() string { return "Runge-Kutta's method" }
+
+// Solve the system with the given initial values
+func (r *SystemRungeKutta) Solve(stepSize, x0 float64, y0 []float64, xEnd float64, d VectorDrawer) error {
+	return solveSystem(r.Name(), r.F, 4, stepSize, x0, y0, xEnd, d, rungeKuttaSystemStep)
+}
+
+// systemStep keeps stages of the step of the method for the system, so steps don't allocate them
+type systemStep struct {
+	method string
+	f      SystemFunc
+	i      int         // index of the node, from which the step is made
+	k      [][]float64 // derivatives at stages of the step
+	tmp    []float64   // y at the stage of the step
+}
+
+// eval evaluates f at the stage of the step into the k-th derivative, the failure of f, or the result
+// of the wrong length, is located at the stage, y of the error is the first component, the whole y
+// is in the message of the cause
+func (st *systemStep) eval(k int, stage string, x float64, y []float64) ([]float64, error) {
+	dy, err := st.f(x, y)
+	if err == nil && len(dy) != len(y) {
+		err = errors.Errorf("f returned %d components, expected %d", len(dy), len(y))
+	}
+	if err != nil {
+		return nil, &StepError{Method: st.method, Step: st.i, Stage: stage, X: x, Y: y[0],
+			Err: errors.Wrapf(err, "y=%v", y)}
+	}
+	copy(st.k[k], dy)
+	return st.k[k], nil
+}
+
+// solveSystem solves the system on the grid of other solvers with the step of the method of the number
+// of stages, points are drawn at each node
+func solveSystem(method string, f SystemFunc, stages int, stepSize, x0 float64, y0 []float64, xEnd float64,
+	d VectorDrawer, step func(st *systemStep, x, h float64, y, next []float64) error) error {
+	if err := checkArgs(stepSize, x0, xEnd); err != nil {
+		return err
+	}
+	if len(y0) == 0 {
+		return errors.New("the system has no equations, y0 is empty")
+	}
+
+	st := &systemStep{method: method, f: f, k: make([][]float64, stages), tmp: make([]float64, len(y0))}
+	for k := range st.k {
+		st.k[k] = make([]float64, len(y0))
+	}
+	y := append([]float64(nil), y0...)
+	g := NewGrid(x0, xEnd, stepSize)
+	for i := 0; i <= g.N; i++ {
+		x := g.X(i)
+		if err := d.DrawVector(num.VectorPoint{X: x, Y: y}); err != nil {
+			return &StepError{Method: method, Step: i, Stage: "draw", X: x, Y: y[0], Err: err}
+		}
+		if i == g.N {
+			break // the point is the last one, so the next step is not calculated
+		}
+		st.i = i
+		next := make([]float64, len(y)) // the drawn y is retained by the drawer
+		if err := step(st, x, g.Step(i+1), y, next); err != nil {
+			return err
+		}
+		y = next
+	}
+	return nil
+}
+
+// CollectSystem solves the system with the given solver and collects all calculated points
+func CollectSystem(s SystemInterface, stepSize, x0 float64, y0 []float64, xEnd float64) ([]num.VectorPoint, error) {
+	c := &VectorCollector{}
+	if err := s.Solve(stepSize, x0, y0, xEnd, c); err != nil {
+		return nil, err
+	}
+	return c.Points, nil
+}
+
+// Components splits points of the solution of the system into lines of its components, e.g. to draw
+// them on the chart, lines are named y1, y2 and so on
+func Components(pts []num.VectorPoint) []num.Line {
+	if len(pts) == 0 {
+		return nil
+	}
+	lines := make([]num.Line, len(pts[0].Y))
+	for j := range lines {
+		lines[j] = num.Line{Name: "y" + strconv.Itoa(j+1), Points: make([]num.Point, len(pts))}
+		for i, p := range pts {
+			lines[j].Points[i] = num.Point{X: p.X, Y: p.Y[j]}
+		}
+	}
+	return lines
+}
+
+// eulerSystemStep makes the step of Euler's method as y_{i+1} = y_i + h * f(x_i, y_i)
+func eulerSystemStep(st *systemStep, x, h float64, y, next []float64) error {
+	k, err := st.eval(0, "f", x, y)
+	if err != nil {
+		return err
+	}
+	for j := range y {
+		next[j] = y[j] + h*k[j]
+	}
+	return nil
+}
+
+// improvedEulerSystemStep makes the step of the Improved Euler's method by the derivative at the midpoint
+func improvedEulerSystemStep(st *systemStep, x, h float64, y, next []float64) error {
+	k1, err := st.eval(0, "k1", x, y)
+	if err != nil {
+		return err
+	}
+	for j := range y {
+		st.tmp[j] = y[j] + (k1[j]/2.0)*h
+	}
+	k2, err := st.eval(1, "k2", x+h/2.0, st.tmp)
+	if err != nil {
+		return err
+	}
+	for j := range y {
+		next[j] = y[j] + h*k2[j]
+	}
+	return nil
+}
+
+// rungeKuttaSystemStep makes the step of Runge-Kutta's method of the 4th order
+func rungeKuttaSystemStep(st *systemStep, x, h float64, y, next []float64) error {
+	k1, err := st.eval(0, "k1", x, y)
+	if err != nil {
+		return err
+	}
+	for j := range y {
+		st.tmp[j] = y[j] + (h/2.0)*k1[j]
+	}
+	k2, err := st.eval(1, "k2", x+h/2.0, st.tmp)
+	if err != nil {
+		return err
+	}
+	for j := range y {
+		st.tmp[j] = y[j] + (h/2.0)*k2[j]
+	}
+	k3, err := st.eval(2, "k3", x+h/2.0, st.tmp)
+	if err != nil {
+		return err
+	}
+	for j := range y {
+		st.tmp[j] = y[j] + h*k3[j]
+	}
+	k4, err := st.eval(3, "k4", x+h, st.tmp)
+	if err != nil {
+		return err
+	}
+	for j := range y {
+		next[j] = y[j] + h/6.0*(k1[j]+2*k2[j]+2*k3[j]+k4[j])
+	}
+	return nil
+}
+
+// Lift makes the system of the single equation y' = f(x,y), e.g. to solve the scalar problem by Scalar
+func Lift(f Func) SystemFunc {
+	dy := make([]float64, 1) // solvers copy the result, so it is reused
+	return func(x float64, y []float64) ([]float64, error) {
+		v, err := f(x, y[0])
+		dy[0] = v
+		return dy, err
+	}
+}
+
+// Scalar is the thin wrapper of the solver of systems, that solves the scalar problem as the system
+// of the single equation, e.g. of Lift, it gives the same points, bit for bit, as the scalar solver
+// of the same method
+type Scalar struct {
+	System SystemInterface
+}
+
+// Name returns the name of the method of the system
+func (s *Scalar) Name() string { return s.System.Name() }
+
+// Solve the differential equation with the given initial values
+func (s *Scalar) Solve(stepSize, x0, y0, xEnd float64, d Drawer) error {
+	return drawFirst(s.Name(), x0, d, func(vd VectorDrawer) error {
+		return s.System.Solve(stepSize, x0, []float64{y0}, xEnd, vd)
+	})
+}
+
+// drawFirst draws the first component of points of the solution of the system by the sink of the method
+func drawFirst(method string, x0 float64, d Drawer, solve func(vd VectorDrawer) error) error {
+	out := sinkOf(method, d)
+	i, prev := 0, x0
+	var drawErr error // the failure of the drawer is already located by the sink
+	err := solve(VectorDrawerFunc(func(p num.VectorPoint) error {
+		drawErr = out.put(i, p.X-prev, num.Point{X: p.X, Y: p.Y[0]})
+		i, prev = i+1, p.X
+		return drawErr
+	}))
+	if drawErr != nil {
+		return drawErr
+	}
+	if err != nil {
+		return out.fail(err)
+	}
+	return out.flush()
+}
@@ -0,0 +1,133 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystem_Solve(t *testing.T) {
+	// the spring-mass y'' = -y as the system of y and y'
+	spring := func(x float64, y []float64) ([]float64, error) { return []float64{y[1], -y[0]}, nil }
+	for _, tt := range []struct {
+		s   SystemInterface
+		tol float64
+	}{
+		{&SystemEuler{F: spring}, 1e-1},
+		{&SystemImprovedEuler{F: spring}, 1e-3},
+		{&SystemRungeKutta{F: spring}, 1e-9},
+	} {
+		pts, err := CollectSystem(tt.s, 0.01, 0, []float64{1, 0}, 2*math.Pi)
+		require.NoError(t, err, tt.s.Name())
+		require.Len(t, pts, 630, tt.s.Name())
+		last := pts[len(pts)-1]
+		assert.Equal(t, 2*math.Pi, last.X)
+		assert.InDelta(t, 1, last.Y[0], tt.tol, "%s: y is cos(x)", tt.s.Name())
+		assert.InDelta(t, 0, last.Y[1], tt.tol, "%s: y' is -sin(x)", tt.s.Name())
+		assert.Equal(t, []float64{1, 0}, pts[0].Y)
+	}
+
+	// predator-prey keeps both populations positive and its first integral
+	lv := func(x float64, y []float64) ([]float64, error) {
+		return []float64{y[0] * (1 - y[1]), y[1] * (y[0] - 1)}, nil
+	}
+	pts, err := CollectSystem(&SystemRungeKutta{F: lv}, 0.01, 0, []float64{2, 1}, 20)
+	require.NoError(t, err)
+	invariant := func(y []float64) float64 { return y[0] - math.Log(y[0]) + y[1] - math.Log(y[1]) }
+	for _, p := range pts {
+		assert.Greater(t, p.Y[0], 0.0)
+		assert.Greater(t, p.Y[1], 0.0)
+		assert.InDelta(t, invariant(pts[0].Y), invariant(p.Y), 1e-6, "the first integral is kept at x=%v", p.X)
+	}
+
+	lines := Components(pts)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "y1", lines[0].Name)
+	assert.Equal(t, "y2", lines[1].Name)
+	assert.Equal(t, num.Point{X: 20, Y: pts[len(pts)-1].Y[1]}, lines[1].Points[len(pts)-1])
+	assert.Nil(t, Components(nil))
+	assert.Equal(t, "(0.0000, [2.0000, 1.0000])", pts[0].String())
+}
+
+func TestSystem_Scalar(t *testing.T) {
+	for _, tt := range []struct {
+		scalar Interface
+		system SystemInterface
+	}{
+		{&Euler{F: canonical}, &SystemEuler{F: Lift(canonical)}},
+		{&ImprovedEuler{F: canonical}, &SystemImprovedEuler{F: Lift(canonical)}},
+		{&RungeKutta{F: canonical}, &SystemRungeKutta{F: Lift(canonical)}},
+	} {
+		want, err := Collect(tt.scalar, 0.03, -4, 1, 4)
+		require.NoError(t, err)
+		got, err := Collect(&Scalar{System: tt.system}, 0.03, -4, 1, 4)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, "%s gives the same points, bit for bit", tt.scalar.Name())
+
+		d := &stepCollector{}
+		require.NoError(t, (&Scalar{System: tt.system}).Solve(0.03, -4, 1, 4, d))
+		assert.Equal(t, want.Points, d.Points)
+		assert.Equal(t, 0.0, d.hs[0])
+		assert.InDelta(t, 0.03, d.hs[1], 1e-12)
+	}
+
+	errDraw := errors.New("closed")
+	err := (&Scalar{System: &SystemEuler{F: Lift(benchF)}}).Solve(0.1, 0, 1, 1, DrawerFunc(func(p num.Point) error {
+		if p.X > 0.25 {
+			return errDraw
+		}
+		return nil
+	}))
+	assert.EqualError(t, err, "Euler's method failed at step 3, draw at x=0.3000 y=0.5168: closed")
+}
+
+func TestSystem_Errors(t *testing.T) {
+	spring := func(x float64, y []float64) ([]float64, error) { return []float64{y[1], -y[0]}, nil }
+	s := &SystemRungeKutta{F: spring}
+
+	err := s.Solve(0, 0, []float64{1, 0}, 1, &VectorCollector{})
+	assert.True(t, errors.Is(err, num.ErrBadStep), err)
+	assert.EqualError(t, s.Solve(0.1, 0, nil, 1, &VectorCollector{}), "the system has no equations, y0 is empty")
+
+	pts, err := CollectSystem(s, 0.1, 2, []float64{1, 0}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []num.VectorPoint{{X: 2, Y: []float64{1, 0}}}, pts, "the empty interval is the single point")
+
+	// the failure of f is located at the stage of the step
+	errF := errors.New("failed")
+	calls := 0
+	c := &VectorCollector{}
+	err = (&SystemRungeKutta{F: func(x float64, y []float64) ([]float64, error) {
+		if calls++; calls == 7 {
+			return nil, errF
+		}
+		return spring(x, y)
+	}}).Solve(0.1, 0, []float64{1, 0}, 1, c)
+	assert.True(t, errors.Is(err, errF))
+	var se *StepError
+	require.True(t, errors.As(err, &se))
+	assert.Equal(t, "k3", se.Stage)
+	assert.Equal(t, 1, se.Step)
+	assert.Contains(t, err.Error(), "Runge-Kutta's method failed at step 1, k3 at x=0.1500")
+	assert.Len(t, c.Points, 2, "points before the failure are drawn")
+
+	err = (&SystemEuler{F: func(x float64, y []float64) ([]float64, error) { return []float64{1}, nil }}).
+		Solve(0.1, 0, []float64{1, 2}, 1, &VectorCollector{})
+	assert.EqualError(t, err, "Euler's method failed at step 0, f at x=0.0000 y=1.0000: y=[1 2]: "+
+		"f returned 1 components, expected 2")
+
+	err = s.Solve(0.1, 0, []float64{1, 0}, 1, VectorDrawerFunc(func(p num.VectorPoint) error {
+		if p.X > 0.45 {
+			return errF
+		}
+		return nil
+	}))
+	assert.True(t, errors.Is(err, errF))
+	require.True(t, errors.As(err, &se))
+	assert.Equal(t, "draw", se.Stage)
+	assert.Equal(t, 5, se.Step)
+}
@@ -0,0 +1,183 @@
+package solver
+
+import (
+	"strconv"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// MaxTaylorOrder is the max order of Taylor's method
+const MaxTaylorOrder = 8
+
+// SeriesFunc returns the truncated Taylor series of f(x(t), y(t)) by t, given series of x(t) and y(t)
+// of the same length, the k-th coefficient is the k-th derivative by t divided by k!, e.g. expr.ParseSeries2
+// differentiates the formula of f automatically, Partials makes it of partial derivatives
+type SeriesFunc func(xs, ys []float64) ([]float64, error)
+
+// Taylor is Taylor's method y_{i+1} = y_i + h y^(1) + h^2/2 y^(2) + ... + h^p/p! y^(p) of the order p, derivatives
+// of the solution are found by derivatives of f along it: y^(2) = f_x + f_y f and so on, so each step is exact
+// for the polynomial solution of the degree p without stages of Runge-Kutta's methods
+type Taylor struct {
+	Series SeriesFunc // the series of f(x,y) = y' along the solution
+	Order  int        // order of the method from 1 to MaxTaylorOrder, 2 if zero
+}
+
+// Name returns the name of the method
+func (t *Taylor) Name() string { return "Taylor's method of order " + strconv.Itoa(t.order()) }
+
+func (t *Taylor) order() int {
+	if t.Order == 0 {
+		return 2
+	}
+	return t.Order
+}
+
+// check validates the order and the series
+func (t *Taylor) check() error {
+	if t.order() < 1 || t.order() > MaxTaylorOrder {
+		return errors.Errorf("order must be from 1 to %d, got %d", MaxTaylorOrder, t.Order)
+	}
+	if t.Series == nil {
+		return errors.New("series of f is not set")
+	}
+	return nil
+}
+
+// Solve the initial value problem with Taylor's method
+func (t *Taylor) Solve(stepSize, x0, y0, xEnd float64, d Drawer) error {
+	if err := checkArgs(stepSize, x0, xEnd); err != nil {
+		return err
+	}
+	if err := t.check(); err != nil {
+		return err
+	}
+
+	logger(d).Logf("[DEBUG] starting solving the equation with Taylor's method of order %d "+
+		"with stepsz = %.4f, x0 = %.4f, y0 = %.4f, xend = %.4f", t.order(), stepSize, x0, y0, xEnd)
+
+	return t.solve(NewGrid(x0, xEnd, stepSize), 0, y0, d)
+}
+
+// Resume continues the solution from the checkpoint up to xEnd
+func (t *Taylor) Resume(cp Checkpoint, xEnd float64, d Drawer) error {
+	if err := t.check(); err != nil {
+		return err
+	}
+	g, err := cp.grid(xEnd)
+	if err != nil {
+		return err
+	}
+	return t.solve(g, cp.Node, cp.Y, d)
+}
+
+// solve solves the problem on the grid from the node, where the solution is y
+func (t *Taylor) solve(g Grid, from int, y float64, d Drawer) error {
+	p := t.order()
+	xs, ys := make([]float64, p+1), make([]float64, p+1)
+	xs[1] = 1 // x(t) = x + t
+
+	out := sinkOf(t.Name(), d)
+	for i := from; i <= g.N; i++ {
+		x := g.X(i)
+		if err := out.put(i, g.Step(i), num.Point{X: x, Y: y}); err != nil {
+			return err
+		}
+		if i == g.N {
+			break // the point is the last one, so the next step is not calculated
+		}
+		h := g.Step(i + 1) // the last step might be shortened to end at xEnd
+
+		// the k-th coefficient of y(x+t) is the (k-1)-th one of f(x+t, y(x+t)) divided by k,
+		// the latter depends only on coefficients of y up to k-1
+		xs[0], ys[0] = x, y
+		for k := 0; k < p; k++ {
+			fs, err := t.Series(xs[:k+1], ys[:k+1])
+			if err != nil {
+				return out.fail(&StepError{Method: t.Name(), Step: i, Stage: "y^(" + strconv.Itoa(k+1) + ")",
+					X: x, Y: y, Err: err})
+			}
+			ys[k+1] = fs[k] / float64(k+1)
+		}
+
+		// y(x+h) by Horner's scheme
+		next := ys[p]
+		for k := p - 1; k >= 0; k-- {
+			next = next*h + ys[k]
+		}
+		y = next
+	}
+
+	return out.flush()
+}
+
+// Partials are f(x,y) and its partial derivatives, supplied by the caller instead of the automatic
+// differentiation, second derivatives are required only for Taylor's method of order 3
+type Partials struct {
+	F, Fx, Fy     Func
+	Fxx, Fxy, Fyy Func
+}
+
+// DiffPartials makes f and its first partial derivatives, estimated by central differences, for Taylor's
+// method of order 2 on f, that is given only by its values, e.g. by the builder of the registry
+func DiffPartials(f Func) Partials {
+	return Partials{
+		F: f,
+		Fx: func(x, y float64) (float64, error) {
+			return centralDiff(func(v float64) (float64, error) { return f(v, y) }, x)
+		},
+		Fy: func(x, y float64) (float64, error) {
+			return centralDiff(func(v float64) (float64, error) { return f(x, v) }, y)
+		},
+	}
+}
+
+// Series returns the series of f(x(t), y(t)) up to the second order by the chain rule, it is SeriesFunc
+// of Taylor's method of order up to 3
+func (p Partials) Series(xs, ys []float64) ([]float64, error) {
+	switch {
+	case len(xs) != len(ys) || len(xs) == 0:
+		return nil, errors.New("series must be of the same non-zero length")
+	case len(xs) > 3:
+		return nil, errors.Errorf("partial derivatives give series of 3 coefficients at most, %d requested", len(xs))
+	case p.F == nil || len(xs) > 1 && (p.Fx == nil || p.Fy == nil):
+		return nil, errors.New("f and its first partial derivatives are required")
+	case len(xs) > 2 && (p.Fxx == nil || p.Fxy == nil || p.Fyy == nil):
+		return nil, errors.New("second partial derivatives are required")
+	}
+
+	x, y := xs[0], ys[0]
+	res := make([]float64, len(xs))
+	var err error
+	if res[0], err = p.F(x, y); err != nil {
+		return nil, err
+	}
+	if len(xs) == 1 {
+		return res, nil
+	}
+	var fx, fy float64
+	if fx, err = p.Fx(x, y); err != nil {
+		return nil, errors.Wrap(err, "failed to evaluate df/dx")
+	}
+	if fy, err = p.Fy(x, y); err != nil {
+		return nil, errors.Wrap(err, "failed to evaluate df/dy")
+	}
+	res[1] = fx*xs[1] + fy*ys[1]
+	if len(xs) == 2 {
+		return res, nil
+	}
+
+	// the second coefficient is (f_xx x1^2 + 2 f_xy x1 y1 + f_yy y1^2) / 2 + f_x x2 + f_y y2
+	var fxx, fxy, fyy float64
+	if fxx, err = p.Fxx(x, y); err != nil {
+		return nil, errors.Wrap(err, "failed to evaluate d2f/dx2")
+	}
+	if fxy, err = p.Fxy(x, y); err != nil {
+		return nil, errors.Wrap(err, "failed to evaluate d2f/dxdy")
+	}
+	if fyy, err = p.Fyy(x, y); err != nil {
+		return nil, errors.Wrap(err, "failed to evaluate d2f/dy2")
+	}
+	res[2] = (fxx*xs[1]*xs[1]+2*fxy*xs[1]*ys[1]+fyy*ys[1]*ys[1])/2 + fx*xs[2] + fy*ys[2]
+	return res, nil
+}
@@ -0,0 +1,113 @@
+package solver
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num/expr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seriesOf differentiates the formula of f(x,y) automatically
+func seriesOf(t *testing.T, f string) SeriesFunc {
+	fs, err := expr.ParseSeries2(f, nil, "x", "y")
+	require.NoError(t, err)
+	return fs
+}
+
+func TestTaylor(t *testing.T) {
+	fs := seriesOf(t, "x^2 - 2*y")
+	exact := func(x float64) float64 { return 0.75*math.Exp(-2*x) + x*x/2 - x/2 + 0.25 }
+	maxErr := func(order, n int) float64 {
+		c := &Collector{}
+		require.NoError(t, (&Taylor{Series: fs, Order: order}).Solve(1/float64(n), 0, 1, 1, c))
+		require.Len(t, c.Points, n+1)
+		res := 0.0
+		for _, p := range c.Points {
+			res = math.Max(res, math.Abs(p.Y-exact(p.X)))
+		}
+		return res
+	}
+	for _, order := range []int{2, 3, 4} {
+		ratio := maxErr(order, 20) / maxErr(order, 40)
+		assert.InDelta(t, math.Pow(2, float64(order)), ratio, 0.15*math.Pow(2, float64(order)), "order %d", order)
+	}
+
+	// the first order is Euler's method
+	f := func(x, y float64) (float64, error) { return x*x - 2*y, nil }
+	want, err := Collect(&Euler{F: f}, 0.1, 0, 1, 1)
+	require.NoError(t, err)
+	got, err := Collect(&Taylor{Series: fs, Order: 1}, 0.1, 0, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, want.Points, got.Points)
+	assert.Equal(t, "Taylor's method of order 1", got.Name)
+
+	// the step of the order 3 is exact for the cubic solution y = x^3
+	c := &Collector{}
+	require.NoError(t, (&Taylor{Series: seriesOf(t, "3*x^2"), Order: 3}).Solve(0.5, 0, 0, 2, c))
+	for _, p := range c.Points {
+		assert.InDelta(t, p.X*p.X*p.X, p.Y, 1e-12)
+	}
+}
+
+func TestTaylor_Partials(t *testing.T) {
+	// y' = x*y^2, partial derivatives are supplied, the series is the same as of the formula
+	p := Partials{
+		F:   func(x, y float64) (float64, error) { return x * y * y, nil },
+		Fx:  func(x, y float64) (float64, error) { return y * y, nil },
+		Fy:  func(x, y float64) (float64, error) { return 2 * x * y, nil },
+		Fxx: func(x, y float64) (float64, error) { return 0, nil },
+		Fxy: func(x, y float64) (float64, error) { return 2 * y, nil },
+		Fyy: func(x, y float64) (float64, error) { return 2 * x, nil },
+	}
+	for _, order := range []int{1, 2, 3} {
+		want, err := Collect(&Taylor{Series: seriesOf(t, "x*y^2"), Order: order}, 0.05, 0, 0.5, 1)
+		require.NoError(t, err)
+		got, err := Collect(&Taylor{Series: p.Series, Order: order}, 0.05, 0, 0.5, 1)
+		require.NoError(t, err)
+		require.Len(t, got.Points, len(want.Points))
+		for i := range want.Points {
+			assert.InDelta(t, want.Points[i].Y, got.Points[i].Y, 1e-12, "order %d", order)
+		}
+	}
+
+	err := (&Taylor{Series: p.Series, Order: 4}).Solve(0.1, 0, 0.5, 1, &Collector{})
+	assert.Contains(t, err.Error(), "partial derivatives give series of 3 coefficients at most, 4 requested")
+	err = (&Taylor{Series: Partials{F: p.F, Fx: p.Fx, Fy: p.Fy}.Series, Order: 3}).Solve(0.1, 0, 0.5, 1, &Collector{})
+	assert.Contains(t, err.Error(), "second partial derivatives are required")
+	require.NoError(t, (&Taylor{Series: Partials{F: p.F, Fx: p.Fx, Fy: p.Fy}.Series}).Solve(0.1, 0, 0.5, 1, &Collector{}),
+		"the order 2 needs first derivatives only")
+}
+
+func TestTaylor_Errors(t *testing.T) {
+	fs := seriesOf(t, "x - y")
+	assert.EqualError(t, (&Taylor{Series: fs, Order: 9}).Solve(0.1, 0, 1, 1, &Collector{}), "order must be from 1 to 8, got 9")
+	assert.EqualError(t, (&Taylor{Order: 3}).Solve(0.1, 0, 1, 1, &Collector{}), "series of f is not set")
+
+	fail := errors.New("fail")
+	err := (&Taylor{Order: 3, Series: func(xs, ys []float64) ([]float64, error) {
+		if len(xs) == 2 && xs[0] > 0.25 {
+			return nil, fail
+		}
+		return fs(xs, ys)
+	}}).Solve(0.1, 0, 1, 1, &Collector{})
+	var se *StepError
+	require.True(t, errors.As(err, &se))
+	assert.Equal(t, 3, se.Step)
+	assert.Equal(t, "y^(2)", se.Stage)
+	assert.True(t, errors.Is(err, fail))
+}
+
+// BenchmarkTaylor measures fewer steps, as series are evaluated by the automatic differentiation
+func BenchmarkTaylor(b *testing.B) {
+	fs, err := expr.ParseSeries2("x^2 - 2*y", nil, "x", "y")
+	require.NoError(b, err)
+	for _, order := range []int{2, 4} {
+		b.Run(fmt.Sprintf("order %d", order), func(b *testing.B) {
+			benchSolverN(b, &Taylor{Series: fs, Order: order}, benchSteps/100)
+		})
+	}
+}
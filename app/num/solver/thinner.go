@@ -0,0 +1,112 @@
+package solver
+
+import (
+	"math"
+
+	"github.com/Semior001/decompract/app/num"
+)
+
+// CurvatureThinner is the drawer, that passes points of the solution to the wrapped one only where
+// the solution bends, so the chart has dense points at knees and sparse ones on flat parts. The point
+// is kept, if the angle between the chord from the last kept point to it and the chord from it
+// to the next point exceeds Angle, or if the arc from the last kept point would exceed MaxArc without it.
+// The first and the last points are kept, as well as not finite ones with their neighbours. The decision
+// on the point is made by the next one, so Flush must be called after the solution to draw the last point
+type CurvatureThinner struct {
+	Angle  float64 // the least angle between chords in radians, that keeps the point
+	MaxArc float64 // the longest arc between kept points, unlimited if not positive
+	// ScaleX and ScaleY scale coordinates before angles and arcs are measured, e.g. to pixels of the chart, 1 if zero
+	ScaleX, ScaleY float64
+
+	next      Drawer
+	kept      num.Point // the last kept point, scaled
+	candidate num.Point // the pending point, unscaled
+	pending   bool
+	started   bool
+	arc       float64 // the arc from the last kept point to the candidate
+	dropped   int
+}
+
+// NewCurvatureThinner makes the thinner of points, drawn to d, with the least angle between chords,
+// that keeps the point, and the longest arc between kept points
+func NewCurvatureThinner(d Drawer, angle, maxArc float64) *CurvatureThinner {
+	return &CurvatureThinner{Angle: angle, MaxArc: maxArc, next: d}
+}
+
+// Dropped returns the number of points, that are not passed to the wrapped drawer
+func (t *CurvatureThinner) Dropped() int { return t.dropped }
+
+// Draw decides on the pending point and keeps the new one pending
+func (t *CurvatureThinner) Draw(p num.Point) error {
+	if !isFinite(p.X) || !isFinite(p.Y) {
+		// the gap of the solution is kept with its ends
+		if err := t.Flush(); err != nil {
+			return err
+		}
+		t.started = false
+		return t.next.Draw(p)
+	}
+	if !t.started {
+		t.started = true
+		return t.keep(p)
+	}
+	if !t.pending {
+		t.candidate, t.pending = p, true
+		t.arc = dist(t.kept, t.scale(p))
+		return nil
+	}
+
+	c, n := t.scale(t.candidate), t.scale(p)
+	step := dist(c, n)
+	if t.bends(c, n) || t.MaxArc > 0 && t.arc+step > t.MaxArc {
+		if err := t.keep(t.candidate); err != nil {
+			return err
+		}
+	} else {
+		t.dropped++
+	}
+	t.candidate, t.pending, t.arc = p, true, t.arc+step
+	return nil
+}
+
+// Flush draws the pending point, as it is the last one of the solution
+func (t *CurvatureThinner) Flush() error {
+	if !t.pending {
+		return nil
+	}
+	return t.keep(t.candidate)
+}
+
+// keep passes the point to the wrapped drawer and measures the next arc from it
+func (t *CurvatureThinner) keep(p num.Point) error {
+	t.kept, t.pending, t.arc = t.scale(p), false, 0
+	return t.next.Draw(p)
+}
+
+// bends checks whether the chord from the last kept point to the candidate c and the chord
+// from it to the next point n turn by more than Angle, chords of zero length don't turn
+func (t *CurvatureThinner) bends(c, n num.Point) bool {
+	ax, ay := c.X-t.kept.X, c.Y-t.kept.Y
+	bx, by := n.X-c.X, n.Y-c.Y
+	if ax == 0 && ay == 0 || bx == 0 && by == 0 {
+		return false
+	}
+	return math.Abs(math.Atan2(ax*by-ay*bx, ax*bx+ay*by)) > t.Angle
+}
+
+// scale scales the point to measure angles and arcs
+func (t *CurvatureThinner) scale(p num.Point) num.Point {
+	sx, sy := t.ScaleX, t.ScaleY
+	if sx == 0 {
+		sx = 1
+	}
+	if sy == 0 {
+		sy = 1
+	}
+	return num.Point{X: p.X * sx, Y: p.Y * sy}
+}
+
+// dist returns the distance between points
+func dist(a, b num.Point) float64 {
+	return math.Hypot(b.X-a.X, b.Y-a.Y)
+}
@@ -0,0 +1,101 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// knee is flat up to x=5, where it sharply turns into the slope of 1
+func knee(x float64) float64 { return math.Log1p(math.Exp(40*(x-5))) / 40 }
+
+// interpolate returns the linear interpolation of sorted points at x
+func interpolate(pts []num.Point, x float64) float64 {
+	for i := 1; i < len(pts); i++ {
+		if x <= pts[i].X {
+			a, b := pts[i-1], pts[i]
+			return a.Y + (b.Y-a.Y)*(x-a.X)/(b.X-a.X)
+		}
+	}
+	return pts[len(pts)-1].Y
+}
+
+func TestCurvatureThinner(t *testing.T) {
+	var full []num.Point
+	for i := 0; i <= 1000; i++ {
+		x := float64(i) / 100
+		full = append(full, num.Point{X: x, Y: knee(x)})
+	}
+
+	c := &Collector{}
+	th := NewCurvatureThinner(c, 0.5*math.Pi/180, 1)
+	for _, p := range full {
+		require.NoError(t, th.Draw(p))
+	}
+	require.NoError(t, th.Flush())
+	assert.Equal(t, len(full), len(c.Points)+th.Dropped())
+	assert.Less(t, len(c.Points), len(full)/5)
+	assert.Equal(t, full[0], c.Points[0], "the first point is kept")
+	assert.Equal(t, full[len(full)-1], c.Points[len(c.Points)-1], "the last point is kept")
+
+	count := func(from, to float64) int {
+		res := 0
+		for _, p := range c.Points {
+			if p.X >= from && p.X < to {
+				res++
+			}
+		}
+		return res
+	}
+	assert.GreaterOrEqual(t, count(4.9, 5.2), 10, "dense points at the knee")
+	// the cap of the arc keeps a point per unit of length of the rest
+	assert.LessOrEqual(t, count(0, 4), 5, "sparse points on the flat part")
+	assert.LessOrEqual(t, count(6, 10), 6, "sparse points on the straight slope")
+
+	maxDev := 0.0
+	for _, p := range full {
+		maxDev = math.Max(maxDev, math.Abs(interpolate(c.Points, p.X)-p.Y))
+	}
+	assert.Less(t, maxDev, 2e-3, "the thinned series follows the full one")
+}
+
+func TestCurvatureThinner_MaxArc(t *testing.T) {
+	c := &Collector{}
+	th := &CurvatureThinner{Angle: math.Pi, MaxArc: 2.5, ScaleX: 10, next: c}
+	for i := 0; i <= 10; i++ {
+		require.NoError(t, th.Draw(num.Point{X: float64(i) / 10, Y: 0}))
+	}
+	require.NoError(t, th.Flush())
+	xs := make([]float64, 0, len(c.Points))
+	for _, p := range c.Points {
+		xs = append(xs, p.X)
+	}
+	assert.InDeltaSlice(t, []float64{0, 0.2, 0.4, 0.6, 0.8, 1}, xs, 1e-12, "arcs are measured in scaled units")
+	assert.Equal(t, 5, th.Dropped())
+}
+
+func TestCurvatureThinner_Gaps(t *testing.T) {
+	c := &Collector{}
+	th := NewCurvatureThinner(c, 0.1, 0)
+	pts := []num.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 3, Y: math.NaN()}, {X: 4, Y: math.Inf(1)},
+		{X: 5, Y: 0}, {X: 6, Y: 0}, {X: 7, Y: 0}}
+	for _, p := range pts {
+		require.NoError(t, th.Draw(p))
+	}
+	require.NoError(t, th.Flush())
+	require.Len(t, c.Points, 6, "ends of the gap are kept")
+	assert.Equal(t, []float64{0, 2, 3, 4, 5, 7}, []float64{c.Points[0].X, c.Points[1].X, c.Points[2].X,
+		c.Points[3].X, c.Points[4].X, c.Points[5].X})
+	assert.Equal(t, 2, th.Dropped())
+
+	// failures of the wrapped drawer are returned
+	failed := errors.New("failed")
+	th = NewCurvatureThinner(DrawerFunc(func(num.Point) error { return failed }), 0.1, 0)
+	assert.Equal(t, failed, th.Draw(num.Point{}))
+	require.NoError(t, th.Draw(num.Point{X: 1}))
+	assert.Equal(t, failed, th.Flush())
+}
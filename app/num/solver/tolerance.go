@@ -0,0 +1,118 @@
+package solver
+
+import (
+	"math"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/pkg/errors"
+)
+
+// StepEstimate is the largest step, found by EstimateStepForTolerance, with the error of the solution with it
+type StepEstimate struct {
+	Step  float64
+	N     int     // number of steps of the interval with the step
+	Error float64 // max global error of the solution, or its estimate by the doubled number of steps
+	Tries int     // number of solutions, made to find the step
+}
+
+// EstimateStepForTolerance finds the largest step of the solver, with which the max global error of the solution
+// doesn't exceed tol: the number of steps is doubled from one, until the error is within tol, then it is bisected
+// between the last two numbers, as the error decreases with the step. The error is measured against the exact
+// solution at nodes, if exact is set, otherwise it is estimated by the difference with the solution with the doubled
+// number of steps, the not finite error exceeds any tol. If the error exceeds tol with maxSteps steps, the estimate
+// of maxSteps is returned along with ErrNotConverged
+func EstimateStepForTolerance(s Interface, exact *Exact, tol float64, x0, y0, xEnd float64,
+	maxSteps int) (*StepEstimate, error) {
+	if !(tol > 0) || math.IsInf(tol, 1) {
+		return nil, errors.Errorf("tolerance must be positive and finite, got %v", tol)
+	}
+	if maxSteps < 1 {
+		return nil, errors.Errorf("max number of steps must be positive, got %d", maxSteps)
+	}
+	var c float64
+	if exact != nil {
+		var err error
+		if c, err = exact.Constant(x0, y0); err != nil {
+			return nil, err
+		}
+	}
+
+	res := &StepEstimate{}
+	solve := func(n int) (num.Line, error) {
+		step, err := num.CalculateStepSize(n, x0, xEnd)
+		if err != nil {
+			return num.Line{}, err
+		}
+		res.Tries++
+		line, err := Collect(s, step, x0, y0, xEnd)
+		return line, errors.Wrapf(err, "failed to solve with n=%d", n)
+	}
+	// errorOf measures the max global error of the solution with n steps
+	errorOf := func(n int) (float64, error) {
+		line, err := solve(n)
+		if err != nil {
+			return 0, err
+		}
+		var ref func(i int, p num.Point) (float64, error)
+		if exact != nil {
+			ref = func(_ int, p num.Point) (float64, error) { return exact.F(p.X, c) }
+		} else {
+			fine, err := solve(2 * n)
+			if err != nil {
+				return 0, err
+			}
+			if len(fine.Points) != 2*len(line.Points)-1 {
+				return 0, errors.Wrapf(ErrPointsCount, "solutions with n=%d and n=%d have %d and %d points",
+					n, 2*n, len(line.Points), len(fine.Points))
+			}
+			ref = func(i int, _ num.Point) (float64, error) { return fine.Points[2*i].Y, nil }
+		}
+		gte := 0.0
+		for i, p := range line.Points {
+			y, err := ref(i, p)
+			if err != nil {
+				return 0, errors.Wrapf(err, "failed to calculate exact solution at x=%.4f with n=%d", p.X, n)
+			}
+			e := math.Abs(p.Y - y)
+			if !isFinite(e) {
+				return math.Inf(1), nil
+			}
+			gte = math.Max(gte, e)
+		}
+		return gte, nil
+	}
+	accept := func(n int, e float64) *StepEstimate {
+		step, _ := num.CalculateStepSize(n, x0, xEnd) // the step of n is calculated by errorOf already
+		res.Step, res.N, res.Error = step, n, e
+		return res
+	}
+
+	// doubling brackets the least number of steps between lo, that exceeds tol, and hi, that doesn't
+	lo, hi := 0, 1
+	e, err := errorOf(hi)
+	for ; err == nil && e > tol; e, err = errorOf(hi) {
+		if hi == maxSteps {
+			return accept(hi, e), errors.Wrapf(ErrNotConverged, "max error %g with n=%d, tolerance is %g", e, hi, tol)
+		}
+		lo, hi = hi, 2*hi
+		if hi > maxSteps {
+			hi = maxSteps
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		me, err := errorOf(mid)
+		if err != nil {
+			return nil, err
+		}
+		if me > tol {
+			lo = mid
+			continue
+		}
+		hi, e = mid, me
+	}
+	return accept(hi, e), nil
+}
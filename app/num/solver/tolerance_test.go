@@ -0,0 +1,55 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateStepForTolerance(t *testing.T) {
+	exact := &Exact{
+		F: func(x, c float64) (float64, error) { return math.Exp(-x) / (c*math.Exp(x) + 1), nil },
+		C: func(x0, y0 float64) (float64, error) { return (math.Exp(-x0) - y0) / (y0 * math.Exp(x0)), nil },
+	}
+	for _, s := range []Interface{&Euler{F: canonical}, &ImprovedEuler{F: canonical}, &RungeKutta{F: canonical}} {
+		est, err := EstimateStepForTolerance(s, exact, 1e-4, 0, 1, 1, 10000)
+		require.NoError(t, err, s.Name())
+		assert.LessOrEqual(t, est.Error, 1e-4, s.Name())
+		assert.InDelta(t, 1/float64(est.N), est.Step, 1e-15, s.Name())
+
+		// the step is the largest one, as one step less exceeds the tolerance
+		c := &Collector{}
+		require.NoError(t, ConvergenceStudy(s, exact, est.N-1, est.N, 0, 1, 1, c))
+		assert.Greater(t, c.Points[0].Y, 1e-4, s.Name())
+		assert.Equal(t, est.Error, c.Points[1].Y, s.Name())
+	}
+
+	// without the exact solution the error is estimated by the doubled number of steps
+	est, err := EstimateStepForTolerance(&RungeKutta{F: canonical}, nil, 1e-6, 0, 1, 1, 10000)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, est.Error, 1e-6)
+	c := &Collector{}
+	require.NoError(t, ConvergenceStudy(&RungeKutta{F: canonical}, exact, est.N, est.N, 0, 1, 1, c))
+	assert.InDelta(t, est.Error, c.Points[0].Y, est.Error/10, "the estimate is close to the actual error")
+	assert.Greater(t, est.Tries, 2)
+}
+
+func TestEstimateStepForTolerance_Errors(t *testing.T) {
+	est, err := EstimateStepForTolerance(&Euler{F: canonical}, nil, 1e-9, 0, 1, 1, 100)
+	assert.True(t, errors.Is(err, ErrNotConverged), err)
+	require.NotNil(t, est)
+	assert.Equal(t, 100, est.N)
+	assert.Greater(t, est.Error, 1e-9)
+
+	_, err = EstimateStepForTolerance(&Euler{F: canonical}, nil, 0, 0, 1, 1, 100)
+	assert.EqualError(t, err, "tolerance must be positive and finite, got 0")
+	_, err = EstimateStepForTolerance(&Euler{F: canonical}, nil, 1e-3, 0, 1, 1, 0)
+	assert.EqualError(t, err, "max number of steps must be positive, got 0")
+
+	errF := errors.New("failed")
+	_, err = EstimateStepForTolerance(&Euler{F: func(x, y float64) (float64, error) { return 0, errF }}, nil, 1e-3, 0, 1, 1, 100)
+	assert.True(t, errors.Is(err, errF), err)
+}
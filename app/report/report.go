@@ -0,0 +1,189 @@
+// Package report writes reports of cli commands and responses of the REST API in csv, json, table
+// and markdown formats.
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+)
+
+// formats of reports
+const (
+	FormatCSV      = "csv"
+	FormatJSON     = "json"
+	FormatTable    = "table"
+	FormatMarkdown = "markdown"
+)
+
+// Formats are the names of all formats of reports
+var Formats = []string{FormatCSV, FormatJSON, FormatTable, FormatMarkdown}
+
+// Report is the result of the cli command, that is written in any of formats,
+// json is encoded from the report itself, the rest of formats are rendered from its table
+type Report interface {
+	// Table returns the header and the rows of the report, numbers are formatted
+	// with prec significant digits, or as the report prefers, if prec is zero
+	Table(prec int) (header []string, rows [][]string)
+}
+
+// Summarizer is the report, that has the summary, written after its table by the output with stats
+type Summarizer interface {
+	Summary(prec int) (header []string, rows [][]string)
+}
+
+// Output describes how reports are written
+type Output struct {
+	Format    string `json:"format,omitempty" yaml:"format,omitempty"`       // one of Formats
+	Out       string `json:"out,omitempty" yaml:"out,omitempty"`             // file to write the report to, - for stdout
+	Precision int    `json:"precision,omitempty" yaml:"precision,omitempty"` // significant digits, the default of the report if zero
+	// Stats adds the summary of each line of the solution after its table, in table and markdown formats,
+	// json reports always have it
+	Stats bool `json:"stats,omitempty" yaml:"stats,omitempty"`
+}
+
+// Validate checks the format and the precision of the output
+func (o Output) Validate() error {
+	if !isFormat(o.Format) {
+		return errors.Errorf("unknown format %q, must be one of %s", o.Format, strings.Join(Formats, ", "))
+	}
+	if o.Precision < 0 {
+		return errors.Errorf("precision must not be negative, got %d", o.Precision)
+	}
+	if o.Stats && o.Format == FormatCSV {
+		return errors.New("stats are not supported in csv format, as it has the single table")
+	}
+	return nil
+}
+
+// Write writes the report in the format of the output
+func (o Output) Write(wr io.Writer, rep Report) error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	if o.Format == FormatJSON {
+		// fields of structs are written in the order of declaration and keys of maps are sorted,
+		// so the same report is always encoded the same way
+		enc := json.NewEncoder(wr)
+		enc.SetIndent("", "  ")
+		return errors.Wrap(enc.Encode(rep), "failed to encode report")
+	}
+
+	header, rows := rep.Table(o.Precision)
+	switch o.Format {
+	case FormatCSV:
+		return WriteCSV(wr, header, rows)
+	case FormatMarkdown:
+		if err := WriteMarkdown(wr, header, rows); err != nil {
+			return err
+		}
+		return o.writeSummary(wr, rep, WriteMarkdown)
+	default:
+		if err := WriteTable(wr, header, rows); err != nil {
+			return err
+		}
+		return o.writeSummary(wr, rep, WriteTable)
+	}
+}
+
+// writeSummary writes the summary of the report after the blank line with the writer of tables,
+// if the output wants stats and the report has the summary
+func (o Output) writeSummary(wr io.Writer, rep Report, write func(io.Writer, []string, [][]string) error) error {
+	s, ok := rep.(Summarizer)
+	if !o.Stats || !ok {
+		return nil
+	}
+	if _, err := fmt.Fprintln(wr); err != nil {
+		return errors.Wrap(err, "failed to write summary")
+	}
+	header, rows := s.Summary(o.Precision)
+	return write(wr, header, rows)
+}
+
+// isFormat checks whether the format is one of Formats
+func isFormat(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteCSV writes the header and the rows as csv
+func WriteCSV(wr io.Writer, header []string, rows [][]string) error {
+	cw := csv.NewWriter(wr)
+	if err := cw.Write(header); err != nil {
+		return errors.Wrap(err, "failed to write header")
+	}
+	for i, row := range rows {
+		if err := cw.Write(row); err != nil {
+			return errors.Wrapf(err, "failed to write row %d", i)
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteTable writes the header and the rows with columns, aligned with spaces
+func WriteTable(wr io.Writer, header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(wr, 0, 0, 2, ' ', 0)
+	for _, row := range append([][]string{header}, rows...) {
+		if _, err := fmt.Fprintln(tw, strings.Join(row, "\t")); err != nil {
+			return errors.Wrap(err, "failed to write row")
+		}
+	}
+	return tw.Flush()
+}
+
+// WriteMarkdown writes the header and the rows as the markdown table, columns are padded to the same width,
+// so the table is readable as the text too, pipes in cells are escaped
+func WriteMarkdown(wr io.Writer, header []string, rows [][]string) error {
+	widths := make([]int, len(header))
+	for i := range widths {
+		widths[i] = 3 // the minimal delimiter of the column
+	}
+	all := make([][]string, 0, len(rows)+1)
+	for _, row := range append([][]string{header}, rows...) {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = strings.ReplaceAll(cell, "|", `\|`)
+			if n := len([]rune(cells[i])); n > widths[i] {
+				widths[i] = n
+			}
+		}
+		all = append(all, cells)
+	}
+
+	line := func(cells []string) string {
+		sb := strings.Builder{}
+		sb.WriteString("|")
+		for i, w := range widths {
+			cell := ""
+			if i < len(cells) {
+				cell = cells[i]
+			}
+			sb.WriteString(" " + cell + strings.Repeat(" ", w-len([]rune(cell))) + " |")
+		}
+		return sb.String()
+	}
+	delims := make([]string, len(widths))
+	for i, w := range widths {
+		delims[i] = strings.Repeat("-", w)
+	}
+
+	lines := []string{line(all[0]), line(delims)}
+	for _, row := range all[1:] {
+		lines = append(lines, line(row))
+	}
+	if _, err := fmt.Fprintln(wr, strings.Join(lines, "\n")); err != nil {
+		return errors.Wrap(err, "failed to write table")
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteMarkdown(buf, []string{"x", "name"}, [][]string{{"0.5", "a|b"}, {"1", "é"}}))
+	assert.Equal(t, "| x   | name |\n| --- | ---- |\n| 0.5 | a\\|b |\n| 1   | é    |\n", buf.String())
+}
+
+// stubReport is the report with the fixed table and the summary, if it is set
+type stubReport struct {
+	Rows    [][]string `json:"rows"`
+	summary [][]string
+}
+
+func (r stubReport) Table(int) (header []string, rows [][]string) { return []string{"x", "y"}, r.Rows }
+
+// summarized is the stub report with the summary
+type summarized struct{ stubReport }
+
+func (r summarized) Summary(int) (header []string, rows [][]string) {
+	return []string{"total"}, r.summary
+}
+
+func TestOutput_Write(t *testing.T) {
+	rep := stubReport{Rows: [][]string{{"0", "1"}, {"1", "2.5"}}, summary: [][]string{{"2"}}}
+	write := func(out Output, rep Report) string {
+		buf := &bytes.Buffer{}
+		require.NoError(t, out.Write(buf, rep))
+		return buf.String()
+	}
+
+	assert.Equal(t, "x,y\n0,1\n1,2.5\n", write(Output{Format: FormatCSV}, rep))
+	assert.Equal(t, "{\n  \"rows\": [\n    [\n      \"0\",\n      \"1\"\n    ],\n    [\n      \"1\",\n      \"2.5\"\n    ]\n  ]\n}\n",
+		write(Output{Format: FormatJSON}, rep))
+	assert.Equal(t, "x  y\n0  1\n1  2.5\n", write(Output{Format: FormatTable, Stats: true}, rep),
+		"reports without summary are written as is")
+	assert.Equal(t, "x  y\n0  1\n1  2.5\n\ntotal\n2\n", write(Output{Format: FormatTable, Stats: true}, summarized{rep}))
+	assert.Equal(t, "| x   | y   |\n| --- | --- |\n| 0   | 1   |\n| 1   | 2.5 |\n",
+		write(Output{Format: FormatMarkdown}, summarized{rep}), "the summary is written only with stats")
+
+	err := Output{Format: "xml"}.Write(&bytes.Buffer{}, rep)
+	assert.EqualError(t, err, `unknown format "xml", must be one of csv, json, table, markdown`)
+	err = Output{Format: FormatCSV, Stats: true}.Write(&bytes.Buffer{}, summarized{rep})
+	assert.EqualError(t, err, "stats are not supported in csv format, as it has the single table")
+}
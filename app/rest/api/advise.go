@@ -0,0 +1,454 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/expr"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/pkg/errors"
+)
+
+// MaxAdviseEvals is the budget of evaluations of f by Advise, the probe, that exceeds it, fails
+const MaxAdviseEvals = 500
+
+const (
+	adviseN       = 20   // steps of the coarse probe, the fine one has twice more
+	newtonIters   = 3    // iterations of Newton's method in the step of the implicit probe
+	blowUpFactor  = 1e6  // growth of the solution relative to max(1, |y0|), that is considered as the blow-up
+	stabilityGap  = 0.9  // share of the stability limit, the step is kept within, the transient decays slowly at the limit
+	defaultTarget = 1e-6 // the target error of the advise request
+)
+
+// candidates returns sorted names of registered methods with traits, either explicit or implicit ones
+func candidates(implicit bool) []string {
+	var res []string
+	for _, m := range solver.Methods() {
+		if tr, ok := solve.MethodTraits[m]; ok && tr.Implicit == implicit {
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
+// Advice is the recommended method of the registry with the number of steps to solve the problem
+// with the target error, reasons explain the choice in human-readable form
+type Advice struct {
+	Method    string   `json:"method"`
+	N         int      `json:"n"`
+	Step      float64  `json:"step"`
+	Stiffness float64  `json:"stiffness"` // max(-df/dy) along the solution by the width of the interval
+	Evals     int      `json:"evals"`     // evaluations of f, made by the probe
+	Reasons   []string `json:"reasons"`
+}
+
+// errAdviseBudget is returned by f of the probe, that exceeds MaxAdviseEvals
+var errAdviseBudget = errors.Errorf("probe takes more than %d evaluations of f", MaxAdviseEvals)
+
+// Advise probes the problem y' = f(x,y), y(x0) = y0 on [x0, xEnd] and recommends the method with the number
+// of steps, that reaches the target error at the least cost. The stiffness is estimated by samples of df/dy
+// along the solution, the error of each method is estimated against the fine solution by rk4 and the error
+// of rk4 itself by Richardson's extrapolation, the blow-up is detected by the growth of the probe.
+// Explicit methods are unstable, if h|df/dy| exceeds their limits, so stiff problems are advised to the implicit
+// backward Euler's method, its error is estimated by its own probes, steps of explicit methods are limited
+// by stability only, if it is not registered. The probe takes MaxAdviseEvals evaluations of f at most
+func Advise(f solver.Func, x0, y0, xEnd, targetErr float64) (*Advice, error) {
+	if !solve.IsFinite(x0) || !solve.IsFinite(y0) || !solve.IsFinite(xEnd) {
+		return nil, errors.Errorf("x0=%v, y0=%v and x_end=%v must be finite", x0, y0, xEnd)
+	}
+	if !(xEnd > x0) {
+		return nil, errors.Errorf("x_end=%v must be greater than x0=%v", xEnd, x0)
+	}
+	if !(targetErr > 0) || math.IsInf(targetErr, 1) {
+		return nil, errors.Errorf("target error must be positive and finite, got %v", targetErr)
+	}
+
+	pr := &probe{f: f, x0: x0, y0: y0, xEnd: xEnd}
+	adv, err := pr.advise(targetErr)
+	if err != nil {
+		return nil, err
+	}
+	adv.Evals = pr.evals
+	return adv, nil
+}
+
+// probe keeps the problem and counts evaluations of f against the budget
+type probe struct {
+	f             solver.Func
+	x0, y0, xEnd  float64
+	evals         int
+	lambda        float64 // the least df/dy along the probed solution, zero if it is not negative
+	width, coarse float64 // width of the interval and the step of the coarse probe
+}
+
+// eval evaluates f within the budget
+func (pr *probe) eval(x, y float64) (float64, error) {
+	if pr.evals >= MaxAdviseEvals {
+		return 0, errAdviseBudget
+	}
+	pr.evals++
+	return pr.f(x, y)
+}
+
+// dfdy estimates df/dy at the point by the central difference and accounts it in the least df/dy
+func (pr *probe) dfdy(x, y float64) (float64, error) {
+	d := 1e-6 * math.Max(1, math.Abs(y))
+	fp, err := pr.eval(x, y+d)
+	if err != nil {
+		return 0, err
+	}
+	fm, err := pr.eval(x, y-d)
+	if err != nil {
+		return 0, err
+	}
+	res := (fp - fm) / (2 * d)
+	if solve.IsFinite(res) && res < pr.lambda {
+		pr.lambda = res
+	}
+	return res, nil
+}
+
+// solve solves the problem by the method of the registry with n steps within the budget
+func (pr *probe) solve(method string, n int) ([]num.Point, error) {
+	c := &solver.Collector{}
+	if err := solve.Builder(method)(pr.eval).Solve(pr.width/float64(n), pr.x0, pr.y0, pr.xEnd, c); err != nil {
+		return nil, errors.Wrapf(err, "failed to probe the solution by %s", method)
+	}
+	return c.Points, nil
+}
+
+// implicit solves the problem by the backward Euler's method with n steps, it is stable for any step,
+// so it shows the shape of the solution of the stiff problem, df/dy is sampled at each node by Newton's method
+func (pr *probe) implicit(n int) ([]num.Point, error) {
+	h := pr.width / float64(n)
+	pts := []num.Point{{X: pr.x0, Y: pr.y0}}
+	y := pr.y0
+	for i := 1; i <= n; i++ {
+		x := pr.x0 + float64(i)*h
+		next := y
+		for it := 0; it < newtonIters; it++ {
+			fy, err := pr.eval(x, next)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to probe the solution by backward Euler's method at x=%v", x)
+			}
+			d, err := pr.dfdy(x, next)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to probe df/dy at x=%v", x)
+			}
+			g := next - y - h*fy
+			if dg := 1 - h*d; dg != 0 {
+				next -= g / dg
+			}
+			if math.Abs(g) <= 1e-12*math.Max(1, math.Abs(next)) {
+				break
+			}
+		}
+		y = next
+		pts = append(pts, num.Point{X: x, Y: y})
+	}
+	return pts, nil
+}
+
+// blowUp returns the first point of the solution, that is not finite or grows beyond the bound, nil if there is none
+func (pr *probe) blowUp(pts []num.Point) *num.Point {
+	bound := blowUpFactor * math.Max(1, math.Abs(pr.y0))
+	for i := range pts {
+		if !solve.IsFinite(pts[i].Y) || math.Abs(pts[i].Y) > bound {
+			return &pts[i]
+		}
+	}
+	return nil
+}
+
+// advise probes the problem and makes the advice
+func (pr *probe) advise(target float64) (*Advice, error) {
+	pr.width = pr.xEnd - pr.x0
+	pr.coarse = pr.width / adviseN
+	d0, err := pr.dfdy(pr.x0, pr.y0)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to probe df/dy at x0")
+	}
+
+	// the coarse explicit probe is unstable for the stiff problem, so its shape is probed by the implicit method
+	if -d0*pr.coarse > 1 {
+		pts, err := pr.implicit(adviseN)
+		if err != nil {
+			return nil, err
+		}
+		if p := pr.blowUp(pts); p != nil {
+			return pr.blownUp(*p), nil
+		}
+		if _, ok := solver.Lookup("beuler"); !ok {
+			return pr.stiff(), nil
+		}
+		half, err := pr.implicit(adviseN / 2)
+		if err != nil {
+			return nil, err
+		}
+		// the error of the first order with adviseN steps is about its difference with the half of them
+		adv := pr.choose(map[string]float64{"beuler": maxDiff(half, pts)}, target)
+		adv.Reasons = append([]string{fmt.Sprintf("the problem is stiff, df/dy reaches %.3g, implicit methods "+
+			"are stable with any step", pr.lambda)}, adv.Reasons...)
+		return adv, nil
+	}
+
+	coarse, err := pr.solve("rk4", adviseN)
+	if err != nil {
+		return nil, err
+	}
+	if p := pr.blowUp(coarse); p != nil {
+		return pr.blownUp(*p), nil
+	}
+	for _, p := range coarse[1:] {
+		if _, err = pr.dfdy(p.X, p.Y); err != nil {
+			return nil, errors.Wrapf(err, "failed to probe df/dy at x=%v", p.X)
+		}
+	}
+	fine, err := pr.solve("rk4", 2*adviseN)
+	if err != nil {
+		return nil, err
+	}
+	if p := pr.blowUp(fine); p != nil {
+		return pr.blownUp(*p), nil
+	}
+
+	// errors of methods with the coarse step, rk4 by Richardson's extrapolation, the rest against the fine rk4
+	errs := map[string]float64{"rk4": maxDiff(coarse, fine) * 16 / 15}
+	for _, m := range candidates(false) {
+		if m == "rk4" {
+			continue
+		}
+		pts, err := pr.solve(m, adviseN)
+		if err != nil {
+			return nil, err
+		}
+		errs[m] = maxDiff(pts, fine)
+	}
+	return pr.choose(errs, target), nil
+}
+
+// choose recommends the method of the least cost, that reaches the target error and is stable, errs are
+// errors of methods with the coarse step
+func (pr *probe) choose(errs map[string]float64, target float64) *Advice {
+	type option struct {
+		method       string
+		n, nAcc      int
+		cost         int
+		stabilityCap bool // the number of steps is set by stability, not by accuracy
+	}
+	var opts []option
+	for m, e := range errs {
+		tr := solve.MethodTraits[m]
+		// the error scales as h^order, so the number of steps, that reaches the target, is scaled from the probe
+		nAcc := int(math.Max(1, math.Ceil(adviseN*math.Pow(e/target, 1/float64(tr.Order)))))
+		o := option{method: m, n: nAcc, nAcc: nAcc}
+		if nStab := pr.stableSteps(tr.Stability); nStab > nAcc {
+			o.n, o.stabilityCap = nStab, true
+		}
+		o.cost = o.n * tr.Stages
+		opts = append(opts, o)
+	}
+	// the higher order wins the tie, as it is less sensitive to the estimate of the error
+	sort.Slice(opts, func(i, j int) bool {
+		if opts[i].cost != opts[j].cost {
+			return opts[i].cost < opts[j].cost
+		}
+		return solve.MethodTraits[opts[i].method].Order > solve.MethodTraits[opts[j].method].Order
+	})
+
+	best := opts[0]
+	adv := &Advice{Method: best.method, N: best.n, Step: pr.width / float64(best.n), Stiffness: pr.stiffness()}
+	adv.Reasons = append(adv.Reasons, fmt.Sprintf("%s with %d steps has the estimated error %.2g, so %d steps "+
+		"reach the target error %g", best.method, adviseN, errs[best.method], best.nAcc, target))
+	if best.stabilityCap {
+		adv.Reasons = append(adv.Reasons, fmt.Sprintf("the step is limited by stability, df/dy reaches %.3g, "+
+			"so %s needs %d steps to be stable", pr.lambda, best.method, best.n))
+	}
+	adv.Reasons = append(adv.Reasons, fmt.Sprintf("%s takes %d evaluations of f, the least of methods",
+		best.method, best.cost))
+	for _, o := range opts[1:] {
+		adv.Reasons = append(adv.Reasons, fmt.Sprintf("%s would take %d steps and %d evaluations of f", o.method, o.n, o.cost))
+	}
+	return adv
+}
+
+// stiff recommends rk4 for the stiff problem with the step, limited by stability, if backward Euler's method
+// is not registered, the error of explicit methods can't be estimated by the coarse probe
+func (pr *probe) stiff() *Advice {
+	tr := solve.MethodTraits["rk4"]
+	n := pr.stableSteps(tr.Stability)
+	return &Advice{Method: "rk4", N: n, Step: pr.width / float64(n), Stiffness: pr.stiffness(), Reasons: []string{
+		fmt.Sprintf("the problem is stiff, df/dy reaches %.3g, explicit methods are stable only with small steps, "+
+			"implicit methods are not available", pr.lambda),
+		fmt.Sprintf("rk4 has the widest stability limit h|df/dy| < %g, which takes %d steps", tr.Stability, n),
+		"the error isn't estimated, compare the solution with the one of twice more steps",
+	}}
+}
+
+// blownUp recommends rk4 with the fine step of the probe for the solution, that blows up at the point
+func (pr *probe) blownUp(p num.Point) *Advice {
+	n := 2 * adviseN
+	if s := pr.stableSteps(solve.MethodTraits["rk4"].Stability); s > n {
+		n = s
+	}
+	return &Advice{Method: "rk4", N: n, Step: pr.width / float64(n), Stiffness: pr.stiffness(), Reasons: []string{
+		fmt.Sprintf("the solution grows to %v near x=%.4g, it might blow up, shorten the interval before it", p.Y, p.X),
+		"the error isn't estimated, as the solution isn't bounded",
+	}}
+}
+
+// stableSteps returns the least number of steps, with which the method with the stability limit is stable
+// within the gap
+func (pr *probe) stableSteps(limit float64) int {
+	return int(math.Max(1, math.Ceil(pr.stiffness()/(stabilityGap*limit))))
+}
+
+// stiffness returns the largest decay rate along the solution by the width of the interval
+func (pr *probe) stiffness() float64 {
+	return -pr.lambda * pr.width
+}
+
+// maxDiff returns the largest difference between the coarse solution and the fine one at nodes of the coarse one,
+// the fine solution has twice more steps
+func maxDiff(coarse, fine []num.Point) float64 {
+	res := 0.0
+	for i, p := range coarse {
+		if 2*i < len(fine) {
+			res = math.Max(res, math.Abs(p.Y-fine[2*i].Y))
+		}
+	}
+	return res
+}
+
+// adviseReq is the problem y' = f(x,y), y(x0) = y0 on [x0, x_end] to advise the method for, with the target error
+type adviseReq struct {
+	F      string
+	Params map[string]float64
+	X0     float64
+	Y0     float64
+	XEnd   float64
+	Target float64
+}
+
+// adviseResp is the advice with the duration of the probe
+type adviseResp struct {
+	Advice
+	Took string `json:"took"`
+}
+
+// adviseProblem is the validated advise request, ready to probe
+type adviseProblem struct {
+	req      adviseReq
+	f        solver.Func
+	maxSteps int
+}
+
+// prepare validates the request, in case of invalid request, rest.ValidationError with all invalid fields is returned
+func (req adviseReq) prepare(l solve.Limits) (adviseProblem, error) {
+	res := adviseProblem{req: req, maxSteps: l.MaxSteps}
+	var errs rest.ValidationError
+	invalid := func(field, msg string, args ...interface{}) {
+		errs = append(errs, rest.FieldError{Field: field, Msg: fmt.Sprintf(msg, args...)})
+	}
+
+	for _, v := range []struct {
+		name string
+		val  float64
+	}{{"x0", req.X0}, {"y0", req.Y0}, {"x_end", req.XEnd}} {
+		if !solve.IsFinite(v.val) {
+			invalid(v.name, "must be finite")
+		}
+	}
+	if solve.IsFinite(req.X0) && solve.IsFinite(req.XEnd) && !(req.XEnd > req.X0) {
+		invalid("x_end", "must be greater than x0")
+	}
+	if !(req.Target > 0 && solve.IsFinite(req.Target)) {
+		invalid("target", "must be positive")
+	}
+	for _, name := range solve.ParamNames(req.Params) {
+		switch {
+		case !solve.ParamName.MatchString(name):
+			invalid("params", "%q is not a valid name", name)
+		case solve.IsReserved(name):
+			invalid("params", "%q is reserved", name)
+		case !solve.IsFinite(req.Params[name]):
+			invalid("params", "%s must be finite", name)
+		}
+	}
+
+	var err error
+	if res.f, err = expr.Parse2(req.F, req.Params, "x", "y"); err != nil {
+		invalid("f", "can't parse f(x,y): %v", err)
+	}
+
+	if len(errs) > 0 {
+		return adviseProblem{}, errs
+	}
+	return res, nil
+}
+
+// advise probes the problem, the advice notes the number of steps beyond max_steps of the server
+func (p adviseProblem) advise() (adviseResp, error) {
+	st := time.Now()
+	adv, err := Advise(p.f, p.req.X0, p.req.Y0, p.req.XEnd, p.req.Target)
+	if err != nil {
+		return adviseResp{}, err
+	}
+	if adv.N > p.maxSteps {
+		adv.Reasons = append(adv.Reasons, fmt.Sprintf("%d steps are more than max_steps=%d of the server", adv.N, p.maxSteps))
+	}
+	return adviseResp{Advice: *adv, Took: time.Since(st).String()}, nil
+}
+
+// readAdviseQuery reads the advise request from query parameters, the target error is 1e-6 by default
+func readAdviseQuery(r *http.Request) (req adviseReq, err error) {
+	if req.F, err = queryFormula(r, "f"); err != nil {
+		return adviseReq{}, err
+	}
+	if req.Params, err = queryParams(r); err != nil {
+		return adviseReq{}, err
+	}
+	for _, v := range []struct {
+		name string
+		dst  *float64
+	}{{"x0", &req.X0}, {"y0", &req.Y0}, {"x1", &req.XEnd}} {
+		if *v.dst, err = queryFloat(r, v.name, 0); err != nil {
+			return adviseReq{}, err
+		}
+	}
+	if req.Target, err = queryFloat(r, "target", defaultTarget); err != nil {
+		return adviseReq{}, err
+	}
+	return req, nil
+}
+
+// GET /api/v1/advise?f=x^2-2*y&x0=0&y0=1&x1=1&target=1e-6 - recommend the method and the number of steps
+// to solve the problem with the target error, the probe takes MaxAdviseEvals evaluations of f at most
+func (s *Rest) adviseCtrl(w http.ResponseWriter, r *http.Request) {
+	req, err := readAdviseQuery(r)
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "failed to read query parameters", rest.ErrDecode)
+		return
+	}
+
+	p, err := req.prepare(s.limits())
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid advise request", rest.ErrBadRequest)
+		return
+	}
+
+	resp, err := p.advise()
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, errors.Wrap(err, "failed to advise"),
+			"problem is not probed", rest.ErrInternal)
+		return
+	}
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	rest.RenderJSON(w, r, resp)
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num/expr"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func adviseURL(ts string, q url.Values) string {
+	return ts + "/api/v1/advise?" + strings.ReplaceAll(q.Encode(), "+", "%20")
+}
+
+// maxErr solves the preset by the method with n steps and returns the largest global error since x
+func maxErr(t *testing.T, prob solve.Problem, method string, n int, x float64) float64 {
+	prob.N, prob.Methods = n, []string{method}
+	p, err := prob.Request().Prepare(solve.Limits{MaxSteps: 100000})
+	require.NoError(t, err)
+	resp, err := p.Solve(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, resp.Exact)
+	res := 0.0
+	for i, pt := range resp.Lines[0].Points {
+		if pt.X < x {
+			continue
+		}
+		res = math.Max(res, math.Abs(pt.Y-resp.Exact.Points[i].Y))
+	}
+	return res
+}
+
+func TestAdvise(t *testing.T) {
+	prob := solve.Presets["canonical"]
+	f, err := expr.Parse2(prob.F, prob.Params, "x", "y")
+	require.NoError(t, err)
+	adv, err := Advise(f, prob.X0, prob.Y0, prob.XEnd, 1e-6)
+	require.NoError(t, err)
+	assert.Equal(t, "rk4", adv.Method)
+	assert.Greater(t, adv.N, 30)
+	assert.Less(t, adv.N, 1000)
+	assert.InDelta(t, (prob.XEnd-prob.X0)/float64(adv.N), adv.Step, 1e-12)
+	assert.LessOrEqual(t, adv.Evals, MaxAdviseEvals)
+	assert.Len(t, adv.Reasons, 4)
+	assert.Less(t, maxErr(t, prob, adv.Method, adv.N, prob.X0), 1e-5, "the advised number of steps is close to the target")
+
+	// ieuler is exact on y' = x, so its single step is the cheapest
+	adv, err = Advise(func(x, y float64) (float64, error) { return x, nil }, 0, 1, 1, 1e-6)
+	require.NoError(t, err)
+	assert.Equal(t, "ieuler", adv.Method)
+	assert.Equal(t, 1, adv.N)
+	assert.LessOrEqual(t, adv.Evals, MaxAdviseEvals)
+
+	// the stiff problem is advised to the implicit method, its steps are limited by the accuracy only
+	prob = solve.Presets["stiff-decay"]
+	f, err = expr.Parse2(prob.F, prob.Params, "x", "y")
+	require.NoError(t, err)
+	adv, err = Advise(f, prob.X0, prob.Y0, prob.XEnd, 1e-4)
+	require.NoError(t, err)
+	assert.Equal(t, "beuler", adv.Method)
+	assert.InDelta(t, 500, adv.Stiffness, 1)
+	assert.LessOrEqual(t, adv.Evals, MaxAdviseEvals)
+	require.Len(t, adv.Reasons, 3)
+	assert.Contains(t, adv.Reasons[0], "the problem is stiff")
+	// the fast transient is not resolved by the probe, past it the target is reached
+	assert.Less(t, maxErr(t, prob, adv.Method, adv.N, 1), 1e-4, "the advised number of steps reaches the target")
+
+	// without implicit methods the step of rk4 is limited by stability
+	beuler, ok := solver.Lookup("beuler")
+	require.True(t, ok)
+	solver.Unregister("beuler")
+	t.Cleanup(func() { solver.Register("beuler", beuler) })
+	adv, err = Advise(f, prob.X0, prob.Y0, prob.XEnd, 1e-6)
+	require.NoError(t, err)
+	assert.Equal(t, "rk4", adv.Method)
+	assert.LessOrEqual(t, adv.Step, 2.785/50)
+	assert.Contains(t, adv.Reasons[0], "implicit methods are not available")
+	// the transient is not resolved with the step, limited by stability, but it decays
+	assert.Less(t, maxErr(t, prob, adv.Method, adv.N, 1), 1e-2, "the advised number of steps is stable")
+
+	// the solution blows up at x=1
+	adv, err = Advise(func(x, y float64) (float64, error) { return y * y, nil }, 0, 1, 2, 1e-6)
+	require.NoError(t, err)
+	assert.Equal(t, "rk4", adv.Method)
+	assert.Contains(t, adv.Reasons[0], "it might blow up")
+	assert.LessOrEqual(t, adv.Evals, MaxAdviseEvals)
+
+	// the failure of f is returned
+	_, err = Advise(func(x, y float64) (float64, error) { return 0, assert.AnError }, 0, 1, 2, 1e-6)
+	assert.True(t, errors.Is(err, assert.AnError), err)
+
+	for _, tt := range []struct {
+		x0, xEnd, target float64
+		err              string
+	}{
+		{0, 0, 1e-6, "x_end=0 must be greater than x0=0"},
+		{0, math.Inf(1), 1e-6, "x0=0, y0=1 and x_end=+Inf must be finite"},
+		{0, 1, 0, "target error must be positive and finite, got 0"},
+		{0, 1, math.NaN(), "target error must be positive and finite, got NaN"},
+	} {
+		_, err = Advise(solver.Func(func(x, y float64) (float64, error) { return y, nil }), tt.x0, 1, tt.xEnd, tt.target)
+		assert.EqualError(t, err, tt.err)
+	}
+}
+
+func TestRest_Advise(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	q := url.Values{"f": {"x^2-2*y"}, "x0": {"0"}, "y0": {"1"}, "x1": {"1"}}
+	resp, err := http.Get(adviseURL(ts.URL, q))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := adviseResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.Equal(t, "rk4", res.Method)
+	assert.Greater(t, res.N, 0)
+	assert.LessOrEqual(t, res.Evals, MaxAdviseEvals)
+	assert.NotEmpty(t, res.Reasons)
+	assert.NotEmpty(t, res.Took)
+
+	// the number of steps beyond max_steps is noted
+	q = url.Values{"f": {"-k*(y - cos(x))"}, "x0": {"0"}, "y0": {"0"}, "x1": {"1000"}, "param": {"k:50"},
+		"target": {"1e-9"}}
+	resp, err = http.Get(adviseURL(ts.URL, q))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res = adviseResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.Greater(t, res.N, 10000)
+	assert.Contains(t, res.Reasons[len(res.Reasons)-1], "more than max_steps=10000 of the server")
+}
+
+func TestRest_AdviseInvalid(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	tbl := []struct {
+		q     url.Values
+		field string
+		msg   string
+	}{
+		{url.Values{"f": {"y"}, "x1": {"0"}}, "x_end", "must be greater than x0"},
+		{url.Values{"f": {"y"}, "x1": {"Inf"}}, "x_end", "must be finite"},
+		{url.Values{"f": {"y"}, "x1": {"1"}, "target": {"-1"}}, "target", "must be positive"},
+		{url.Values{"f": {"y"}, "x1": {"1"}, "param": {"y:1"}}, "params", `"y" is reserved`},
+		{url.Values{"f": {"y+"}, "x1": {"1"}}, "f", "can't parse f(x,y): unexpected end of formula, the operand is missing at position 3"},
+	}
+	for _, tt := range tbl {
+		resp, err := http.Get(adviseURL(ts.URL, tt.q))
+		require.NoError(t, err)
+		er := rest.ErrorResponse{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&er))
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, tt.msg)
+		assert.Contains(t, er.Errors, rest.FieldError{Field: tt.field, Msg: tt.msg})
+	}
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/go-chi/render"
+	"github.com/pkg/errors"
+)
+
+// defaultBatchWorkers is the default number of problems, solved concurrently in the batch
+const defaultBatchWorkers = 4
+
+// batchResp describes the results of solving the batch of problems
+type batchResp struct {
+	Results []solve.Item `json:"results"`
+	Took    string       `json:"took"`
+}
+
+// POST /api/v1/solve/batch - solve each problem in the list, the same as POST /api/v1/solve,
+// the failure of a particular problem is reported in its entry, results are in the order of the request
+func (s *Rest) batchSolveCtrl(w http.ResponseWriter, r *http.Request) {
+	var reqs []solve.Request
+	if err := render.DecodeJSON(r.Body, &reqs); err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "failed to decode request", rest.ErrDecode)
+		return
+	}
+
+	if maxBatch := s.limits().MaxBatch; len(reqs) > maxBatch {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest,
+			errors.Errorf("batch contains %d problems, more than max_batch=%d", len(reqs), maxBatch),
+			"batch is too large", rest.ErrBadRequest)
+		return
+	}
+
+	workers := s.BatchWorkers
+	if workers <= 0 {
+		workers = defaultBatchWorkers
+	}
+
+	st := time.Now()
+	resp := batchResp{Results: s.solveBatch(r.Context(), reqs, workers)}
+	resp.Took = time.Since(st).String()
+	rest.RenderJSON(w, r, resp)
+}
+
+// solveBatch solves problems concurrently by the given number of workers
+func (s *Rest) solveBatch(ctx context.Context, reqs []solve.Request, workers int) []solve.Item {
+	res := make([]solve.Item, len(reqs))
+	idxs := make(chan int)
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range idxs {
+				res[idx] = s.solveItem(ctx, reqs[idx])
+			}
+		}()
+	}
+
+	for i := range reqs {
+		idxs <- i
+	}
+	close(idxs)
+	wg.Wait()
+
+	return res
+}
+
+func (s *Rest) solveItem(ctx context.Context, req solve.Request) solve.Item {
+	st := time.Now()
+	p, err := s.prepare(req)
+	if err != nil {
+		be := rest.NewErrorResponse(err, "invalid solve request", rest.ErrBadRequest)
+		return solve.Item{Error: &be, Took: time.Since(st).String()}
+	}
+
+	if req.Save && s.Store == nil {
+		be := rest.NewErrorResponse(errors.New("results can't be saved"), "saving of results is disabled", rest.ErrBadRequest)
+		return solve.Item{Error: &be, Took: time.Since(st).String()}
+	}
+	if req.Warm {
+		be := rest.NewErrorResponse(errors.New("warm start is not supported in batches"), "invalid solve request",
+			rest.ErrBadRequest)
+		return solve.Item{Error: &be, Took: time.Since(st).String()}
+	}
+
+	resp, err := s.solve(ctx, p)
+	var busy *busyError
+	if errors.As(err, &busy) {
+		be := rest.NewErrorResponse(err, "too many solves at once", rest.ErrBusy)
+		return solve.Item{Error: &be, Took: time.Since(st).String()}
+	}
+	if err != nil {
+		be := rest.NewErrorResponse(err, "failed to solve", rest.ErrInternal)
+		return solve.Item{Error: &be, Took: time.Since(st).String()}
+	}
+
+	if req.Errors {
+		resp.Errors = resp.LocalErrors()
+	}
+	if req.Save {
+		if resp, err = s.saveResult(req, resp); err != nil {
+			be := rest.NewErrorResponse(err, "failed to save result", rest.ErrInternal)
+			return solve.Item{Error: &be, Took: time.Since(st).String()}
+		}
+	}
+	return solve.Item{Result: &resp, Took: time.Since(st).String()}
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRest_BatchSolve(t *testing.T) {
+	breakMethod(t)
+	srv, ts := prepTestServer(t)
+	srv.BatchWorkers = 3
+
+	var items []string
+	for i := 0; i < 10; i++ {
+		items = append(items, fmt.Sprintf(`{"f": "x**2 - 2*y", "x0": 0, "y0": %d, "x_end": 1, "n": %d, "methods": ["rk4"]}`, i, i+1))
+	}
+	items[4] = `{"f": "x +* y", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"]}`
+	items[7] = `{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["broken"]}`
+
+	resp, err := http.Post(ts.URL+"/api/v1/solve/batch", "application/json",
+		strings.NewReader("["+strings.Join(items, ",")+"]"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	res := batchResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.NotEmpty(t, res.Took)
+	require.Len(t, res.Results, 10)
+
+	for i, item := range res.Results {
+		assert.NotEmpty(t, item.Took)
+		switch i {
+		case 4:
+			require.NotNil(t, item.Error)
+			assert.Nil(t, item.Result)
+			assert.Equal(t, rest.ErrBadRequest, item.Error.Code)
+			require.Len(t, item.Error.Errors, 1)
+			assert.Equal(t, "f", item.Error.Errors[0].Field)
+		case 7:
+			require.NotNil(t, item.Error)
+			assert.Equal(t, rest.ErrInternal, item.Error.Code)
+		default:
+			require.Nil(t, item.Error, "item %d", i)
+			require.Len(t, item.Result.Lines, 1)
+			assert.InDelta(t, 1/float64(i+1), item.Result.Step, 1e-12, "results must be in order of requests")
+			assert.Equal(t, float64(i), item.Result.Lines[0].Points[0].Y, "results must be in order of requests")
+		}
+	}
+}
+
+func TestRest_BatchSolveLimit(t *testing.T) {
+	srv, ts := prepTestServer(t)
+	srv.Limits.MaxBatch = 2
+
+	item := `{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"]}`
+	resp, err := http.Post(ts.URL+"/api/v1/solve/batch", "application/json",
+		strings.NewReader("["+strings.Repeat(item+",", 2)+item+"]"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Post(ts.URL+"/api/v1/solve/batch", "application/json", strings.NewReader("["+item+","+item+"]"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Post(ts.URL+"/api/v1/solve/batch", "application/json", strings.NewReader(item))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "batch must be an array")
+}
+
+func TestRest_BatchSolveSave(t *testing.T) {
+	srv, ts := prepTestServer(t)
+
+	body := `[{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"], "save": true},
+		{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"]}]`
+	resp, err := http.Post(ts.URL+"/api/v1/solve/batch", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	res := batchResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Results, 2)
+	require.NotNil(t, res.Results[0].Result)
+	assert.NotEmpty(t, res.Results[0].Result.ID)
+	assert.Empty(t, res.Results[1].Result.ID)
+
+	saved, err := srv.loadResult(res.Results[0].Result.ID)
+	require.NoError(t, err)
+	assert.Equal(t, *res.Results[0].Result, saved.Result)
+
+	// saving fails the item, if it's disabled
+	srv.Store = nil
+	resp, err = http.Post(ts.URL+"/api/v1/solve/batch", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	res = batchResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Results, 2)
+	require.NotNil(t, res.Results[0].Error)
+	assert.Equal(t, rest.ErrBadRequest, res.Results[0].Error.Code)
+	assert.Nil(t, res.Results[1].Error)
+}
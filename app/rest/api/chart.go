@@ -0,0 +1,334 @@
+package api
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/expr"
+	"github.com/Semior001/decompract/app/num/field"
+	"github.com/Semior001/decompract/app/num/graph"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	log "github.com/go-pkgz/lgr"
+	"github.com/pkg/errors"
+)
+
+// maxFieldNodes is the maximal number of nodes of the slope field by each axis
+const maxFieldNodes = 100
+
+// limits of the size of the chart image in pixels
+const (
+	defaultChartWidth  = 800
+	defaultChartHeight = 600
+	maxChartSize       = 4000
+)
+
+// thinning of lines of the chart by curvature, the angle and the arc are measured in pixels of the image
+const (
+	chartThinAngle = 0.5 * math.Pi / 180 // the least angle between chords, that keeps the point
+	chartThinArc   = 8                   // the longest arc between kept points
+)
+
+// GET /api/chart/field?f=y-x&xmin=-1&xmax=1&ymin=-1&ymax=1&nx=20&ny=20&format=json|png
+// - returns the slope field of f(x,y) as the list of segments or renders it
+func (s *Rest) fieldCtrl(w http.ResponseWriter, r *http.Request) {
+	fxy, err := expr.Parse2(r.URL.Query().Get("f"), nil, "x", "y")
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "can't parse f(x,y)", rest.ErrBadRequest)
+		return
+	}
+
+	var xMin, xMax, yMin, yMax float64
+	var nx, ny int
+	for _, v := range []struct {
+		name string
+		dst  *float64
+		def  float64
+	}{{"xmin", &xMin, -1}, {"xmax", &xMax, 1}, {"ymin", &yMin, -1}, {"ymax", &yMax, 1}} {
+		if *v.dst, err = queryFloat(r, v.name, v.def); err != nil {
+			rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "can't read "+v.name, rest.ErrBadRequest)
+			return
+		}
+	}
+	if nx, err = queryInt(r, "nx", 20); err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "can't read nx", rest.ErrBadRequest)
+		return
+	}
+	if ny, err = queryInt(r, "ny", 20); err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "can't read ny", rest.ErrBadRequest)
+		return
+	}
+	if nx > maxFieldNodes || ny > maxFieldNodes {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, errors.Errorf("grid %dx%d is too large", nx, ny),
+			"max number of nodes by axis is "+strconv.Itoa(maxFieldNodes), rest.ErrBadRequest)
+		return
+	}
+
+	segs, err := field.SlopeField(fxy, xMin, xMax, yMin, yMax, nx, ny)
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "can't build slope field", rest.ErrBadRequest)
+		return
+	}
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		rest.RenderJSON(w, r, segs)
+	case "png":
+		img, err := s.NumService.Plotter.PlotField("Slope field", "X", "Y", segs, nil)
+		if err != nil {
+			rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "can't plot slope field", rest.ErrInternal)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		if _, err = w.Write(img); err != nil {
+			rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "can't write image", rest.ErrInternal)
+			return
+		}
+	default:
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, errors.Errorf("unknown format %q", format),
+			"format must be json or png", rest.ErrBadRequest)
+	}
+}
+
+// GET /api/v1/chart?f=x^2-2*y&x0=0&y0=1&x1=1&n=10&method=rk4&width=800&height=600&format=png|svg
+// - renders the chart of solutions, the parameters of the problem are the same as in the GET solve request,
+// errors=true adds the subplot of errors of methods against the exact solution, field=20 draws the slope field
+// of 20x20 nodes under solutions
+func (s *Rest) chartCtrl(w http.ResponseWriter, r *http.Request) {
+	req, ok := readGetSolve(w, r)
+	if !ok {
+		return
+	}
+
+	img, err := readChartImage(r)
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid chart parameters", rest.ErrBadRequest)
+		return
+	}
+
+	thin := true
+	if v := r.URL.Query().Get("thin"); v != "" {
+		if thin, err = strconv.ParseBool(v); err != nil {
+			rest.SendErrorJSON(w, r, http.StatusBadRequest, errors.Wrap(err, "thin is not a boolean"),
+				"invalid chart parameters", rest.ErrBadRequest)
+			return
+		}
+	}
+
+	fieldNodes, err := queryInt(r, "field", 0)
+	if err == nil && (fieldNodes < 0 || fieldNodes > maxFieldNodes) {
+		err = errors.Errorf("field must be between 0 and %d nodes by axis, got %d", maxFieldNodes, fieldNodes)
+	}
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid chart parameters", rest.ErrBadRequest)
+		return
+	}
+
+	// the chart is deterministic, so it is identified by the problem and the image parameters
+	etag := fmt.Sprintf(`"%x"`, sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%d|%s|%t|%t|%d",
+		req.CacheKey(s.limits()), img.Width, img.Height, img.Format, thin, req.Errors, fieldNodes))))
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	resp, ok := s.solveRequest(w, r, req)
+	if !ok {
+		return
+	}
+
+	lines := make([]num.Line, 0, len(resp.Lines)+1)
+	for _, line := range resp.Lines {
+		if line.Error == nil {
+			lines = append(lines, num.Line{Name: line.Name, Points: line.Points})
+		}
+	}
+	if resp.Exact != nil {
+		lines = append(lines, num.Line{Name: resp.Exact.Name, Points: resp.Exact.Points})
+	}
+	errLines := make([]num.Line, 0, len(resp.Errors))
+	for _, et := range resp.Errors {
+		errLines = append(errLines, num.Line{Name: et.Name, Points: et.Points})
+	}
+	if thin && s.ChartThinAbove > 0 {
+		dropped := thinLines(lines, img, s.ChartThinAbove) + thinLines(errLines, img, s.ChartThinAbove)
+		w.Header().Set("X-Dropped-Points", strconv.Itoa(dropped))
+	}
+
+	var segs []field.Segment
+	if fieldNodes > 0 {
+		if segs, err = chartField(req, lines, fieldNodes); err != nil {
+			rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "can't build slope field", rest.ErrInternal)
+			return
+		}
+	}
+
+	// the image is rendered into the buffer from the pool, the buffer is reset before the next use,
+	// so nothing is left from the previous chart
+	buf := rest.GetBuffer()
+	defer rest.PutBuffer(buf)
+	if req.Errors {
+		err = s.NumService.Plotter.WriteWithErrors(buf, "Solutions", "X", "Y", segs, lines, errLines, img)
+	} else {
+		err = s.NumService.Plotter.WriteFieldImage(buf, "Solutions", "X", "Y", segs, lines, img)
+	}
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "can't plot chart", rest.ErrInternal)
+		return
+	}
+
+	ct := "image/png"
+	if img.Format == "svg" {
+		ct = "image/svg+xml"
+	}
+	w.Header().Set("Content-Type", ct)
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Header().Set("ETag", etag)
+	if _, err = w.Write(buf.Bytes()); err != nil {
+		log.Printf("[WARN] failed to write chart, %v", err)
+	}
+}
+
+// GET /api/plot.png?f=x^2-2*y&x0=0&y0=1&x1=1&n=10&method=rk4&errors=true
+// - renders the chart of solutions to png, as GET /api/v1/chart with format=png does, to embed it into reports
+// or fetch it with curl by the url, that ends with the extension of the image
+func (s *Rest) plotPNGCtrl(w http.ResponseWriter, r *http.Request) {
+	// the raw query is kept as is, as formulas are read from it with unescaped plus signs
+	kvs := []string{"format=png"}
+	for _, kv := range strings.Split(r.URL.RawQuery, "&") {
+		if kv != "" && kv != "format" && !strings.HasPrefix(kv, "format=") {
+			kvs = append(kvs, kv)
+		}
+	}
+	u := *r.URL
+	u.RawQuery = strings.Join(kvs, "&")
+	r = r.WithContext(r.Context())
+	r.URL = &u
+	s.chartCtrl(w, r)
+}
+
+// chartField makes the slope field of f of the solved request with nodes by each axis, the field spans
+// the interval and the range of values of lines, so it lies under solutions
+func chartField(req solve.Request, lines []num.Line, nodes int) ([]field.Segment, error) {
+	req, err := req.WithPreset()
+	if err != nil {
+		return nil, err
+	}
+	if req.Linear != nil && strings.TrimSpace(req.F) == "" {
+		req.F = req.Linear.F()
+	}
+	fxy, err := expr.Parse2(req.F, req.Params, "x", "y")
+	if err != nil {
+		return nil, errors.Wrap(err, "can't parse f(x,y)")
+	}
+
+	xMin, xMax := math.Min(req.X0, req.XEnd), math.Max(req.X0, req.XEnd)
+	yMin, yMax := req.Y0, req.Y0
+	for _, line := range lines {
+		for _, pt := range line.Points {
+			if solve.IsFinite(pt.Y) {
+				yMin, yMax = math.Min(yMin, pt.Y), math.Max(yMax, pt.Y)
+			}
+		}
+	}
+	// the constant solution and the empty interval still have the field around them
+	if xMin == xMax {
+		xMin, xMax = xMin-1, xMax+1
+	}
+	if yMin == yMax {
+		yMin, yMax = yMin-1, yMax+1
+	}
+	return field.SlopeField(fxy, xMin, xMax, yMin, yMax, nodes, nodes)
+}
+
+// thinLines thins lines with more than above points by curvature in pixels of the image, as the chart
+// needs dense points only where lines bend, the number of dropped points is returned
+func thinLines(lines []num.Line, img graph.Image, above int) int {
+	xMin, xMax, yMin, yMax := math.Inf(1), math.Inf(-1), math.Inf(1), math.Inf(-1)
+	for _, line := range lines {
+		for _, p := range line.Points {
+			if solve.IsFinite(p.X) && solve.IsFinite(p.Y) {
+				xMin, xMax = math.Min(xMin, p.X), math.Max(xMax, p.X)
+				yMin, yMax = math.Min(yMin, p.Y), math.Max(yMax, p.Y)
+			}
+		}
+	}
+	scale := func(pixels int, min, max float64) float64 {
+		if !(max > min) || math.IsInf(max-min, 1) {
+			return 1
+		}
+		return float64(pixels) / (max - min)
+	}
+
+	dropped := 0
+	for i, line := range lines {
+		if len(line.Points) <= above {
+			continue
+		}
+		c := &solver.Collector{}
+		th := solver.NewCurvatureThinner(c, chartThinAngle, chartThinArc)
+		th.ScaleX, th.ScaleY = scale(img.Width, xMin, xMax), scale(img.Height, yMin, yMax)
+		for _, p := range line.Points {
+			_ = th.Draw(p) // the collector doesn't fail
+		}
+		_ = th.Flush()
+		lines[i].Points = c.Points
+		dropped += th.Dropped()
+	}
+	return dropped
+}
+
+// readChartImage reads and validates the size and the format of the chart
+func readChartImage(r *http.Request) (img graph.Image, err error) {
+	if img.Width, err = queryInt(r, "width", defaultChartWidth); err != nil {
+		return graph.Image{}, err
+	}
+	if img.Height, err = queryInt(r, "height", defaultChartHeight); err != nil {
+		return graph.Image{}, err
+	}
+	if img.Width < 1 || img.Width > maxChartSize || img.Height < 1 || img.Height > maxChartSize {
+		return graph.Image{}, errors.Errorf("size %dx%d must be between 1x1 and %dx%d",
+			img.Width, img.Height, maxChartSize, maxChartSize)
+	}
+
+	switch img.Format = r.URL.Query().Get("format"); img.Format {
+	case "":
+		img.Format = "png"
+	case "png", "svg":
+	default:
+		return graph.Image{}, errors.Errorf("unknown format %q, must be png or svg", img.Format)
+	}
+	return img, nil
+}
+
+// queryFloat reads the float value of the query parameter, def is returned if it is not set
+func queryFloat(r *http.Request, name string, def float64) (float64, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+	res, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "%s is not a number", name)
+	}
+	return res, nil
+}
+
+// queryInt reads the integer value of the query parameter, def is returned if it is not set
+func queryInt(r *http.Request, name string, def int) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+	res, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, errors.Wrapf(err, "%s is not an integer", name)
+	}
+	return res, nil
+}
@@ -0,0 +1,352 @@
+package api
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/graph"
+	"github.com/Semior001/decompract/app/num/service"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/Semior001/decompract/app/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chartQuery is the problem with the exact solution, solved by two methods
+var chartQuery = url.Values{
+	"f":      {"y*y*exp(x) - 2*y"},
+	"exact":  {"exp(-x) / (c*exp(x) + 1)"},
+	"c":      {"(exp(-x0) - y0) / (y0 * exp(x0))"},
+	"x0":     {"0"},
+	"y0":     {"1"},
+	"x1":     {"1"},
+	"n":      {"10"},
+	"method": {"rk4", "euler"},
+}
+
+func chartURL(ts string, params ...string) string {
+	return ts + "/api/v1/chart?" + strings.ReplaceAll(chartQuery.Encode(), "+", "%20") + "&" + strings.Join(params, "&")
+}
+
+// svgTexts returns the contents of all text elements of the svg document
+func svgTexts(t *testing.T, r io.Reader) []string {
+	var res []string
+	dec := xml.NewDecoder(r)
+	inText := false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return res
+		}
+		require.NoError(t, err)
+		switch tt := tok.(type) {
+		case xml.StartElement:
+			inText = tt.Name.Local == "text"
+		case xml.EndElement:
+			inText = false
+		case xml.CharData:
+			if inText {
+				res = append(res, strings.TrimSpace(string(tt)))
+			}
+		}
+	}
+}
+
+func TestRest_Chart(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	resp, err := http.Get(chartURL(ts.URL, "width=640", "height=480"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "public, max-age=3600", resp.Header.Get("Cache-Control"))
+	etag := resp.Header.Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	img, err := png.Decode(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, 640, img.Bounds().Dx())
+	assert.Equal(t, 480, img.Bounds().Dy())
+
+	// the same chart is not rendered again
+	req, err := http.NewRequest(http.MethodGet, chartURL(ts.URL, "width=640", "height=480"), nil)
+	require.NoError(t, err)
+	req.Header.Set("If-None-Match", etag)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+	resp, err = http.Get(chartURL(ts.URL, "format=svg"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/svg+xml", resp.Header.Get("Content-Type"))
+	assert.NotEqual(t, etag, resp.Header.Get("ETag"), "format must change the etag")
+
+	series := 0
+	for _, text := range svgTexts(t, resp.Body) {
+		switch text {
+		case "Runge-Kutta's method", "Euler's method", "Exact solution":
+			series++
+		}
+	}
+	assert.Equal(t, 3, series, "legend must contain both methods and the exact solution")
+}
+
+func TestRest_ChartThin(t *testing.T) {
+	srv, ts := prepTestServer(t)
+	u := ts.URL + "/api/v1/chart?f=x&x0=0&y0=1&x1=1&n=1000&method=rk4"
+
+	resp, err := http.Get(u)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("X-Dropped-Points"), "thinning is disabled by default")
+
+	srv.ChartThinAbove = 5
+	resp, err = http.Get(u)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	dropped, err := strconv.Atoi(resp.Header.Get("X-Dropped-Points"))
+	require.NoError(t, err)
+	assert.Greater(t, dropped, 0)
+	etag := resp.Header.Get("ETag")
+
+	resp, err = http.Get(u + "&thin=false")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("X-Dropped-Points"))
+	assert.NotEqual(t, etag, resp.Header.Get("ETag"), "thin must change the etag")
+}
+
+func TestRest_ChartWithErrors(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	resp, err := http.Get(chartURL(ts.URL, "width=640", "height=480"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	etag := resp.Header.Get("ETag")
+
+	resp, err = http.Get(chartURL(ts.URL, "width=640", "height=480", "errors=true"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEqual(t, etag, resp.Header.Get("ETag"), "errors must change the etag")
+	img, err := png.Decode(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, 640, img.Bounds().Dx())
+	assert.Equal(t, 480, img.Bounds().Dy())
+
+	resp, err = http.Get(chartURL(ts.URL, "format=svg", "errors=true"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	texts := svgTexts(t, resp.Body)
+	assert.Contains(t, texts, "|Y - exact|")
+	assert.Contains(t, texts, "Solutions")
+
+	// there are no errors without the exact solution
+	resp, err = http.Get(ts.URL + "/api/v1/chart?f=x&x0=0&y0=1&x1=1&n=10&method=rk4&errors=true")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestRest_PlotPNG(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	query := strings.ReplaceAll(chartQuery.Encode(), "+", "%20")
+	resp, err := http.Get(ts.URL + "/api/plot.png?" + query + "&width=640&height=480&errors=true&format=svg")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/png", resp.Header.Get("Content-Type"), "the plot is png, whatever the format is")
+	img, err := png.Decode(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, 640, img.Bounds().Dx())
+	assert.Equal(t, 480, img.Bounds().Dy())
+
+	// the plot is the chart in png
+	chart, err := http.Get(chartURL(ts.URL, "width=640", "height=480", "errors=true"))
+	require.NoError(t, err)
+	defer chart.Body.Close()
+	assert.Equal(t, chart.Header.Get("ETag"), resp.Header.Get("ETag"))
+
+	resp, err = http.Get(ts.URL + "/api/plot.png?f=x&x0=0&y0=1&x1=1&n=10&method=rk4&errors=true")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestRest_ChartField(t *testing.T) {
+	_, ts := prepTestServer(t)
+	get := func(params ...string) (body, etag string) {
+		resp, err := http.Get(chartURL(ts.URL, params...))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(b), resp.Header.Get("ETag")
+	}
+
+	plain, etag := get("format=svg")
+	withField, fieldEtag := get("format=svg", "field=10")
+	assert.NotEqual(t, etag, fieldEtag, "field must change the etag")
+	// each segment of the field is the path of its own
+	assert.GreaterOrEqual(t, strings.Count(withField, "<path"), strings.Count(plain, "<path")+100)
+
+	withErrors, _ := get("format=svg", "field=10", "errors=true")
+	assert.GreaterOrEqual(t, strings.Count(withErrors, "<path"), strings.Count(plain, "<path")+100)
+
+	_, _ = get("field=0")
+}
+
+func TestChartField(t *testing.T) {
+	lines := []num.Line{{Points: []num.Point{{X: 0, Y: 1}, {X: 0.5, Y: 3}, {X: 1, Y: math.Inf(1)}}},
+		{Points: []num.Point{{X: 0, Y: 1}, {X: 1, Y: -2}}}}
+	segs, err := chartField(solve.Request{F: "k*y", Params: map[string]float64{"k": 2}, X0: 0, Y0: 1, XEnd: 1}, lines, 3)
+	require.NoError(t, err)
+	require.Len(t, segs, 9)
+	assert.Equal(t, num.Point{X: 0, Y: -2}, segs[0].Center)
+	assert.Equal(t, num.Point{X: 1, Y: 3}, segs[8].Center)
+	assert.InDelta(t, math.Atan(-4), segs[0].Angle, 1e-12)
+
+	// f of the linear equation and of the preset
+	segs, err = chartField(solve.Request{Linear: &solve.LinearForm{P: "1", Q: "x"}, X0: 0, Y0: 1, XEnd: 1}, nil, 2)
+	require.NoError(t, err)
+	require.Len(t, segs, 4)
+	assert.Equal(t, num.Point{X: 0, Y: 0}, segs[0].Center, "the constant solution has the field around it")
+	assert.InDelta(t, math.Atan(0), segs[0].Angle, 1e-12)
+	_, err = chartField(solve.Request{Preset: "logistic", N: 10}, nil, 2)
+	require.NoError(t, err)
+}
+
+func TestThinLines(t *testing.T) {
+	straight, short := num.Line{Name: "straight"}, num.Line{Name: "short"}
+	for i := 0; i <= 2000; i++ {
+		x := float64(i) / 2000
+		straight.Points = append(straight.Points, num.Point{X: x, Y: x})
+	}
+	for i := 0; i <= 10; i++ {
+		short.Points = append(short.Points, num.Point{X: float64(i) / 10, Y: math.NaN()})
+	}
+	lines := []num.Line{straight, short}
+
+	dropped := thinLines(lines, graph.Image{Width: 800, Height: 600}, 100)
+	// points of the diagonal of 1000 pixels are kept at most 8 pixels apart
+	assert.GreaterOrEqual(t, len(lines[0].Points), 1000/chartThinArc)
+	assert.Less(t, len(lines[0].Points), 1000/(chartThinArc-1))
+	assert.Equal(t, 2001, len(lines[0].Points)+dropped)
+	assert.Equal(t, num.Point{X: 1, Y: 1}, lines[0].Points[len(lines[0].Points)-1])
+	assert.Len(t, lines[1].Points, 11, "short lines are not thinned")
+}
+
+func TestRest_ChartPooled(t *testing.T) {
+	_, ts := prepTestServer(t)
+	get := func(params ...string) []byte {
+		resp, err := http.Get(chartURL(ts.URL, params...))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return b
+	}
+
+	// charts are rendered back to back with buffers of each other, the larger one goes first,
+	// so the smaller one is drawn on its pixels
+	large := []string{"width=900", "height=700", "method=ieuler"}
+	small := []string{"width=640", "height=480"}
+	first := map[string][]byte{"large": get(large...), "small": get(small...)}
+	second := map[string][]byte{"large": get(large...), "small": get(small...)}
+	assert.Equal(t, first["small"], second["small"], "the chart doesn't depend on the previous one")
+	assert.Equal(t, first["large"], second["large"], "the chart doesn't depend on the previous one")
+	assert.NotEqual(t, first["small"], first["large"])
+
+	img, err := png.Decode(bytes.NewReader(second["small"]))
+	require.NoError(t, err)
+	assert.Equal(t, 640, img.Bounds().Dx())
+	r, g, b, _ := img.At(639, 479).RGBA()
+	assert.Equal(t, [3]uint32{0xffff, 0xffff, 0xffff}, [3]uint32{r, g, b}, "the corner is the background")
+}
+
+func TestRest_ChartErrors(t *testing.T) {
+	srv, ts := prepTestServer(t)
+	srv.Limits.MaxSteps = 100
+
+	tbl := []struct {
+		name string
+		url  string
+	}{
+		{"too wide", chartURL(ts.URL, "width=4001")},
+		{"too high", chartURL(ts.URL, "height=10000")},
+		{"zero size", chartURL(ts.URL, "width=0")},
+		{"bad width", chartURL(ts.URL, "width=wide")},
+		{"unknown format", chartURL(ts.URL, "format=gif")},
+		{"bad thin", chartURL(ts.URL, "thin=maybe")},
+		{"bad errors", chartURL(ts.URL, "errors=maybe")},
+		{"bad field", chartURL(ts.URL, "field=many")},
+		{"too large field", chartURL(ts.URL, "field=101")},
+		{"negative field", chartURL(ts.URL, "field=-1")},
+		{"too many steps", ts.URL + "/api/v1/chart?f=x&x0=0&y0=1&x1=1&n=101&method=rk4"},
+		{"unknown method", ts.URL + "/api/v1/chart?f=x&x0=0&y0=1&x1=1&n=10&method=rk5"},
+	}
+
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(tt.url)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			b, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, string(b))
+			assert.Contains(t, resp.Header.Get("Content-Type"), "application/json")
+			assert.Empty(t, resp.Header.Get("ETag"))
+		})
+	}
+}
+
+// discardResponse is the response writer, that drops the body, so benchmarks measure the handler only
+type discardResponse struct {
+	header http.Header
+	status int
+}
+
+func (d *discardResponse) Header() http.Header         { return d.header }
+func (d *discardResponse) Write(b []byte) (int, error) { return len(b), nil }
+func (d *discardResponse) WriteHeader(status int)      { d.status = status }
+
+func BenchmarkRest_Chart(b *testing.B) {
+	srv := &Rest{Version: "test", NumService: &service.Service{}, Store: &store.Memory{}}
+	h := srv.routes()
+	for _, format := range []string{"png", "svg"} {
+		b.Run(format, func(b *testing.B) {
+			req := httptest.NewRequest(http.MethodGet, chartURL("", "format="+format), nil)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				w := &discardResponse{header: http.Header{}}
+				h.ServeHTTP(w, req)
+				if w.status != 0 && w.status != http.StatusOK {
+					b.Fatalf("unexpected status %d", w.status)
+				}
+			}
+		})
+	}
+}
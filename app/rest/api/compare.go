@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"github.com/Semior001/decompract/app/report"
+	"github.com/Semior001/decompract/app/solve"
+	"net/http"
+
+	"github.com/Semior001/decompract/app/num/graph"
+	"github.com/Semior001/decompract/app/rest"
+	log "github.com/go-pkgz/lgr"
+	"github.com/pkg/errors"
+)
+
+// GET /api/v1/compare?f=y&exact=c*exp(x)&c=y0/exp(x0)&x0=0&y0=1&x1=1&n=10&format=json|csv|png|svg - solve
+// the problem with each of requested or all methods and compare their errors and costs, png and svg formats
+// render the chart of errors with the width and the height as in the chart request
+func (s *Rest) compareCtrl(w http.ResponseWriter, r *http.Request) {
+	req, ok := readGetSolve(w, r)
+	if !ok {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	var img graph.Image
+	switch format {
+	case "", "json", "csv":
+	case "png", "svg":
+		var err error
+		if img, err = readChartImage(r); err != nil {
+			rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid chart parameters", rest.ErrBadRequest)
+			return
+		}
+	default:
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, errors.Errorf("unknown format %q", format),
+			"format must be json, csv, png or svg", rest.ErrBadRequest)
+		return
+	}
+
+	cmp, err := solve.PrepareComparison(req, s.limits())
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid compare request", rest.ErrBadRequest)
+		return
+	}
+
+	resp, err := s.runComparison(r.Context(), cmp)
+	if err != nil {
+		var te *solve.TimeoutError
+		if errors.As(err, &te) {
+			s.sendTimeout(w, r, te)
+			return
+		}
+		var be *busyError
+		if errors.As(err, &be) {
+			sendBusy(w, r, be)
+			return
+		}
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "failed to compare methods", rest.ErrInternal)
+		return
+	}
+
+	// errors and evaluations are deterministic, as solutions are, the time is not, but it is informational
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	switch format {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		header, rows := resp.Table(0)
+		if err = report.WriteCSV(w, header, rows); err != nil {
+			log.Printf("[WARN] failed to write csv response, %v", err)
+		}
+	case "png", "svg":
+		b, err := resp.Chart(s.NumService.Plotter, img)
+		if err != nil {
+			rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "can't plot chart", rest.ErrInternal)
+			return
+		}
+		ct := "image/png"
+		if img.Format == "svg" {
+			ct = "image/svg+xml"
+		}
+		w.Header().Set("Content-Type", ct)
+		if _, err = w.Write(b); err != nil {
+			log.Printf("[WARN] failed to write chart, %v", err)
+		}
+	default:
+		rest.RenderJSON(w, r, resp)
+	}
+}
+
+// runComparison runs the comparison, holding the slots of its methods
+func (s *Rest) runComparison(ctx context.Context, cmp solve.PreparedComparison) (solve.CompareResp, error) {
+	release, err := s.acquire(cmp.Problem)
+	if err != nil {
+		return solve.CompareResp{}, err
+	}
+	defer release()
+	return cmp.Run(ctx)
+}
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compareURL(ts string, q url.Values) string {
+	return ts + "/api/v1/compare?" + strings.ReplaceAll(q.Encode(), "+", "%20")
+}
+
+func TestRest_Compare(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	q := url.Values{"f": {"y"}, "exact": {"c*exp(x)"}, "c": {"y0/exp(x0)"}, "x0": {"0"}, "y0": {"1"}, "x1": {"1"},
+		"n": {"10"}}
+	resp, err := http.Get(compareURL(ts.URL, q))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "public, max-age=3600", resp.Header.Get("Cache-Control"))
+
+	res := solve.CompareResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.Equal(t, "exact", res.Reference)
+	assert.InDelta(t, 0.1, res.Step, 1e-12)
+	assert.NotEmpty(t, res.Took)
+	require.Len(t, res.Rows, len(solver.Methods()), "all methods are compared by default")
+	byMethod := map[string]solve.CompareRow{}
+	for _, row := range res.Rows {
+		byMethod[row.Method] = row
+		assert.NotEmpty(t, row.Name)
+		assert.Nil(t, row.Error, row.Method)
+		assert.NotEmpty(t, row.Took)
+		assert.Greater(t, row.MaxGTE, 0.0)
+		assert.Greater(t, row.MaxGTE, row.L2, "the interval is shorter than 1")
+		assert.Greater(t, row.Mean, 0.0)
+		assert.LessOrEqual(t, row.Mean, row.RMS, "the mean doesn't exceed the rms")
+		assert.LessOrEqual(t, row.RMS, row.MaxGTE, "and the rms doesn't exceed the max")
+	}
+	for _, pair := range [][2]string{{"euler", "ieuler"}, {"ieuler", "rk4"}} {
+		lower, higher := byMethod[pair[0]], byMethod[pair[1]]
+		assert.Less(t, higher.MaxGTE, lower.MaxGTE, "higher order methods are more accurate")
+		assert.Greater(t, higher.Evals, lower.Evals, "and more expensive")
+	}
+	// e - (1.1)^10 for euler
+	assert.InDelta(t, 0.124539, byMethod["euler"].MaxGTE, 1e-6)
+
+	// the same report in csv
+	q.Set("format", "csv")
+	q.Set("method", "rk4")
+	resp, err = http.Get(compareURL(ts.URL, q))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/csv; charset=utf-8", resp.Header.Get("Content-Type"))
+	rows, err := csv.NewReader(resp.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, []string{"method", "max_gte", "mean", "rms", "l2", "evals", "took"}, rows[0])
+	assert.Equal(t, "rk4", rows[1][0])
+
+	// the chart of errors
+	q.Set("format", "png")
+	q.Set("width", "320")
+	q.Set("height", "200")
+	resp, err = http.Get(compareURL(ts.URL, q))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+	cfg, err := png.DecodeConfig(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, 320, cfg.Width)
+	assert.Equal(t, 200, cfg.Height)
+}
+
+func TestRest_CompareReference(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	q := url.Values{"f": {"y"}, "x0": {"0"}, "y0": {"1"}, "x1": {"1"}, "step": {"0.1"}, "method": {"euler"}}
+	resp, err := http.Get(compareURL(ts.URL, q))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := solve.CompareResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.Equal(t, "rk4", res.Reference)
+	require.Len(t, res.Rows, 1)
+	assert.InDelta(t, 0.124539, res.Rows[0].MaxGTE, 1e-5, "the reference is close to the exact solution")
+}
+
+func TestRest_CompareNByMethod(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	compare := func(q url.Values) solve.CompareResp {
+		resp, err := http.Get(compareURL(ts.URL, q))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		res := solve.CompareResp{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+		return res
+	}
+
+	q := url.Values{"f": {"y"}, "exact": {"c*exp(x)"}, "c": {"y0/exp(x0)"}, "x0": {"0"}, "y0": {"1"}, "x1": {"1"},
+		"method": {"euler", "rk4"}, "n_by_method": {"euler:40", "rk4:10"}}
+	res := compare(q)
+	assert.Equal(t, "exact", res.Reference)
+	assert.True(t, res.GridsDiffer)
+	assert.InDelta(t, 0.025, res.Step, 1e-12, "the least step")
+	require.Len(t, res.Rows, 2)
+
+	// each row is the same as the comparison of the method alone with its n
+	for i, m := range []struct {
+		method, n string
+		step      float64
+	}{{"euler", "40", 0.025}, {"rk4", "10", 0.1}} {
+		assert.Equal(t, m.method, res.Rows[i].Method)
+		assert.InDelta(t, m.step, res.Rows[i].Step, 1e-12)
+		alone := url.Values{"f": q["f"], "exact": q["exact"], "c": q["c"], "x0": q["x0"], "y0": q["y0"], "x1": q["x1"],
+			"n": {m.n}, "method": {m.method}}
+		single := compare(alone)
+		assert.False(t, single.GridsDiffer)
+		assert.Zero(t, single.Rows[0].Step, "rows of the single grid have the step of the response")
+		assert.Equal(t, single.Rows[0].MaxGTE, res.Rows[i].MaxGTE, m.method)
+		assert.Equal(t, single.Rows[0].Evals, res.Rows[i].Evals, m.method)
+	}
+
+	// without the exact solution rows are measured against the reference, refined from the largest n
+	q.Del("exact")
+	q.Del("c")
+	ref := compare(q)
+	assert.Equal(t, "rk4", ref.Reference)
+	require.Len(t, ref.Rows, 2)
+	for i := range ref.Rows {
+		assert.InDelta(t, res.Rows[i].MaxGTE, ref.Rows[i].MaxGTE, 1e-6, ref.Rows[i].Method)
+	}
+}
+
+func TestRest_CompareErrors(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	base := url.Values{"f": {"y"}, "x0": {"0"}, "y0": {"1"}, "x1": {"1"}, "n": {"10"}}
+	tbl := []struct {
+		params url.Values
+		field  string
+		msg    string
+	}{
+		{url.Values{"method": {"exact"}, "exact": {"c*exp(x)"}, "c": {"y0"}}, "methods",
+			"exact solution is the reference, it has no errors"},
+		{url.Values{"x1": {"0"}}, "x_end", "must differ from x0, the interval of zero length can't be split into n steps"},
+		{url.Values{"method": {"rk5"}}, "methods", `unknown method "rk5"`},
+		{url.Values{"n": {"0"}}, "n", "either n or step must be set"},
+	}
+	for _, tt := range tbl {
+		q := url.Values{}
+		for k, v := range base {
+			q[k] = v
+		}
+		for k, v := range tt.params {
+			q[k] = v
+		}
+		resp, err := http.Get(compareURL(ts.URL, q))
+		require.NoError(t, err)
+		er := rest.ErrorResponse{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&er))
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, tt.msg)
+		assert.Contains(t, er.Errors, rest.FieldError{Field: tt.field, Msg: tt.msg})
+	}
+
+	for _, p := range []url.Values{{"format": {"xml"}}, {"format": {"png"}, "width": {"0"}}} {
+		q := url.Values{}
+		for k, v := range base {
+			q[k] = v
+		}
+		for k, v := range p {
+			q[k] = v
+		}
+		resp, err := http.Get(compareURL(ts.URL, q))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, p)
+	}
+}
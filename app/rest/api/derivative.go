@@ -0,0 +1,143 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Semior001/decompract/app/num/expr"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/pkg/errors"
+)
+
+// derivativeReq is the point, at which f(x,y) and its partial derivatives are evaluated
+type derivativeReq struct {
+	F      string
+	Params map[string]float64
+	X, Y   float64
+}
+
+// derivativeResp is the value of f(x,y) and its partial derivatives at the point
+type derivativeResp struct {
+	F    float64 `json:"f"`
+	DFDX float64 `json:"dfdx"`
+	DFDY float64 `json:"dfdy"`
+	Took string  `json:"took"`
+}
+
+// derivative is the validated derivative request, f and its partial derivatives are compiled
+type derivative struct {
+	req           derivativeReq
+	f, dfdx, dfdy func(x, y float64) (float64, error)
+}
+
+// prepare validates the request, in case of invalid request, rest.ValidationError with all invalid fields is returned
+func (req derivativeReq) prepare() (derivative, error) {
+	res := derivative{req: req}
+	var errs rest.ValidationError
+	invalid := func(field, msg string, args ...interface{}) {
+		errs = append(errs, rest.FieldError{Field: field, Msg: fmt.Sprintf(msg, args...)})
+	}
+
+	if !solve.IsFinite(req.X) {
+		invalid("x", "must be finite")
+	}
+	if !solve.IsFinite(req.Y) {
+		invalid("y", "must be finite")
+	}
+	for _, name := range solve.ParamNames(req.Params) {
+		switch {
+		case !solve.ParamName.MatchString(name):
+			invalid("params", "%q is not a valid name", name)
+		case solve.IsReserved(name):
+			invalid("params", "%q is reserved", name)
+		case !solve.IsFinite(req.Params[name]):
+			invalid("params", "%s must be finite", name)
+		}
+	}
+
+	var err error
+	if res.f, err = expr.Parse2(req.F, req.Params, "x", "y"); err != nil {
+		invalid("f", "can't parse f(x,y): %v", err)
+	} else {
+		// the formula is valid, so are its derivatives
+		res.dfdx, _ = expr.Derivative2(req.F, req.Params, "x", "x", "y")
+		res.dfdy, _ = expr.Derivative2(req.F, req.Params, "y", "x", "y")
+	}
+
+	if len(errs) > 0 {
+		return derivative{}, errs
+	}
+	return res, nil
+}
+
+// calculate evaluates f and its partial derivatives at the point
+func (d derivative) calculate() (derivativeResp, error) {
+	st := time.Now()
+	resp := derivativeResp{}
+	x, y := d.req.X, d.req.Y
+	var err error
+	if resp.F, err = d.f(x, y); err != nil {
+		return derivativeResp{}, err
+	}
+	if resp.DFDX, err = d.dfdx(x, y); err != nil {
+		return derivativeResp{}, err
+	}
+	if resp.DFDY, err = d.dfdy(x, y); err != nil {
+		return derivativeResp{}, err
+	}
+	if !solve.IsFinite(resp.F) || !solve.IsFinite(resp.DFDX) || !solve.IsFinite(resp.DFDY) {
+		return derivativeResp{}, rest.ValidationError{{Field: "x", Msg: fmt.Sprintf("(%v, %v) is outside "+
+			"the domain of f or of its derivatives, f = %v, df/dx = %v, df/dy = %v", x, y, resp.F, resp.DFDX, resp.DFDY)}}
+	}
+	resp.Took = time.Since(st).String()
+	return resp, nil
+}
+
+// readDerivativeQuery reads the derivative request from query parameters
+func readDerivativeQuery(r *http.Request) (req derivativeReq, err error) {
+	if req.F, err = queryFormula(r, "f"); err != nil {
+		return derivativeReq{}, err
+	}
+	if req.Params, err = queryParams(r); err != nil {
+		return derivativeReq{}, err
+	}
+	if req.X, err = queryFloat(r, "x", 0); err != nil {
+		return derivativeReq{}, err
+	}
+	if req.Y, err = queryFloat(r, "y", 0); err != nil {
+		return derivativeReq{}, err
+	}
+	return req, nil
+}
+
+// GET /api/v1/derivative?f=x^2-2*y&x=1&y=2 - evaluate f(x,y) and its partial derivatives df/dx and df/dy
+// at the point, derivatives are found by the automatic differentiation of the formula, exactly up to the round-off
+func (s *Rest) derivativeCtrl(w http.ResponseWriter, r *http.Request) {
+	req, err := readDerivativeQuery(r)
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "failed to read query parameters", rest.ErrDecode)
+		return
+	}
+
+	d, err := req.prepare()
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid derivative request", rest.ErrBadRequest)
+		return
+	}
+
+	resp, err := d.calculate()
+	if err != nil {
+		var ve rest.ValidationError
+		if errors.As(err, &ve) {
+			rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid derivative request", rest.ErrBadRequest)
+			return
+		}
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, errors.Wrap(err, "failed to differentiate"),
+			"derivatives are not calculated", rest.ErrInternal)
+		return
+	}
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	rest.RenderJSON(w, r, resp)
+}
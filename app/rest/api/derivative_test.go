@@ -0,0 +1,58 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func derivativeURL(ts string, q url.Values) string {
+	return ts + "/api/v1/derivative?" + strings.ReplaceAll(q.Encode(), "+", "%20")
+}
+
+func TestRest_Derivative(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	q := url.Values{"f": {"k*x^2*y - sin(y)"}, "x": {"2"}, "y": {"0"}, "param": {"k:3"}}
+	resp, err := http.Get(derivativeURL(ts.URL, q))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := derivativeResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.Equal(t, 0.0, res.F)
+	assert.Equal(t, 0.0, res.DFDX)
+	assert.Equal(t, 11.0, res.DFDY)
+	assert.NotEmpty(t, res.Took)
+}
+
+func TestRest_DerivativeInvalid(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	tbl := []struct {
+		q     url.Values
+		field string
+		msg   string
+	}{
+		{url.Values{"f": {"x"}, "x": {"Inf"}}, "x", "must be finite"},
+		{url.Values{"f": {"x"}, "param": {"y:1"}}, "params", `"y" is reserved`},
+		{url.Values{"f": {"z"}}, "f", `can't parse f(x,y): unknown variable "z", available: x, y at position 1`},
+		{url.Values{"f": {"sqrt(y)"}, "x": {"1"}}, "x",
+			"(1, 0) is outside the domain of f or of its derivatives, f = 0, df/dx = 0, df/dy = +Inf"},
+	}
+	for _, tt := range tbl {
+		resp, err := http.Get(derivativeURL(ts.URL, tt.q))
+		require.NoError(t, err)
+		er := rest.ErrorResponse{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&er))
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, tt.msg)
+		assert.Contains(t, er.Errors, rest.FieldError{Field: tt.field, Msg: tt.msg})
+	}
+}
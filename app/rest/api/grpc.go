@@ -0,0 +1,279 @@
+package api
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/rest/api/pb"
+	"github.com/Semior001/decompract/app/solve"
+	log "github.com/go-pkgz/lgr"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/duration"
+	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// grpcSolver serves the solver over grpc, problems are prepared and solved as the rest api does,
+// under the same limits, keys and solve timeout
+type grpcSolver struct {
+	pb.UnimplementedSolverServer
+	s *Rest
+}
+
+// makeGRPCServer makes the grpc server of the solver, calls are drained on shutdown along with http requests
+// and require the api key in the "authorization: bearer" metadata, if keys are set, the server uses tls,
+// if https is served, routes must be made before the server, as they set up limits and the drainer
+func (s *Rest) makeGRPCServer() *grpc.Server {
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(s.grpcUnaryInterceptor),
+		grpc.ChainStreamInterceptor(s.grpcStreamInterceptor),
+	}
+	if s.TLS != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(s.TLS.Config.Clone())))
+	}
+	srv := grpc.NewServer(opts...)
+	pb.RegisterSolverServer(srv, &grpcSolver{s: s})
+	return srv
+}
+
+// serveGRPC serves grpc calls on the listener, until the server is stopped
+func (s *Rest) serveGRPC(srv *grpc.Server, ln net.Listener) {
+	log.Printf("[INFO] started grpc server at port %d", ln.Addr().(*net.TCPAddr).Port)
+	if err := srv.Serve(ln); err != nil {
+		log.Printf("[WARN] grpc server terminated reason: %s", err)
+	}
+}
+
+// grpcUnaryInterceptor checks the key of the call and makes its context cancellable by the drainer
+func (s *Rest) grpcUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	if err := s.checkGRPCKey(ctx); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer s.drain.remove(s.drain.add(cancel))
+	return handler(ctx, req)
+}
+
+// grpcStreamInterceptor checks the key of the stream and makes its context cancellable by the drainer
+func (s *Rest) grpcStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	if err := s.checkGRPCKey(ss.Context()); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ss.Context())
+	defer s.drain.remove(s.drain.add(cancel))
+	return handler(srv, ctxServerStream{ServerStream: ss, ctx: ctx})
+}
+
+// ctxServerStream replaces the context of the stream
+type ctxServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the replaced context of the stream
+func (cs ctxServerStream) Context() context.Context { return cs.ctx }
+
+// checkGRPCKey checks the key of the call, as the rest api does, calls without the key are unauthenticated
+// and calls with the unknown key are denied, all calls are allowed, if there are no keys
+func (s *Rest) checkGRPCKey(ctx context.Context) error {
+	if len(s.APIKeys) == 0 {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	auth := md.Get("authorization")
+	const prefix = "bearer "
+	if len(auth) == 0 || len(auth[0]) <= len(prefix) || !strings.EqualFold(auth[0][:len(prefix)], prefix) {
+		return status.Error(codes.Unauthenticated, "api key is required as authorization: bearer metadata")
+	}
+	hash := rest.HashAPIKey(strings.TrimSpace(auth[0][len(prefix):]))
+	for _, k := range s.APIKeys {
+		if strings.EqualFold(k.Hash, hash) {
+			return nil
+		}
+	}
+	return status.Error(codes.PermissionDenied, "api key is not accepted")
+}
+
+// Solve solves the problem with the requested methods, as POST /api/v1/solve does
+func (g *grpcSolver) Solve(ctx context.Context, in *pb.SolveRequest) (*pb.SolveResponse, error) {
+	p, err := g.prepare(in)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, g.s.solveTimeout())
+	defer cancel()
+	resp, err := g.s.solve(ctx, p)
+	if err != nil {
+		return nil, grpcSolveError(err)
+	}
+
+	res := &pb.SolveResponse{Step: resp.Step, GridsDiffer: resp.GridsDiffer, Took: durationProto(resp.Took)}
+	for _, line := range resp.Lines {
+		res.Lines = append(res.Lines, pbLine(line))
+	}
+	if resp.Exact != nil {
+		res.Exact = pbLine(*resp.Exact)
+	}
+	return res, nil
+}
+
+// SolveStream streams points of the problem, as GET /api/v1/solve/stream does, the stream is not limited
+// by the solve timeout
+func (g *grpcSolver) SolveStream(in *pb.SolveRequest, stream pb.Solver_SolveStreamServer) error {
+	p, err := g.prepare(in)
+	if err != nil {
+		return err
+	}
+
+	release, err := g.s.acquire(p)
+	if err != nil {
+		return grpcSolveError(err)
+	}
+	defer release()
+
+	summary, err := p.Stream(stream.Context(), grpcEventWriter{stream: stream})
+	if err != nil {
+		if stream.Context().Err() != nil && g.s.drain.shuttingDown() {
+			return status.Error(codes.Unavailable, shutdownMsg)
+		}
+		return grpcSolveError(err)
+	}
+
+	done := &pb.StreamSummary{Took: durationProto(summary.Took)}
+	for _, l := range summary.Lines {
+		done.Lines = append(done.Lines, &pb.LineSummary{Method: l.Method, Name: l.Name, Points: int32(l.Points),
+			Took: durationProto(l.Took)})
+	}
+	return stream.Send(&pb.SolveEvent{Event: &pb.SolveEvent_Done{Done: done}})
+}
+
+// prepare converts the call to the solve request and prepares its problem, the invalid request
+// is reported with fields, that violate the request
+func (g *grpcSolver) prepare(in *pb.SolveRequest) (solve.Prepared, error) {
+	req := solve.Request{
+		F:       in.F,
+		Exact:   in.Exact,
+		C:       in.C,
+		Params:  in.Params,
+		X0:      in.X0,
+		Y0:      in.Y0,
+		XEnd:    in.XEnd,
+		N:       int(in.N),
+		Step:    in.Step,
+		Methods: in.Methods,
+		Preset:  in.Preset,
+	}
+	for m, n := range in.NByMethod {
+		if req.NByMethod == nil {
+			req.NByMethod = map[string]int{}
+		}
+		req.NByMethod[m] = int(n)
+	}
+
+	req, err := req.WithPreset()
+	if err != nil {
+		return solve.Prepared{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	p, err := g.s.prepare(req)
+	if err != nil {
+		return solve.Prepared{}, grpcInvalid(err)
+	}
+	return p, nil
+}
+
+// grpcInvalid makes the status of the invalid request with the violations of its fields
+func grpcInvalid(err error) error {
+	st := status.New(codes.InvalidArgument, err.Error())
+	var ve rest.ValidationError
+	if !errors.As(err, &ve) {
+		return st.Err()
+	}
+	br := &errdetails.BadRequest{}
+	for _, fe := range ve {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{Field: fe.Field, Description: fe.Msg})
+	}
+	if dst, derr := st.WithDetails(br); derr == nil {
+		st = dst
+	}
+	return st.Err()
+}
+
+// grpcSolveError makes the status of the failed solve
+func grpcSolveError(err error) error {
+	var te *solve.TimeoutError
+	if errors.As(err, &te) {
+		return status.Error(codes.DeadlineExceeded, te.Error())
+	}
+	var be *busyError
+	if errors.As(err, &be) {
+		return status.Error(codes.ResourceExhausted, be.Error())
+	}
+	if errors.Is(err, context.Canceled) {
+		return status.Error(codes.Canceled, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// grpcEventWriter sends points of the stream as events of the call
+type grpcEventWriter struct {
+	stream pb.Solver_SolveStreamServer
+}
+
+// event sends the point event, the stream has no other events, until it is done
+func (gw grpcEventWriter) Event(name string, data interface{}) error {
+	pe, ok := data.(solve.PointEvent)
+	if !ok {
+		return errors.Errorf("unexpected %s event", name)
+	}
+	return gw.stream.Send(&pb.SolveEvent{Event: &pb.SolveEvent_Point{Point: &pb.PointEvent{Method: pe.Method, X: pe.X, Y: pe.Y}}})
+}
+
+// pbLine converts the line of the response
+func pbLine(line solve.Line) *pb.Line {
+	res := &pb.Line{Method: line.Method, Name: line.Name, Step: line.Step, Points: pbPoints(line.Points),
+		Took: durationProto(line.Took)}
+	if line.Error != nil {
+		res.Error = line.Error.Error
+	}
+	if st := line.Stats; st != nil {
+		res.Stats = &pb.Stats{Points: int32(st.Points), Min: pbPoint(st.Min), Max: pbPoint(st.Max), Final: pbPoint(st.Final),
+			Evals: int32(st.Evals), Warnings: st.Warnings}
+	}
+	return res
+}
+
+func pbPoints(pts []num.Point) []*pb.Point {
+	res := make([]*pb.Point, 0, len(pts))
+	for i := range pts {
+		res = append(res, pbPoint(&pts[i]))
+	}
+	return res
+}
+
+func pbPoint(pt *num.Point) *pb.Point {
+	if pt == nil {
+		return nil
+	}
+	return &pb.Point{X: pt.X, Y: pt.Y}
+}
+
+// durationProto converts the duration, formatted as in rest responses, the empty duration is not set
+func durationProto(s string) *duration.Duration {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return nil
+	}
+	return ptypes.DurationProto(d)
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"io"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Semior001/decompract/app/num/service"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/rest/api/pb"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// startGRPC serves the api with grpc on random ports until the test is done, the client of grpc is returned
+func startGRPC(t *testing.T, srv *Rest) pb.SolverClient {
+	ctx, cancel := context.WithCancel(context.Background())
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	gln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		srv.serve(ctx, ln, gln)
+	}()
+
+	conn, err := grpc.Dial(gln.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+		cancel()
+		<-done
+	})
+	return pb.NewSolverClient(conn)
+}
+
+func TestGRPC_Solve(t *testing.T) {
+	cl := startGRPC(t, &Rest{Version: "test", NumService: &service.Service{}})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := cl.Solve(ctx, &pb.SolveRequest{F: "x^2-2*y", Exact: "c*exp(-2*x) + x^2/2 - x/2 + 1/4",
+		C: "(y0 - x0^2/2 + x0/2 - 1/4)*exp(2*x0)", Y0: 1, XEnd: 1, N: 10, Methods: []string{"euler", "rk4"}})
+	require.NoError(t, err)
+	assert.InDelta(t, 0.1, resp.Step, 1e-12)
+	require.Len(t, resp.Lines, 2)
+	for i, m := range []string{"euler", "rk4"} {
+		line := resp.Lines[i]
+		assert.Equal(t, m, line.Method)
+		assert.Len(t, line.Points, 11)
+		assert.Empty(t, line.Error)
+		require.NotNil(t, line.Stats)
+		assert.EqualValues(t, 11, line.Stats.Points)
+		assert.Greater(t, line.Stats.Evals, int32(0))
+		assert.NotNil(t, line.Took)
+	}
+	require.NotNil(t, resp.Exact)
+	assert.Equal(t, solve.ExactMethod, resp.Exact.Method)
+	exactEnd := resp.Exact.Points[10].Y
+	assert.InDelta(t, exactEnd, resp.Lines[1].Points[10].Y, 1e-5)
+	assert.Greater(t, math.Abs(exactEnd-resp.Lines[0].Points[10].Y), 1e-3)
+}
+
+func TestGRPC_SolveInvalid(t *testing.T) {
+	cl := startGRPC(t, &Rest{Version: "test", NumService: &service.Service{}})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := cl.Solve(ctx, &pb.SolveRequest{F: "x^2-", Y0: 1, XEnd: 1, Methods: []string{"rk4", "nope"}})
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+
+	fields := map[string]bool{}
+	for _, d := range st.Details() {
+		br, ok := d.(*errdetails.BadRequest)
+		require.True(t, ok)
+		for _, fv := range br.FieldViolations {
+			fields[fv.Field] = true
+		}
+	}
+	assert.Equal(t, map[string]bool{"n": true, "f": true, "methods": true}, fields)
+
+	_, err = cl.Solve(ctx, &pb.SolveRequest{Preset: "nope", Methods: []string{"rk4"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPC_SolveStream(t *testing.T) {
+	cl := startGRPC(t, &Rest{Version: "test", NumService: &service.Service{}})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := cl.SolveStream(ctx, &pb.SolveRequest{F: "x^2-2*y", Y0: 1, XEnd: 1, N: 4,
+		Methods: []string{"euler", "rk4"}})
+	require.NoError(t, err)
+
+	points := map[string]int{}
+	var done *pb.StreamSummary
+	for {
+		ev, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		require.Nil(t, done, "no events after done")
+		switch e := ev.Event.(type) {
+		case *pb.SolveEvent_Point:
+			points[e.Point.Method]++
+		case *pb.SolveEvent_Done:
+			done = e.Done
+		}
+	}
+	assert.Equal(t, map[string]int{"euler": 5, "rk4": 5}, points)
+	require.NotNil(t, done)
+	require.Len(t, done.Lines, 2)
+	assert.Equal(t, "euler", done.Lines[0].Method)
+	assert.EqualValues(t, 5, done.Lines[1].Points)
+}
+
+func TestGRPC_APIKeys(t *testing.T) {
+	cl := startGRPC(t, &Rest{Version: "test", NumService: &service.Service{},
+		APIKeys: []rest.APIKey{{Hash: rest.HashAPIKey("secret")}}})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req := &pb.SolveRequest{F: "x", Y0: 1, XEnd: 1, N: 2, Methods: []string{"euler"}}
+
+	_, err := cl.Solve(ctx, req)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = cl.Solve(metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer wrong"), req)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	stream, err := cl.SolveStream(ctx, req)
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	resp, err := cl.Solve(metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer secret"), req)
+	require.NoError(t, err)
+	assert.Len(t, resp.Lines, 1)
+}
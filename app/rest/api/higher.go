@@ -0,0 +1,257 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/expr"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/go-chi/render"
+	"github.com/pkg/errors"
+)
+
+// maxOrder is the highest order of the equation of the higher order request
+const maxOrder = 8
+
+// systemMethods are solvers of the reduced system by names of methods of the higher order request
+var systemMethods = map[string]func(f solver.SystemFunc) solver.SystemInterface{
+	"euler":  func(f solver.SystemFunc) solver.SystemInterface { return &solver.SystemEuler{F: f} },
+	"ieuler": func(f solver.SystemFunc) solver.SystemInterface { return &solver.SystemImprovedEuler{F: f} },
+	"rk4":    func(f solver.SystemFunc) solver.SystemInterface { return &solver.SystemRungeKutta{F: f} },
+}
+
+// higherReq is the equation y^(n) = f(x, y, dy, ..., d{n-1}y) of the n-th order, n is the number
+// of initial values of y and its derivatives
+type higherReq struct {
+	F       string             `json:"f"` // f(x, y, dy, d2y, ...) = y^(n)
+	Params  map[string]float64 `json:"params,omitempty"`
+	X0      float64            `json:"x0"`
+	Y0      []float64          `json:"y0"` // y(x0), y'(x0), ..., y^(n-1)(x0)
+	XEnd    float64            `json:"x_end"`
+	N       int                `json:"n"`
+	Methods []string           `json:"methods"`
+	Preset  string             `json:"preset,omitempty"` // id of the built-in equation, that sets f, the interval and y0
+}
+
+// higherResp contains solutions of the equation by methods
+type higherResp struct {
+	Step  float64      `json:"step"`
+	Lines []higherLine `json:"lines"`
+	Took  string       `json:"took"`
+}
+
+// higherLine is the solution by the method, the failed solution has the error and no components
+type higherLine struct {
+	Method     string              `json:"method"`
+	Name       string              `json:"name"`
+	Components []component         `json:"components"` // y and its derivatives
+	Error      *rest.ErrorResponse `json:"error,omitempty"`
+	Took       string              `json:"took"`
+}
+
+// component is y or its derivative, named as the variable of f
+type component struct {
+	Name   string      `json:"name"`
+	Points []num.Point `json:"points"`
+}
+
+// higher is the validated higher order request, ready to solve
+type higher struct {
+	req       higherReq
+	f         solver.FuncN
+	step      float64
+	maxPoints int
+}
+
+// derivName returns the name of the k-th derivative of y in formulas, y, dy, d2y and so on
+func derivName(k int) string {
+	switch k {
+	case 0:
+		return "y"
+	case 1:
+		return "dy"
+	}
+	return "d" + strconv.Itoa(k) + "y"
+}
+
+// prepare validates the request, in case of invalid request, rest.ValidationError with all invalid fields is returned
+func (req higherReq) prepare(l solve.Limits) (higher, error) {
+	req, err := req.withPreset()
+	if err != nil {
+		return higher{}, err
+	}
+	var errs rest.ValidationError
+	invalid := func(field, msg string, args ...interface{}) {
+		errs = append(errs, rest.FieldError{Field: field, Msg: fmt.Sprintf(msg, args...)})
+	}
+
+	if len(req.Y0) < 1 || len(req.Y0) > maxOrder {
+		invalid("y0", "must have between 1 and %d values, as the order of the equation, got %d", maxOrder, len(req.Y0))
+	}
+	for k, v := range req.Y0 {
+		if !solve.IsFinite(v) {
+			invalid("y0", "%s(x0) must be finite", derivName(k))
+		}
+	}
+	if !solve.IsFinite(req.X0) {
+		invalid("x0", "must be finite")
+	}
+	if !solve.IsFinite(req.XEnd) {
+		invalid("x_end", "must be finite")
+	} else if req.X0 == req.XEnd {
+		invalid("x_end", "must differ from x0, the interval of zero length can't be split into n steps")
+	}
+	if req.N < 1 || req.N > l.MaxSteps {
+		invalid("n", "must be between 1 and max_steps=%d, got %d", l.MaxSteps, req.N)
+	}
+	if len(req.Methods) == 0 {
+		invalid("methods", "at least one method is required")
+	}
+	for _, m := range req.Methods {
+		if _, ok := systemMethods[m]; !ok {
+			invalid("methods", "unknown method %q", m)
+		}
+	}
+
+	names := []string{"x"}
+	for k := 0; k < len(req.Y0) && k < maxOrder; k++ {
+		names = append(names, derivName(k))
+	}
+	for _, name := range solve.ParamNames(req.Params) {
+		switch {
+		case !solve.ParamName.MatchString(name):
+			invalid("params", "%q is not a valid name", name)
+		case solve.IsReserved(name) || isDerivName(name):
+			invalid("params", "%q is reserved", name)
+		case !solve.IsFinite(req.Params[name]):
+			invalid("params", "%s must be finite", name)
+		}
+	}
+	fn, err := expr.Parse(req.F, req.Params, names...)
+	if err != nil {
+		invalid("f", "can't parse f(%s): %v", strings.Join(names, ","), err)
+	}
+	if len(errs) > 0 {
+		return higher{}, errs
+	}
+
+	step, err := num.CalculateStepSize(req.N, req.X0, req.XEnd)
+	if err != nil {
+		return higher{}, err
+	}
+	args := make([]float64, len(names)) // the solver doesn't evaluate f concurrently
+	f := func(x float64, ys []float64) (float64, error) {
+		args[0] = x
+		copy(args[1:], ys)
+		return fn(args...)
+	}
+	return higher{req: req, f: f, step: step, maxPoints: l.MaxPoints}, nil
+}
+
+// isDerivName checks, whether the name is the one of derivatives of y up to maxOrder
+func isDerivName(name string) bool {
+	for k := 0; k < maxOrder; k++ {
+		if name == derivName(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// solve solves the equation with each method, the failure of the method is reported in its line,
+// the request fails, if all methods fail or the context is done
+func (hr higher) solve(ctx context.Context) (higherResp, error) {
+	st := time.Now()
+	req := hr.req
+	resp := higherResp{Step: hr.step, Lines: make([]higherLine, len(req.Methods))}
+	failed := 0
+	for i, m := range req.Methods {
+		mst := time.Now()
+		ho := &solver.HigherOrder{F: hr.f, DY0: req.Y0[1:], System: systemMethods[m]}
+		line := higherLine{Method: m, Name: ho.Name(), Components: []component{}}
+
+		var pts []num.VectorPoint
+		err := ho.SolveAll(hr.step, req.X0, req.Y0[0], req.XEnd, solver.VectorDrawerFunc(func(p num.VectorPoint) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			pts = append(pts, p)
+			return nil
+		}))
+		if errors.Is(err, context.DeadlineExceeded) {
+			x := req.X0
+			if len(pts) > 0 {
+				x = pts[len(pts)-1].X
+			}
+			return higherResp{}, &solve.TimeoutError{Method: m, XReached: x}
+		}
+		if ctx.Err() != nil {
+			return higherResp{}, ctx.Err()
+		}
+
+		if err != nil {
+			be := rest.NewErrorResponse(errors.Wrapf(err, "failed to solve with %s", m), "failed to solve", rest.ErrInternal)
+			line.Error = &be
+			failed++
+		} else {
+			for k, l := range solver.Components(pts) {
+				line.Components = append(line.Components, component{Name: derivName(k), Points: solve.Downsample(l.Points, hr.maxPoints)})
+			}
+		}
+		line.Took = time.Since(mst).String()
+		resp.Lines[i] = line
+	}
+	if failed == len(resp.Lines) {
+		return higherResp{}, errors.Errorf("all methods failed, %s", resp.Lines[0].Error.Error)
+	}
+	resp.Took = time.Since(st).String()
+	return resp, nil
+}
+
+// POST /api/v1/solve/higher - solve the equation y^(n) = f(x, y, dy, ..., d{n-1}y) of the order n,
+// reduced to the system of first order equations, from y0 = [y(x0), y'(x0), ..., y^(n-1)(x0)],
+// lines of methods have y along with its derivatives
+func (s *Rest) higherCtrl(w http.ResponseWriter, r *http.Request) {
+	req := higherReq{}
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "failed to decode request", rest.ErrDecode)
+		return
+	}
+
+	hr, err := req.prepare(s.limits())
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid higher order request", rest.ErrBadRequest)
+		return
+	}
+
+	release, err := s.acquireLines(len(req.Methods))
+	if err != nil {
+		var be *busyError
+		if errors.As(err, &be) {
+			sendBusy(w, r, be)
+			return
+		}
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "failed to solve", rest.ErrInternal)
+		return
+	}
+	defer release()
+
+	resp, err := hr.solve(r.Context())
+	if err != nil {
+		var te *solve.TimeoutError
+		if errors.As(err, &te) {
+			s.sendTimeout(w, r, te)
+			return
+		}
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "failed to solve", rest.ErrInternal)
+		return
+	}
+	rest.RenderJSON(w, r, resp)
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRest_Higher(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	// y'' = -k*y from y(0) = 0 and y'(0) = 1 is sin(x) with k = 1
+	body := `{"f": "-k*y", "params": {"k": 1}, "x0": 0, "y0": [0, 1], "x_end": 3, "n": 300, "methods": ["rk4", "euler"]}`
+	resp, err := http.Post(ts.URL+"/api/v1/solve/higher", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := higherResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.InDelta(t, 0.01, res.Step, 1e-12)
+	require.Len(t, res.Lines, 2)
+	assert.Equal(t, "rk4", res.Lines[0].Method)
+	assert.Equal(t, "Runge-Kutta's method", res.Lines[0].Name)
+	assert.Equal(t, "Euler's method", res.Lines[1].Name)
+
+	comps := res.Lines[0].Components
+	require.Len(t, comps, 2)
+	assert.Equal(t, "y", comps[0].Name)
+	assert.Equal(t, "dy", comps[1].Name)
+	require.Len(t, comps[0].Points, 301)
+	for i, p := range comps[0].Points {
+		assert.InDelta(t, math.Sin(p.X), p.Y, 1e-8, "x=%v", p.X)
+		assert.InDelta(t, math.Cos(p.X), comps[1].Points[i].Y, 1e-8, "x=%v", p.X)
+	}
+	assert.Greater(t, math.Abs(res.Lines[1].Components[0].Points[300].Y-math.Sin(3)), 1e-3,
+		"Euler's method is less accurate")
+
+	// y''' = y from ones is exp(x)
+	body = `{"f": "y + 0*dy + 0*d2y", "x0": 0, "y0": [1, 1, 1], "x_end": 1, "n": 10, "methods": ["rk4"]}`
+	resp, err = http.Post(ts.URL+"/api/v1/solve/higher", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res = higherResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	comps = res.Lines[0].Components
+	require.Len(t, comps, 3)
+	assert.Equal(t, "d2y", comps[2].Name)
+	for _, c := range comps {
+		assert.InDelta(t, math.E, c.Points[10].Y, 1e-5, c.Name)
+	}
+
+	// sin(x) backward from x = 3 to 0
+	body = fmt.Sprintf(`{"f": "-y", "x0": 3, "y0": [%v, %v], "x_end": 0, "n": 300, "methods": ["rk4"]}`,
+		math.Sin(3), math.Cos(3))
+	resp, err = http.Post(ts.URL+"/api/v1/solve/higher", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res = higherResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.InDelta(t, 0.01, res.Step, 1e-12, "the step is the positive size of the step")
+	comps = res.Lines[0].Components
+	require.Len(t, comps[0].Points, 301)
+	assert.Equal(t, 0.0, comps[0].Points[300].X)
+	assert.InDelta(t, 0, comps[0].Points[300].Y, 1e-8)
+	assert.InDelta(t, 1, comps[1].Points[300].Y, 1e-8)
+
+	// the failure of the method is isolated in its line
+	systemMethods["broken"] = func(f solver.SystemFunc) solver.SystemInterface {
+		return &solver.SystemEuler{F: func(x float64, y []float64) ([]float64, error) {
+			if x > 0.5 {
+				return nil, errors.New("broken")
+			}
+			return f(x, y)
+		}}
+	}
+	defer delete(systemMethods, "broken")
+	body = `{"f": "-y", "x0": 0, "y0": [0, 1], "x_end": 1, "n": 10, "methods": ["broken", "rk4"]}`
+	resp, err = http.Post(ts.URL+"/api/v1/solve/higher", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res = higherResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.NotNil(t, res.Lines[0].Error)
+	assert.Contains(t, res.Lines[0].Error.Error, "failed to solve with broken")
+	assert.Empty(t, res.Lines[0].Components)
+	assert.Nil(t, res.Lines[1].Error)
+
+	body = `{"f": "-y", "x0": 0, "y0": [0, 1], "x_end": 1, "n": 10, "methods": ["broken"]}`
+	resp, err = http.Post(ts.URL+"/api/v1/solve/higher", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
+
+func TestRest_HigherInvalid(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	tbl := []struct {
+		body  string
+		field string
+		msg   string
+	}{
+		{`{"f": "-y", "y0": [], "x_end": 1, "n": 10, "methods": ["rk4"]}`, "y0",
+			"must have between 1 and 8 values, as the order of the equation, got 0"},
+		{`{"f": "-y", "y0": [0, 1], "x_end": 0, "n": 10, "methods": ["rk4"]}`, "x_end",
+			"must differ from x0, the interval of zero length can't be split into n steps"},
+		{`{"f": "-y", "y0": [0, 1], "x_end": 1, "n": 0, "methods": ["rk4"]}`, "n",
+			"must be between 1 and max_steps=10000, got 0"},
+		{`{"f": "-y", "y0": [0, 1], "x_end": 1, "n": 10, "methods": ["rkf45"]}`, "methods", `unknown method "rkf45"`},
+		{`{"f": "-y", "y0": [0, 1], "x_end": 1, "n": 10}`, "methods", "at least one method is required"},
+		{`{"f": "-y", "params": {"d2y": 1}, "y0": [0, 1], "x_end": 1, "n": 10, "methods": ["rk4"]}`, "params",
+			`"d2y" is reserved`},
+		{`{"f": "-d2y", "y0": [0, 1], "x_end": 1, "n": 10, "methods": ["rk4"]}`, "f",
+			`can't parse f(x,y,dy): unknown variable "d2y", available: x, y, dy at position 2`},
+	}
+	for _, tt := range tbl {
+		resp, err := http.Post(ts.URL+"/api/v1/solve/higher", "application/json", strings.NewReader(tt.body))
+		require.NoError(t, err)
+		er := rest.ErrorResponse{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&er))
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, tt.msg)
+		assert.Contains(t, er.Errors, rest.FieldError{Field: tt.field, Msg: tt.msg})
+	}
+}
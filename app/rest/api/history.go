@@ -0,0 +1,192 @@
+package api
+
+import (
+	"crypto/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/Semior001/decompract/app/store"
+	log "github.com/go-pkgz/lgr"
+	"github.com/pkg/errors"
+)
+
+// limits of the history of solve requests
+const (
+	maxHistory       = 20    // number of the last requests, kept for each session
+	maxSessions      = 10000 // number of sessions with histories, the least recently used ones are dropped
+	historyQueueSize = 1000  // number of writes to the history, waiting to be applied
+	sessionMaxAge    = 30 * 24 * time.Hour
+)
+
+// historyEntry is the summary of the solve request
+type historyEntry struct {
+	F       string   `json:"f"`
+	Exact   string   `json:"exact,omitempty"`
+	C       string   `json:"c,omitempty"`
+	X0      float64  `json:"x0"`
+	Y0      float64  `json:"y0"`
+	XEnd    float64  `json:"x_end"`
+	N       int      `json:"n,omitempty"`
+	Step    float64  `json:"step"` // the step, the problem is solved with
+	Methods []string `json:"methods"`
+	ID      string   `json:"id,omitempty"` // id of the saved result
+	At      string   `json:"at"`           // time of the request
+}
+
+// historyResp lists the last solve requests of the session, from the newest to the oldest
+type historyResp struct {
+	Entries []historyEntry `json:"entries"`
+}
+
+type historyWrite struct {
+	session string
+	entry   historyEntry
+	run     *store.Run    // if set, the run is recorded to the store of runs instead of the entry
+	clear   chan struct{} // if set, the history is cleared and the channel is closed
+}
+
+// history keeps the last solve requests of each session and records runs to the store of runs,
+// if it is set, writes are applied in background, so they don't slow down the solving
+type history struct {
+	sessions *rest.LRU // session id -> []historyEntry, from the newest to the oldest
+	runs     store.Runs
+	queue    chan historyWrite
+	once     sync.Once
+}
+
+func newHistory(runs store.Runs) *history {
+	return &history{
+		sessions: &rest.LRU{MaxEntries: maxSessions, TTL: sessionMaxAge},
+		runs:     runs,
+		queue:    make(chan historyWrite, historyQueueSize),
+	}
+}
+
+// add puts the entry to the history of the session, the entry is dropped,
+// if there are too many writes in the queue
+func (h *history) add(session string, entry historyEntry) {
+	h.push(historyWrite{session: session, entry: entry})
+}
+
+// addRun records the run of the session to the store of runs, the run is dropped,
+// if there are too many writes in the queue
+func (h *history) addRun(run store.Run) {
+	if h.runs == nil {
+		return
+	}
+	h.push(historyWrite{session: run.Session, run: &run})
+}
+
+// clear removes all entries from the history of the session, it waits for the preceding writes
+// to be applied, so they don't restore the history
+func (h *history) clear(session string) {
+	if session == "" {
+		return
+	}
+	h.once.Do(func() { go h.run() })
+	done := make(chan struct{})
+	h.queue <- historyWrite{session: session, clear: done}
+	<-done
+}
+
+// get returns the entries of the session, from the newest to the oldest
+func (h *history) get(session string) []historyEntry {
+	v, ok := h.sessions.Get(session)
+	if !ok {
+		return []historyEntry{}
+	}
+	return append([]historyEntry{}, v.([]historyEntry)...)
+}
+
+func (h *history) push(hw historyWrite) {
+	if hw.session == "" {
+		return
+	}
+	h.once.Do(func() { go h.run() })
+	select {
+	case h.queue <- hw:
+	default:
+		log.Printf("[WARN] history queue is full, write for session %s is dropped", hw.session)
+	}
+}
+
+// run applies the writes from the queue
+func (h *history) run() {
+	for hw := range h.queue {
+		if hw.run != nil {
+			if _, err := h.runs.AddRun(*hw.run); err != nil {
+				log.Printf("[WARN] failed to record run of session %s, %v", hw.session, err)
+			}
+			continue
+		}
+		if hw.clear != nil {
+			h.sessions.Put(hw.session, []historyEntry{})
+			close(hw.clear)
+			continue
+		}
+		entries := []historyEntry{hw.entry}
+		if v, ok := h.sessions.Get(hw.session); ok {
+			entries = append(entries, v.([]historyEntry)...)
+		}
+		if len(entries) > maxHistory {
+			entries = entries[:maxHistory]
+		}
+		h.sessions.Put(hw.session, entries)
+	}
+}
+
+// randRead fills the random session secret, it is replaced in tests
+var randRead = rand.Read
+
+// sessionSecret returns the key to sign session cookies and permalinks, if it is not set, the random key is generated,
+// the server doesn't start without it, as cookies, signed with the zero key, are forged by anyone
+func (s *Rest) sessionSecret() ([]byte, error) {
+	if s.SessionSecret != "" {
+		return []byte(s.SessionSecret), nil
+	}
+	b := make([]byte, 32)
+	if _, err := randRead(b); err != nil {
+		return nil, errors.Wrap(err, "failed to generate session secret")
+	}
+	return b, nil
+}
+
+// record adds the solved request to the history of the session of the request
+// and records the run with the summary of the solution, if runs are kept
+func (s *Rest) record(r *http.Request, req solve.Request, resp solve.Response) {
+	if s.Runs != nil && rest.SessionID(r) != "" {
+		run, err := makeRun(rest.SessionID(r), req, resp)
+		if err != nil {
+			log.Printf("[WARN] failed to make run, %v", err)
+		} else {
+			s.history.addRun(run)
+		}
+	}
+	s.history.add(rest.SessionID(r), historyEntry{
+		F:       req.F,
+		Exact:   req.Exact,
+		C:       req.C,
+		X0:      req.X0,
+		Y0:      req.Y0,
+		XEnd:    req.XEnd,
+		N:       req.N,
+		Step:    resp.Step,
+		Methods: req.Methods,
+		ID:      resp.ID,
+		At:      time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// GET /api/v1/history - returns the last solve requests of the session
+func (s *Rest) historyCtrl(w http.ResponseWriter, r *http.Request) {
+	rest.RenderJSON(w, r, historyResp{Entries: s.history.get(rest.SessionID(r))})
+}
+
+// DELETE /api/v1/history - clears the history of the session
+func (s *Rest) clearHistoryCtrl(w http.ResponseWriter, r *http.Request) {
+	s.history.clear(rest.SessionID(r))
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Semior001/decompract/app/num/service"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRest_History(t *testing.T) {
+	srv, ts := prepTestServer(t)
+	srv.SessionSecret = "secret"
+	ts.Config.Handler = srv.routes()
+
+	newClient := func() *http.Client {
+		jar, err := cookiejar.New(nil)
+		require.NoError(t, err)
+		return &http.Client{Jar: jar}
+	}
+	getHistory := func(cl *http.Client) []historyEntry {
+		resp, err := cl.Get(ts.URL + "/api/v1/history")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		res := historyResp{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+		return res.Entries
+	}
+	doSolve := func(cl *http.Client, body string) solve.Response {
+		resp, err := cl.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		res := solve.Response{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+		return res
+	}
+
+	cl := newClient()
+	assert.Empty(t, getHistory(cl), "new session has no history")
+
+	for i := 0; i < 25; i++ {
+		doSolve(cl, fmt.Sprintf(`{"f": "x", "x0": 0, "y0": %d, "x_end": 1, "n": 10, "methods": ["rk4"]}`, i))
+	}
+	saved := doSolve(cl, `{"f": "x", "x0": 0, "y0": 100, "x_end": 1, "step": 0.5, "methods": ["euler"], "save": true}`)
+	resp, err := cl.Get(ts.URL + "/api/v1/solve?f=x&x0=0&y0=200&x1=1&n=10&method=rk4")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	var entries []historyEntry
+	require.Eventually(t, func() bool {
+		entries = getHistory(cl)
+		return len(entries) > 0 && entries[0].Y0 == 200
+	}, time.Second, 10*time.Millisecond, "history must be written in background")
+
+	require.Len(t, entries, maxHistory, "history must be capped")
+	assert.Equal(t, float64(100), entries[1].Y0)
+	assert.Equal(t, saved.ID, entries[1].ID)
+	assert.Equal(t, []string{"euler"}, entries[1].Methods)
+	assert.Equal(t, 0.5, entries[1].Step)
+	assert.NotEmpty(t, entries[1].At)
+	assert.Equal(t, float64(24), entries[2].Y0, "entries must be from the newest to the oldest")
+	assert.Equal(t, float64(7), entries[maxHistory-1].Y0, "the oldest entries must be dropped")
+
+	// the history of other session is separate
+	other := newClient()
+	doSolve(other, `{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"]}`)
+	require.Eventually(t, func() bool { return len(getHistory(other)) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Len(t, getHistory(cl), maxHistory)
+
+	// the history is cleared
+	req, err := http.NewRequest(http.MethodDelete, ts.URL+"/api/v1/history", nil)
+	require.NoError(t, err)
+	resp, err = cl.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Empty(t, getHistory(cl))
+	assert.Len(t, getHistory(other), 1)
+}
+
+func TestHistory_QueueFull(t *testing.T) {
+	h := &history{sessions: &rest.LRU{}, queue: make(chan historyWrite, 1)}
+	h.once.Do(func() {}) // writes are not applied
+
+	h.add("s", historyEntry{F: "x"})
+	h.add("s", historyEntry{F: "y"})
+	h.add("", historyEntry{F: "z"})
+	require.Len(t, h.queue, 1, "writes over the size of the queue must be dropped")
+	assert.Equal(t, "x", (<-h.queue).entry.F)
+}
+
+func TestRest_RunSessionSecretFailed(t *testing.T) {
+	defer func(read func([]byte) (int, error)) { randRead = read }(randRead)
+	randRead = func([]byte) (int, error) { return 0, errors.New("no entropy") }
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+	require.NoError(t, ln.Close())
+
+	srv := &Rest{Version: "test", NumService: &service.Service{}}
+	err = srv.Run(context.Background(), port)
+	assert.EqualError(t, err, "failed to generate session secret: no entropy")
+	assert.Nil(t, srv.httpServer, "the server must not start without the key")
+
+	srv.SessionSecret = "secret"
+	secret, err := srv.sessionSecret()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret"), secret, "the given key doesn't need random bytes")
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+)
+
+// infoResp describes the running application
+type infoResp struct {
+	Version   string       `json:"version"`
+	Revision  string       `json:"revision"`
+	GoVersion string       `json:"go_version"`
+	Uptime    string       `json:"uptime"`
+	Methods   []methodInfo `json:"methods"`
+	Limits    solve.Limits `json:"limits"` // effective limits of solve requests
+}
+
+// methodInfo describes the method, available in solve requests
+type methodInfo struct {
+	Method string `json:"method"`
+	Name   string `json:"name"`
+}
+
+// GET /api/v1/info - returns the version of the application and the list of available methods
+func (s *Rest) infoCtrl(w http.ResponseWriter, r *http.Request) {
+	resp := infoResp{
+		Version:   orUnknown(s.Version),
+		Revision:  orUnknown(s.Revision),
+		GoVersion: runtime.Version(),
+		Uptime:    time.Since(s.started).Truncate(time.Second).String(),
+		Limits:    s.limits(),
+	}
+	resp.Methods = methodInfos()
+	rest.RenderJSON(w, r, resp)
+}
+
+// methodInfos describes registered methods, sorted by names
+func methodInfos() []methodInfo {
+	var res []methodInfo
+	for _, name := range solver.Methods() {
+		// solvers don't use the function until solving, so it's safe to instantiate them without it
+		res = append(res, methodInfo{Method: name, Name: solve.Builder(name)(nil).Name()})
+	}
+	return res
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRest_Info(t *testing.T) {
+	srv, ts := prepTestServer(t)
+	srv.Revision = "abc1234"
+
+	resp, err := http.Get(ts.URL + "/api/v1/info")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	res := infoResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.Equal(t, "test", res.Version)
+	assert.Equal(t, "abc1234", res.Revision)
+	assert.Equal(t, runtime.Version(), res.GoVersion)
+	assert.Equal(t, "0s", res.Uptime)
+	assert.Equal(t, []methodInfo{
+		{Method: "ab4", Name: "Adams-Bashforth's method of order 4"},
+		{Method: "abm4", Name: "Adams-Bashforth-Moulton's method"},
+		{Method: "bdf", Name: "BDF method"},
+		{Method: "beuler", Name: "Backward Euler's method"},
+		{Method: "dopri5", Name: "Dormand-Prince's method"},
+		{Method: "euler", Name: "Euler's method"},
+		{Method: "ieuler", Name: "Improved Euler's method"},
+		{Method: "rk4", Name: "Runge-Kutta's method"},
+		{Method: "rkf45", Name: "Runge-Kutta-Fehlberg's method"},
+		{Method: "switching", Name: "Runge-Kutta's method with switching to backward Euler's"},
+		{Method: "taylor2", Name: "Taylor's method of order 2"},
+		{Method: "trapezoid", Name: "Trapezoidal method"},
+	}, res.Methods)
+
+	// not injected version is reported as unknown
+	ts2 := httptest.NewServer((&Rest{}).routes())
+	defer ts2.Close()
+	resp, err = http.Get(ts2.URL + "/api/v1/info")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	res = infoResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.Equal(t, "unknown", res.Version)
+	assert.Equal(t, "unknown", res.Revision)
+}
+
+func TestRest_Ping(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/ping")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "pong", string(body))
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Semior001/decompract/app/num/expr"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/pkg/errors"
+)
+
+// defaultIntegrateN is the initial number of steps of the integral with the tolerance
+const defaultIntegrateN = 4
+
+// integrateReq is the definite integral of g(x) over [a, b], calculated with n steps, or with the number
+// of steps, doubled from n, until the integral is found up to tol
+type integrateReq struct {
+	G      string
+	Params map[string]float64
+	A, B   float64
+	N      int
+	Tol    float64
+	Method string
+}
+
+// integrateResp is the integral with the number of steps, it is calculated with
+type integrateResp struct {
+	Value  float64 `json:"value"`
+	N      int     `json:"n"`
+	Method string  `json:"method"`
+	Took   string  `json:"took"`
+}
+
+// integral is the validated integrate request, ready to calculate
+type integral struct {
+	req  integrateReq
+	g    func(x float64) (float64, error)
+	maxN int
+}
+
+// prepare validates the request, in case of invalid request, rest.ValidationError with all invalid fields is returned
+func (req integrateReq) prepare(l solve.Limits) (integral, error) {
+	res := integral{req: req, maxN: l.MaxSteps}
+	var errs rest.ValidationError
+	invalid := func(field, msg string, args ...interface{}) {
+		errs = append(errs, rest.FieldError{Field: field, Msg: fmt.Sprintf(msg, args...)})
+	}
+
+	if !solve.IsFinite(req.A) {
+		invalid("a", "must be finite")
+	}
+	if !solve.IsFinite(req.B) {
+		invalid("b", "must be finite")
+	}
+	if _, ok := solver.Lookup(req.Method); !ok {
+		invalid("method", "unknown method %q", req.Method)
+	}
+	switch {
+	case req.Tol != 0 && !(req.Tol > 0 && solve.IsFinite(req.Tol)):
+		invalid("tol", "must be positive")
+	case req.N == 0 && req.Tol == 0:
+		invalid("n", "either n or tol must be set")
+	case req.N != 0 && (req.N < 1 || req.N > l.MaxSteps):
+		invalid("n", "must be between 1 and max_steps=%d, got %d", l.MaxSteps, req.N)
+	}
+	for _, name := range solve.ParamNames(req.Params) {
+		switch {
+		case !solve.ParamName.MatchString(name):
+			invalid("params", "%q is not a valid name", name)
+		case solve.IsReserved(name):
+			invalid("params", "%q is reserved", name)
+		case !solve.IsFinite(req.Params[name]):
+			invalid("params", "%s must be finite", name)
+		}
+	}
+
+	// the integrand is the function of x only, y is not defined in it
+	gx, err := expr.Parse(req.G, req.Params, "x")
+	if err != nil {
+		invalid("g", "can't parse g(x): %v", err)
+	}
+	res.g = func(x float64) (float64, error) { return gx(x) }
+
+	if len(errs) > 0 {
+		return integral{}, errs
+	}
+	return res, nil
+}
+
+// calculate calculates the integral with n steps or, if the tolerance is set, with the number of steps,
+// doubled from n up to max_steps
+func (in integral) calculate() (integrateResp, error) {
+	st := time.Now()
+	resp := integrateResp{N: in.req.N, Method: in.req.Method}
+	method := solve.Builder(in.req.Method)
+	var err error
+	if in.req.Tol > 0 {
+		n := in.req.N
+		if n == 0 {
+			n = defaultIntegrateN
+		}
+		resp.Value, resp.N, err = solver.IntegrateTol(in.g, in.req.A, in.req.B, n, in.maxN, in.req.Tol, method)
+	} else {
+		resp.Value, err = solver.Integrate(in.g, in.req.A, in.req.B, in.req.N, method)
+	}
+	if err != nil {
+		return integrateResp{}, err
+	}
+	resp.Took = time.Since(st).String()
+	return resp, nil
+}
+
+// readIntegrateQuery reads the integrate request from query parameters, the method is rk4 by default
+func readIntegrateQuery(r *http.Request) (req integrateReq, err error) {
+	if req.G, err = queryFormula(r, "g"); err != nil {
+		return integrateReq{}, err
+	}
+	if req.Params, err = queryParams(r); err != nil {
+		return integrateReq{}, err
+	}
+	if req.A, err = queryFloat(r, "a", 0); err != nil {
+		return integrateReq{}, err
+	}
+	if req.B, err = queryFloat(r, "b", 0); err != nil {
+		return integrateReq{}, err
+	}
+	if req.N, err = queryInt(r, "n", 0); err != nil {
+		return integrateReq{}, err
+	}
+	if req.Tol, err = queryFloat(r, "tol", 0); err != nil {
+		return integrateReq{}, err
+	}
+	if req.Method = r.URL.Query().Get("method"); req.Method == "" {
+		req.Method = "rk4"
+	}
+	return req, nil
+}
+
+// GET /api/v1/integrate?g=sin(x)&a=0&b=3.14159&n=100&tol=1e-9&method=rk4 - calculate the definite integral
+// of g(x) over [a, b] as the solution of y' = g(x), y(a) = 0 at b
+func (s *Rest) integrateCtrl(w http.ResponseWriter, r *http.Request) {
+	req, err := readIntegrateQuery(r)
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "failed to read query parameters", rest.ErrDecode)
+		return
+	}
+
+	in, err := req.prepare(s.limits())
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid integrate request", rest.ErrBadRequest)
+		return
+	}
+
+	resp, err := in.calculate()
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, errors.Wrap(err, "failed to integrate"),
+			"integral is not calculated", rest.ErrInternal)
+		return
+	}
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	rest.RenderJSON(w, r, resp)
+}
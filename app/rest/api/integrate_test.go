@@ -0,0 +1,83 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func integrateURL(ts string, q url.Values) string {
+	return ts + "/api/v1/integrate?" + strings.ReplaceAll(q.Encode(), "+", "%20")
+}
+
+func TestRest_Integrate(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	q := url.Values{"g": {"sin(x)"}, "a": {"0"}, "b": {"3.141592653589793"}, "n": {"100"}}
+	resp, err := http.Get(integrateURL(ts.URL, q))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := integrateResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.InDelta(t, 2, res.Value, 1e-8)
+	assert.Equal(t, 100, res.N)
+	assert.Equal(t, "rk4", res.Method)
+	assert.NotEmpty(t, res.Took)
+
+	// with the tolerance and the parameter, the reversed interval negates the integral
+	q = url.Values{"g": {"k*x^2"}, "a": {"1"}, "b": {"0"}, "tol": {"1e-6"}, "method": {"ieuler"}, "param": {"k:3"}}
+	resp, err = http.Get(integrateURL(ts.URL, q))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res = integrateResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.InDelta(t, -1, res.Value, 1e-5)
+	assert.Greater(t, res.N, defaultIntegrateN)
+	assert.Equal(t, "ieuler", res.Method)
+}
+
+func TestRest_IntegrateInvalid(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	tbl := []struct {
+		q     url.Values
+		field string
+		msg   string
+	}{
+		{url.Values{"g": {"x"}, "b": {"1"}}, "n", "either n or tol must be set"},
+		{url.Values{"g": {"x"}, "b": {"1"}, "n": {"100000"}}, "n", "must be between 1 and max_steps=10000, got 100000"},
+		{url.Values{"g": {"x"}, "b": {"1"}, "tol": {"-1"}}, "tol", "must be positive"},
+		{url.Values{"g": {"x"}, "b": {"1"}, "n": {"1"}, "method": {"exact"}}, "method", `unknown method "exact"`},
+		{url.Values{"g": {"x"}, "b": {"Inf"}, "n": {"1"}}, "b", "must be finite"},
+		{url.Values{"g": {"x"}, "b": {"1"}, "n": {"1"}, "param": {"y:1"}}, "params", `"y" is reserved`},
+		{url.Values{"g": {"y"}, "b": {"1"}, "n": {"10"}}, "g",
+			`can't parse g(x): unknown variable "y", available: x at position 1`},
+	}
+	for _, tt := range tbl {
+		resp, err := http.Get(integrateURL(ts.URL, tt.q))
+		require.NoError(t, err)
+		er := rest.ErrorResponse{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&er))
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, tt.msg)
+		assert.Contains(t, er.Errors, rest.FieldError{Field: tt.field, Msg: tt.msg})
+	}
+
+	// the tolerance is not reached within max_steps
+	q := url.Values{"g": {"exp(x)"}, "b": {"1"}, "tol": {"1e-300"}, "method": {"euler"}}
+	resp, err := http.Get(integrateURL(ts.URL, q))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	er := rest.ErrorResponse{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&er))
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Contains(t, er.Error, "integral is not converged")
+}
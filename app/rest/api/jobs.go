@@ -0,0 +1,267 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	log "github.com/go-pkgz/lgr"
+	"github.com/pkg/errors"
+)
+
+// limits of jobs
+const (
+	defaultJobWorkers = 2                // default number of jobs, solved at once
+	defaultJobTimeout = 10 * time.Minute // default maximal duration of the solve of the job
+	jobQueueSize      = 100              // number of jobs, waiting for workers, more jobs are rejected as busy
+	maxJobs           = 1000             // number of kept jobs, the least recently used ones are dropped
+	jobTTL            = time.Hour        // time to keep the job since it is submitted, or finished
+)
+
+// statuses of the job
+const (
+	jobQueued  = "queued"
+	jobRunning = "running"
+	jobDone    = "done"
+	jobFailed  = "failed"
+)
+
+// jobResp describes the job, the result is set, once it is done, the error, once it is failed
+type jobResp struct {
+	ID         string              `json:"id"`
+	Status     string              `json:"status"`   // queued, running, done or failed
+	Progress   float64             `json:"progress"` // share of the interval, covered by methods, from 0 to 1
+	CreatedAt  string              `json:"created_at"`
+	StartedAt  string              `json:"started_at,omitempty"`
+	FinishedAt string              `json:"finished_at,omitempty"`
+	Result     *solve.Response     `json:"result,omitempty"`
+	Error      *rest.ErrorResponse `json:"error,omitempty"` // describes why the job is failed
+}
+
+// job is the solve request, solved in background
+type job struct {
+	id       string
+	req      solve.Request
+	p        solve.Prepared
+	progress *solve.JobProgress
+
+	lock sync.Mutex
+	resp jobResp
+}
+
+// jobs keeps submitted jobs and the queue of jobs, waiting for workers, workers are started with the first job
+type jobs struct {
+	all   *rest.LRU // job id -> *job
+	queue chan *job
+	once  sync.Once
+}
+
+func newJobs() *jobs {
+	return &jobs{all: &rest.LRU{MaxEntries: maxJobs, TTL: jobTTL}, queue: make(chan *job, jobQueueSize)}
+}
+
+// get returns the job by its id
+func (js *jobs) get(id string) (*job, bool) {
+	v, ok := js.all.Get(id)
+	if !ok {
+		return nil, false
+	}
+	return v.(*job), true
+}
+
+// jobWorkers returns the number of jobs, solved at once
+func (s *Rest) jobWorkers() int {
+	if s.JobWorkers <= 0 {
+		return defaultJobWorkers
+	}
+	return s.JobWorkers
+}
+
+// jobTimeout returns the maximal duration of the solve of the job
+func (s *Rest) jobTimeout() time.Duration {
+	if s.JobTimeout <= 0 {
+		return defaultJobTimeout
+	}
+	return s.JobTimeout
+}
+
+// POST /api/jobs - validates the solve request, the same as POST /api/v1/solve, and puts it to the queue of jobs,
+// it responds with 202 and the queued job, GET /api/jobs/{id} reports its progress and returns the result, once it
+// is done. Jobs are limited by max_job_steps instead of max_steps and by the job timeout instead of the solve one
+func (s *Rest) submitJobCtrl(w http.ResponseWriter, r *http.Request) {
+	req := solve.Request{}
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "failed to decode request", rest.ErrDecode)
+		return
+	}
+
+	req, err := req.WithPreset()
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid solve request", rest.ErrBadRequest)
+		return
+	}
+	if req.Warm {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, errors.New("warm start is not supported in jobs"),
+			"invalid solve request", rest.ErrBadRequest)
+		return
+	}
+	if req.Save && s.Store == nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, errors.New("results can't be saved"),
+			"saving of results is disabled", rest.ErrBadRequest)
+		return
+	}
+
+	l := s.limits()
+	l.MaxSteps = l.MaxJobSteps
+	p, err := s.prepareUnder(req, l)
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid solve request", rest.ErrBadRequest)
+		return
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "failed to make job", rest.ErrInternal)
+		return
+	}
+	j := &job{id: id, req: req, p: p, progress: newJobProgress(p)}
+	j.p.Progress = j.progress
+	j.resp = jobResp{ID: id, Status: jobQueued, CreatedAt: time.Now().UTC().Format(time.RFC3339)}
+
+	js := s.jobs
+	js.once.Do(func() {
+		for i := 0; i < s.jobWorkers(); i++ {
+			go s.runJobs(js)
+		}
+	})
+	select {
+	case js.queue <- j:
+	default:
+		w.Header().Set("Retry-After", strconv.Itoa(busyRetryAfter))
+		rest.SendErrorJSON(w, r, http.StatusServiceUnavailable,
+			errors.Errorf("%d jobs are waiting already", jobQueueSize), "too many jobs", rest.ErrBusy)
+		return
+	}
+	js.all.Put(id, j)
+
+	w.Header().Set("Location", "/api/jobs/"+id)
+	render.Status(r, http.StatusAccepted)
+	rest.RenderJSON(w, r, j.status())
+}
+
+// GET /api/jobs/{id} - returns the status and the progress of the job, along with the result, once it is done
+func (s *Rest) jobCtrl(w http.ResponseWriter, r *http.Request) {
+	j, ok := s.jobs.get(chi.URLParam(r, "id"))
+	if !ok {
+		rest.SendErrorJSON(w, r, http.StatusNotFound, errors.New("job not found"), "no such job or it is expired",
+			rest.ErrNotFound)
+		return
+	}
+	rest.RenderJSON(w, r, j.status())
+}
+
+// runJobs solves jobs from the queue one by one
+func (s *Rest) runJobs(js *jobs) {
+	for j := range js.queue {
+		resp, err := s.runJob(j)
+		j.finish(resp, err)
+		js.all.Put(j.id, j) // the finished job is kept for its ttl since now
+	}
+}
+
+// runJob solves the problem of the job, it waits for the slots of lines, if the server is busy, running jobs are
+// drained on shutdown along with requests, the jobs, that are not started yet, are failed
+func (s *Rest) runJob(j *job) (solve.Response, error) {
+	if s.drain.shuttingDown() {
+		return solve.Response{}, errors.New(shutdownMsg)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.jobTimeout())
+	defer s.drain.remove(s.drain.add(cancel))
+
+	j.start()
+	release, err := s.waitLines(ctx, j.p.Lines())
+	if err != nil {
+		return solve.Response{}, errors.Wrap(err, "failed to wait for solves in flight")
+	}
+	defer release()
+
+	st := time.Now()
+	resp, err := s.solveAcquired(ctx, j.p)
+	if err != nil {
+		log.Printf("[WARN] job %s is failed after %s, %v", j.id, time.Since(st), err)
+		return solve.Response{}, err
+	}
+	if j.req.Errors {
+		resp.Errors = resp.LocalErrors()
+	}
+	if j.req.Save {
+		return s.saveResult(j.req, resp)
+	}
+	return resp, nil
+}
+
+// start marks the job as running
+func (j *job) start() {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.resp.Status = jobRunning
+	j.resp.StartedAt = time.Now().UTC().Format(time.RFC3339)
+}
+
+// finish marks the job as done with the result or as failed with the error
+func (j *job) finish(resp solve.Response, err error) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.resp.FinishedAt = time.Now().UTC().Format(time.RFC3339)
+	if err != nil {
+		var te *solve.TimeoutError
+		details := "failed to solve"
+		if errors.As(err, &te) {
+			details = "failed to solve in time"
+		}
+		be := rest.NewErrorResponse(err, details, rest.ErrInternal)
+		j.resp.Status, j.resp.Error = jobFailed, &be
+		return
+	}
+	j.resp.Status, j.resp.Result, j.resp.Progress = jobDone, &resp, 1
+}
+
+// status returns the description of the job
+func (j *job) status() jobResp {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	res := j.resp
+	if res.Status == jobRunning {
+		res.Progress = j.progress.Value()
+	}
+	return res
+}
+
+// newJobID returns the random id of the job
+func newJobID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "failed to generate id")
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func newJobProgress(p solve.Prepared) *solve.JobProgress {
+	jp := &solve.JobProgress{X0: p.Req.X0, XEnd: p.Req.XEnd, Reached: map[string]*uint64{}}
+	_ = p.Each(func(method string, _ solver.Interface) error {
+		bits := math.Float64bits(p.Req.X0)
+		jp.Reached[method] = &bits
+		return nil
+	})
+	return jp
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// submitJob submits the job and returns the response with its status
+func submitJob(t *testing.T, ts *httptest.Server, body string) (jobResp, *http.Response) {
+	resp, err := http.Post(ts.URL+"/api/jobs", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	res := jobResp{}
+	if resp.StatusCode == http.StatusAccepted {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	}
+	return res, resp
+}
+
+// waitJob polls the job until it is finished
+func waitJob(t *testing.T, ts *httptest.Server, id string) jobResp {
+	res := jobResp{}
+	require.Eventually(t, func() bool {
+		resp, err := http.Get(ts.URL + "/api/jobs/" + id)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		res = jobResp{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+		return res.Status == jobDone || res.Status == jobFailed
+	}, 10*time.Second, 10*time.Millisecond)
+	return res
+}
+
+func TestRest_Jobs(t *testing.T) {
+	srv, ts := prepTestServer(t)
+	srv.Limits = solve.Limits{MaxSteps: 100, MaxJobSteps: 100000}
+	ts.Config.Handler = srv.routes()
+
+	// the job isn't limited by max_steps
+	queued, resp := submitJob(t, ts, `{"f": "x^2-2*y", "exact": "c*exp(-2*x) + x^2/2 - x/2 + 1/4",
+		"c": "(y0 - x0^2/2 + x0/2 - 1/4)*exp(2*x0)", "x0": 0, "y0": 1, "x_end": 1, "n": 20000,
+		"methods": ["euler", "rk4"], "errors": true, "save": true}`)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.Equal(t, "/api/jobs/"+queued.ID, resp.Header.Get("Location"))
+	assert.Len(t, queued.ID, 32)
+	assert.NotEmpty(t, queued.CreatedAt)
+	assert.Contains(t, []string{jobQueued, jobRunning}, queued.Status)
+	assert.Nil(t, queued.Result)
+
+	done := waitJob(t, ts, queued.ID)
+	require.Equal(t, jobDone, done.Status, "job must be done, %+v", done.Error)
+	assert.Equal(t, 1.0, done.Progress)
+	assert.NotEmpty(t, done.StartedAt)
+	assert.NotEmpty(t, done.FinishedAt)
+	require.NotNil(t, done.Result)
+	require.Len(t, done.Result.Lines, 2)
+	assert.Len(t, done.Result.Lines[1].Points, 20001)
+	require.NotNil(t, done.Result.Exact)
+	assert.Len(t, done.Result.Errors, 2)
+	require.NotEmpty(t, done.Result.ID, "the result is saved")
+
+	saved, err := http.Get(ts.URL + "/api/v1/result/" + done.Result.ID)
+	require.NoError(t, err)
+	saved.Body.Close()
+	assert.Equal(t, http.StatusOK, saved.StatusCode)
+
+	// the same request is limited by max_steps without the job
+	resp, err = http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(`{"f": "x", "x0": 0,
+		"y0": 1, "x_end": 1, "n": 20000, "methods": ["rk4"]}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Get(ts.URL + "/api/jobs/unknown")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestRest_JobsInvalid(t *testing.T) {
+	srv, ts := prepTestServer(t)
+	srv.Limits = solve.Limits{MaxJobSteps: 1000}
+	ts.Config.Handler = srv.routes()
+
+	for _, body := range []string{
+		`{"f": "x^2-", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"]}`,
+		`{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 100000, "methods": ["rk4"]}`,
+		`{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"], "warm": true}`,
+		`{"preset": "nope", "n": 10, "methods": ["rk4"]}`,
+		`{"f": "x", `,
+	} {
+		_, resp := submitJob(t, ts, body)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, body)
+	}
+}
+
+func TestRest_JobsFailed(t *testing.T) {
+	srv, ts := prepTestServer(t)
+	srv.JobTimeout = time.Nanosecond
+	ts.Config.Handler = srv.routes()
+
+	queued, resp := submitJob(t, ts, `{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10000, "methods": ["rk4"]}`)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	failed := waitJob(t, ts, queued.ID)
+	require.Equal(t, jobFailed, failed.Status)
+	require.NotNil(t, failed.Error)
+	assert.Equal(t, rest.ErrInternal, failed.Error.Code)
+	assert.Nil(t, failed.Result)
+	assert.NotEmpty(t, failed.FinishedAt)
+}
+
+func TestRest_JobsQueue(t *testing.T) {
+	srv, ts := prepTestServer(t)
+	srv.JobWorkers = 1
+	srv.Limits = solve.Limits{MaxConcurrent: 1}
+	ts.Config.Handler = srv.routes()
+
+	// the worker waits for the slot of the solve, so jobs are kept in the queue
+	release, err := srv.acquireLines(1)
+	require.NoError(t, err)
+	body := `{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["euler"]}`
+	first, resp := submitJob(t, ts, body)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	// the queue is filled directly, as requests of the client are limited by the rate
+	p, err := srv.prepare(solve.Request{F: "x", X0: 0, Y0: 1, XEnd: 1, N: 10, Methods: []string{"euler"}})
+	require.NoError(t, err)
+	var fillers []*job
+	for full := false; !full; {
+		j := &job{id: "filler", p: p, resp: jobResp{Status: jobQueued}}
+		select {
+		case srv.jobs.queue <- j:
+			fillers = append(fillers, j)
+		default:
+			full = true
+		}
+	}
+	_, resp = submitJob(t, ts, body)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode, "the job must be rejected, once the queue is full")
+	assert.Equal(t, "1", resp.Header.Get("Retry-After"))
+
+	release()
+	assert.Equal(t, jobDone, waitJob(t, ts, first.ID).Status)
+	require.Eventually(t, func() bool {
+		return fillers[len(fillers)-1].status().Status == jobDone
+	}, 10*time.Second, 10*time.Millisecond, "jobs of the queue must be solved")
+}
+
+func TestJobProgress(t *testing.T) {
+	p, err := solve.Request{F: "x", X0: 1, Y0: 1, XEnd: 3, N: 10, Methods: []string{"euler", "rk4"}}.Prepare(solve.Limits{MaxSteps: 100})
+	require.NoError(t, err)
+	jp := newJobProgress(p)
+	assert.Equal(t, 0.0, jp.Value())
+
+	c := solve.Collector(p.Step, 1, 3)
+	d := jp.Wrap("euler", c)
+	require.NoError(t, d.Draw(num.Point{X: 1, Y: 1}))
+	require.NoError(t, d.Draw(num.Point{X: 2, Y: 1}))
+	assert.Equal(t, 0.25, jp.Value(), "half of the interval of the one of two methods")
+	require.NoError(t, jp.Wrap("rk4", c).Draw(num.Point{X: 3, Y: 1}))
+	assert.Equal(t, 0.75, jp.Value())
+	assert.Len(t, c.Points, 3)
+
+	assert.Equal(t, c, jp.Wrap("unknown", c), "unknown methods are not tracked")
+	var nilProgress *solve.JobProgress
+	assert.Equal(t, c, nilProgress.Wrap("euler", c))
+	assert.Equal(t, 0.0, nilProgress.Value())
+}
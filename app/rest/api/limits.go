@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/go-chi/render"
+	log "github.com/go-pkgz/lgr"
+	"golang.org/x/sync/semaphore"
+)
+
+// busyRetryAfter is the number of seconds to wait, when the server is busy
+const busyRetryAfter = 1
+
+// limits returns the effective limits of the server
+func (s *Rest) limits() solve.Limits {
+	return s.Limits.Effective()
+}
+
+// busyError is returned, when the server solves too many lines to start solving the request
+type busyError struct {
+	lines int
+	limit int
+}
+
+func (e *busyError) Error() string {
+	return fmt.Sprintf("server is busy, can't start solving %d lines with max_concurrent=%d", e.lines, e.limit)
+}
+
+// acquire takes the slots of the lines of the problem from the semaphore of concurrent solves,
+// it doesn't wait for the slots and returns busyError, if they are taken
+func (s *Rest) acquire(p solve.Prepared) (release func(), err error) {
+	return s.acquireLines(p.Lines())
+}
+
+// acquireLines takes the slots of lines from the semaphore of concurrent solves, as acquire does
+func (s *Rest) acquireLines(lines int) (release func(), err error) {
+	if s.solving == nil {
+		return func() {}, nil
+	}
+	limit := s.limits().MaxConcurrent
+	weight := int64(lines)
+	if weight > int64(limit) {
+		weight = int64(limit) // the problem is solved alone
+	}
+	if !s.solving.TryAcquire(weight) {
+		return nil, &busyError{lines: lines, limit: limit}
+	}
+	return func() { s.solving.Release(weight) }, nil
+}
+
+// waitLines takes the slots of lines from the semaphore of concurrent solves, as acquireLines does,
+// but it waits for the slots, until the context is done
+func (s *Rest) waitLines(ctx context.Context, lines int) (release func(), err error) {
+	if s.solving == nil {
+		return func() {}, nil
+	}
+	weight := int64(lines)
+	if limit := int64(s.limits().MaxConcurrent); weight > limit {
+		weight = limit
+	}
+	if err = s.solving.Acquire(ctx, weight); err != nil {
+		return nil, err
+	}
+	return func() { s.solving.Release(weight) }, nil
+}
+
+// newSemaphore makes the semaphore of concurrent solves, nil if they are unlimited
+func newSemaphore(limit int) *semaphore.Weighted {
+	if limit <= 0 {
+		return nil
+	}
+	return semaphore.NewWeighted(int64(limit))
+}
+
+// sendBusy responds with 503 and Retry-After header
+func sendBusy(w http.ResponseWriter, r *http.Request, be *busyError) {
+	log.Printf("[WARN] %s %s: %v", r.Method, r.URL.Path, be)
+	w.Header().Set("Retry-After", strconv.Itoa(busyRetryAfter))
+	render.Status(r, http.StatusServiceUnavailable)
+	rest.RenderJSON(w, r, rest.NewErrorResponse(be, "too many solves at once", rest.ErrBusy))
+}
@@ -0,0 +1,252 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Semior001/decompract/app/num/service"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/Semior001/decompract/app/store"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRest_Limits(t *testing.T) {
+	srv := &Rest{}
+	assert.Equal(t, solve.Limits{MaxSteps: solve.DefaultMaxSteps, MaxBatch: solve.DefaultMaxBatch, MaxSweep: solve.DefaultMaxSweep,
+		MaxJobSteps: solve.DefaultMaxJobSteps}, srv.limits())
+
+	srv.Limits = solve.Limits{MaxSteps: 10, MaxWidth: -1, MaxBatch: 5, MaxConcurrent: -1, MaxPoints: 1, MaxSweep: 20,
+		MaxJobSteps: 100}
+	assert.Equal(t, solve.Limits{MaxSteps: 10, MaxBatch: 5, MaxPoints: 2, MaxSweep: 20, MaxJobSteps: 100}, srv.limits())
+
+	srv.Limits = solve.Limits{MaxSteps: 2 * solve.DefaultMaxJobSteps, MaxJobSteps: 10}
+	assert.Equal(t, 2*solve.DefaultMaxJobSteps, srv.limits().MaxJobSteps, "jobs are limited by max_steps at least")
+}
+
+func TestRest_LimitsInfo(t *testing.T) {
+	srv, ts := prepTestServer(t)
+	srv.Limits = solve.Limits{MaxWidth: 100, MaxConcurrent: 8, MaxPoints: 500}
+
+	resp, err := http.Get(ts.URL + "/api/v1/info")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	res := infoResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.Equal(t, solve.Limits{MaxSteps: solve.DefaultMaxSteps, MaxWidth: 100, MaxBatch: solve.DefaultMaxBatch,
+		MaxConcurrent: 8, MaxPoints: 500, MaxSweep: solve.DefaultMaxSweep, MaxJobSteps: solve.DefaultMaxJobSteps}, res.Limits)
+}
+
+func TestRest_LimitsValidation(t *testing.T) {
+	srv, ts := prepTestServer(t)
+	srv.Limits = solve.Limits{MaxSteps: 100, MaxWidth: 10, MaxBatch: 1}
+
+	tbl := []struct {
+		name  string
+		body  string
+		field string
+		msg   string
+	}{
+		{
+			name:  "steps",
+			body:  `{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 101, "methods": ["euler"]}`,
+			field: "n", msg: "must be between 1 and max_steps=100, got 101",
+		},
+		{
+			name:  "step",
+			body:  `{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "step": 0.005, "methods": ["euler"]}`,
+			field: "step", msg: "gives 200 steps, more than max_steps=100",
+		},
+		{
+			name:  "width",
+			body:  `{"f": "x", "x0": -10, "y0": 1, "x_end": 5, "n": 10, "methods": ["euler"]}`,
+			field: "x_end", msg: "gives the interval of width 15, more than max_width=10",
+		},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			res := rest.ErrorResponse{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+			assert.Equal(t, rest.ValidationError{{Field: tt.field, Msg: tt.msg}}, res.Errors)
+		})
+	}
+
+	item := `{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"]}`
+	resp, err := http.Post(ts.URL+"/api/v1/solve/batch", "application/json", strings.NewReader("["+item+","+item+"]"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	res := rest.ErrorResponse{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.Contains(t, res.Error, "batch contains 2 problems, more than max_batch=1")
+}
+
+func TestRest_LimitsPoints(t *testing.T) {
+	srv, ts := prepTestServer(t)
+	srv.Limits.MaxPoints = 11
+	body := `{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 1000, "methods": ["euler", "rk4", "exact"], "exact": "x*x/2 + c", "c": "y0"}`
+
+	resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Lines, 3)
+	for _, line := range res.Lines {
+		assert.True(t, len(line.Points) <= 11, "%s has %d points", line.Method, len(line.Points))
+		assert.True(t, len(line.Points) >= 10, "%s has %d points", line.Method, len(line.Points))
+		assert.Equal(t, 0.0, line.Points[0].X)
+		assert.InDelta(t, 1, line.Points[len(line.Points)-1].X, 0.01, "the end of the line must be kept")
+	}
+
+	// the stream is downsampled as well
+	sresp, err := http.Get(ts.URL + "/api/v1/solve/stream?f=x&x0=0&y0=1&x1=1&n=1000&method=euler")
+	require.NoError(t, err)
+	defer sresp.Body.Close()
+	points, last := 0, solve.PointEvent{}
+	for ev := range readEvents(t, sresp) {
+		if ev.name == "point" {
+			points++
+			require.NoError(t, json.Unmarshal([]byte(ev.data), &last))
+		}
+	}
+	assert.True(t, points <= 11, "stream has %d points", points)
+	assert.InDelta(t, 1, last.X, 0.01, "the end of the line must be kept")
+
+	// the websocket frames are downsampled as well
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/api/v1/ws", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"id": "1", "request": `+body+`}`)))
+	for {
+		f := wsFrame{}
+		require.NoError(t, conn.ReadJSON(&f))
+		if f.Type == wsPoints {
+			continue
+		}
+		require.Equal(t, wsDone, f.Type)
+		require.Len(t, f.Lines, 3)
+		for _, line := range f.Lines {
+			assert.True(t, line.Points <= 11, "%s has %d points", line.Method, line.Points)
+		}
+		break
+	}
+}
+
+func TestRest_LimitsConcurrent(t *testing.T) {
+	solver.Register("sleepy", func(solver.Func) solver.Interface { return &solver.Euler{F: sleepyFunc(5 * time.Millisecond)} })
+	defer solver.Unregister("sleepy")
+
+	srv := &Rest{Version: "test", NumService: &service.Service{}, Store: &store.Memory{},
+		Limits: solve.Limits{MaxConcurrent: 2}}
+	ts := httptest.NewServer(srv.routes())
+	defer ts.Close()
+
+	// each request takes two slots of the semaphore, so only one of them is solved at once
+	const n = 8
+	var wg sync.WaitGroup
+	resps := make(chan *http.Response, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(
+				`{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 20, "methods": ["sleepy", "euler"]}`))
+			if !assert.NoError(t, err) {
+				return
+			}
+			resp.Body.Close()
+			resps <- resp
+		}()
+	}
+	wg.Wait()
+	close(resps)
+
+	ok, busy := 0, 0
+	for resp := range resps {
+		switch resp.StatusCode {
+		case http.StatusOK:
+			ok++
+		case http.StatusServiceUnavailable:
+			busy++
+			assert.Equal(t, "1", resp.Header.Get("Retry-After"))
+		default:
+			t.Errorf("unexpected status %d", resp.StatusCode)
+		}
+	}
+	assert.True(t, ok >= 1, "at least one request must be solved")
+	assert.True(t, busy >= 1, "concurrent requests must be rejected")
+	assert.Equal(t, n, ok+busy)
+
+	// the slots are released after the solves
+	resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(
+		`{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 20, "methods": ["euler", "rk4", "ieuler"]}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "the request with more lines than the limit is solved alone")
+}
+
+func TestRest_LimitsConcurrentBusy(t *testing.T) {
+	srv := &Rest{Version: "test", NumService: &service.Service{}, Store: &store.Memory{},
+		Limits: solve.Limits{MaxConcurrent: 1}}
+	ts := httptest.NewServer(srv.routes())
+	defer ts.Close()
+	require.True(t, srv.solving.TryAcquire(1))
+
+	resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(
+		`{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 20, "methods": ["euler"]}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "1", resp.Header.Get("Retry-After"))
+	res := rest.ErrorResponse{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.Equal(t, rest.ErrBusy, res.Code)
+	assert.Contains(t, res.Error, "max_concurrent=1")
+
+	item := `{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"]}`
+	bresp, err := http.Post(ts.URL+"/api/v1/solve/batch", "application/json", strings.NewReader("["+item+"]"))
+	require.NoError(t, err)
+	defer bresp.Body.Close()
+	require.Equal(t, http.StatusOK, bresp.StatusCode)
+	bres := batchResp{}
+	require.NoError(t, json.NewDecoder(bresp.Body).Decode(&bres))
+	require.Len(t, bres.Results, 1)
+	require.NotNil(t, bres.Results[0].Error)
+	assert.Equal(t, rest.ErrBusy, bres.Results[0].Error.Code)
+
+	sresp, err := http.Get(ts.URL + "/api/v1/solve/stream?f=x&x0=0&y0=1&x1=1&n=10&method=euler")
+	require.NoError(t, err)
+	defer sresp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, sresp.StatusCode)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/api/v1/ws", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"id": "1", "request": `+item+`}`)))
+	f := wsFrame{}
+	require.NoError(t, conn.ReadJSON(&f))
+	assert.Equal(t, wsError, f.Type)
+	require.NotNil(t, f.Error)
+	assert.Equal(t, rest.ErrBusy, f.Error.Code)
+
+	srv.solving.Release(1)
+	resp, err = http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(
+		`{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 20, "methods": ["euler"]}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"github.com/Semior001/decompract/app/report"
+	"github.com/Semior001/decompract/app/solve"
+	"net/http"
+	"runtime"
+
+	"github.com/Semior001/decompract/app/rest"
+	log "github.com/go-pkgz/lgr"
+	"github.com/pkg/errors"
+)
+
+// GET /api/v1/compare/all?preset=canonical&method=euler&method=rk4&n=100&format=json|csv|markdown - compare
+// each of requested or all methods on each of requested or all presets with n steps or steps of presets
+func (s *Rest) compareAllCtrl(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	format := q.Get("format")
+	switch format {
+	case "", report.FormatJSON, report.FormatCSV, report.FormatMarkdown:
+	default:
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, errors.Errorf("unknown format %q", format),
+			"format must be json, csv or markdown", rest.ErrBadRequest)
+		return
+	}
+
+	probs, err := solve.PresetProblems(q["preset"]...)
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid compare request", rest.ErrBadRequest)
+		return
+	}
+	l := s.limits()
+	n, err := queryInt(r, "n", 0)
+	if err == nil && n > l.MaxSteps {
+		err = errors.Errorf("n must be at most max_steps=%d, got %d", l.MaxSteps, n)
+	}
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid compare request", rest.ErrBadRequest)
+		return
+	}
+
+	// the matrix holds the slots of its workers, each of them solves the single line at once
+	workers := runtime.GOMAXPROCS(0)
+	if l.MaxConcurrent > 0 && l.MaxConcurrent < workers {
+		workers = l.MaxConcurrent
+	}
+	release, err := s.acquireLines(workers)
+	if err != nil {
+		var be *busyError
+		if errors.As(err, &be) {
+			sendBusy(w, r, be)
+			return
+		}
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "failed to compare methods", rest.ErrInternal)
+		return
+	}
+	defer release()
+
+	m, err := solve.CompareAllUnder(r.Context(), probs, q["method"], n, l, workers)
+	if err != nil {
+		var te *solve.TimeoutError
+		if errors.As(err, &te) {
+			s.sendTimeout(w, r, te)
+			return
+		}
+		if r.Context().Err() == nil {
+			rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid compare request", rest.ErrBadRequest)
+			return
+		}
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "failed to compare methods", rest.ErrInternal)
+		return
+	}
+
+	switch format {
+	case report.FormatCSV, report.FormatMarkdown:
+		ct := "text/csv; charset=utf-8"
+		if format == report.FormatMarkdown {
+			ct = "text/markdown; charset=utf-8"
+		}
+		w.Header().Set("Content-Type", ct)
+		if err = m.Write(w, report.Output{Format: format}); err != nil {
+			log.Printf("[WARN] failed to write %s response, %v", format, err)
+		}
+	default:
+		rest.RenderJSON(w, r, m)
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRest_CompareAll(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/api/v1/compare/all?preset=canonical&method=euler&method=rk4&n=10")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	m := solve.Matrix{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&m))
+	assert.Equal(t, 10, m.N)
+	assert.Equal(t, []string{"euler", "rk4"}, m.Methods)
+	assert.Equal(t, []string{"canonical"}, m.Problems)
+	require.Len(t, m.Cells, 2)
+	require.Len(t, m.Cells[0], 1)
+	assert.Less(t, m.Cells[1][0].MaxGTE, m.Cells[0][0].MaxGTE)
+
+	resp, err = http.Get(ts.URL + "/api/v1/compare/all?n=10&format=markdown")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/markdown; charset=utf-8", resp.Header.Get("Content-Type"))
+	b, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(b)), "\n"), 2+len(solver.Methods()),
+		"all methods on all presets")
+
+	for _, q := range []string{"preset=unknown", "n=10001", "n=ten", "method=rk5", "format=png"} {
+		resp, err = http.Get(ts.URL + "/api/v1/compare/all?" + q)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, q)
+	}
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// types of solve errors in metrics
+const (
+	errTypeFormula      = "formula"        // formula can't be parsed or evaluated
+	errTypeTooManySteps = "too_many_steps" // requested number of steps exceeds the limit
+	errTypeBlowUp       = "blow_up"        // solution reached non-finite values
+	errTypeInvalid      = "invalid"        // any other invalid field of the request
+)
+
+// metrics collects the statistics of requests and solves in its own registry
+type metrics struct {
+	registry *prometheus.Registry
+
+	requestDuration *prometheus.HistogramVec
+	solves          *prometheus.CounterVec
+	solveDuration   *prometheus.HistogramVec
+	points          *prometheus.CounterVec
+	solveErrors     *prometheus.CounterVec
+	cache           *prometheus.CounterVec
+}
+
+func newMetrics() *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "decompract_http_request_duration_seconds",
+			Help:    "Duration of HTTP requests by route and status",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		solves: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "decompract_solves_total",
+			Help: "Number of solves by method",
+		}, []string{"method"}),
+		solveDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "decompract_solve_duration_seconds",
+			Help:    "Duration of solves by method",
+			Buckets: prometheus.ExponentialBuckets(0.0001, 4, 10), // from 100µs to 26s
+		}, []string{"method"}),
+		points: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "decompract_solve_points_total",
+			Help: "Number of calculated points by method",
+		}, []string{"method"}),
+		solveErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "decompract_solve_errors_total",
+			Help: "Number of failed or invalid solves by the type of error",
+		}, []string{"type"}),
+		cache: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "decompract_cache_requests_total",
+			Help: "Number of lookups of solutions in the cache by the result",
+		}, []string{"result"}),
+	}
+	m.registry.MustRegister(m.requestDuration, m.solves, m.solveDuration, m.points, m.solveErrors, m.cache)
+	return m
+}
+
+// handler exposes the metrics in the prometheus format
+func (m *metrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// middleware measures the duration of requests by the route pattern
+func (m *metrics) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		st := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route := "unknown"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		m.requestDuration.WithLabelValues(route, r.Method, strconv.Itoa(status)).Observe(time.Since(st).Seconds())
+	})
+}
+
+// invalid counts the errors of the invalid request by their types
+func (m *metrics) invalid(req solve.Request, maxSteps int, err error) {
+	var ve rest.ValidationError
+	if !errors.As(err, &ve) {
+		m.solveErrors.WithLabelValues(errTypeInvalid).Inc()
+		return
+	}
+	types := map[string]bool{}
+	if req.Steps() > float64(maxSteps) {
+		types[errTypeTooManySteps] = true
+	}
+	for _, fe := range ve {
+		switch fe.Field {
+		case "f", "exact", "c", "dfdy", "linear":
+			types[errTypeFormula] = true
+		case "n", "step":
+			if !types[errTypeTooManySteps] {
+				types[errTypeInvalid] = true
+			}
+		default:
+			types[errTypeInvalid] = true
+		}
+	}
+	for typ := range types {
+		m.solveErrors.WithLabelValues(typ).Inc()
+	}
+}
+
+// instrument wraps the solver to collect its statistics, the resumer stays the resumer
+func (m *metrics) instrument(method string, slvr solver.Interface) solver.Interface {
+	is := &instrumentedSolver{Interface: slvr, method: method, m: m}
+	if r, ok := slvr.(solver.Resumer); ok {
+		return &instrumentedResumer{instrumentedSolver: is, r: r}
+	}
+	return is
+}
+
+// instrumentedSolver counts solves, calculated points and errors of the wrapped solver
+type instrumentedSolver struct {
+	solver.Interface
+	method string
+	m      *metrics
+}
+
+// Solve solves the problem with the wrapped solver and collects its statistics
+func (is *instrumentedSolver) Solve(stepSize, x0, y0, xEnd float64, d solver.Drawer) error {
+	return is.observe(d, func(d solver.Drawer) error { return is.Interface.Solve(stepSize, x0, y0, xEnd, d) })
+}
+
+// observe counts the solve, that draws to d, its duration, points and errors
+func (is *instrumentedSolver) observe(d solver.Drawer, run func(d solver.Drawer) error) error {
+	is.m.solves.WithLabelValues(is.method).Inc()
+	st := time.Now()
+	defer func() { is.m.solveDuration.WithLabelValues(is.method).Observe(time.Since(st).Seconds()) }()
+
+	var drawErr error
+	blowUp := false
+	points := is.m.points.WithLabelValues(is.method) // the lookup of the counter is not repeated for each point
+	err := run(solver.WithObserver(d, func(p num.Point, err error) {
+		drawErr = err
+		points.Inc()
+		if !blowUp && (!solve.IsFinite(p.X) || !solve.IsFinite(p.Y)) {
+			blowUp = true
+			is.m.solveErrors.WithLabelValues(errTypeBlowUp).Inc()
+		}
+	}))
+	// drawer's own errors, e.g. cancelled context, are not the errors of the solver
+	switch {
+	case err != nil && drawErr == nil && errors.Is(err, solver.ErrNonFinite):
+		// f met the singularity, the solution would reach it at the next point
+		if !blowUp {
+			is.m.solveErrors.WithLabelValues(errTypeBlowUp).Inc()
+		}
+	case err != nil && drawErr == nil:
+		is.m.solveErrors.WithLabelValues(errTypeFormula).Inc()
+	}
+	return err
+}
+
+// instrumentedResumer counts resumed solutions as well as the solutions from x0
+type instrumentedResumer struct {
+	*instrumentedSolver
+	r solver.Resumer
+}
+
+// Resume resumes the solution with the wrapped solver and collects its statistics
+func (ir *instrumentedResumer) Resume(cp solver.Checkpoint, xEnd float64, d solver.Drawer) error {
+	return ir.observe(d, func(d solver.Drawer) error { return ir.r.Resume(cp, xEnd, d) })
+}
+
+// cacheLookup counts the result of the lookup in the cache
+func (m *metrics) cacheLookup(hit bool) {
+	res := "miss"
+	if hit {
+		res = "hit"
+	}
+	m.cache.WithLabelValues(res).Inc()
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRest_Metrics(t *testing.T) {
+	breakMethod(t)
+	srv := &Rest{Version: "test", NumService: &service.Service{}, CacheSize: 10}
+	ts := httptest.NewServer(srv.routes())
+	defer ts.Close()
+
+	post := func(body string, status int) {
+		resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, status, resp.StatusCode, body)
+	}
+
+	ok := `{"f": "x**2 - 2*y", "x0": 0, "y0": 1, "x_end": 1, "step": 0.25, "methods": ["rk4", "euler"]}`
+	post(ok, http.StatusOK)
+	post(ok, http.StatusOK) // served from cache
+	post(`{"f": "x +* y", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"]}`, http.StatusBadRequest)
+	post(`{"linear": {"p": "1 +", "q": "x"}, "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"]}`, http.StatusBadRequest)
+	post(`{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 100000, "methods": ["rk4"]}`, http.StatusBadRequest)
+	post(`{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["broken"]}`, http.StatusInternalServerError)
+
+	// the blow-up fails the method at the singularity of f
+	resp, err := http.Get(ts.URL + "/api/v1/solve?f=1/(1-x)&x0=0&y0=1&x1=2&n=2&method=euler&format=csv")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	resp, err = http.Get(ts.URL + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	b, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	body := string(b)
+
+	for _, line := range []string{
+		`decompract_http_request_duration_seconds_count{method="POST",route="/api/v1/solve",status="200"} 2`,
+		`decompract_http_request_duration_seconds_count{method="GET",route="/api/v1/solve",status="500"} 1`,
+		`decompract_http_request_duration_seconds_count{method="POST",route="/api/v1/solve",status="400"} 3`,
+		`decompract_http_request_duration_seconds_count{method="POST",route="/api/v1/solve",status="500"} 1`,
+		`decompract_solves_total{method="rk4"} 1`,
+		`decompract_solves_total{method="euler"} 2`,
+		`decompract_solves_total{method="broken"} 1`,
+		`decompract_solve_duration_seconds_count{method="rk4"} 1`,
+		`decompract_solve_duration_seconds_count{method="euler"} 2`,
+		`decompract_solve_duration_seconds_count{method="broken"} 1`,
+		`decompract_solve_points_total{method="rk4"} 5`,
+		`decompract_solve_points_total{method="euler"} 7`,
+		`decompract_solve_points_total{method="broken"} 7`,
+		`decompract_solve_errors_total{type="formula"} 3`,
+		`decompract_solve_errors_total{type="too_many_steps"} 1`,
+		`decompract_solve_errors_total{type="blow_up"} 1`,
+		`decompract_cache_requests_total{result="hit"} 1`,
+		`decompract_cache_requests_total{result="miss"} 3`,
+	} {
+		assert.Contains(t, body, line+"\n")
+	}
+}
@@ -0,0 +1,1042 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/Semior001/decompract/app/report"
+	"github.com/Semior001/decompract/app/solve"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/field"
+	"github.com/Semior001/decompract/app/num/interp"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/go-chi/render"
+	"github.com/pkg/errors"
+)
+
+// openAPIDoc is the OpenAPI 3 document, describing the API
+type openAPIDoc struct {
+	OpenAPI    string                 `json:"openapi"`
+	Info       openAPIInfo            `json:"info"`
+	Paths      map[string]openAPIPath `json:"paths"`
+	Components openAPIComponents      `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type openAPIComponents struct {
+	Schemas         map[string]*jsonSchema           `json:"schemas"`
+	SecuritySchemes map[string]openAPISecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type openAPISecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme,omitempty"`
+	In     string `json:"in,omitempty"`
+	Name   string `json:"name,omitempty"`
+}
+
+// openAPIPath maps lowercase http methods to the operations
+type openAPIPath map[string]*openAPIOperation
+
+type openAPIOperation struct {
+	Summary     string                     `json:"summary"`
+	Description string                     `json:"description,omitempty"`
+	OperationID string                     `json:"operationId"`
+	Parameters  []openAPIParam             `json:"parameters,omitempty"`
+	RequestBody *openAPIBody               `json:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+	Security    []map[string][]string      `json:"security,omitempty"`
+}
+
+type openAPIParam struct {
+	Name        string      `json:"name"`
+	In          string      `json:"in"`
+	Description string      `json:"description,omitempty"`
+	Required    bool        `json:"required,omitempty"`
+	Schema      *jsonSchema `json:"schema"`
+	Example     interface{} `json:"example,omitempty"`
+}
+
+type openAPIBody struct {
+	Required bool                    `json:"required"`
+	Content  map[string]openAPIMedia `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                  `json:"description"`
+	Content     map[string]openAPIMedia `json:"content,omitempty"`
+}
+
+type openAPIMedia struct {
+	Schema  *jsonSchema `json:"schema"`
+	Example interface{} `json:"example,omitempty"`
+	// Events lists the schemas of data of server-sent events by their names
+	Events map[string]*jsonSchema `json:"x-events,omitempty"`
+}
+
+// jsonSchema is the subset of OpenAPI schema object, used to describe the API
+type jsonSchema struct {
+	Ref        string                 `json:"$ref,omitempty"`
+	Type       string                 `json:"type,omitempty"`
+	Format     string                 `json:"format,omitempty"`
+	Properties map[string]*jsonSchema `json:"properties,omitempty"`
+	Required   []string               `json:"required,omitempty"`
+	Items      *jsonSchema            `json:"items,omitempty"`
+	// AdditionalProperties describes values of the object with arbitrary keys
+	AdditionalProperties *jsonSchema   `json:"additionalProperties,omitempty"`
+	Enum                 []interface{} `json:"enum,omitempty"`
+}
+
+// schemaRegistry makes schemas of go types, named types are placed to components and referenced
+type schemaRegistry struct {
+	names   map[reflect.Type]string
+	schemas map[string]*jsonSchema
+}
+
+// register adds the schema of the type of v to components and returns the reference to it,
+// types must be registered before the types, that refer to them
+func (sr *schemaRegistry) register(name string, v interface{}) *jsonSchema {
+	t := reflect.TypeOf(v)
+	sr.names[t] = name
+	sr.schemas[name] = sr.structSchema(t)
+	return sr.ref(name)
+}
+
+func (sr *schemaRegistry) ref(name string) *jsonSchema {
+	return &jsonSchema{Ref: "#/components/schemas/" + name}
+}
+
+// schemaOf returns the schema of the type, as the json encoder represents it
+func (sr *schemaRegistry) schemaOf(t reflect.Type) *jsonSchema {
+	if name, ok := sr.names[t]; ok {
+		return sr.ref(name)
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return sr.schemaOf(t.Elem())
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &jsonSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &jsonSchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &jsonSchema{Type: "array", Items: sr.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &jsonSchema{Type: "object", AdditionalProperties: sr.schemaOf(t.Elem())}
+	case reflect.Struct:
+		return sr.structSchema(t)
+	default:
+		return &jsonSchema{}
+	}
+}
+
+// structSchema returns the object schema with the exported fields of the struct,
+// fields without omitempty option are required
+func (sr *schemaRegistry) structSchema(t reflect.Type) *jsonSchema {
+	res := &jsonSchema{Type: "object", Properties: map[string]*jsonSchema{}}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		tag := strings.Split(f.Tag.Get("json"), ",")
+		name := tag[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" && f.Anonymous && f.Type.Kind() == reflect.Struct {
+			// fields of embedded structs are encoded as the fields of the outer struct
+			embedded := sr.structSchema(f.Type)
+			for pname, ps := range embedded.Properties {
+				res.Properties[pname] = ps
+			}
+			res.Required = append(res.Required, embedded.Required...)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		res.Properties[name] = sr.schemaOf(f.Type)
+
+		omitempty := false
+		for _, opt := range tag[1:] {
+			omitempty = omitempty || opt == "omitempty"
+		}
+		if !omitempty {
+			res.Required = append(res.Required, name)
+		}
+	}
+	return res
+}
+
+// exampleSolveReq is the example of the solve request in the documentation
+var exampleSolveReq = solve.Request{
+	F:       "y*y*exp(x) - 2*y",
+	Exact:   "exp(-x) / (c*exp(x) + 1)",
+	C:       "(exp(-x0) - y0) / (y0 * exp(x0))",
+	X0:      0,
+	Y0:      1,
+	XEnd:    1,
+	N:       10,
+	Methods: []string{"euler", "rk4"},
+}
+
+// openAPI builds the description of the API from the types of requests and responses
+func (s *Rest) openAPI() openAPIDoc {
+	sr := &schemaRegistry{names: map[reflect.Type]string{}, schemas: map[string]*jsonSchema{}}
+
+	sr.register("FieldError", rest.FieldError{})
+	errResp := sr.register("Error", rest.ErrorResponse{})
+	problemResp := sr.register("Problem", rest.ProblemResponse{})
+	timeoutRef := sr.register("Timeout", timeoutResp{})
+	// ErrCode is the named integer, so it is described by enum
+	for _, name := range []string{"Error", "Problem"} {
+		sr.schemas[name].Properties["code"].Enum = []interface{}{
+			rest.ErrInternal, rest.ErrDecode, rest.ErrBadRequest, rest.ErrNotFound, rest.ErrUnauthorized, rest.ErrForbidden, rest.ErrBusy,
+		}
+	}
+
+	solveReqRef := sr.register("SolveRequest", solve.Request{})
+	sr.schemas["SolveRequest"].Properties["methods"].Items.Enum = stringEnum(append(solver.Methods(), solve.ExactMethod))
+	sr.register("Output", report.Output{})
+	problemDocRef := sr.register("ProblemDocument", solve.Document{})
+	sr.register("Point", num.Point{})
+	sr.register("Line", solve.Line{})
+	solveRespRef := sr.register("SolveResponse", solve.Response{})
+	sr.register("BatchItem", solve.Item{})
+	batchRespRef := sr.register("BatchResponse", batchResp{})
+	sr.register("Segment", field.Segment{})
+	pointEventRef := sr.register("PointEvent", solve.PointEvent{})
+	sr.register("LineSummary", solve.LineSummary{})
+	streamSummaryRef := sr.register("StreamSummary", solve.StreamSummary{})
+	streamErrorRef := sr.register("StreamError", streamError{})
+	sr.register("WSRequest", wsRequest{})
+	wsFrameRef := sr.register("WSFrame", wsFrame{})
+	sr.schemas["WSFrame"].Properties["type"].Enum = stringEnum([]string{wsPoints, wsDone, wsError, wsCancelled})
+	sr.register("Method", methodInfo{})
+	infoRef := sr.register("Info", infoResp{})
+	presetRef := sr.register("Preset", presetResp{})
+	sr.register("ErrorTable", solve.ErrorTable{})
+	resultRef := sr.register("Result", resultResp{})
+	sr.register("HistoryEntry", historyEntry{})
+	sr.register("GTEPoint", solve.GTEPoint{})
+	sr.register("GTEFailure", solve.GTEFailure{})
+	sr.register("GTELine", solve.GTELine{})
+	errorsRespRef := sr.register("ErrorsResponse", solve.ErrorsResp{})
+	historyRef := sr.register("History", historyResp{})
+	sr.register("RunLine", runLine{})
+	sr.register("RunSummary", runSummary{})
+	sr.register("Run", runResp{})
+	runsRef := sr.register("Runs", runsResp{})
+	shareRef := sr.register("Share", shareResp{})
+	jobRef := sr.register("Job", jobResp{})
+	setAddReqRef := sr.register("SetAddRequest", setAddReq{})
+	sr.register("SetLine", setLine{})
+	sr.register("SetEntry", setEntry{})
+	setRef := sr.register("Set", setResp{})
+	sr.register("CompareRow", solve.CompareRow{})
+	compareRespRef := sr.register("CompareResponse", solve.CompareResp{})
+	valueRespRef := sr.register("ValueResponse", valueResp{})
+	sr.register("MatrixCell", solve.MatrixCell{})
+	matrixRef := sr.register("Matrix", solve.Matrix{})
+	integrateRespRef := sr.register("IntegrateResponse", integrateResp{})
+	derivativeRespRef := sr.register("DerivativeResponse", derivativeResp{})
+	adviseRespRef := sr.register("AdviseResponse", adviseResp{})
+	phaseRespRef := sr.register("PhaseResponse", phaseResp{})
+	stabilityRespRef := sr.register("StabilityResponse", stabilityResp{})
+	paramSweepReqRef := sr.register("SweepRequest", paramSweepReq{})
+	sr.register("SweepLine", paramLine{})
+	paramSweepRespRef := sr.register("SweepResponse", paramSweepResp{})
+	gridSweepReqRef := sr.register("GridSweepRequest", gridSweepReq{})
+	gridSweepRespRef := sr.register("GridSweepResponse", gridSweepResp{})
+	higherReqRef := sr.register("HigherRequest", higherReq{})
+	sr.register("Component", component{})
+	sr.register("HigherLine", higherLine{})
+	higherRespRef := sr.register("HigherResponse", higherResp{})
+
+	jsonErr := func(descr string) openAPIResponse {
+		// problem details of RFC 7807 are responded to clients, that accept application/problem+json
+		return openAPIResponse{Description: descr, Content: map[string]openAPIMedia{"application/json": {Schema: errResp},
+			"application/problem+json": {Schema: problemResp}}}
+	}
+	jsonResp := func(descr string, schema *jsonSchema) openAPIResponse {
+		return openAPIResponse{Description: descr, Content: map[string]openAPIMedia{"application/json": {Schema: schema}}}
+	}
+	solveErrors := func(responses map[string]openAPIResponse) map[string]openAPIResponse {
+		responses["400"] = jsonErr("invalid request")
+		responses["429"] = jsonErr("too many requests")
+		responses["500"] = jsonErr("failed to solve")
+		responses["503"] = jsonErr("too many solves at once, retry after the delay in Retry-After header")
+		responses["504"] = jsonResp("solve is not finished in time", timeoutRef)
+		return responses
+	}
+
+	solveParams := solveQueryParams()
+	getSolveParams := append(append([]openAPIParam{}, solveParams...), openAPIParam{
+		Name: "format", In: "query", Description: "format of the response",
+		Schema: &jsonSchema{Type: "string", Enum: []interface{}{"json", "csv"}}, Example: "json",
+	}, openAPIParam{
+		Name: "sensitivity", In: "query", Description: "add dy/dy0 to lines of methods", Schema: &jsonSchema{Type: "boolean"},
+	}, openAPIParam{
+		Name: "dfdy", In: "query", Description: "df/dy(x,y), the sensitivity is found by the variational equation, if it is set",
+		Schema: &jsonSchema{Type: "string"},
+	}, openAPIParam{
+		Name: "estimates", In: "query", Description: "add estimates of local errors of steps by the step doubling",
+		Schema: &jsonSchema{Type: "boolean"},
+	}, openAPIParam{
+		Name: "errors", In: "query", Description: "add local errors of methods against the exact solution, which is required then",
+		Schema: &jsonSchema{Type: "boolean"},
+	}, openAPIParam{
+		Name: "output_points", In: "query", Description: "give lines at the number of evenly spaced x from x0 to x1, " +
+			"interpolated between internal steps of methods", Schema: &jsonSchema{Type: "integer"}, Example: 11,
+	}, openAPIParam{
+		Name: "output_x", In: "query", Description: "give lines at x, going from x0 to x1, repeatable or comma separated, " +
+			"instead of output_points", Schema: &jsonSchema{Type: "number"},
+	}, openAPIParam{
+		Name: "output_interp", In: "query", Description: "interpolation of lines at the output grid, cubic by default",
+		Schema: &jsonSchema{Type: "string", Enum: []interface{}{interp.KindLinear, interp.KindCubic, interp.KindHermite}},
+	})
+	var errorsParams []openAPIParam
+	for _, p := range solveParams {
+		if p.Name != "n" && p.Name != "step" {
+			errorsParams = append(errorsParams, p)
+		}
+	}
+	errorsParams = append(errorsParams,
+		openAPIParam{Name: "n0", In: "query", Description: "the least number of steps", Schema: &jsonSchema{Type: "integer"},
+			Example: defaultSweepN0},
+		openAPIParam{Name: "n1", In: "query", Description: "the largest number of steps", Schema: &jsonSchema{Type: "integer"},
+			Example: 50},
+	)
+	convergenceParams := []openAPIParam{
+		{Name: "n0", In: "query", Description: "the least number of steps of the convergence",
+			Schema: &jsonSchema{Type: "integer"}, Example: defaultSweepN0},
+		{Name: "n1", In: "query", Description: "the largest number of steps of the convergence",
+			Schema: &jsonSchema{Type: "integer"}, Example: defaultSweepN1},
+	}
+	reportParams := append(append(solveQueryParams(),
+		openAPIParam{Name: "format", In: "query", Description: "format of the report",
+			Schema: &jsonSchema{Type: "string", Enum: []interface{}{"md", "tex"}}, Example: "md"}),
+		convergenceParams...)
+	exportParams := append(solveQueryParams(), convergenceParams...)
+	valueParams := append(solveQueryParams(), openAPIParam{
+		Name: "x", In: "query", Description: "x within the interval to evaluate solutions at, repeatable, up to " +
+			strconv.Itoa(maxValueXs), Required: true,
+		Schema: &jsonSchema{Type: "array", Items: &jsonSchema{Type: "number"}}, Example: []float64{0.25, 0.5},
+	}, openAPIParam{
+		Name: "interp", In: "query", Description: "interpolation between points of lines, hermite takes slopes f(x,y) at points",
+		Schema: &jsonSchema{Type: "string", Enum: []interface{}{"linear", "cubic", "hermite"}}, Example: "cubic",
+	})
+	var compareParams []openAPIParam
+	for _, p := range solveParams {
+		if p.Name == "method" {
+			p.Required = false
+			p.Description += ", all methods are compared, if not set"
+		}
+		compareParams = append(compareParams, p)
+	}
+	resultIDParam := openAPIParam{Name: "id", In: "path", Description: "id of the saved result", Required: true,
+		Schema: &jsonSchema{Type: "string"}}
+	setNameParam := openAPIParam{Name: "name", In: "path", Description: "name of the comparison set, up to 64 letters, " +
+		"digits, dots, dashes or underscores", Required: true, Schema: &jsonSchema{Type: "string"}}
+	sizeSchema := &jsonSchema{Type: "integer"}
+	chartParams := append(append([]openAPIParam{}, solveParams...),
+		openAPIParam{Name: "width", In: "query", Description: "width of the image in pixels, up to " +
+			strconv.Itoa(maxChartSize), Schema: sizeSchema, Example: 400},
+		openAPIParam{Name: "height", In: "query", Description: "height of the image in pixels, up to " +
+			strconv.Itoa(maxChartSize), Schema: sizeSchema, Example: 300},
+		openAPIParam{Name: "format", In: "query", Description: "format of the image",
+			Schema: &jsonSchema{Type: "string", Enum: []interface{}{"png", "svg"}}, Example: "png"},
+		openAPIParam{Name: "thin", In: "query", Description: "thin long lines by curvature, true by default",
+			Schema: &jsonSchema{Type: "boolean"}, Example: true},
+		openAPIParam{Name: "errors", In: "query", Description: "add the subplot of errors of methods against " +
+			"the exact solution, which is required then", Schema: &jsonSchema{Type: "boolean"}, Example: false},
+		openAPIParam{Name: "field", In: "query", Description: "nodes of the slope field by each axis, drawn under " +
+			"solutions over the interval and the range of their values, up to " + strconv.Itoa(maxFieldNodes) +
+			", the field is not drawn, if it is zero", Schema: &jsonSchema{Type: "integer"}, Example: 0},
+	)
+	// the plot is always png, so it takes parameters of the chart but the format
+	plotParams := append(append([]openAPIParam{}, chartParams[:len(solveParams)+2]...), chartParams[len(solveParams)+3:]...)
+	compareParams = append(compareParams, chartParams[len(solveParams):len(solveParams)+2]...)
+	compareParams = append(compareParams, openAPIParam{Name: "format", In: "query",
+		Description: "format of the report, png and svg render the chart of errors",
+		Schema:      &jsonSchema{Type: "string", Enum: []interface{}{"json", "csv", "png", "svg"}}, Example: "json"})
+
+	// the websocket is served at both paths, operations differ by ids only
+	wsOp := func(id string) *openAPIOperation {
+		return &openAPIOperation{
+			Summary: "Solve problems interactively over websocket",
+			Description: "The client sends WSRequest messages, the server replies with WSFrame messages, " +
+				"tagged with the id of the request. The newer request cancels the one in flight.",
+			OperationID: id,
+			Responses: map[string]openAPIResponse{
+				"101": {
+					Description: "switching to websocket, the frames of responses to requests",
+					Content:     map[string]openAPIMedia{"application/json": {Schema: wsFrameRef}},
+				},
+				"429": jsonErr("too many requests"),
+			},
+		}
+	}
+
+	doc := openAPIDoc{
+		OpenAPI: "3.0.3",
+		Info: openAPIInfo{
+			Title:       "DEComPract",
+			Description: "Numerical methods for solving the initial value problem",
+			Version:     s.Version,
+		},
+		Paths: map[string]openAPIPath{
+			"/ping": {"get": {
+				Summary:     "Check that the server is alive",
+				OperationID: "ping",
+				Responses: map[string]openAPIResponse{"200": {
+					Description: "pong",
+					Content:     map[string]openAPIMedia{"text/plain": {Schema: &jsonSchema{Type: "string"}}},
+				}},
+			}},
+			"/api/chart/field": {"get": {
+				Summary:     "Slope field of f(x,y)",
+				OperationID: "getSlopeField",
+				Parameters: []openAPIParam{
+					{Name: "f", In: "query", Description: "f(x,y) = y'", Required: true, Schema: &jsonSchema{Type: "string"}, Example: "y-x"},
+					{Name: "xmin", In: "query", Schema: &jsonSchema{Type: "number"}, Example: -1},
+					{Name: "xmax", In: "query", Schema: &jsonSchema{Type: "number"}, Example: 1},
+					{Name: "ymin", In: "query", Schema: &jsonSchema{Type: "number"}, Example: -1},
+					{Name: "ymax", In: "query", Schema: &jsonSchema{Type: "number"}, Example: 1},
+					{Name: "nx", In: "query", Description: "number of nodes by x", Schema: &jsonSchema{Type: "integer"}, Example: 5},
+					{Name: "ny", In: "query", Description: "number of nodes by y", Schema: &jsonSchema{Type: "integer"}, Example: 5},
+					{Name: "format", In: "query", Schema: &jsonSchema{Type: "string", Enum: []interface{}{"json", "png"}}, Example: "json"},
+				},
+				Responses: map[string]openAPIResponse{
+					"200": {Description: "segments of the slope field", Content: map[string]openAPIMedia{
+						"application/json": {Schema: sr.schemaOf(reflect.TypeOf([]field.Segment{}))},
+						"image/png":        {Schema: &jsonSchema{Type: "string", Format: "binary"}},
+					}},
+					"400": jsonErr("invalid request"),
+				},
+			}},
+			"/api/v1/solve": {
+				"post": {
+					Summary:     "Solve the initial value problem",
+					OperationID: "solve",
+					RequestBody: &openAPIBody{Required: true, Content: map[string]openAPIMedia{
+						// the problem document is decoded strictly in both formats, unknown fields are rejected
+						"application/json": {Schema: problemDocRef, Example: exampleSolveReq},
+						"application/yaml": {Schema: problemDocRef, Example: solve.Document{Request: exampleSolveReq}},
+					}},
+					Responses: solveErrors(map[string]openAPIResponse{"200": jsonResp("solutions", solveRespRef),
+						"413": jsonErr("request body is too large")}),
+				},
+				"get": {
+					Summary:     "Solve the initial value problem with parameters in query",
+					OperationID: "getSolve",
+					Parameters:  getSolveParams,
+					Responses: solveErrors(map[string]openAPIResponse{"200": {
+						Description: "solutions",
+						Content: map[string]openAPIMedia{
+							"application/json": {Schema: solveRespRef},
+							"text/csv":         {Schema: &jsonSchema{Type: "string"}},
+						},
+					}}),
+				},
+			},
+			"/api/v1/solve.csv": {"get": {
+				Summary:     "Download the solutions as csv file",
+				OperationID: "solveCSV",
+				Parameters:  solveParams,
+				Responses: solveErrors(map[string]openAPIResponse{"200": {
+					Description: "csv file with x column and y column for each method",
+					Content:     map[string]openAPIMedia{"text/csv": {Schema: &jsonSchema{Type: "string"}}},
+				}}),
+			}},
+			"/api/v1/chart": {"get": {
+				Summary:     "Render the chart of solutions",
+				OperationID: "getChart",
+				Parameters:  chartParams,
+				Responses: solveErrors(map[string]openAPIResponse{"200": {
+					Description: "chart of solutions",
+					Content: map[string]openAPIMedia{
+						"image/png":     {Schema: &jsonSchema{Type: "string", Format: "binary"}},
+						"image/svg+xml": {Schema: &jsonSchema{Type: "string"}},
+					},
+				}}),
+			}},
+			"/api/plot.png": {"get": {
+				Summary:     "Render the chart of solutions to png",
+				Description: "The same chart as GET /api/v1/chart with format=png, the url ends with the extension of the image.",
+				OperationID: "getPlotPNG",
+				Parameters:  plotParams,
+				Responses: solveErrors(map[string]openAPIResponse{"200": {
+					Description: "chart of solutions",
+					Content: map[string]openAPIMedia{
+						"image/png": {Schema: &jsonSchema{Type: "string", Format: "binary"}},
+					},
+				}}),
+			}},
+			"/api/v1/chart/phase": {"get": {
+				Summary: "Phase portrait of the system of two equations",
+				Description: "The system y1' = f1(t,y1,y2), y2' = f2(t,y1,y2) is solved by rk4 over [t0, t1] from the nx*ny grid " +
+					"of initial conditions, up to " + strconv.Itoa(maxPhaseSeeds) + " seeds, trajectories, that blow up, are skipped.",
+				OperationID: "getPhasePortrait",
+				Parameters: append([]openAPIParam{
+					{Name: "f1", In: "query", Description: "f1(t,y1,y2) = y1'", Required: true, Schema: &jsonSchema{Type: "string"}, Example: "y2"},
+					{Name: "f2", In: "query", Description: "f2(t,y1,y2) = y2'", Required: true, Schema: &jsonSchema{Type: "string"}, Example: "-y1"},
+					{Name: "t0", In: "query", Schema: &jsonSchema{Type: "number"}, Example: 0},
+					{Name: "t1", In: "query", Schema: &jsonSchema{Type: "number"}, Example: 6.28},
+					{Name: "n", In: "query", Description: "number of steps", Schema: &jsonSchema{Type: "integer"}, Example: 100},
+					{Name: "y1min", In: "query", Schema: &jsonSchema{Type: "number"}, Example: -1},
+					{Name: "y1max", In: "query", Schema: &jsonSchema{Type: "number"}, Example: 1},
+					{Name: "y2min", In: "query", Schema: &jsonSchema{Type: "number"}, Example: -1},
+					{Name: "y2max", In: "query", Schema: &jsonSchema{Type: "number"}, Example: 1},
+					{Name: "nx", In: "query", Description: "number of seeds by y1", Schema: &jsonSchema{Type: "integer"}, Example: 3},
+					{Name: "ny", In: "query", Description: "number of seeds by y2", Schema: &jsonSchema{Type: "integer"}, Example: 3},
+					{Name: "param", In: "query", Description: "named constant of formulas as name:value, repeatable",
+						Schema: &jsonSchema{Type: "array", Items: &jsonSchema{Type: "string"}}},
+				}, append(chartParams[len(solveParams):len(solveParams)+2:len(solveParams)+2], openAPIParam{
+					Name: "format", In: "query", Description: "format of the response, png and svg render the portrait",
+					Schema: &jsonSchema{Type: "string", Enum: []interface{}{"json", "png", "svg"}}, Example: "json",
+				})...),
+				Responses: map[string]openAPIResponse{
+					"200": {Description: "trajectories of the portrait", Content: map[string]openAPIMedia{
+						"application/json": {Schema: phaseRespRef},
+						"image/png":        {Schema: &jsonSchema{Type: "string", Format: "binary"}},
+						"image/svg+xml":    {Schema: &jsonSchema{Type: "string"}},
+					}},
+					"400": jsonErr("invalid request"),
+					"429": jsonErr("too many requests"),
+					"500": jsonErr("failed to solve the system"),
+				},
+			}},
+			"/api/v1/stability": {"get": {
+				Summary: "Linear stability regions of methods",
+				Description: "|R(hλ)| of the stability function of each method over the grid of the rectangle of the complex " +
+					"plane of hλ, the method is stable, where it doesn't exceed one. With h and lambda the step is checked " +
+					"against each region and warned, if it is unstable, while the solution decays.",
+				OperationID: "getStability",
+				Parameters: append([]openAPIParam{
+					{Name: "method", In: "query", Description: "method to draw, repeatable, all methods with stability functions " +
+						"by default", Schema: &jsonSchema{Type: "array", Items: &jsonSchema{Type: "string"}}},
+					{Name: "h", In: "query", Description: "step to check", Schema: &jsonSchema{Type: "number"}, Example: 0.05},
+					{Name: "lambda", In: "query", Description: "λ of the linearized problem, e.g. df/dy", Schema: &jsonSchema{Type: "number"},
+						Example: -50},
+					{Name: "lambda_im", In: "query", Description: "imaginary part of λ", Schema: &jsonSchema{Type: "number"}},
+					{Name: "re0", In: "query", Schema: &jsonSchema{Type: "number"}, Example: defaultStabilityRe0},
+					{Name: "re1", In: "query", Schema: &jsonSchema{Type: "number"}, Example: defaultStabilityRe1},
+					{Name: "im0", In: "query", Schema: &jsonSchema{Type: "number"}, Example: defaultStabilityIm0},
+					{Name: "im1", In: "query", Schema: &jsonSchema{Type: "number"}, Example: defaultStabilityIm1},
+					{Name: "n", In: "query", Description: "number of nodes by each axis, up to " + strconv.Itoa(maxStabilityNodes),
+						Schema: &jsonSchema{Type: "integer"}, Example: defaultStabilityNodes},
+				}, append(chartParams[len(solveParams):len(solveParams)+2:len(solveParams)+2], openAPIParam{
+					Name: "format", In: "query", Description: "format of the response, png and svg render boundaries of regions",
+					Schema: &jsonSchema{Type: "string", Enum: []interface{}{"json", "png", "svg"}}, Example: "json",
+				})...),
+				Responses: map[string]openAPIResponse{
+					"200": {Description: "stability regions of methods", Content: map[string]openAPIMedia{
+						"application/json": {Schema: stabilityRespRef},
+						"image/png":        {Schema: &jsonSchema{Type: "string", Format: "binary"}},
+						"image/svg+xml":    {Schema: &jsonSchema{Type: "string"}},
+					}},
+					"400": jsonErr("invalid request"),
+					"429": jsonErr("too many requests"),
+					"500": jsonErr("failed to compute regions"),
+				},
+			}},
+			"/api/v1/errors": {"get": {
+				Summary: "Global truncation errors of methods by the number of steps",
+				Description: "The problem is solved with each number of steps from n0 to n1, " +
+					"errors are measured against the exact solution, if it is given, or against the fine solution by rk4.",
+				OperationID: "getErrors",
+				Parameters:  errorsParams,
+				Responses:   solveErrors(map[string]openAPIResponse{"200": jsonResp("errors and orders of convergence", errorsRespRef)}),
+			}},
+			"/api/v1/report": {"get": {
+				Summary: "Download the report of the solution",
+				Description: "The report has the problem, tables and charts of solutions and local errors, " +
+					"and the convergence of methods with numbers of steps, doubled from n0 until n1.",
+				OperationID: "getReport",
+				Parameters:  reportParams,
+				Responses: solveErrors(map[string]openAPIResponse{"200": {
+					Description: "markdown document with embedded charts or LaTeX article with pgfplots charts",
+					Content: map[string]openAPIMedia{
+						"text/markdown":     {Schema: &jsonSchema{Type: "string"}},
+						"application/x-tex": {Schema: &jsonSchema{Type: "string"}},
+					},
+				}}),
+			}},
+			"/api/export.xlsx": {"get": {
+				Summary: "Download solutions and errors of methods as xlsx workbook",
+				Description: "The summary sheet has max local and global errors of methods and orders of convergence " +
+					"with numbers of steps, doubled from n0 until n1, the sheet of each method has x, y, local and global " +
+					"errors at nodes. The exact solution is required.",
+				OperationID: "exportXLSX",
+				Parameters:  exportParams,
+				Responses: solveErrors(map[string]openAPIResponse{"200": {
+					Description: "xlsx workbook",
+					Content: map[string]openAPIMedia{"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": {
+						Schema: &jsonSchema{Type: "string", Format: "binary"}}},
+				}}),
+			}},
+			"/api/value": {"get": {
+				Summary: "Values of solutions at arbitrary x",
+				Description: "The problem is solved, as by the GET solve request, the solution by each method is interpolated " +
+					"between points of its line, the exact solution is evaluated at x directly, values, that are not finite, are null.",
+				OperationID: "getValue",
+				Parameters:  valueParams,
+				Responses:   solveErrors(map[string]openAPIResponse{"200": jsonResp("values of solutions at x", valueRespRef)}),
+			}},
+			"/api/v1/compare": {"get": {
+				Summary: "Compare errors and costs of methods on the problem",
+				Description: "Errors are measured at the nodes against the exact solution, if it is given, " +
+					"or against the fine solution by rk4, the cost is the number of evaluations of f(x,y).",
+				OperationID: "compare",
+				Parameters:  compareParams,
+				Responses: solveErrors(map[string]openAPIResponse{"200": {
+					Description: "the report of methods",
+					Content: map[string]openAPIMedia{
+						"application/json": {Schema: compareRespRef},
+						"text/csv":         {Schema: &jsonSchema{Type: "string"}},
+						"image/png":        {Schema: &jsonSchema{Type: "string", Format: "binary"}},
+						"image/svg+xml":    {Schema: &jsonSchema{Type: "string"}},
+					},
+				}}),
+			}},
+			"/api/v1/compare/all": {"get": {
+				Summary: "Compare methods on presets",
+				Description: "Each method is compared on each preset as in the compare request, cells of the matrix " +
+					"are compared on their own, so the failed cell has the error and the rest are not affected by it.",
+				OperationID: "compareAll",
+				Parameters: []openAPIParam{
+					{Name: "preset", In: "query", Description: "name of the preset, repeatable, all presets are compared, if not set",
+						Schema: &jsonSchema{Type: "array", Items: &jsonSchema{Type: "string"}}, Example: []string{"canonical"}},
+					{Name: "method", In: "query", Description: "method to compare, repeatable, all methods are compared, if not set",
+						Schema: &jsonSchema{Type: "array", Items: &jsonSchema{Type: "string", Enum: stringEnum(solver.Methods())}}},
+					{Name: "n", In: "query", Description: "number of steps of each preset, steps of presets, if not set",
+						Schema: &jsonSchema{Type: "integer"}, Example: 100},
+					{Name: "format", In: "query", Description: "format of the report, markdown has rows of methods and columns of presets",
+						Schema: &jsonSchema{Type: "string", Enum: []interface{}{"json", "csv", "markdown"}}, Example: "json"},
+				},
+				Responses: solveErrors(map[string]openAPIResponse{"200": {
+					Description: "the matrix of methods by presets",
+					Content: map[string]openAPIMedia{
+						"application/json": {Schema: matrixRef},
+						"text/csv":         {Schema: &jsonSchema{Type: "string"}},
+						"text/markdown":    {Schema: &jsonSchema{Type: "string"}},
+					},
+				}}),
+			}},
+			"/api/v1/integrate": {"get": {
+				Summary:     "Definite integral of g(x) by the method",
+				Description: "The integral over [a, b] is the solution of y' = g(x), y(a) = 0 at b.",
+				OperationID: "integrate",
+				Parameters: []openAPIParam{
+					{Name: "g", In: "query", Description: "g(x), the integrand", Required: true, Schema: &jsonSchema{Type: "string"}, Example: "sin(x)"},
+					{Name: "a", In: "query", Required: true, Schema: &jsonSchema{Type: "number"}, Example: 0},
+					{Name: "b", In: "query", Required: true, Schema: &jsonSchema{Type: "number"}, Example: 3.14159},
+					{Name: "n", In: "query", Description: "number of steps, the initial one, if tol is set", Schema: &jsonSchema{Type: "integer"}, Example: 100},
+					{Name: "tol", In: "query", Description: "tolerance, the number of steps is doubled, until the integral differs by at most tol",
+						Schema: &jsonSchema{Type: "number"}},
+					{Name: "method", In: "query", Description: "method of the solution, rk4 by default",
+						Schema: &jsonSchema{Type: "string", Enum: stringEnum(solver.Methods())}, Example: "rk4"},
+					{Name: "param", In: "query", Description: "named constant of formulas as name:value, repeatable",
+						Schema: &jsonSchema{Type: "array", Items: &jsonSchema{Type: "string"}}},
+				},
+				Responses: map[string]openAPIResponse{
+					"200": jsonResp("the integral", integrateRespRef),
+					"400": jsonErr("invalid request"),
+					"429": jsonErr("too many requests"),
+					"500": jsonErr("failed to integrate"),
+				},
+			}},
+			"/api/v1/derivative": {"get": {
+				Summary:     "Partial derivatives of f(x,y) at the point",
+				Description: "df/dx and df/dy are found by the automatic differentiation of the formula, exactly up to the round-off.",
+				OperationID: "derivative",
+				Parameters: []openAPIParam{
+					{Name: "f", In: "query", Description: "f(x,y)", Required: true, Schema: &jsonSchema{Type: "string"}, Example: "x^2-2*y"},
+					{Name: "x", In: "query", Required: true, Schema: &jsonSchema{Type: "number"}, Example: 1},
+					{Name: "y", In: "query", Required: true, Schema: &jsonSchema{Type: "number"}, Example: 2},
+					{Name: "param", In: "query", Description: "named constant of formulas as name:value, repeatable",
+						Schema: &jsonSchema{Type: "array", Items: &jsonSchema{Type: "string"}}},
+				},
+				Responses: map[string]openAPIResponse{
+					"200": jsonResp("f and its partial derivatives", derivativeRespRef),
+					"400": jsonErr("invalid request, or the point is outside the domain of f"),
+					"429": jsonErr("too many requests"),
+				},
+			}},
+			"/api/v1/advise": {"get": {
+				Summary: "Recommend the method and the number of steps to reach the target error",
+				Description: "The problem is probed with at most 500 evaluations of f: the stiffness is estimated by df/dy " +
+					"along the solution, errors of methods are estimated against the fine solution by rk4.",
+				OperationID: "advise",
+				Parameters: []openAPIParam{
+					{Name: "f", In: "query", Description: "f(x,y) = y'", Required: true, Schema: &jsonSchema{Type: "string"}, Example: "x^2-2*y"},
+					{Name: "x0", In: "query", Required: true, Schema: &jsonSchema{Type: "number"}, Example: 0},
+					{Name: "y0", In: "query", Required: true, Schema: &jsonSchema{Type: "number"}, Example: 1},
+					{Name: "x1", In: "query", Description: "x_end, the end of the interval", Required: true, Schema: &jsonSchema{Type: "number"}, Example: 1},
+					{Name: "target", In: "query", Description: "target global error, 1e-6 by default", Schema: &jsonSchema{Type: "number"}, Example: 1e-6},
+					{Name: "param", In: "query", Description: "named constant of formulas as name:value, repeatable",
+						Schema: &jsonSchema{Type: "array", Items: &jsonSchema{Type: "string"}}},
+				},
+				Responses: map[string]openAPIResponse{
+					"200": jsonResp("the advice", adviseRespRef),
+					"400": jsonErr("invalid request"),
+					"429": jsonErr("too many requests"),
+					"500": jsonErr("failed to probe the problem"),
+				},
+			}},
+			"/api/v1/solve/stream": {"get": {
+				Summary:     "Stream the calculated points as server-sent events",
+				Description: "With format=ndjson events are lines of json objects with the name of the event and its data.",
+				OperationID: "streamSolve",
+				Parameters: append(append([]openAPIParam{}, solveParams...), openAPIParam{
+					Name: "format", In: "query", Description: "format of the stream",
+					Schema: &jsonSchema{Type: "string", Enum: []interface{}{"sse", "ndjson"}}, Example: "sse",
+				}),
+				Responses: solveErrors(map[string]openAPIResponse{"200": {
+					Description: "stream of point events, terminated by done, error or close event on shutdown",
+					Content: map[string]openAPIMedia{
+						"text/event-stream": {
+							Schema: &jsonSchema{Type: "string"},
+							Events: map[string]*jsonSchema{"point": pointEventRef, "done": streamSummaryRef, "error": streamErrorRef,
+								"close": streamErrorRef},
+						},
+						"application/x-ndjson": {Schema: &jsonSchema{Type: "string"}},
+					},
+				}}),
+			}},
+			"/api/v1/ws": {"get": wsOp("solveWS")},
+			"/ws/solve":  {"get": wsOp("solveLiveWS")},
+			"/api/v1/sweep": {"post": {
+				Summary: "Solve the problem once per value of the parameter",
+				Description: "Other parameters are fixed, up to " + strconv.Itoa(maxParamValues) + " values are solved, " +
+					"the failed value has the error in its line.",
+				OperationID: "sweepParam",
+				Parameters: append(append([]openAPIParam{}, chartParams[len(solveParams):len(solveParams)+2]...), openAPIParam{
+					Name: "format", In: "query", Description: "format of the response, png and svg render the family of solutions",
+					Schema: &jsonSchema{Type: "string", Enum: []interface{}{"json", "png", "svg"}}, Example: "json",
+				}),
+				RequestBody: &openAPIBody{Required: true, Content: map[string]openAPIMedia{
+					"application/json": {
+						Schema: paramSweepReqRef,
+						Example: paramSweepReq{F: "x^2 - b*y", Param: "b", Values: []float64{1, 2, 3}, Method: "rk4",
+							X0: 0, Y0: 1, XEnd: 1, N: 10},
+					},
+				}},
+				Responses: map[string]openAPIResponse{
+					"200": {Description: "solutions in the order of values", Content: map[string]openAPIMedia{
+						"application/json": {Schema: paramSweepRespRef},
+						"image/png":        {Schema: &jsonSchema{Type: "string", Format: "binary"}},
+						"image/svg+xml":    {Schema: &jsonSchema{Type: "string"}},
+					}},
+					"400": jsonErr("invalid request"),
+					"429": jsonErr("too many requests"),
+					"500": jsonErr("failed to solve"),
+				},
+			}},
+			"/api/v1/sweep/grid": {"post": {
+				Summary: "Solve the problem once per combination of values of parameters",
+				Description: "Other parameters are fixed, up to " + strconv.Itoa(maxGridLines) + " combinations are solved, " +
+					"the failed combination has the error in its line.",
+				OperationID: "sweepGrid",
+				RequestBody: &openAPIBody{Required: true, Content: map[string]openAPIMedia{
+					"application/json": {
+						Schema: gridSweepReqRef,
+						Example: gridSweepReq{F: "a*x^2 - b*y", Grid: map[string]gridAxis{"a": {Values: []float64{1, 2}},
+							"b": {Range: &sweepRange{From: 1, To: 3, K: 3}}}, Method: "rk4", X0: 0, Y0: 1, XEnd: 1, N: 10},
+					},
+				}},
+				Responses: map[string]openAPIResponse{
+					"200": {Description: "solutions in the order of combinations", Content: map[string]openAPIMedia{
+						"application/json": {Schema: gridSweepRespRef},
+					}},
+					"400": jsonErr("invalid request"),
+					"429": jsonErr("too many requests"),
+					"500": jsonErr("failed to solve"),
+				},
+			}},
+			"/api/v1/solve/batch": {"post": {
+				Summary:     "Solve the list of problems",
+				OperationID: "solveBatch",
+				RequestBody: &openAPIBody{Required: true, Content: map[string]openAPIMedia{
+					"application/json": {
+						Schema:  &jsonSchema{Type: "array", Items: solveReqRef},
+						Example: []solve.Request{exampleSolveReq, {F: "x", X0: 0, Y0: 0, XEnd: 2, Step: 0.5, Methods: []string{"ieuler"}}},
+					},
+				}},
+				Responses: solveErrors(map[string]openAPIResponse{"200": jsonResp("results in the order of problems", batchRespRef)}),
+			}},
+			"/api/v1/solve/higher": {"post": {
+				Summary: "Solve the equation of the higher order",
+				Description: "The equation y^(n) = f(x, y, dy, d2y, ...) of the order n up to " + strconv.Itoa(maxOrder) +
+					" is reduced to the system of first order equations, y0 has y and its derivatives up to the (n-1)-th one at x0.",
+				OperationID: "solveHigher",
+				RequestBody: &openAPIBody{Required: true, Content: map[string]openAPIMedia{
+					"application/json": {
+						Schema:  higherReqRef,
+						Example: higherReq{F: "-y", X0: 0, Y0: []float64{0, 1}, XEnd: 3, N: 30, Methods: []string{"rk4"}},
+					},
+				}},
+				Responses: solveErrors(map[string]openAPIResponse{"200": jsonResp("y and its derivatives by methods", higherRespRef)}),
+			}},
+			"/api/v1/result/{id}": {"get": {
+				Summary:     "Saved result",
+				OperationID: "getResult",
+				Parameters:  []openAPIParam{resultIDParam},
+				Responses: map[string]openAPIResponse{
+					"200": jsonResp("saved request, its solution and local errors of methods", resultRef),
+					"404": jsonErr("no such result or it is expired"),
+				},
+			}},
+			"/result/{id}": {"get": {
+				Summary:     "Form, filled with the saved request",
+				OperationID: "getResultPage",
+				Parameters:  []openAPIParam{resultIDParam},
+				Responses: map[string]openAPIResponse{
+					"200": {Description: "html page", Content: map[string]openAPIMedia{"text/html": {Schema: &jsonSchema{Type: "string"}}}},
+					"404": {Description: "no such result or it is expired"},
+				},
+			}},
+			"/api/v1/share": {"post": {
+				Summary: "Permalink of the problem",
+				Description: "The problem is validated and encoded into the signed token, it is not stored, " +
+					"GET /s/{token} renders the form, filled with the problem, along with the chart of solutions. " +
+					"Only f, exact, c, params, the interval, the initial value, n and methods are kept.",
+				OperationID: "shareProblem",
+				RequestBody: &openAPIBody{Required: true, Content: map[string]openAPIMedia{
+					"application/json": {Schema: solveReqRef, Example: exampleSolveReq},
+				}},
+				Responses: map[string]openAPIResponse{
+					"200": jsonResp("token of the permalink", shareRef),
+					"400": jsonErr("invalid request"),
+					"429": jsonErr("too many requests"),
+				},
+			}},
+			"/api/jobs": {"post": {
+				Summary: "Solve the problem in background",
+				Description: "The request is validated, as the solve request is, and queued, GET /api/jobs/{id} returns " +
+					"the status of the job, queued, running, done or failed, its progress and the result, once it is done. " +
+					"Jobs are limited by max_job_steps instead of max_steps and by the job timeout, they are kept " +
+					"for an hour after they are finished.",
+				OperationID: "submitJob",
+				RequestBody: &openAPIBody{Required: true, Content: map[string]openAPIMedia{
+					"application/json": {Schema: solveReqRef, Example: exampleSolveReq},
+				}},
+				Responses: map[string]openAPIResponse{
+					"202": jsonResp("queued job, its path is in Location header", jobRef),
+					"400": jsonErr("invalid request"),
+					"429": jsonErr("too many requests"),
+					"503": jsonErr("too many jobs are queued, retry after the delay in Retry-After header"),
+				},
+			}},
+			"/api/v1/sets/{name}": {
+				"post": {
+					Summary: "Add the solved problem to the comparison set of the session",
+					Description: "The request is solved, as the solve request is, and its lines are added to the named set, " +
+						"labeled as label: method, so the same method is compared across equations or intervals. " +
+						"The set is created by the first problem, it has up to " + strconv.Itoa(maxSetEntries) +
+						" problems, the session has up to " + strconv.Itoa(maxSets) + " sets.",
+					OperationID: "addToSet",
+					Parameters:  []openAPIParam{setNameParam},
+					RequestBody: &openAPIBody{Required: true, Content: map[string]openAPIMedia{
+						"application/json": {Schema: setAddReqRef, Example: setAddReq{Label: "canonical", Request: exampleSolveReq}},
+					}},
+					Responses: solveErrors(map[string]openAPIResponse{"200": jsonResp("the set with the added problem", setRef)}),
+				},
+				"get": {
+					Summary:     "Comparison set of the session",
+					Description: "Lines of all problems of the set are overlaid on one chart in png and svg formats.",
+					OperationID: "getSet",
+					Parameters: []openAPIParam{setNameParam,
+						{Name: "format", In: "query", Description: "format of the set",
+							Schema: &jsonSchema{Type: "string", Enum: []interface{}{"json", "png", "svg"}}, Example: "json"},
+						{Name: "width", In: "query", Description: "width of the image in pixels, up to " +
+							strconv.Itoa(maxChartSize), Schema: sizeSchema, Example: 400},
+						{Name: "height", In: "query", Description: "height of the image in pixels, up to " +
+							strconv.Itoa(maxChartSize), Schema: sizeSchema, Example: 300},
+					},
+					Responses: map[string]openAPIResponse{
+						"200": {Description: "problems of the set in the order they are added in, the unknown set is empty",
+							Content: map[string]openAPIMedia{
+								"application/json": {Schema: setRef},
+								"image/png":        {Schema: &jsonSchema{Type: "string", Format: "binary"}},
+								"image/svg+xml":    {Schema: &jsonSchema{Type: "string"}},
+							}},
+						"400": jsonErr("invalid name or chart parameters"),
+					},
+				},
+				"delete": {
+					Summary:     "Delete the comparison set of the session",
+					OperationID: "deleteSet",
+					Parameters:  []openAPIParam{setNameParam},
+					Responses:   map[string]openAPIResponse{"204": {Description: "set is deleted"}},
+				},
+			},
+			"/api/v1/history": {
+				"get": {
+					Summary:     "Last solve requests of the session",
+					OperationID: "getHistory",
+					Responses:   map[string]openAPIResponse{"200": jsonResp("requests from the newest to the oldest", historyRef)},
+				},
+				"delete": {
+					Summary:     "Clear the history of the session",
+					OperationID: "clearHistory",
+					Responses:   map[string]openAPIResponse{"204": {Description: "history is cleared"}},
+				},
+			},
+			"/api/v1/runs": {"get": {
+				Summary: "Recorded solve requests of the session",
+				Description: "Each run has the request, the summary of lines with max and rms errors against the exact " +
+					"solution, if it is known, and the time of solving, GET /api/v1/runs/{id} returns the run by its id, " +
+					"POST /api/v1/runs/{id}/rerun solves its request once again",
+				OperationID: "listRuns",
+				Parameters: []openAPIParam{{Name: "limit", In: "query", Description: "number of the newest runs, up to " +
+					strconv.Itoa(maxRunsList), Schema: &jsonSchema{Type: "integer"}, Example: 20}},
+				Responses: map[string]openAPIResponse{
+					"200": jsonResp("runs from the newest to the oldest", runsRef),
+					"400": jsonErr("invalid limit"),
+				},
+			}},
+			"/api/v1/info": {"get": {
+				Summary:     "Version of the application and available methods",
+				OperationID: "getInfo",
+				Responses:   map[string]openAPIResponse{"200": jsonResp("information about the application", infoRef)},
+			}},
+			"/api/presets": {"get": {
+				Summary: "Built-in problems",
+				Description: "Each preset is selected by its id in the preset field of the request at the endpoint, " +
+					"the request of the preset is ready to solve, once methods are set",
+				OperationID: "listPresets",
+				Responses: map[string]openAPIResponse{"200": jsonResp("presets, sorted by ids",
+					&jsonSchema{Type: "array", Items: presetRef})},
+			}},
+			"/api/v1/openapi.json": {"get": {
+				Summary:     "This document",
+				OperationID: "getOpenAPI",
+				Responses:   map[string]openAPIResponse{"200": jsonResp("OpenAPI document", &jsonSchema{Type: "object"})},
+			}},
+		},
+		Components: openAPIComponents{Schemas: sr.schemas},
+	}
+
+	if len(s.APIKeys) > 0 {
+		doc.Components.SecuritySchemes = map[string]openAPISecurityScheme{
+			"bearer": {Type: "http", Scheme: "bearer"},
+			"apiKey": {Type: "apiKey", In: "query", Name: "api_key"},
+		}
+		for _, path := range protectedPaths {
+			for _, op := range doc.Paths[path] {
+				op.Security = []map[string][]string{{"bearer": {}}, {"apiKey": {}}}
+				op.Responses["401"] = jsonErr("api key is required")
+				op.Responses["403"] = jsonErr("api key is not accepted")
+			}
+		}
+	}
+	return doc
+}
+
+// protectedPaths are the paths, that require api key, if keys are set
+var protectedPaths = []string{"/api/chart/field", "/api/v1/solve", "/api/v1/solve.csv", "/api/v1/chart", "/api/plot.png", "/api/v1/chart/phase",
+	"/api/v1/stability", "/api/v1/errors", "/api/v1/report", "/api/export.xlsx", "/api/value", "/api/v1/compare", "/api/v1/compare/all", "/api/v1/integrate", "/api/v1/derivative", "/api/v1/advise",
+	"/api/v1/sweep", "/api/v1/sweep/grid", "/api/v1/solve/stream", "/api/v1/ws", "/ws/solve", "/api/v1/solve/batch", "/api/v1/solve/higher",
+	"/api/v1/share", "/api/jobs", "/api/v1/sets/{name}"}
+
+// solveQueryParams describes the query parameters of the solve request
+func solveQueryParams() []openAPIParam {
+	float := &jsonSchema{Type: "number"}
+	str := &jsonSchema{Type: "string"}
+	return []openAPIParam{
+		{Name: "f", In: "query", Description: "f(x,y) = y', required, unless the preset is set", Schema: str,
+			Example: exampleSolveReq.F},
+		{Name: "preset", In: "query", Description: "id of the built-in problem, that sets f, exact, c, x0, y0 and x1, " +
+			"listed by GET /api/presets", Schema: str},
+		{Name: "exact", In: "query", Description: "y(x,c), the exact solution", Schema: str, Example: exampleSolveReq.Exact},
+		{Name: "c", In: "query", Description: "C(x0,y0), the constant for the exact solution", Schema: str, Example: exampleSolveReq.C},
+		{Name: "c_bracket", In: "query", Description: "lo:hi or lo:hi:tol, the bracket of the constant of the exact " +
+			"solution, found numerically instead of c", Schema: str},
+		{Name: "p", In: "query", Description: "p(x) of the linear equation y' + p(x)y = q(x), the exact solution is derived " +
+			"from p and q instead of exact and c", Schema: str},
+		{Name: "q", In: "query", Description: "q(x) of the linear equation y' + p(x)y = q(x)", Schema: str},
+		{Name: "x0", In: "query", Required: true, Schema: float, Example: exampleSolveReq.X0},
+		{Name: "y0", In: "query", Required: true, Schema: float, Example: exampleSolveReq.Y0},
+		{Name: "x1", In: "query", Description: "the end of the interval", Required: true, Schema: float, Example: exampleSolveReq.XEnd},
+		{Name: "n", In: "query", Description: "number of steps", Schema: &jsonSchema{Type: "integer"}, Example: exampleSolveReq.N},
+		{Name: "step", In: "query", Description: "step size, used if n is not set", Schema: float},
+		{Name: "n_by_method", In: "query", Description: "number of steps of the method as method:n instead of n and step, " +
+			"repeatable, might be comma-separated, each method is solved on its own grid",
+			Schema: &jsonSchema{Type: "array", Items: str}},
+		{Name: "param", In: "query", Description: "named constant of formulas as name:value, repeatable",
+			Schema: &jsonSchema{Type: "array", Items: str}},
+		{Name: "method", In: "query", Description: "method to solve with, repeatable, might be comma-separated", Required: true,
+			Schema:  &jsonSchema{Type: "array", Items: &jsonSchema{Type: "string", Enum: stringEnum(append(solver.Methods(), solve.ExactMethod))}},
+			Example: exampleSolveReq.Methods},
+	}
+}
+
+// stringEnum makes the enum of the schema from the strings
+func stringEnum(vals []string) []interface{} {
+	res := make([]interface{}, 0, len(vals))
+	for _, v := range vals {
+		res = append(res, v)
+	}
+	return res
+}
+
+const swaggerUIHTML = `<!DOCTYPE html>
+<html>
+<head>
+    <meta name="viewport" content="width=device-width"/>
+    <meta http-equiv="Content-Type" content="text/html; charset=UTF-8"/>
+    <title>DEComPract API</title>
+    <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@3/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@3/swagger-ui-bundle.js"></script>
+<script>
+    window.onload = function () {
+        SwaggerUIBundle({url: "/api/v1/openapi.json", dom_id: "#swagger-ui"});
+    };
+</script>
+</body>
+</html>`
+
+// GET /api/v1/openapi.json - returns the OpenAPI description of the API
+func (s *Rest) openAPICtrl(w http.ResponseWriter, r *http.Request) {
+	rest.RenderJSON(w, r, s.openAPI())
+}
+
+// WriteOpenAPI writes the OpenAPI description of the API of the version in the indented json, the description
+// of the server without api keys, the same as GET /api/v1/openapi.json returns, it is the contract of clients
+func WriteOpenAPI(w io.Writer, version string) error {
+	s := &Rest{Version: version}
+	b, err := json.MarshalIndent(s.openAPI(), "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal openapi description")
+	}
+	if _, err = w.Write(append(b, '\n')); err != nil {
+		return errors.Wrap(err, "failed to write openapi description")
+	}
+	return nil
+}
+
+// GET /api/v1/docs - renders the Swagger UI page for the OpenAPI description
+func (s *Rest) docsCtrl(w http.ResponseWriter, r *http.Request) {
+	render.HTML(w, r, swaggerUIHTML)
+}
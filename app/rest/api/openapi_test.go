@@ -0,0 +1,302 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type jsonMap = map[string]interface{}
+
+// validateSchema checks that the value, decoded from json, matches the schema,
+// properties, not listed in the schema, are reported as well
+func validateSchema(doc jsonMap, schema jsonMap, v interface{}, path string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		resolved, ok := doc["components"].(jsonMap)["schemas"].(jsonMap)[name].(jsonMap)
+		if !ok {
+			return fmt.Errorf("%s: unresolved reference %s", path, ref)
+		}
+		return validateSchema(doc, resolved, v, path)
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			found = found || e == v
+		}
+		if !found {
+			return fmt.Errorf("%s: %v is not in %v", path, v, enum)
+		}
+	}
+
+	switch schema["type"] {
+	case "object":
+		obj, ok := v.(jsonMap)
+		if !ok {
+			return fmt.Errorf("%s: expected object, got %T", path, v)
+		}
+		props, _ := schema["properties"].(jsonMap)
+		if props == nil {
+			return nil
+		}
+		if req, ok := schema["required"].([]interface{}); ok {
+			for _, name := range req {
+				if _, ok := obj[name.(string)]; !ok {
+					return fmt.Errorf("%s: missing required property %s", path, name)
+				}
+			}
+		}
+		for name, pv := range obj {
+			ps, ok := props[name].(jsonMap)
+			if !ok {
+				return fmt.Errorf("%s: unknown property %s", path, name)
+			}
+			if err := validateSchema(doc, ps, pv, path+"."+name); err != nil {
+				return err
+			}
+		}
+	case "array":
+		arr, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected array, got %T", path, v)
+		}
+		for i, item := range arr {
+			if err := validateSchema(doc, schema["items"].(jsonMap), item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "number":
+		if _, ok := v.(float64); !ok {
+			return fmt.Errorf("%s: expected number, got %T", path, v)
+		}
+	case "integer":
+		if f, ok := v.(float64); !ok || f != math.Trunc(f) {
+			return fmt.Errorf("%s: expected integer, got %v", path, v)
+		}
+	case "string":
+		if _, ok := v.(string); !ok {
+			return fmt.Errorf("%s: expected string, got %T", path, v)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("%s: expected boolean, got %T", path, v)
+		}
+	}
+	return nil
+}
+
+// checkRefs checks that all references in the document are resolvable
+func checkRefs(t *testing.T, doc jsonMap, v interface{}) {
+	switch vv := v.(type) {
+	case jsonMap:
+		if ref, ok := vv["$ref"].(string); ok {
+			name := strings.TrimPrefix(ref, "#/components/schemas/")
+			_, ok = doc["components"].(jsonMap)["schemas"].(jsonMap)[name]
+			assert.True(t, ok, "unresolved reference %s", ref)
+		}
+		for _, item := range vv {
+			checkRefs(t, doc, item)
+		}
+	case []interface{}:
+		for _, item := range vv {
+			checkRefs(t, doc, item)
+		}
+	}
+}
+
+// exampleQuery builds the query string from the examples of parameters,
+// formulas are escaped as the path, so the plus sign is kept
+func exampleQuery(params []interface{}) string {
+	var q []string
+	for _, p := range params {
+		param := p.(jsonMap)
+		name := param["name"].(string)
+		switch ex := param["example"].(type) {
+		case nil:
+		case []interface{}:
+			for _, item := range ex {
+				q = append(q, name+"="+url.PathEscape(fmt.Sprint(item)))
+			}
+		default:
+			q = append(q, name+"="+url.PathEscape(fmt.Sprint(ex)))
+		}
+	}
+	return strings.Join(q, "&")
+}
+
+func TestRest_OpenAPI(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/api/v1/openapi.json")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	doc := jsonMap{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&doc))
+	assert.Equal(t, "3.0.3", doc["openapi"])
+	assert.Equal(t, "test", doc["info"].(jsonMap)["version"])
+	checkRefs(t, doc, doc)
+
+	paths := doc["paths"].(jsonMap)
+	for _, p := range []string{"/api/v1/solve", "/api/v1/solve/stream", "/api/v1/solve/batch", "/api/chart/field"} {
+		assert.Contains(t, paths, p)
+	}
+
+	// the saved result for the routes with its id in path
+	saved := solve.Response{}
+	b, err := json.Marshal(jsonMap{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": []string{"rk4"}, "save": true})
+	require.NoError(t, err)
+	resp, err = http.Post(ts.URL+"/api/v1/solve", "application/json", bytes.NewReader(b))
+	require.NoError(t, err)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&saved))
+	resp.Body.Close()
+	require.NotEmpty(t, saved.ID)
+
+	// sorted for the stable order of subtests
+	var keys []string
+	for path, ops := range paths {
+		for method := range ops.(jsonMap) {
+			keys = append(keys, method+" "+path)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		method, path := strings.Split(key, " ")[0], strings.Split(key, " ")[1]
+		op := paths[path].(jsonMap)[method].(jsonMap)
+		t.Run(key, func(t *testing.T) {
+			var body []byte
+			if rb, ok := op["requestBody"].(jsonMap); ok {
+				media := rb["content"].(jsonMap)["application/json"].(jsonMap)
+				require.Contains(t, media, "example", "request body must have an example")
+				require.NoError(t, validateSchema(doc, media["schema"].(jsonMap), media["example"], "example"))
+				body, err = json.Marshal(media["example"])
+				require.NoError(t, err)
+			}
+
+			u := ts.URL + strings.NewReplacer("{id}", saved.ID, "{name}", "example").Replace(path)
+			if params, ok := op["parameters"].([]interface{}); ok {
+				u += "?" + exampleQuery(params)
+			}
+
+			if _, ok := op["responses"].(jsonMap)["101"]; ok {
+				t.Skip("websocket routes are covered by their own tests")
+			}
+
+			req, err := http.NewRequest(strings.ToUpper(method), u, bytes.NewReader(body))
+			require.NoError(t, err)
+			if body == nil {
+				req.Body = nil
+			}
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			if _, ok := op["responses"].(jsonMap)["204"]; ok {
+				require.Equal(t, http.StatusNoContent, resp.StatusCode, "example request must succeed")
+				return
+			}
+			status := "200"
+			if _, ok := op["responses"].(jsonMap)["202"]; ok {
+				status = "202"
+			}
+			require.Equal(t, status, strconv.Itoa(resp.StatusCode), "example request must succeed")
+
+			ct := strings.Split(resp.Header.Get("Content-Type"), ";")[0]
+			media, ok := op["responses"].(jsonMap)[status].(jsonMap)["content"].(jsonMap)[ct].(jsonMap)
+			require.True(t, ok, "content type %s must be described", ct)
+
+			switch ct {
+			case "application/json":
+				var v interface{}
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&v))
+				assert.NoError(t, validateSchema(doc, media["schema"].(jsonMap), v, "response"))
+			case "text/event-stream":
+				events := media["x-events"].(jsonMap)
+				cnt := 0
+				for ev := range readEvents(t, resp) {
+					schema, ok := events[ev.name].(jsonMap)
+					require.True(t, ok, "event %s must be described", ev.name)
+					var v interface{}
+					require.NoError(t, json.Unmarshal([]byte(ev.data), &v))
+					assert.NoError(t, validateSchema(doc, schema, v, ev.name))
+					cnt++
+				}
+				assert.NotZero(t, cnt)
+			default:
+				b, err := ioutil.ReadAll(resp.Body)
+				require.NoError(t, err)
+				assert.NotEmpty(t, b)
+			}
+		})
+	}
+
+	// the validator itself must catch mismatches
+	schema := jsonMap{"$ref": "#/components/schemas/SolveRequest"}
+	assert.Error(t, validateSchema(doc, schema, jsonMap{"f": "x", "x0": 0.0, "y0": 0.0, "x_end": 1.0}, "req"))
+	assert.Error(t, validateSchema(doc, schema, jsonMap{"f": "x", "x0": 0.0, "y0": 0.0, "x_end": 1.0,
+		"methods": []interface{}{"rk4"}, "unknown": 1.0}, "req"))
+	assert.Error(t, validateSchema(doc, schema, jsonMap{"f": "x", "x0": 0.0, "y0": 0.0, "x_end": 1.0,
+		"methods": []interface{}{"rk5"}}, "req"))
+	assert.NoError(t, validateSchema(doc, schema, jsonMap{"f": "x", "x0": 0.0, "y0": 0.0, "x_end": 1.0,
+		"methods": []interface{}{"rk4"}}, "req"))
+}
+
+func TestWriteOpenAPI(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteOpenAPI(buf, "v1.2.3"))
+	assert.True(t, strings.HasPrefix(buf.String(), "{\n  \"openapi\": \"3.0.3\","), "the description is indented")
+
+	b, err := json.Marshal((&Rest{Version: "v1.2.3"}).openAPI())
+	require.NoError(t, err)
+	assert.JSONEq(t, string(b), buf.String(), "the description is the one of the server")
+	doc := jsonMap{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Empty(t, doc["components"].(jsonMap)["securitySchemes"])
+}
+
+func TestRest_OpenAPISecurity(t *testing.T) {
+	srv := &Rest{Version: "test"}
+	assert.Empty(t, srv.openAPI().Components.SecuritySchemes, "api is open without keys")
+
+	srv.APIKeys = []rest.APIKey{{Hash: rest.HashAPIKey("secret")}}
+	doc := srv.openAPI()
+	assert.Len(t, doc.Components.SecuritySchemes, 2)
+	for _, path := range protectedPaths {
+		require.Contains(t, doc.Paths, path)
+		for method, op := range doc.Paths[path] {
+			assert.NotEmpty(t, op.Security, "%s %s", method, path)
+			assert.Contains(t, op.Responses, "401", "%s %s", method, path)
+			assert.Contains(t, op.Responses, "403", "%s %s", method, path)
+		}
+	}
+	assert.Empty(t, doc.Paths["/ping"]["get"].Security)
+	assert.Empty(t, doc.Paths["/api/v1/info"]["get"].Security)
+}
+
+func TestRest_Docs(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/api/v1/docs")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+	assert.Contains(t, string(body), `url: "/api/v1/openapi.json"`)
+}
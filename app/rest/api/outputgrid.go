@@ -0,0 +1,37 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/pkg/errors"
+)
+
+// readOutputGridQuery reads the output grid of the GET solve request from output_points or repeatable output_x
+// with output_interp, nil, if neither is set
+func readOutputGridQuery(r *http.Request) (*solve.OutputGrid, error) {
+	q := r.URL.Query()
+	if q.Get("output_points") == "" && len(q["output_x"]) == 0 {
+		if q.Get("output_interp") != "" {
+			return nil, errors.New("output_interp requires output_points or output_x")
+		}
+		return nil, nil
+	}
+	og := &solve.OutputGrid{Interp: q.Get("output_interp")}
+	var err error
+	if og.Points, err = queryInt(r, "output_points", 0); err != nil {
+		return nil, err
+	}
+	for _, v := range q["output_x"] {
+		for _, s := range strings.Split(v, ",") {
+			x, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "output_x has the invalid number %q", s)
+			}
+			og.X = append(og.X, x)
+		}
+	}
+	return og, nil
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRest_SolveOutputGrid(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	// y' = x^2 - 2y, y = x^2/2 - x/2 + 1/4 + 3/4*exp(-2x)
+	exact := func(x float64) float64 { return x*x/2 - x/2 + 0.25 + 0.75*math.Exp(-2*x) }
+	doSolve := func(body string) solve.Response {
+		resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode, body)
+		res := solve.Response{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+		return res
+	}
+
+	// methods are solved on their own grids, but lines are given at the same evenly spaced x
+	res := doSolve(`{"f": "x^2 - 2*y", "exact": "x^2/2 - x/2 + 1/4 + c*exp(-2*x)", "c": "(y0 - x0^2/2 + x0/2 - 1/4)*exp(2*x0)",
+		"x0": 0, "y0": 1, "x_end": 1, "n_by_method": {"euler": 400, "rk4": 30}, "methods": ["euler", "rk4"],
+		"errors": true, "output_grid": {"points": 5}}`)
+	assert.False(t, res.GridsDiffer, "lines share x of the output grid")
+	require.Len(t, res.Lines, 2)
+	require.NotNil(t, res.Exact)
+	xs := []float64{0, 0.25, 0.5, 0.75, 1}
+	for i, line := range append(res.Lines, *res.Exact) {
+		require.Len(t, line.Points, len(xs), line.Method)
+		for j, pt := range line.Points {
+			assert.InDelta(t, xs[j], pt.X, 1e-15, line.Method)
+			assert.InDelta(t, exact(pt.X), pt.Y, []float64{1e-2, 1e-5, 1e-12}[i], "%s at x=%v", line.Method, pt.X)
+		}
+		assert.Empty(t, line.Stats.Warnings, "points are interpolated, not downsampled")
+	}
+	assert.Equal(t, 401, res.Lines[0].Stats.Points, "stats are of internal steps")
+	assert.Equal(t, 31, res.Lines[1].Stats.Points)
+	require.Len(t, res.Errors, 2)
+	assert.Len(t, res.Errors[1].Points, len(xs), "errors are at x of the output grid")
+
+	// custom x of the backward solve go from x0 to x_end
+	res = doSolve(`{"f": "x^2 - 2*y", "x0": 1, "y0": ` + solve.FormatFloat(exact(1)) + `, "x_end": 0, "n": 20,
+		"methods": ["rk4"], "output_grid": {"x": [0.9, 0.33, 0.1], "interp": "hermite"}}`)
+	require.Len(t, res.Lines, 1)
+	require.Len(t, res.Lines[0].Points, 3)
+	for i, x := range []float64{0.9, 0.33, 0.1} {
+		assert.Equal(t, x, res.Lines[0].Points[i].X)
+		assert.InDelta(t, exact(x), res.Lines[0].Points[i].Y, 1e-5, "backward at x=%v", x)
+	}
+
+	// the GET request sets the grid in the query
+	q := url.Values{"f": {"x^2 - 2*y"}, "x0": {"0"}, "y0": {"1"}, "x1": {"1"}, "n": {"10"}, "method": {"ieuler"},
+		"output_x": {"0.5,1", "0.25"}, "output_interp": {"linear"}}
+	resp, err := http.Get(ts.URL + "/api/v1/solve?" + q.Encode())
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "x must go from x0 to x_end")
+
+	q["output_x"] = []string{"0.25,0.5", "1"}
+	resp, err = http.Get(ts.URL + "/api/v1/solve?" + q.Encode())
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res = solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Lines, 1)
+	require.Len(t, res.Lines[0].Points, 3)
+	assert.Equal(t, 0.25, res.Lines[0].Points[0].X)
+	assert.Equal(t, 11, res.Lines[0].Stats.Points)
+
+	// the stream gives points of steps, so it doesn't accept the grid
+	resp, err = http.Get(ts.URL + "/api/v1/solve/stream?" + q.Encode())
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,201 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/expr"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/go-chi/render"
+	"github.com/pkg/errors"
+)
+
+// maxGridLines is the maximal number of combinations of values of parameters in the grid sweep
+const maxGridLines = 100
+
+// gridSweepReq is the problem, solved by the method once per combination of values of swept parameters
+type gridSweepReq struct {
+	F      string              `json:"f"`                // f(x,y) = y', with parameters
+	Grid   map[string]gridAxis `json:"grid"`             // values of swept parameters by names
+	Params map[string]float64  `json:"params,omitempty"` // fixed parameters
+	Method string              `json:"method,omitempty"` // rk4 by default
+	X0     float64             `json:"x0"`
+	Y0     float64             `json:"y0"`
+	XEnd   float64             `json:"x_end"`
+	N      int                 `json:"n,omitempty"`
+	Step   float64             `json:"step,omitempty"`
+}
+
+// gridAxis is values of the swept parameter, listed or evenly spaced by the range
+type gridAxis struct {
+	Values []float64   `json:"values,omitempty"`
+	Range  *sweepRange `json:"range,omitempty"`
+}
+
+// gridSweepResp contains solutions in the order of combinations, the last parameter by names changes first
+type gridSweepResp struct {
+	Params []string   `json:"params"` // names of swept parameters in order
+	Method string     `json:"method"`
+	Step   float64    `json:"step"`
+	Lines  []gridLine `json:"lines"`
+	Took   string     `json:"took"`
+}
+
+// gridLine is the solution with the combination of values, labelled as "a=1, b=2"
+type gridLine struct {
+	Label  string              `json:"label"`
+	Params map[string]float64  `json:"params"`
+	Points []num.Point         `json:"points"`
+	Error  *rest.ErrorResponse `json:"error,omitempty"`
+}
+
+// gridSweep is the validated grid sweep, ready to solve
+type gridSweep struct {
+	req       gridSweepReq
+	grid      map[string][]float64
+	f         solver.ParamFunc
+	step      float64
+	maxPoints int
+}
+
+// prepare validates the request, the problem with first values of parameters is validated as the solve
+// request is, in case of invalid request, rest.ValidationError with all invalid fields is returned
+func (req gridSweepReq) prepare(l solve.Limits) (gridSweep, error) {
+	if req.Method == "" {
+		req.Method = "rk4"
+	}
+	var errs rest.ValidationError
+	invalid := func(field, msg string, args ...interface{}) {
+		errs = append(errs, rest.FieldError{Field: field, Msg: fmt.Sprintf(msg, args...)})
+	}
+
+	if len(req.Grid) == 0 {
+		invalid("grid", "must have at least one parameter")
+	}
+	names := make([]string, 0, len(req.Grid))
+	for name := range req.Grid {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// the rest of the problem is validated as the solve request with first values of parameters
+	sr := solve.Request{F: req.F, X0: req.X0, Y0: req.Y0, XEnd: req.XEnd, N: req.N, Step: req.Step,
+		Methods: []string{req.Method}, Params: map[string]float64{}}
+	for name, v := range req.Params {
+		sr.Params[name] = v
+	}
+	grid := make(map[string][]float64, len(req.Grid))
+	total := 1
+	for _, name := range names {
+		switch {
+		case !solve.ParamName.MatchString(name):
+			invalid("grid", "%q is not a valid name", name)
+			continue
+		case solve.IsReserved(name):
+			invalid("grid", "%q is reserved", name)
+			continue
+		}
+		if _, ok := req.Params[name]; ok {
+			invalid("params", "%s is swept, it can't be fixed", name)
+		}
+		axis := req.Grid[name]
+		values := sweepValues("grid."+name+".", axis.Values, axis.Range, invalid)
+		if len(values) > 0 {
+			sr.Params[name] = values[0]
+		}
+		grid[name] = values
+		total *= len(values)
+	}
+	if total > maxGridLines {
+		invalid("grid", "must have up to %d combinations of values, got %d", maxGridLines, total)
+	}
+	if req.Method == solve.ExactMethod {
+		invalid("method", "exact solution can't be swept")
+		sr.Methods = nil
+	}
+	p, err := sr.Prepare(l)
+	var verr rest.ValidationError
+	if errors.As(err, &verr) {
+		errs = append(errs, verr...)
+	}
+
+	if len(errs) > 0 {
+		return gridSweep{}, errs
+	}
+
+	fxy, err := expr.Parse(req.F, req.Params, append([]string{"x", "y"}, names...)...)
+	if err != nil {
+		return gridSweep{}, errors.Wrap(err, "can't parse f(x,y)")
+	}
+	f := func(x, y float64, params map[string]float64) (float64, error) {
+		args := make([]float64, 0, len(names)+2)
+		args = append(args, x, y)
+		for _, name := range names {
+			args = append(args, params[name])
+		}
+		return fxy(args...)
+	}
+	return gridSweep{req: req, grid: grid, f: f, step: p.Step, maxPoints: l.MaxPoints}, nil
+}
+
+// solve solves the problem with each combination of values, the failure of the combination is reported
+// in its line, the sweep fails only if all combinations fail
+func (gs gridSweep) solve() (gridSweepResp, error) {
+	st := time.Now()
+	req := gs.req
+	lines, err := solver.SweepGrid(gs.f, gs.grid, req.Params, solve.Builder(req.Method), gs.step, req.X0, req.Y0, req.XEnd)
+	if err != nil {
+		return gridSweepResp{}, err
+	}
+
+	resp := gridSweepResp{Method: req.Method, Step: gs.step, Lines: make([]gridLine, len(lines))}
+	for name := range gs.grid {
+		resp.Params = append(resp.Params, name)
+	}
+	sort.Strings(resp.Params)
+	failed := 0
+	for i, l := range lines {
+		line := gridLine{Label: l.Label(), Params: l.Params, Points: []num.Point{}}
+		if l.Err != nil {
+			be := rest.NewErrorResponse(errors.Wrapf(l.Err, "failed to solve with %s", line.Label),
+				"failed to solve", rest.ErrInternal)
+			line.Error = &be
+			failed++
+		} else {
+			line.Points = solve.Downsample(l.Points, gs.maxPoints)
+		}
+		resp.Lines[i] = line
+	}
+	if failed == len(lines) {
+		return gridSweepResp{}, errors.Wrapf(lines[0].Err, "all %d solutions failed, %s", failed, lines[0].Label())
+	}
+	resp.Took = time.Since(st).String()
+	return resp, nil
+}
+
+// POST /api/v1/sweep/grid - solve the problem once per combination of values of swept parameters
+func (s *Rest) gridSweepCtrl(w http.ResponseWriter, r *http.Request) {
+	req := gridSweepReq{}
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "failed to decode request", rest.ErrDecode)
+		return
+	}
+
+	gs, err := req.prepare(s.limits())
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid sweep request", rest.ErrBadRequest)
+		return
+	}
+
+	resp, err := gs.solve()
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "failed to solve", rest.ErrInternal)
+		return
+	}
+	rest.RenderJSON(w, r, resp)
+}
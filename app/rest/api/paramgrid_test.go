@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRest_GridSweep(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	body := `{"f": "k*a - b*y", "grid": {"b": {"range": {"from": 1, "to": 3, "k": 3}}, "a": {"values": [1, 2]}},
+		"params": {"k": 1}, "x0": 0, "y0": 0, "x_end": 1, "n": 10}`
+	resp, err := http.Post(ts.URL+"/api/v1/sweep/grid", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := gridSweepResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.Equal(t, []string{"a", "b"}, res.Params)
+	assert.Equal(t, "rk4", res.Method)
+	require.Len(t, res.Lines, 6)
+	assert.Equal(t, "a=1, b=1", res.Lines[0].Label)
+	assert.Equal(t, "a=1, b=2", res.Lines[1].Label)
+	assert.Equal(t, "a=2, b=3", res.Lines[5].Label)
+	for _, line := range res.Lines {
+		assert.Nil(t, line.Error)
+		require.Len(t, line.Points, 11)
+		a, b := line.Params["a"], line.Params["b"]
+		assert.InDelta(t, a/b*(1-math.Exp(-b)), line.Points[10].Y, 1e-4, line.Label)
+	}
+}
+
+func TestRest_GridSweepInvalid(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	tbl := []struct {
+		body  string
+		field string
+		msg   string
+	}{
+		{`{"f": "y", "grid": {}, "x_end": 1, "n": 10}`, "grid", "must have at least one parameter"},
+		{`{"f": "y", "grid": {"x0": {"values": [1]}}, "x_end": 1, "n": 10}`, "grid", `"x0" is reserved`},
+		{`{"f": "a*y", "grid": {"a": {"values": [1]}}, "params": {"a": 1}, "x_end": 1, "n": 10}`, "params",
+			"a is swept, it can't be fixed"},
+		{`{"f": "a*y", "grid": {"a": {"values": [1, 1]}}, "x_end": 1, "n": 10}`, "grid.a.values", "1 is repeated"},
+		{`{"f": "a*b*y", "grid": {"a": {"range": {"from": 0, "to": 1, "k": 11}}, "b": {"range": {"from": 0, "to": 1, "k": 10}}},
+			"x_end": 1, "n": 10}`, "grid", "must have up to 100 combinations of values, got 110"},
+		{`{"f": "a*y", "grid": {"a": {"values": [1]}}, "x_end": 1, "n": 10, "method": "exact"}`, "method",
+			"exact solution can't be swept"},
+	}
+	for _, tt := range tbl {
+		resp, err := http.Post(ts.URL+"/api/v1/sweep/grid", "application/json", strings.NewReader(tt.body))
+		require.NoError(t, err)
+		er := rest.ErrorResponse{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&er))
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, tt.msg)
+		assert.Contains(t, er.Errors, rest.FieldError{Field: tt.field, Msg: tt.msg})
+	}
+}
@@ -0,0 +1,286 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/expr"
+	"github.com/Semior001/decompract/app/num/graph"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/go-chi/render"
+	log "github.com/go-pkgz/lgr"
+	"github.com/pkg/errors"
+)
+
+// maxParamValues is the maximal number of values of the parameter in the parameter sweep
+const maxParamValues = 20
+
+// paramSweepReq is the problem, solved by the method once per value of the parameter, other parameters are fixed
+type paramSweepReq struct {
+	F      string             `json:"f"`                // f(x,y) = y', with the parameter
+	Param  string             `json:"param"`            // name of the swept parameter, y0 sweeps initial values
+	Values []float64          `json:"values"`           // values of the parameter
+	Range  *sweepRange        `json:"range,omitempty"`  // evenly spaced values of the parameter instead of values
+	Params map[string]float64 `json:"params,omitempty"` // fixed parameters
+	Method string             `json:"method,omitempty"` // rk4 by default
+	X0     float64            `json:"x0"`
+	Y0     float64            `json:"y0"`
+	XEnd   float64            `json:"x_end"`
+	N      int                `json:"n,omitempty"`
+	Step   float64            `json:"step,omitempty"`
+}
+
+// sweepRange is k evenly spaced values from one to another, both included
+type sweepRange struct {
+	From float64 `json:"from"`
+	To   float64 `json:"to"`
+	K    int     `json:"k"`
+}
+
+// values returns values of the range, the single value is from
+func (r sweepRange) values() []float64 {
+	res := make([]float64, r.K)
+	for i := range res {
+		res[i] = r.From
+		if r.K > 1 {
+			res[i] = r.From + (r.To-r.From)*float64(i)/float64(r.K-1)
+		}
+	}
+	res[len(res)-1] = r.To
+	return res
+}
+
+// paramSweepResp contains the family of solutions in the order of values of the parameter
+type paramSweepResp struct {
+	Param  string      `json:"param"`
+	Method string      `json:"method"`
+	Step   float64     `json:"step"`
+	Lines  []paramLine `json:"lines"`
+	Took   string      `json:"took"`
+}
+
+// paramLine is the solution with the value of the parameter, the failed solution has the error and no points
+type paramLine struct {
+	Value  float64             `json:"value"`
+	Points []num.Point         `json:"points"`
+	Error  *rest.ErrorResponse `json:"error,omitempty"`
+}
+
+// paramSweep is the validated parameter sweep, ready to solve
+type paramSweep struct {
+	req       paramSweepReq
+	f         solver.ParamFunc
+	initial   solver.Func // f(x,y), if y0 is swept
+	step      float64
+	maxPoints int
+}
+
+// prepare validates the request, the problem with the first value of the parameter is validated as the solve
+// request is, in case of invalid request, rest.ValidationError with all invalid fields is returned
+func (req paramSweepReq) prepare(l solve.Limits) (paramSweep, error) {
+	if req.Method == "" {
+		req.Method = "rk4"
+	}
+	var errs rest.ValidationError
+	invalid := func(field, msg string, args ...interface{}) {
+		errs = append(errs, rest.FieldError{Field: field, Msg: fmt.Sprintf(msg, args...)})
+	}
+
+	req.Values = sweepValues("", req.Values, req.Range, invalid)
+
+	initial := req.Param == "y0" // y0 is reserved, but sweeps initial values
+	validParam := true
+	switch {
+	case !solve.ParamName.MatchString(req.Param):
+		invalid("param", "%q is not a valid name", req.Param)
+		validParam = false
+	case solve.IsReserved(req.Param) && !initial:
+		invalid("param", "%q is reserved", req.Param)
+		validParam = false
+	}
+	if _, ok := req.Params[req.Param]; ok && validParam {
+		invalid("params", "%s is swept, it can't be fixed", req.Param)
+	}
+
+	// the rest of the problem is validated as the solve request with the first value of the parameter
+	sr := solve.Request{F: req.F, X0: req.X0, Y0: req.Y0, XEnd: req.XEnd, N: req.N, Step: req.Step,
+		Methods: []string{req.Method}, Params: map[string]float64{}}
+	for name, v := range req.Params {
+		sr.Params[name] = v
+	}
+	switch {
+	case initial && len(req.Values) > 0:
+		sr.Y0 = req.Values[0]
+	case validParam && !initial && len(req.Values) > 0:
+		sr.Params[req.Param] = req.Values[0]
+	}
+	if req.Method == solve.ExactMethod {
+		invalid("method", "exact solution can't be swept")
+		sr.Methods = nil
+	}
+	p, err := sr.Prepare(l)
+	var verr rest.ValidationError
+	if errors.As(err, &verr) {
+		errs = append(errs, verr...)
+	}
+
+	if len(errs) > 0 {
+		return paramSweep{}, errs
+	}
+
+	if initial {
+		fxy, err := expr.Parse2(req.F, req.Params, "x", "y")
+		if err != nil {
+			return paramSweep{}, errors.Wrap(err, "can't parse f(x,y)")
+		}
+		return paramSweep{req: req, initial: fxy, step: p.Step, maxPoints: l.MaxPoints}, nil
+	}
+
+	fxy, err := expr.Parse(req.F, req.Params, "x", "y", req.Param)
+	if err != nil {
+		return paramSweep{}, errors.Wrap(err, "can't parse f(x,y)")
+	}
+	f := func(x, y float64, params map[string]float64) (float64, error) { return fxy(x, y, params[req.Param]) }
+	return paramSweep{req: req, f: f, step: p.Step, maxPoints: l.MaxPoints}, nil
+}
+
+// sweepValues returns values of the parameter, listed or evenly spaced by the range, invalid values
+// and ranges are reported by fields values and range with the prefix
+func sweepValues(prefix string, values []float64, rng *sweepRange,
+	invalid func(field, msg string, args ...interface{})) []float64 {
+	if rng != nil {
+		switch {
+		case len(values) > 0:
+			invalid(prefix+"range", "values and range are mutually exclusive")
+		case rng.K < 1 || rng.K > maxParamValues:
+			invalid(prefix+"range", "k must be between 1 and %d, got %d", maxParamValues, rng.K)
+		case !solve.IsFinite(rng.From) || !solve.IsFinite(rng.To):
+			invalid(prefix+"range", "from and to must be finite, got %v and %v", rng.From, rng.To)
+		case rng.K > 1 && rng.From == rng.To:
+			invalid(prefix+"range", "from and to must differ, got %v", rng.From)
+		default:
+			return rng.values()
+		}
+		return values
+	}
+
+	if len(values) == 0 || len(values) > maxParamValues {
+		invalid(prefix+"values", "must have between 1 and %d values, got %d", maxParamValues, len(values))
+	}
+	seen := map[float64]bool{}
+	for _, v := range values {
+		if !solve.IsFinite(v) {
+			invalid(prefix+"values", "%v must be finite", v)
+			continue
+		}
+		if seen[v] {
+			invalid(prefix+"values", "%v is repeated", v)
+		}
+		seen[v] = true
+	}
+	return values
+}
+
+// solve solves the problem with each value of the parameter, the failure of the value is reported
+// in its line, the sweep fails only if all values fail
+func (ps paramSweep) solve() (paramSweepResp, error) {
+	st := time.Now()
+	req := ps.req
+	var sols map[float64][]num.Point
+	var err error
+	if ps.initial != nil {
+		sols, err = solver.SweepInitial(ps.initial, req.Values, solve.Builder(req.Method), ps.step, req.X0, req.XEnd)
+	} else {
+		sols, err = solver.Sweep(ps.f, req.Param, req.Values, req.Params, solve.Builder(req.Method),
+			ps.step, req.X0, req.Y0, req.XEnd)
+	}
+	var serr *solver.SweepError
+	if err != nil && !errors.As(err, &serr) {
+		return paramSweepResp{}, err
+	}
+	if serr != nil && len(serr.Errs) == len(req.Values) {
+		return paramSweepResp{}, err
+	}
+
+	resp := paramSweepResp{Param: req.Param, Method: req.Method, Step: ps.step, Lines: make([]paramLine, len(req.Values))}
+	for i, v := range req.Values {
+		line := paramLine{Value: v, Points: []num.Point{}}
+		if pts, ok := sols[v]; ok {
+			line.Points = solve.Downsample(pts, ps.maxPoints)
+		} else if serr != nil {
+			be := rest.NewErrorResponse(errors.Wrapf(serr.Errs[v], "failed to solve with %s=%v", req.Param, v),
+				"failed to solve", rest.ErrInternal)
+			line.Error = &be
+		}
+		resp.Lines[i] = line
+	}
+	resp.Took = time.Since(st).String()
+	return resp, nil
+}
+
+// chart plots the family of solutions, failed values are skipped
+func (resp paramSweepResp) chart(pl graph.Plotter, img graph.Image) ([]byte, error) {
+	var values []float64
+	var lines [][]num.Point
+	for _, line := range resp.Lines {
+		if line.Error == nil {
+			values, lines = append(values, line.Value), append(lines, line.Points)
+		}
+	}
+	return pl.PlotGradient("Solutions by "+resp.Param, "X", "Y", resp.Param, values, lines, img)
+}
+
+// POST /api/v1/sweep?format=json|png|svg&width=800&height=600 - solve the problem once per value
+// of the parameter, png and svg formats render the family of solutions with the color bar
+func (s *Rest) paramSweepCtrl(w http.ResponseWriter, r *http.Request) {
+	var img graph.Image
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+	default:
+		var err error
+		if img, err = readChartImage(r); err != nil {
+			rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid chart parameters", rest.ErrBadRequest)
+			return
+		}
+	}
+
+	req := paramSweepReq{}
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "failed to decode request", rest.ErrDecode)
+		return
+	}
+
+	ps, err := req.prepare(s.limits())
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid sweep request", rest.ErrBadRequest)
+		return
+	}
+
+	resp, err := ps.solve()
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "failed to solve", rest.ErrInternal)
+		return
+	}
+
+	if img.Format == "" {
+		rest.RenderJSON(w, r, resp)
+		return
+	}
+	b, err := resp.chart(s.NumService.Plotter, img)
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "can't plot chart", rest.ErrInternal)
+		return
+	}
+	ct := "image/png"
+	if img.Format == "svg" {
+		ct = "image/svg+xml"
+	}
+	w.Header().Set("Content-Type", ct)
+	if _, err = w.Write(b); err != nil {
+		log.Printf("[WARN] failed to write chart, %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"image/png"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRest_ParamSweep(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	body := `{"f": "x^2 - b*y", "param": "b", "values": [1, 2, 3], "x0": 0, "y0": 1, "x_end": 1, "n": 10}`
+	resp, err := http.Post(ts.URL+"/api/v1/sweep", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := paramSweepResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.Equal(t, "b", res.Param)
+	assert.Equal(t, "rk4", res.Method)
+	assert.InDelta(t, 0.1, res.Step, 1e-12)
+	require.Len(t, res.Lines, 3)
+	for i, line := range res.Lines {
+		assert.Equal(t, float64(i+1), line.Value)
+		assert.Nil(t, line.Error)
+		require.Len(t, line.Points, 11)
+		if i > 0 {
+			assert.Less(t, line.Points[10].Y, res.Lines[i-1].Points[10].Y, "solutions decay faster with larger b")
+		}
+	}
+
+	resp, err = http.Post(ts.URL+"/api/v1/sweep?format=png&width=400&height=300", "application/json",
+		strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+	im, err := png.Decode(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, 400, im.Bounds().Dx())
+
+	// the failure of the value is isolated in its line
+	solver.Register("picky", func(f solver.Func) solver.Interface {
+		return &solver.Euler{F: func(x, y float64) (float64, error) {
+			res, err := f(x, y)
+			if res < 0 {
+				return 0, errors.New("negative slope")
+			}
+			return res, err
+		}}
+	})
+	defer solver.Unregister("picky")
+	body = `{"f": "k*b*y", "param": "b", "values": [1, -1], "params": {"k": 2}, "method": "picky",
+		"x0": 0, "y0": 1, "x_end": 1, "n": 10}`
+	resp, err = http.Post(ts.URL+"/api/v1/sweep", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res = paramSweepResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Lines, 2)
+	assert.Nil(t, res.Lines[0].Error)
+	require.NotNil(t, res.Lines[1].Error)
+	assert.Contains(t, res.Lines[1].Error.Error, "failed to solve with b=-1")
+	assert.Empty(t, res.Lines[1].Points)
+}
+
+func TestRest_ParamSweepInitial(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	body := `{"f": "-y", "param": "y0", "range": {"from": -1, "to": 1, "k": 5}, "x0": 0, "x_end": 1, "n": 10}`
+	resp, err := http.Post(ts.URL+"/api/v1/sweep", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := paramSweepResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.Equal(t, "y0", res.Param)
+	require.Len(t, res.Lines, 5)
+	for i, line := range res.Lines {
+		y0 := -1 + 0.5*float64(i)
+		assert.InDelta(t, y0, line.Value, 1e-12)
+		assert.Nil(t, line.Error)
+		require.Len(t, line.Points, 11)
+		assert.InDelta(t, y0, line.Points[0].Y, 1e-12, "solutions start at swept values")
+		assert.InDelta(t, y0*math.Exp(-1), line.Points[10].Y, 1e-5)
+	}
+}
+
+func TestRest_ParamSweepInvalid(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	tbl := []struct {
+		body  string
+		field string
+		msg   string
+	}{
+		{`{"f": "b*y", "param": "y", "values": [1], "x_end": 1, "n": 10}`, "param", `"y" is reserved`},
+		{`{"f": "b*y", "param": "b", "values": [], "x_end": 1, "n": 10}`, "values", "must have between 1 and 20 values, got 0"},
+		{`{"f": "b*y", "param": "b", "values": [1, 1], "x_end": 1, "n": 10}`, "values", "1 is repeated"},
+		{`{"f": "b*y", "param": "b", "values": [1], "params": {"b": 1}, "x_end": 1, "n": 10}`, "params",
+			"b is swept, it can't be fixed"},
+		{`{"f": "b*y", "param": "b", "values": [1], "x_end": 1, "n": 10, "method": "exact"}`, "method",
+			"exact solution can't be swept"},
+		{`{"f": "b*y", "param": "b", "values": [1], "x_end": 0, "n": 10}`, "x_end",
+			"must differ from x0, the interval of zero length can't be split into n steps"},
+		{`{"f": "b*y", "param": "b", "values": [1], "range": {"from": 0, "to": 1, "k": 2}, "x_end": 1, "n": 10}`,
+			"range", "values and range are mutually exclusive"},
+		{`{"f": "b*y", "param": "b", "range": {"from": 0, "to": 1, "k": 21}, "x_end": 1, "n": 10}`, "range",
+			"k must be between 1 and 20, got 21"},
+		{`{"f": "b*y", "param": "b", "range": {"from": 1, "to": 1, "k": 2}, "x_end": 1, "n": 10}`, "range",
+			"from and to must differ, got 1"},
+		{`{"f": "b*y", "param": "b", "values": [1], "x_end": 1, "n": 10, "method": "rk5"}`, "methods", `unknown method "rk5"`},
+	}
+	for _, tt := range tbl {
+		resp, err := http.Post(ts.URL+"/api/v1/sweep", "application/json", strings.NewReader(tt.body))
+		require.NoError(t, err)
+		er := rest.ErrorResponse{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&er))
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, tt.msg)
+		assert.Contains(t, er.Errors, rest.FieldError{Field: tt.field, Msg: tt.msg})
+	}
+}
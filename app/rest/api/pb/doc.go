@@ -0,0 +1,4 @@
+// Package pb contains the grpc api of the solver, generated from solver.proto
+package pb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative solver.proto
@@ -0,0 +1,169 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion7
+
+// SolverClient is the client API for Solver service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SolverClient interface {
+	// Solve solves the problem with the requested methods at once, the failure of the method is reported
+	// in its line, the call fails only if all methods fail
+	Solve(ctx context.Context, in *SolveRequest, opts ...grpc.CallOption) (*SolveResponse, error)
+	// SolveStream streams points of methods, as they are solved, one method after another,
+	// the last event is the summary of lines
+	SolveStream(ctx context.Context, in *SolveRequest, opts ...grpc.CallOption) (Solver_SolveStreamClient, error)
+}
+
+type solverClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSolverClient(cc grpc.ClientConnInterface) SolverClient {
+	return &solverClient{cc}
+}
+
+func (c *solverClient) Solve(ctx context.Context, in *SolveRequest, opts ...grpc.CallOption) (*SolveResponse, error) {
+	out := new(SolveResponse)
+	err := c.cc.Invoke(ctx, "/decompract.v1.Solver/Solve", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *solverClient) SolveStream(ctx context.Context, in *SolveRequest, opts ...grpc.CallOption) (Solver_SolveStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Solver_serviceDesc.Streams[0], "/decompract.v1.Solver/SolveStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &solverSolveStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Solver_SolveStreamClient interface {
+	Recv() (*SolveEvent, error)
+	grpc.ClientStream
+}
+
+type solverSolveStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *solverSolveStreamClient) Recv() (*SolveEvent, error) {
+	m := new(SolveEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SolverServer is the server API for Solver service.
+// All implementations must embed UnimplementedSolverServer
+// for forward compatibility
+type SolverServer interface {
+	// Solve solves the problem with the requested methods at once, the failure of the method is reported
+	// in its line, the call fails only if all methods fail
+	Solve(context.Context, *SolveRequest) (*SolveResponse, error)
+	// SolveStream streams points of methods, as they are solved, one method after another,
+	// the last event is the summary of lines
+	SolveStream(*SolveRequest, Solver_SolveStreamServer) error
+	mustEmbedUnimplementedSolverServer()
+}
+
+// UnimplementedSolverServer must be embedded to have forward compatible implementations.
+type UnimplementedSolverServer struct {
+}
+
+func (UnimplementedSolverServer) Solve(context.Context, *SolveRequest) (*SolveResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Solve not implemented")
+}
+func (UnimplementedSolverServer) SolveStream(*SolveRequest, Solver_SolveStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method SolveStream not implemented")
+}
+func (UnimplementedSolverServer) mustEmbedUnimplementedSolverServer() {}
+
+// UnsafeSolverServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SolverServer will
+// result in compilation errors.
+type UnsafeSolverServer interface {
+	mustEmbedUnimplementedSolverServer()
+}
+
+func RegisterSolverServer(s grpc.ServiceRegistrar, srv SolverServer) {
+	s.RegisterService(&_Solver_serviceDesc, srv)
+}
+
+func _Solver_Solve_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SolveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SolverServer).Solve(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/decompract.v1.Solver/Solve",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SolverServer).Solve(ctx, req.(*SolveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Solver_SolveStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SolveRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SolverServer).SolveStream(m, &solverSolveStreamServer{stream})
+}
+
+type Solver_SolveStreamServer interface {
+	Send(*SolveEvent) error
+	grpc.ServerStream
+}
+
+type solverSolveStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *solverSolveStreamServer) Send(m *SolveEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _Solver_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "decompract.v1.Solver",
+	HandlerType: (*SolverServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Solve",
+			Handler:    _Solver_Solve_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SolveStream",
+			Handler:       _Solver_SolveStream_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "solver.proto",
+}
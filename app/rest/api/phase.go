@@ -0,0 +1,207 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/expr"
+	"github.com/Semior001/decompract/app/num/graph"
+	"github.com/Semior001/decompract/app/num/phase"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	log "github.com/go-pkgz/lgr"
+)
+
+// limits of the phase portrait, the number of initial conditions and the number of points of all trajectories
+const (
+	maxPhaseSeeds  = 100
+	maxPhasePoints = 100000
+)
+
+// defaults of the phase portrait request
+const (
+	defaultPhaseT1    = 10
+	defaultPhaseN     = 500
+	defaultPhaseNodes = 5
+)
+
+// phaseReq is the phase portrait of the system y1' = f1(t,y1,y2), y2' = f2(t,y1,y2), solved over [t0, t1]
+// from the nx*ny grid of initial conditions over the rectangle
+type phaseReq struct {
+	F1, F2                     string
+	Params                     map[string]float64
+	T0, T1                     float64
+	N                          int
+	Y1Min, Y1Max, Y2Min, Y2Max float64
+	NX, NY                     int
+}
+
+// phaseResp contains trajectories in the (y1, y2) plane, trajectories, that blow up, are skipped
+type phaseResp struct {
+	Trajectories [][]num.Point `json:"trajectories"`
+	Skipped      int           `json:"skipped"`
+	Took         string        `json:"took"`
+}
+
+// prepare validates the request and makes the portrait with its seeds, in case of invalid request,
+// rest.ValidationError with all invalid fields is returned
+func (req phaseReq) prepare(l solve.Limits) (phase.Portrait, []num.Point, error) {
+	var errs rest.ValidationError
+	invalid := func(field, msg string, args ...interface{}) {
+		errs = append(errs, rest.FieldError{Field: field, Msg: fmt.Sprintf(msg, args...)})
+	}
+
+	for _, v := range []struct {
+		name string
+		val  float64
+	}{{"t0", req.T0}, {"t1", req.T1}, {"y1min", req.Y1Min}, {"y1max", req.Y1Max}, {"y2min", req.Y2Min}, {"y2max", req.Y2Max}} {
+		if !solve.IsFinite(v.val) {
+			invalid(v.name, "must be finite")
+		}
+	}
+	if !(req.T0 < req.T1) {
+		invalid("t1", "must be greater than t0")
+	}
+	if req.Y1Min > req.Y1Max {
+		invalid("y1max", "must not be less than y1min")
+	}
+	if req.Y2Min > req.Y2Max {
+		invalid("y2max", "must not be less than y2min")
+	}
+	if req.N < 1 || req.N > l.MaxSteps {
+		invalid("n", "must be between 1 and max_steps=%d, got %d", l.MaxSteps, req.N)
+	}
+	if req.NX < 1 || req.NY < 1 || req.NX*req.NY > maxPhaseSeeds {
+		invalid("nx", "grid of seeds %dx%d must have between 1 and %d nodes", req.NX, req.NY, maxPhaseSeeds)
+	} else if pts := req.NX * req.NY * (req.N + 1); req.N > 0 && pts > maxPhasePoints {
+		invalid("n", "gives %d points of trajectories, more than %d", pts, maxPhasePoints)
+	}
+	for _, name := range solve.ParamNames(req.Params) {
+		switch {
+		case !solve.ParamName.MatchString(name):
+			invalid("params", "%q is not a valid name", name)
+		case name == "t" || name == "y1" || name == "y2" || solve.IsReserved(name):
+			invalid("params", "%q is reserved", name)
+		case !solve.IsFinite(req.Params[name]):
+			invalid("params", "%s must be finite", name)
+		}
+	}
+
+	f1, err := expr.Parse(req.F1, req.Params, "t", "y1", "y2")
+	if err != nil {
+		invalid("f1", "can't parse f1(t,y1,y2): %v", err)
+	}
+	f2, err := expr.Parse(req.F2, req.Params, "t", "y1", "y2")
+	if err != nil {
+		invalid("f2", "can't parse f2(t,y1,y2): %v", err)
+	}
+	if len(errs) > 0 {
+		return phase.Portrait{}, nil, errs
+	}
+
+	seeds, err := phase.Seeds(req.Y1Min, req.Y1Max, req.Y2Min, req.Y2Max, req.NX, req.NY)
+	if err != nil {
+		return phase.Portrait{}, nil, err
+	}
+	sys := func(t, y1, y2 float64) (d1, d2 float64, err error) {
+		if d1, err = f1(t, y1, y2); err != nil {
+			return 0, 0, err
+		}
+		if d2, err = f2(t, y1, y2); err != nil {
+			return 0, 0, err
+		}
+		return d1, d2, nil
+	}
+	return phase.Portrait{F: sys, T0: req.T0, T1: req.T1, N: req.N}, seeds, nil
+}
+
+// readPhaseQuery reads the phase portrait request from query parameters
+func readPhaseQuery(r *http.Request) (req phaseReq, err error) {
+	if req.F1, err = queryFormula(r, "f1"); err != nil {
+		return phaseReq{}, err
+	}
+	if req.F2, err = queryFormula(r, "f2"); err != nil {
+		return phaseReq{}, err
+	}
+	if req.Params, err = queryParams(r); err != nil {
+		return phaseReq{}, err
+	}
+	for _, v := range []struct {
+		name string
+		dst  *float64
+		def  float64
+	}{{"t0", &req.T0, 0}, {"t1", &req.T1, defaultPhaseT1}, {"y1min", &req.Y1Min, -1}, {"y1max", &req.Y1Max, 1},
+		{"y2min", &req.Y2Min, -1}, {"y2max", &req.Y2Max, 1}} {
+		if *v.dst, err = queryFloat(r, v.name, v.def); err != nil {
+			return phaseReq{}, err
+		}
+	}
+	if req.N, err = queryInt(r, "n", defaultPhaseN); err != nil {
+		return phaseReq{}, err
+	}
+	if req.NX, err = queryInt(r, "nx", defaultPhaseNodes); err != nil {
+		return phaseReq{}, err
+	}
+	if req.NY, err = queryInt(r, "ny", defaultPhaseNodes); err != nil {
+		return phaseReq{}, err
+	}
+	return req, nil
+}
+
+// GET /api/v1/chart/phase?f1=y2&f2=-y1&t0=0&t1=10&n=500&y1min=-1&y1max=1&y2min=-1&y2max=1&nx=5&ny=5&format=json|png|svg
+// - solve the system from the grid of initial conditions and render trajectories in the (y1, y2) plane,
+// png and svg images have the width and the height as in the chart request
+func (s *Rest) phaseCtrl(w http.ResponseWriter, r *http.Request) {
+	st := time.Now()
+	req, err := readPhaseQuery(r)
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "failed to read query parameters", rest.ErrDecode)
+		return
+	}
+
+	var img graph.Image
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" {
+		if img, err = readChartImage(r); err != nil {
+			rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid chart parameters", rest.ErrBadRequest)
+			return
+		}
+	}
+
+	p, seeds, err := req.prepare(s.limits())
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid phase portrait request", rest.ErrBadRequest)
+		return
+	}
+
+	trs, err := p.Trajectories(seeds)
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "failed to solve the system", rest.ErrInternal)
+		return
+	}
+
+	if trs == nil {
+		trs = [][]num.Point{}
+	}
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	if img.Format == "" {
+		rest.RenderJSON(w, r, phaseResp{Trajectories: trs, Skipped: len(seeds) - len(trs), Took: time.Since(st).String()})
+		return
+	}
+
+	b, err := s.NumService.Plotter.PlotPhase("Phase portrait", "Y1", "Y2", trs, img)
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "can't plot phase portrait", rest.ErrInternal)
+		return
+	}
+	ct := "image/png"
+	if img.Format == "svg" {
+		ct = "image/svg+xml"
+	}
+	w.Header().Set("Content-Type", ct)
+	if _, err = w.Write(b); err != nil {
+		log.Printf("[WARN] failed to write phase portrait, %v", err)
+	}
+}
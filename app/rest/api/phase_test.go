@@ -0,0 +1,103 @@
+package api
+
+import (
+	"encoding/json"
+	"image/png"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func phaseURL(ts string, q url.Values) string {
+	return ts + "/api/v1/chart/phase?" + strings.ReplaceAll(q.Encode(), "+", "%20")
+}
+
+func TestRest_Phase(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	// the pendulum y1' = y2, y2' = -k*sin(y1)
+	q := url.Values{"f1": {"y2"}, "f2": {"-k*sin(y1)"}, "param": {"k:1"}, "t1": {"6.28"}, "n": {"100"},
+		"nx": {"3"}, "ny": {"2"}}
+	resp, err := http.Get(phaseURL(ts.URL, q))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := phaseResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Trajectories, 6)
+	assert.Zero(t, res.Skipped)
+	assert.NotEmpty(t, res.Took)
+	for _, tr := range res.Trajectories {
+		require.Len(t, tr, 101)
+		// the energy y2^2/2 - cos(y1) is conserved
+		e0 := tr[0].Y*tr[0].Y/2 - math.Cos(tr[0].X)
+		for _, pt := range tr {
+			assert.InDelta(t, e0, pt.Y*pt.Y/2-math.Cos(pt.X), 1e-5)
+		}
+	}
+
+	// y1' = y1^2 blows up from positive y1
+	q = url.Values{"f1": {"y1^2"}, "f2": {"0"}, "t1": {"2"}, "n": {"100"}, "nx": {"2"}, "ny": {"1"}}
+	resp, err = http.Get(phaseURL(ts.URL, q))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	res = phaseResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.Len(t, res.Trajectories, 1)
+	assert.Equal(t, 1, res.Skipped)
+
+	q.Set("format", "png")
+	resp, err = http.Get(phaseURL(ts.URL, q))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+	_, err = png.Decode(resp.Body)
+	require.NoError(t, err)
+}
+
+func TestRest_PhaseInvalid(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	base := url.Values{"f1": {"y2"}, "f2": {"-y1"}}
+	tbl := []struct {
+		params url.Values
+		field  string
+		msg    string
+	}{
+		{url.Values{"nx": {"20"}, "ny": {"20"}}, "nx", "grid of seeds 20x20 must have between 1 and 100 nodes"},
+		{url.Values{"nx": {"10"}, "ny": {"10"}, "n": {"5000"}}, "n", "gives 500100 points of trajectories, more than 100000"},
+		{url.Values{"t1": {"-1"}}, "t1", "must be greater than t0"},
+		{url.Values{"y1min": {"2"}}, "y1max", "must not be less than y1min"},
+		{url.Values{"n": {"0"}}, "n", "must be between 1 and max_steps=10000, got 0"},
+		{url.Values{"param": {"y1:1"}}, "params", `"y1" is reserved`},
+		{url.Values{"f2": {"-y1("}}, "f2", `can't parse f2(t,y1,y2): missing operator before "(", e.g. 2*x at position 4`},
+	}
+	for _, tt := range tbl {
+		q := url.Values{}
+		for k, v := range base {
+			q[k] = v
+		}
+		for k, v := range tt.params {
+			q[k] = v
+		}
+		resp, err := http.Get(phaseURL(ts.URL, q))
+		require.NoError(t, err)
+		er := rest.ErrorResponse{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&er))
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, tt.msg)
+		assert.Contains(t, er.Errors, rest.FieldError{Field: tt.field, Msg: tt.msg})
+	}
+
+	resp, err := http.Get(phaseURL(ts.URL, url.Values{"f1": {"y2"}, "f2": {"-y1"}, "format": {"xml"}}))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
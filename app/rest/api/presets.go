@@ -0,0 +1,81 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+)
+
+// higherPresets are the named equations of higher orders, solved by the higher order request
+var higherPresets = map[string]higherPreset{
+	// y = y0*cos(w*(x-x0)) + dy0/w*sin(w*(x-x0)), the energy of the oscillator is kept by the exact solution only
+	"harmonic": {Description: "the harmonic oscillator y'' = -w^2*y, y = cos(w*x) with y(0) = 1 and y'(0) = 0",
+		req: higherReq{F: "-w^2*y", Params: map[string]float64{"w": 1}, X0: 0, Y0: []float64{1, 0}, XEnd: 4 * math.Pi,
+			N: 200}},
+}
+
+// higherPreset is the named equation of the higher order
+type higherPreset struct {
+	Description string
+	req         higherReq
+}
+
+// withPreset returns the request with the equation of its preset, f, x0, y0 and x_end are taken from the preset,
+// params of the request override params of the preset, n of the preset is used, unless the request sets it
+func (req higherReq) withPreset() (higherReq, error) {
+	if req.Preset == "" {
+		return req, nil
+	}
+	pr, ok := higherPresets[req.Preset]
+	if !ok {
+		names := make([]string, 0, len(higherPresets))
+		for name := range higherPresets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return higherReq{}, rest.ValidationError{{Field: "preset", Msg: fmt.Sprintf("unknown preset %q, available: %s",
+			req.Preset, strings.Join(names, ", "))}}
+	}
+	if req.F != "" || len(req.Y0) > 0 {
+		return higherReq{}, rest.ValidationError{{Field: "preset",
+			Msg: "must not be set together with f or y0, as the preset sets the equation"}}
+	}
+
+	res := pr.req
+	res.Params = solve.MergeParams(pr.req.Params, req.Params)
+	res.Y0 = append([]float64{}, pr.req.Y0...)
+	res.Methods = req.Methods
+	if req.N != 0 {
+		res.N = req.N
+	}
+	return res, nil
+}
+
+// presetResp is the preset with the request, that solves its problem, methods are chosen by the client
+type presetResp struct {
+	ID          string      `json:"id"`
+	Description string      `json:"description"`
+	Endpoint    string      `json:"endpoint"` // path of the request, that solves the problem
+	Request     interface{} `json:"request"`
+}
+
+// GET /api/presets - list built-in problems, each of them is selected by its id in the preset field of the request
+func (s *Rest) presetsCtrl(w http.ResponseWriter, r *http.Request) {
+	res := make([]presetResp, 0, len(solve.Presets)+len(higherPresets))
+	for _, name := range solve.PresetNames(solve.Presets) {
+		prob := solve.Presets[name]
+		res = append(res, presetResp{ID: name, Description: prob.Description, Endpoint: "/api/v1/solve",
+			Request: prob.Request()})
+	}
+	for name, pr := range higherPresets {
+		res = append(res, presetResp{ID: name, Description: pr.Description, Endpoint: "/api/v1/solve/higher",
+			Request: pr.req})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	rest.RenderJSON(w, r, res)
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRest_Presets(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/api/presets")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var res []struct {
+		ID          string
+		Description string
+		Endpoint    string
+		Request     jsonMap
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+
+	ids := make([]string, 0, len(res))
+	for _, pr := range res {
+		ids = append(ids, pr.ID)
+		assert.NotEmpty(t, pr.Description, pr.ID)
+		assert.NotEmpty(t, pr.Request["f"], pr.ID)
+	}
+	assert.Equal(t, []string{"canonical", "dahlquist", "harmonic", "logistic", "stiff-decay"}, ids)
+	assert.Equal(t, "/api/v1/solve", res[1].Endpoint)
+	assert.Equal(t, "/api/v1/solve/higher", res[2].Endpoint)
+	assert.Equal(t, []interface{}{1.0, 0.0}, res[2].Request["y0"])
+	assert.Equal(t, jsonMap{"lambda": -100.0}, res[1].Request["params"])
+}
+
+func TestRest_SolvePreset(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	// the logistic growth with the doubled rate, the exact solution is solved as the method
+	body := `{"preset": "logistic", "params": {"r": 2}, "n": 50, "methods": ["rk4", "exact"]}`
+	resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.InDelta(t, 0.2, res.Step, 1e-12)
+	require.Len(t, res.Lines, 2)
+	require.Len(t, res.Lines[0].Points, 51)
+	for i, p := range res.Lines[0].Points {
+		exact := 10 / (1 + 9*math.Exp(-2*p.X))
+		assert.InDelta(t, exact, p.Y, 1e-3, "x=%v", p.X)
+		assert.InDelta(t, exact, res.Lines[1].Points[i].Y, 1e-9, "x=%v", p.X)
+	}
+
+	// steps of the preset are used without n
+	resp, err = http.Get(ts.URL + "/api/v1/solve?preset=canonical&method=euler")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res = solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Lines, 1)
+	assert.Len(t, res.Lines[0].Points, 31)
+	assert.Equal(t, -4.0, res.Lines[0].Points[0].X)
+
+	// the harmonic oscillator is cos(x) with the period of 2*pi
+	body = `{"preset": "harmonic", "methods": ["rk4"]}`
+	resp, err = http.Post(ts.URL+"/api/v1/solve/higher", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	hr := higherResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&hr))
+	require.Len(t, hr.Lines, 1)
+	pts := hr.Lines[0].Components[0].Points
+	require.Len(t, pts, 201)
+	for _, p := range pts {
+		assert.InDelta(t, math.Cos(p.X), p.Y, 1e-5, "x=%v", p.X)
+	}
+}
+
+func TestRest_SolvePresetInvalid(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	tbl := []struct {
+		path, body string
+		msg        string
+	}{
+		{"/api/v1/solve", `{"preset": "unknown", "methods": ["rk4"]}`,
+			`unknown preset "unknown", available: canonical, dahlquist, logistic, stiff-decay`},
+		{"/api/v1/solve", `{"preset": "canonical", "f": "x", "methods": ["rk4"]}`,
+			"must not be set together with f, exact, c, linear or c_bracket, as the preset sets the problem"},
+		{"/api/v1/solve/higher", `{"preset": "canonical", "methods": ["rk4"]}`,
+			`unknown preset "canonical", available: harmonic`},
+		{"/api/v1/solve/higher", `{"preset": "harmonic", "y0": [0, 1], "methods": ["rk4"]}`,
+			"must not be set together with f or y0, as the preset sets the equation"},
+	}
+	for _, tt := range tbl {
+		resp, err := http.Post(ts.URL+tt.path, "application/json", strings.NewReader(tt.body))
+		require.NoError(t, err)
+		er := rest.ErrorResponse{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&er))
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, tt.msg)
+		assert.Contains(t, er.Errors, rest.FieldError{Field: "preset", Msg: tt.msg})
+	}
+
+	resp, err := http.Get(ts.URL + "/api/v1/solve?preset=unknown&method=rk4")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRest_SolveAutoRefine(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(
+		`{"f": "y*y*exp(x) - 2*y", "exact": "exp(-x) / (c*exp(x) + 1)", "c": "(exp(-x0) - y0) / (y0 * exp(x0))",
+		"x0": -4, "y0": 1, "x_end": 4, "n": 30, "methods": ["rk4", "euler", "exact"], "auto_refine": {"tol": 1e-6}}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.True(t, res.GridsDiffer)
+	require.Len(t, res.Lines, 3)
+
+	rk4 := res.Lines[0]
+	require.Nil(t, rk4.Error)
+	require.NotNil(t, rk4.Refine)
+	assert.Equal(t, solve.RefineResp{N: 240, Doublings: 3, Diffs: rk4.Refine.Diffs, Converged: true}, *rk4.Refine)
+	assert.Len(t, rk4.Points, 241)
+	assert.InDelta(t, 8.0/240, rk4.Step, 1e-12)
+	assert.Empty(t, rk4.Stats.Warnings)
+
+	// euler is not converged up to max_steps, the finest solution is returned anyway
+	euler := res.Lines[1]
+	require.Nil(t, euler.Error)
+	require.NotNil(t, euler.Refine)
+	assert.False(t, euler.Refine.Converged)
+	assert.Equal(t, 8, euler.Refine.Doublings, "30*2^8 steps fit into 10000")
+	assert.Equal(t, 7680, euler.Refine.N)
+	assert.Equal(t, []string{"not converged to tol=1e-06 after 8 doublings"}, euler.Stats.Warnings)
+	assert.Greater(t, euler.Stats.Evals, rk4.Stats.Evals)
+
+	exact := res.Lines[2]
+	assert.Nil(t, exact.Refine, "the exact solution is drawn on the initial grid")
+	assert.Len(t, exact.Points, 31)
+}
+
+func TestRest_SolveAutoRefineErrors(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	for _, tt := range []struct{ body, msg string }{
+		{`{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "step": 0.1, "methods": ["rk4"], "auto_refine": {"tol": 1e-6}}`,
+			"must be set with n, the initial number of steps"},
+		{`{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"], "sensitivity": true, "auto_refine": {"tol": 1e-6}}`,
+			"must not be set together with warm or sensitivity"},
+		{`{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"], "auto_refine": {"tol": 0}}`,
+			"tol must be positive and finite, got 0"},
+		{`{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"], "auto_refine": {"tol": 1e-6, "max_doublings": -1}}`,
+			"max_doublings must not be negative, got -1"},
+		{`{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 6000, "methods": ["rk4"], "auto_refine": {"tol": 1e-6}}`,
+			"n=6000 can't be doubled under max_steps=10000"},
+		{`{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"], "auto_refine": {"tol": 1e-6, "max_doublings": 10}}`,
+			"max_doublings=10 gives more steps than max_steps=10000, at most 9 doublings are allowed"},
+	} {
+		resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(tt.body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, tt.body)
+		e := rest.ErrorResponse{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&e))
+		assert.Equal(t, rest.ValidationError{{Field: "auto_refine", Msg: tt.msg}}, e.Errors, tt.body)
+	}
+}
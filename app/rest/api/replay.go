@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/pkg/errors"
+)
+
+// RunRecorder records failed solves to files in the directory to replay them, the oldest files are removed,
+// as soon as files take more than MaxSize bytes, the last file is kept anyway
+type RunRecorder struct {
+	Dir     string
+	MaxSize int64 // maximal total size of recorded files, unlimited if not positive
+
+	lock sync.Mutex
+	seq  int
+}
+
+// recordPrefix is the prefix of names of recorded files, names are ordered by the time of recording
+const recordPrefix = "run-"
+
+// Save writes the run to the new file in the directory and removes the oldest files beyond MaxSize,
+// the path of the file is returned
+func (rr *RunRecorder) Save(run *solve.RecordedRun) (string, error) {
+	data, err := json.MarshalIndent(run, "", "\t")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode run")
+	}
+
+	rr.lock.Lock()
+	defer rr.lock.Unlock()
+	if err = os.MkdirAll(rr.Dir, 0o750); err != nil {
+		return "", errors.Wrapf(err, "failed to make directory %s", rr.Dir)
+	}
+	rr.seq++
+	name := fmt.Sprintf("%s%s-%06d.json", recordPrefix, run.RecordedAt.UTC().Format("20060102T150405.000000000"), rr.seq%1000000)
+	path := filepath.Join(rr.Dir, name)
+	if err = ioutil.WriteFile(path, data, 0o640); err != nil {
+		return "", errors.Wrapf(err, "failed to write %s", path)
+	}
+	if err = rr.rotate(); err != nil {
+		return path, errors.Wrap(err, "failed to rotate recorded runs")
+	}
+	return path, nil
+}
+
+// rotate removes the oldest recorded files, until the rest take at most MaxSize bytes
+func (rr *RunRecorder) rotate() error {
+	if rr.MaxSize <= 0 {
+		return nil
+	}
+	entries, err := ioutil.ReadDir(rr.Dir)
+	if err != nil {
+		return err
+	}
+	var files []os.FileInfo
+	var total int64
+	for _, e := range entries {
+		if e.Mode().IsRegular() && strings.HasPrefix(e.Name(), recordPrefix) && filepath.Ext(e.Name()) == ".json" {
+			files = append(files, e)
+			total += e.Size()
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name() < files[j].Name() })
+	for len(files) > 1 && total > rr.MaxSize {
+		if err = os.Remove(filepath.Join(rr.Dir, files[0].Name())); err != nil {
+			return err
+		}
+		total -= files[0].Size()
+		files = files[1:]
+	}
+	return nil
+}
+
+// record saves the run of the problem, if it failed, a request, canceled by the client, is not recorded
+func (rr *RunRecorder) record(ctx context.Context, p solve.Prepared, resp solve.Response, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	run := p.Recorder.Run(p, resp, err)
+	if !run.Failed() {
+		return
+	}
+	path, err := rr.Save(run)
+	if err != nil {
+		rest.CtxLogger(ctx).Logf("[WARN] failed to record the failed run, %v", err)
+		return
+	}
+	rest.CtxLogger(ctx).Logf("[INFO] failed run is recorded to %s", path)
+}
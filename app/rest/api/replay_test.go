@@ -0,0 +1,138 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordRun solves the request under the limits and records its run
+func recordRun(t *testing.T, req solve.Request, l solve.Limits) *solve.RecordedRun {
+	p, err := req.Prepare(l)
+	require.NoError(t, err)
+	p.Recorder = solve.NewRecorder()
+	resp, err := p.Solve(context.Background())
+	return p.Recorder.Run(p, resp, err)
+}
+
+func TestRecordedRun_Replay(t *testing.T) {
+	req := solve.Presets["canonical"].Request()
+	req.Methods = []string{"euler", "ieuler", "rk4", "exact"}
+	l := solve.Limits{MaxSteps: 10000, MaxPoints: 10}
+	run := recordRun(t, req, l)
+	require.Len(t, run.Lines, 4)
+	for _, line := range run.Lines {
+		assert.Len(t, line.Points, 31, line.Method)
+		assert.Empty(t, line.Error, line.Method)
+	}
+	assert.Equal(t, []string{"points are downsampled from 31 to 9 by max_points"}, run.Lines[3].Warnings,
+		"points are recorded before downsampling")
+
+	rr := &RunRecorder{Dir: t.TempDir()}
+	path, err := rr.Save(run)
+	require.NoError(t, err)
+	replayed, err := solve.Replay(path)
+	require.NoError(t, err)
+	assert.Equal(t, run.Lines, replayed.Lines, "points are kept with full precision")
+	assert.Equal(t, run.Request, replayed.Request)
+	assert.True(t, run.RecordedAt.Equal(replayed.RecordedAt))
+
+	div, err := replayed.Verify(context.Background(), l, 0)
+	require.NoError(t, err)
+	assert.Nil(t, div, "the run is reproduced exactly")
+
+	// the injected divergence is reported at the first differing point
+	replayed.Lines[1].Points[5].Y += 1e-6
+	replayed.Lines[2].Points[3].Y += 1e-6
+	div, err = replayed.Verify(context.Background(), l, 1e-9)
+	require.NoError(t, err)
+	require.NotNil(t, div)
+	assert.Equal(t, "ieuler", div.Method)
+	assert.Equal(t, 5, div.Index)
+	assert.Equal(t, replayed.Lines[1].Points[5], div.Want)
+	assert.InDelta(t, 1e-6, div.Want.Y-div.Got.Y, 1e-12)
+	assert.Contains(t, div.String(), "ieuler: point #5: ")
+
+	div, err = replayed.Verify(context.Background(), l, 1e-5)
+	require.NoError(t, err)
+	assert.Nil(t, div, "the divergence is within the tolerance")
+
+	_, err = solve.Replay(filepath.Join(t.TempDir(), "unknown.json"))
+	assert.Error(t, err)
+}
+
+func TestRunRecorder_Rotate(t *testing.T) {
+	dir := t.TempDir()
+	run := &solve.RecordedRun{Request: solve.Request{F: "x"}, Lines: []solve.RecordedLine{{Method: "euler", Points: make([]num.Point, 100)}}}
+	data, err := json.MarshalIndent(run, "", "\t")
+	require.NoError(t, err)
+
+	// the recorder keeps two runs at most
+	rr := &RunRecorder{Dir: dir, MaxSize: int64(2*len(data) + len(data)/2)}
+	var paths []string
+	for i := 0; i < 4; i++ {
+		run.RecordedAt = time.Date(2020, 1, 1, 0, 0, i, 0, time.UTC)
+		path, err := rr.Save(run)
+		require.NoError(t, err)
+		paths = append(paths, path)
+	}
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "other.json"), data, 0o600))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{paths[2], paths[3], filepath.Join(dir, "other.json")}, files)
+
+	// the last run is kept anyway
+	rr.MaxSize = 1
+	path, err := rr.Save(run)
+	require.NoError(t, err)
+	files, err = filepath.Glob(filepath.Join(dir, "run-*.json"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{path}, files)
+}
+
+func TestRest_RecordFailed(t *testing.T) {
+	breakMethod(t)
+	srv, ts := prepTestServer(t)
+	dir := t.TempDir()
+	srv.Recorder = &RunRecorder{Dir: dir}
+
+	resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json",
+		strings.NewReader(`{"f": "x^2-2*y", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"]}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	assert.Empty(t, files, "succeeded solves are not recorded")
+
+	resp, err = http.Get(ts.URL + "/api/v1/solve?f=x&x0=1&y0=1&x1=2&n=4&method=broken")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	files, err = filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	run, err := solve.Replay(files[0])
+	require.NoError(t, err)
+	assert.Equal(t, "x", run.Request.F)
+	assert.Contains(t, run.Error, "failed to solve with broken")
+	require.Len(t, run.Lines, 1)
+	assert.Contains(t, run.Lines[0].Error, "failed to solve with broken")
+	assert.Equal(t, []num.Point{{X: 1, Y: 1}}, run.Lines[0].Points, "the initial point is drawn before the failure")
+
+	div, err := run.Verify(context.Background(), srv.limits(), 0)
+	require.NoError(t, err)
+	assert.Nil(t, div, "the failure is reproduced")
+}
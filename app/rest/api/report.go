@@ -0,0 +1,238 @@
+package api
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/export"
+	"github.com/Semior001/decompract/app/num/graph"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	log "github.com/go-pkgz/lgr"
+	"github.com/pkg/errors"
+)
+
+// reportDigits is the number of significant digits of numbers in tables of the report
+const reportDigits = 6
+
+// GET /api/v1/report?f=x^2-2*y&x0=0&y0=1&x1=1&n=10&method=euler&method=rk4&format=md - solve the problem
+// and download the report with solutions, local errors and the convergence of methods as markdown or LaTeX,
+// numbers of steps of the convergence are doubled from n0 until n1
+func (s *Rest) reportCtrl(w http.ResponseWriter, r *http.Request) {
+	req, ok := readGetSolve(w, r)
+	if !ok {
+		return
+	}
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "":
+		format = "md"
+	case "md", "tex":
+	default:
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, errors.Errorf("unknown format %q", format),
+			"format must be md or tex", rest.ErrBadRequest)
+		return
+	}
+	swr := solve.SweepReq{Request: req, Geometric: true}
+	var err error
+	if swr.N0, err = queryInt(r, "n0", defaultSweepN0); err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "failed to read query parameters", rest.ErrDecode)
+		return
+	}
+	if swr.N1, err = queryInt(r, "n1", defaultSweepN1); err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "failed to read query parameters", rest.ErrDecode)
+		return
+	}
+
+	// the exact solution has no errors, so it isn't swept
+	swr.Methods = nil
+	for _, m := range req.Methods {
+		if m != solve.ExactMethod {
+			swr.Methods = append(swr.Methods, m)
+		}
+	}
+	var sw *solve.Sweep
+	if len(swr.Methods) > 0 {
+		prepared, err := swr.Prepare(s.limits())
+		if err != nil {
+			rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid report request", rest.ErrBadRequest)
+			return
+		}
+		prepared.Workers = s.SweepWorkers
+		sw = &prepared
+	}
+
+	// local errors are reported, if the exact solution is known
+	req.Errors = req.Exact != "" || req.Linear != nil
+	resp, ok := s.solveRequest(w, r, req)
+	if !ok {
+		return
+	}
+	report, err := s.report(req, resp, format == "md")
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "can't make report", rest.ErrInternal)
+		return
+	}
+	if sw != nil {
+		conv, ok := s.reportConvergence(w, r, *sw, format == "md")
+		if !ok {
+			return
+		}
+		report.Sections = append(report.Sections, conv)
+	}
+
+	buf := rest.GetBuffer()
+	defer rest.PutBuffer(buf)
+	ct := "text/markdown; charset=utf-8"
+	if format == "tex" {
+		ct = "application/x-tex; charset=utf-8"
+		err = report.WriteLaTeX(buf, reportDigits-1)
+	} else {
+		err = report.WriteMarkdown(buf)
+	}
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "can't write report", rest.ErrInternal)
+		return
+	}
+
+	name := "report" + strings.TrimPrefix(strings.TrimSuffix(csvFilename(req.F), ".csv"), "solution") + "." + format
+	w.Header().Set("Content-Type", ct)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	if _, err = w.Write(buf.Bytes()); err != nil {
+		log.Printf("[WARN] failed to write report, %v", err)
+	}
+}
+
+// report makes the report with the problem, solutions and local errors of the solved request,
+// images of charts are rendered only to embed, as LaTeX plots lines by itself
+func (s *Rest) report(req solve.Request, resp solve.Response, embed bool) (export.Report, error) {
+	report := export.Report{Title: "Solution of y' = " + req.F, Problem: []export.Field{{Name: "f(x,y)", Value: req.F}}}
+	if req.Exact != "" {
+		report.Problem = append(report.Problem, export.Field{Name: "y(x,c)", Value: req.Exact})
+	}
+	if req.C != "" {
+		report.Problem = append(report.Problem, export.Field{Name: "c(x0,y0)", Value: req.C})
+	}
+	if req.Linear != nil {
+		report.Problem = append(report.Problem, export.Field{Name: "p(x)", Value: req.Linear.P},
+			export.Field{Name: "q(x)", Value: req.Linear.Q})
+	}
+	for _, name := range solve.ParamNames(req.Params) {
+		report.Problem = append(report.Problem, export.Field{Name: name, Value: solve.FormatFloat(req.Params[name])})
+	}
+	report.Problem = append(report.Problem,
+		export.Field{Name: "y0", Value: "y(" + solve.FormatFloat(req.X0) + ") = " + solve.FormatFloat(req.Y0)},
+		export.Field{Name: "interval", Value: "[" + solve.FormatFloat(req.X0) + ", " + solve.FormatFloat(req.XEnd) + "]"},
+		export.Field{Name: "step", Value: solve.FormatFloat(resp.Step)},
+		export.Field{Name: "methods", Value: strings.Join(req.Methods, ", ")},
+	)
+
+	var err error
+	var lines []num.Line
+	var failed []string
+	for _, line := range resp.Lines {
+		if line.Error != nil {
+			failed = append(failed, line.Name+": "+line.Error.Error)
+			continue
+		}
+		lines = append(lines, num.Line{Name: line.Name, Points: line.Points})
+	}
+	if resp.Exact != nil {
+		lines = append(lines, num.Line{Name: resp.Exact.Name, Points: resp.Exact.Points})
+	}
+	sol := export.Section{Title: "Solutions"}
+	sol.Header, sol.Rows = resp.Table(reportDigits)
+	if len(failed) > 0 {
+		sol.Text = "Failed: " + strings.Join(failed, "; ")
+	}
+	if len(lines) > 0 {
+		sol.Chart = &export.Chart{XLabel: "X", YLabel: "Y", Lines: lines}
+		if sol.Chart.PNG, err = s.reportChart("Solutions", "Y", lines, embed); err != nil {
+			return export.Report{}, err
+		}
+	}
+	stats := export.Section{Title: "Summary"}
+	stats.Header, stats.Rows = resp.Summary(reportDigits)
+	report.Sections = append(report.Sections, sol, stats)
+
+	if len(resp.Errors) > 0 {
+		lte := export.Section{Title: "Local errors",
+			Text: "Absolute differences between methods and the exact solution at nodes of methods."}
+		errLines := make([]num.Line, 0, len(resp.Errors))
+		lte.Header = []string{"method", "x", "error"}
+		for _, et := range resp.Errors {
+			errLines = append(errLines, num.Line{Name: et.Name, Points: et.Points})
+			for _, pt := range et.Points {
+				lte.Rows = append(lte.Rows, []string{et.Method, rest.NumberFormat{Digits: reportDigits}.Format(pt.X), rest.NumberFormat{Digits: reportDigits}.Format(pt.Y)})
+			}
+		}
+		lte.Chart = &export.Chart{XLabel: "X", YLabel: "Err", Lines: errLines}
+		if lte.Chart.PNG, err = s.reportChart("Local errors", "Err", errLines, embed); err != nil {
+			return export.Report{}, err
+		}
+		report.Sections = append(report.Sections, lte)
+	}
+	return report, nil
+}
+
+// reportConvergence runs the sweep and makes the section with global errors and orders of methods, the image
+// of the chart is rendered only to embed, responds with the error and returns false, if the sweep fails
+func (s *Rest) reportConvergence(w http.ResponseWriter, r *http.Request, sw solve.Sweep, embed bool) (export.Section, bool) {
+	resp, err := s.sweepCached(r.Context(), w, sw)
+	if err != nil {
+		var te *solve.TimeoutError
+		if errors.As(err, &te) {
+			s.sendTimeout(w, r, te)
+			return export.Section{}, false
+		}
+		var be *busyError
+		if errors.As(err, &be) {
+			sendBusy(w, r, be)
+			return export.Section{}, false
+		}
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "failed to calculate errors", rest.ErrInternal)
+		return export.Section{}, false
+	}
+
+	sec := export.Section{Title: "Convergence"}
+	sec.Header, sec.Rows = resp.Table(0)
+	orders := make([]string, 0, len(resp.Lines))
+	for _, line := range resp.Lines {
+		if line.Order != nil {
+			orders = append(orders, line.Name+" "+strconv.FormatFloat(*line.Order, 'f', 3, 64))
+		}
+	}
+	sec.Text = "Max global errors against the reference: " + resp.Reference + "."
+	if len(orders) > 0 {
+		sec.Text += " Fitted orders: " + strings.Join(orders, ", ") + "."
+	}
+	// errors, dominated by rounding, are not plotted, so the chart might be missing
+	if lines := resp.GTELines(); len(lines) > 0 {
+		sec.Chart = &export.Chart{XLabel: "N", YLabel: "GTE", LogLog: true, Lines: lines}
+	}
+	if sec.Chart != nil && embed {
+		img := graph.Image{Width: defaultChartWidth, Height: defaultChartHeight, Format: "png"}
+		if sec.Chart.PNG, err = resp.Chart(s.NumService.Plotter, img); err != nil {
+			rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "can't plot errors", rest.ErrInternal)
+			return export.Section{}, false
+		}
+	}
+	return sec, true
+}
+
+// reportChart renders the image of the chart of lines to embed into the report, nothing is rendered, unless embed
+func (s *Rest) reportChart(title, yLabel string, lines []num.Line, embed bool) ([]byte, error) {
+	if !embed {
+		return nil, nil
+	}
+	buf := &bytes.Buffer{}
+	img := graph.Image{Width: defaultChartWidth, Height: defaultChartHeight, Format: "png"}
+	if err := s.NumService.Plotter.WriteImage(buf, title, "X", yLabel, lines, img); err != nil {
+		return nil, errors.Wrapf(err, "can't plot %s", strings.ToLower(title))
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getReport(t *testing.T, base string, q url.Values) (*http.Response, string) {
+	resp, err := http.Get(base + "/api/v1/report?" + strings.ReplaceAll(q.Encode(), "+", "%20"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(body)
+}
+
+func TestRest_Report(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	q := url.Values{"f": {"y"}, "exact": {"c*exp(x)"}, "c": {"y0/exp(x0)"}, "x0": {"0"}, "y0": {"1"}, "x1": {"1"},
+		"n": {"10"}, "n0": {"10"}, "n1": {"40"}, "methods": {"euler,rk4,exact"}}
+	resp, body := getReport(t, ts.URL, q)
+	require.Equal(t, http.StatusOK, resp.StatusCode, body)
+	assert.Equal(t, "text/markdown; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "attachment; filename=report-y.md", resp.Header.Get("Content-Disposition"))
+
+	assert.True(t, strings.HasPrefix(body, "# Solution of y' = y\n\n- **f(x,y)**: `y`\n- **y(x,c)**: `c*exp(x)`\n"), body)
+	for _, s := range []string{"## Solutions", "| x | euler | rk4 | exact |", "| 0 | 1 | 1 | 1 |",
+		"## Summary", "## Local errors", "| euler | 1 | 0.124539 |", "## Convergence",
+		"Max global errors against the reference: exact. Fitted orders: ", "| euler | 40 |",
+		"(data:image/png;base64,"} {
+		assert.Contains(t, body, s)
+	}
+	assert.Equal(t, 3, strings.Count(body, "(data:image/png;base64,"), "each section, except summary, has the chart")
+
+	q.Set("format", "tex")
+	resp, body = getReport(t, ts.URL, q)
+	require.Equal(t, http.StatusOK, resp.StatusCode, body)
+	assert.Equal(t, "application/x-tex; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "attachment; filename=report-y.tex", resp.Header.Get("Content-Disposition"))
+	assert.True(t, strings.HasPrefix(body, "\\documentclass{article}\n"), body)
+	assert.True(t, strings.HasSuffix(body, "\\end{document}\n"), body)
+	for _, s := range []string{"\\section*{Solutions}", "x & euler & rk4 & exact \\\\", "\\section*{Convergence}",
+		"\\begin{loglogaxis}", "\\addlegendentry{Euler's method}"} {
+		assert.Contains(t, body, s)
+	}
+	assert.NotContains(t, body, "base64")
+}
+
+func TestRest_ReportWithoutExact(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	q := url.Values{"f": {"x^2 - 2*y"}, "x0": {"0"}, "y0": {"1"}, "x1": {"1"}, "n": {"10"}, "methods": {"euler"}}
+	resp, body := getReport(t, ts.URL, q)
+	require.Equal(t, http.StatusOK, resp.StatusCode, body)
+	assert.Equal(t, "attachment; filename=report-x-2-2-y.md", resp.Header.Get("Content-Disposition"))
+	assert.NotContains(t, body, "## Local errors")
+	assert.Contains(t, body, "Max global errors against the reference: rk4.")
+}
+
+func TestRest_ReportInvalid(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	q := url.Values{"f": {"y"}, "x0": {"0"}, "y0": {"1"}, "x1": {"1"}, "n": {"10"}, "methods": {"euler"}}
+	q.Set("format", "pdf")
+	resp, body := getReport(t, ts.URL, q)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, body, "format must be md or tex")
+
+	q.Set("format", "md")
+	q.Set("n0", "40")
+	q.Set("n1", "10")
+	resp, body = getReport(t, ts.URL, q)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, body, "must not be less than n0=40")
+}
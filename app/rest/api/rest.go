@@ -2,65 +2,42 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"html/template"
 	"math"
+	"net"
 	"net/http"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/rakyll/statik/fs"
-
 	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/solve"
 
 	"github.com/Semior001/decompract/app/num/service"
-	// adding statik files, for serving form
-	_ "github.com/Semior001/decompract/app/statik"
 
 	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/expr"
 
 	"github.com/pkg/errors"
 
 	"github.com/go-chi/render"
 
 	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/store"
+	"github.com/Semior001/decompract/app/web"
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/httprate"
 	log "github.com/go-pkgz/lgr"
 	R "github.com/go-pkgz/rest"
 
-	"github.com/Knetic/govaluate"
+	"golang.org/x/sync/semaphore"
+	"google.golang.org/grpc"
 )
 
-const plotHTMLTmpl = `<!DOCTYPE html>
-<html>
-<head>
-    <meta name="viewport" content="width=device-width"/>
-    <meta http-equiv="Content-Type" content="text/html; charset=UTF-8"/>
-    <title>Solution</title>
-</head>
-<body>
-<div style="text-align: center; font-family: Arial, sans-serif; font-size: 18px;">
-    <h1 style="position: relative; color: #4fbbd6; margin-top: 0.2em;">DEComPract</h1>
-    <h3 style="position: relative; color: #666666; margin-top: 0.2em;">Yelshat Duskaliyev, B19-04</h3>
-    <p>f(x,y) = {{.Fxy}}; y(x,c) = {{.Yxc}}; C(x<sub>0</sub>,y<sub>0</sub>) = {{.Cx0y0}}</p>
-    <p>x<sub>0</sub> = {{printf "%.4f" .X0}}; y<sub>0</sub> = {{printf "%.4f" .Y0}}; X = {{printf "%.4f" .XEnd}}; N = {{.N}}; N<sub>min</sub> = {{.NMin}}; N<sub>max</sub> = {{.NMax}}</p>
-    <a href="/">Enter another data</a>
-</div>
-<table width="100%" style="align-content: center; font-family: Arial, sans-serif; font-size: 18px; position: relative; margin-top: 0.2em;">
-    <tr>
-        <td><img width="100%" src="data:image/jpg;base64,{{.SolutionsImg}}" alt="solutions plot"></td>
-        <td><img width="100%" src="data:image/jpg;base64,{{.LTEImg}}" alt="lte plot"></td>
-        <td><img width="100%" src="data:image/jpg;base64,{{.GTEImg}}" alt="gte plot"></td>
-    </tr>
-</table>
-</body>
-</html>`
-
 type plotTmplData struct {
 	X0           float64
 	Y0           float64
@@ -74,29 +51,166 @@ type plotTmplData struct {
 	Fxy          string
 	Yxc          string
 	Cx0y0        string
+	Method       string   // the name of the method, chosen in the form, empty for the default methods
+	Advice       []string // reasons of the method, chosen automatically
 }
 
+// indexTmplData is the data of the form, methods of the registry are listed in the method selector
+type indexTmplData struct {
+	Methods []methodInfo
+}
+
+// autoMethod is the option of the method selector in the form, that takes the method of the advice
+const autoMethod = "auto"
+
+const (
+	compressMinSize = 1024             // minimal size of the response in bytes to compress it
+	corsMaxAge      = 10 * time.Minute // duration to cache the results of the preflight request
+)
+
 // Rest defines a simple web server for routing to calendar REST api methods
 type Rest struct {
-	Version string
-	WebRoot string
+	Version  string
+	Revision string
+
+	Web *web.Assets // templates and static assets of the ui, the embedded ones are used if nil
 
 	NumService *service.Service
 
+	Limits       solve.Limits // limits of resources, consumed by solve requests
+	BatchWorkers int          // number of problems, solved concurrently in the batch request
+	SweepWorkers int          // number of solutions of the error sweep at once, GOMAXPROCS if not positive
+	JobWorkers   int          // number of jobs, solved at once, defaultJobWorkers if not positive
+
+	SolveTimeout time.Duration // maximal duration of computations of a single request
+	JobTimeout   time.Duration // maximal duration of the solve of the job, defaultJobTimeout if not positive
+
+	RateLimit  float64 // number of computational requests per second from a single client, unlimited if zero
+	RateBurst  int     // number of computational requests from a single client at once
+	TrustProxy bool    // take the client's address from the proxy headers
+
+	CORSOrigins []string // origins, allowed to call the API from browsers, "*" allows any origin
+
+	CacheSize int           // maximal number of cached solutions, cache is disabled if zero
+	CacheTTL  time.Duration // time to live of the cached solution, unlimited if zero
+
+	Store store.Interface // storage of saved results, saving is disabled if nil
+	Runs  store.Runs      // history of solves of sessions with summaries of solutions, disabled if nil
+
+	SessionSecret string // key to sign session cookies and permalinks, random if empty, so they don't survive restarts
+
+	DrainTimeout time.Duration // maximal duration to wait for requests in flight on shutdown
+
+	APIKeys []rest.APIKey // keys, required to call the computational API, the API is open if empty
+
+	Recorder *RunRecorder // records failed solves to replay them, disabled if nil
+
+	ChartThinAbove int // lines of charts with more points are thinned by curvature, thinning is disabled if zero
+
+	Logger log.L // logger of requests and solves, tagged with the id of the request, the default one if nil
+
+	Numbers rest.NumberFormat // format of numbers in json and csv responses, the shortest one if zero
+	// CanonicalOutput makes identical requests give byte-identical json and csv responses, numbers are formatted
+	// with canonicalDigits, if Numbers are not set, keys of objects are sorted and durations are zeroed
+	CanonicalOutput bool
+
+	TLS          *rest.TLSSetup // serves https with the setup, plain http if nil
+	RedirectPort int            // port of plain http listener, that redirects to https, disabled if zero
+	GRPCPort     int            // port of the grpc api of the solver, disabled if zero
+
+	cache      *rest.LRU
+	solving    *semaphore.Weighted // slots of lines, solved at once, nil if unlimited
+	drain      *drainer
+	limiter    *rest.RateLimiter // limiter of computational requests, nil if neither clients nor keys are limited
+	history    *history
+	jobs       *jobs
+	sets       *compSets
+	warm       *rest.LRU // session id -> warmStart, the last warm solve of the session
+	secret     []byte    // key to sign session cookies and permalinks, it is set by Run
+	metrics    *metrics
+	started    time.Time
 	httpServer *http.Server
+	grpcServer *grpc.Server
 	lock       sync.Mutex
 }
 
-// Run starts the web-server for listening
-func (s *Rest) Run(port int) {
+// Run starts the web-server for listening, as soon as the context is done, the server is shut down,
+// Run returns after the requests in flight are drained, the error is returned, if the server can't be started
+func (s *Rest) Run(ctx context.Context, port int) error {
+	var err error
+	if s.secret, err = s.sessionSecret(); err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return errors.Wrapf(err, "failed to listen port %d", port)
+	}
+	var gln net.Listener
+	if s.GRPCPort > 0 {
+		if gln, err = net.Listen("tcp", fmt.Sprintf(":%d", s.GRPCPort)); err != nil {
+			_ = ln.Close()
+			return errors.Wrapf(err, "failed to listen grpc port %d", s.GRPCPort)
+		}
+	}
+	if s.TLS == nil {
+		log.Printf("[INFO] started web server at port %d", port)
+		s.serve(ctx, ln, gln)
+		return nil
+	}
+
+	var rln net.Listener
+	if s.RedirectPort > 0 {
+		if rln, err = net.Listen("tcp", fmt.Sprintf(":%d", s.RedirectPort)); err != nil {
+			_ = ln.Close()
+			if gln != nil {
+				_ = gln.Close()
+			}
+			return errors.Wrapf(err, "failed to listen redirect port %d", s.RedirectPort)
+		}
+	}
+	log.Printf("[INFO] started https web server at port %d", port)
+	return s.serveTLS(ctx, ln, rln, gln)
+}
+
+// serve serves http requests on the listener and grpc calls on the grpc listener, if it is set
+func (s *Rest) serve(ctx context.Context, ln, gln net.Listener) {
 	s.lock.Lock()
-	s.httpServer = s.makeHTTPServer(port, s.routes())
+	s.httpServer = s.makeHTTPServer(ln.Addr().(*net.TCPAddr).Port, s.routes())
 	s.httpServer.ErrorLog = log.ToStdLogger(log.Default(), "WARN")
+	if gln != nil {
+		s.grpcServer = s.makeGRPCServer()
+	}
 	s.lock.Unlock()
 
-	log.Printf("[INFO] started web server at port %d", port)
-	err := s.httpServer.ListenAndServe()
+	grpcStopped := make(chan struct{})
+	go func() {
+		defer close(grpcStopped)
+		if gln != nil {
+			s.serveGRPC(s.grpcServer, gln)
+		}
+	}()
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-ctx.Done():
+			s.shutdown()
+		case <-s.drain.closing:
+		}
+	}()
+
+	err := s.httpServer.Serve(ln)
 	log.Printf("[WARN] web server terminated reason: %s", err)
+	if !errors.Is(err, http.ErrServerClosed) {
+		s.drain.close()
+	}
+	<-stopped
+	if s.grpcServer != nil {
+		s.grpcServer.Stop()
+	}
+	<-grpcStopped
 }
 
 func (s *Rest) makeHTTPServer(port int, routes chi.Router) *http.Server {
@@ -116,52 +230,200 @@ func (s *Rest) notFound(w http.ResponseWriter, r *http.Request) {
 func (s *Rest) routes() chi.Router {
 	r := chi.NewRouter()
 
+	s.metrics = newMetrics()
+	s.drain = newDrainer()
+	r.Use(s.metrics.middleware)
+	r.Use(s.drain.middleware)
+	r.Use((&rest.Logger{L: s.Logger, TrustProxy: s.TrustProxy}).Handler)
 	r.Use(R.AppInfo("decompract", "Semior001", s.Version))
 	r.Use(R.Recoverer(log.Default()))
-	r.Use(R.Ping, middleware.RealIP)
-	r.Use(httprate.LimitByIP(100, 1*time.Minute))
+	r.Use(R.Ping)
+	if s.TrustProxy {
+		r.Use(middleware.RealIP)
+	}
+	if len(s.CORSOrigins) > 0 {
+		cors := &rest.CORS{AllowedOrigins: s.CORSOrigins, MaxAge: corsMaxAge}
+		r.Use(withPrefix("/api/", cors.Handler))
+	}
+	// static assets and the ui page are not limited, the client is the same as for the rate limit of solves
+	r.Use(withPrefix("/api/", httprate.Limit(100, 1*time.Minute, func(r *http.Request) (string, error) {
+		return rest.ClientIP(r, s.TrustProxy), nil
+	})))
 
 	r.NotFound(s.notFound)
 
+	s.started = time.Now()
+	r.Get("/api/v1/info", s.infoCtrl)
+	r.Get("/api/presets", s.presetsCtrl)
+	r.Get("/api/v1/openapi.json", s.openAPICtrl)
+	r.Handle("/metrics", s.metrics.handler())
+	r.Get("/api/v1/docs", s.docsCtrl)
+
+	// the cache is rebuilt along with routes, so the solutions, obtained under other limits, are dropped
+	s.cache = nil
+	if s.CacheSize > 0 {
+		s.cache = &rest.LRU{MaxEntries: s.CacheSize, TTL: s.CacheTTL}
+	}
+
+	s.solving = newSemaphore(s.limits().MaxConcurrent)
+	s.history = newHistory(s.Runs)
+	s.jobs = newJobs()
+	s.sets = newCompSets()
+	s.warm = &rest.LRU{MaxEntries: maxSessions, TTL: sessionMaxAge}
+	session := &rest.Session{Secret: s.secret, MaxAge: sessionMaxAge}
+	s.limiter = nil
+	if s.RateLimit > 0 || limitsKeys(s.APIKeys) {
+		s.limiter = &rest.RateLimiter{Rate: s.RateLimit, Burst: s.RateBurst, TrustProxy: s.TrustProxy}
+	}
+	// requests in flight are limited for the client across all computational routes, but streams
+	inFlight := &rest.InFlight{Max: s.limits().MaxPerClient, TrustProxy: s.TrustProxy}
+	limit := func(r chi.Router) {
+		if s.limiter != nil {
+			r.Use(s.limiter.Handler)
+		}
+	}
+	// responses are rewritten before they are compressed
+	canonical := func(r chi.Router) {
+		if c := s.canonical(); c != nil {
+			r.Use(c.Handler)
+		}
+	}
+
+	// computational routes
+	r.Group(func(r chi.Router) {
+		r.Use((&rest.Compressor{MinSize: compressMinSize}).Handler)
+		canonical(r)
+		r.Use(session.Handler)
+
+		// api routes require the key, if keys are set, the key is checked before the rate limit to apply its own rate
+		r.Group(func(r chi.Router) {
+			r.Use((&rest.APIKeys{Keys: s.APIKeys}).Handler)
+			limit(r)
+
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.Timeout(s.solveTimeout()))
+				r.Use(inFlight.Handler)
+				r.Get("/api/chart/field", s.fieldCtrl)
+				r.Post("/api/v1/solve", s.solveCtrl)
+				r.Get("/api/v1/solve", s.getSolveCtrl)
+				r.Get("/api/v1/solve.csv", s.solveCSVCtrl)
+				r.Get("/api/v1/chart", s.chartCtrl)
+				r.Get("/api/plot.png", s.plotPNGCtrl)
+				r.Get("/api/v1/chart/phase", s.phaseCtrl)
+				r.Get("/api/v1/stability", s.stabilityCtrl)
+				r.Get("/api/v1/errors", s.errorsCtrl)
+				r.Get("/api/v1/report", s.reportCtrl)
+				r.Get("/api/export.xlsx", s.exportXLSXCtrl)
+				r.Get("/api/value", s.valueCtrl)
+				r.Get("/api/v1/compare", s.compareCtrl)
+				r.Get("/api/v1/compare/all", s.compareAllCtrl)
+				r.Get("/api/v1/integrate", s.integrateCtrl)
+				r.Get("/api/v1/derivative", s.derivativeCtrl)
+				r.Get("/api/v1/advise", s.adviseCtrl)
+				r.Post("/api/v1/sweep", s.paramSweepCtrl)
+				r.Post("/api/v1/sweep/grid", s.gridSweepCtrl)
+				r.Post("/api/v1/solve/batch", s.batchSolveCtrl)
+				r.Post("/api/v1/solve/higher", s.higherCtrl)
+				r.Post("/api/v1/runs/{id}/rerun", s.rerunCtrl)
+				r.Post("/api/v1/share", s.shareCtrl)
+				r.Post("/api/jobs", s.submitJobCtrl)
+				r.Get("/api/jobs/{id}", s.jobCtrl)
+				r.Post("/api/v1/sets/{name}", s.addToSetCtrl)
+				r.Get("/api/v1/sets/{name}", s.setCtrl)
+				r.Delete("/api/v1/sets/{name}", s.deleteSetCtrl)
+			})
+
+			// streaming is not limited by the timeout, each solve over websocket is limited separately
+			r.Get("/api/v1/solve/stream", s.streamSolveCtrl)
+			r.Get("/api/v1/ws", s.wsCtrl)
+			r.Get("/ws/solve", s.wsCtrl)
+
+			// the form of the ui solves as the api does, it is limited by the solve timeout in the handler
+			r.Group(func(r chi.Router) {
+				r.Use(inFlight.Handler)
+				r.Post("/", s.plotGraphsCtrl)
+			})
+		})
+	})
+
+	// saved results and shared problems
 	r.Group(func(r chi.Router) {
-		r.Use(middleware.Timeout(5 * time.Second))
+		r.Use((&rest.Compressor{MinSize: compressMinSize}).Handler)
+		canonical(r)
+		r.Get("/api/v1/result/{id}", s.resultCtrl)
+		r.Get("/result/{id}", s.resultPageCtrl)
+		r.Get("/s/{token}", s.sharedPageCtrl)
 	})
 
-	addFileServer(r, "/", http.Dir(s.WebRoot))
-	r.Post("/", s.plotGraphsCtrl)
+	r.Group(func(r chi.Router) {
+		r.Use(session.Handler)
+		r.Get("/api/v1/history", s.historyCtrl)
+		r.Delete("/api/v1/history", s.clearHistoryCtrl)
+		r.Get("/api/v1/runs", s.runsCtrl)
+		r.Get("/api/v1/runs/{id}", s.runCtrl)
+	})
+
+	if s.Web == nil {
+		s.Web = web.Embedded()
+	}
+	r.Get("/", s.indexCtrl)
+	r.Handle(web.StaticPrefix+"*", s.Web.Handler())
 
 	return r
 }
 
-func addFileServer(r chi.Router, path string, root http.FileSystem) {
-	var webFS http.Handler
+// limitsKeys checks whether any of the keys has its own rate limit
+func limitsKeys(keys []rest.APIKey) bool {
+	for _, k := range keys {
+		if k.Rate > 0 {
+			return true
+		}
+	}
+	return false
+}
 
-	statikFS, err := fs.New()
-	if err != nil {
-		log.Printf("[DEBUG] no embedded assets loaded, %s", err)
-		log.Printf("[INFO] run file server for %s, path %s", root, path)
-		webFS = http.FileServer(root)
-	} else {
-		log.Printf("[INFO] run file server for %s, embedded", root)
-		webFS = http.FileServer(statikFS)
-	}
-
-	origPath := path
-	webFS = http.StripPrefix(path, webFS)
-	if path != "/" && path[len(path)-1] != '/' {
-		r.Get(path, http.RedirectHandler(path+"/", 301).ServeHTTP)
-		path += "/"
-	}
-	path += "*"
-
-	r.Get(path, func(w http.ResponseWriter, r *http.Request) {
-		// don't show dirs, just serve files
-		if strings.HasSuffix(r.URL.Path, "/") && len(r.URL.Path) > 1 && r.URL.Path != (origPath+"/") {
-			http.NotFound(w, r)
-			return
+// canonicalDigits are significant digits of numbers in the canonical output, if the format is not set,
+// so the differences of the last bits of results on other platforms are rounded off
+const canonicalDigits = 12
+
+// canonical returns the middleware, that rewrites responses in the format of numbers and in the canonical form,
+// if it is requested, nil if responses are written as is
+func (s *Rest) canonical() *rest.Canonical {
+	if !s.CanonicalOutput {
+		if s.Numbers == (rest.NumberFormat{}) {
+			return nil
 		}
-		webFS.ServeHTTP(w, r)
-	})
+		return &rest.Canonical{Numbers: s.Numbers}
+	}
+	nf := s.Numbers
+	if nf.Digits == 0 {
+		nf.Digits = canonicalDigits
+	}
+	return &rest.Canonical{Numbers: nf, SortKeys: true, Volatile: []string{"took"}}
+}
+
+// withPrefix applies the middleware only to requests with the path, that starts with the prefix
+func withPrefix(prefix string, mw func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		wrapped := mw(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				wrapped.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// GET / - the form of the ui
+func (s *Rest) indexCtrl(w http.ResponseWriter, r *http.Request) {
+	buf := &bytes.Buffer{}
+	if err := s.Web.Render(buf, "index.html", indexTmplData{Methods: methodInfos()}); err != nil {
+		rest.SendErrorHTML(w, r, http.StatusInternalServerError, err, "can't execute template")
+		return
+	}
+	render.HTML(w, r, buf.String())
 }
 
 // POST / - plot graphs according to the given parameters
@@ -172,8 +434,14 @@ func (s *Rest) plotGraphsCtrl(w http.ResponseWriter, r *http.Request) {
 		rest.SendErrorHTML(w, r, http.StatusForbidden, err, "failed to read request values")
 		return
 	}
+	if err = req.validate(s.limits()); err != nil {
+		rest.SendErrorHTML(w, r, http.StatusBadRequest, err, "invalid request")
+		return
+	}
 
-	// if functions are specified, prepare them
+	// if functions are specified, prepare them, the service of the request replaces the default one,
+	// so the choice of the form doesn't leak to other requests
+	numSvc, tmplData := s.NumService, plotTmplData{}
 	if req.fxy != "" && req.yxc != "" && req.c != "" {
 		funcs, err := prepareFuncs(req.fxy, req.yxc, req.c)
 		if err != nil {
@@ -181,43 +449,83 @@ func (s *Rest) plotGraphsCtrl(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		solvers := []solver.Interface{
+			&solver.RungeKutta{F: funcs.fxy},
+			&solver.ImprovedEuler{F: funcs.fxy},
+			&solver.Euler{F: funcs.fxy},
+		}
+		if req.method != "" {
+			slvr, adv, err := formSolver(req, funcs.fxy)
+			if err != nil {
+				rest.SendErrorHTML(w, r, http.StatusBadRequest, err, "failed to choose the method")
+				return
+			}
+			solvers, tmplData.Method = []solver.Interface{slvr}, slvr.Name()
+			if adv != nil {
+				tmplData.Advice = adv.Reasons
+			}
+		}
+
 		// initializing services
-		s.NumService = &service.Service{
-			Plotter: s.NumService.Plotter,
-			Solvers: []solver.Interface{
-				&solver.RungeKutta{F: funcs.fxy},
-				&solver.ImprovedEuler{F: funcs.fxy},
-				&solver.Euler{F: funcs.fxy},
-			},
+		numSvc = &service.Service{
+			Plotter:     s.NumService.Plotter,
+			Solvers:     solvers,
 			ExactSolver: &solver.Exact{F: funcs.yxc, C: funcs.cx0y0},
 		}
+	} else if req.method != "" {
+		rest.SendErrorHTML(w, r, http.StatusBadRequest, errors.New("the method is chosen for the given functions only"),
+			"failed to choose the method")
+		return
+	}
+
+	// all computations, including the sweep of global errors, are limited by the solve timeout
+	ctx, cancel := context.WithTimeout(r.Context(), s.solveTimeout())
+	defer cancel()
+	svc := &service.Service{
+		Plotter:     numSvc.Plotter,
+		Solvers:     withContext(ctx, numSvc.Solvers...),
+		ExactSolver: ctxSolver{Interface: numSvc.ExactSolver, ctx: ctx},
+	}
+
+	sendPlotErr := func(err error, details string) {
+		if errors.Is(err, context.DeadlineExceeded) {
+			rest.SendErrorHTML(w, r, http.StatusGatewayTimeout,
+				errors.Errorf("solve timed out after %s", s.solveTimeout()), details)
+			return
+		}
+		rest.SendErrorHTML(w, r, http.StatusInternalServerError, err, details)
+	}
+
+	step, err := num.CalculateStepSize(req.N, req.X0, req.XEnd)
+	if err != nil {
+		rest.SendErrorHTML(w, r, http.StatusBadRequest, err, "invalid interval or number of steps")
+		return
 	}
 
 	// encoding solutions plot
-	bSols, err := s.NumService.PlotSolutions(num.CalculateStepSize(req.N, req.X0, req.XEnd), req.X0, req.Y0, req.XEnd)
+	bSols, err := svc.PlotSolutions(step, req.X0, req.Y0, req.XEnd)
 	if err != nil {
-		rest.SendErrorHTML(w, r, http.StatusInternalServerError, err, "failed to plot solutions")
+		sendPlotErr(err, "failed to plot solutions")
 		return
 	}
 
 	// encoding lte plot
-	bLTEs, err := s.NumService.PlotLocalErrors(num.CalculateStepSize(req.N, req.X0, req.XEnd), req.X0, req.Y0, req.XEnd)
+	bLTEs, err := svc.PlotLocalErrors(step, req.X0, req.Y0, req.XEnd)
 	if err != nil {
-		rest.SendErrorHTML(w, r, http.StatusInternalServerError, err, "failed to plot lte")
+		sendPlotErr(err, "failed to plot lte")
 		return
 	}
 
 	// encoding gte plot
-	bGTEs, err := s.NumService.PlotGlobalErrors(req.NMin, req.NMax, req.X0, req.Y0, req.XEnd)
+	bGTEs, err := svc.PlotGlobalErrors(req.NMin, req.NMax, req.X0, req.Y0, req.XEnd)
 	if err != nil {
-		rest.SendErrorHTML(w, r, http.StatusInternalServerError, err, "failed to plot gte")
+		sendPlotErr(err, "failed to plot gte")
 		return
 	}
 
 	// building html template
 	buf := &bytes.Buffer{}
-	tmpl := template.Must(template.New("plot").Parse(plotHTMLTmpl))
-	err = tmpl.Execute(buf, plotTmplData{
+	err = s.Web.Render(buf, "plot.html", plotTmplData{
 		X0:           req.X0,
 		Y0:           req.Y0,
 		XEnd:         req.XEnd,
@@ -230,6 +538,8 @@ func (s *Rest) plotGraphsCtrl(w http.ResponseWriter, r *http.Request) {
 		Fxy:          req.fxy,
 		Yxc:          req.yxc,
 		Cx0y0:        req.c,
+		Method:       tmplData.Method,
+		Advice:       tmplData.Advice,
 	})
 	if err != nil {
 		rest.SendErrorHTML(w, r, http.StatusInternalServerError, err, "can't execute template")
@@ -241,15 +551,16 @@ func (s *Rest) plotGraphsCtrl(w http.ResponseWriter, r *http.Request) {
 }
 
 type solveRequest struct {
-	X0   float64
-	Y0   float64
-	XEnd float64
-	N    int
-	NMin int
-	NMax int
-	fxy  string
-	yxc  string
-	c    string
+	X0     float64
+	Y0     float64
+	XEnd   float64
+	N      int
+	NMin   int
+	NMax   int
+	fxy    string
+	yxc    string
+	c      string
+	method string // the method of the selector, empty for the default methods
 }
 
 func readVals(r *http.Request) (req solveRequest, err error) {
@@ -285,18 +596,67 @@ func readVals(r *http.Request) (req solveRequest, err error) {
 	}
 
 	return solveRequest{
-		X0:   x0,
-		Y0:   y0,
-		XEnd: xEnd,
-		N:    n,
-		NMax: nmax,
-		NMin: nmin,
-		fxy:  r.Form["fxy"][0],
-		yxc:  r.Form["yxc"][0],
-		c:    r.Form["c"][0],
+		X0:     x0,
+		Y0:     y0,
+		XEnd:   xEnd,
+		N:      n,
+		NMax:   nmax,
+		NMin:   nmin,
+		fxy:    r.Form["fxy"][0],
+		yxc:    r.Form["yxc"][0],
+		c:      r.Form["c"][0],
+		method: r.Form.Get("method"),
 	}, nil
 }
 
+// validate checks numbers of steps and the interval of the form under limits, as the solve and the errors
+// requests do, in case of invalid request, rest.ValidationError with all invalid fields is returned
+func (req solveRequest) validate(l solve.Limits) error {
+	var errs rest.ValidationError
+	invalid := func(field, msg string, args ...interface{}) {
+		errs = append(errs, rest.FieldError{Field: field, Msg: fmt.Sprintf(msg, args...)})
+	}
+
+	if width := math.Abs(req.XEnd - req.X0); l.MaxWidth > 0 && width > l.MaxWidth {
+		invalid("x_end", "gives the interval of width %v, more than max_width=%v", width, l.MaxWidth)
+	}
+	if req.N < 1 || req.N > l.MaxSteps {
+		invalid("n", "must be between 1 and max_steps=%d, got %d", l.MaxSteps, req.N)
+	}
+	switch {
+	case req.NMin < 1:
+		invalid("nmin", "must be positive, got %d", req.NMin)
+	case req.NMax < req.NMin:
+		invalid("nmax", "must not be less than nmin=%d, got %d", req.NMin, req.NMax)
+	case req.NMax > l.MaxSteps:
+		invalid("nmax", "must not be more than max_steps=%d, got %d", l.MaxSteps, req.NMax)
+	case req.NMax-req.NMin+1 > l.MaxSweep:
+		invalid("nmax", "gives %d values of n, more than max_sweep=%d", req.NMax-req.NMin+1, l.MaxSweep)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// formSolver returns the solver of the method, chosen in the form, the automatic choice takes the method
+// of the advice for the default target error and returns the advice along with it
+func formSolver(req solveRequest, f solver.Func) (solver.Interface, *Advice, error) {
+	if req.method == autoMethod {
+		adv, err := Advise(f, req.X0, req.Y0, req.XEnd, defaultTarget)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to advise the method")
+		}
+		return solve.Builder(adv.Method)(f), adv, nil
+	}
+	b, ok := solver.Lookup(req.method)
+	if !ok {
+		return nil, nil, errors.Errorf("unknown method %q", req.method)
+	}
+	return b(f), nil, nil
+}
+
 type parsedFuncs struct {
 	fxy   func(x, y float64) (float64, error)
 	yxc   func(x, c float64) (float64, error)
@@ -305,117 +665,20 @@ type parsedFuncs struct {
 
 // prepareFuncs parses the string expressions and prepares the functions for the future evaluation
 func prepareFuncs(fxyStr, yxcStr, cStr string) (parsedFuncs, error) {
-	funcs := map[string]govaluate.ExpressionFunction{
-		"exp": govaluate.ExpressionFunction(func(args ...interface{}) (interface{}, error) {
-			if len(args) != 1 {
-				return nil, errors.New("exponent takes only 1 argument")
-			}
-			p, ok := args[0].(float64)
-			if !ok {
-				return nil, errors.New("argument is not of type float64")
-			}
-			return math.Exp(p), nil
-		}),
-		"ln": govaluate.ExpressionFunction(func(args ...interface{}) (interface{}, error) {
-			if len(args) != 1 {
-				return nil, errors.New("ln takes only 1 argument")
-			}
-			p, ok := args[0].(float64)
-			if !ok {
-				return nil, errors.New("argument is not of type float64")
-			}
-			return math.Log(p), nil
-		}),
-		"tan": govaluate.ExpressionFunction(func(args ...interface{}) (interface{}, error) {
-			if len(args) != 1 {
-				return nil, errors.New("tan takes only 1 argument")
-			}
-			p, ok := args[0].(float64)
-			if !ok {
-				return nil, errors.New("argument is not of type float64")
-			}
-			return math.Tan(p), nil
-		}),
-		"cos": govaluate.ExpressionFunction(func(args ...interface{}) (interface{}, error) {
-			if len(args) != 1 {
-				return nil, errors.New("cos takes only 1 argument")
-			}
-			p, ok := args[0].(float64)
-			if !ok {
-				return nil, errors.New("argument is not of type float64")
-			}
-			return math.Cos(p), nil
-		}),
-		"pi": govaluate.ExpressionFunction(func(args ...interface{}) (interface{}, error) {
-			if len(args) > 0 {
-				return nil, errors.New("pi does not require any arguments")
-			}
-			return math.Pi, nil
-		}),
-		"sin": govaluate.ExpressionFunction(func(args ...interface{}) (interface{}, error) {
-			if len(args) != 1 {
-				return nil, errors.New("sin takes only 1 argument")
-			}
-			p, ok := args[0].(float64)
-			if !ok {
-				return nil, errors.New("argument is not of type float64")
-			}
-			return math.Sin(p), nil
-		}),
-	}
-
-	fxyExpr, err := govaluate.NewEvaluableExpressionWithFunctions(fxyStr, funcs)
+	fxy, err := expr.Parse2(fxyStr, nil, "x", "y")
 	if err != nil {
 		return parsedFuncs{}, errors.Wrap(err, "can't parse f(x,y)")
 	}
-	fxy := func(x, y float64) (float64, error) {
-		params := map[string]interface{}{"x": x, "y": y}
-		resExpr, err := fxyExpr.Evaluate(params)
-		if err != nil {
-			return 0, errors.Wrap(err, "failed to evaluate expression")
-		}
-		res, ok := resExpr.(float64)
-		if !ok {
-			return 0, errors.Wrap(err, "result is not float64")
-		}
-		return res, nil
-	}
 
-	yxcExpr, err := govaluate.NewEvaluableExpressionWithFunctions(yxcStr, funcs)
+	yxc, err := expr.Parse2(yxcStr, nil, "x", "c")
 	if err != nil {
 		return parsedFuncs{}, errors.Wrap(err, "can't parse y(x,c)")
 	}
 
-	cExpr, err := govaluate.NewEvaluableExpressionWithFunctions(cStr, funcs)
+	cx0y0, err := expr.Parse2(cStr, nil, "x0", "y0")
 	if err != nil {
 		return parsedFuncs{}, errors.Wrap(err, "can't parse c(x0,y0)")
 	}
 
-	yxc := func(x, c float64) (float64, error) {
-		params := map[string]interface{}{"x": x, "c": c}
-		resExpr, err := yxcExpr.Evaluate(params)
-		if err != nil {
-			return 0, errors.Wrap(err, "failed to evaluate expression")
-		}
-		res, ok := resExpr.(float64)
-		if !ok {
-			return 0, errors.Wrap(err, "result is not float64")
-		}
-		return res, nil
-	}
-
-	cx0y0 := func(x0, y0 float64) (float64, error) {
-		params := map[string]interface{}{"x0": x0, "y0": y0}
-		resExpr, err := cExpr.Evaluate(params)
-		if err != nil {
-			return 0, errors.Wrap(err, "failed to evaluate expression")
-		}
-		res, ok := resExpr.(float64)
-		if !ok {
-			return 0, errors.Wrap(err, "result is not float64")
-		}
-		return res, nil
-	}
-
 	return parsedFuncs{fxy: fxy, yxc: yxc, cx0y0: cx0y0}, nil
 }
@@ -0,0 +1,1605 @@
+package api
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/field"
+	"github.com/Semior001/decompract/app/num/service"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/Semior001/decompract/app/store"
+	log "github.com/go-pkgz/lgr"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func prepTestServer(t *testing.T) (srv *Rest, ts *httptest.Server) {
+	srv = &Rest{Version: "test", NumService: &service.Service{}, Store: &store.Memory{}}
+	ts = httptest.NewServer(srv.routes())
+	t.Cleanup(ts.Close)
+	return srv, ts
+}
+
+func TestRest_Field(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/api/chart/field?f=" + url.QueryEscape("x+y") + "&nx=3&ny=2")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var segs []field.Segment
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&segs))
+	assert.Len(t, segs, 6)
+
+	resp, err = http.Get(ts.URL + "/api/chart/field?f=x%2By&format=png")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+	assert.NotEmpty(t, body)
+
+	for _, q := range []string{"f=x*", "f=x&nx=abc", "f=x&nx=1000", "f=x&xmin=2&xmax=1", "f=x&format=bmp"} {
+		resp, err = http.Get(ts.URL + "/api/chart/field?" + q)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, q)
+	}
+}
+
+func TestRest_SolveBackward(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	// y = exp(x) from y(1) = e back to y(0) = 1
+	resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(`{"f": "y",
+		"exact": "c*exp(x)", "c": "y0*exp(-x0)", "x0": 1, "y0": 2.718281828459045, "x_end": 0, "n": 10,
+		"methods": ["rk4", "euler"], "errors": true}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	res := solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.InDelta(t, 0.1, res.Step, 1e-12, "the step is the positive size of the step")
+	require.Len(t, res.Lines, 2)
+	for _, line := range res.Lines {
+		require.Nil(t, line.Error, line.Method)
+		require.Len(t, line.Points, 11, line.Method)
+		assert.Equal(t, 1.0, line.Points[0].X, line.Method)
+		assert.Equal(t, 0.0, line.Points[10].X, line.Method)
+		for i := 1; i < len(line.Points); i++ {
+			assert.Less(t, line.Points[i].X, line.Points[i-1].X, "%s goes backward", line.Method)
+		}
+	}
+	assert.InDelta(t, 1, res.Lines[0].Points[10].Y, 1e-6)
+	require.NotNil(t, res.Exact)
+	assert.InDelta(t, 1, res.Exact.Points[10].Y, 1e-12)
+	require.Len(t, res.Errors, 2)
+
+	// grids are refined backward as well
+	resp, err = http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(`{"f": "y", "x0": 1,
+		"y0": 2.718281828459045, "x_end": 0, "n": 10, "methods": ["euler"], "auto_refine": {"tol": 1e-2}}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res = solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Lines, 1)
+	require.NotNil(t, res.Lines[0].Refine)
+	assert.Greater(t, res.Lines[0].Refine.N, 10)
+	pts := res.Lines[0].Points
+	assert.Equal(t, 0.0, pts[len(pts)-1].X)
+	assert.InDelta(t, 1, pts[len(pts)-1].Y, 1e-2)
+}
+
+func TestRest_Solve(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(`{
+		"f": "y*y*exp(x) - 2*y", "exact": "exp(-x) / (c*exp(x) + 1)", "c": "(exp(-x0) - y0) / (y0 * exp(x0))",
+		"x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4", "euler"]
+	}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	res := solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.InDelta(t, 0.1, res.Step, 1e-12)
+	require.Len(t, res.Lines, 2)
+	assert.Equal(t, "rk4", res.Lines[0].Method)
+	assert.Equal(t, "Runge-Kutta's method", res.Lines[0].Name)
+	assert.Equal(t, "euler", res.Lines[1].Method)
+	assert.Len(t, res.Lines[0].Points, 11)
+	require.NotNil(t, res.Exact)
+	assert.Len(t, res.Exact.Points, 11)
+	assert.InDelta(t, res.Exact.Points[10].Y, res.Lines[0].Points[10].Y, 1e-5)
+	assert.NotEmpty(t, res.Took)
+
+	// without exact solution
+	resp, err = http.Post(ts.URL+"/api/v1/solve", "application/json",
+		strings.NewReader(`{"f": "x**2 - 2*y", "x0": 0, "y0": 1, "x_end": 1, "step": 0.1, "methods": ["ieuler"]}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res = solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.Nil(t, res.Exact)
+	require.Len(t, res.Lines, 1)
+	assert.InDelta(t, 0.820250, res.Lines[0].Points[1].Y, 1e-6)
+}
+
+func TestRest_SolveYAML(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	post := func(body string) *http.Response {
+		resp, err := http.Post(ts.URL+"/api/v1/solve", "application/yaml; charset=utf-8", strings.NewReader(body))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = resp.Body.Close() })
+		return resp
+	}
+
+	resp := post(`
+f: k*y
+exact: c*exp(k*x)
+c: y0/exp(k*x0)
+params:
+  k: -2
+x0: 0
+y0: 1
+x_end: 1
+n: 10
+methods: [rk4, euler]
+output:
+  format: table
+  out: solution.txt
+`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Lines, 2)
+	require.NotNil(t, res.Exact)
+	assert.InDelta(t, math.Exp(-2), res.Exact.Points[10].Y, 1e-12)
+	assert.InDelta(t, math.Exp(-2), res.Lines[0].Points[10].Y, 1e-4)
+
+	// minimal document
+	resp = post("f: x\nx_end: 1\nn: 2\nmethods: [ieuler]\n")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res = solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Lines, 1)
+	assert.Equal(t, []num.Point{{X: 0, Y: 0}, {X: 0.5, Y: 0.125}, {X: 1, Y: 0.5}}, res.Lines[0].Points)
+
+	tbl := []struct {
+		name string
+		body string
+		err  string
+	}{
+		{"unknown field", "f: x\nx_end: 1\nn: 2\nmethods: [rk4]\nx1: 2\n", "line 5: field x1 not found"},
+		{"unknown output field", "f: x\nx_end: 1\nn: 2\nmethods: [rk4]\noutput:\n  file: a.csv\n", "field file not found"},
+		{"bad type", "f: x\nx_end: one\nn: 2\nmethods: [rk4]\n", "cannot unmarshal !!str `one`"},
+		{"empty", "", "document is empty"},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := post(tt.body)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			er := rest.ErrorResponse{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&er))
+			assert.Equal(t, rest.ErrDecode, er.Code)
+			assert.Contains(t, er.Error, tt.err)
+		})
+	}
+
+	// json bodies are decoded as strictly as yaml ones
+	postJSON := func(body string) (int, rest.ErrorResponse) {
+		resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		er := rest.ErrorResponse{}
+		if resp.StatusCode != http.StatusOK {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&er))
+		}
+		return resp.StatusCode, er
+	}
+	status, _ := postJSON(`{"f": "x", "x_end": 1, "n": 2, "methods": ["rk4"], "output": {"format": "csv"}}`)
+	assert.Equal(t, http.StatusOK, status)
+	status, er := postJSON(`{"f": "x", "x_end": 1, "n": 2, "methods": ["rk4"], "x1": 2}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, rest.ErrDecode, er.Code)
+	assert.Contains(t, er.Error, `unknown field "x1"`)
+	status, er = postJSON("")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "request is empty", er.Error)
+
+	// the body is limited in both formats
+	large := `{"f": "x` + strings.Repeat(" ", maxSolveBody) + `", "x_end": 1, "n": 2, "methods": ["rk4"]}`
+	status, er = postJSON(large)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, status)
+	assert.Equal(t, rest.ErrDecode, er.Code)
+	resp = post("f: x" + strings.Repeat(" ", maxSolveBody) + "\nx_end: 1\nn: 2\nmethods: [rk4]\n")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+}
+
+func TestRest_SolveParams(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/api/v1/solve?f=k*x&x0=0&y0=0&x1=1&n=2&method=ieuler&param=k:2")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.Equal(t, []num.Point{{X: 0, Y: 0}, {X: 0.5, Y: 0.25}, {X: 1, Y: 1}}, res.Lines[0].Points)
+
+	for _, q := range []string{"param=k", "param=k:two"} {
+		resp, err = http.Get(ts.URL + "/api/v1/solve?f=k*x&x0=0&y0=0&x1=1&n=2&method=ieuler&" + q)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, q)
+	}
+
+	req := solve.Request{F: "k*x", XEnd: 1, N: 2, Methods: []string{"rk4"}, Params: map[string]float64{"k": 2, "b": 0.5}}
+	assert.Equal(t, "f=k%2Ax&method=rk4&n=2&param=b%3A0.5&param=k%3A2&x0=0&x1=1&y0=0", req.Query())
+	l := solve.Limits{MaxSteps: 10}
+	other := req
+	other.Params = map[string]float64{"k": 3, "b": 0.5}
+	assert.NotEqual(t, req.CacheKey(l), other.CacheKey(l))
+}
+
+func TestRest_SolveErrors(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	tbl := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"broken json", `{"f": `, 1},
+		{"bad formula", `{"f": "x +* y", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"]}`, 2},
+		{"unknown method", `{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk5"]}`, 2},
+		{"no methods", `{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10}`, 2},
+		{"no step", `{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "methods": ["rk4"]}`, 2},
+		{"exact without formulas", `{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["exact"]}`, 2},
+	}
+
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+			e := struct {
+				Code    int    `json:"code"`
+				Error   string `json:"error"`
+				Details string `json:"details"`
+			}{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&e))
+			assert.Equal(t, tt.code, e.Code)
+			assert.NotEmpty(t, e.Error)
+		})
+	}
+}
+
+func TestRest_SolveConcurrently(t *testing.T) {
+	for _, m := range []string{"slow1", "slow2", "slow3"} {
+		solver.Register(m, func(solver.Func) solver.Interface { return &solver.Euler{F: sleepyFunc(20 * time.Millisecond)} })
+		defer solver.Unregister(m)
+	}
+	_, ts := prepTestServer(t)
+
+	// each method takes about 200ms, so sequential solving takes about 600ms
+	st := time.Now()
+	resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(
+		`{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["slow3", "slow1", "slow2"]}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	took := time.Since(st)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, took < 450*time.Millisecond, "methods must be solved concurrently, took %s", took)
+
+	res := solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Lines, 3)
+	for i, m := range []string{"slow3", "slow1", "slow2"} {
+		assert.Equal(t, m, res.Lines[i].Method, "lines must follow the order of the request")
+		assert.NotEmpty(t, res.Lines[i].Points)
+	}
+}
+
+// breakMethod registers the method "broken" for the test, it is Euler's method, that fails at x > 0.5,
+// as formulas fail only to be parsed
+func breakMethod(t *testing.T) {
+	solver.Register("broken", func(f solver.Func) solver.Interface {
+		return &solver.Euler{F: func(x, y float64) (float64, error) {
+			if x > 0.5 {
+				return 0, errors.New("broken")
+			}
+			return f(x, y)
+		}}
+	})
+	t.Cleanup(func() { solver.Unregister("broken") })
+}
+
+func TestRest_SolvePartialFailure(t *testing.T) {
+	breakMethod(t)
+	_, ts := prepTestServer(t)
+
+	resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(`{
+		"f": "y*y*exp(x) - 2*y", "exact": "exp(-x) / (c*exp(x) + 1)", "c": "(exp(-x0) - y0) / (y0 * exp(x0))",
+		"x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4", "broken", "exact"]
+	}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	res := solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Lines, 3)
+	assert.Nil(t, res.Exact, "listed exact solution must be in lines")
+
+	assert.Equal(t, "rk4", res.Lines[0].Method)
+	assert.Nil(t, res.Lines[0].Error)
+	assert.Len(t, res.Lines[0].Points, 11)
+
+	assert.Equal(t, "broken", res.Lines[1].Method)
+	require.NotNil(t, res.Lines[1].Error)
+	assert.Equal(t, rest.ErrInternal, res.Lines[1].Error.Code)
+	assert.Contains(t, res.Lines[1].Error.Error, "Euler's method failed at step 6, f at x=0.6000 y=")
+	loc := res.Lines[1].Error.Location
+	require.NotNil(t, loc, "the failed point is reported")
+	assert.Equal(t, rest.ErrorLocation{Method: "Euler's method", Step: 6, Stage: "f", X: loc.X, Y: loc.Y}, *loc)
+	assert.InDelta(t, 0.6, loc.X, 1e-12)
+	assert.Empty(t, res.Lines[1].Points)
+
+	assert.Equal(t, "exact", res.Lines[2].Method)
+	assert.Equal(t, "Exact solution", res.Lines[2].Name)
+	assert.Nil(t, res.Lines[2].Error)
+	assert.InDelta(t, res.Lines[2].Points[10].Y, res.Lines[0].Points[10].Y, 1e-5)
+
+	// failed lines are skipped in csv
+	resp, err = http.Get(ts.URL + "/api/v1/solve?f=x&x0=0&y0=1&x1=1&n=10&method=rk4&method=broken&format=csv")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	b, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(b), "x,rk4\n"), string(b))
+
+	// the request fails, if all methods fail
+	resp, err = http.Post(ts.URL+"/api/v1/solve", "application/json",
+		strings.NewReader(`{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["broken"]}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
+
+func TestRest_SolveNonFinite(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	// rk4 evaluates f at the pole x=0.375 on the midpoint of the second step, euler steps over it
+	resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(`{"f": "1/(x-0.375)",
+		"x0": 0, "y0": 1, "x_end": 1, "n": 4, "methods": ["euler", "rk4"]}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	res := solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Lines, 2)
+	assert.Nil(t, res.Lines[0].Error)
+	assert.Len(t, res.Lines[0].Points, 5)
+	require.NotNil(t, res.Lines[1].Error, "the not finite value fails the method")
+	loc := res.Lines[1].Error.Location
+	require.NotNil(t, loc)
+	assert.Equal(t, "k2", loc.Stage)
+	assert.Equal(t, 0.375, loc.X)
+	assert.Empty(t, res.Lines[1].Points)
+
+	// the overflow fails all methods, the response is still the json error
+	resp, err = http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(`{"f": "y",
+		"x0": 0, "y0": 1, "x_end": 1e308, "n": 10, "methods": ["euler"]}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+	errResp := rest.ErrorResponse{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+	assert.Contains(t, errResp.Error, "y is not finite")
+	assert.Nil(t, errResp.Location, "the not finite point can't be the location")
+}
+
+func TestRest_GetSolve(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	post := func(body string) solve.Response {
+		resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		res := solve.Response{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+		return res
+	}
+	get := func(query string) solve.Response {
+		resp, err := http.Get(ts.URL + "/api/v1/solve?" + query)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "public, max-age=3600", resp.Header.Get("Cache-Control"))
+		res := solve.Response{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+		return res
+	}
+	pointsJSON := func(res solve.Response) string {
+		var data []byte
+		for _, l := range res.Lines {
+			b, err := json.Marshal(l.Points)
+			require.NoError(t, err)
+			data = append(data, b...)
+		}
+		return string(data)
+	}
+
+	expected := pointsJSON(post(`{"f": "x^2-2*y", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4", "euler"]}`))
+	assert.Equal(t, expected, pointsJSON(get("f=x^2-2*y&x0=0&y0=1&x1=1&n=10&method=rk4&method=euler")))
+	assert.Equal(t, expected, pointsJSON(get("f=x%5E2-2*y&x0=0&y0=1&x1=1&n=10&methods=rk4,euler")))
+	assert.Equal(t, expected, pointsJSON(get("f="+url.PathEscape("x^2 - 2*y")+"&x0=0&y0=1&x1=1&n=10&method=rk4,euler")))
+
+	// unescaped plus sign is kept as is
+	expected = pointsJSON(post(`{"f": "x+y", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"]}`))
+	assert.Equal(t, expected, pointsJSON(get("f=x+y&x0=0&y0=1&x1=1&n=10&method=rk4")))
+	assert.Equal(t, expected, pointsJSON(get("f=x%2By&x0=0&y0=1&x1=1&n=10&method=rk4")))
+	assert.Equal(t, expected, pointsJSON(get("f=x%20%2B%20y&x0=0&y0=1&x1=1&n=10&method=rk4")))
+}
+
+func TestRest_GetSolveCSV(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/api/v1/solve?f=x**2-2*y&x0=0&y0=1&x1=1&step=0.5&method=euler&format=csv")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/csv; charset=utf-8", resp.Header.Get("Content-Type"))
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "x,euler\n0,1\n0.5,0\n1,0.125\n", string(body))
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/v1/solve?f=x&method=euler&n=1", strings.NewReader("{}"))
+	require.NoError(t, err)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "body must be rejected")
+
+	resp, err = http.Get(ts.URL + "/api/v1/solve?f=x&x1=1&method=euler&n=1&format=xml")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestRest_SolveCSVDownload(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	q := url.Values{
+		"f":      {"y*y*exp(x) - 2*y"},
+		"exact":  {"exp(-x) / (c*exp(x) + 1)"},
+		"c":      {"(exp(-x0) - y0) / (y0 * exp(x0))"},
+		"x0":     {"0"},
+		"y0":     {"1"},
+		"x1":     {"1"},
+		"n":      {"20"},
+		"method": {"rk4", "euler"},
+	}
+	resp, err := http.Get(ts.URL + "/api/v1/solve.csv?" + strings.ReplaceAll(q.Encode(), "+", "%20"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/csv; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "attachment; filename=solution-y-y-exp-x-2-y.csv", resp.Header.Get("Content-Disposition"))
+
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	require.NoError(t, err)
+	require.True(t, len(records) > 20)
+	assert.Equal(t, []string{"x", "rk4", "euler", "exact"}, records[0])
+
+	f := func(x, y float64) (float64, error) { return y*y*math.Exp(x) - 2*y, nil }
+	direct := map[string]solver.Interface{
+		"rk4":   &solver.RungeKutta{F: f},
+		"euler": &solver.Euler{F: f},
+		"exact": &solver.Exact{
+			F: func(x, c float64) (float64, error) { return math.Exp(-x) / (c*math.Exp(x) + 1), nil },
+			C: func(x0, y0 float64) (float64, error) { return (math.Exp(-x0) - y0) / (y0 * math.Exp(x0)), nil },
+		},
+	}
+	for col, method := range records[0][1:] {
+		c := &solver.Collector{}
+		require.NoError(t, direct[method].Solve(0.05, 0, 1, 1, c))
+		require.Len(t, c.Points, len(records)-1, method)
+		for i, pt := range c.Points {
+			x, err := strconv.ParseFloat(records[i+1][0], 64)
+			require.NoError(t, err)
+			y, err := strconv.ParseFloat(records[i+1][col+1], 64)
+			require.NoError(t, err)
+			assert.InDelta(t, pt.X, x, 1e-9, "%s, row %d", method, i)
+			assert.InDelta(t, pt.Y, y, 1e-9, "%s, row %d", method, i)
+		}
+	}
+
+	// errors are returned as json without the attachment
+	resp, err = http.Get(ts.URL + "/api/v1/solve.csv?f=x&x0=0&y0=1&x1=1&n=10&method=rk5")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("Content-Disposition"))
+	assert.Contains(t, resp.Header.Get("Content-Type"), "application/json")
+}
+
+func TestCSVFilename(t *testing.T) {
+	tbl := []struct {
+		f, name string
+	}{
+		{"x^2 - 2*y", "solution-x-2-2-y.csv"},
+		{"sin(x)", "solution-sin-x.csv"},
+		{"\"; rm -rf /", "solution-rm-rf.csv"},
+		{"π*x", "solution-x.csv"},
+		{"*", "solution.csv"},
+		{strings.Repeat("x", 100), "solution-" + strings.Repeat("x", maxFilenameLen) + ".csv"},
+	}
+	for _, tt := range tbl {
+		assert.Equal(t, tt.name, csvFilename(tt.f), tt.f)
+	}
+}
+
+func TestRest_SolveNByMethod(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	body := `{"f": "y", "exact": "c*exp(x)", "c": "y0/exp(x0)", "x0": 0, "y0": 1, "x_end": 1,
+		"n_by_method": {"euler": 40, "rk4": 10}, "methods": ["euler", "rk4"]}`
+	resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.True(t, res.GridsDiffer)
+	assert.InDelta(t, 0.025, res.Step, 1e-12, "the exact solution has the least step")
+	require.Len(t, res.Lines, 2)
+	for i, tt := range []struct {
+		step float64
+		n    int
+	}{{0.025, 40}, {0.1, 10}} {
+		assert.InDelta(t, tt.step, res.Lines[i].Step, 1e-12)
+		assert.Len(t, res.Lines[i].Points, tt.n+1)
+		assert.Equal(t, tt.n*(3*i+1), res.Lines[i].Stats.Evals, "euler evaluates f once per step, rk4 four times")
+	}
+	require.NotNil(t, res.Exact)
+	assert.InDelta(t, 0.025, res.Exact.Step, 1e-12)
+	assert.Len(t, res.Exact.Points, 41)
+
+	// lines on their own grids are written as rows of the method, x and y
+	resp, err = http.Get(ts.URL + "/api/v1/solve?f=y&x0=0&y0=1&x1=1&n_by_method=euler:2,rk4:1&method=euler&method=rk4" +
+		"&format=csv")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	b, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "method,x,y\neuler,0,1\neuler,0.5,1.5\neuler,1,2.25\nrk4,0,1\nrk4,1,2.708333333333333\n", string(b))
+
+	// without n_by_method lines share the grid, so the response doesn't tell it
+	resp, err = http.Get(ts.URL + "/api/v1/solve?f=y&x0=0&y0=1&x1=1&n=2&method=euler")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	b, err = ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "grids_differ")
+
+	resp, err = http.Get(ts.URL + "/api/v1/solve?f=y&x0=0&y0=1&x1=1&n_by_method=euler&method=euler")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestRest_RateLimit(t *testing.T) {
+	srv := &Rest{Version: "test", NumService: &service.Service{}, RateLimit: 0.001, RateBurst: 2}
+	ts := httptest.NewServer(srv.routes())
+	defer ts.Close()
+
+	for i := 0; i < 2; i++ {
+		resp, err := http.Get(ts.URL + "/api/v1/solve?f=x&x1=1&n=1&method=euler")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	resp, err := http.Get(ts.URL + "/api/v1/solve/stream?f=x&x1=1&n=1&method=euler")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+
+	// ping is not limited
+	for i := 0; i < 5; i++ {
+		resp, err = http.Get(ts.URL + "/ping")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	// static assets are not limited by the limit of all api requests of the client
+	for i := 0; i < 110; i++ {
+		resp, err = http.Get(ts.URL + "/static/view.00000000.css")
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusNotFound, resp.StatusCode, "request %d", i)
+	}
+	status := 0
+	for i := 0; i < 110 && status != http.StatusTooManyRequests; i++ {
+		resp, err = http.Get(ts.URL + "/api/v1/info")
+		require.NoError(t, err)
+		resp.Body.Close()
+		status = resp.StatusCode
+	}
+	assert.Equal(t, http.StatusTooManyRequests, status)
+}
+
+func TestRest_APIKeys(t *testing.T) {
+	srv := &Rest{Version: "test", NumService: &service.Service{}, RateLimit: 100, RateBurst: 100,
+		APIKeys: []rest.APIKey{{Hash: rest.HashAPIKey("secret")}, {Hash: rest.HashAPIKey("limited"), Rate: 0.001, Burst: 1}}}
+	ts := httptest.NewServer(srv.routes())
+	defer ts.Close()
+
+	do := func(method, path, key string) int {
+		var body io.Reader
+		if method == http.MethodPost {
+			body = strings.NewReader(`{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 1, "methods": ["euler"]}`)
+		}
+		req, err := http.NewRequest(method, ts.URL+path, body)
+		require.NoError(t, err)
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	query := "?f=x&x1=1&n=1&method=euler"
+	for _, route := range []struct{ method, path string }{
+		{http.MethodPost, "/api/v1/solve"},
+		{http.MethodGet, "/api/v1/solve" + query},
+		{http.MethodGet, "/api/v1/solve.csv" + query},
+		{http.MethodGet, "/api/v1/solve/stream" + query},
+		{http.MethodGet, "/api/v1/chart" + query},
+		{http.MethodGet, "/api/chart/field?f=x"},
+		{http.MethodGet, "/api/v1/ws"},
+		{http.MethodGet, "/ws/solve"},
+		{http.MethodPost, "/"},
+	} {
+		assert.Equal(t, http.StatusUnauthorized, do(route.method, route.path, ""), route.path)
+		assert.Equal(t, http.StatusForbidden, do(route.method, route.path, "wrong"), route.path)
+	}
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodPost, "/api/v1/solve/batch", ""))
+
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "/api/v1/solve", "secret"))
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/v1/chart"+query+"&api_key=secret", ""))
+	form := url.Values{"x0": {"0"}, "y0": {"1"}, "x_end": {"1"}, "n": {"10"}, "nmin": {"10"}, "nmax": {"20"},
+		"fxy": {"-y"}, "yxc": {"c*exp(-x)"}, "c": {"y0*exp(x0)"}}
+	resp, err := http.PostForm(ts.URL+"/?api_key=secret", form)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "the form is solved with the key")
+
+	// the key with its own rate is limited by it instead of the rate of the server
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/v1/solve"+query, "limited"))
+	assert.Equal(t, http.StatusTooManyRequests, do(http.MethodGet, "/api/v1/solve"+query, "limited"))
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/v1/solve"+query, "secret"))
+
+	// service routes and ui stay open
+	for _, path := range []string{"/ping", "/api/v1/info", "/api/v1/openapi.json", "/api/v1/history", "/"} {
+		assert.Equal(t, http.StatusOK, do(http.MethodGet, path, ""), path)
+	}
+}
+
+func TestRest_APIKeysDisabled(t *testing.T) {
+	_, ts := prepTestServer(t)
+	for _, path := range []string{"/api/v1/solve?f=x&x1=1&n=1&method=euler", "/api/v1/solve?f=x&x1=1&n=1&method=euler&api_key=any"} {
+		resp, err := http.Get(ts.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "api must be open without keys")
+	}
+}
+
+func TestRest_SolveValidation(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	type fieldErrors struct {
+		Errors []rest.FieldError `json:"errors"`
+	}
+
+	tbl := []struct {
+		name   string
+		body   string
+		errors []rest.FieldError
+	}{
+		{
+			name: "n and step are mutually exclusive",
+			body: `{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "step": 0.1, "methods": ["rk4"]}`,
+			errors: []rest.FieldError{
+				{Field: "step", Msg: "must not be set together with n"},
+			},
+		},
+		{
+			name: "multiple errors",
+			body: `{"f": "x +* y", "x0": 1, "y0": 1, "x_end": 1, "n": 100000, "methods": ["rk4", "rk5"], "exact": "x"}`,
+			errors: []rest.FieldError{
+				{Field: "x_end", Msg: "must differ from x0, the interval of zero length can't be split into n steps"},
+				{Field: "n", Msg: "must be between 1 and max_steps=10000, got 100000"},
+				{Field: "f", Msg: `can't parse f(x,y): unexpected "*", the operand is missing at position 4`, Column: 4},
+				{Field: "methods", Msg: `unknown method "rk5"`},
+				{Field: "c", Msg: "can't parse c(x0,y0): unexpected end of formula, the operand is missing at position 1", Column: 1},
+			},
+		},
+		{
+			name: "no steps and methods",
+			body: `{"f": "x", "x0": 0, "y0": 1, "x_end": 1}`,
+			errors: []rest.FieldError{
+				{Field: "n", Msg: "either n or step must be set"},
+				{Field: "methods", Msg: "must not be empty"},
+			},
+		},
+		{
+			name: "too small step",
+			body: `{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "step": 0.00001, "methods": ["euler"]}`,
+			errors: []rest.FieldError{
+				{Field: "step", Msg: "gives 100000 steps, more than max_steps=10000"},
+			},
+		},
+		{
+			name: "invalid params",
+			body: `{"f": "k*x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["euler"],
+				"params": {"k": 1, "x": 1, "exp": 2, "2k": 3, "k-1": 4}}`,
+			errors: []rest.FieldError{
+				{Field: "params", Msg: `"2k" is not a valid name`},
+				{Field: "params", Msg: `"exp" is reserved`},
+				{Field: "params", Msg: `"k-1" is not a valid name`},
+				{Field: "params", Msg: `"x" is reserved`},
+			},
+		},
+		{
+			name: "empty interval with n",
+			body: `{"f": "x", "x0": 2, "y0": 1, "x_end": 2, "n": 10, "methods": ["euler"]}`,
+			errors: []rest.FieldError{
+				{Field: "x_end", Msg: "must differ from x0, the interval of zero length can't be split into n steps"},
+			},
+		},
+		{
+			name: "interval too wide for the step",
+			body: `{"f": "x", "x0": -1.7e308, "y0": 1, "x_end": 1.7e308, "n": 1, "methods": ["euler"]}`,
+			errors: []rest.FieldError{
+				{Field: "n", Msg: "gives the step +Inf, the interval is too wide"},
+			},
+		},
+		{
+			name: "initial value incompatible with the exact solution",
+			body: `{"f": "y*y*exp(x) - 2*y", "exact": "exp(-x) / (c*exp(x) + 1)", "c": "(exp(-x0) - y0) / (y0 * exp(x0))",
+				"x0": 0, "y0": 0, "x_end": 1, "n": 10, "methods": ["rk4", "exact"]}`,
+			errors: []rest.FieldError{
+				{Field: "y0", Msg: "is incompatible with the exact solution, c(x0,y0) is not finite"},
+			},
+		},
+		{
+			name: "initial value outside the domain of f",
+			body: `{"f": "log(y)", "x0": 0, "y0": -1, "x_end": 1, "n": 10, "methods": ["rk4"]}`,
+			errors: []rest.FieldError{
+				{Field: "y0", Msg: "is outside the domain of f, f(x0,y0) = NaN"},
+			},
+		},
+		{
+			name: "initial value outside the domain of the exact solution",
+			body: `{"f": "x", "exact": "c/x", "c": "y0", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"]}`,
+			errors: []rest.FieldError{
+				{Field: "y0", Msg: "is outside the domain of the exact solution, y(x0,c) = +Inf"},
+			},
+		},
+		{
+			name: "n_by_method with n",
+			body: `{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "n_by_method": {"euler": 40}, "methods": ["euler"]}`,
+			errors: []rest.FieldError{
+				{Field: "n_by_method", Msg: "must not be set together with n or step"},
+			},
+		},
+		{
+			name: "invalid n_by_method",
+			body: `{"f": "x", "exact": "x", "c": "0", "x0": 0, "y0": 0, "x_end": 1,
+				"n_by_method": {"euler": 40, "rk4": 100000, "ieuler": 0, "lobatto": 2, "exact": 10},
+				"methods": ["euler", "rk4", "ieuler", "exact", "midpoint"]}`,
+			errors: []rest.FieldError{
+				{Field: "n_by_method", Msg: "midpoint has no number of steps"},
+				{Field: "n_by_method", Msg: "exact solution has no steps, it is drawn with the least step of methods"},
+				{Field: "n_by_method", Msg: "ieuler: must be between 1 and max_steps=10000, got 0"},
+				{Field: "n_by_method", Msg: "lobatto is not requested in methods"},
+				{Field: "n_by_method", Msg: "rk4: must be between 1 and max_steps=10000, got 100000"},
+				{Field: "methods", Msg: `unknown method "midpoint"`},
+			},
+		},
+		{
+			name: "negative step",
+			body: `{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "step": -1, "methods": ["euler"]}`,
+			errors: []rest.FieldError{
+				{Field: "step", Msg: "must be positive"},
+			},
+		},
+	}
+
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+			res := fieldErrors{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+			assert.Equal(t, tt.errors, res.Errors)
+		})
+	}
+
+	// the same validation for query parameters
+	resp, err := http.Get(ts.URL + "/api/v1/solve?f=x&x0=NaN&x1=1&n=1&method=euler")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	res := fieldErrors{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.Equal(t, []rest.FieldError{{Field: "x0", Msg: "must be finite"}}, res.Errors)
+
+	// problem details are responded, if the client accepts them
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/api/v1/solve", strings.NewReader(
+		`{"f": "x + (y", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"]}`))
+	require.NoError(t, err)
+	req.Header.Set("Accept", "application/problem+json, application/json;q=0.9")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "application/problem+json; charset=utf-8", resp.Header.Get("Content-Type"))
+	problem := rest.ProblemResponse{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	assert.Equal(t, rest.ProblemResponse{Type: "about:blank", Title: "Bad Request", Status: http.StatusBadRequest,
+		Detail: "invalid solve request: f: can't parse f(x,y): unclosed parenthesis at position 5", Instance: "/api/v1/solve",
+		Code: rest.ErrBadRequest, Errors: rest.ValidationError{{Field: "f",
+			Msg: "can't parse f(x,y): unclosed parenthesis at position 5", Column: 5}}}, problem)
+}
+
+func TestRest_SolveEmptyInterval(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	// the interval of the single point is solved with the step as the initial point
+	resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(`{"f": "x*x - 2*y",
+		"exact": "exp(-2*x) * c", "c": "y0 * exp(2*x0)", "x0": 2, "y0": 3, "x_end": 2, "step": 0.1,
+		"methods": ["euler", "ieuler", "rk4", "exact"]}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	res := solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Lines, 4)
+	for _, line := range res.Lines {
+		assert.Nil(t, line.Error, line.Method)
+		assert.Equal(t, []num.Point{{X: 2, Y: 3}}, line.Points, line.Method)
+	}
+}
+
+func TestRest_SolveLostPoints(t *testing.T) {
+	// the faulty solver skips the last point of the grid
+	solver.Register("lossy", func(f solver.Func) solver.Interface { return lossySolver{&solver.Euler{F: f}} })
+	defer solver.Unregister("lossy")
+	_, ts := prepTestServer(t)
+
+	resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json",
+		strings.NewReader(`{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "step": 0.3, "methods": ["rk4", "lossy"]}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	res := solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Lines, 2)
+	assert.Nil(t, res.Lines[0].Error)
+	assert.Len(t, res.Lines[0].Points, 5, "the shortened last step ends at x_end")
+	require.NotNil(t, res.Lines[1].Error)
+	assert.Equal(t, rest.ErrInternal, res.Lines[1].Error.Code)
+	assert.Contains(t, res.Lines[1].Error.Error, "solver lost points: got 4 points, expected 5")
+}
+
+// lossySolver drops the last point of the solution
+type lossySolver struct{ solver.Interface }
+
+func (l lossySolver) Solve(stepSize, x0, y0, xEnd float64, d solver.Drawer) error {
+	return l.Interface.Solve(stepSize, x0, y0, xEnd, solver.DrawerFunc(func(p num.Point) error {
+		if p.X == xEnd {
+			return nil
+		}
+		return d.Draw(p)
+	}))
+}
+
+func TestRest_SolveDiscontinuities(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	// the constant places the pole of the exact solution at x = 1.23
+	resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(`{"f": "y*y*exp(x) - 2*y",
+		"exact": "exp(-x) / (c*exp(x) + 1)", "c": "-exp(-1.23)", "x0": 0, "y0": 1, "x_end": 3, "n": 30,
+		"methods": ["euler", "exact"]}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	res := solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Lines, 2)
+	assert.Empty(t, res.Lines[0].Discontinuities, "only the exact solution is checked")
+	require.Len(t, res.Lines[1].Discontinuities, 1)
+	br := res.Lines[1].Discontinuities[0]
+	assert.InDelta(t, 1.2, br.Left, 1e-9)
+	assert.InDelta(t, 1.3, br.Right, 1e-9)
+	assert.Len(t, res.Lines[1].Points, 31, "the solution is not failed")
+}
+
+func TestRest_SolveSensitivity(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	// y' = x^2 - 2y is linear, its sensitivity to y0 is exp(-2x)
+	resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(`{"f": "x^2 - 2*y",
+		"exact": "x^2/2 - x/2 + 1/4 + c*exp(-2*x)", "c": "(y0 - x0^2/2 + x0/2 - 1/4)*exp(2*x0)",
+		"x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4", "exact"], "sensitivity": true}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Lines, 2)
+	require.Len(t, res.Lines[0].Sensitivity, 11)
+	for _, p := range res.Lines[0].Sensitivity {
+		assert.InDelta(t, math.Exp(-2*p.X), p.Y, 1e-5, "x=%v", p.X)
+	}
+	assert.Empty(t, res.Lines[1].Sensitivity, "the exact solution has no sensitivity")
+
+	// the variational equation with df/dy by the query
+	q := url.Values{"f": {"x^2 - 2*y"}, "x0": {"0"}, "y0": {"1"}, "x1": {"1"}, "n": {"10"}, "method": {"euler"},
+		"sensitivity": {"true"}, "dfdy": {"-2"}}
+	resp, err = http.Get(ts.URL + "/api/v1/solve?" + strings.ReplaceAll(q.Encode(), "+", "%20"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res = solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Lines[0].Sensitivity, 11)
+	for _, p := range res.Lines[0].Sensitivity {
+		assert.InDelta(t, math.Exp(-2*p.X), p.Y, 1e-5, "rk4 solves the variational equation, x=%v", p.X)
+	}
+
+	resp, err = http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(`{"f": "y",
+		"x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"], "dfdy": "1"}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	er := rest.ErrorResponse{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&er))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, er.Errors, rest.FieldError{Field: "dfdy", Msg: "is used only with sensitivity"})
+}
+
+func TestRest_SolveEstimates(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	// the local error of the step of euler from y for y' = y is y*(exp(h) - 1 - h)
+	resp, err := http.Get(ts.URL + "/api/v1/solve?f=y&x0=0&y0=1&x1=1&n=10&method=euler&method=exact&estimates=true" +
+		"&exact=c*exp(x)&c=y0/exp(x0)")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Lines, 2)
+	line := res.Lines[0]
+	require.Len(t, line.Estimates, 11)
+	assert.Equal(t, num.Point{}, line.Estimates[0], "the initial value is exact")
+	for i := 1; i < len(line.Estimates); i++ {
+		assert.Equal(t, line.Points[i].X, line.Estimates[i].X)
+		assert.InEpsilon(t, line.Points[i-1].Y*(math.Exp(0.1)-1.1), line.Estimates[i].Y, 0.05, "x=%v", line.Points[i].X)
+	}
+	assert.Empty(t, res.Lines[1].Estimates, "the exact solution has no estimates")
+
+	solver.Register("lossy", func(f solver.Func) solver.Interface { return lossySolver{&solver.Euler{F: f}} })
+	defer solver.Unregister("lossy")
+	tbl := []struct {
+		body string
+		err  rest.FieldError
+	}{
+		{`{"f": "y", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["lossy"], "estimates": true}`,
+			rest.FieldError{Field: "estimates",
+				Msg: `the order of "lossy" is unknown, errors are estimated only for euler, ieuler and rk4`}},
+		{`{"f": "y", "x0": 0, "y0": 1, "x_end": 1, "step": 0.1, "methods": ["rk4"], "estimates": true, "warm": true}`,
+			rest.FieldError{Field: "warm", Msg: "must not be set together with estimates"}},
+		{`{"f": "y", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"], "estimates": true,
+			"auto_refine": {"tol": 1e-6}}`,
+			rest.FieldError{Field: "estimates", Msg: "must not be set together with auto_refine"}},
+	}
+	for _, tt := range tbl {
+		resp, err = http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(tt.body))
+		require.NoError(t, err)
+		er := rest.ErrorResponse{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&er))
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, tt.err.Msg)
+		assert.Contains(t, er.Errors, tt.err)
+	}
+}
+
+func TestRest_SolveWithErrors(t *testing.T) {
+	srv, ts := prepTestServer(t)
+
+	body := `{"f": "x^2 - 2*y", "exact": "x^2/2 - x/2 + 1/4 + c*exp(-2*x)", "c": "(y0 - x0^2/2 + x0/2 - 1/4)*exp(2*x0)",
+		"x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["euler", "rk4"], "errors": true, "save": true}`
+	resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.NotNil(t, res.Exact)
+	require.Len(t, res.Errors, 2)
+	for i, et := range res.Errors {
+		assert.Equal(t, res.Lines[i].Method, et.Method)
+		require.Len(t, et.Points, 11)
+		for j, p := range et.Points {
+			assert.Equal(t, math.Abs(res.Lines[i].Points[j].Y-res.Exact.Points[j].Y), p.Y)
+		}
+	}
+	assert.Less(t, res.Errors[1].Points[10].Y, res.Errors[0].Points[10].Y, "rk4 is more accurate")
+
+	// errors are not stored with the saved solution, but calculated on load
+	saved, err := srv.loadResult(res.ID)
+	require.NoError(t, err)
+	assert.Empty(t, saved.Result.Errors)
+	assert.Equal(t, res.Errors, saved.Errors)
+
+	// by the query
+	q := url.Values{"f": {"x^2 - 2*y"}, "exact": {"x^2/2 - x/2 + 1/4 + c*exp(-2*x)"},
+		"c": {"(y0 - x0^2/2 + x0/2 - 1/4)*exp(2*x0)"}, "x0": {"0"}, "y0": {"1"}, "x1": {"1"}, "n": {"10"},
+		"method": {"rk4"}, "errors": {"true"}}
+	resp, err = http.Get(ts.URL + "/api/v1/solve?" + strings.ReplaceAll(q.Encode(), "+", "%20"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res = solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Errors, 1)
+
+	// errors require the exact solution
+	resp, err = http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(`{"f": "y",
+		"x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"], "errors": true}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	er := rest.ErrorResponse{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&er))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, er.Errors, rest.FieldError{Field: "errors", Msg: "require exact and c or linear"})
+}
+
+func TestRest_SolveLinear(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	// y' + 2y = x^2, f is derived from p and q
+	body := `{"linear": {"p": "k", "q": "x^2"}, "params": {"k": 2}, "x0": 0, "y0": 1, "x_end": 1, "n": 10,
+		"methods": ["rk4", "exact"], "errors": true}`
+	resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Lines, 2)
+	require.Nil(t, res.Lines[1].Error)
+	for _, p := range res.Lines[1].Points {
+		assert.InDelta(t, p.X*p.X/2-p.X/2+0.25+0.75*math.Exp(-2*p.X), p.Y, 1e-8, "x=%v", p.X)
+	}
+	require.Len(t, res.Errors, 1)
+	assert.Less(t, res.Errors[0].Points[10].Y, 1e-5)
+
+	// by the query
+	q := url.Values{"f": {"x^2 - 2*y"}, "p": {"2"}, "q": {"x^2"}, "x0": {"0"}, "y0": {"1"}, "x1": {"1"}, "n": {"10"},
+		"method": {"exact"}}
+	resp, err = http.Get(ts.URL + "/api/v1/solve?" + strings.ReplaceAll(q.Encode(), "+", "%20"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res = solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Lines, 1)
+	assert.InDelta(t, 0.25+0.75*math.Exp(-2), res.Lines[0].Points[10].Y, 1e-8)
+
+	tbl := []struct {
+		body string
+		msg  string
+	}{
+		{`{"linear": {"p": "2", "q": "x"}, "exact": "c", "c": "y0", "x0": 0, "y0": 1, "x_end": 1, "n": 10,
+			"methods": ["rk4"]}`, "must not be set together with exact and c"},
+		{`{"linear": {"p": "y", "q": "x"}, "f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"]}`,
+			"can't parse p(x): unknown variable \"y\", available: x at position 1"},
+	}
+	for _, tt := range tbl {
+		resp, err = http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(tt.body))
+		require.NoError(t, err)
+		er := rest.ErrorResponse{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&er))
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, tt.msg)
+		assert.Contains(t, er.Errors, rest.FieldError{Field: "linear", Msg: tt.msg})
+	}
+}
+
+func TestRest_SolveNumericC(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	// the canonical problem with the constant, found by the exact solution
+	body := `{"f": "x^2 - 2*y", "exact": "x^2/2 - x/2 + 1/4 + c*exp(-2*x)", "c_bracket": {"lo": -10, "hi": 10},
+		"x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["exact"]}`
+	resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Lines, 1)
+	assert.InDelta(t, 0.25+0.75*math.Exp(-2), res.Lines[0].Points[10].Y, 1e-9)
+
+	// by the query
+	q := url.Values{"f": {"x^2 - 2*y"}, "exact": {"x^2/2 - x/2 + 1/4 + c*exp(-2*x)"}, "c_bracket": {"-10:10:1e-12"},
+		"x0": {"0"}, "y0": {"1"}, "x1": {"1"}, "n": {"10"}, "method": {"exact"}}
+	resp, err = http.Get(ts.URL + "/api/v1/solve?" + strings.ReplaceAll(q.Encode(), "+", "%20"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res = solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.InDelta(t, 0.25+0.75*math.Exp(-2), res.Lines[0].Points[10].Y, 1e-12)
+
+	tbl := []struct {
+		body string
+		msg  string
+	}{
+		{`{"f": "y", "exact": "c*exp(x)", "c": "y0", "c_bracket": {"lo": 0, "hi": 1}, "x0": 0, "y0": 1, "x_end": 1,
+			"n": 10, "methods": ["exact"]}`, "must not be set together with c"},
+		{`{"f": "y", "exact": "c*exp(x)", "c_bracket": {"lo": 1, "hi": 0}, "x0": 0, "y0": 1, "x_end": 1,
+			"n": 10, "methods": ["exact"]}`, "must be finite with lo < hi, got [1, 0]"},
+		{`{"f": "y", "exact": "c*exp(x)", "c_bracket": {"lo": 0, "hi": 1, "tol": -1}, "x0": 0, "y0": 1, "x_end": 1,
+			"n": 10, "methods": ["exact"]}`, "tol must be positive and finite, got -1"},
+		{`{"f": "y", "exact": "c*exp(x)", "c_bracket": {"lo": 2, "hi": 3}, "x0": 0, "y0": 1, "x_end": 1,
+			"n": 10, "methods": ["exact"]}`, "can't find c: residuals 1 and 2 at ends of the bracket [2, 3] don't differ in sign"},
+	}
+	for _, tt := range tbl {
+		resp, err = http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(tt.body))
+		require.NoError(t, err)
+		er := rest.ErrorResponse{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&er))
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, tt.msg)
+		assert.Contains(t, er.Errors, rest.FieldError{Field: "c_bracket", Msg: tt.msg})
+	}
+}
+
+func TestRest_SolveStats(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(`{"f": "x^2 - 2*y",
+		"exact": "x^2/2 - x/2 + 1/4 + c*exp(-2*x)", "c": "(y0 - x0^2/2 + x0/2 - 1/4)*exp(2*x0)",
+		"x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["euler", "rk4", "exact"]}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Lines, 3)
+
+	// the solutions decrease from y0 to the minimum near x=0.8 and grow after it
+	tbl := []struct {
+		evals      int
+		min, final float64
+	}{{evals: 10, min: 0.27502, final: 0.30821}, {evals: 40, min: 0.321430, final: 0.351509},
+		{evals: 0, min: 0.321422, final: 0.351501}}
+	for i, tt := range tbl {
+		st := res.Lines[i].Stats
+		require.NotNil(t, st, res.Lines[i].Method)
+		assert.Equal(t, 11, st.Points, res.Lines[i].Method)
+		assert.Equal(t, tt.evals, st.Evals, res.Lines[i].Method)
+		assert.Equal(t, &num.Point{X: 0, Y: 1}, st.Max, res.Lines[i].Method)
+		require.NotNil(t, st.Min, res.Lines[i].Method)
+		assert.InDelta(t, 0.8, st.Min.X, 1e-9, res.Lines[i].Method)
+		assert.InDelta(t, tt.min, st.Min.Y, 1e-5, res.Lines[i].Method)
+		require.NotNil(t, st.Final, res.Lines[i].Method)
+		assert.InDelta(t, 1, st.Final.X, 1e-9, res.Lines[i].Method)
+		assert.InDelta(t, tt.final, st.Final.Y, 1e-5, res.Lines[i].Method)
+		assert.Empty(t, st.Warnings, res.Lines[i].Method)
+	}
+
+	// points are summarized before downsampling
+	p, err := solve.Request{F: "x^2 - 2*y", X0: 0, Y0: 1, XEnd: 1, N: 10, Methods: []string{"euler"}}.Prepare(solve.Limits{MaxSteps: 10,
+		MaxPoints: 4})
+	require.NoError(t, err)
+	sr, err := p.Solve(context.Background())
+	require.NoError(t, err)
+	require.Len(t, sr.Lines[0].Points, 4)
+	assert.Equal(t, 11, sr.Lines[0].Stats.Points)
+	assert.InDelta(t, 0.27502, sr.Lines[0].Stats.Min.Y, 1e-5)
+	assert.Equal(t, []string{"points are downsampled from 11 to 4 by max_points"}, sr.Lines[0].Stats.Warnings)
+}
+
+func TestRest_CanonicalOutput(t *testing.T) {
+	srv, ts := prepTestServer(t)
+	srv.CanonicalOutput = true
+	ts.Config.Handler = srv.routes()
+
+	doSolve := func(body string) string {
+		resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	body := `{"f": "y*y*exp(x) - 2*y", "exact": "exp(-x) / (c*exp(x) + 1)", "c": "(exp(-x0) - y0) / (y0 * exp(x0))",
+		"x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4", "euler", "ieuler"]}`
+	first := doSolve(body)
+	assert.Equal(t, first, doSolve(body), "identical requests give byte-identical responses")
+	assert.Contains(t, first, `"took":""`)
+
+	// solutions of y' = x by improved Euler's method are exact, so they are the same on any platform
+	assert.Equal(t, `{"lines":[{"method":"ieuler","name":"Improved Euler's method","points":[{"x":0,"y":0},`+
+		`{"x":0.5,"y":0.125},{"x":1,"y":0.5}],"stats":{"evals":4,"final":{"x":1,"y":0.5},"max":{"x":1,"y":0.5},`+
+		`"min":{"x":0,"y":0},"points":3},"took":""}],"step":0.5,"took":""}`+"\n",
+		doSolve(`{"f": "x", "x0": 0, "y0": 0, "x_end": 1, "n": 2, "methods": ["ieuler"]}`))
+
+	resp, err := http.Get(ts.URL + "/api/v1/solve?f=y&x0=0&y0=1&x1=1&n=3&method=euler&format=csv")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "x,euler\n0,1\n0.333333333333,1.33333333333\n0.666666666667,1.77777777778\n1,2.37037037037\n",
+		string(b), "numbers of csv are formatted with 12 digits")
+}
+
+func TestRest_SolveCache(t *testing.T) {
+	calls := 0
+	solver.Register("counting", func(f solver.Func) solver.Interface {
+		return &solver.Euler{F: func(x, y float64) (float64, error) {
+			calls++
+			return f(x, y)
+		}}
+	})
+	defer solver.Unregister("counting")
+
+	srv := &Rest{Version: "test", NumService: &service.Service{}, CacheSize: 10}
+	ts := httptest.NewServer(srv.routes())
+	defer ts.Close()
+
+	doSolve := func(body string) (http.Header, solve.Response) {
+		resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		res := solve.Response{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+		return resp.Header, res
+	}
+
+	body := `{"f": "x**2 - 2*y", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["counting"]}`
+	hdr, first := doSolve(body)
+	assert.Equal(t, "MISS", hdr.Get("X-Cache"))
+	require.NotZero(t, calls)
+	solved := calls
+
+	hdr, second := doSolve(body)
+	assert.Equal(t, "HIT", hdr.Get("X-Cache"))
+	assert.Equal(t, solved, calls, "cached solution must not be solved again")
+	assert.Equal(t, first, second)
+
+	// the same problem via query parameters is served from the same cache
+	resp, err := http.Get(ts.URL + "/api/v1/solve?f=" + url.PathEscape("x**2 - 2*y") +
+		"&x0=0&y0=1&x1=1&n=10&method=counting&format=csv")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "HIT", resp.Header.Get("X-Cache"))
+
+	hdr, _ = doSolve(`{"f": "x**2 - 2*y", "x0": 0, "y0": 1, "x_end": 1, "n": 20, "methods": ["counting"]}`)
+	assert.Equal(t, "MISS", hdr.Get("X-Cache"))
+	assert.True(t, calls > solved)
+
+	// changed limits invalidate the cache
+	srv.Limits.MaxSteps = 100
+	solved = calls
+	hdr, _ = doSolve(body)
+	assert.Equal(t, "MISS", hdr.Get("X-Cache"))
+	assert.True(t, calls > solved)
+
+	// streaming bypasses the cache
+	resp, err = http.Get(ts.URL + "/api/v1/solve/stream?f=x&x0=0&y0=1&x1=1&n=10&method=counting")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Empty(t, resp.Header.Get("X-Cache"))
+}
+
+func TestRest_SolveETag(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	get := func(path, etag string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+path, http.NoBody)
+		require.NoError(t, err)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	query := "?f=x&x0=0&y0=1&x1=1&n=10&method=rk4"
+	resp := get("/api/v1/solve"+query, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	etag := resp.Header.Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Equal(t, "public, max-age=3600", resp.Header.Get("Cache-Control"))
+	assert.Equal(t, etag, get("/api/v1/solve"+query+"&format=json", "").Header.Get("ETag"), "json is the default")
+
+	resp = get("/api/v1/solve"+query, etag)
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+	// the format and the problem change the etag
+	csv := get("/api/v1/solve"+query+"&format=csv", "").Header.Get("ETag")
+	assert.NotEqual(t, etag, csv)
+	assert.NotEqual(t, etag, get("/api/v1/solve?f=x&x0=0&y0=2&x1=1&n=10&method=rk4", "").Header.Get("ETag"))
+	assert.Equal(t, http.StatusOK, get("/api/v1/solve?f=x&x0=0&y0=2&x1=1&n=10&method=rk4", etag).StatusCode)
+
+	// the download is the same csv
+	resp = get("/api/v1/solve.csv"+query, csv)
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+}
+
+func TestRest_SolveCompressed(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/api/v1/solve", strings.NewReader(
+		`{"f": "x**2 - 2*y", "x0": 0, "y0": 1, "x_end": 1, "n": 1000, "methods": ["rk4", "euler"]}`))
+	require.NoError(t, err)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+
+	gz, err := gzip.NewReader(resp.Body)
+	require.NoError(t, err)
+	res := solve.Response{}
+	require.NoError(t, json.NewDecoder(gz).Decode(&res))
+	require.Len(t, res.Lines, 2)
+	assert.NotEmpty(t, res.Lines[0].Points)
+}
+
+func TestRest_CORS(t *testing.T) {
+	srv := &Rest{Version: "test", NumService: &service.Service{}, CORSOrigins: []string{"https://example.com"}}
+	ts := httptest.NewServer(srv.routes())
+	defer ts.Close()
+
+	do := func(method, path string) *http.Response {
+		req, err := http.NewRequest(method, ts.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Origin", "https://example.com")
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		req.Header.Set("Access-Control-Request-Headers", "Content-Type")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := do(http.MethodOptions, "/api/v1/solve")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "https://example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+
+	resp = do(http.MethodGet, "/api/v1/info")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "https://example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+
+	// non-api routes are not affected
+	resp = do(http.MethodGet, "/metrics")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+}
+
+func TestRest_Index(t *testing.T) {
+	// the binary is started without any files of the ui on disk
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	dir, err := ioutil.TempDir("", "decompract-index")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { require.NoError(t, os.Chdir(wd)) })
+
+	_, ts := prepTestServer(t)
+	resp, err := http.Get(ts.URL + "/")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `<form id="form_5508" class="appnitro"  method="post" action="/">`)
+	assert.Contains(t, string(body), `<option value="auto">`, "the method is advised automatically")
+	assert.Contains(t, string(body), `<option value="beuler">Backward Euler&#39;s method</option>`,
+		"methods of the registry are listed")
+
+	urls := regexp.MustCompile(`(?:href|src)="(/static/[^"]+)"`).FindAllStringSubmatch(string(body), -1)
+	require.Len(t, urls, 4)
+	for _, u := range urls {
+		assert.Regexp(t, `^/static/\w+\.[0-9a-f]{8}\.\w+$`, u[1])
+		resp, err := http.Get(ts.URL + u[1])
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode, u[1])
+		assert.Contains(t, resp.Header.Get("Cache-Control"), "immutable", u[1])
+	}
+
+	resp, err = http.Get(ts.URL + "/static/view.00000000.css")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "stale hash must not resolve")
+}
+
+func TestRest_PlotGraphsMethod(t *testing.T) {
+	_, ts := prepTestServer(t)
+	post := func(method, fxy string) (int, string) {
+		form := url.Values{"x0": {"0"}, "y0": {"1"}, "x_end": {"1"}, "n": {"10"}, "nmin": {"10"}, "nmax": {"20"},
+			"fxy": {fxy}, "yxc": {"c*exp(-x)"}, "c": {"y0*exp(x0)"}, "method": {method}}
+		resp, err := http.PostForm(ts.URL+"/", form)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	status, body := post("beuler", "-y")
+	require.Equal(t, http.StatusOK, status, body)
+	assert.Contains(t, body, "Method: Backward Euler&#39;s method")
+
+	// the stiff equation is advised to the implicit method
+	status, body = post("auto", "-50*y")
+	require.Equal(t, http.StatusOK, status, body)
+	assert.Contains(t, body, "Method: Backward Euler&#39;s method")
+	assert.Contains(t, body, "the problem is stiff")
+
+	status, _ = post("", "-y")
+	assert.Equal(t, http.StatusOK, status, "all default methods are plotted")
+	status, body = post("rk5", "-y")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, body, `unknown method &#34;rk5&#34;`)
+	status, body = post("rk4", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, body, "the method is chosen for the given functions only")
+}
+
+func TestRest_PlotGraphsLimits(t *testing.T) {
+	srv, ts := prepTestServer(t)
+	srv.Limits = solve.Limits{MaxSteps: 100, MaxSweep: 20, MaxWidth: 10}
+	post := func(vals url.Values) (int, string) {
+		form := url.Values{"x0": {"0"}, "y0": {"1"}, "x_end": {"1"}, "n": {"10"}, "nmin": {"10"}, "nmax": {"20"},
+			"fxy": {"-y"}, "yxc": {"c*exp(-x)"}, "c": {"y0*exp(x0)"}}
+		for k, v := range vals {
+			form[k] = v
+		}
+		resp, err := http.PostForm(ts.URL+"/", form)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	for _, tt := range []struct {
+		vals url.Values
+		msg  string
+	}{
+		{url.Values{"n": {"101"}}, "n: must be between 1 and max_steps=100, got 101"},
+		{url.Values{"n": {"0"}}, "n: must be between 1 and max_steps=100, got 0"},
+		{url.Values{"nmin": {"0"}}, "nmin: must be positive, got 0"},
+		{url.Values{"nmax": {"5"}}, "nmax: must not be less than nmin=10, got 5"},
+		{url.Values{"nmax": {"1000"}}, "nmax: must not be more than max_steps=100, got 1000"},
+		{url.Values{"nmax": {"30"}}, "nmax: gives 21 values of n, more than max_sweep=20"},
+		{url.Values{"x_end": {"11"}}, "x_end: gives the interval of width 11, more than max_width=10"},
+	} {
+		status, body := post(tt.vals)
+		assert.Equal(t, http.StatusBadRequest, status, tt.vals)
+		assert.Contains(t, body, tt.msg)
+	}
+
+	status, body := post(nil)
+	assert.Equal(t, http.StatusOK, status, body)
+}
+
+func TestRest_RequestLog(t *testing.T) {
+	var mu sync.Mutex
+	var logged []string
+	srv := &Rest{Version: "test", NumService: &service.Service{}, Store: &store.Memory{}, CacheSize: 10,
+		Logger: log.Func(func(format string, args ...interface{}) {
+			mu.Lock()
+			defer mu.Unlock()
+			logged = append(logged, fmt.Sprintf(format, args...))
+		})}
+	ts := httptest.NewServer(srv.routes())
+	defer ts.Close()
+
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/api/v1/solve", strings.NewReader(
+		`{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["euler", "rk4"]}`))
+	require.NoError(t, err)
+	req.Header.Set("X-Request-Id", "req-42")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "req-42", resp.Header.Get("X-Request-Id"))
+
+	resp, err = http.Get(ts.URL + "/ping")
+	require.NoError(t, err)
+	resp.Body.Close()
+	pingID := resp.Header.Get("X-Request-Id")
+	assert.Len(t, pingID, 32)
+
+	mu.Lock()
+	defer mu.Unlock()
+	var access, solves []string
+	for _, l := range logged {
+		switch {
+		case strings.HasPrefix(l, "[INFO] request_id="):
+			access = append(access, l)
+		case strings.HasPrefix(l, "[DEBUG] request_id=req-42 starting solving"):
+			solves = append(solves, l)
+		}
+	}
+	require.Len(t, access, 2, "single line per request")
+	assert.Regexp(t, `^\[INFO\] request_id=req-42 method=POST path="/api/v1/solve" status=200 bytes=\d+ `+
+		`duration=\S+ ip=127.0.0.1 cache=MISS$`, access[0])
+	assert.Contains(t, access[1], "request_id="+pingID+" method=GET path=\"/ping\" status=200")
+	assert.Len(t, solves, 2, "logs of solvers must be tagged with the id of the request")
+}
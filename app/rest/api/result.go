@@ -0,0 +1,151 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/Semior001/decompract/app/store"
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/pkg/errors"
+)
+
+// resultResp is the saved solve request with its solution
+type resultResp struct {
+	ID        string             `json:"id"`
+	Request   solve.Request      `json:"request"`
+	Result    solve.Response     `json:"result"`
+	Errors    []solve.ErrorTable `json:"errors"` // local errors of methods, empty if the exact solution is unknown
+	CreatedAt string             `json:"created_at"`
+	ExpiresAt string             `json:"expires_at,omitempty"` // empty if the result never expires
+}
+
+type resultTmplData struct {
+	ID         string // empty for the shared problem, which is not saved
+	CreatedAt  string
+	Methods    string
+	ChartQuery template.URL
+	Fields     []resultTmplField
+}
+
+type resultTmplField struct {
+	Name  string
+	Label string
+	Value string
+}
+
+// saveResult saves the request with its solution and sets the id of the result to the response
+func (s *Rest) saveResult(req solve.Request, resp solve.Response) (solve.Response, error) {
+	req.Save = false
+	reqData, err := json.Marshal(req)
+	if err != nil {
+		return solve.Response{}, errors.Wrap(err, "failed to marshal request")
+	}
+	saved := resp
+	saved.Errors = nil // errors of the saved result are calculated on load
+	respData, err := json.Marshal(saved)
+	if err != nil {
+		return solve.Response{}, errors.Wrap(err, "failed to marshal solution")
+	}
+
+	res, err := s.Store.Put(store.Result{Request: reqData, Response: respData})
+	if err != nil {
+		return solve.Response{}, errors.Wrap(err, "failed to save result")
+	}
+	resp.ID = res.ID
+	return resp, nil
+}
+
+// loadResult loads the saved result by its id
+func (s *Rest) loadResult(id string) (resultResp, error) {
+	if s.Store == nil {
+		return resultResp{}, store.ErrNotFound
+	}
+	res, err := s.Store.Get(id)
+	if err != nil {
+		return resultResp{}, err
+	}
+
+	resp := resultResp{ID: res.ID, CreatedAt: res.CreatedAt.UTC().Format(time.RFC3339)}
+	if !res.ExpiresAt.IsZero() {
+		resp.ExpiresAt = res.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	if err = json.Unmarshal(res.Request, &resp.Request); err != nil {
+		return resultResp{}, errors.Wrapf(err, "failed to unmarshal request of %s", id)
+	}
+	if err = json.Unmarshal(res.Response, &resp.Result); err != nil {
+		return resultResp{}, errors.Wrapf(err, "failed to unmarshal solution of %s", id)
+	}
+	resp.Result.ID = res.ID
+	resp.Errors = resp.Result.LocalErrors()
+	return resp, nil
+}
+
+// GET /api/v1/result/{id} - returns the saved request with its solution and errors of methods
+func (s *Rest) resultCtrl(w http.ResponseWriter, r *http.Request) {
+	res, err := s.loadResult(chi.URLParam(r, "id"))
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		rest.SendErrorJSON(w, r, http.StatusNotFound, err, "no such result", rest.ErrNotFound)
+		return
+	case err != nil:
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "failed to load result", rest.ErrInternal)
+		return
+	}
+	rest.RenderJSON(w, r, res)
+}
+
+// GET /result/{id} - renders the form, filled with the saved request
+func (s *Rest) resultPageCtrl(w http.ResponseWriter, r *http.Request) {
+	res, err := s.loadResult(chi.URLParam(r, "id"))
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		rest.SendErrorHTML(w, r, http.StatusNotFound, err, "no such result")
+		return
+	case err != nil:
+		rest.SendErrorHTML(w, r, http.StatusInternalServerError, err, "failed to load result")
+		return
+	}
+
+	req := res.Request
+	buf := &bytes.Buffer{}
+	err = s.Web.Render(buf, "result.html", resultTmplData{
+		ID:         res.ID,
+		CreatedAt:  res.CreatedAt,
+		Methods:    strings.Join(req.Methods, ", "),
+		ChartQuery: template.URL(req.Query()),
+		Fields:     resultTmplFields(req),
+	})
+	if err != nil {
+		rest.SendErrorHTML(w, r, http.StatusInternalServerError, err, "can't execute template")
+		return
+	}
+	render.HTML(w, r, buf.String())
+}
+
+// resultTmplFields makes fields of the form, filled with the request
+func resultTmplFields(req solve.Request) []resultTmplField {
+	n := req.N
+	if n == 0 {
+		n = int(math.Round(req.Steps()))
+	}
+	return []resultTmplField{
+		{Name: "fxy", Label: "f(x,y), also known as y'", Value: req.F},
+		{Name: "yxc", Label: "y(x,c), also known as the exact solution", Value: req.Exact},
+		{Name: "c", Label: "C(x0,y0), constant, which is needed for exact solution", Value: req.C},
+		{Name: "x0", Label: "Initial x (x0)", Value: solve.FormatFloat(req.X0)},
+		{Name: "y0", Label: "Initial y (y0)", Value: solve.FormatFloat(req.Y0)},
+		{Name: "x_end", Label: "Ending x (X)", Value: solve.FormatFloat(req.XEnd)},
+		{Name: "n", Label: "Number of steps (N)", Value: strconv.Itoa(n)},
+		{Name: "nmin", Label: "Nmin", Value: "1"},
+		{Name: "nmax", Label: "Nmax", Value: strconv.Itoa(n)},
+	}
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Semior001/decompract/app/num/service"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/Semior001/decompract/app/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// saveSolve solves the problem with saving and returns the solution
+func saveSolve(t *testing.T, ts *httptest.Server, body string) solve.Response {
+	resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	return res
+}
+
+func TestRest_SaveResult(t *testing.T) {
+	srv := &Rest{Version: "test", NumService: &service.Service{}, CacheSize: 10, Store: &store.Memory{}}
+	ts := httptest.NewServer(srv.routes())
+	defer ts.Close()
+
+	body := `{"f": "y*y*exp(x) - 2*y", "exact": "exp(-x) / (c*exp(x) + 1)", "c": "(exp(-x0) - y0) / (y0 * exp(x0))",
+		"x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4", "euler"], "save": true}`
+	solved := saveSolve(t, ts, body)
+	require.NotEmpty(t, solved.ID)
+
+	// the same problem is saved under the new id, even if it's cached
+	again := saveSolve(t, ts, body)
+	assert.NotEqual(t, solved.ID, again.ID)
+	unsaved := saveSolve(t, ts, strings.Replace(body, `"save": true`, `"save": false`, 1))
+	assert.Empty(t, unsaved.ID, "cached solution must not keep the id")
+
+	resp, err := http.Get(ts.URL + "/api/v1/result/" + solved.ID)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := resultResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+
+	assert.Equal(t, solved.ID, res.ID)
+	assert.Equal(t, "y*y*exp(x) - 2*y", res.Request.F)
+	assert.Equal(t, []string{"rk4", "euler"}, res.Request.Methods)
+	assert.False(t, res.Request.Save)
+	assert.Equal(t, solved, res.Result)
+	assert.NotEmpty(t, res.CreatedAt)
+	assert.Empty(t, res.ExpiresAt)
+
+	require.Len(t, res.Errors, 2)
+	assert.Equal(t, "rk4", res.Errors[0].Method)
+	assert.Equal(t, "euler", res.Errors[1].Method)
+	for i, pt := range res.Errors[1].Points {
+		assert.InDelta(t, math.Abs(res.Result.Lines[1].Points[i].Y-res.Result.Exact.Points[i].Y), pt.Y, 1e-12)
+	}
+	assert.Zero(t, res.Errors[1].Points[0].Y, "initial point is exact")
+	assert.True(t, res.Errors[0].Points[10].Y < res.Errors[1].Points[10].Y, "rk4 must be more accurate than euler")
+
+	// the page of the result is filled with the request
+	resp, err = http.Get(ts.URL + "/result/" + solved.ID)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	b, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	page := string(b)
+	assert.Contains(t, page, `name="fxy" class="element text medium" type="text" maxlength="255" value="y*y*exp(x) - 2*y"`)
+	assert.Contains(t, page, `name="x_end" class="element text medium" type="text" maxlength="255" value="1"`)
+	assert.Contains(t, page, `name="n" class="element text medium" type="text" maxlength="255" value="10"`)
+	assert.Contains(t, page, `src="/api/v1/chart?c=`)
+
+	// the chart of the page is rendered
+	start := strings.Index(page, `src="/api/v1/chart?`) + len(`src="`)
+	chart := strings.ReplaceAll(page[start:start+strings.Index(page[start:], `"`)], "&amp;", "&")
+	resp, err = http.Get(ts.URL + chart)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+}
+
+func TestRest_ResultNotFound(t *testing.T) {
+	srv := &Rest{Version: "test", NumService: &service.Service{},
+		Store: &store.Memory{TTL: 50 * time.Millisecond}}
+	ts := httptest.NewServer(srv.routes())
+	defer ts.Close()
+
+	solved := saveSolve(t, ts, `{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"], "save": true}`)
+
+	resp, err := http.Get(ts.URL + "/api/v1/result/" + solved.ID)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := resultResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.NotEmpty(t, res.ExpiresAt)
+	assert.Empty(t, res.Errors, "no errors without the exact solution")
+
+	time.Sleep(100 * time.Millisecond)
+
+	for _, id := range []string{solved.ID, "unknown"} {
+		resp, err = http.Get(ts.URL + "/api/v1/result/" + id)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode, id)
+		e := rest.ErrorResponse{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&e))
+		assert.Equal(t, rest.ErrNotFound, e.Code)
+
+		resp, err = http.Get(ts.URL + "/result/" + id)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode, id)
+		assert.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+	}
+}
+
+func TestRest_SaveDisabled(t *testing.T) {
+	srv, ts := prepTestServer(t)
+	srv.Store = nil
+
+	resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json",
+		strings.NewReader(`{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"], "save": true}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Get(ts.URL + "/api/v1/result/abc")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/Semior001/decompract/app/store"
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+)
+
+// maxRunsList is the number of runs, listed by default and at most
+const maxRunsList = 100
+
+// runResp is the recorded solve request of the session with the summary of its solution
+type runResp struct {
+	ID        string        `json:"id"`
+	Request   solve.Request `json:"request"`
+	Summary   runSummary    `json:"summary"`
+	Took      string        `json:"took"` // total duration of solving
+	CreatedAt string        `json:"created_at"`
+}
+
+// runsResp lists runs of the session, from the newest to the oldest
+type runsResp struct {
+	Runs []runResp `json:"runs"`
+}
+
+// runSummary summarizes the solution of the recorded run
+type runSummary struct {
+	Step  float64   `json:"step"`
+	Lines []runLine `json:"lines"`
+}
+
+// runLine summarizes the solution by the method, errors are set, if the exact solution is known
+type runLine struct {
+	Method   string     `json:"method"`
+	Name     string     `json:"name"`
+	Points   int        `json:"points"`
+	Evals    int        `json:"evals"`
+	Final    *num.Point `json:"final,omitempty"`
+	MaxError *float64   `json:"max_error,omitempty"` // max local error against the exact solution
+	RMSError *float64   `json:"rms_error,omitempty"` // root mean square of local errors
+	Error    string     `json:"error,omitempty"`     // describes why the method failed
+	Took     string     `json:"took"`
+}
+
+// makeRun makes the record of the solved request, save and warm are dropped, so the request
+// solves the whole problem on its own, when it is run again
+func makeRun(session string, req solve.Request, resp solve.Response) (store.Run, error) {
+	req.Save, req.Warm = false, false
+	reqData, err := json.Marshal(req)
+	if err != nil {
+		return store.Run{}, errors.Wrap(err, "failed to marshal request")
+	}
+
+	errs := map[string]solve.ErrorTable{}
+	for _, et := range resp.LocalErrors() {
+		errs[et.Method] = et
+	}
+	summary := runSummary{Step: resp.Step, Lines: []runLine{}}
+	lines := resp.Lines
+	if resp.Exact != nil {
+		lines = append(append([]solve.Line{}, lines...), *resp.Exact)
+	}
+	for _, line := range lines {
+		rl := runLine{Method: line.Method, Name: line.Name, Took: line.Took}
+		if line.Error != nil {
+			rl.Error = line.Error.Error
+		}
+		if st := line.Stats; st != nil {
+			rl.Points, rl.Evals, rl.Final = st.Points, st.Evals, st.Final
+		}
+		if et, ok := errs[line.Method]; ok && len(et.Points) > 0 {
+			max, sumSq := 0.0, 0.0
+			for _, pt := range et.Points {
+				max, sumSq = math.Max(max, pt.Y), sumSq+pt.Y*pt.Y
+			}
+			rms := math.Sqrt(sumSq / float64(len(et.Points)))
+			rl.MaxError, rl.RMSError = &max, &rms
+		}
+		summary.Lines = append(summary.Lines, rl)
+	}
+	sumData, err := json.Marshal(summary)
+	if err != nil {
+		return store.Run{}, errors.Wrap(err, "failed to marshal summary")
+	}
+
+	took, _ := time.ParseDuration(resp.Took)
+	return store.Run{Session: session, Request: reqData, Summary: sumData, Took: took}, nil
+}
+
+// runResponse makes the response of the recorded run
+func runResponse(r store.Run) (runResp, error) {
+	res := runResp{ID: r.ID, Took: r.Took.String(), CreatedAt: r.CreatedAt.UTC().Format(time.RFC3339)}
+	if err := json.Unmarshal(r.Request, &res.Request); err != nil {
+		return runResp{}, errors.Wrapf(err, "failed to unmarshal request of %s", r.ID)
+	}
+	if err := json.Unmarshal(r.Summary, &res.Summary); err != nil {
+		return runResp{}, errors.Wrapf(err, "failed to unmarshal summary of %s", r.ID)
+	}
+	return res, nil
+}
+
+// loadRun loads the recorded run by its id
+func (s *Rest) loadRun(id string) (runResp, error) {
+	if s.Runs == nil {
+		return runResp{}, store.ErrNotFound
+	}
+	r, err := s.Runs.Run(id)
+	if err != nil {
+		return runResp{}, err
+	}
+	return runResponse(r)
+}
+
+// GET /api/v1/runs?limit=20 - returns recorded solve requests of the session with summaries of solutions,
+// from the newest to the oldest, up to 100
+func (s *Rest) runsCtrl(w http.ResponseWriter, r *http.Request) {
+	limit := maxRunsList
+	if v := r.URL.Query().Get("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 || limit > maxRunsList {
+			rest.SendErrorJSON(w, r, http.StatusBadRequest, errors.Errorf("limit must be between 1 and %d, got %q", maxRunsList, v),
+				"failed to read query parameters", rest.ErrDecode)
+			return
+		}
+	}
+
+	res := runsResp{Runs: []runResp{}}
+	if s.Runs == nil {
+		rest.RenderJSON(w, r, res)
+		return
+	}
+	runs, err := s.Runs.Runs(rest.SessionID(r), limit)
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "failed to load runs", rest.ErrInternal)
+		return
+	}
+	for _, run := range runs {
+		rr, err := runResponse(run)
+		if err != nil {
+			rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "failed to load runs", rest.ErrInternal)
+			return
+		}
+		res.Runs = append(res.Runs, rr)
+	}
+	rest.RenderJSON(w, r, res)
+}
+
+// GET /api/v1/runs/{id} - returns the recorded solve request with the summary of its solution
+func (s *Rest) runCtrl(w http.ResponseWriter, r *http.Request) {
+	res, err := s.loadRun(chi.URLParam(r, "id"))
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		rest.SendErrorJSON(w, r, http.StatusNotFound, err, "no such run", rest.ErrNotFound)
+		return
+	case err != nil:
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "failed to load run", rest.ErrInternal)
+		return
+	}
+	rest.RenderJSON(w, r, res)
+}
+
+// POST /api/v1/runs/{id}/rerun - solves the recorded request once again, as POST /api/v1/solve does,
+// the new run is recorded to the history of the session
+func (s *Rest) rerunCtrl(w http.ResponseWriter, r *http.Request) {
+	run, err := s.loadRun(chi.URLParam(r, "id"))
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		rest.SendErrorJSON(w, r, http.StatusNotFound, err, "no such run", rest.ErrNotFound)
+		return
+	case err != nil:
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "failed to load run", rest.ErrInternal)
+		return
+	}
+
+	resp, ok := s.solveRequest(w, r, run.Request)
+	if !ok {
+		return
+	}
+	s.record(r, run.Request, resp)
+	rest.RenderJSON(w, r, resp)
+}
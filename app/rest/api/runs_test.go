@@ -0,0 +1,130 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/Semior001/decompract/app/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRest_Runs(t *testing.T) {
+	srv, ts := prepTestServer(t)
+	srv.SessionSecret = "secret"
+	srv.Runs = &store.MemoryRuns{}
+	ts.Config.Handler = srv.routes()
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	cl := &http.Client{Jar: jar}
+	getJSON := func(cl *http.Client, path string, code int, v interface{}) {
+		resp, err := cl.Get(ts.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, code, resp.StatusCode)
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	}
+	listRuns := func(cl *http.Client) []runResp {
+		res := runsResp{}
+		getJSON(cl, "/api/v1/runs", http.StatusOK, &res)
+		return res.Runs
+	}
+
+	assert.Empty(t, listRuns(cl), "new session has no runs")
+
+	resp, err := cl.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(`{"f": "x^2-2*y",
+		"exact": "c*exp(-2*x) + x^2/2 - x/2 + 1/4", "c": "(y0 - x0^2/2 + x0/2 - 1/4)*exp(2*x0)",
+		"x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["euler", "rk4"], "save": true}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp, err = cl.Get(ts.URL + "/api/v1/solve?f=x&x0=0&y0=2&x1=1&n=4&method=euler")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	var runs []runResp
+	require.Eventually(t, func() bool {
+		runs = listRuns(cl)
+		return len(runs) == 2
+	}, time.Second, 10*time.Millisecond, "runs must be recorded in background")
+
+	assert.Equal(t, float64(2), runs[0].Request.Y0, "runs must be from the newest to the oldest")
+	assert.Nil(t, runs[0].Summary.Lines[0].MaxError, "errors are unknown without the exact solution")
+
+	run := runs[1]
+	assert.False(t, run.Request.Save, "the run must not be saved once again")
+	assert.Equal(t, 0.1, run.Summary.Step)
+	assert.NotEmpty(t, run.Took)
+	assert.NotEmpty(t, run.CreatedAt)
+	require.Len(t, run.Summary.Lines, 3)
+	for i, m := range []string{"euler", "rk4", solve.ExactMethod} {
+		assert.Equal(t, m, run.Summary.Lines[i].Method)
+		assert.Equal(t, 11, run.Summary.Lines[i].Points)
+		assert.NotNil(t, run.Summary.Lines[i].Final)
+	}
+	require.NotNil(t, run.Summary.Lines[0].MaxError)
+	require.NotNil(t, run.Summary.Lines[1].MaxError)
+	assert.Greater(t, *run.Summary.Lines[0].MaxError, *run.Summary.Lines[1].MaxError)
+	assert.LessOrEqual(t, *run.Summary.Lines[0].RMSError, *run.Summary.Lines[0].MaxError)
+
+	loaded := runResp{}
+	getJSON(cl, "/api/v1/runs/"+run.ID, http.StatusOK, &loaded)
+	assert.Equal(t, run, loaded)
+
+	// the run is solved once again and recorded as the newest one
+	resp, err = cl.Post(ts.URL+"/api/v1/runs/"+run.ID+"/rerun", "application/json", nil)
+	require.NoError(t, err)
+	rerun := solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&rerun))
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, rerun.Lines, 2)
+	assert.Empty(t, rerun.ID, "the rerun is not saved")
+	require.Eventually(t, func() bool {
+		runs = listRuns(cl)
+		return len(runs) == 3
+	}, time.Second, 10*time.Millisecond)
+	require.Len(t, runs[0].Summary.Lines, 3)
+	for i, line := range run.Summary.Lines {
+		assert.Equal(t, line.Method, runs[0].Summary.Lines[i].Method)
+		assert.Equal(t, line.Final, runs[0].Summary.Lines[i].Final, "the rerun must give the same solution")
+	}
+
+	limited := runsResp{}
+	getJSON(cl, "/api/v1/runs?limit=1", http.StatusOK, &limited)
+	assert.Len(t, limited.Runs, 1)
+
+	// runs of the other session are separate
+	other := &http.Client{}
+	assert.Empty(t, listRuns(other))
+
+	getJSON(cl, "/api/v1/runs/unknown", http.StatusNotFound, &struct{}{})
+	getJSON(cl, "/api/v1/runs?limit=0", http.StatusBadRequest, &struct{}{})
+	resp, err = cl.Post(ts.URL+"/api/v1/runs/unknown/rerun", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestRest_RunsDisabled(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/api/v1/runs")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := runsResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.Empty(t, res.Runs)
+
+	resp, err = http.Get(ts.URL + "/api/v1/runs/unknown")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,271 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	log "github.com/go-pkgz/lgr"
+	"github.com/pkg/errors"
+)
+
+// limits of comparison sets
+const (
+	maxSets        = 20  // sets of the session, the new set beyond the limit is rejected
+	maxSetEntries  = 10  // solved problems in the set
+	maxSetLabelLen = 100 // length of the label of the problem in the set
+)
+
+// setName is the name of the comparison set in the path
+var setName = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// setAddReq adds the solved problem to the comparison set under the label, its lines are labeled
+// as "label: method", so the same method is told apart across problems, if the label is not set,
+// it is made of f and the interval
+type setAddReq struct {
+	Label   string        `json:"label,omitempty"`
+	Request solve.Request `json:"request"`
+}
+
+// setEntry is the problem, solved and added to the comparison set
+type setEntry struct {
+	Label   string    `json:"label"`
+	F       string    `json:"f"`
+	X0      float64   `json:"x0"`
+	Y0      float64   `json:"y0"`
+	XEnd    float64   `json:"x_end"`
+	Step    float64   `json:"step"` // the step, the problem is solved with
+	Lines   []setLine `json:"lines"`
+	AddedAt string    `json:"added_at"`
+}
+
+// setLine is the solution of the problem by the method, the label is distinct across the set
+type setLine struct {
+	Label  string      `json:"label"`
+	Method string      `json:"method"`
+	Points []num.Point `json:"points"`
+}
+
+// setResp is the comparison set, entries are in the order, they are added in
+type setResp struct {
+	Name    string     `json:"name"`
+	Entries []setEntry `json:"entries"`
+}
+
+// compSets keeps comparison sets of each session by their names, the set lives as long as its session
+type compSets struct {
+	lock     sync.Mutex
+	sessions *rest.LRU // session id -> map[string][]setEntry
+}
+
+func newCompSets() *compSets {
+	return &compSets{sessions: &rest.LRU{MaxEntries: maxSessions, TTL: sessionMaxAge}}
+}
+
+// add appends the entry to the set of the session, the default label of the entry gets the number,
+// if the problem with the same label is in the set already, the explicit one is rejected then
+func (cs *compSets) add(session, name string, entry setEntry, explicit bool) (setResp, error) {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	sets := map[string][]setEntry{}
+	if v, ok := cs.sessions.Get(session); ok {
+		sets = v.(map[string][]setEntry)
+	}
+	entries, ok := sets[name]
+	switch {
+	case !ok && len(sets) >= maxSets:
+		return setResp{}, errors.Errorf("the session has %d sets already, delete some of them", maxSets)
+	case len(entries) >= maxSetEntries:
+		return setResp{}, errors.Errorf("set %s has %d problems already", name, maxSetEntries)
+	}
+
+	label := entry.Label
+	for k := 2; hasLabel(entries, label); k++ {
+		if explicit {
+			return setResp{}, rest.ValidationError{{Field: "label", Msg: fmt.Sprintf("%q is in the set already", label)}}
+		}
+		label = fmt.Sprintf("%s #%d", entry.Label, k)
+	}
+	entry.Label = label
+	entry.Lines = append([]setLine{}, entry.Lines...)
+	for i := range entry.Lines {
+		entry.Lines[i].Label = label + ": " + entry.Lines[i].Label
+	}
+
+	sets[name] = append(entries, entry)
+	cs.sessions.Put(session, sets)
+	return setResp{Name: name, Entries: append([]setEntry{}, sets[name]...)}, nil
+}
+
+// get returns the set of the session, the unknown set is empty
+func (cs *compSets) get(session, name string) setResp {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+	resp := setResp{Name: name, Entries: []setEntry{}}
+	if v, ok := cs.sessions.Get(session); ok {
+		resp.Entries = append(resp.Entries, v.(map[string][]setEntry)[name]...)
+	}
+	return resp
+}
+
+// remove deletes the set of the session
+func (cs *compSets) remove(session, name string) {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+	if v, ok := cs.sessions.Get(session); ok {
+		delete(v.(map[string][]setEntry), name)
+	}
+}
+
+func hasLabel(entries []setEntry, label string) bool {
+	for _, e := range entries {
+		if e.Label == label {
+			return true
+		}
+	}
+	return false
+}
+
+// lines returns lines of all problems of the set with their labels as names, so they are drawn on one chart
+func (resp setResp) lines() []num.Line {
+	var res []num.Line
+	for _, e := range resp.Entries {
+		for _, line := range e.Lines {
+			res = append(res, num.Line{Name: line.Label, Points: line.Points})
+		}
+	}
+	return res
+}
+
+// readSetName reads and validates the name of the set from the path
+func readSetName(w http.ResponseWriter, r *http.Request) (string, bool) {
+	name := chi.URLParam(r, "name")
+	if !setName.MatchString(name) {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, errors.Errorf("invalid set name %q", name),
+			"name must have from 1 to 64 letters, digits, dots, dashes or underscores", rest.ErrBadRequest)
+		return "", false
+	}
+	return name, true
+}
+
+// POST /api/v1/sets/{name} - solves the request, the same as POST /api/v1/solve, and adds its lines to the named
+// comparison set of the session, so the same method is compared across different equations or intervals,
+// it responds with the whole set
+func (s *Rest) addToSetCtrl(w http.ResponseWriter, r *http.Request) {
+	name, ok := readSetName(w, r)
+	if !ok {
+		return
+	}
+
+	add := setAddReq{}
+	if err := render.DecodeJSON(r.Body, &add); err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "failed to decode request", rest.ErrDecode)
+		return
+	}
+	add.Label = strings.TrimSpace(add.Label)
+	if len(add.Label) > maxSetLabelLen {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, rest.ValidationError{{Field: "label",
+			Msg: fmt.Sprintf("must be at most %d bytes, got %d", maxSetLabelLen, len(add.Label))}},
+			"invalid set request", rest.ErrBadRequest)
+		return
+	}
+
+	req, err := add.Request.WithPreset()
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid solve request", rest.ErrBadRequest)
+		return
+	}
+	if req.Save {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, errors.New("results of sets can't be saved"),
+			"invalid solve request", rest.ErrBadRequest)
+		return
+	}
+
+	resp, ok := s.solveRequest(w, r, req)
+	if !ok {
+		return
+	}
+	s.record(r, req, resp)
+
+	entry := setEntry{Label: add.Label, F: req.F, X0: req.X0, Y0: req.Y0, XEnd: req.XEnd, Step: resp.Step,
+		AddedAt: time.Now().UTC().Format(time.RFC3339)}
+	if req.Linear != nil && strings.TrimSpace(req.F) == "" {
+		entry.F = req.Linear.F()
+	}
+	if entry.Label == "" {
+		entry.Label = fmt.Sprintf("%s on [%s, %s]", entry.F, solve.FormatFloat(req.X0), solve.FormatFloat(req.XEnd))
+	}
+	// failed methods have no points to compare
+	for _, line := range resp.Lines {
+		if line.Error == nil {
+			entry.Lines = append(entry.Lines, setLine{Label: line.Name, Method: line.Method, Points: line.Points})
+		}
+	}
+	if resp.Exact != nil {
+		entry.Lines = append(entry.Lines, setLine{Label: resp.Exact.Name, Method: resp.Exact.Method,
+			Points: resp.Exact.Points})
+	}
+
+	set, err := s.sets.add(rest.SessionID(r), name, entry, add.Label != "")
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "can't add the problem to the set", rest.ErrBadRequest)
+		return
+	}
+	rest.RenderJSON(w, r, set)
+}
+
+// GET /api/v1/sets/{name}?format=json|png|svg&width=800&height=600 - returns the comparison set of the session,
+// png and svg formats render lines of all its problems overlaid on one chart, the unknown set is empty
+func (s *Rest) setCtrl(w http.ResponseWriter, r *http.Request) {
+	name, ok := readSetName(w, r)
+	if !ok {
+		return
+	}
+	set := s.sets.get(rest.SessionID(r), name)
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		rest.RenderJSON(w, r, set)
+	case "png", "svg":
+		img, err := readChartImage(r)
+		if err != nil {
+			rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid chart parameters", rest.ErrBadRequest)
+			return
+		}
+		b, err := s.NumService.Plotter.PlotImage("Set "+name, "X", "Y", set.lines(), img)
+		if err != nil {
+			rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "can't plot chart", rest.ErrInternal)
+			return
+		}
+		ct := "image/png"
+		if img.Format == "svg" {
+			ct = "image/svg+xml"
+		}
+		w.Header().Set("Content-Type", ct)
+		if _, err = w.Write(b); err != nil {
+			log.Printf("[WARN] failed to write chart, %v", err)
+		}
+	default:
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, errors.Errorf("unknown format %q", format),
+			"format must be json, png or svg", rest.ErrBadRequest)
+	}
+}
+
+// DELETE /api/v1/sets/{name} - deletes the comparison set of the session
+func (s *Rest) deleteSetCtrl(w http.ResponseWriter, r *http.Request) {
+	name, ok := readSetName(w, r)
+	if !ok {
+		return
+	}
+	s.sets.remove(rest.SessionID(r), name)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRest_Sets(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	newClient := func() *http.Client {
+		jar, err := cookiejar.New(nil)
+		require.NoError(t, err)
+		return &http.Client{Jar: jar}
+	}
+	add := func(cl *http.Client, name, body string) (int, setResp) {
+		resp, err := cl.Post(ts.URL+"/api/v1/sets/"+name, "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		set := setResp{}
+		if resp.StatusCode == http.StatusOK {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&set))
+		}
+		return resp.StatusCode, set
+	}
+	get := func(cl *http.Client, name string) setResp {
+		resp, err := cl.Get(ts.URL + "/api/v1/sets/" + name)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		set := setResp{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&set))
+		return set
+	}
+
+	cl := newClient()
+	assert.Equal(t, setResp{Name: "rk4", Entries: []setEntry{}}, get(cl, "rk4"), "the unknown set is empty")
+
+	// the same method on two equations and on two intervals of the same equation
+	status, _ := add(cl, "rk4", `{"label": "growth", "request": {"f": "y", "exact": "c*exp(x)", "c": "y0/exp(x0)",
+		"x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"]}}`)
+	require.Equal(t, http.StatusOK, status)
+	status, _ = add(cl, "rk4", `{"request": {"f": "x^2 - 2*y", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"]}}`)
+	require.Equal(t, http.StatusOK, status)
+	status, set := add(cl, "rk4", `{"request": {"f": "x^2 - 2*y", "x0": 0, "y0": 1, "x_end": 2, "n": 20, "methods": ["rk4"]}}`)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "rk4", set.Name)
+	require.Len(t, set.Entries, 3)
+	assert.Equal(t, "growth", set.Entries[0].Label)
+	assert.Equal(t, "x^2 - 2*y on [0, 1]", set.Entries[1].Label)
+	assert.Equal(t, "x^2 - 2*y on [0, 2]", set.Entries[2].Label)
+	assert.Equal(t, 0.1, set.Entries[2].Step)
+
+	var labels []string
+	for _, e := range set.Entries {
+		for _, line := range e.Lines {
+			labels = append(labels, line.Label)
+			assert.Len(t, line.Points, int(e.XEnd*10)+1, line.Label)
+		}
+	}
+	assert.Equal(t, []string{"growth: Runge-Kutta's method", "growth: Exact solution",
+		"x^2 - 2*y on [0, 1]: Runge-Kutta's method", "x^2 - 2*y on [0, 2]: Runge-Kutta's method"}, labels,
+		"lines of the same method are told apart by labels")
+	assert.Equal(t, "rk4", set.Entries[0].Lines[0].Method)
+
+	// the same problem once again gets the numbered default label, the explicit one must be unique
+	status, set = add(cl, "rk4", `{"request": {"f": "x^2 - 2*y", "x0": 0, "y0": 1, "x_end": 1, "n": 5, "methods": ["rk4"]}}`)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "x^2 - 2*y on [0, 1] #2", set.Entries[3].Label)
+	status, _ = add(cl, "rk4", `{"label": "growth", "request": {"f": "y", "x0": 0, "y0": 1, "x_end": 1, "n": 5,
+		"methods": ["rk4"]}}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Len(t, get(cl, "rk4").Entries, 4)
+
+	// the chart overlays all lines of the set
+	for _, format := range []string{"png", "svg"} {
+		resp, err := cl.Get(ts.URL + "/api/v1/sets/rk4?format=" + format + "&width=400&height=300")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, resp.Header.Get("Content-Type"), format)
+	}
+
+	// sets belong to the session
+	assert.Empty(t, get(newClient(), "rk4").Entries)
+
+	// the invalid problem and the invalid name are rejected, the set is not changed
+	status, _ = add(cl, "rk4", `{"request": {"f": "x^", "x0": 0, "y0": 1, "x_end": 1, "n": 5, "methods": ["rk4"]}}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = add(cl, "rk4", `{"request": {"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 5, "methods": ["rk4"], "save": true}}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = add(cl, "r%20k", `{"request": {"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 5, "methods": ["rk4"]}}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Len(t, get(cl, "rk4").Entries, 4)
+
+	req, err := http.NewRequest(http.MethodDelete, ts.URL+"/api/v1/sets/rk4", nil)
+	require.NoError(t, err)
+	resp, err := cl.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Empty(t, get(cl, "rk4").Entries)
+}
+
+func TestCompSets_Limits(t *testing.T) {
+	cs := newCompSets()
+	entry := setEntry{Label: "p", Lines: []setLine{{Label: "Euler"}}}
+	for i := 0; i < maxSetEntries; i++ {
+		set, err := cs.add("s", "full", entry, false)
+		require.NoError(t, err)
+		assert.Equal(t, "Euler", entry.Lines[0].Label, "labels of lines are set on the copy")
+		assert.Len(t, set.Entries, i+1)
+	}
+	_, err := cs.add("s", "full", entry, false)
+	assert.EqualError(t, err, "set full has 10 problems already")
+
+	for i := 1; i < maxSets; i++ {
+		_, err = cs.add("s", string(rune('a'+i)), entry, false)
+		require.NoError(t, err)
+	}
+	_, err = cs.add("s", "one-more", entry, false)
+	assert.EqualError(t, err, "the session has 20 sets already, delete some of them")
+	_, err = cs.add("other", "one-more", entry, false)
+	assert.NoError(t, err, "limits are per session")
+
+	cs.remove("s", "full")
+	_, err = cs.add("s", "one-more", entry, false)
+	assert.NoError(t, err)
+}
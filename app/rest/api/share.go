@@ -0,0 +1,95 @@
+package api
+
+import (
+	"bytes"
+	"html/template"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/Semior001/decompract/app/num/permalink"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/pkg/errors"
+)
+
+// shareResp is the permalink of the problem
+type shareResp struct {
+	Token string `json:"token"`
+	URL   string `json:"url"` // path of the page of the problem, /s/{token}
+}
+
+// permalinks returns the codec of permalinks, tokens are signed with the key of sessions,
+// so they don't survive restarts, unless the key is set
+func (s *Rest) permalinks() permalink.Codec {
+	return permalink.Codec{Secret: s.secret}
+}
+
+// sharedProblem converts the request to the shared problem, the number of steps is derived from the step,
+// if n is not set, the request must be prepared before, so the problem is valid
+func sharedProblem(req solve.Request) permalink.Problem {
+	return permalink.Problem{F: req.F, Exact: req.Exact, C: req.C, Params: req.Params, X0: req.X0, Y0: req.Y0,
+		XEnd: req.XEnd, N: int(math.Round(req.Steps())), Methods: req.Methods}
+}
+
+// sharedRequest converts the shared problem back to the request
+func sharedRequest(p permalink.Problem) solve.Request {
+	return solve.Request{F: p.F, Exact: p.Exact, C: p.C, Params: p.Params, X0: p.X0, Y0: p.Y0, XEnd: p.XEnd, N: p.N,
+		Methods: p.Methods}
+}
+
+// POST /api/v1/share - validates the problem and encodes it into the token of the permalink GET /s/{token},
+// the problem is not stored, only f, exact, c, params, the interval, the initial value, n and methods are kept
+func (s *Rest) shareCtrl(w http.ResponseWriter, r *http.Request) {
+	req := solve.Request{}
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "failed to decode request", rest.ErrDecode)
+		return
+	}
+
+	req, err := req.WithPreset()
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid solve request", rest.ErrBadRequest)
+		return
+	}
+	if req.Linear != nil || req.CBracket != nil || len(req.NByMethod) > 0 {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, errors.New("linear, c_bracket and n_by_method can't be shared"),
+			"invalid solve request", rest.ErrBadRequest)
+		return
+	}
+	if _, err = req.Prepare(s.limits()); err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid solve request", rest.ErrBadRequest)
+		return
+	}
+
+	tok, err := s.permalinks().Encode(sharedProblem(req))
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "failed to share problem", rest.ErrBadRequest)
+		return
+	}
+	rest.RenderJSON(w, r, shareResp{Token: tok, URL: "/s/" + tok})
+}
+
+// GET /s/{token} - renders the form, filled with the shared problem, along with its chart
+func (s *Rest) sharedPageCtrl(w http.ResponseWriter, r *http.Request) {
+	p, err := s.permalinks().Decode(chi.URLParam(r, "token"))
+	if err != nil {
+		rest.SendErrorHTML(w, r, http.StatusNotFound, err, "no such problem")
+		return
+	}
+
+	req := sharedRequest(p)
+	buf := &bytes.Buffer{}
+	err = s.Web.Render(buf, "result.html", resultTmplData{
+		Methods:    strings.Join(req.Methods, ", "),
+		ChartQuery: template.URL(req.Query()),
+		Fields:     resultTmplFields(req),
+	})
+	if err != nil {
+		rest.SendErrorHTML(w, r, http.StatusInternalServerError, err, "can't execute template")
+		return
+	}
+	render.HTML(w, r, buf.String())
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num/permalink"
+	"github.com/Semior001/decompract/app/num/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// share shares the problem and returns the response with its status
+func share(t *testing.T, ts *httptest.Server, body string) (shareResp, int) {
+	resp, err := http.Post(ts.URL+"/api/v1/share", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	res := shareResp{}
+	if resp.StatusCode == http.StatusOK {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	}
+	return res, resp.StatusCode
+}
+
+func TestRest_Share(t *testing.T) {
+	srv := &Rest{Version: "test", NumService: &service.Service{}, SessionSecret: "secret"}
+	ts := httptest.NewServer(srv.routes())
+	defer ts.Close()
+
+	shared, code := share(t, ts, `{"f": "k*x^2-2*y", "exact": "c*exp(-2*x) + k*(x^2/2 - x/2 + 1/4)",
+		"c": "(y0 - k*(x0^2/2 - x0/2 + 1/4))*exp(2*x0)", "params": {"k": 1}, "x0": 0, "y0": 1, "x_end": 2,
+		"step": 0.25, "methods": ["euler", "rk4"], "save": true}`)
+	require.Equal(t, http.StatusOK, code)
+	require.NotEmpty(t, shared.Token)
+	assert.Equal(t, "/s/"+shared.Token, shared.URL)
+
+	p, err := srv.permalinks().Decode(shared.Token)
+	require.NoError(t, err)
+	assert.Equal(t, 8, p.N, "n must be derived from the step")
+	assert.Equal(t, map[string]float64{"k": 1}, p.Params)
+
+	resp, err := http.Get(ts.URL + shared.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	b, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	page := string(b)
+	assert.Contains(t, page, "Shared problem")
+	assert.Contains(t, page, "Solved with euler, rk4")
+	assert.Contains(t, page, `name="fxy" class="element text medium" type="text" maxlength="255" value="k*x^2-2*y"`)
+	assert.Contains(t, page, `name="x_end" class="element text medium" type="text" maxlength="255" value="2"`)
+	assert.Contains(t, page, `name="n" class="element text medium" type="text" maxlength="255" value="8"`)
+
+	// the chart of the page is rendered
+	start := strings.Index(page, `src="/api/v1/chart?`) + len(`src="`)
+	chart := strings.ReplaceAll(page[start:start+strings.Index(page[start:], `"`)], "&amp;", "&")
+	assert.Contains(t, chart, "param=k%3A1")
+	resp, err = http.Get(ts.URL + chart)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+
+	// presets are resolved, the same problem gives the same token
+	preset, code := share(t, ts, `{"preset": "logistic", "n": 20, "methods": ["rk4"]}`)
+	require.Equal(t, http.StatusOK, code)
+	again, _ := share(t, ts, `{"preset": "logistic", "n": 20, "methods": ["rk4"]}`)
+	assert.Equal(t, preset.Token, again.Token)
+	p, err = srv.permalinks().Decode(preset.Token)
+	require.NoError(t, err)
+	assert.NotEmpty(t, p.F)
+}
+
+func TestRest_ShareInvalid(t *testing.T) {
+	srv := &Rest{Version: "test", NumService: &service.Service{}, SessionSecret: "secret"}
+	ts := httptest.NewServer(srv.routes())
+	defer ts.Close()
+
+	for _, body := range []string{
+		`{"f": "x^2-", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"]}`,
+		`{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk5"]}`,
+		`{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n_by_method": {"rk4": 10}, "methods": ["rk4"]}`,
+		`{"linear": {"p": "2", "q": "x^2"}, "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"]}`,
+		`{"preset": "nope", "n": 10, "methods": ["rk4"]}`,
+		`{"f": "x", `,
+	} {
+		_, code := share(t, ts, body)
+		assert.Equal(t, http.StatusBadRequest, code, body)
+	}
+
+	// tokens of the other secret and malformed ones are not found
+	other, err := permalink.Codec{Secret: []byte("other")}.Encode(permalink.Problem{F: "x", X0: 0, Y0: 1, XEnd: 1,
+		N: 10, Methods: []string{"rk4"}})
+	require.NoError(t, err)
+	for _, tok := range []string{other, "abc"} {
+		resp, err := http.Get(ts.URL + "/s/" + tok)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode, tok)
+	}
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/go-pkgz/lgr"
+)
+
+// defaultDrainTimeout is the default duration to wait for the requests in flight on shutdown
+const defaultDrainTimeout = 10 * time.Second
+
+// drainGrace is the duration, given to the cancelled requests to respond
+var drainGrace = time.Second
+
+// shutdownMsg is the reason of closing of streams and websocket connections on shutdown
+const shutdownMsg = "server is shutting down"
+
+// drainer tracks requests in flight, so they can be drained on shutdown and cancelled at its deadline
+type drainer struct {
+	mu       sync.Mutex
+	active   map[int]context.CancelFunc
+	next     int
+	closing  chan struct{} // closed as soon as the shutdown is started
+	idle     chan struct{} // closed, when there are no requests in flight after the shutdown is started
+	canceled bool
+
+	drained, cancelled int // requests, finished after the shutdown is started
+}
+
+func newDrainer() *drainer {
+	return &drainer{active: map[int]context.CancelFunc{}, closing: make(chan struct{})}
+}
+
+// middleware makes the context of each request cancellable by the drainer
+func (d *drainer) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithCancel(r.Context())
+		id := d.add(cancel)
+		defer d.remove(id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (d *drainer) add(cancel context.CancelFunc) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.next++
+	d.active[d.next] = cancel
+	if d.canceled {
+		cancel()
+	}
+	return d.next
+}
+
+func (d *drainer) remove(id int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.active[id]()
+	delete(d.active, id)
+	if d.idle == nil {
+		return
+	}
+	if d.canceled {
+		d.cancelled++
+	} else {
+		d.drained++
+	}
+	if len(d.active) == 0 {
+		close(d.idle)
+		d.idle = make(chan struct{}) // requests, that are started on the closing connections, are waited as well
+	}
+}
+
+// close starts the shutdown
+func (d *drainer) close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.idle != nil {
+		return
+	}
+	close(d.closing)
+	d.idle = make(chan struct{})
+	if len(d.active) == 0 {
+		close(d.idle)
+	}
+}
+
+// shuttingDown checks whether the shutdown is started
+func (d *drainer) shuttingDown() bool {
+	select {
+	case <-d.closing:
+		return true
+	default:
+		return false
+	}
+}
+
+// cancel cancels all requests in flight and the ones, that are started after
+func (d *drainer) cancel() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.canceled = true
+	for _, cancel := range d.active {
+		cancel()
+	}
+}
+
+// wait waits until there are no requests in flight, it returns false, if the context is done earlier
+func (d *drainer) wait(ctx context.Context) bool {
+	for {
+		d.mu.Lock()
+		idle, empty := d.idle, len(d.active) == 0
+		d.mu.Unlock()
+		if empty {
+			return true
+		}
+		select {
+		case <-idle:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// stats returns the number of drained and cancelled requests
+func (d *drainer) stats() (drained, cancelled int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.drained, d.cancelled
+}
+
+// drainTimeout returns the duration to wait for the requests in flight on shutdown
+func (s *Rest) drainTimeout() time.Duration {
+	if s.DrainTimeout <= 0 {
+		return defaultDrainTimeout
+	}
+	return s.DrainTimeout
+}
+
+// shutdown stops accepting new requests and waits for the ones in flight until the drain timeout,
+// then the rest of requests are cancelled
+func (s *Rest) shutdown() {
+	log.Printf("[INFO] shutting down, waiting for requests in flight up to %s", s.drainTimeout())
+	s.drain.close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.drainTimeout())
+	defer cancel()
+
+	// grpc calls in flight are waited by the drainer along with http requests
+	if s.grpcServer != nil {
+		go s.grpcServer.GracefulStop()
+	}
+	// the server doesn't wait for websocket connections, so they are waited by the drainer
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		log.Printf("[DEBUG] failed to wait for connections, %v", err)
+	}
+	if !s.drain.wait(ctx) {
+		log.Printf("[WARN] drain timeout exceeded, cancelling requests in flight")
+		s.drain.cancel()
+		gctx, gcancel := context.WithTimeout(context.Background(), drainGrace)
+		defer gcancel()
+		s.drain.wait(gctx)
+	}
+	if err := s.httpServer.Close(); err != nil {
+		log.Printf("[WARN] failed to close server, %v", err)
+	}
+
+	drained, cancelled := s.drain.stats()
+	log.Printf("[INFO] server is stopped, %d requests drained, %d cancelled", drained, cancelled)
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Semior001/decompract/app/num/service"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startServer serves the api on the random port until the context is done,
+// the returned channel is closed, when the server is stopped
+func startServer(ctx context.Context, t *testing.T, srv *Rest) (addr string, stopped <-chan struct{}) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		srv.serve(ctx, ln, nil)
+	}()
+	return ln.Addr().String(), done
+}
+
+// drainOf returns the drainer of the server, it is set, when the server is started
+func drainOf(srv *Rest) *drainer {
+	srv.lock.Lock()
+	defer srv.lock.Unlock()
+	return srv.drain
+}
+
+// waitInFlight waits until the number of requests in flight reaches n
+func waitInFlight(t *testing.T, srv *Rest, n int) {
+	require.Eventually(t, func() bool {
+		d := drainOf(srv)
+		if d == nil {
+			return false
+		}
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		return len(d.active) == n
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestRest_Shutdown(t *testing.T) {
+	solver.Register("sleepy", func(solver.Func) solver.Interface { return &solver.Euler{F: sleepyFunc(10 * time.Millisecond)} })
+	defer solver.Unregister("sleepy")
+
+	srv := &Rest{Version: "test", NumService: &service.Service{}, DrainTimeout: 5 * time.Second}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	addr, stopped := startServer(ctx, t, srv)
+	cl := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+	type result struct {
+		resp solve.Response
+		code int
+		err  error
+	}
+	slow := make(chan result, 1)
+	go func() {
+		resp, err := cl.Post("http://"+addr+"/api/v1/solve", "application/json",
+			strings.NewReader(`{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 30, "methods": ["sleepy"]}`))
+		if err != nil {
+			slow <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		res := result{code: resp.StatusCode}
+		res.err = json.NewDecoder(resp.Body).Decode(&res.resp)
+		slow <- res
+	}()
+	waitInFlight(t, srv, 1)
+
+	cancel()
+	<-drainOf(srv).closing
+	require.Eventually(t, func() bool {
+		resp, err := cl.Get("http://" + addr + "/ping")
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err != nil
+	}, time.Second, 5*time.Millisecond, "new requests must be refused")
+
+	res := <-slow
+	require.NoError(t, res.err)
+	assert.Equal(t, http.StatusOK, res.code, "request in flight must be finished")
+	require.Len(t, res.resp.Lines, 1)
+	assert.True(t, len(res.resp.Lines[0].Points) >= 30)
+
+	<-stopped
+	drained, cancelled := drainOf(srv).stats()
+	assert.Equal(t, 1, drained)
+	assert.Zero(t, cancelled)
+}
+
+func TestRest_ShutdownCancel(t *testing.T) {
+	solver.Register("sleepy", func(solver.Func) solver.Interface { return &solver.Euler{F: sleepyFunc(10 * time.Millisecond)} })
+	defer solver.Unregister("sleepy")
+
+	srv := &Rest{Version: "test", NumService: &service.Service{},
+		DrainTimeout: 100 * time.Millisecond, SolveTimeout: time.Minute}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	addr, stopped := startServer(ctx, t, srv)
+
+	// the stream, that takes longer than the drain timeout
+	resp, err := http.Get("http://" + addr + "/api/v1/solve/stream?f=x&x0=0&y0=1&x1=1&n=1000&method=sleepy")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	events := readEvents(t, resp)
+	assert.Equal(t, "point", (<-events).name)
+
+	// the websocket connection with the solve in flight and the idle one
+	busy, _, err := websocket.DefaultDialer.Dial("ws://"+addr+"/api/v1/ws", nil)
+	require.NoError(t, err)
+	defer busy.Close()
+	require.NoError(t, busy.WriteMessage(websocket.TextMessage,
+		[]byte(`{"id": "1", "request": {"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 1000, "methods": ["sleepy"]}}`)))
+	idle, _, err := websocket.DefaultDialer.Dial("ws://"+addr+"/api/v1/ws", nil)
+	require.NoError(t, err)
+	defer idle.Close()
+	waitInFlight(t, srv, 3)
+
+	st := time.Now()
+	cancel()
+
+	// the idle connection is closed at once
+	_, _, err = idle.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "%v", err)
+	assert.True(t, time.Since(st) < 100*time.Millisecond)
+
+	// the solve in flight is cancelled at the deadline
+	var last wsFrame
+	for {
+		if err = busy.ReadJSON(&last); err != nil {
+			break
+		}
+	}
+	assert.Equal(t, wsCancelled, last.Type)
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "%v", err)
+
+	var ev sseEvent
+	for e := range events {
+		ev = e
+	}
+	assert.Equal(t, "close", ev.name)
+	assert.Contains(t, ev.data, shutdownMsg)
+
+	<-stopped
+	assert.True(t, time.Since(st) < time.Second, "requests must be cancelled at the deadline")
+	drained, cancelled := drainOf(srv).stats()
+	assert.Equal(t, 1, drained)
+	assert.Equal(t, 2, cancelled)
+}
@@ -0,0 +1,495 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	log "github.com/go-pkgz/lgr"
+	"github.com/pkg/errors"
+)
+
+// maxFilenameLen is the maximal length of the part of the downloaded file name, derived from the formula
+const maxFilenameLen = 64
+
+// solveETag identifies the response of the GET solve request in the format by the problem, as it is deterministic
+func (s *Rest) solveETag(req solve.Request, format string) string {
+	if format == "" {
+		format = "json" // the default format
+	}
+	return fmt.Sprintf(`"%x"`, sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%t", req.CacheKey(s.limits()), format, req.Errors))))
+}
+
+// prepare validates the request under the limits of the server and instruments the solvers
+// of the problem to collect metrics
+func (s *Rest) prepare(req solve.Request) (solve.Prepared, error) {
+	return s.prepareUnder(req, s.limits())
+}
+
+// prepareUnder validates the request under the given limits and instruments the solvers, as prepare does
+func (s *Rest) prepareUnder(req solve.Request, l solve.Limits) (solve.Prepared, error) {
+	p, err := req.Prepare(l)
+	if s.metrics == nil {
+		return p, err
+	}
+	if err != nil {
+		s.metrics.invalid(req, l.MaxSteps, err)
+		return p, err
+	}
+	for i, slvr := range p.Solvers {
+		p.Solvers[i] = s.metrics.instrument(p.Methods[i], slvr)
+	}
+	if p.Exact != nil {
+		p.Exact = s.metrics.instrument(solve.ExactMethod, p.Exact)
+	}
+	return p, nil
+}
+
+// maxSolveBody is the max size of the body of the solve request, larger bodies are rejected with 413
+const maxSolveBody = 1 << 20
+
+// POST /api/v1/solve - solve the initial value problem with the requested methods
+// the problem document is accepted in yaml with Content-Type application/yaml, unknown fields are rejected
+// in both json and yaml
+func (s *Rest) solveCtrl(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxSolveBody)
+	decode, details := solve.DecodeJSON, "failed to decode request"
+	if isYAML(r.Header.Get("Content-Type")) {
+		decode, details = solve.DecodeYAML, "failed to decode problem document"
+	}
+	doc, err := decode(r.Body)
+	if err != nil {
+		status := http.StatusBadRequest
+		if tooLarge(err) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		rest.SendErrorJSON(w, r, status, err, details, rest.ErrDecode)
+		return
+	}
+
+	req, err := doc.Request.WithPreset()
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid solve request", rest.ErrBadRequest)
+		return
+	}
+
+	if req.Save && s.Store == nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, errors.New("results can't be saved"),
+			"saving of results is disabled", rest.ErrBadRequest)
+		return
+	}
+
+	resp, ok := s.solveRequest(w, r, req)
+	if !ok {
+		return
+	}
+
+	if req.Save {
+		var err error
+		if resp, err = s.saveResult(req, resp); err != nil {
+			rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "failed to save result", rest.ErrInternal)
+			return
+		}
+	}
+
+	s.record(r, req, resp)
+	rest.RenderJSON(w, r, resp)
+}
+
+// isYAML checks whether the content type is one of yaml media types
+func isYAML(contentType string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch mt {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return true
+	}
+	return false
+}
+
+// tooLarge checks whether the body of the request exceeded the limit of http.MaxBytesReader
+func tooLarge(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "request body too large")
+}
+
+// GET /api/v1/solve?f=x^2-2*y&x0=0&y0=1&x1=1&n=10&method=rk4&format=json|csv - solve the initial
+// value problem, the parameters are the same as in the POST request, method is repeatable
+func (s *Rest) getSolveCtrl(w http.ResponseWriter, r *http.Request) {
+	req, ok := readGetSolve(w, r)
+	if !ok {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, errors.Errorf("unknown format %q", format),
+			"format must be json or csv", rest.ErrBadRequest)
+		return
+	}
+
+	// solutions are deterministic, so the same query always gives the same result
+	etag := s.solveETag(req, format)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Header().Set("ETag", etag)
+	s.respondSolve(w, r, req, format)
+}
+
+// GET /api/v1/solve.csv?f=x^2-2*y&x0=0&y0=1&x1=1&n=10&method=rk4 - solve the initial value problem
+// and download the solution as csv file, the parameters are the same as in the GET solve request
+func (s *Rest) solveCSVCtrl(w http.ResponseWriter, r *http.Request) {
+	req, ok := readGetSolve(w, r)
+	if !ok {
+		return
+	}
+
+	etag := s.solveETag(req, "csv")
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	resp, ok := s.solveRequest(w, r, req)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Content-Disposition",
+		mime.FormatMediaType("attachment", map[string]string{"filename": csvFilename(req.F)}))
+	sendCSV(w, resp)
+}
+
+// readGetSolve reads the solve request from query parameters of the GET request,
+// responds with the error and returns false, if the request is malformed
+func readGetSolve(w http.ResponseWriter, r *http.Request) (solve.Request, bool) {
+	if r.ContentLength != 0 {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, errors.New("body is not allowed"),
+			"parameters must be passed in query", rest.ErrBadRequest)
+		return solve.Request{}, false
+	}
+
+	req, err := readSolveQuery(r)
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "failed to read query parameters", rest.ErrDecode)
+		return solve.Request{}, false
+	}
+	if req, err = req.WithPreset(); err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid solve request", rest.ErrBadRequest)
+		return solve.Request{}, false
+	}
+	return req, true
+}
+
+// respondSolve solves the problem of the request and writes the result in the given format
+func (s *Rest) respondSolve(w http.ResponseWriter, r *http.Request, req solve.Request, format string) {
+	resp, ok := s.solveRequest(w, r, req)
+	if !ok {
+		return
+	}
+	s.record(r, req, resp)
+
+	if format == "csv" {
+		sendCSV(w, resp)
+		return
+	}
+
+	rest.RenderJSON(w, r, resp)
+}
+
+// solveRequest solves the problem of the request, responds with the error and returns false, if it fails,
+// json can't encode not finite values, so methods fail at them with the step error
+func (s *Rest) solveRequest(w http.ResponseWriter, r *http.Request, req solve.Request) (solve.Response, bool) {
+	p, err := s.prepare(req)
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid solve request", rest.ErrBadRequest)
+		return solve.Response{}, false
+	}
+	p.FailNonFinite()
+
+	var resp solve.Response
+	if req.Warm {
+		resp, err = s.solveWarm(r.Context(), rest.SessionID(r), p)
+	} else {
+		resp, err = s.solveCached(r.Context(), w, p)
+	}
+	if err != nil {
+		var te *solve.TimeoutError
+		if errors.As(err, &te) {
+			s.sendTimeout(w, r, te)
+			return solve.Response{}, false
+		}
+		var be *busyError
+		if errors.As(err, &be) {
+			sendBusy(w, r, be)
+			return solve.Response{}, false
+		}
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "failed to solve", rest.ErrInternal)
+		return solve.Response{}, false
+	}
+	if req.Errors {
+		resp.Errors = resp.LocalErrors()
+	}
+	return resp, true
+}
+
+// sendCSV writes the solution as csv
+func sendCSV(w http.ResponseWriter, resp solve.Response) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	if err := resp.WriteCSV(w); err != nil {
+		log.Printf("[WARN] failed to write csv response, %v", err)
+	}
+}
+
+// csvFilename derives the name of the csv file from the formula, e.g. "x^2 - 2*y" gives "solution-x-2-2-y.csv"
+func csvFilename(f string) string {
+	var sb strings.Builder
+	dash := false
+	for _, c := range f {
+		if c < unicode.MaxASCII && (unicode.IsLetter(c) || unicode.IsDigit(c)) {
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(c)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+
+	name := sb.String()
+	if len(name) > maxFilenameLen {
+		name = strings.TrimRight(name[:maxFilenameLen], "-")
+	}
+	if name == "" {
+		return "solution.csv"
+	}
+	return "solution-" + name + ".csv"
+}
+
+// solveCached returns the solution of the problem from the cache, if the cache is enabled,
+// solving and caching it in case of miss, the outcome is reported in the X-Cache header
+func (s *Rest) solveCached(ctx context.Context, w http.ResponseWriter, p solve.Prepared) (solve.Response, error) {
+	if s.cache == nil {
+		return s.solve(ctx, p)
+	}
+
+	key := p.Req.CacheKey(s.limits())
+	v, ok := s.cache.Get(key)
+	if s.metrics != nil {
+		s.metrics.cacheLookup(ok)
+	}
+	if ok {
+		w.Header().Set("X-Cache", "HIT")
+		return v.(solve.Response), nil
+	}
+
+	w.Header().Set("X-Cache", "MISS")
+	resp, err := s.solve(ctx, p)
+	if err != nil {
+		return solve.Response{}, err
+	}
+	s.cache.Put(key, resp)
+	return resp, nil
+}
+
+// solve solves the problem, if the server isn't busy with other solves
+func (s *Rest) solve(ctx context.Context, p solve.Prepared) (solve.Response, error) {
+	release, err := s.acquire(p)
+	if err != nil {
+		return solve.Response{}, err
+	}
+	defer release()
+	return s.solveAcquired(ctx, p)
+}
+
+// solveAcquired solves the problem, which slots of lines are taken, and records the run to replay it,
+// if the recorder is set
+func (s *Rest) solveAcquired(ctx context.Context, p solve.Prepared) (solve.Response, error) {
+	// the resumed run doesn't start at x0, so it can't be replayed
+	if s.Recorder == nil || p.Warm.Start() > 0 {
+		return p.Solve(ctx)
+	}
+	p.Recorder = solve.NewRecorder()
+	resp, err := p.Solve(ctx)
+	s.Recorder.record(ctx, p, resp, err)
+	return resp, err
+}
+
+// readSolveQuery reads the solve request from query parameters
+func readSolveQuery(r *http.Request) (req solve.Request, err error) {
+	q := r.URL.Query()
+
+	if req.F, err = queryFormula(r, "f"); err != nil {
+		return solve.Request{}, err
+	}
+	if req.Exact, err = queryFormula(r, "exact"); err != nil {
+		return solve.Request{}, err
+	}
+	if req.C, err = queryFormula(r, "c"); err != nil {
+		return solve.Request{}, err
+	}
+	req.Preset = q.Get("preset")
+
+	for _, v := range []struct {
+		name string
+		dst  *float64
+	}{{"x0", &req.X0}, {"y0", &req.Y0}, {"x1", &req.XEnd}, {"step", &req.Step}} {
+		if *v.dst, err = queryFloat(r, v.name, 0); err != nil {
+			return solve.Request{}, err
+		}
+	}
+	if req.N, err = queryInt(r, "n", 0); err != nil {
+		return solve.Request{}, err
+	}
+	if req.NByMethod, err = queryNByMethod(r); err != nil {
+		return solve.Request{}, err
+	}
+	if req.Params, err = queryParams(r); err != nil {
+		return solve.Request{}, err
+	}
+	if v := q.Get("sensitivity"); v != "" {
+		if req.Sensitivity, err = strconv.ParseBool(v); err != nil {
+			return solve.Request{}, errors.Wrap(err, "sensitivity is not a boolean")
+		}
+	}
+	if req.DFDY, err = queryFormula(r, "dfdy"); err != nil {
+		return solve.Request{}, err
+	}
+	if v := q.Get("estimates"); v != "" {
+		if req.Estimates, err = strconv.ParseBool(v); err != nil {
+			return solve.Request{}, errors.Wrap(err, "estimates is not a boolean")
+		}
+	}
+	if v := q.Get("errors"); v != "" {
+		if req.Errors, err = strconv.ParseBool(v); err != nil {
+			return solve.Request{}, errors.Wrap(err, "errors is not a boolean")
+		}
+	}
+	if v := q.Get("c_bracket"); v != "" {
+		if req.CBracket, err = parseCBracket(v); err != nil {
+			return solve.Request{}, err
+		}
+	}
+	if q.Get("p") != "" || q.Get("q") != "" {
+		req.Linear = &solve.LinearForm{}
+		if req.Linear.P, err = queryFormula(r, "p"); err != nil {
+			return solve.Request{}, err
+		}
+		if req.Linear.Q, err = queryFormula(r, "q"); err != nil {
+			return solve.Request{}, err
+		}
+	}
+
+	if req.OutputGrid, err = readOutputGridQuery(r); err != nil {
+		return solve.Request{}, err
+	}
+
+	for _, m := range append(q["method"], q["methods"]...) {
+		for _, name := range strings.Split(m, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				req.Methods = append(req.Methods, name)
+			}
+		}
+	}
+
+	return req, nil
+}
+
+// parseCBracket parses the bracket of the constant as lo:hi or lo:hi:tol
+func parseCBracket(v string) (*solve.CBracket, error) {
+	parts := strings.Split(v, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, errors.Errorf("c_bracket must be lo:hi or lo:hi:tol, got %q", v)
+	}
+	vals := make([]float64, len(parts))
+	for i, part := range parts {
+		var err error
+		if vals[i], err = strconv.ParseFloat(strings.TrimSpace(part), 64); err != nil {
+			return nil, errors.Wrapf(err, "c_bracket has the invalid number %q", part)
+		}
+	}
+	cb := &solve.CBracket{Lo: vals[0], Hi: vals[1]}
+	if len(vals) == 3 {
+		cb.Tol = vals[2]
+	}
+	return cb, nil
+}
+
+// queryParams reads parameters of formulas from the repeatable query parameter "param" as name:value
+func queryParams(r *http.Request) (map[string]float64, error) {
+	var res map[string]float64
+	for _, p := range r.URL.Query()["param"] {
+		i := strings.Index(p, ":")
+		if i < 0 {
+			return nil, errors.Errorf("param %q must be name:value", p)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(p[i+1:]), 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't parse value of param %s", p[:i])
+		}
+		if res == nil {
+			res = map[string]float64{}
+		}
+		res[strings.TrimSpace(p[:i])] = v
+	}
+	return res, nil
+}
+
+// queryNByMethod reads numbers of steps of methods from the repeatable query parameter "n_by_method"
+// as method:n, pairs might be separated by commas
+func queryNByMethod(r *http.Request) (map[string]int, error) {
+	var res map[string]int
+	for _, v := range r.URL.Query()["n_by_method"] {
+		for _, kv := range strings.Split(v, ",") {
+			i := strings.Index(kv, ":")
+			if i < 0 {
+				return nil, errors.Errorf("n_by_method %q must be method:n", kv)
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(kv[i+1:]))
+			if err != nil {
+				return nil, errors.Wrapf(err, "n of %s is not an integer", kv[:i])
+			}
+			if res == nil {
+				res = map[string]int{}
+			}
+			res[strings.TrimSpace(kv[:i])] = n
+		}
+	}
+	return res, nil
+}
+
+// queryFormula reads the formula from the query parameter, unlike url.Values,
+// it keeps the unescaped plus sign as is, as it is more likely to be a part of
+// the formula than the encoded space
+func queryFormula(r *http.Request, name string) (string, error) {
+	for _, kv := range strings.Split(r.URL.RawQuery, "&") {
+		k, v := kv, ""
+		if i := strings.Index(kv, "="); i >= 0 {
+			k, v = kv[:i], kv[i+1:]
+		}
+		if k != name {
+			continue
+		}
+		res, err := url.PathUnescape(v)
+		if err != nil {
+			return "", errors.Wrapf(err, "can't unescape %s", name)
+		}
+		return res, nil
+	}
+	return "", nil
+}
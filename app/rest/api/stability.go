@@ -0,0 +1,217 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"net/http"
+	"time"
+
+	"github.com/Semior001/decompract/app/num/graph"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	log "github.com/go-pkgz/lgr"
+)
+
+// limits of the grid of stability regions, the number of nodes by each axis and the largest |re| and |im|
+// of its rectangle, so |R(z)| of methods stays finite
+const (
+	maxStabilityNodes  = 401
+	maxStabilityExtent = 1000
+)
+
+// defaults of the stability request, the rectangle covers regions of all methods of the registry
+const (
+	defaultStabilityNodes = 101
+	defaultStabilityRe0   = -5
+	defaultStabilityRe1   = 1
+	defaultStabilityIm0   = -3.5
+	defaultStabilityIm1   = 3.5
+)
+
+// stabilityReq is the request of stability regions of methods over the rectangle [re0, re1]×[im0, im1]
+// of the complex plane of hλ, with h and λ the step hλ is checked against regions
+type stabilityReq struct {
+	Methods            []string
+	H                  float64
+	Lambda, LambdaIm   float64
+	Re0, Re1, Im0, Im1 float64
+	N                  int
+	check              bool // h and lambda are set
+}
+
+// stabilityResp contains stability regions of methods
+type stabilityResp struct {
+	Lines []stabilityLine `json:"lines"`
+	Took  string          `json:"took"`
+}
+
+// stabilityLine is the stability region of the method, |R(z)| at nodes of the grid, by rows of im
+// and columns of re, the method is stable, where it doesn't exceed one
+type stabilityLine struct {
+	Method   string                `json:"method"`
+	Name     string                `json:"name"`
+	Interval float64               `json:"interval"` // the method is stable for real hλ within [-interval, 0]
+	Check    *solve.StabilityCheck `json:"check,omitempty"`
+	Re       []float64             `json:"re"`
+	Im       []float64             `json:"im"`
+	Abs      [][]float64           `json:"abs"`
+}
+
+// readStabilityQuery reads the stability request from the query, methods with stability functions
+// of the registry are taken, if none are set
+func readStabilityQuery(r *http.Request) (req stabilityReq, err error) {
+	q := r.URL.Query()
+	req.Methods = q["method"]
+	for _, v := range []struct {
+		name string
+		dst  *float64
+		def  float64
+	}{{"h", &req.H, 0}, {"lambda", &req.Lambda, 0}, {"lambda_im", &req.LambdaIm, 0},
+		{"re0", &req.Re0, defaultStabilityRe0}, {"re1", &req.Re1, defaultStabilityRe1},
+		{"im0", &req.Im0, defaultStabilityIm0}, {"im1", &req.Im1, defaultStabilityIm1}} {
+		if *v.dst, err = queryFloat(r, v.name, v.def); err != nil {
+			return stabilityReq{}, err
+		}
+	}
+	if req.N, err = queryInt(r, "n", defaultStabilityNodes); err != nil {
+		return stabilityReq{}, err
+	}
+	req.check = q.Get("h") != "" || q.Get("lambda") != "" || q.Get("lambda_im") != ""
+	return req, nil
+}
+
+// prepare validates the request and returns methods to draw, in case of invalid request,
+// rest.ValidationError with all invalid fields is returned
+func (req stabilityReq) prepare() ([]string, error) {
+	var errs rest.ValidationError
+	invalid := func(field, msg string, args ...interface{}) {
+		errs = append(errs, rest.FieldError{Field: field, Msg: fmt.Sprintf(msg, args...)})
+	}
+
+	methods := req.Methods
+	if len(methods) == 0 {
+		for _, m := range solver.Methods() {
+			if _, err := solver.StabilityFunc(m); err == nil {
+				methods = append(methods, m)
+			}
+		}
+	}
+	for _, m := range req.Methods {
+		if _, ok := solver.Lookup(m); !ok {
+			invalid("methods", "unknown method %q", m)
+			continue
+		}
+		if _, err := solver.StabilityFunc(m); err != nil {
+			invalid("methods", "the stability function of %q is unknown", m)
+		}
+	}
+	if req.check {
+		if !(req.H > 0) || math.IsInf(req.H, 1) {
+			invalid("h", "must be positive and finite, as it is set to check the step")
+		}
+		if z := complex(req.H*req.Lambda, req.H*req.LambdaIm); !(cmplx.Abs(z) <= maxStabilityExtent) {
+			invalid("lambda", "h*lambda must be within %d of the origin, got %v", maxStabilityExtent, z)
+		}
+	}
+	for _, v := range []struct {
+		name string
+		val  float64
+	}{{"re0", req.Re0}, {"re1", req.Re1}, {"im0", req.Im0}, {"im1", req.Im1}} {
+		if !solve.IsFinite(v.val) || math.Abs(v.val) > maxStabilityExtent {
+			invalid(v.name, "must be within ±%d, got %v", maxStabilityExtent, v.val)
+		}
+	}
+	if !(req.Re0 < req.Re1) {
+		invalid("re1", "must be greater than re0")
+	}
+	if !(req.Im0 < req.Im1) {
+		invalid("im1", "must be greater than im0")
+	}
+	if req.N < 2 || req.N > maxStabilityNodes {
+		invalid("n", "must be between 2 and %d, got %d", maxStabilityNodes, req.N)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return methods, nil
+}
+
+// GET /api/v1/stability?method=euler&method=rk4&h=0.1&lambda=-30&re0=-5&re1=1&im0=-3.5&im1=3.5&n=101&format=json|png|svg
+// - compute linear stability regions |R(hλ)| <= 1 of methods and render their boundaries, methods with stability
+// functions of the registry are taken, if none are set, with h and lambda (lambda_im for its imaginary part) the step
+// hλ is checked against each region, png and svg images have the width and the height as in the chart request
+func (s *Rest) stabilityCtrl(w http.ResponseWriter, r *http.Request) {
+	st := time.Now()
+	req, err := readStabilityQuery(r)
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "failed to read query parameters", rest.ErrDecode)
+		return
+	}
+	var img graph.Image
+	if format := r.URL.Query().Get("format"); format != "" && format != "json" {
+		if img, err = readChartImage(r); err != nil {
+			rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid chart parameters", rest.ErrBadRequest)
+			return
+		}
+	}
+	methods, err := req.prepare()
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid stability request", rest.ErrBadRequest)
+		return
+	}
+
+	lambda := complex(req.Lambda, req.LambdaIm)
+	resp := stabilityResp{Lines: make([]stabilityLine, 0, len(methods))}
+	regions := make([]solver.Region, 0, len(methods))
+	for _, m := range methods {
+		reg, err := solver.StabilityRegion(m, req.Re0, req.Re1, req.Im0, req.Im1, req.N)
+		if err != nil {
+			rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "failed to compute the stability region",
+				rest.ErrInternal)
+			return
+		}
+		interval, err := solver.StabilityInterval(m)
+		if err != nil {
+			rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "failed to compute the stability interval",
+				rest.ErrInternal)
+			return
+		}
+		line := stabilityLine{Method: m, Name: solve.Builder(m)(nil).Name(), Interval: interval,
+			Re: reg.Re, Im: reg.Im, Abs: reg.Abs}
+		if req.check {
+			if line.Check, err = solve.CheckStability(m, req.H, lambda); err != nil {
+				rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "failed to check the step", rest.ErrInternal)
+				return
+			}
+		}
+		resp.Lines = append(resp.Lines, line)
+		regions = append(regions, reg)
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	if img.Format == "" {
+		resp.Took = time.Since(st).String()
+		rest.RenderJSON(w, r, resp)
+		return
+	}
+
+	var pts []complex128
+	if req.check {
+		pts = append(pts, complex(req.H, 0)*lambda)
+	}
+	b, err := s.NumService.Plotter.PlotStability("Stability regions", regions, pts, img)
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "can't plot stability regions", rest.ErrInternal)
+		return
+	}
+	ct := "image/png"
+	if img.Format == "svg" {
+		ct = "image/svg+xml"
+	}
+	w.Header().Set("Content-Type", ct)
+	if _, err = w.Write(b); err != nil {
+		log.Printf("[WARN] failed to write stability regions, %v", err)
+	}
+}
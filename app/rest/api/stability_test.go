@@ -0,0 +1,135 @@
+package api
+
+import (
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRest_Stability(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	// hλ = -2.5 is beyond the stability interval of euler and ieuler, but within the one of rk4
+	q := url.Values{"h": {"0.05"}, "lambda": {"-50"}, "n": {"11"}, "re0": {"-4"}, "im0": {"-2.5"}, "im1": {"2.5"}}
+	resp, err := http.Get(ts.URL + "/api/v1/stability?" + q.Encode())
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := stabilityResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Lines, 3)
+	assert.NotEmpty(t, res.Took)
+	for i, m := range []string{"euler", "ieuler", "rk4"} {
+		line := res.Lines[i]
+		assert.Equal(t, m, line.Method)
+		assert.InDelta(t, solve.MethodTraits[m].Stability, line.Interval, 1e-3, "the limit of advise is the interval of %s", m)
+		require.Len(t, line.Re, 11)
+		require.Len(t, line.Im, 11)
+		require.Len(t, line.Abs, 11)
+		assert.InDelta(t, 1, line.Abs[5][8], 1e-12, "R(0) is 1 for %s", m)
+		require.NotNil(t, line.Check, m)
+		assert.Equal(t, [2]float64{-2.5, 0}, line.Check.Z)
+	}
+	assert.False(t, res.Lines[0].Check.Stable)
+	assert.InDelta(t, 1.5, res.Lines[0].Check.AbsR, 1e-12)
+	assert.Equal(t, "hλ = -2.5 is outside of the stability region of euler, |R(hλ)| = 1.5, errors grow by each step, "+
+		"while the solution decays, the step must be less than 0.04", res.Lines[0].Check.Warning)
+	assert.False(t, res.Lines[1].Check.Stable)
+	assert.True(t, res.Lines[2].Check.Stable)
+	assert.Empty(t, res.Lines[2].Check.Warning)
+
+	// the growing solution is not warned, the region is rendered with the checked step
+	q = url.Values{"method": {"rk4"}, "h": {"0.1"}, "lambda": {"30"}, "format": {"png"}, "width": {"300"},
+		"height": {"200"}}
+	resp, err = http.Get(ts.URL + "/api/v1/stability?" + q.Encode())
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+	img, err := png.Decode(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, 300, img.Bounds().Dx())
+
+	ok, _, err := solver.Stable("rk4", 3)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	check, err := solve.CheckStability("rk4", 0.1, 30)
+	require.NoError(t, err)
+	assert.Empty(t, check.Warning)
+}
+
+func TestRest_StabilityInvalid(t *testing.T) {
+	solver.Register("lossy", func(f solver.Func) solver.Interface { return lossySolver{&solver.Euler{F: f}} })
+	defer solver.Unregister("lossy")
+	_, ts := prepTestServer(t)
+
+	// methods without stability functions are skipped, unless they are requested
+	resp, err := http.Get(ts.URL + "/api/v1/stability?n=2")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	res := stabilityResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.Len(t, res.Lines, 3)
+
+	tbl := []struct {
+		params url.Values
+		field  string
+		msg    string
+	}{
+		{url.Values{"method": {"unknown"}}, "methods", `unknown method "unknown"`},
+		{url.Values{"method": {"lossy"}}, "methods", `the stability function of "lossy" is unknown`},
+		{url.Values{"lambda": {"-1"}}, "h", "must be positive and finite, as it is set to check the step"},
+		{url.Values{"h": {"10"}, "lambda": {"-1000"}}, "lambda", "h*lambda must be within 1000 of the origin, got (-10000+0i)"},
+		{url.Values{"re0": {"2"}}, "re1", "must be greater than re0"},
+		{url.Values{"im1": {"1e6"}}, "im1", "must be within ±1000, got 1e+06"},
+		{url.Values{"n": {"1000"}}, "n", "must be between 2 and 401, got 1000"},
+	}
+	for _, tt := range tbl {
+		resp, err := http.Get(ts.URL + "/api/v1/stability?" + tt.params.Encode())
+		require.NoError(t, err)
+		er := rest.ErrorResponse{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&er))
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, tt.msg)
+		assert.Contains(t, er.Errors, rest.FieldError{Field: tt.field, Msg: tt.msg})
+	}
+}
+
+func TestRest_SolveStabilityWarning(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	body := `{"f": "-50*y", "x0": 0, "y0": 1, "x_end": 1, "n": 20, "methods": ["euler", "rk4"]}`
+	resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Lines, 2)
+	require.NotNil(t, res.Lines[0].Stats)
+	assert.Contains(t, res.Lines[0].Stats.Warnings, "λ = df/dy = -50 at x0: hλ = -2.5 is outside of the stability "+
+		"region of euler, |R(hλ)| = 1.5, errors grow by each step, while the solution decays, the step must be less than 0.04")
+	assert.Empty(t, res.Lines[1].Stats.Warnings, "rk4 is stable with hλ = -2.5")
+
+	// the backward solve of the growing solution decays, so it is checked with -df/dy
+	body = `{"f": "50*y", "x0": 1, "y0": 1, "x_end": 0, "n": 20, "methods": ["euler"]}`
+	resp, err = http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res = solve.Response{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Lines, 1)
+	require.NotNil(t, res.Lines[0].Stats)
+	require.Len(t, res.Lines[0].Stats.Warnings, 1)
+	assert.Contains(t, res.Lines[0].Stats.Warnings[0], "λ = -df/dy of the backward solve = -50 at x0: hλ = -2.5 is outside")
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	log "github.com/go-pkgz/lgr"
+	"github.com/pkg/errors"
+)
+
+// sseHeartbeat is the interval between heartbeat comments in the event stream
+var sseHeartbeat = 5 * time.Second
+
+// GET /api/v1/solve/stream?f=x^2-2*y&x0=0&y0=1&x1=1&n=10&method=rk4&format=sse|ndjson - solves the problem
+// with the parameters of GET /api/v1/solve and streams the calculated points as server-sent events,
+// or as lines of json objects with the name of the event and its data
+func (s *Rest) streamSolveCtrl(w http.ResponseWriter, r *http.Request) {
+	req, err := readSolveQuery(r)
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "failed to read query parameters", rest.ErrDecode)
+		return
+	}
+	if req.OutputGrid != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, errors.New("points of steps are streamed"),
+			"output grid is not supported by streaming", rest.ErrBadRequest)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "sse" && format != "ndjson" {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, errors.Errorf("format must be sse or ndjson, got %q", format),
+			"failed to read query parameters", rest.ErrDecode)
+		return
+	}
+
+	p, err := s.prepare(req)
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid solve request", rest.ErrBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, errors.New("response writer is not a flusher"),
+			"streaming is not supported", rest.ErrInternal)
+		return
+	}
+
+	release, err := s.acquire(p)
+	var be *busyError
+	if errors.As(err, &be) {
+		sendBusy(w, r, be)
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	var sw solve.EventWriter
+	if format == "ndjson" {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		sw = &ndjsonWriter{w: w, flusher: flusher}
+	} else {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Connection", "keep-alive")
+		ssew := &sseWriter{w: w, flusher: flusher}
+		go ssew.heartbeat(ctx, sseHeartbeat)
+		sw = ssew
+	}
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	summary, err := p.Stream(ctx, sw)
+	if err != nil {
+		if ctx.Err() != nil && s.drain.shuttingDown() {
+			if err = sw.Event("close", streamError{Error: shutdownMsg}); err != nil {
+				log.Printf("[WARN] failed to send close event, %v", err)
+			}
+			return
+		}
+		if ctx.Err() != nil {
+			log.Printf("[DEBUG] stream is cancelled by client, %v", err)
+			return
+		}
+		if err = sw.Event("error", streamError{Error: err.Error()}); err != nil {
+			log.Printf("[WARN] failed to send error event, %v", err)
+		}
+		return
+	}
+
+	if err = sw.Event("done", summary); err != nil {
+		log.Printf("[WARN] failed to send done event, %v", err)
+	}
+}
+
+// streamError is the data of the error event in the stream
+type streamError struct {
+	Error string `json:"error"`
+}
+
+// ndjsonWriter writes events to the response as lines of json objects, there are no heartbeats,
+// so events are written by the single goroutine
+type ndjsonWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// ndjsonEvent is the line of the stream of json objects
+type ndjsonEvent struct {
+	Event string      `json:"event"`
+	Data  interface{} `json:"data"`
+}
+
+// event writes the event as the line and flushes it
+func (nw *ndjsonWriter) Event(name string, data interface{}) error {
+	b, err := json.Marshal(ndjsonEvent{Event: name, Data: data})
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal %s event", name)
+	}
+	if _, err = nw.w.Write(append(b, '\n')); err != nil {
+		return errors.Wrap(err, "failed to write to stream")
+	}
+	nw.flusher.Flush()
+	return nil
+}
+
+// sseWriter writes server-sent events to the response
+type sseWriter struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// event writes the event with the json-encoded data and flushes it
+func (sw *sseWriter) Event(name string, data interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal %s event", name)
+	}
+	return sw.write(fmt.Sprintf("event: %s\ndata: %s\n\n", name, b))
+}
+
+// heartbeat writes comments to the stream with the given interval until the context is done
+func (sw *sseWriter) heartbeat(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := sw.write(": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (sw *sseWriter) write(s string) error {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	if _, err := fmt.Fprint(sw.w, s); err != nil {
+		return errors.Wrap(err, "failed to write to stream")
+	}
+	sw.flusher.Flush()
+	return nil
+}
@@ -0,0 +1,203 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sseEvent struct {
+	name string
+	data string
+}
+
+// readEvents reads events from the stream and sends them to the channel until the stream is closed
+func readEvents(t *testing.T, resp *http.Response) <-chan sseEvent {
+	ch := make(chan sseEvent)
+	go func() {
+		defer close(ch)
+		sc := bufio.NewScanner(resp.Body)
+		ev := sseEvent{}
+		for sc.Scan() {
+			line := sc.Text()
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				ev.name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				ev.data = strings.TrimPrefix(line, "data: ")
+			case line == "" && ev.name != "":
+				ch <- ev
+				ev = sseEvent{}
+			}
+		}
+	}()
+	return ch
+}
+
+func TestRest_StreamSolve(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/api/v1/solve/stream?f=y*y*exp(x)-2*y&exact=exp(-x)/(c*exp(x)%2B1)" +
+		"&c=(exp(-x0)-y0)/(y0*exp(x0))&x0=0&y0=1&x1=1&n=10&method=rk4,euler")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	var methods []string
+	var last sseEvent
+	for ev := range readEvents(t, resp) {
+		last = ev
+		if ev.name != "point" {
+			continue
+		}
+		pt := solve.PointEvent{}
+		require.NoError(t, json.Unmarshal([]byte(ev.data), &pt))
+		methods = append(methods, pt.Method)
+	}
+
+	require.Len(t, methods, 33)
+	for i, m := range []string{"rk4", "euler", "exact"} {
+		for _, actual := range methods[i*11 : (i+1)*11] {
+			assert.Equal(t, m, actual)
+		}
+	}
+
+	assert.Equal(t, "done", last.name)
+	summary := solve.StreamSummary{}
+	require.NoError(t, json.Unmarshal([]byte(last.data), &summary))
+	require.Len(t, summary.Lines, 3)
+	assert.Equal(t, 11, summary.Lines[0].Points)
+	assert.Equal(t, "Exact solution", summary.Lines[2].Name)
+}
+
+func TestRest_StreamSolveNDJSON(t *testing.T) {
+	breakMethod(t)
+	_, ts := prepTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/api/v1/solve/stream?f=x&x0=0&y0=1&x1=1&n=10&method=rk4&format=ndjson")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+	type line struct {
+		Event string          `json:"event"`
+		Data  json.RawMessage `json:"data"`
+	}
+	var lines []line
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		l := line{}
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &l), sc.Text())
+		lines = append(lines, l)
+	}
+	require.Len(t, lines, 12)
+	pt := solve.PointEvent{}
+	require.NoError(t, json.Unmarshal(lines[10].Data, &pt))
+	assert.Equal(t, "rk4", pt.Method)
+	assert.Equal(t, 1.0, pt.X)
+	assert.InDelta(t, 1.5, pt.Y, 1e-12)
+	assert.Equal(t, "done", lines[11].Event)
+	summary := solve.StreamSummary{}
+	require.NoError(t, json.Unmarshal(lines[11].Data, &summary))
+	assert.Equal(t, 11, summary.Lines[0].Points)
+
+	resp, err = http.Get(ts.URL + "/api/v1/solve/stream?f=x&x0=1&y0=1&x1=2&n=1&method=broken&format=ndjson")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `{"event":"error","data":{"error":"failed to solve with broken`)
+
+	resp, err = http.Get(ts.URL + "/api/v1/solve/stream?f=x&x0=0&y0=1&x1=1&n=10&method=rk4&format=xml")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestRest_StreamSolveError(t *testing.T) {
+	breakMethod(t)
+	_, ts := prepTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/api/v1/solve/stream?f=y/x&x0=1&y0=1&x1=2&n=1&method=euler&exact=abc%2B&c=1")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Get(ts.URL + "/api/v1/solve/stream?f=x&x0=1&y0=1&x1=2&n=1&method=broken")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var last sseEvent
+	for ev := range readEvents(t, resp) {
+		last = ev
+	}
+	assert.Equal(t, "error", last.name)
+	assert.Contains(t, last.data, "failed to solve with broken")
+}
+
+func TestRest_StreamSolveCancel(t *testing.T) {
+	var calls int32
+	solver.Register("slow", func(f solver.Func) solver.Interface {
+		return &solver.Euler{F: func(x, y float64) (float64, error) {
+			atomic.AddInt32(&calls, 1)
+			time.Sleep(time.Millisecond)
+			return f(x, y)
+		}}
+	})
+	defer solver.Unregister("slow")
+
+	srv, ts := prepTestServer(t)
+	srv.Limits.MaxSteps = 100000
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/v1/solve/stream?f=x&x0=0&y0=1&x1=1&n=100000&method=slow", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	events := readEvents(t, resp)
+	for i := 0; i < 5; i++ {
+		ev := <-events
+		assert.Equal(t, "point", ev.name)
+	}
+	cancel()
+
+	time.Sleep(100 * time.Millisecond)
+	stopped := atomic.LoadInt32(&calls)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, stopped, atomic.LoadInt32(&calls), "solving must be stopped after client disconnect")
+	assert.True(t, stopped < 1000)
+}
+
+func TestRest_StreamSolveCompressionAccepted(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/v1/solve/stream?f=x&x0=0&y0=1&x1=1&n=10&method=rk4", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("Content-Encoding"), "events must not be compressed")
+
+	select {
+	case ev := <-readEvents(t, resp):
+		assert.Equal(t, "point", ev.name)
+	case <-time.After(time.Second):
+		t.Fatal("events must be delivered promptly")
+	}
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/pkg/errors"
+)
+
+// default bounds of the number of steps in the error sweep
+const (
+	defaultSweepN0 = 10
+	defaultSweepN1 = 100
+)
+
+// GET /api/v1/errors?f=y&exact=c*exp(x)&c=y0/exp(x0)&x0=0&y0=1&x1=1&n0=10&n1=100&methods=euler,rk4 - solve
+// the problem with each number of steps from n0 to n1 and return max global truncation errors of methods
+// along with the fitted orders of convergence, the reference is the exact solution, if it is given
+func (s *Rest) errorsCtrl(w http.ResponseWriter, r *http.Request) {
+	sr, ok := readGetSolve(w, r)
+	if !ok {
+		return
+	}
+	req := solve.SweepReq{Request: sr}
+	var err error
+	if req.N0, err = queryInt(r, "n0", defaultSweepN0); err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "failed to read query parameters", rest.ErrDecode)
+		return
+	}
+	if req.N1, err = queryInt(r, "n1", defaultSweepN1); err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "failed to read query parameters", rest.ErrDecode)
+		return
+	}
+
+	sw, err := req.Prepare(s.limits())
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid errors request", rest.ErrBadRequest)
+		return
+	}
+	sw.Workers = s.SweepWorkers
+
+	resp, err := s.sweepCached(r.Context(), w, sw)
+	if err != nil {
+		var te *solve.TimeoutError
+		if errors.As(err, &te) {
+			s.sendTimeout(w, r, te)
+			return
+		}
+		var be *busyError
+		if errors.As(err, &be) {
+			sendBusy(w, r, be)
+			return
+		}
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "failed to calculate errors", rest.ErrInternal)
+		return
+	}
+
+	// errors are deterministic, as solutions are
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	rest.RenderJSON(w, r, resp)
+}
+
+// sweepCached returns the errors of the sweep from the cache, if the cache is enabled,
+// running and caching it in case of miss, the outcome is reported in the X-Cache header
+func (s *Rest) sweepCached(ctx context.Context, w http.ResponseWriter, sw solve.Sweep) (solve.ErrorsResp, error) {
+	run := func() (solve.ErrorsResp, error) {
+		release, err := s.acquire(sw.Problem)
+		if err != nil {
+			return solve.ErrorsResp{}, err
+		}
+		defer release()
+		return sw.Run(ctx)
+	}
+	if s.cache == nil {
+		return run()
+	}
+
+	key := sw.Req.CacheKey()
+	v, ok := s.cache.Get(key)
+	if s.metrics != nil {
+		s.metrics.cacheLookup(ok)
+	}
+	if ok {
+		w.Header().Set("X-Cache", "HIT")
+		return v.(solve.ErrorsResp), nil
+	}
+
+	w.Header().Set("X-Cache", "MISS")
+	resp, err := run()
+	if err != nil {
+		return solve.ErrorsResp{}, err
+	}
+	s.cache.Put(key, resp)
+	return resp, nil
+}
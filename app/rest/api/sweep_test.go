@@ -0,0 +1,211 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num/service"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func errorsURL(ts string, q url.Values) string {
+	return ts + "/api/v1/errors?" + strings.ReplaceAll(q.Encode(), "+", "%20")
+}
+
+func getErrors(t *testing.T, u string) (solve.ErrorsResp, *http.Response) {
+	resp, err := http.Get(u)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	res := solve.ErrorsResp{}
+	if resp.StatusCode == http.StatusOK {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	}
+	return res, resp
+}
+
+func TestRest_Errors(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	q := url.Values{"f": {"y"}, "exact": {"c*exp(x)"}, "c": {"y0/exp(x0)"}, "x0": {"0"}, "y0": {"1"}, "x1": {"1"},
+		"n0": {"10"}, "n1": {"40"}, "methods": {"euler,ieuler,rk4"}}
+	res, resp := getErrors(t, errorsURL(ts.URL, q))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "public, max-age=3600", resp.Header.Get("Cache-Control"))
+	assert.Equal(t, "exact", res.Reference)
+	require.Len(t, res.Lines, 3)
+
+	for i, expected := range []struct {
+		method string
+		order  float64
+	}{{"euler", 1}, {"ieuler", 2}, {"rk4", 4}} {
+		line := res.Lines[i]
+		assert.Equal(t, expected.method, line.Method)
+		assert.NotEmpty(t, line.Name)
+		assert.Empty(t, line.Failures)
+		assert.Nil(t, line.Error)
+		require.Len(t, line.Points, 31)
+		assert.Equal(t, 10, line.Points[0].N)
+		assert.Equal(t, 40, line.Points[30].N)
+		assert.Less(t, line.Points[30].GTE, line.Points[0].GTE, "error decreases with n")
+		require.NotNil(t, line.Order)
+		assert.InDelta(t, expected.order, *line.Order, 0.15, expected.method)
+	}
+
+	// the same orders are fitted against the reference solution
+	q.Del("exact")
+	q.Del("c")
+	res, resp = getErrors(t, errorsURL(ts.URL, q))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "rk4", res.Reference)
+	require.Len(t, res.Lines, 3)
+	for i, order := range []float64{1, 2, 4} {
+		require.NotNil(t, res.Lines[i].Order)
+		assert.InDelta(t, order, *res.Lines[i].Order, 0.15, res.Lines[i].Method)
+	}
+}
+
+func TestRest_ErrorsJSON(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	q := url.Values{"f": {"x"}, "exact": {"x*x/2 + c"}, "c": {"y0 - x0*x0/2"}, "x0": {"0"}, "y0": {"0"}, "x1": {"1"},
+		"n0": {"2"}, "n1": {"3"}, "method": {"rk4"}}
+	resp, err := http.Get(errorsURL(ts.URL, q))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var v map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&v))
+	assert.Equal(t, "exact", v["reference"])
+	assert.NotEmpty(t, v["took"])
+	line := v["lines"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "rk4", line["method"])
+	pts := line["points"].([]interface{})
+	require.Len(t, pts, 2)
+	assert.Equal(t, 2.0, pts[0].(map[string]interface{})["n"])
+	assert.Equal(t, 0.5, pts[0].(map[string]interface{})["h"])
+	assert.InDelta(t, 0, pts[0].(map[string]interface{})["gte"], 1e-12, "rk4 is exact for polynomials")
+	assert.NotContains(t, line, "order", "order can't be fitted through zero errors")
+	assert.NotContains(t, line, "failures")
+	assert.NotContains(t, line, "error")
+}
+
+func TestRest_ErrorsPartial(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	// y' = -y^3 from y0 = 10 is stable for explicit methods only with small steps, large ones blow up
+	q := url.Values{"f": {"-y*y*y"}, "exact": {"(2*x + c)**(-0.5)"}, "c": {"1/(y0*y0) - 2*x0"},
+		"x0": {"0"}, "y0": {"10"}, "x1": {"1"}, "n0": {"10"}, "n1": {"200"}, "methods": {"euler,rk4"}}
+	res, resp := getErrors(t, errorsURL(ts.URL, q))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, res.Lines, 2)
+
+	for _, line := range res.Lines {
+		require.NotEmpty(t, line.Failures, line.Method)
+		assert.Equal(t, 10, line.Failures[0].N, line.Method)
+		assert.Equal(t, "solution diverged", line.Failures[0].Error.Details)
+		assert.Contains(t, line.Failures[0].Error.Error, "diverged at x=")
+		require.NotEmpty(t, line.Points, line.Method)
+		assert.Equal(t, 200, line.Points[len(line.Points)-1].N)
+		assert.Equal(t, 191, len(line.Points)+len(line.Failures))
+		assert.Nil(t, line.Error)
+		assert.NotNil(t, line.Order)
+	}
+
+	// the method fails with each n, the rest of methods are solved
+	q.Set("n1", "45")
+	res, resp = getErrors(t, errorsURL(ts.URL, q))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, res.Lines, 2)
+	euler := res.Lines[0]
+	require.NotNil(t, euler.Error)
+	assert.Equal(t, "euler failed with each n from 10 to 45", euler.Error.Error)
+	assert.Empty(t, euler.Points)
+	assert.Nil(t, euler.Order)
+	assert.Len(t, euler.Failures, 36)
+	assert.Nil(t, res.Lines[1].Error)
+	assert.NotEmpty(t, res.Lines[1].Points)
+
+	// all methods fail
+	q.Set("n1", "20")
+	_, resp = getErrors(t, errorsURL(ts.URL, q))
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
+
+func TestRest_ErrorsLimits(t *testing.T) {
+	srv, ts := prepTestServer(t)
+	srv.Limits = solve.Limits{MaxSteps: 100, MaxWidth: 10, MaxSweep: 50}
+
+	base := url.Values{"f": {"y"}, "x0": {"0"}, "y0": {"1"}, "x1": {"1"}, "methods": {"euler"}}
+	tbl := []struct {
+		params url.Values
+		field  string
+		msg    string
+	}{
+		{url.Values{"n0": {"0"}}, "n0", "must be positive, got 0"},
+		{url.Values{"n0": {"20"}, "n1": {"10"}}, "n1", "must not be less than n0=20, got 10"},
+		{url.Values{"n0": {"10"}, "n1": {"101"}}, "n1", "must not be more than max_steps=100, got 101"},
+		{url.Values{"n0": {"10"}, "n1": {"60"}}, "n1", "gives 51 values of n, more than max_sweep=50"},
+		{url.Values{"x1": {"20"}}, "x_end", "gives the interval of width 20, more than max_width=10"},
+		{url.Values{"x1": {"0"}}, "x_end", "must differ from x0, the interval of zero length can't be split into n steps"},
+		{url.Values{"methods": {"exact"}, "exact": {"c*exp(x)"}, "c": {"y0"}}, "methods",
+			"exact solution is the reference, it has no errors"},
+		{url.Values{"methods": {"rk5"}}, "methods", `unknown method "rk5"`},
+	}
+	for _, tt := range tbl {
+		q := url.Values{}
+		for k, v := range base {
+			q[k] = v
+		}
+		for k, v := range tt.params {
+			q[k] = v
+		}
+		resp, err := http.Get(errorsURL(ts.URL, q))
+		require.NoError(t, err)
+		er := rest.ErrorResponse{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&er))
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, tt.msg)
+		assert.Equal(t, rest.ErrBadRequest, er.Code)
+		assert.Contains(t, er.Errors, rest.FieldError{Field: tt.field, Msg: tt.msg})
+	}
+
+	resp, err := http.Get(ts.URL + "/api/v1/errors?f=y&x0=0&y0=1&x1=1&methods=euler&n0=ten")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// defaults of n0 and n1 are within default limits
+	srv.Limits = solve.Limits{}
+	_, resp = getErrors(t, errorsURL(ts.URL, base))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestRest_ErrorsCache(t *testing.T) {
+	srv := &Rest{Version: "test", NumService: &service.Service{}, CacheSize: 10}
+	ts := httptest.NewServer(srv.routes())
+	defer ts.Close()
+
+	q := url.Values{"f": {"y"}, "x0": {"0"}, "y0": {"1"}, "x1": {"1"}, "n0": {"5"}, "n1": {"10"}, "methods": {"rk4"}}
+	first, resp := getErrors(t, errorsURL(ts.URL, q))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "MISS", resp.Header.Get("X-Cache"))
+
+	second, resp := getErrors(t, errorsURL(ts.URL, q))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "HIT", resp.Header.Get("X-Cache"))
+	assert.Equal(t, first, second)
+
+	// the solve request of the same problem is cached separately
+	resp, err := http.Get(ts.URL + "/api/v1/solve?f=y&x0=0&y0=1&x1=1&n=10&methods=rk4")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "MISS", resp.Header.Get("X-Cache"))
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/go-chi/render"
+	log "github.com/go-pkgz/lgr"
+	"github.com/pkg/errors"
+)
+
+// defaultSolveTimeout is the default maximal duration of computations of a single request
+const defaultSolveTimeout = 5 * time.Second
+
+// timeoutResp is the body of the response to the request, that is not solved in time
+type timeoutResp struct {
+	rest.ErrorResponse
+	XReached float64 `json:"x_reached"`
+}
+
+// solveTimeout returns the maximal duration of computations of a single request
+func (s *Rest) solveTimeout() time.Duration {
+	if s.SolveTimeout <= 0 {
+		return defaultSolveTimeout
+	}
+	return s.SolveTimeout
+}
+
+// sendTimeout responds with 504 and the point, the integration reached
+func (s *Rest) sendTimeout(w http.ResponseWriter, r *http.Request, te *solve.TimeoutError) {
+	log.Printf("[WARN] %s %s: %v", r.Method, r.URL.Path, te)
+	err := errors.Errorf("solve timed out after %s", s.solveTimeout())
+	render.Status(r, http.StatusGatewayTimeout)
+	rest.RenderJSON(w, r, timeoutResp{
+		ErrorResponse: rest.NewErrorResponse(err, "failed to solve in time", rest.ErrInternal),
+		XReached:      te.XReached,
+	})
+}
+
+// ctxSolver stops solving with the wrapped solver, as soon as the context is done
+type ctxSolver struct {
+	solver.Interface
+	ctx context.Context
+}
+
+// Solve solves the problem with the wrapped solver until the context is done
+func (cs ctxSolver) Solve(stepSize, x0, y0, xEnd float64, d solver.Drawer) error {
+	return solver.SolveContext(cs.ctx, cs.Interface, stepSize, x0, y0, xEnd, solver.WithLogger(d, rest.CtxLogger(cs.ctx)))
+}
+
+// withContext wraps solvers to stop them, as soon as the context is done
+func withContext(ctx context.Context, slvrs ...solver.Interface) []solver.Interface {
+	res := make([]solver.Interface, 0, len(slvrs))
+	for _, slvr := range slvrs {
+		res = append(res, ctxSolver{Interface: slvr, ctx: ctx})
+	}
+	return res
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Semior001/decompract/app/num/graph"
+	"github.com/Semior001/decompract/app/num/service"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sleepyFunc is f(x,y) = 1, that sleeps on each call
+func sleepyFunc(d time.Duration) solver.Func {
+	return func(x, y float64) (float64, error) {
+		time.Sleep(d)
+		return 1, nil
+	}
+}
+
+func TestRest_SolveTimeout(t *testing.T) {
+	solver.Register("sleepy", func(solver.Func) solver.Interface { return &solver.Euler{F: sleepyFunc(5 * time.Millisecond)} })
+	defer solver.Unregister("sleepy")
+
+	srv, ts := prepTestServer(t)
+	srv.SolveTimeout = 100 * time.Millisecond
+	ts.Config.Handler = srv.routes()
+
+	st := time.Now()
+	resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json",
+		strings.NewReader(`{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 1000, "methods": ["rk4", "sleepy"]}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.True(t, time.Since(st) < time.Second, "solve must be stopped by timeout")
+	require.Equal(t, http.StatusGatewayTimeout, resp.StatusCode)
+
+	var body jsonMap
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "solve timed out after 100ms", body["error"])
+	xReached := body["x_reached"].(float64)
+	assert.True(t, xReached > 0 && xReached < 1, "x_reached must show the progress, got %v", xReached)
+
+	// the response matches the description in the spec
+	doc := jsonMap{}
+	b, err := json.Marshal(srv.openAPI())
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(b, &doc))
+	assert.NoError(t, validateSchema(doc, jsonMap{"$ref": "#/components/schemas/Timeout"}, body, "timeout"))
+
+	// the remaining problems of the batch are failed by the timeout
+	resp, err = http.Post(ts.URL+"/api/v1/solve/batch", "application/json", strings.NewReader(
+		`[{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 1000, "methods": ["sleepy"]}]`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	bres := batchResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&bres))
+	require.Len(t, bres.Results, 1)
+	require.NotNil(t, bres.Results[0].Error)
+	assert.Contains(t, bres.Results[0].Error.Error, "timed out")
+}
+
+func TestRest_PlotGraphsTimeout(t *testing.T) {
+	slow := sleepyFunc(time.Millisecond)
+	srv := &Rest{
+		SolveTimeout: 200 * time.Millisecond,
+		NumService: &service.Service{
+			Plotter: graph.Plotter{},
+			Solvers: []solver.Interface{&solver.Euler{F: slow}},
+			ExactSolver: &solver.Exact{
+				F: func(x, c float64) (float64, error) { return x + c, nil },
+				C: func(x0, y0 float64) (float64, error) { return y0 - x0, nil },
+			},
+		},
+	}
+	ts := httptest.NewServer(srv.routes())
+	defer ts.Close()
+
+	// the sweep of global errors over n is too long to finish in time
+	form := url.Values{"x0": {"0"}, "y0": {"1"}, "x_end": {"1"}, "n": {"5"}, "nmin": {"5"}, "nmax": {"200"},
+		"fxy": {""}, "yxc": {""}, "c": {""}}
+	st := time.Now()
+	resp, err := http.PostForm(ts.URL+"/", form)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusGatewayTimeout, resp.StatusCode)
+	assert.True(t, time.Since(st) < 2*time.Second, "sweep must be stopped by timeout")
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Semior001/decompract/app/rest"
+	log "github.com/go-pkgz/lgr"
+	"github.com/pkg/errors"
+)
+
+// serveTLS serves https on the listener and redirects plain http requests from the redirect listener,
+// if it is set, grpc calls are served on the grpc listener with tls, if it is set, the server is stopped
+// with the error, if certificates can't be obtained
+func (s *Rest) serveTLS(ctx context.Context, ln, rln, gln net.Listener) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	if rln != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.serveRedirect(ctx, rln, ln.Addr().(*net.TCPAddr).Port)
+		}()
+	}
+
+	var obtainErr error
+	if s.TLS.Obtain != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.TLS.Obtain(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("[ERROR] %v, stopping the server", err)
+				obtainErr = err
+				cancel()
+			}
+		}()
+	}
+
+	s.serve(ctx, tls.NewListener(ln, s.TLS.Config), gln)
+	cancel()
+	wg.Wait()
+	return obtainErr
+}
+
+// serveRedirect redirects requests from the listener to https server at the port until the context is done,
+// acme challenges are answered, if the setup of https has them
+func (s *Rest) serveRedirect(ctx context.Context, ln net.Listener, port int) {
+	var h http.Handler = rest.RedirectHTTPS{Port: port}
+	if s.TLS.Challenge != nil {
+		h = s.TLS.Challenge(h)
+	}
+	srv := &http.Server{Handler: h, ReadHeaderTimeout: 5 * time.Second, IdleTimeout: 30 * time.Second,
+		ErrorLog: log.ToStdLogger(log.Default(), "WARN")}
+
+	go func() {
+		<-ctx.Done()
+		sctx, scancel := context.WithTimeout(context.Background(), time.Second)
+		defer scancel()
+		if err := srv.Shutdown(sctx); err != nil {
+			log.Printf("[WARN] failed to shutdown redirect server, %v", err)
+		}
+	}()
+
+	log.Printf("[INFO] redirecting http requests at port %d to https", ln.Addr().(*net.TCPAddr).Port)
+	if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("[WARN] redirect server terminated reason: %s", err)
+	}
+}
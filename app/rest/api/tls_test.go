@@ -0,0 +1,153 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Semior001/decompract/app/num/service"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRest_TLS(t *testing.T) {
+	pool, setup := certSetup(t)
+	srv := &Rest{Version: "test", NumService: &service.Service{}, TLS: setup}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	rln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.serveTLS(ctx, ln, rln, nil) }()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	cl := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}, DisableKeepAlives: true},
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	var resp *http.Response
+	require.Eventually(t, func() bool {
+		resp, err = cl.Get("https://localhost:" + strconv.Itoa(port) + "/ping")
+		return err == nil
+	}, time.Second, 5*time.Millisecond)
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "pong", string(body))
+
+	// plain http is not served at the https port
+	resp, err = cl.Get("http://localhost:" + strconv.Itoa(port) + "/ping")
+	if err == nil {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	}
+
+	// tls versions older than 1.2 are refused
+	old := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool,
+		MinVersion: tls.VersionTLS10, MaxVersion: tls.VersionTLS11}}}
+	_, err = old.Get("https://localhost:" + strconv.Itoa(port) + "/ping")
+	require.Error(t, err)
+
+	resp, err = cl.Get("http://" + rln.Addr().String() + "/api/v1/info?a=1")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMovedPermanently, resp.StatusCode)
+	assert.Equal(t, "https://127.0.0.1:"+strconv.Itoa(port)+"/api/v1/info?a=1", resp.Header.Get("Location"))
+
+	cancel()
+	select {
+	case err = <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server is not stopped")
+	}
+	_, err = net.Dial("tcp", rln.Addr().String())
+	assert.Error(t, err, "redirect listener must be closed")
+}
+
+func TestRest_TLSObtainError(t *testing.T) {
+	_, setup := certSetup(t)
+	setup.Obtain = func(context.Context) error { return errors.New("failed to obtain certificate for example.com") }
+	srv := &Rest{Version: "test", NumService: &service.Service{}, TLS: setup}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() { done <- srv.serveTLS(context.Background(), ln, nil, nil) }()
+	select {
+	case err = <-done:
+		assert.EqualError(t, err, "failed to obtain certificate for example.com")
+	case <-time.After(5 * time.Second):
+		t.Fatal("server is not stopped")
+	}
+}
+
+func TestRest_RunTLSErrors(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	busy := ln.Addr().(*net.TCPAddr).Port
+
+	_, setup := certSetup(t)
+	srv := &Rest{Version: "test", NumService: &service.Service{}, TLS: setup, RedirectPort: busy}
+	err = srv.Run(context.Background(), 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to listen redirect port "+strconv.Itoa(busy))
+
+	err = srv.Run(context.Background(), busy)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to listen port "+strconv.Itoa(busy))
+}
+
+// certSetup writes the self-signed certificate of localhost and 127.0.0.1 and loads it
+// with rest.TLS, the returned pool trusts the certificate
+func certSetup(t *testing.T) (*x509.CertPool, *rest.TLSSetup) {
+	dir, err := ioutil.TempDir("", "decompract-tls")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	pk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &pk.PublicKey, pk)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(pk)
+	require.NoError(t, err)
+
+	cert, key := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	require.NoError(t, ioutil.WriteFile(cert, certPEM, 0o600))
+	require.NoError(t, ioutil.WriteFile(key, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	setup, err := rest.TLS{CertFile: cert, KeyFile: key}.Setup()
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	require.True(t, pool.AppendCertsFromPEM(certPEM))
+	return pool, setup
+}
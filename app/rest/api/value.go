@@ -0,0 +1,96 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Semior001/decompract/app/num/interp"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/pkg/errors"
+)
+
+// maxValueXs is the maximal number of x, at which solutions are evaluated by the value request
+const maxValueXs = 1000
+
+// valueResp contains values of solutions at x of the request, interpolated between points of lines,
+// the exact solution is evaluated at x directly
+type valueResp struct {
+	X      []float64         `json:"x"`
+	Interp string            `json:"interp"`
+	Lines  []solve.ValueLine `json:"lines"`
+	Exact  *solve.ValueLine  `json:"exact,omitempty"`
+	Took   string            `json:"took"`
+}
+
+// readValueQuery reads x and the kind of the interpolation of the value request, x are within
+// the interval of the problem, the kind is cubic by default
+func readValueQuery(r *http.Request, req solve.Request) (xs []float64, kind string, err error) {
+	q := r.URL.Query()
+	if len(q["x"]) == 0 || len(q["x"]) > maxValueXs {
+		return nil, "", rest.ValidationError{{Field: "x", Msg: fmt.Sprintf("must be set from 1 to %d times", maxValueXs)}}
+	}
+	for _, v := range q["x"] {
+		x, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, "", errors.Wrap(err, "x is not a number")
+		}
+		// the interval of the backward solve is from x_end to x0
+		if lo, hi := math.Min(req.X0, req.XEnd), math.Max(req.X0, req.XEnd); !(x >= lo && x <= hi) {
+			return nil, "", rest.ValidationError{{Field: "x",
+				Msg: fmt.Sprintf("%v is out of the interval [%v, %v] of the problem", x, lo, hi)}}
+		}
+		xs = append(xs, x)
+	}
+	switch kind = q.Get("interp"); kind {
+	case "":
+		kind = interp.KindCubic
+	case interp.KindLinear, interp.KindCubic, interp.KindHermite:
+	default:
+		return nil, "", rest.ValidationError{{Field: "interp", Msg: fmt.Sprintf("must be %s, %s or %s, got %q",
+			interp.KindLinear, interp.KindCubic, interp.KindHermite, kind)}}
+	}
+	return xs, kind, nil
+}
+
+// GET /api/value?x=0.25&x=0.5&interp=cubic&f=x^2-2*y&x0=0&y0=1&x1=1&n=10&method=euler&method=rk4 - solve
+// the problem, as the GET solve request does, and evaluate the solution by each method at arbitrary x within
+// the interval, it is interpolated between points of the line by linear, cubic spline or hermite with slopes
+// f(x,y) at points, the exact solution is evaluated at x directly
+func (s *Rest) valueCtrl(w http.ResponseWriter, r *http.Request) {
+	st := time.Now()
+	req, ok := readGetSolve(w, r)
+	if !ok {
+		return
+	}
+	xs, kind, err := readValueQuery(r, req)
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid value request", rest.ErrBadRequest)
+		return
+	}
+
+	// the problem is valid, as it is solved
+	sol, ok := s.solveRequest(w, r, req)
+	if !ok {
+		return
+	}
+	p, err := req.Prepare(s.limits())
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "failed to prepare the problem", rest.ErrInternal)
+		return
+	}
+
+	resp := valueResp{X: xs, Interp: kind, Lines: make([]solve.ValueLine, 0, len(sol.Lines))}
+	for _, line := range sol.Lines {
+		resp.Lines = append(resp.Lines, p.ValueOf(line, xs, kind))
+	}
+	if sol.Exact != nil {
+		exact := p.ValueOf(*sol.Exact, xs, kind)
+		resp.Exact = &exact
+	}
+	resp.Took = time.Since(st).String()
+	rest.RenderJSON(w, r, resp)
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func valueURL(ts string, q url.Values) string {
+	return ts + "/api/value?" + strings.ReplaceAll(q.Encode(), "+", "%20")
+}
+
+func TestRest_Value(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	// y' = x^2 - 2y, y = x^2/2 - x/2 + 1/4 + 3/4*exp(-2x)
+	exact := func(x float64) float64 { return x*x/2 - x/2 + 0.25 + 0.75*math.Exp(-2*x) }
+	q := url.Values{"f": {"x^2 - 2*y"}, "exact": {"x^2/2 - x/2 + 1/4 + c*exp(-2*x)"},
+		"c": {"(y0 - x0^2/2 + x0/2 - 1/4)*exp(2*x0)"}, "x0": {"0"}, "y0": {"1"}, "x1": {"1"}, "n": {"20"},
+		"method": {"rk4", "exact"}, "x": {"0.33", "0.5", "1"}}
+	for kind, tol := range map[string]float64{"": 1e-4, "linear": 1e-2, "cubic": 1e-4, "hermite": 1e-6} {
+		q.Set("interp", kind)
+		resp, err := http.Get(valueURL(ts.URL, q))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode, kind)
+		res := valueResp{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+		assert.Equal(t, []float64{0.33, 0.5, 1}, res.X)
+		require.Len(t, res.Lines, 2)
+		for i, x := range res.X {
+			require.NotNil(t, res.Lines[0].Y[i])
+			assert.InDelta(t, exact(x), *res.Lines[0].Y[i], tol, "%s at x=%v", kind, x)
+			assert.InDelta(t, exact(x), *res.Lines[1].Y[i], 1e-12, "the exact solution is evaluated at x=%v", x)
+		}
+	}
+	q.Del("interp")
+
+	// the exact solution, that is not listed in methods, is evaluated as well
+	q["method"] = []string{"euler"}
+	resp, err := http.Get(valueURL(ts.URL, q))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := valueResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.Equal(t, "cubic", res.Interp)
+	require.Len(t, res.Lines, 1)
+	require.NotNil(t, res.Exact)
+	assert.InDelta(t, exact(0.33), *res.Exact.Y[0], 1e-12)
+
+	// the backward solve from x = 1 is evaluated inside [0, 1] as well
+	q.Set("x0", "1")
+	q.Set("y0", strconv.FormatFloat(exact(1), 'g', -1, 64))
+	q.Set("x1", "0")
+	q.Set("interp", "hermite")
+	q["method"] = []string{"rk4"}
+	resp, err = http.Get(valueURL(ts.URL, q))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res = valueResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Lines, 1)
+	for i, x := range res.X {
+		require.NotNil(t, res.Lines[0].Y[i])
+		assert.InDelta(t, exact(x), *res.Lines[0].Y[i], 1e-5, "backward at x=%v", x)
+	}
+}
+
+func TestRest_ValueInvalid(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	base := url.Values{"f": {"y"}, "x0": {"0"}, "y0": {"1"}, "x1": {"1"}, "n": {"10"}, "method": {"rk4"}}
+	tbl := []struct {
+		params url.Values
+		field  string
+		msg    string
+	}{
+		{url.Values{}, "x", "must be set from 1 to 1000 times"},
+		{url.Values{"x": {"2"}}, "x", "2 is out of the interval [0, 1] of the problem"},
+		{url.Values{"x": {"0.5"}, "x1": {"-1"}}, "x", "0.5 is out of the interval [-1, 0] of the problem"},
+		{url.Values{"x": {"0.5"}, "interp": {"quadratic"}}, "interp", `must be linear, cubic or hermite, got "quadratic"`},
+	}
+	for _, tt := range tbl {
+		q := url.Values{}
+		for k, v := range base {
+			q[k] = v
+		}
+		for k, v := range tt.params {
+			q[k] = v
+		}
+		resp, err := http.Get(valueURL(ts.URL, q))
+		require.NoError(t, err)
+		er := rest.ErrorResponse{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&er))
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, tt.msg)
+		assert.Contains(t, er.Errors, rest.FieldError{Field: tt.field, Msg: tt.msg})
+	}
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"context"
+
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/solve"
+)
+
+// warmStart is the last warm solve of the session, the problem of the same key with the longer interval
+// is resumed from checkpoints of its methods
+type warmStart struct {
+	key         string // warm key of the request
+	xEnd        float64
+	checkpoints map[string]solver.Checkpoint // checkpoints of resumable methods, that succeeded
+}
+
+// solveWarm solves the problem, resuming it from the last warm solve of the session, if the problem is
+// the same, but its interval is longer, the cache is not used, as the response depends on the session
+func (s *Rest) solveWarm(ctx context.Context, session string, p solve.Prepared) (solve.Response, error) {
+	key := p.Req.WarmKey(s.limits())
+	p.Warm = &solve.WarmRun{Last: map[string]solver.Checkpoint{}, Next: map[string]solver.Checkpoint{}}
+	if v, ok := s.warm.Get(session); ok && session != "" {
+		if ws := v.(warmStart); ws.key == key && ws.continuedBy(p.Req.X0, p.Req.XEnd) {
+			p.Warm.Resume(p, ws.checkpoints)
+		}
+	}
+
+	resp, err := s.solve(ctx, p)
+	if err != nil {
+		return solve.Response{}, err
+	}
+	if session != "" {
+		s.warm.Put(session, warmStart{key: key, xEnd: p.Req.XEnd, checkpoints: p.Warm.Next})
+	}
+	return resp, nil
+}
+
+// continuedBy checks whether the interval from x0 to xEnd continues the interval of the warm start further
+// in the same direction, backward solves are continued backward only
+func (ws warmStart) continuedBy(x0, xEnd float64) bool {
+	if xEnd < x0 {
+		return xEnd < ws.xEnd && ws.xEnd <= x0
+	}
+	return xEnd > ws.xEnd && ws.xEnd >= x0
+}
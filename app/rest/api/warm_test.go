@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRest_SolveWarm(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	newClient := func() *http.Client {
+		jar, err := cookiejar.New(nil)
+		require.NoError(t, err)
+		return &http.Client{Jar: jar}
+	}
+	doSolve := func(cl *http.Client, y0, xEnd float64, warm bool) solve.Response {
+		body := fmt.Sprintf(`{"f": "y*y*exp(x) - 2*y", "exact": "exp(-x) / (c*exp(x) + 1)",
+			"c": "(exp(-x0) - y0) / (y0 * exp(x0))", "x0": 0, "y0": %v, "x_end": %v, "step": 0.3,
+			"methods": ["rk4", "exact", "euler"], "warm": %t}`, y0, xEnd, warm)
+		resp, err := cl.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		res := solve.Response{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+		return res
+	}
+	// sameAppended checks, that lines of the warm solve are lines of the cold one from the node
+	sameAppended := func(cold, warm solve.Response, node int) {
+		assert.True(t, warm.Append)
+		assert.Equal(t, node, warm.AppendFrom)
+		require.Len(t, warm.Lines, len(cold.Lines))
+		for i, line := range warm.Lines {
+			require.Nil(t, line.Error, line.Method)
+			assert.Equal(t, cold.Lines[i].Points[node:], line.Points, "%s is the same, bit for bit", line.Method)
+			assert.Equal(t, len(line.Points), line.Stats.Points, line.Method)
+		}
+	}
+	// points returns points of lines, as durations of solves differ
+	points := func(resp solve.Response) (res [][]num.Point) {
+		for _, line := range resp.Lines {
+			res = append(res, line.Points)
+		}
+		return res
+	}
+
+	cl := newClient()
+	first := doSolve(cl, 1, 1.1, true)
+	assert.False(t, first.Append, "nothing to resume")
+	assert.Equal(t, points(doSolve(cl, 1, 1.1, false)), points(first))
+
+	// the last step to 1.1 is shortened, so lines are resumed from the node before it
+	sameAppended(doSolve(cl, 1, 2.9, false), doSolve(cl, 1, 2.9, true), 3)
+	sameAppended(doSolve(cl, 1, 4.2, false), doSolve(cl, 1, 4.2, true), 9)
+
+	// the changed y0 busts the warm start
+	cold := doSolve(cl, 0.5, 5, false)
+	changed := doSolve(cl, 0.5, 5, true)
+	assert.False(t, changed.Append)
+	assert.Zero(t, changed.AppendFrom)
+	assert.Equal(t, points(cold), points(changed))
+
+	// the shorter interval and other sessions are solved from x0, 3 is the node of the grid, so the next solve resumes at it
+	assert.False(t, doSolve(cl, 0.5, 3, true).Append)
+	assert.False(t, doSolve(newClient(), 0.5, 6, true).Append)
+	sameAppended(doSolve(cl, 0.5, 3.4, false), doSolve(cl, 0.5, 3.4, true), 10)
+
+	// the backward solve doesn't resume the forward one, but the longer backward solve resumes it
+	assert.False(t, doSolve(cl, 0.5, -1.1, true).Append)
+	sameAppended(doSolve(cl, 0.5, -2.9, false), doSolve(cl, 0.5, -2.9, true), 3)
+	assert.False(t, doSolve(cl, 0.5, 1, true).Append, "the forward solve doesn't resume the backward one")
+}
+
+func TestRest_SolveWarmErrors(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	for _, tt := range []struct{ body, msg string }{
+		{`{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"], "warm": true}`,
+			"must be set with step, as n changes the step with x_end"},
+		{`{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "step": 0.1, "methods": ["rk4"], "warm": true, "save": true}`,
+			"must not be set together with save, as the result has only new points"},
+		{`{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "step": 0.1, "methods": ["rk4"], "warm": true, "sensitivity": true}`,
+			"must not be set together with sensitivity"},
+	} {
+		resp, err := http.Post(ts.URL+"/api/v1/solve", "application/json", strings.NewReader(tt.body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, tt.body)
+		e := rest.ErrorResponse{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&e))
+		assert.Equal(t, rest.ValidationError{{Field: "warm", Msg: tt.msg}}, e.Errors, tt.body)
+	}
+
+	resp, err := http.Post(ts.URL+"/api/v1/solve/batch", "application/json", strings.NewReader(
+		`[{"f": "x", "x0": 0, "y0": 1, "x_end": 1, "step": 0.1, "methods": ["rk4"], "warm": true}]`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	res := batchResp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Len(t, res.Results, 1)
+	require.NotNil(t, res.Results[0].Error)
+	assert.Equal(t, "warm start is not supported in batches", res.Results[0].Error.Error)
+}
@@ -0,0 +1,317 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	log "github.com/go-pkgz/lgr"
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+)
+
+// limits of the websocket connection
+const (
+	wsMaxMessageSize = 64 * 1024 // maximal size of the message from the client in bytes
+	wsWriteWait      = 10 * time.Second
+)
+
+// wsPing is the interval between pings, the connection is closed, if the client doesn't answer in two intervals
+var wsPing = 30 * time.Second
+
+// types of frames, sent by the server
+const (
+	wsPoints    = "points"    // batch of calculated points of the method
+	wsDone      = "done"      // the request is solved
+	wsError     = "error"     // the request is malformed or failed to solve
+	wsCancelled = "cancelled" // the request is superseded by the newer one or cancelled on shutdown
+)
+
+// types of messages, sent by the client
+const (
+	wsSolveMsg  = "solve"  // the problem to solve, the default
+	wsCancelMsg = "cancel" // cancels the request in flight with the id
+)
+
+// wsRequest is the message of the client with the problem to solve
+type wsRequest struct {
+	ID      string        `json:"id"`             // id of the request, the frames of the response are tagged with it
+	Type    string        `json:"type,omitempty"` // solve, if not set, or cancel
+	Request solve.Request `json:"request"`
+}
+
+// wsFrame is the message of the server with the part of the response to the request
+type wsFrame struct {
+	ID     string      `json:"id"`
+	Type   string      `json:"type"`
+	Method string      `json:"method,omitempty"`
+	Points []num.Point `json:"points,omitempty"`
+	// Progress is the percent of the request, solved up to the last point of the frame, by x of all methods
+	Progress float64             `json:"progress,omitempty"`
+	Lines    []solve.LineSummary `json:"lines,omitempty"`
+	Took     string              `json:"took,omitempty"`
+	Error    *rest.ErrorResponse `json:"error,omitempty"`
+}
+
+// GET /api/v1/ws, GET /ws/solve - upgrades the connection to websocket, the client sends problems as {"id": "1", "request": {...}}
+// with the body of POST /api/v1/solve, the server replies with frames, tagged with the id of the request,
+// the newer request cancels the one in flight, as well as {"id": "1", "type": "cancel"}
+func (s *Rest) wsCtrl(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{CheckOrigin: s.checkOrigin}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader has already replied with the error
+		log.Printf("[DEBUG] failed to upgrade connection, %v", err)
+		return
+	}
+	defer conn.Close()
+
+	conn.SetReadLimit(wsMaxMessageSize)
+	ws := &wsConn{conn: conn}
+	ws.keepAlive()
+
+	// the context of the request is not cancelled after the connection is hijacked, except on shutdown
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go ws.ping(ctx, wsPing)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-s.drain.closing:
+			// stops reading of new requests
+			_ = conn.SetReadDeadline(time.Now())
+		}
+	}()
+
+	var inflight *wsSolve
+	defer func() {
+		if !s.drain.shuttingDown() {
+			inflight.stop()
+			return
+		}
+		// the request in flight is finished, unless the drain timeout is exceeded
+		inflight.wait()
+		ws.goAway()
+	}()
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Printf("[DEBUG] websocket connection is closed, %v", err)
+			}
+			return
+		}
+
+		req := wsRequest{}
+		if err = json.Unmarshal(msg, &req); err != nil {
+			ws.fail("", err, "failed to decode message", rest.ErrDecode)
+			continue
+		}
+		if req.ID == "" {
+			ws.fail("", errors.New("id must be set"), "invalid message", rest.ErrBadRequest)
+			continue
+		}
+		switch req.Type {
+		case "", wsSolveMsg:
+		case wsCancelMsg:
+			if !inflight.running(req.ID) {
+				ws.fail(req.ID, errors.Errorf("request %q is not in flight", req.ID), "invalid message", rest.ErrBadRequest)
+				continue
+			}
+			inflight.stop()
+			inflight = nil
+			continue
+		default:
+			ws.fail(req.ID, errors.Errorf("unknown type of the message %q", req.Type), "invalid message", rest.ErrBadRequest)
+			continue
+		}
+
+		// the previous request is stale, even if the newer one is invalid
+		inflight.stop()
+		inflight = nil
+
+		if s.limiter != nil {
+			if ok, _ := s.limiter.Allow(r); !ok {
+				ws.fail(req.ID, errors.New("rate limit exceeded"), "too many requests", rest.ErrBusy)
+				continue
+			}
+		}
+		if req.Request.Save {
+			ws.fail(req.ID, errors.New("saving of results is not supported over websocket"),
+				"invalid solve request", rest.ErrBadRequest)
+			continue
+		}
+		if req.Request.Warm {
+			ws.fail(req.ID, errors.New("warm start is not supported over websocket"),
+				"invalid solve request", rest.ErrBadRequest)
+			continue
+		}
+		if req.Request.AutoRefine != nil {
+			ws.fail(req.ID, errors.New("refinement of grids is not supported over websocket"),
+				"invalid solve request", rest.ErrBadRequest)
+			continue
+		}
+		if req.Request.OutputGrid != nil {
+			ws.fail(req.ID, errors.New("output grid is not supported over websocket, points of steps are streamed"),
+				"invalid solve request", rest.ErrBadRequest)
+			continue
+		}
+		p, err := s.prepare(req.Request)
+		if err != nil {
+			ws.fail(req.ID, err, "invalid solve request", rest.ErrBadRequest)
+			continue
+		}
+		release, err := s.acquire(p)
+		if err != nil {
+			ws.fail(req.ID, err, "too many solves at once", rest.ErrBusy)
+			continue
+		}
+		inflight = s.startWS(ctx, ws, req.ID, p, release)
+	}
+}
+
+// wsSolve is the request, being solved in background
+type wsSolve struct {
+	id     string
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// running checks whether the request with the id is still being solved
+func (ws *wsSolve) running(id string) bool {
+	if ws == nil || ws.id != id {
+		return false
+	}
+	select {
+	case <-ws.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// stop cancels the solve and waits until it stops sending frames
+func (ws *wsSolve) stop() {
+	if ws == nil {
+		return
+	}
+	ws.cancel()
+	<-ws.done
+}
+
+// wait waits until the solve is finished
+func (ws *wsSolve) wait() {
+	if ws == nil {
+		return
+	}
+	<-ws.done
+	ws.cancel()
+}
+
+// startWS solves the problem in background under the timeout of the single request,
+// release is called, when the solve is finished
+func (s *Rest) startWS(ctx context.Context, ws *wsConn, id string, p solve.Prepared, release func()) *wsSolve {
+	ctx, cancel := context.WithCancel(ctx)
+	res := &wsSolve{id: id, cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(res.done)
+		defer release()
+		tctx, tcancel := context.WithTimeout(ctx, s.solveTimeout())
+		defer tcancel()
+
+		summary, err := p.Batches(tctx, func(method string, pts []num.Point, progress float64) error {
+			return ws.send(wsFrame{ID: id, Type: wsPoints, Method: method, Points: pts, Progress: progress})
+		})
+		switch {
+		case err == nil:
+			_ = ws.send(wsFrame{ID: id, Type: wsDone, Progress: 100, Lines: summary.Lines, Took: summary.Took})
+		case ctx.Err() != nil:
+			_ = ws.send(wsFrame{ID: id, Type: wsCancelled})
+		case errors.Is(err, context.DeadlineExceeded):
+			ws.fail(id, errors.Errorf("solve timed out after %s", s.solveTimeout()), "failed to solve in time", rest.ErrInternal)
+		default:
+			ws.fail(id, err, "failed to solve", rest.ErrInternal)
+		}
+	}()
+	return res
+}
+
+// checkOrigin allows connections from the same origin and from the origins, allowed by CORS
+func (s *Rest) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	return (&rest.CORS{AllowedOrigins: s.CORSOrigins}).AllowsOrigin(origin)
+}
+
+// wsConn serializes writes to the websocket connection
+type wsConn struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+// send writes the frame to the connection
+func (ws *wsConn) send(f wsFrame) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	_ = ws.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	if err := ws.conn.WriteJSON(f); err != nil {
+		log.Printf("[DEBUG] failed to send %s frame of %q, %v", f.Type, f.ID, err)
+		return errors.Wrapf(err, "failed to send %s frame", f.Type)
+	}
+	return nil
+}
+
+// fail sends the error frame
+func (ws *wsConn) fail(id string, err error, details string, code rest.ErrCode) {
+	log.Printf("[WARN] websocket request %q: %s, %v", id, details, err)
+	e := rest.NewErrorResponse(err, details, code)
+	_ = ws.send(wsFrame{ID: id, Type: wsError, Error: &e})
+}
+
+// goAway sends the close frame on shutdown
+func (ws *wsConn) goAway() {
+	msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, shutdownMsg)
+	if err := ws.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(wsWriteWait)); err != nil {
+		log.Printf("[DEBUG] failed to send close frame, %v", err)
+	}
+}
+
+// keepAlive extends the read deadline of the connection on each pong
+func (ws *wsConn) keepAlive() {
+	_ = ws.conn.SetReadDeadline(time.Now().Add(2 * wsPing))
+	ws.conn.SetPongHandler(func(string) error {
+		return ws.conn.SetReadDeadline(time.Now().Add(2 * wsPing))
+	})
+}
+
+// ping writes pings to the connection with the given interval until the context is done
+func (ws *wsConn) ping(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ws.mu.Lock()
+			err := ws.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait))
+			ws.mu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}
+}
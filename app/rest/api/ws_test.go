@@ -0,0 +1,272 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Semior001/decompract/app/num/service"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dialWS opens the websocket connection to the server
+func dialWS(t *testing.T, ts *httptest.Server) *websocket.Conn {
+	return dialWSPath(t, ts, "/api/v1/ws")
+}
+
+// dialWSPath opens the websocket connection to the path of the server
+func dialWSPath(t *testing.T, ts *httptest.Server, path string) *websocket.Conn {
+	conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+path, nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readFrame reads the next frame from the connection
+func readFrame(t *testing.T, conn *websocket.Conn) wsFrame {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	f := wsFrame{}
+	require.NoError(t, conn.ReadJSON(&f))
+	return f
+}
+
+func TestRest_WS(t *testing.T) {
+	for _, path := range []string{"/api/v1/ws", "/ws/solve"} {
+		t.Run(path, func(t *testing.T) { testWS(t, path) })
+	}
+}
+
+func testWS(t *testing.T, path string) {
+	_, ts := prepTestServer(t)
+	conn := dialWSPath(t, ts, path)
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"id": "a", "request": {
+		"f": "y*y*exp(x) - 2*y", "exact": "exp(-x) / (c*exp(x) + 1)", "c": "(exp(-x0) - y0) / (y0 * exp(x0))",
+		"x0": 0, "y0": 1, "x_end": 1, "n": 250, "methods": ["rk4", "euler"]}}`)))
+
+	points := map[string]int{}
+	var methods []string
+	progress := 0.0
+	f := readFrame(t, conn)
+	for ; f.Type == wsPoints; f = readFrame(t, conn) {
+		assert.Equal(t, "a", f.ID)
+		assert.True(t, f.Progress > progress && f.Progress <= 100, "progress %v after %v", f.Progress, progress)
+		progress = f.Progress
+		assert.True(t, len(f.Points) <= solve.BatchSize, "points must be sent in batches")
+		if len(methods) == 0 || methods[len(methods)-1] != f.Method {
+			methods = append(methods, f.Method)
+		}
+		points[f.Method] += len(f.Points)
+	}
+
+	require.Equal(t, wsDone, f.Type, "%+v", f)
+	assert.Equal(t, "a", f.ID)
+	assert.InDelta(t, 100, progress, 1e-9, "the last batch ends the last method")
+	assert.Equal(t, 100.0, f.Progress)
+	assert.Equal(t, []string{"rk4", "euler", "exact"}, methods, "methods must be solved in order")
+	require.Len(t, f.Lines, 3)
+	for _, line := range f.Lines {
+		assert.True(t, line.Points >= 250, line.Method)
+		assert.Equal(t, line.Points, points[line.Method], line.Method)
+	}
+	assert.NotEmpty(t, f.Took)
+}
+
+func TestRest_WSCancel(t *testing.T) {
+	var calls int32
+	solver.Register("slow", func(f solver.Func) solver.Interface {
+		return &solver.Euler{F: func(x, y float64) (float64, error) {
+			atomic.AddInt32(&calls, 1)
+			time.Sleep(time.Millisecond)
+			return f(x, y)
+		}}
+	})
+	defer solver.Unregister("slow")
+
+	srv, ts := prepTestServer(t)
+	srv.Limits.MaxSteps = 100000
+	conn := dialWS(t, ts)
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage,
+		[]byte(`{"id": "1", "request": {"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 100000, "methods": ["slow"]}}`)))
+	f := readFrame(t, conn)
+	require.Equal(t, wsPoints, f.Type, "%+v", f)
+	assert.Equal(t, "1", f.ID)
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage,
+		[]byte(`{"id": "2", "request": {"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"]}}`)))
+
+	// the superseded request is finished with the cancelled frame before the frames of the newer one
+	for f = readFrame(t, conn); f.Type == wsPoints; f = readFrame(t, conn) {
+		assert.Equal(t, "1", f.ID)
+	}
+	assert.Equal(t, wsCancelled, f.Type)
+	assert.Equal(t, "1", f.ID)
+
+	for f = readFrame(t, conn); f.Type == wsPoints; f = readFrame(t, conn) {
+		assert.Equal(t, "2", f.ID)
+	}
+	assert.Equal(t, wsDone, f.Type)
+	assert.Equal(t, "2", f.ID)
+
+	stopped := atomic.LoadInt32(&calls)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, stopped, atomic.LoadInt32(&calls), "superseded solve must be stopped")
+	assert.True(t, stopped < 100000)
+}
+
+func TestRest_WSCancelMessage(t *testing.T) {
+	solver.Register("slow", func(f solver.Func) solver.Interface {
+		return &solver.Euler{F: func(x, y float64) (float64, error) {
+			time.Sleep(time.Millisecond)
+			return f(x, y)
+		}}
+	})
+	defer solver.Unregister("slow")
+
+	srv, ts := prepTestServer(t)
+	srv.Limits.MaxSteps = 100000
+	conn := dialWS(t, ts)
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage,
+		[]byte(`{"id": "1", "request": {"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 100000, "methods": ["slow"]}}`)))
+	f := readFrame(t, conn)
+	require.Equal(t, wsPoints, f.Type, "%+v", f)
+	assert.Less(t, f.Progress, 1.0)
+
+	// the cancel of another id is refused, the request goes on
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"id": "2", "type": "cancel"}`)))
+	for f = readFrame(t, conn); f.Type == wsPoints; f = readFrame(t, conn) {
+		assert.Equal(t, "1", f.ID)
+	}
+	require.Equal(t, wsError, f.Type)
+	assert.Equal(t, "2", f.ID)
+	assert.Equal(t, `request "2" is not in flight`, f.Error.Error)
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"id": "1", "type": "cancel"}`)))
+	for f = readFrame(t, conn); f.Type == wsPoints; f = readFrame(t, conn) {
+		assert.Equal(t, "1", f.ID)
+	}
+	assert.Equal(t, wsCancelled, f.Type)
+	assert.Equal(t, "1", f.ID)
+
+	// the cancelled request is not in flight anymore
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"id": "1", "type": "cancel"}`)))
+	f = readFrame(t, conn)
+	require.Equal(t, wsError, f.Type)
+	assert.Equal(t, `request "1" is not in flight`, f.Error.Error)
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"id": "3", "type": "pause"}`)))
+	f = readFrame(t, conn)
+	require.Equal(t, wsError, f.Type)
+	assert.Equal(t, `unknown type of the message "pause"`, f.Error.Error)
+}
+
+func TestRest_WSErrors(t *testing.T) {
+	solver.Register("sleepy", func(solver.Func) solver.Interface { return &solver.Euler{F: sleepyFunc(5 * time.Millisecond)} })
+	defer solver.Unregister("sleepy")
+	breakMethod(t)
+
+	srv, ts := prepTestServer(t)
+	srv.SolveTimeout = 100 * time.Millisecond
+	ts.Config.Handler = srv.routes()
+	conn := dialWS(t, ts)
+
+	tbl := []struct {
+		msg  string
+		id   string
+		code rest.ErrCode
+		err  string
+	}{
+		{msg: `{"id": "1", "request": `, code: rest.ErrDecode, err: "unexpected end of JSON input"},
+		{msg: `{"request": {"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"]}}`,
+			code: rest.ErrBadRequest, err: "id must be set"},
+		{msg: `{"id": "2", "request": {"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 100000, "methods": ["rk4"]}}`,
+			id: "2", code: rest.ErrBadRequest, err: "n: must be between 1 and max_steps=10000"},
+		{msg: `{"id": "3", "request": {"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"], "save": true}}`,
+			id: "3", code: rest.ErrBadRequest, err: "saving of results is not supported over websocket"},
+		{msg: `{"id": "4", "request": {"f": "x", "x0": 1, "y0": 1, "x_end": 2, "n": 1, "methods": ["broken"]}}`,
+			id: "4", code: rest.ErrInternal, err: "failed to solve with broken"},
+		{msg: `{"id": "5", "request": {"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 1000, "methods": ["sleepy"]}}`,
+			id: "5", code: rest.ErrInternal, err: "solve timed out after 100ms"},
+	}
+
+	for _, tt := range tbl {
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(tt.msg)))
+		f := readFrame(t, conn)
+		for f.Type == wsPoints {
+			f = readFrame(t, conn)
+		}
+		require.Equal(t, wsError, f.Type, tt.msg)
+		assert.Equal(t, tt.id, f.ID, tt.msg)
+		require.NotNil(t, f.Error, tt.msg)
+		assert.Equal(t, tt.code, f.Error.Code, tt.msg)
+		assert.Contains(t, f.Error.Error, tt.err, tt.msg)
+	}
+
+	// the connection is still usable after errors
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage,
+		[]byte(`{"id": "6", "request": {"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"]}}`)))
+	f := readFrame(t, conn)
+	for f.Type == wsPoints {
+		f = readFrame(t, conn)
+	}
+	assert.Equal(t, wsDone, f.Type)
+	assert.Equal(t, "6", f.ID)
+}
+
+func TestRest_WSRateLimit(t *testing.T) {
+	srv, ts := prepTestServer(t)
+	srv.RateLimit, srv.RateBurst = 0.001, 2
+	ts.Config.Handler = srv.routes()
+	conn := dialWS(t, ts) // the upgrade takes the first token
+
+	msg := []byte(`{"id": "1", "request": {"f": "x", "x0": 0, "y0": 1, "x_end": 1, "n": 10, "methods": ["rk4"]}}`)
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, msg))
+	f := readFrame(t, conn)
+	for f.Type == wsPoints {
+		f = readFrame(t, conn)
+	}
+	assert.Equal(t, wsDone, f.Type)
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, msg))
+	f = readFrame(t, conn)
+	require.Equal(t, wsError, f.Type)
+	assert.Equal(t, "1", f.ID)
+	assert.Equal(t, "rate limit exceeded", f.Error.Error)
+	assert.Equal(t, rest.ErrBusy, f.Error.Code, "the client might retry later")
+}
+
+func TestRest_WSOrigin(t *testing.T) {
+	_, ts := prepTestServer(t)
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/api/v1/ws"
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": {"https://example.com"}})
+	require.Error(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode, "other origins must be rejected")
+	resp.Body.Close()
+
+	conn, resp, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": {ts.URL}})
+	require.NoError(t, err, "the same origin must be allowed")
+	resp.Body.Close()
+	conn.Close()
+
+	// the handler of the closed connection might be still running, so the routes are not rebuilt
+	srv := &Rest{Version: "test", NumService: &service.Service{}, CORSOrigins: []string{"https://example.com"}}
+	cts := httptest.NewServer(srv.routes())
+	defer cts.Close()
+	url = "ws" + strings.TrimPrefix(cts.URL, "http") + "/api/v1/ws"
+	conn, resp, err = websocket.DefaultDialer.Dial(url, http.Header{"Origin": {"https://example.com"}})
+	require.NoError(t, err, "origins, allowed by CORS, must be allowed")
+	resp.Body.Close()
+	conn.Close()
+}
@@ -0,0 +1,237 @@
+package api
+
+import (
+	"context"
+	"math"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/analyzer"
+	"github.com/Semior001/decompract/app/num/export"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/Semior001/decompract/app/solve"
+	log "github.com/go-pkgz/lgr"
+	"github.com/pkg/errors"
+)
+
+// workbook is the validated problem of the xlsx export, ready to run
+type workbook struct {
+	p       solve.Prepared
+	sw      solve.Sweep // sweep of the convergence, it gives the exact solution of the problem too
+	workers int         // methods, solved at once, GOMAXPROCS if not positive
+}
+
+// methodErrors is the solution of the method with local and global errors at its nodes
+type methodErrors struct {
+	method, name string
+	step         float64
+	points       []num.Point
+	local        []num.Point
+	global       []num.Point
+	err          error // describes why the method failed, errors are missing then
+}
+
+// prepareWorkbook validates the problem under the limits, the exact solution is required, as errors are measured
+// against it, it isn't exported as the method, numbers of steps of the convergence are doubled from n0 until n1
+func prepareWorkbook(req solve.Request, n0, n1 int, l solve.Limits) (workbook, error) {
+	var methods []string
+	for _, m := range req.Methods {
+		if m != solve.ExactMethod {
+			methods = append(methods, m)
+		}
+	}
+	switch {
+	case req.Exact == "" && req.Linear == nil:
+		return workbook{}, rest.ValidationError{{Field: "exact", Msg: "must be set, as errors of methods are exported"}}
+	case len(methods) == 0:
+		return workbook{}, rest.ValidationError{{Field: "methods", Msg: "must have a method besides the exact solution"}}
+	}
+	req.Methods = methods
+
+	p, err := req.Prepare(l)
+	if err != nil {
+		return workbook{}, err
+	}
+	sw, err := solve.SweepReq{Request: req, N0: n0, N1: n1, Geometric: true}.Prepare(l)
+	if err != nil {
+		return workbook{}, err
+	}
+	return workbook{p: p, sw: sw}, nil
+}
+
+// solve solves the problem with each method by workers of the workbook and measures its errors, the failure
+// of the method is reported in its errors, the workbook fails only if all methods fail
+func (wb workbook) solve(ctx context.Context) ([]methodErrors, error) {
+	ref, err := wb.sw.Reference(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]methodErrors, len(wb.p.Methods))
+	err = solve.ForEach(ctx, len(wb.p.Methods), wb.workers, func(ctx context.Context, i int) (err error) {
+		res[i], err = wb.solveWith(ctx, wb.p.Methods[i], ref)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, me := range res {
+		if me.err == nil {
+			return res, nil
+		}
+	}
+	return nil, errors.Wrap(res[0].err, "all methods failed")
+}
+
+// solveWith solves the problem with the method and measures global errors of its solution and local errors
+// of its steps, each step is made from the exact solution at the previous node
+func (wb workbook) solveWith(ctx context.Context, method string, exact analyzer.Exact) (methodErrors, error) {
+	slvr := solve.Builder(method)(wb.sw.F)
+	x0, y0, xEnd := wb.p.Req.X0, wb.p.Req.Y0, wb.p.Req.XEnd
+	me := methodErrors{method: method, name: slvr.Name(), step: wb.p.StepOf(method)}
+
+	c := solve.Collector(me.step, x0, xEnd)
+	err := slvr.Solve(me.step, x0, y0, xEnd, solve.WithRequest(ctx, c))
+	if err == nil {
+		err = solve.Solved(c)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return methodErrors{}, &solve.TimeoutError{Method: method, XReached: solve.LastX(c.Points, x0)}
+	}
+	if ctx.Err() != nil {
+		return methodErrors{}, ctx.Err()
+	}
+	if err != nil {
+		me.err = errors.Wrapf(err, "failed to solve with %s", method)
+		return me, nil
+	}
+
+	me.points = c.Points
+	if me.global, err = analyzer.Global(c.Points, exact); err != nil {
+		me.err = errors.Wrapf(err, "failed to calculate global errors of %s", method)
+		return me, nil
+	}
+	if me.local, err = analyzer.Local(slvr, exact, c.Points); err != nil {
+		me.err = errors.Wrapf(err, "failed to calculate local errors of %s", method)
+	}
+	return me, nil
+}
+
+// workbookSheets returns the summary sheet with max errors and orders of methods, followed by the sheet of each
+// method, that didn't fail, with x, y, local and global errors at nodes
+func workbookSheets(res []methodErrors, conv solve.ErrorsResp) []export.Sheet {
+	orders := map[string]float64{}
+	for _, line := range conv.Lines {
+		if line.Order != nil {
+			orders[line.Method] = *line.Order
+		}
+	}
+
+	summary := export.Sheet{Name: "summary",
+		Header: []string{"method", "name", "step", "max_local_error", "max_global_error", "order", "error"}}
+	var sheets []export.Sheet
+	for _, me := range res {
+		order, ok := orders[me.method]
+		if !ok {
+			order = math.NaN()
+		}
+		row := []export.Cell{export.Text(me.method), export.Text(me.name), export.Number(me.step)}
+		if me.err != nil {
+			nan := export.Number(math.NaN())
+			summary.Rows = append(summary.Rows, append(row, nan, nan, export.Number(order), export.Text(me.err.Error())))
+			continue
+		}
+		summary.Rows = append(summary.Rows, append(row, export.Number(maxY(me.local)), export.Number(maxY(me.global)),
+			export.Number(order)))
+
+		sh := export.Sheet{Name: me.method, Header: []string{"x", "y", "local_error", "global_error"}}
+		for i, pt := range me.points {
+			sh.Rows = append(sh.Rows, []export.Cell{export.Number(pt.X), export.Number(pt.Y),
+				export.Number(me.local[i].Y), export.Number(me.global[i].Y)})
+		}
+		sheets = append(sheets, sh)
+	}
+	return append([]export.Sheet{summary}, sheets...)
+}
+
+// GET /api/export.xlsx?f=y&exact=c*exp(x)&c=y0/exp(x0)&x0=0&y0=1&x1=1&n=10&method=euler&method=rk4 - solve
+// the problem and download the workbook with the summary sheet of max errors and orders of methods, and the sheet
+// of each method with x, y, local and global errors, numbers of steps of orders are doubled from n0 until n1
+func (s *Rest) exportXLSXCtrl(w http.ResponseWriter, r *http.Request) {
+	req, ok := readGetSolve(w, r)
+	if !ok {
+		return
+	}
+	n0, err := queryInt(r, "n0", defaultSweepN0)
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "failed to read query parameters", rest.ErrDecode)
+		return
+	}
+	n1, err := queryInt(r, "n1", defaultSweepN1)
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "failed to read query parameters", rest.ErrDecode)
+		return
+	}
+
+	wb, err := prepareWorkbook(req, n0, n1, s.limits())
+	if err != nil {
+		rest.SendErrorJSON(w, r, http.StatusBadRequest, err, "invalid export request", rest.ErrBadRequest)
+		return
+	}
+	wb.workers, wb.sw.Workers = s.SweepWorkers, s.SweepWorkers
+
+	res, err := s.solveWorkbook(r.Context(), wb)
+	var conv solve.ErrorsResp
+	if err == nil {
+		conv, err = s.sweepCached(r.Context(), w, wb.sw)
+	}
+	if err != nil {
+		var te *solve.TimeoutError
+		if errors.As(err, &te) {
+			s.sendTimeout(w, r, te)
+			return
+		}
+		var be *busyError
+		if errors.As(err, &be) {
+			sendBusy(w, r, be)
+			return
+		}
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "failed to export", rest.ErrInternal)
+		return
+	}
+
+	buf := rest.GetBuffer()
+	defer rest.PutBuffer(buf)
+	if err = (export.Workbook{Sheets: workbookSheets(res, conv)}).Write(buf); err != nil {
+		rest.SendErrorJSON(w, r, http.StatusInternalServerError, err, "can't write workbook", rest.ErrInternal)
+		return
+	}
+
+	name := strings.TrimSuffix(csvFilename(req.F), ".csv") + ".xlsx"
+	w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	if _, err = w.Write(buf.Bytes()); err != nil {
+		log.Printf("[WARN] failed to write workbook, %v", err)
+	}
+}
+
+// solveWorkbook solves methods of the workbook under limits of the server
+func (s *Rest) solveWorkbook(ctx context.Context, wb workbook) ([]methodErrors, error) {
+	release, err := s.acquire(wb.p)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return wb.solve(ctx)
+}
+
+// maxY returns the max y of points, zero for no points
+func maxY(pts []num.Point) float64 {
+	res := 0.0
+	for _, p := range pts {
+		res = math.Max(res, p.Y)
+	}
+	return res
+}
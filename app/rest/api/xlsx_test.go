@@ -0,0 +1,97 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// getWorkbook downloads the workbook and returns xml of its sheets by their paths in the package
+func getWorkbook(t *testing.T, base string, q url.Values) (*http.Response, map[string]string) {
+	resp, err := http.Get(base + "/api/export.xlsx?" + strings.ReplaceAll(q.Encode(), "+", "%20"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	if resp.StatusCode != http.StatusOK {
+		return resp, map[string]string{"body": string(body)}
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	require.NoError(t, err)
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		files[f.Name] = string(b)
+	}
+	return resp, files
+}
+
+func TestRest_ExportXLSX(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	q := url.Values{"f": {"y"}, "exact": {"c*exp(x)"}, "c": {"y0/exp(x0)"}, "x0": {"0"}, "y0": {"1"}, "x1": {"1"},
+		"n": {"10"}, "n0": {"10"}, "n1": {"80"}, "methods": {"euler,rk4,exact"}}
+	resp, files := getWorkbook(t, ts.URL, q)
+	require.Equal(t, http.StatusOK, resp.StatusCode, files["body"])
+	assert.Equal(t, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "attachment; filename=solution-y.xlsx", resp.Header.Get("Content-Disposition"))
+
+	assert.Contains(t, files["xl/workbook.xml"], `<sheet name="summary" sheetId="1" r:id="rId1"/>`+
+		`<sheet name="euler" sheetId="2" r:id="rId2"/><sheet name="rk4" sheetId="3" r:id="rId3"/></sheets>`)
+
+	// orders of convergence are in the summary
+	summary := files["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, summary, `<t xml:space="preserve">max_local_error</t>`)
+	for row, expected := range map[string]float64{"2": 1, "3": 4} {
+		m := regexp.MustCompile(`<c r="F` + row + `"><v>([^<]+)</v>`).FindStringSubmatch(summary)
+		require.Len(t, m, 2, summary)
+		order, err := strconv.ParseFloat(m[1], 64)
+		require.NoError(t, err)
+		assert.InDelta(t, expected, order, 0.1, row)
+	}
+
+	// euler makes y(0.1) = 1.1, local and global errors of the first step are the same
+	euler := files["xl/worksheets/sheet2.xml"]
+	assert.Contains(t, euler, `<row r="2"><c r="A2"><v>0</v></c><c r="B2"><v>1</v></c><c r="C2"><v>0</v></c><c r="D2"><v>0</v></c></row>`)
+	m := regexp.MustCompile(`<row r="3"><c r="A3"><v>0.1</v></c><c r="B3"><v>1.1</v></c>` +
+		`<c r="C3"><v>([^<]+)</v></c><c r="D3"><v>([^<]+)</v></c></row>`).FindStringSubmatch(euler)
+	require.Len(t, m, 3, euler)
+	assert.Equal(t, m[1], m[2])
+	assert.Equal(t, 12, strings.Count(euler, "<row "), "header and 11 nodes")
+}
+
+func TestRest_ExportXLSXInvalid(t *testing.T) {
+	_, ts := prepTestServer(t)
+
+	q := url.Values{"f": {"y"}, "x0": {"0"}, "y0": {"1"}, "x1": {"1"}, "n": {"10"}, "methods": {"euler"}}
+	resp, files := getWorkbook(t, ts.URL, q)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, files["body"], "must be set, as errors of methods are exported")
+
+	q.Set("exact", "c*exp(x)")
+	q.Set("c", "y0/exp(x0)")
+	q.Set("methods", "exact")
+	resp, files = getWorkbook(t, ts.URL, q)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, files["body"], "must have a method besides the exact solution")
+
+	q.Set("methods", "euler")
+	q.Set("n0", "0")
+	resp, files = getWorkbook(t, ts.URL, q)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, files["body"], "n0: must be positive, got 0")
+}
@@ -0,0 +1,108 @@
+package rest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type apiKeyCtxKey struct{}
+
+// APIKey is the key, allowed to call the protected routes, only the hash of the key is kept
+type APIKey struct {
+	Hash  string  // hex-encoded sha256 of the key
+	Rate  float64 // requests per second with the key, overrides the limit by client's address if positive
+	Burst int     // requests with the key at once
+}
+
+// ParseAPIKey parses the key in the form hash[:rate[:burst]]
+func ParseAPIKey(s string) (APIKey, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) > 3 {
+		return APIKey{}, errors.Errorf("too many parts in api key %q", s)
+	}
+
+	key := APIKey{Hash: strings.ToLower(parts[0])}
+	if b, err := hex.DecodeString(key.Hash); err != nil || len(b) != sha256.Size {
+		return APIKey{}, errors.Errorf("hash of api key must be hex-encoded sha256, got %q", parts[0])
+	}
+
+	var err error
+	if len(parts) > 1 {
+		if key.Rate, err = strconv.ParseFloat(parts[1], 64); err != nil || key.Rate <= 0 {
+			return APIKey{}, errors.Errorf("rate of api key must be positive number, got %q", parts[1])
+		}
+		key.Burst = 1
+	}
+	if len(parts) > 2 {
+		if key.Burst, err = strconv.Atoi(parts[2]); err != nil || key.Burst < 1 {
+			return APIKey{}, errors.Errorf("burst of api key must be positive integer, got %q", parts[2])
+		}
+	}
+	return key, nil
+}
+
+// HashAPIKey returns the hash of the key, as it is kept in APIKey
+func HashAPIKey(key string) string {
+	h := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(h[:])
+}
+
+// APIKeyFrom returns the key, the request is authorized with
+func APIKeyFrom(r *http.Request) (APIKey, bool) {
+	key, ok := r.Context().Value(apiKeyCtxKey{}).(APIKey)
+	return key, ok
+}
+
+// APIKeys requires one of the keys for the requests, the key is taken from the "Authorization: Bearer" header
+// or the api_key query parameter, the requests without key are unauthorized (401) and the requests with
+// unknown key are forbidden (403), all requests are allowed, if there are no keys
+type APIKeys struct {
+	Keys []APIKey
+}
+
+// Handler checks the key of the request and puts it to the context of the request
+func (a *APIKeys) Handler(next http.Handler) http.Handler {
+	if len(a.Keys) == 0 {
+		return next
+	}
+	keys := make(map[string]APIKey, len(a.Keys))
+	for _, k := range a.Keys {
+		keys[strings.ToLower(k.Hash)] = k
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, err := apiKeyToken(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="decompract"`)
+			SendErrorJSON(w, r, http.StatusUnauthorized, err, "api key is required", ErrUnauthorized)
+			return
+		}
+		key, ok := keys[HashAPIKey(token)]
+		if !ok {
+			SendErrorJSON(w, r, http.StatusForbidden, errors.New("unknown api key"), "api key is not accepted", ErrForbidden)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiKeyCtxKey{}, key)))
+	})
+}
+
+// apiKeyToken returns the key, presented by the request
+func apiKeyToken(r *http.Request) (string, error) {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		const prefix = "bearer "
+		if len(auth) <= len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+			return "", errors.New("authorization header must be bearer token")
+		}
+		return strings.TrimSpace(auth[len(prefix):]), nil
+	}
+	if key := r.URL.Query().Get("api_key"); key != "" {
+		return key, nil
+	}
+	return "", errors.New("no api key")
+}
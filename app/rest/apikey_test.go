@@ -0,0 +1,108 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeys_Handler(t *testing.T) {
+	var keys []APIKey
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, ok := APIKeyFrom(r)
+		require.True(t, ok)
+		keys = append(keys, key)
+	})
+	a := &APIKeys{Keys: []APIKey{{Hash: HashAPIKey("secret"), Rate: 1, Burst: 2}, {Hash: strings.ToUpper(HashAPIKey("other"))}}}
+	h := a.Handler(next)
+
+	do := func(target, auth string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	tbl := []struct {
+		target, auth string
+		status       int
+	}{
+		{target: "/api/v1/solve", status: http.StatusUnauthorized},
+		{target: "/api/v1/solve", auth: "Basic c2VjcmV0", status: http.StatusUnauthorized},
+		{target: "/api/v1/solve", auth: "Bearer", status: http.StatusUnauthorized},
+		{target: "/api/v1/solve", auth: "Bearer wrong", status: http.StatusForbidden},
+		{target: "/api/v1/solve?api_key=wrong", status: http.StatusForbidden},
+		{target: "/api/v1/solve?api_key=secret", auth: "Bearer wrong", status: http.StatusForbidden},
+		{target: "/api/v1/solve", auth: "Bearer secret", status: http.StatusOK},
+		{target: "/api/v1/solve", auth: "bearer other", status: http.StatusOK},
+		{target: "/api/v1/solve?api_key=secret", status: http.StatusOK},
+	}
+	for _, tt := range tbl {
+		rec := do(tt.target, tt.auth)
+		assert.Equal(t, tt.status, rec.Code, "%s %s", tt.target, tt.auth)
+		if tt.status == http.StatusUnauthorized {
+			assert.Equal(t, `Bearer realm="decompract"`, rec.Header().Get("WWW-Authenticate"))
+			assert.Contains(t, rec.Body.String(), `"code":4`)
+		}
+		if tt.status == http.StatusForbidden {
+			assert.Contains(t, rec.Body.String(), `"code":5`)
+		}
+	}
+
+	require.Len(t, keys, 3)
+	assert.Equal(t, 1.0, keys[0].Rate)
+	assert.Zero(t, keys[1].Rate)
+	assert.Equal(t, keys[0], keys[2])
+}
+
+func TestAPIKeys_NoKeys(t *testing.T) {
+	called := false
+	h := (&APIKeys{}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		_, ok := APIKeyFrom(r)
+		assert.False(t, ok)
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/solve?api_key=any", nil))
+	assert.True(t, called)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestParseAPIKey(t *testing.T) {
+	hash := HashAPIKey("secret")
+	tbl := []struct {
+		s   string
+		key APIKey
+		err string
+	}{
+		{s: hash, key: APIKey{Hash: hash}},
+		{s: " " + strings.ToUpper(hash) + " ", key: APIKey{Hash: hash}},
+		{s: hash + ":2.5", key: APIKey{Hash: hash, Rate: 2.5, Burst: 1}},
+		{s: hash + ":2:10", key: APIKey{Hash: hash, Rate: 2, Burst: 10}},
+		{s: "secret", err: "hash of api key must be hex-encoded sha256"},
+		{s: hash[:10], err: "hash of api key must be hex-encoded sha256"},
+		{s: hash + ":0", err: "rate of api key must be positive number"},
+		{s: hash + ":abc", err: "rate of api key must be positive number"},
+		{s: hash + ":1:0", err: "burst of api key must be positive integer"},
+		{s: hash + ":1:2:3", err: "too many parts"},
+	}
+	for _, tt := range tbl {
+		key, err := ParseAPIKey(tt.s)
+		if tt.err != "" {
+			assert.Error(t, err, tt.s)
+			if err != nil {
+				assert.Contains(t, err.Error(), tt.err, tt.s)
+			}
+			continue
+		}
+		require.NoError(t, err, tt.s)
+		assert.Equal(t, tt.key, key, tt.s)
+	}
+}
@@ -0,0 +1,61 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/go-chi/render"
+	log "github.com/go-pkgz/lgr"
+)
+
+// maxPooledBuffer is the capacity of the buffer, above which the buffer is not returned to the pool,
+// so the single huge response doesn't stay in memory
+const maxPooledBuffer = 8 << 20
+
+// buffers are reused by responses of handlers
+var buffers = sync.Pool{New: func() interface{} { return &bytes.Buffer{} }}
+
+// GetBuffer returns the empty buffer from the pool, it must be returned by PutBuffer,
+// when its content is written and not used anymore
+func GetBuffer() *bytes.Buffer {
+	b := buffers.Get().(*bytes.Buffer)
+	b.Reset()
+	return b
+}
+
+// PutBuffer returns the buffer to the pool
+func PutBuffer(b *bytes.Buffer) {
+	if b.Cap() > maxPooledBuffer {
+		return
+	}
+	buffers.Put(b)
+}
+
+// RenderJSON encodes v as json to the response with the status, set by render.Status, as render.JSON does,
+// but the response is encoded into the buffer from the pool
+func RenderJSON(w http.ResponseWriter, r *http.Request, v interface{}) {
+	renderJSON(w, r, v, "application/json; charset=utf-8")
+}
+
+// renderJSON encodes v as json to the response with the content type
+func renderJSON(w http.ResponseWriter, r *http.Request, v interface{}, contentType string) {
+	buf := GetBuffer()
+	defer PutBuffer(buf)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(true)
+	if err := enc.Encode(v); err != nil {
+		// the error response is always encodable, so it doesn't recurse
+		SendErrorJSON(w, r, http.StatusInternalServerError, err, "can't encode response", ErrInternal)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if status, ok := r.Context().Value(render.StatusCtxKey).(int); ok {
+		w.WriteHeader(status)
+	}
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		log.Printf("[WARN] failed to write json response, %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package rest
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderJSON(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("large") != "" {
+			RenderJSON(w, r, map[string]string{"data": strings.Repeat("x", 10000)})
+			return
+		}
+		render.Status(r, http.StatusCreated)
+		RenderJSON(w, r, map[string]string{"id": "<1>"})
+	})
+
+	// the small response is encoded after the large one into the same buffer
+	for _, tt := range []struct {
+		query, body string
+		status      int
+	}{
+		{"large=1", `{"data":"` + strings.Repeat("x", 10000) + `"}` + "\n", http.StatusOK},
+		{"", `{"id":"\u003c1\u003e"}` + "\n", http.StatusCreated}, // html is escaped, as by render.JSON
+		{"", `{"id":"\u003c1\u003e"}` + "\n", http.StatusCreated},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil))
+		assert.Equal(t, tt.status, rec.Code)
+		assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Equal(t, tt.body, rec.Body.String())
+	}
+
+	rec := httptest.NewRecorder()
+	RenderJSON(rec, httptest.NewRequest(http.MethodGet, "/", nil), map[string]interface{}{"f": math.Inf(1)})
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), `"details":"can't encode response"`)
+}
+
+func TestGetBuffer(t *testing.T) {
+	b := GetBuffer()
+	b.WriteString("previous response")
+	PutBuffer(b)
+	for i := 0; i < 10; i++ {
+		b = GetBuffer()
+		require.Zero(t, b.Len(), "buffers from the pool are empty")
+		PutBuffer(b)
+	}
+}
@@ -0,0 +1,97 @@
+package rest
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRU is an in-memory cache, that evicts the least recently used entries,
+// when the number of entries exceeds the limit, it is safe for concurrent use
+type LRU struct {
+	MaxEntries int           // maximal number of entries, unlimited if zero
+	TTL        time.Duration // time to live of the entry, unlimited if zero
+
+	mu      sync.Mutex
+	ll      *list.List
+	entries map[string]*list.Element
+	now     func() time.Time
+}
+
+type lruEntry struct {
+	key     string
+	value   interface{}
+	expires time.Time
+}
+
+// Get returns the value by the key, if it is present and not expired
+func (c *LRU) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*lruEntry)
+	if !e.expires.IsZero() && !c.timeNow().Before(e.expires) {
+		c.remove(el)
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return e.value, true
+}
+
+// Put stores the value by the key, evicting the least recently used entry, if the cache is full
+func (c *LRU) Put(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = map[string]*list.Element{}
+		c.ll = list.New()
+	}
+
+	var expires time.Time
+	if c.TTL > 0 {
+		expires = c.timeNow().Add(c.TTL)
+	}
+
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*lruEntry)
+		e.value, e.expires = value, expires
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.entries[key] = c.ll.PushFront(&lruEntry{key: key, value: value, expires: expires})
+	if c.MaxEntries > 0 && c.ll.Len() > c.MaxEntries {
+		c.remove(c.ll.Back())
+	}
+}
+
+// Purge removes all entries from the cache
+func (c *LRU) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries, c.ll = nil, nil
+}
+
+// Len returns the number of entries in the cache, including expired ones
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+func (c *LRU) remove(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.entries, el.Value.(*lruEntry).key)
+}
+
+func (c *LRU) timeNow() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
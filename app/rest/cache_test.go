@@ -0,0 +1,72 @@
+package rest
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU_Eviction(t *testing.T) {
+	c := &LRU{MaxEntries: 2}
+
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+
+	c.Put("a", 1)
+	c.Put("b", 2)
+	v, ok := c.Get("a") // "b" becomes the least recently used
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	c.Put("c", 3)
+	assert.Equal(t, 2, c.Len())
+	_, ok = c.Get("b")
+	assert.False(t, ok, "least recently used entry must be evicted")
+
+	c.Put("a", 10)
+	v, _ = c.Get("a")
+	assert.Equal(t, 10, v)
+	v, _ = c.Get("c")
+	assert.Equal(t, 3, v)
+
+	c.Purge()
+	assert.Equal(t, 0, c.Len())
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+}
+
+func TestLRU_TTL(t *testing.T) {
+	now := time.Date(2020, 11, 7, 0, 0, 0, 0, time.UTC)
+	c := &LRU{TTL: time.Minute, now: func() time.Time { return now }}
+
+	c.Put("a", 1)
+	now = now.Add(59 * time.Second)
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+
+	now = now.Add(time.Second)
+	_, ok = c.Get("a")
+	assert.False(t, ok, "expired entry must not be returned")
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestLRU_Concurrent(t *testing.T) {
+	c := &LRU{MaxEntries: 10}
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				key := fmt.Sprintf("%d", (i+j)%20)
+				c.Put(key, j)
+				c.Get(key)
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 10, c.Len())
+}
@@ -0,0 +1,130 @@
+package rest
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	log "github.com/go-pkgz/lgr"
+	"github.com/pkg/errors"
+)
+
+// Canonical rewrites json and csv responses, so numbers in them are formatted the same way by all handlers,
+// json objects get sorted keys and zeroed volatile values, if SortKeys is set, so identical requests
+// give byte-identical responses. Responses are buffered to be rewritten, so the middleware must go after
+// the compressor, streamed and compressed responses, as well as the rest of content types, are written as is
+type Canonical struct {
+	Numbers  NumberFormat
+	SortKeys bool     // canonical json, keys of objects are sorted and volatile values are zeroed
+	Volatile []string // keys of json values, that differ from run to run, e.g. durations
+}
+
+// Handler rewrites the response of next handler
+func (c *Canonical) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cw := &canonicalWriter{ResponseWriter: w, c: c, status: http.StatusOK}
+		defer cw.close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// canonicalWriter buffers the rewritten response until the handler returns
+type canonicalWriter struct {
+	http.ResponseWriter
+	c *Canonical
+
+	status  int
+	decided bool   // the kind of the response is known
+	kind    string // "json" or "csv" for buffered responses, empty for the ones written as is
+	buf     bytes.Buffer
+}
+
+// WriteHeader decides whether the response is rewritten by its header, rewritten responses postpone it
+func (cw *canonicalWriter) WriteHeader(code int) {
+	if cw.decided {
+		return
+	}
+	cw.status = code
+	cw.decide()
+}
+
+// Write buffers the rewritten response or passes it as is
+func (cw *canonicalWriter) Write(b []byte) (int, error) {
+	if !cw.decided {
+		cw.decide()
+	}
+	if cw.kind == "" {
+		return cw.ResponseWriter.Write(b)
+	}
+	return cw.buf.Write(b)
+}
+
+// Flush flushes the response, that is written as is, rewritten responses are written at once in the end
+func (cw *canonicalWriter) Flush() {
+	if !cw.decided {
+		cw.decide()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok && cw.kind == "" {
+		f.Flush()
+	}
+}
+
+// Hijack hijacks the underlying connection, if it is supported
+func (cw *canonicalWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := cw.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("hijacking is not supported")
+}
+
+// decide chooses the kind of the response by its content type, the header of the response,
+// that is written as is, is passed to the underlying writer
+func (cw *canonicalWriter) decide() {
+	cw.decided = true
+	h := cw.Header()
+	ct := h.Get("Content-Type")
+	switch {
+	case h.Get("Content-Encoding") != "":
+	case strings.HasPrefix(ct, "application/json"):
+		cw.kind = "json"
+		return
+	case strings.HasPrefix(ct, "text/csv"):
+		cw.kind = "csv"
+		return
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+}
+
+// close rewrites and writes the buffered response, the response is written as is, if it can't be rewritten
+func (cw *canonicalWriter) close() {
+	if !cw.decided || cw.kind == "" {
+		return
+	}
+	if cw.buf.Len() == 0 {
+		cw.ResponseWriter.WriteHeader(cw.status)
+		return
+	}
+	data, err := cw.rewrite(cw.buf.Bytes())
+	if err != nil {
+		log.Printf("[WARN] failed to rewrite %s response, %v", cw.kind, err)
+		data = cw.buf.Bytes()
+	}
+	if cw.Header().Get("Content-Length") != "" {
+		cw.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+	if _, err = cw.ResponseWriter.Write(data); err != nil {
+		log.Printf("[WARN] failed to write %s response, %v", cw.kind, err)
+	}
+}
+
+// rewrite rewrites the buffered response of its kind
+func (cw *canonicalWriter) rewrite(data []byte) ([]byte, error) {
+	if cw.kind == "json" {
+		return cw.c.Numbers.ReformatJSON(data, cw.c.SortKeys, cw.c.Volatile...)
+	}
+	return cw.c.Numbers.ReformatCSV(data)
+}
@@ -0,0 +1,62 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonical_Handler(t *testing.T) {
+	c := &Canonical{Numbers: NumberFormat{Digits: 3}, SortKeys: true, Volatile: []string{"took"}}
+	h := c.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"y": 0.123456, `))
+			_, _ = w.Write([]byte(`"took": "1ms", "n": 10}` + "\n"))
+		case "/csv":
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			_, _ = w.Write([]byte("x,y\n0.5,0.123456\n"))
+		case "/stream":
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte(`data: {"y": 0.123456}` + "\n\n"))
+			w.(http.Flusher).Flush()
+		case "/broken":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"y": 0.123456`))
+		case "/empty":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotModified)
+		}
+	}))
+
+	do := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := do("/json")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, `{"n":10,"took":"","y":0.123}`+"\n", rec.Body.String())
+
+	rec = do("/csv")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "x,y\n0.5,0.123\n", rec.Body.String())
+
+	rec = do("/stream")
+	assert.True(t, rec.Flushed, "streams are written as is")
+	assert.Equal(t, `data: {"y": 0.123456}`+"\n\n", rec.Body.String())
+
+	rec = do("/broken")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"y": 0.123456`, rec.Body.String(), "the malformed response is written as is")
+
+	rec = do("/empty")
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+}
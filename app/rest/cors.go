@@ -0,0 +1,101 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORS allows browsers to call the API from the listed origins, requests of other origins are served
+// without CORS headers, so the browser blocks them, and their preflight requests are forbidden
+type CORS struct {
+	AllowedOrigins []string      // exact origins, like https://example.com, "*" allows any origin
+	MaxAge         time.Duration // duration to cache the results of the preflight request
+}
+
+var (
+	corsMethods = []string{http.MethodGet, http.MethodPost}
+	corsHeaders = []string{"Accept", "Content-Type", "Authorization", "X-Requested-With"}
+	// corsExposed are the headers, available to the scripts in the browser
+	corsExposed = []string{"X-Cache", "Retry-After", "Link", "X-Total-Count", "X-Request-Id"}
+)
+
+// Handler adds CORS headers to the responses and replies to preflight requests
+func (c *CORS) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h := w.Header()
+		h.Add("Vary", "Origin")
+		allowOrigin, allowed := c.allowOrigin(origin)
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			if !allowed || !corsAllowed(r) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			h.Set("Access-Control-Allow-Origin", allowOrigin)
+			h.Set("Access-Control-Allow-Methods", strings.Join(corsMethods, ", "))
+			h.Set("Access-Control-Allow-Headers", strings.Join(corsHeaders, ", "))
+			if c.MaxAge > 0 {
+				h.Set("Access-Control-Max-Age", strconv.Itoa(int(c.MaxAge.Seconds())))
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if allowed {
+			h.Set("Access-Control-Allow-Origin", allowOrigin)
+			h.Set("Access-Control-Expose-Headers", strings.Join(corsExposed, ", "))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AllowsOrigin checks whether the origin is allowed
+func (c *CORS) AllowsOrigin(origin string) bool {
+	_, ok := c.allowOrigin(origin)
+	return ok
+}
+
+// allowOrigin returns the value of Access-Control-Allow-Origin header for the origin
+func (c *CORS) allowOrigin(origin string) (string, bool) {
+	for _, o := range c.AllowedOrigins {
+		if o == "*" {
+			return "*", true
+		}
+		if strings.EqualFold(strings.TrimSuffix(o, "/"), origin) {
+			return origin, true
+		}
+	}
+	return "", false
+}
+
+// corsAllowed checks whether the method and headers, requested by the preflight, are allowed
+func corsAllowed(r *http.Request) bool {
+	if !containsFold(corsMethods, r.Header.Get("Access-Control-Request-Method")) {
+		return false
+	}
+	for _, hdr := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+		if hdr = strings.TrimSpace(hdr); hdr != "" && !containsFold(corsHeaders, hdr) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
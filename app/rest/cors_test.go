@@ -0,0 +1,85 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS_Handler(t *testing.T) {
+	called := 0
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called++
+		w.Header().Set("X-Cache", "MISS")
+		w.WriteHeader(http.StatusOK)
+	})
+	h := (&CORS{AllowedOrigins: []string{"https://example.com/"}, MaxAge: 10 * time.Minute}).Handler(next)
+
+	do := func(method, origin string, hdrs map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/solve", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		for k, v := range hdrs {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	preflight := map[string]string{
+		"Access-Control-Request-Method":  "POST",
+		"Access-Control-Request-Headers": "content-type",
+	}
+
+	// preflight of the allowed origin
+	rec := do(http.MethodOptions, "https://example.com", preflight)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, POST", rec.Header().Get("Access-Control-Allow-Methods"))
+	assert.Contains(t, rec.Header().Get("Access-Control-Allow-Headers"), "Content-Type")
+	assert.Equal(t, "600", rec.Header().Get("Access-Control-Max-Age"))
+	assert.Equal(t, 0, called, "preflight must not reach the handler")
+
+	// preflight of not allowed method or header
+	rec = do(http.MethodOptions, "https://example.com", map[string]string{"Access-Control-Request-Method": "DELETE"})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	rec = do(http.MethodOptions, "https://example.com", map[string]string{
+		"Access-Control-Request-Method":  "POST",
+		"Access-Control-Request-Headers": "X-Custom",
+	})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	// actual request of the allowed origin
+	rec = do(http.MethodPost, "https://example.com", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "https://example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, rec.Header().Get("Access-Control-Expose-Headers"), "X-Cache")
+	assert.Equal(t, "Origin", rec.Header().Get("Vary"))
+
+	// disallowed origin
+	rec = do(http.MethodOptions, "https://evil.com", preflight)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	rec = do(http.MethodPost, "https://evil.com", nil)
+	assert.Equal(t, http.StatusOK, rec.Code, "the browser blocks the response itself")
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rec.Header().Get("Access-Control-Expose-Headers"))
+
+	// not a cross-origin request
+	rec = do(http.MethodPost, "", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Vary"))
+
+	// wildcard mode
+	h = (&CORS{AllowedOrigins: []string{"*"}}).Handler(next)
+	rec = do(http.MethodOptions, "http://localhost:3000", preflight)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rec.Header().Get("Access-Control-Max-Age"))
+	rec = do(http.MethodGet, "http://localhost:3000", nil)
+	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+}
@@ -0,0 +1,197 @@
+package rest
+
+import (
+	"bufio"
+	"compress/gzip"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Compressor compresses responses with gzip, if the client accepts it, responses smaller than MinSize
+// and responses of content types, that are already compressed or streamed, are written as is
+type Compressor struct {
+	MinSize int // minimal size of the response in bytes to compress
+	Level   int // gzip compression level, gzip.DefaultCompression if zero
+}
+
+// compressibleTypes lists the prefixes of content types, worth to compress
+var compressibleTypes = []string{"application/json", "text/html", "text/csv", "text/plain", "text/css",
+	"application/javascript", "image/svg+xml"}
+
+// Handler compresses the response of next handler
+func (c *Compressor) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		gw := &gzipWriter{ResponseWriter: w, c: c, status: http.StatusOK}
+		defer gw.close()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// acceptsGzip checks whether gzip is acceptable by the value of Accept-Encoding header
+func acceptsGzip(header string) bool {
+	for _, enc := range strings.Split(header, ",") {
+		name, q := enc, 1.0
+		if i := strings.Index(enc, ";"); i >= 0 {
+			name = enc[:i]
+			param := strings.TrimSpace(enc[i+1:])
+			if strings.HasPrefix(param, "q=") {
+				v, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+				if err != nil {
+					continue
+				}
+				q = v
+			}
+		}
+		if name = strings.TrimSpace(name); (name == "gzip" || name == "*") && q > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// gzipWriter buffers the beginning of the response to decide whether to compress it
+type gzipWriter struct {
+	http.ResponseWriter
+	c *Compressor
+
+	status      int
+	wroteHeader bool // WriteHeader is called by the handler
+	decided     bool // the header is passed to the underlying writer
+	buf         []byte
+	gz          *gzip.Writer
+}
+
+// WriteHeader postpones writing the header until the decision about compression is made
+func (gw *gzipWriter) WriteHeader(code int) {
+	if gw.wroteHeader {
+		return
+	}
+	gw.wroteHeader = true
+	gw.status = code
+}
+
+// Write buffers the response until it is large enough to compress
+func (gw *gzipWriter) Write(b []byte) (int, error) {
+	gw.wroteHeader = true
+	if gw.decided {
+		return gw.write(b)
+	}
+
+	if !gw.compressible(b) {
+		if err := gw.decide(false); err != nil {
+			return 0, err
+		}
+		return gw.write(b)
+	}
+
+	gw.buf = append(gw.buf, b...)
+	if len(gw.buf) >= gw.c.MinSize {
+		if err := gw.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Flush writes the buffered response, the response is not compressed, if it is not decided yet
+func (gw *gzipWriter) Flush() {
+	if !gw.decided {
+		if err := gw.decide(false); err != nil {
+			return
+		}
+	}
+	if gw.gz != nil {
+		if err := gw.gz.Flush(); err != nil {
+			return
+		}
+	}
+	if f, ok := gw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack hijacks the underlying connection, if it is supported
+func (gw *gzipWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := gw.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("hijacking is not supported")
+}
+
+func (gw *gzipWriter) write(b []byte) (int, error) {
+	if gw.gz != nil {
+		return gw.gz.Write(b)
+	}
+	return gw.ResponseWriter.Write(b)
+}
+
+// compressible checks whether the response is worth to compress by its header and the first bytes
+func (gw *gzipWriter) compressible(b []byte) bool {
+	h := gw.Header()
+	if h.Get("Content-Encoding") != "" || gw.status == http.StatusNoContent || gw.status == http.StatusNotModified {
+		return false
+	}
+	ct := h.Get("Content-Type")
+	if ct == "" {
+		ct = http.DetectContentType(append(gw.buf, b...))
+	}
+	for _, prefix := range compressibleTypes {
+		if strings.HasPrefix(ct, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// decide writes the header and the buffered part of the response, compressed or not
+func (gw *gzipWriter) decide(compress bool) error {
+	gw.decided = true
+	if compress {
+		h := gw.Header()
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		level := gw.c.Level
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		gz, err := gzip.NewWriterLevel(gw.ResponseWriter, level)
+		if err != nil {
+			return errors.Wrapf(err, "failed to make gzip writer with level %d", level)
+		}
+		gw.gz = gz
+	}
+	gw.ResponseWriter.WriteHeader(gw.status)
+	if len(gw.buf) == 0 {
+		return nil
+	}
+	buf := gw.buf
+	gw.buf = nil
+	_, err := gw.write(buf)
+	return err
+}
+
+// close writes the rest of the response
+func (gw *gzipWriter) close() {
+	if !gw.decided {
+		if !gw.wroteHeader {
+			// the handler wrote nothing, leave the response to the server
+			return
+		}
+		if err := gw.decide(false); err != nil {
+			return
+		}
+	}
+	if gw.gz != nil {
+		_ = gw.gz.Close()
+	}
+}
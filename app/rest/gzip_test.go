@@ -0,0 +1,104 @@
+package rest
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressor_Handler(t *testing.T) {
+	large := `{"points": [` + strings.Repeat(`{"x": 0.1, "y": 0.2},`, 100) + `{"x": 1, "y": 2}]}`
+	h := (&Compressor{MinSize: 256}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/large":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			// write in chunks to check buffering before the decision
+			for i := 0; i < len(large); i += 100 {
+				end := i + 100
+				if end > len(large) {
+					end = len(large)
+				}
+				_, _ = w.Write([]byte(large[i:end]))
+			}
+		case "/small":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"ok": true}`))
+		case "/png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte(large))
+		case "/empty":
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+
+	do := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do("/large", "deflate, gzip;q=0.8")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+	assert.True(t, rec.Body.Len() < len(large))
+	gz, err := gzip.NewReader(rec.Body)
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, large, string(body))
+
+	for _, tt := range []struct{ path, enc, body string }{
+		{path: "/large", enc: "", body: large},
+		{path: "/large", enc: "gzip;q=0", body: large},
+		{path: "/small", enc: "gzip", body: `{"ok": true}`},
+		{path: "/png", enc: "gzip", body: large},
+	} {
+		rec = do(tt.path, tt.enc)
+		assert.Empty(t, rec.Header().Get("Content-Encoding"), tt.path+" "+tt.enc)
+		assert.Equal(t, tt.body, rec.Body.String(), tt.path+" "+tt.enc)
+	}
+
+	rec = do("/empty", "gzip")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+}
+
+func TestCompressor_Flush(t *testing.T) {
+	events := make(chan struct{})
+	h := (&Compressor{MinSize: 1024}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("event: point\n\n"))
+		w.(http.Flusher).Flush()
+		<-events
+	}))
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+	defer close(events)
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Empty(t, resp.Header.Get("Content-Encoding"))
+
+	// the event must be delivered while the handler is still running
+	buf := make([]byte, 14)
+	_, err = io.ReadFull(resp.Body, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "event: point\n\n", string(buf))
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"html/template"
 	"io"
+	"math"
 	"net/http"
 	"net/url"
 	"runtime"
@@ -35,6 +36,150 @@ const errHTMLTmpl = `<!DOCTYPE html>
 </body>
 </html>`
 
+// ErrCode is used to map the error to the client's representation
+type ErrCode int
+
+// All error codes for client mapping
+const (
+	ErrInternal     ErrCode = 0 // any internal error
+	ErrDecode       ErrCode = 1 // failed to unmarshal incoming request
+	ErrBadRequest   ErrCode = 2 // request contains incorrect data or doesn't contain data
+	ErrNotFound     ErrCode = 3 // requested entity doesn't exist
+	ErrUnauthorized ErrCode = 4 // request doesn't have credentials
+	ErrForbidden    ErrCode = 5 // credentials of the request are not accepted
+	ErrBusy         ErrCode = 6 // server is overloaded, the request might be retried later
+)
+
+// FieldError describes the invalid field of the request, the column is set for formulas, that can't be parsed
+type FieldError struct {
+	Field  string `json:"field"`
+	Msg    string `json:"msg"`
+	Column int    `json:"column,omitempty"` // of the offending character of the formula, counted from 1
+}
+
+// ValidationError lists all invalid fields of the request
+type ValidationError []FieldError
+
+// Error implements error interface to print all invalid fields
+func (e ValidationError) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, fe := range e {
+		msgs = append(msgs, fe.Field+": "+fe.Msg)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// ErrorLocation is the point of the solution, at which the method failed
+type ErrorLocation struct {
+	Method string  `json:"method"`
+	Step   int     `json:"step"`
+	Stage  string  `json:"stage"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+}
+
+// locator is implemented by errors, that know the point of the solution, at which they occurred
+type locator interface {
+	Locate() (method string, step int, stage string, x, y float64)
+}
+
+// ErrorResponse is the body of the JSON error response
+type ErrorResponse struct {
+	Code     ErrCode         `json:"code"`
+	Details  string          `json:"details"`
+	Error    string          `json:"error"`
+	Errors   ValidationError `json:"errors,omitempty"`   // invalid fields of the request, if any
+	Location *ErrorLocation  `json:"location,omitempty"` // point of the failed solution, if any
+}
+
+// NewErrorResponse makes the error response, if the error is caused by ValidationError,
+// the list of invalid fields is added to the response, if it is caused by the failure
+// of the solution at the finite point, the point is added as the location
+func NewErrorResponse(err error, details string, errCode ErrCode) ErrorResponse {
+	if err == nil {
+		err = errors.New("no error")
+	}
+	resp := ErrorResponse{Code: errCode, Details: details, Error: err.Error()}
+	var ve ValidationError
+	if errors.As(err, &ve) {
+		resp.Errors = ve
+	}
+	var le locator
+	if errors.As(err, &le) {
+		loc := ErrorLocation{}
+		loc.Method, loc.Step, loc.Stage, loc.X, loc.Y = le.Locate()
+		// json can't encode infinities and NaN, which are usual for diverged solutions
+		if isFinite(loc.X) && isFinite(loc.Y) {
+			resp.Location = &loc
+		}
+	}
+	return resp
+}
+
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// ProblemResponse is the body of the error response in application/problem+json of RFC 7807,
+// the code, invalid fields and the location of ErrorResponse are its extension members
+type ProblemResponse struct {
+	Type     string          `json:"type"`
+	Title    string          `json:"title"`
+	Status   int             `json:"status"`
+	Detail   string          `json:"detail"`
+	Instance string          `json:"instance,omitempty"`
+	Code     ErrCode         `json:"code"`
+	Errors   ValidationError `json:"errors,omitempty"`
+	Location *ErrorLocation  `json:"location,omitempty"`
+}
+
+// problemMediaType is the media type of RFC 7807 problem details
+const problemMediaType = "application/problem+json"
+
+// NewProblemResponse makes the problem details of the error response with the status of the request's path,
+// the type of the problem is about:blank, as the status describes it
+func NewProblemResponse(r *http.Request, status int, resp ErrorResponse) ProblemResponse {
+	return ProblemResponse{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   resp.Details + ": " + resp.Error,
+		Instance: r.URL.Path,
+		Code:     resp.Code,
+		Errors:   resp.Errors,
+		Location: resp.Location,
+	}
+}
+
+// acceptsProblem checks whether the client asks for problem details by the Accept header
+func acceptsProblem(r *http.Request) bool {
+	for _, v := range r.Header.Values("Accept") {
+		for _, mt := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(strings.Split(mt, ";")[0]), problemMediaType) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SendErrorJSON makes {error: blah, details: blah, code: 42} json body and responds with provided http status code,
+// if the error is caused by ValidationError, the list of invalid fields is added as "errors", clients, that accept
+// application/problem+json, are responded with ProblemResponse
+func SendErrorJSON(w http.ResponseWriter, r *http.Request, httpStatusCode int, err error, details string, errCode ErrCode) {
+	if err == nil {
+		err = errors.New("no error")
+	}
+	log.Printf("[WARN] %s", errDetailsMsg(r, httpStatusCode, err, details))
+	render.Status(r, httpStatusCode)
+	resp := NewErrorResponse(err, details, errCode)
+	if !acceptsProblem(r) {
+		RenderJSON(w, r, resp)
+		return
+	}
+	renderJSON(w, r, NewProblemResponse(r, httpStatusCode, resp), problemMediaType+"; charset=utf-8")
+}
+
 // SendErrorHTML makes html body with provided template and responds with provided http status code,
 // error code is not included in render as it is intended for UI developers and not for the users
 func SendErrorHTML(w http.ResponseWriter, r *http.Request, httpStatusCode int, err error, details string) {
@@ -56,7 +201,7 @@ func SendErrorHTML(w http.ResponseWriter, r *http.Request, httpStatusCode int, e
 }
 
 func errDetailsMsg(r *http.Request, code int, err error, msg string) string {
-	q := r.URL.String()
+	q := redactURL(r.URL).String() // the api key in the query is not logged
 	if qun, e := url.QueryUnescape(q); e == nil {
 		q = qun
 	}
@@ -1,8 +1,11 @@
 package rest
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -35,3 +38,91 @@ func TestSendErrorHTML(t *testing.T) {
 	assert.Contains(t, string(body), `error details 123456`)
 	assert.Contains(t, string(body), `error 500`)
 }
+
+func TestSendErrorJSON(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/error" {
+			t.Log("http err request", r.URL)
+			SendErrorJSON(w, r, 400, errors.New("error 400"), "error details 123456", ErrBadRequest)
+			return
+		}
+		w.WriteHeader(404)
+	}))
+
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/error")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Equal(t, `{"code":2,"details":"error details 123456","error":"error 400"}`+"\n", string(body))
+}
+
+func TestErrDetailsMsg_RedactsKey(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/solve?f=x%2By&api_key=secret", nil)
+	msg := func() string {
+		return errDetailsMsg(r, http.StatusForbidden, errors.New("unknown api key"), "forbidden")
+	}()
+	assert.Contains(t, msg, "forbidden - unknown api key - 403 - 192.0.2.1 - /api/v1/solve?f=x+y&api_key=redacted [caused by")
+	assert.NotContains(t, msg, "secret")
+}
+
+func TestSendErrorJSON_Problem(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := fmt.Errorf("invalid: %w", ValidationError{{Field: "f", Msg: "can't parse", Column: 3}})
+		SendErrorJSON(w, r, 400, err, "invalid request", ErrBadRequest)
+	}))
+	defer ts.Close()
+
+	for accept, problem := range map[string]bool{
+		"":                         false,
+		"application/json":         false,
+		"*/*":                      false,
+		"application/problem+json": true,
+		"text/html, Application/Problem+JSON;q=0.5": true,
+	} {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+"/solve?a=1", nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", accept)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, 400, resp.StatusCode)
+
+		if !problem {
+			assert.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"), accept)
+			assert.Equal(t, `{"code":2,"details":"invalid request","error":"invalid: f: can't parse",`+
+				`"errors":[{"field":"f","msg":"can't parse","column":3}]}`+"\n", string(body), accept)
+			continue
+		}
+		assert.Equal(t, "application/problem+json; charset=utf-8", resp.Header.Get("Content-Type"), accept)
+		assert.Equal(t, `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid request: invalid: f: can't parse",`+
+			`"instance":"/solve","code":2,"errors":[{"field":"f","msg":"can't parse","column":3}]}`+"\n", string(body), accept)
+	}
+}
+
+// locatedErr is the failure of the solution at the point
+type locatedErr struct{ x, y float64 }
+
+func (e locatedErr) Error() string { return "f failed" }
+func (e locatedErr) Locate() (method string, step int, stage string, x, y float64) {
+	return "Euler's method", 3, "f", e.x, e.y
+}
+
+func TestNewErrorResponse_Location(t *testing.T) {
+	resp := NewErrorResponse(fmt.Errorf("failed to solve: %w", locatedErr{x: 0.3, y: 0.5}), "failed", ErrInternal)
+	require.NotNil(t, resp.Location)
+	assert.Equal(t, ErrorLocation{Method: "Euler's method", Step: 3, Stage: "f", X: 0.3, Y: 0.5}, *resp.Location)
+	b, err := json.Marshal(resp)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"location":{"method":"Euler's method","step":3,"stage":"f","x":0.3,"y":0.5}`)
+
+	resp = NewErrorResponse(locatedErr{x: 0.3, y: math.Inf(1)}, "failed", ErrInternal)
+	assert.Nil(t, resp.Location, "diverged point can't be encoded")
+	assert.Nil(t, NewErrorResponse(errors.New("plain"), "failed", ErrInternal).Location)
+}
@@ -0,0 +1,67 @@
+package rest
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// inFlightRetryAfter is the number of seconds to wait, when the client has too many requests in flight
+const inFlightRetryAfter = "1"
+
+// InFlight limits the number of requests of each client in flight at once, the client is identified
+// as by RateLimiter, by the api key of the request or by its IP address, so the single client can't take
+// all workers of the server with slow requests, the rate of which is allowed
+type InFlight struct {
+	Max        int  // requests of the client at once, unlimited if not positive
+	TrustProxy bool // take the client's address from X-Forwarded-For and X-Real-IP headers
+
+	mu      sync.Mutex
+	clients map[string]int // requests in flight by clients, clients without requests are removed
+}
+
+// Handler responds with 429 and the Retry-After header to the request of the client, that has Max requests in flight
+func (f *InFlight) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if f.Max <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		client := ClientIP(r, f.TrustProxy)
+		if key, ok := APIKeyFrom(r); ok {
+			client = "key:" + key.Hash
+		}
+		if !f.acquire(client) {
+			w.Header().Set("Retry-After", inFlightRetryAfter)
+			SendErrorJSON(w, r, http.StatusTooManyRequests, errors.Errorf("client has %d requests in flight", f.Max),
+				"too many requests at once", ErrBusy)
+			return
+		}
+		defer f.release(client)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// acquire counts the request of the client, unless the client has Max requests in flight
+func (f *InFlight) acquire(client string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.clients == nil {
+		f.clients = map[string]int{}
+	}
+	if f.clients[client] >= f.Max {
+		return false
+	}
+	f.clients[client]++
+	return true
+}
+
+// release uncounts the completed request of the client
+func (f *InFlight) release(client string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.clients[client]--; f.clients[client] <= 0 {
+		delete(f.clients, client)
+	}
+}
@@ -0,0 +1,64 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInFlight_Handler(t *testing.T) {
+	f := &InFlight{Max: 2}
+	started, unblock := make(chan struct{}), make(chan struct{})
+	h := f.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("block") != "" {
+			started <- struct{}{}
+			<-unblock
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	do := func(remote, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/solve?"+query, nil)
+		req.RemoteAddr = remote
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, http.StatusOK, do("10.0.0.1:1234", "block=1").Code)
+		}()
+		<-started
+	}
+
+	rec := do("10.0.0.1:4321", "")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+	var resp ErrorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, ErrorResponse{Code: ErrBusy, Details: "too many requests at once", Error: "client has 2 requests in flight"}, resp)
+
+	// other clients are not affected
+	assert.Equal(t, http.StatusOK, do("10.0.0.2:1234", "").Code)
+
+	// completed requests free their slots
+	close(unblock)
+	wg.Wait()
+	assert.Equal(t, http.StatusOK, do("10.0.0.1:1234", "").Code)
+	assert.Empty(t, f.clients)
+}
+
+func TestInFlight_Unlimited(t *testing.T) {
+	h := (&InFlight{}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
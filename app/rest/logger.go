@@ -0,0 +1,149 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	log "github.com/go-pkgz/lgr"
+)
+
+// requestIDHeader is the header with the id of the request, both incoming and outgoing
+const requestIDHeader = "X-Request-Id"
+
+// maxRequestIDLen is the maximal length of the incoming request id, longer ones are replaced
+const maxRequestIDLen = 64
+
+type requestIDCtxKey struct{}
+
+// Logger assigns the id to each request and logs a single line per request, when it is finished
+type Logger struct {
+	L          log.L // logger to write to, the default one if nil
+	TrustProxy bool  // take the client's address from X-Forwarded-For and X-Real-IP headers
+}
+
+// Handler puts the id of the request and the logger, that tags messages with it, to the context
+// and the id to the response headers, the incoming X-Request-Id is kept, if it is valid
+func (l *Logger) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		st := time.Now()
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			var err error
+			if id, err = newSessionID(); err != nil {
+				SendErrorJSON(w, r, http.StatusInternalServerError, err, "can't make request id", ErrInternal)
+				return
+			}
+		}
+		w.Header().Set(requestIDHeader, id)
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		ctx := context.WithValue(r.Context(), requestIDCtxKey{}, idLogger{l: l.logger(), id: id})
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		cache := ww.Header().Get("X-Cache")
+		if cache == "" {
+			cache = "-"
+		}
+		l.logger().Logf("[INFO] request_id=%s method=%s path=%q status=%d bytes=%d duration=%s ip=%s cache=%s",
+			id, r.Method, redactURL(r.URL).RequestURI(), status, ww.BytesWritten(), time.Since(st), ClientIP(r, l.TrustProxy), cache)
+	})
+}
+
+func (l *Logger) logger() log.L {
+	if l.L == nil {
+		return log.Default()
+	}
+	return l.L
+}
+
+// secretParams are query parameters with credentials, their values are not logged
+var secretParams = []string{"api_key", "access_token", "token"}
+
+// redactURL returns the copy of the url to log, values of secret query parameters are replaced with "redacted",
+// other parameters are kept as is and in their order
+func redactURL(u *url.URL) *url.URL {
+	if u.RawQuery == "" {
+		return u
+	}
+	parts := strings.Split(u.RawQuery, "&")
+	redacted := false
+	for i, part := range parts {
+		key := part
+		if j := strings.IndexByte(part, '='); j >= 0 {
+			key = part[:j]
+		}
+		if k, err := url.QueryUnescape(key); err == nil {
+			key = k
+		}
+		for _, secret := range secretParams {
+			if strings.EqualFold(key, secret) {
+				parts[i], redacted = secret+"=redacted", true
+				break
+			}
+		}
+	}
+	if !redacted {
+		return u
+	}
+	res := *u
+	res.RawQuery = strings.Join(parts, "&")
+	return &res
+}
+
+// validRequestID checks whether the id is safe to log and to return in headers
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("-_.:", c)) {
+			return false
+		}
+	}
+	return true
+}
+
+// RequestID returns the id of the request from the context, empty if there is no id
+func RequestID(ctx context.Context) string {
+	il, _ := ctx.Value(requestIDCtxKey{}).(idLogger)
+	return il.id
+}
+
+// CtxLogger returns the logger, that tags messages with the id of the request from the context,
+// the default logger is returned, if there is no id
+func CtxLogger(ctx context.Context) log.L {
+	if il, ok := ctx.Value(requestIDCtxKey{}).(idLogger); ok {
+		return il
+	}
+	return log.Default()
+}
+
+// idLogger puts the id of the request after the level of the message
+type idLogger struct {
+	l  log.L
+	id string
+}
+
+// Logf formats the message and passes it to the wrapped logger with the id of the request
+func (il idLogger) Logf(format string, args ...interface{}) {
+	msg := format
+	if len(args) > 0 {
+		msg = fmt.Sprintf(format, args...)
+	}
+	level := ""
+	if strings.HasPrefix(msg, "[") {
+		if i := strings.Index(msg, "] "); i > 0 {
+			level, msg = msg[:i+2], msg[i+2:]
+		}
+	}
+	il.l.Logf("%srequest_id=%s %s", level, il.id, msg)
+}
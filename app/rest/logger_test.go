@@ -0,0 +1,114 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	log "github.com/go-pkgz/lgr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logBuffer collects formatted messages of the logger
+type logBuffer struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (lb *logBuffer) Logf(format string, args ...interface{}) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	lb.lines = append(lb.lines, fmt.Sprintf(format, args...))
+}
+
+func TestLogger_Handler(t *testing.T) {
+	lb := &logBuffer{}
+	var ctxID string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctxID = RequestID(r.Context())
+		CtxLogger(r.Context()).Logf("[DEBUG] solving %d", 42)
+		w.Header().Set("X-Cache", "MISS")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("hello"))
+	})
+	h := (&Logger{L: lb}).Handler(next)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/solve?a=1", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Request-Id", "abc-123")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, "abc-123", rec.Header().Get("X-Request-Id"))
+	assert.Equal(t, "abc-123", ctxID)
+
+	require.Len(t, lb.lines, 2)
+	assert.Equal(t, "[DEBUG] request_id=abc-123 solving 42", lb.lines[0])
+	assert.Regexp(t, `^\[INFO\] request_id=abc-123 method=POST path="/api/v1/solve\?a=1" status=201 bytes=5 `+
+		`duration=\S+ ip=10.0.0.1 cache=MISS$`, lb.lines[1])
+
+	// the id is generated, if it is missing or malformed
+	for _, id := range []string{"", "abc def", strings.Repeat("a", 65)} {
+		lb.lines = nil
+		req = httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.Header.Set("X-Request-Id", id)
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		got := rec.Header().Get("X-Request-Id")
+		assert.Len(t, got, 32, "id %q", id)
+		assert.Equal(t, got, ctxID)
+		require.Len(t, lb.lines, 2)
+		assert.Contains(t, lb.lines[1], "request_id="+got+" method=GET path=\"/ping\" status=201")
+	}
+}
+
+func TestLogger_TrustProxy(t *testing.T) {
+	lb := &logBuffer{}
+	h := (&Logger{L: lb, TrustProxy: true}).Handler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Forwarded-For", "1.2.3.4, 10.0.0.1")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	require.Len(t, lb.lines, 1)
+	assert.Contains(t, lb.lines[0], "status=200 bytes=0")
+	assert.Contains(t, lb.lines[0], "ip=10.0.0.1 cache=-", "the proxy appends the address of the client")
+}
+
+func TestLogger_RedactsSecrets(t *testing.T) {
+	lb := &logBuffer{}
+	h := (&Logger{L: lb}).Handler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/solve?f=x&api_key=secret&n=10", nil))
+	require.Len(t, lb.lines, 1)
+	assert.Contains(t, lb.lines[0], `path="/api/v1/solve?f=x&api_key=redacted&n=10"`)
+	assert.NotContains(t, lb.lines[0], "secret")
+}
+
+func TestRedactURL(t *testing.T) {
+	for _, tt := range []struct{ uri, want string }{
+		{"/ping", "/ping"},
+		{"/api/v1/solve?f=x%2By&n=10", "/api/v1/solve?f=x%2By&n=10"},
+		{"/api/v1/solve?api_key=k1&f=x&API_KEY=k2", "/api/v1/solve?api_key=redacted&f=x&api_key=redacted"},
+		{"/api/v1/solve?api%5Fkey=k1&access_token=t&token", "/api/v1/solve?api_key=redacted&access_token=redacted&token=redacted"},
+	} {
+		u, err := url.Parse(tt.uri)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, redactURL(u).RequestURI(), tt.uri)
+		assert.Equal(t, tt.uri, u.RequestURI(), "the url itself is not changed")
+	}
+}
+
+func TestCtxLogger(t *testing.T) {
+	assert.Equal(t, "", RequestID(context.Background()))
+	assert.Equal(t, log.Default(), CtxLogger(context.Background()))
+
+	lb := &logBuffer{}
+	l := idLogger{l: lb, id: "1"}
+	l.Logf("no level")
+	l.Logf("[WARN] with level %s", "arg")
+	l.Logf("[not a level")
+	assert.Equal(t, []string{"request_id=1 no level", "[WARN] request_id=1 with level arg", "request_id=1 [not a level"}, lb.lines)
+}
@@ -0,0 +1,212 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// notations of numbers of NumberFormat
+const (
+	NotationGeneral    = "g" // %e for large exponents, %f otherwise
+	NotationScientific = "e"
+	NotationFixed      = "f" // digits are the digits after the point
+)
+
+// NumberFormat is the format of floating point numbers in responses, the zero value formats numbers
+// in the shortest representation without the loss of precision
+type NumberFormat struct {
+	Digits   int    // significant digits, or digits after the point in the fixed notation, 0 for the shortest
+	Notation string // one of notations, general if empty
+}
+
+// Validate checks the digits and the notation
+func (nf NumberFormat) Validate() error {
+	if nf.Digits < 0 {
+		return errors.Errorf("digits must not be negative, got %d", nf.Digits)
+	}
+	switch nf.Notation {
+	case "", NotationGeneral, NotationScientific, NotationFixed:
+		return nil
+	}
+	return errors.Errorf("unknown notation %q, must be %s, %s or %s", nf.Notation,
+		NotationGeneral, NotationScientific, NotationFixed)
+}
+
+// Format formats the number, the number is formatted the same way on all platforms
+func (nf NumberFormat) Format(v float64) string {
+	return string(nf.Append(nil, v))
+}
+
+// Append appends the formatted number to dst
+func (nf NumberFormat) Append(dst []byte, v float64) []byte {
+	notation := byte('g')
+	if nf.Notation != "" {
+		notation = nf.Notation[0]
+	}
+	prec := nf.Digits
+	if prec == 0 {
+		prec = -1
+	}
+	return strconv.AppendFloat(dst, v, notation, prec, 64)
+}
+
+// ReformatJSON rewrites numbers of the json document in the format, integers are kept as is, so they are
+// still decoded into integers, keys of objects are sorted, if sortKeys is set, and values of volatile keys,
+// e.g. durations, are replaced by zeros of their types, the document is written compactly with the new line
+func (nf NumberFormat) ReformatJSON(data []byte, sortKeys bool, volatile ...string) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	rf := jsonReformatter{nf: nf, dec: dec, sortKeys: sortKeys, volatile: map[string]bool{}}
+	for _, key := range volatile {
+		rf.volatile[key] = true
+	}
+	res, err := rf.value(false)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode json")
+	}
+	if _, err = dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("unexpected data after the json value")
+	}
+	return append(res, '\n'), nil
+}
+
+// jsonReformatter rewrites the json document token by token
+type jsonReformatter struct {
+	nf       NumberFormat
+	dec      *json.Decoder
+	sortKeys bool
+	volatile map[string]bool
+}
+
+// member is the encoded member of the json object
+type member struct {
+	key, val []byte
+	name     string
+}
+
+// value reads the next value of the document and returns it encoded, the scalar value is zeroed, if it is volatile
+func (rf jsonReformatter) value(volatile bool) ([]byte, error) {
+	tok, err := rf.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '[' {
+			return rf.array()
+		}
+		return rf.object()
+	case json.Number:
+		if volatile {
+			return []byte("0"), nil
+		}
+		s := t.String()
+		if !strings.ContainsAny(s, ".eE") {
+			return []byte(s), nil
+		}
+		v, err := t.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return rf.nf.Append(nil, v), nil
+	case string:
+		if volatile {
+			t = ""
+		}
+		return json.Marshal(t)
+	default: // bool and null
+		if volatile && t != nil {
+			return []byte("false"), nil
+		}
+		return json.Marshal(t)
+	}
+}
+
+func (rf jsonReformatter) array() ([]byte, error) {
+	res := []byte{'['}
+	for i := 0; rf.dec.More(); i++ {
+		if i > 0 {
+			res = append(res, ',')
+		}
+		v, err := rf.value(false)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, v...)
+	}
+	if _, err := rf.dec.Token(); err != nil {
+		return nil, err
+	}
+	return append(res, ']'), nil
+}
+
+func (rf jsonReformatter) object() ([]byte, error) {
+	var members []member
+	for rf.dec.More() {
+		tok, err := rf.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		name, _ := tok.(string)
+		m := member{name: name}
+		if m.key, err = json.Marshal(name); err != nil {
+			return nil, err
+		}
+		if m.val, err = rf.value(rf.volatile[name]); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	if _, err := rf.dec.Token(); err != nil {
+		return nil, err
+	}
+	if rf.sortKeys {
+		sort.SliceStable(members, func(i, j int) bool { return members[i].name < members[j].name })
+	}
+
+	res := []byte{'{'}
+	for i, m := range members {
+		if i > 0 {
+			res = append(res, ',')
+		}
+		res = append(append(append(res, m.key...), ':'), m.val...)
+	}
+	return append(res, '}'), nil
+}
+
+// ReformatCSV rewrites cells of the csv document, that are numbers, in the format, integers are kept as is
+func (nf NumberFormat) ReformatCSV(data []byte) ([]byte, error) {
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.FieldsPerRecord = -1
+	buf := &bytes.Buffer{}
+	cw := csv.NewWriter(buf)
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read csv")
+		}
+		for i, cell := range row {
+			if !strings.ContainsAny(cell, ".eE") {
+				continue
+			}
+			if v, err := strconv.ParseFloat(cell, 64); err == nil {
+				row[i] = nf.Format(v)
+			}
+		}
+		if err = cw.Write(row); err != nil {
+			return nil, errors.Wrap(err, "failed to write csv")
+		}
+	}
+	cw.Flush()
+	return buf.Bytes(), cw.Error()
+}
@@ -0,0 +1,61 @@
+package rest
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNumberFormat_Format(t *testing.T) {
+	tbl := []struct {
+		nf   NumberFormat
+		v    float64
+		want string
+	}{
+		{NumberFormat{}, 1.0 / 3, "0.3333333333333333"},
+		{NumberFormat{}, 1e21, "1e+21"},
+		{NumberFormat{Digits: 4}, 1.0 / 3, "0.3333"},
+		{NumberFormat{Digits: 4}, 123456, "1.235e+05"},
+		{NumberFormat{Digits: 3, Notation: NotationScientific}, 0.0123456, "1.235e-02"},
+		{NumberFormat{Digits: 2, Notation: NotationFixed}, 2.0 / 3, "0.67"},
+		{NumberFormat{Notation: NotationFixed}, 0.5, "0.5"},
+		{NumberFormat{Digits: 4}, math.Inf(-1), "-Inf"},
+	}
+	for _, tt := range tbl {
+		assert.Equal(t, tt.want, tt.nf.Format(tt.v), "%+v, %v", tt.nf, tt.v)
+	}
+
+	assert.NoError(t, NumberFormat{Digits: 12, Notation: NotationGeneral}.Validate())
+	assert.EqualError(t, NumberFormat{Digits: -1}.Validate(), "digits must not be negative, got -1")
+	assert.EqualError(t, NumberFormat{Notation: "x"}.Validate(), `unknown notation "x", must be g, e or f`)
+}
+
+func TestNumberFormat_ReformatJSON(t *testing.T) {
+	nf := NumberFormat{Digits: 4}
+	res, err := nf.ReformatJSON([]byte(`{"b": [0.30000000000000004, 11, -2.5e-7, true, null], "a": {"took": "1.2ms",
+		"x": 1.0000001, "s": "<&>"}, "took": 12.5}`+"\n"), false)
+	require.NoError(t, err)
+	assert.Equal(t, `{"b":[0.3,11,-2.5e-07,true,null],"a":{"took":"1.2ms","x":1,"s":"\u003c\u0026\u003e"},"took":12.5}`+"\n",
+		string(res), "the order of keys is kept, strings are escaped as RenderJSON does")
+
+	res, err = nf.ReformatJSON([]byte(`{"b": {"z": 1, "took": "1.2ms", "a": [{"took": 3}]}, "a": 0.125, "took": "5µs"}`),
+		true, "took")
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":0.125,"b":{"a":[{"took":0}],"took":"","z":1},"took":""}`+"\n", string(res))
+
+	_, err = nf.ReformatJSON([]byte(`{"a": `), false)
+	assert.Error(t, err)
+	_, err = nf.ReformatJSON([]byte(`{} {}`), false)
+	assert.EqualError(t, err, "unexpected data after the json value")
+}
+
+func TestNumberFormat_ReformatCSV(t *testing.T) {
+	res, err := NumberFormat{Digits: 3}.ReformatCSV([]byte("x,euler,exact\n0,1,1\n0.5,1.5,1.6487212707001282\n1,2.25,\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "x,euler,exact\n0,1,1\n0.5,1.5,1.65\n1,2.25,\n", string(res))
+
+	_, err = NumberFormat{}.ReformatCSV([]byte("a,\"b\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,136 @@
+package rest
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxBuckets is the number of tracked clients, after which the refilled buckets are evicted
+const maxBuckets = 10000
+
+// RateLimiter limits the rate of requests from each client with the token bucket algorithm,
+// each client is identified by its IP address, the requests with api key, that has its own rate,
+// are limited by the key instead
+type RateLimiter struct {
+	Rate       float64 // number of requests per second, requests without own rate of the key are unlimited if zero
+	Burst      int     // maximal number of requests at once
+	TrustProxy bool    // take the client's address from X-Forwarded-For and X-Real-IP headers
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	rate   float64
+	burst  float64
+}
+
+// Handler responds with 429 and the Retry-After header to the requests, exceeding the rate
+func (rl *RateLimiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ok, retryAfter := rl.Allow(r)
+		if !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			SendErrorJSON(w, r, http.StatusTooManyRequests, errors.New("rate limit exceeded"),
+				"too many requests", ErrBusy)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Allow takes the token from the bucket of the request's client, it is used to limit the rate
+// of messages, that are not separate requests, like the messages of the websocket connection
+func (rl *RateLimiter) Allow(r *http.Request) (bool, time.Duration) {
+	if key, ok := APIKeyFrom(r); ok && key.Rate > 0 {
+		return rl.allow("key:"+key.Hash, key.Rate, key.Burst)
+	}
+	if rl.Rate <= 0 {
+		return true, 0
+	}
+	return rl.allow(rl.clientIP(r), rl.Rate, rl.Burst)
+}
+
+// allow takes the token from the client's bucket, if there is no tokens left,
+// it returns the duration, after which the token will be available
+func (rl *RateLimiter) allow(key string, rate float64, maxBurst int) (bool, time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	if rl.now != nil {
+		now = rl.now()
+	}
+	if rl.buckets == nil {
+		rl.buckets = map[string]*bucket{}
+	}
+
+	burst := float64(maxBurst)
+	if burst < 1 {
+		burst = 1
+	}
+
+	b, ok := rl.buckets[key]
+	if !ok {
+		if len(rl.buckets) >= maxBuckets {
+			rl.evict(now)
+		}
+		b = &bucket{tokens: burst, last: now, rate: rate, burst: burst}
+		rl.buckets[key] = b
+	}
+
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// evict removes buckets, that are already refilled, so they are indistinguishable from the new ones
+func (rl *RateLimiter) evict(now time.Time) {
+	for key, b := range rl.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*b.rate >= b.burst {
+			delete(rl.buckets, key)
+		}
+	}
+}
+
+// clientIP returns the address of the client
+func (rl *RateLimiter) clientIP(r *http.Request) string {
+	return ClientIP(r, rl.TrustProxy)
+}
+
+// ClientIP returns the address of the client, taking it from the proxy headers, if they are trusted,
+// the rightmost address of X-Forwarded-For is taken, as it is appended by the proxy, while the client
+// sets any addresses before it
+func ClientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+			if i := strings.LastIndex(xff, ","); i >= 0 {
+				xff = xff[i+1:]
+			}
+			return strings.TrimSpace(xff)
+		}
+		if xrip := r.Header.Get("X-Real-IP"); xrip != "" {
+			return xrip
+		}
+	}
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
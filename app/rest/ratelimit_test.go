@@ -0,0 +1,103 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_Handler(t *testing.T) {
+	now := time.Date(2020, 11, 7, 0, 0, 0, 0, time.UTC)
+	rl := &RateLimiter{Rate: 2, Burst: 3, now: func() time.Time { return now }}
+	h := rl.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }))
+
+	do := func(remote, xff string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/solve", nil)
+		req.RemoteAddr = remote
+		if xff != "" {
+			req.Header.Set("X-Forwarded-For", xff)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// burst is allowed at once
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, do("10.0.0.1:1234", "").Code, "request %d", i)
+	}
+	rec := do("10.0.0.1:4321", "")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+	var er ErrorResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&er))
+	assert.Equal(t, ErrBusy, er.Code, "the client might retry later")
+
+	// other clients are not affected
+	assert.Equal(t, http.StatusOK, do("10.0.0.2:1234", "").Code)
+
+	// proxy headers are ignored for untrusted proxy
+	assert.Equal(t, http.StatusTooManyRequests, do("10.0.0.1:1234", "192.168.0.1").Code)
+
+	// one token is refilled after half of a second
+	now = now.Add(500 * time.Millisecond)
+	assert.Equal(t, http.StatusOK, do("10.0.0.1:1234", "").Code)
+	assert.Equal(t, http.StatusTooManyRequests, do("10.0.0.1:1234", "").Code)
+
+	// whole bucket is refilled after the window
+	now = now.Add(2 * time.Second)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, do("10.0.0.1:1234", "").Code, "request %d", i)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, do("10.0.0.1:1234", "").Code)
+}
+
+func TestRateLimiter_TrustProxy(t *testing.T) {
+	rl := &RateLimiter{Rate: 1, Burst: 1, TrustProxy: true}
+	h := rl.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }))
+
+	do := func(xff string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/solve", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-Forwarded-For", xff)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// the client sets any addresses before the one, appended by the proxy, so they don't give the new bucket
+	assert.Equal(t, http.StatusOK, do("1.1.1.1, 192.168.0.1"))
+	assert.Equal(t, http.StatusTooManyRequests, do("2.2.2.2,192.168.0.1"))
+	assert.Equal(t, http.StatusTooManyRequests, do("192.168.0.1"))
+	assert.Equal(t, http.StatusOK, do("192.168.0.1, 192.168.0.2"))
+}
+
+func TestRateLimiter_APIKey(t *testing.T) {
+	rl := &RateLimiter{Burst: 1}
+	auth := &APIKeys{Keys: []APIKey{{Hash: HashAPIKey("limited"), Rate: 0.001, Burst: 2}, {Hash: HashAPIKey("default")}}}
+	h := auth.Handler(rl.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })))
+
+	do := func(remote, key string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/solve", nil)
+		req.RemoteAddr = remote
+		req.Header.Set("Authorization", "Bearer "+key)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// the key is limited by its own rate from any address
+	assert.Equal(t, http.StatusOK, do("10.0.0.1:1234", "limited"))
+	assert.Equal(t, http.StatusOK, do("10.0.0.2:1234", "limited"))
+	assert.Equal(t, http.StatusTooManyRequests, do("10.0.0.3:1234", "limited"))
+
+	// the key without own rate is not limited, as the rate of the limiter is zero
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, http.StatusOK, do("10.0.0.1:1234", "default"), "request %d", i)
+	}
+}
@@ -0,0 +1,91 @@
+package rest
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultSessionCookie is the name of the session cookie, if it is not set
+const defaultSessionCookie = "decompract_session"
+
+type sessionCtxKey struct{}
+
+// Session identifies the browser by the signed cookie with the random id, without any login,
+// the requests without valid cookie are given the new session
+type Session struct {
+	Secret []byte        // key to sign the cookie
+	Name   string        // name of the cookie, decompract_session if empty
+	MaxAge time.Duration // lifetime of the cookie, it lives until the browser is closed if zero
+}
+
+// Handler puts the id of the session to the context of the request, setting the cookie if needed
+func (s *Session) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := "", false
+		if c, err := r.Cookie(s.name()); err == nil {
+			id, ok = s.verify(c.Value)
+		}
+
+		if !ok {
+			var err error
+			if id, err = newSessionID(); err != nil {
+				SendErrorJSON(w, r, http.StatusInternalServerError, err, "can't start session", ErrInternal)
+				return
+			}
+			// the cookie of the session, started over https, is not sent over plain http
+			c := &http.Cookie{Name: s.name(), Value: s.sign(id), Path: "/", HttpOnly: true, Secure: r.TLS != nil,
+				SameSite: http.SameSiteLaxMode}
+			if s.MaxAge > 0 {
+				c.MaxAge = int(s.MaxAge.Seconds())
+			}
+			http.SetCookie(w, c)
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), sessionCtxKey{}, id)))
+	})
+}
+
+// SessionID returns the id of the session of the request, empty if there is no session
+func SessionID(r *http.Request) string {
+	id, _ := r.Context().Value(sessionCtxKey{}).(string)
+	return id
+}
+
+func (s *Session) name() string {
+	if s.Name == "" {
+		return defaultSessionCookie
+	}
+	return s.Name
+}
+
+// sign returns the value of the cookie as id.signature
+func (s *Session) sign(id string) string {
+	mac := hmac.New(sha256.New, s.Secret)
+	_, _ = mac.Write([]byte(id))
+	return id + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verify checks the signature of the cookie's value and returns the id of the session
+func (s *Session) verify(value string) (string, bool) {
+	i := strings.LastIndexByte(value, '.')
+	if i <= 0 {
+		return "", false
+	}
+	id := value[:i]
+	return id, hmac.Equal([]byte(s.sign(id)), []byte(value))
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
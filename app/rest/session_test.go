@@ -0,0 +1,69 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_Handler(t *testing.T) {
+	var ids []string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, SessionID(r))
+	})
+	h := (&Session{Secret: []byte("secret"), MaxAge: time.Hour}).Handler(next)
+
+	do := func(c *http.Cookie) *http.Response {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/history", nil)
+		if c != nil {
+			req.AddCookie(c)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Result()
+	}
+
+	// the new session is started without cookie
+	resp := do(nil)
+	require.Len(t, resp.Cookies(), 1)
+	c := resp.Cookies()[0]
+	assert.Equal(t, "decompract_session", c.Name)
+	assert.True(t, c.HttpOnly)
+	assert.False(t, c.Secure, "the session over plain http is kept over plain http")
+	assert.Equal(t, 3600, c.MaxAge)
+	require.Len(t, ids, 1)
+	assert.Len(t, ids[0], 32)
+
+	// the valid cookie keeps the session
+	resp = do(&http.Cookie{Name: c.Name, Value: c.Value})
+	assert.Empty(t, resp.Cookies())
+	require.Len(t, ids, 2)
+	assert.Equal(t, ids[0], ids[1])
+
+	// the forged cookies start the new session
+	for _, v := range []string{ids[0], ids[0] + ".forged", "other" + c.Value[len(ids[0]):], ".", ""} {
+		resp = do(&http.Cookie{Name: c.Name, Value: v})
+		require.Len(t, resp.Cookies(), 1, v)
+		assert.NotEqual(t, ids[0], ids[len(ids)-1], v)
+	}
+
+	// the cookie signed by other secret is not valid
+	other := &Session{Secret: []byte("other")}
+	resp = do(&http.Cookie{Name: c.Name, Value: other.sign(ids[0])})
+	require.Len(t, resp.Cookies(), 1)
+	assert.NotEqual(t, ids[0], ids[len(ids)-1])
+
+	// the session, started over https, is not sent over plain http
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "https://example.com/api/v1/history", nil))
+	require.Len(t, rec.Result().Cookies(), 1)
+	assert.True(t, rec.Result().Cookies()[0].Secure)
+}
+
+func TestSessionID_NoSession(t *testing.T) {
+	assert.Empty(t, SessionID(httptest.NewRequest(http.MethodGet, "/", nil)))
+}
@@ -0,0 +1,127 @@
+package rest
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// TLS configures https of the server, certificates are loaded from files or obtained from Let's Encrypt
+type TLS struct {
+	CertFile string   // certificate chain in pem
+	KeyFile  string   // private key of the certificate in pem
+	Domains  []string // domains to obtain certificates for with autocert, files are used if empty
+	CacheDir string   // directory to keep certificates, obtained with autocert
+	Email    string   // contact of the Let's Encrypt account, optional
+
+	directoryURL string // url of the acme directory, Let's Encrypt if empty
+}
+
+// TLSSetup is the prepared configuration of https
+type TLSSetup struct {
+	Config    *tls.Config
+	Challenge func(http.Handler) http.Handler // answers acme http-01 challenges, nil for certificate files
+	Obtain    func(ctx context.Context) error // obtains certificates of all domains, nil for certificate files
+}
+
+// Enabled checks whether https is configured
+func (t TLS) Enabled() bool {
+	return t.CertFile != "" || t.KeyFile != "" || len(t.Domains) > 0
+}
+
+// Setup validates the options, loads the certificate files or prepares autocert
+func (t TLS) Setup() (*TLSSetup, error) {
+	switch {
+	case len(t.Domains) > 0 && (t.CertFile != "" || t.KeyFile != ""):
+		return nil, errors.New("certificate files and autocert domains are mutually exclusive")
+	case len(t.Domains) > 0:
+		return t.autocert()
+	case t.CertFile == "" || t.KeyFile == "":
+		return nil, errors.New("both certificate and key files must be set")
+	}
+	cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load certificate %s with key %s", t.CertFile, t.KeyFile)
+	}
+	cfg := tlsConfig()
+	cfg.Certificates = []tls.Certificate{cert}
+	return &TLSSetup{Config: cfg}, nil
+}
+
+func (t TLS) autocert() (*TLSSetup, error) {
+	if t.CacheDir == "" {
+		return nil, errors.New("cache directory of autocert must be set")
+	}
+	if err := os.MkdirAll(t.CacheDir, 0o700); err != nil {
+		return nil, errors.Wrapf(err, "failed to make cache directory %s", t.CacheDir)
+	}
+	m := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(t.Domains...),
+		Cache:      autocert.DirCache(t.CacheDir),
+		Email:      t.Email,
+	}
+	if t.directoryURL != "" {
+		m.Client = &acme.Client{DirectoryURL: t.directoryURL}
+	}
+	cfg := tlsConfig()
+	cfg.GetCertificate = m.GetCertificate
+	// acme-tls/1 is negotiated only by Let's Encrypt during tls-alpn-01 challenges
+	cfg.NextProtos = []string{"http/1.1", acme.ALPNProto}
+
+	obtain := func(ctx context.Context) error {
+		for _, domain := range t.Domains {
+			errCh := make(chan error, 1)
+			go func(domain string) {
+				_, err := m.GetCertificate(&tls.ClientHelloInfo{ServerName: domain})
+				errCh <- err
+			}(domain)
+			select {
+			case err := <-errCh:
+				if err != nil {
+					return errors.Wrapf(err, "failed to obtain certificate for %s", domain)
+				}
+			case <-ctx.Done():
+				return errors.Wrapf(ctx.Err(), "failed to obtain certificate for %s", domain)
+			}
+		}
+		return nil
+	}
+	return &TLSSetup{Config: cfg, Challenge: m.HTTPHandler, Obtain: obtain}, nil
+}
+
+// tlsConfig returns the config with defaults of the server
+func tlsConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:       tls.VersionTLS12,
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256},
+	}
+}
+
+// RedirectHTTPS redirects plain http requests to https with the same host and path
+type RedirectHTTPS struct {
+	Port int // port of https server, omitted in the location, if it is 443 or zero
+}
+
+// ServeHTTP responds with the permanent redirect
+func (rh RedirectHTTPS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if rh.Port != 0 && rh.Port != 443 {
+		host = net.JoinHostPort(host, strconv.Itoa(rh.Port))
+	}
+	status := http.StatusMovedPermanently
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		status = http.StatusPermanentRedirect // the method and the body are kept
+	}
+	http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), status)
+}
@@ -0,0 +1,139 @@
+package rest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLS_Setup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "decompract-tls")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	cert, key := writeCert(t, dir)
+
+	assert.False(t, TLS{}.Enabled())
+	assert.True(t, TLS{KeyFile: key}.Enabled())
+	assert.True(t, TLS{Domains: []string{"example.com"}}.Enabled())
+
+	setup, err := TLS{CertFile: cert, KeyFile: key}.Setup()
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), setup.Config.MinVersion)
+	assert.Len(t, setup.Config.Certificates, 1)
+	assert.Nil(t, setup.Challenge)
+	assert.Nil(t, setup.Obtain)
+
+	cache := filepath.Join(dir, "autocert")
+	setup, err = TLS{Domains: []string{"example.com"}, CacheDir: cache}.Setup()
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), setup.Config.MinVersion)
+	assert.NotNil(t, setup.Config.GetCertificate)
+	assert.Equal(t, []string{"http/1.1", "acme-tls/1"}, setup.Config.NextProtos)
+	assert.NotNil(t, setup.Challenge)
+	assert.NotNil(t, setup.Obtain)
+	assert.DirExists(t, cache)
+
+	tbl := []struct {
+		tls TLS
+		err string
+	}{
+		{TLS{CertFile: cert}, "both certificate and key files must be set"},
+		{TLS{CertFile: cert, KeyFile: filepath.Join(dir, "unknown")}, "failed to load certificate " + cert},
+		{TLS{CertFile: key, KeyFile: key}, "failed to load certificate " + key},
+		{TLS{CertFile: cert, KeyFile: key, Domains: []string{"example.com"}}, "mutually exclusive"},
+		{TLS{Domains: []string{"example.com"}}, "cache directory of autocert must be set"},
+		{TLS{Domains: []string{"example.com"}, CacheDir: filepath.Join(cert, "cache")}, "failed to make cache directory"},
+	}
+	for _, tt := range tbl {
+		_, err := tt.tls.Setup()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), tt.err)
+	}
+}
+
+func TestTLS_Obtain(t *testing.T) {
+	dir, err := ioutil.TempDir("", "decompract-tls")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	// the acme directory is unavailable
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	setup, err := TLS{Domains: []string{"example.com"}, CacheDir: dir, directoryURL: ts.URL}.Setup()
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err = setup.Obtain(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to obtain certificate for example.com")
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	err = setup.Obtain(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to obtain certificate for example.com")
+}
+
+func TestRedirectHTTPS(t *testing.T) {
+	tbl := []struct {
+		method, target string
+		port           int
+		status         int
+		location       string
+	}{
+		{http.MethodGet, "http://example.com/api/v1/info?a=1", 0, http.StatusMovedPermanently, "https://example.com/api/v1/info?a=1"},
+		{http.MethodGet, "http://example.com:8080/", 443, http.StatusMovedPermanently, "https://example.com/"},
+		{http.MethodHead, "http://example.com:8080/", 8443, http.StatusMovedPermanently, "https://example.com:8443/"},
+		{http.MethodPost, "http://example.com/api/v1/solve", 0, http.StatusPermanentRedirect, "https://example.com/api/v1/solve"},
+	}
+	for _, tt := range tbl {
+		rec := httptest.NewRecorder()
+		RedirectHTTPS{Port: tt.port}.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, strings.NewReader("")))
+		assert.Equal(t, tt.status, rec.Code, tt.target)
+		assert.Equal(t, tt.location, rec.Header().Get("Location"), tt.target)
+	}
+}
+
+// writeCert writes the self-signed certificate of localhost and its key to the directory
+func writeCert(t *testing.T, dir string) (cert, key string) {
+	pk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &pk.PublicKey, pk)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(pk)
+	require.NoError(t, err)
+
+	cert, key = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, ioutil.WriteFile(cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, ioutil.WriteFile(key, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return cert, key
+}
@@ -0,0 +1,24 @@
+package solve
+
+import (
+	"math"
+)
+
+// MethodTraits describes methods of the registry for Advise: the order of the global error, evaluations of f
+// per step and the limit of h|df/dy| on the negative real axis, beyond which the method is unstable.
+// Advise takes registered methods with traits only, the trapezoidal rule doesn't damp fast components
+// of stiff problems and methods with the adaptive step have no fixed order, so they are not advised
+var MethodTraits = map[string]Traits{
+	"euler":  {Order: 1, Stages: 1, Stability: 2},
+	"ieuler": {Order: 2, Stages: 2, Stability: 2},
+	"rk4":    {Order: 4, Stages: 4, Stability: 2.785},
+	// f and df/dy by the central difference at about two iterations of Newton's method
+	"beuler": {Order: 1, Stages: 6, Stability: math.Inf(1), Implicit: true},
+}
+
+// Traits are the order, the stages and the limit of stability of the method
+type Traits struct {
+	Order, Stages int
+	Stability     float64
+	Implicit      bool
+}
@@ -0,0 +1,12 @@
+package solve
+
+import (
+	"github.com/Semior001/decompract/app/rest"
+)
+
+// Item is either the result of solving the problem or the error
+type Item struct {
+	Result *Response           `json:"result,omitempty"`
+	Error  *rest.ErrorResponse `json:"error,omitempty"` // describes why the problem is not solved
+	Took   string              `json:"took"`
+}
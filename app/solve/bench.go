@@ -0,0 +1,157 @@
+package solve
+
+import (
+	"io"
+	"math"
+	"runtime"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/expr"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/report"
+	"github.com/pkg/errors"
+)
+
+// BenchReport is the performance of methods, measured by Bench
+type BenchReport []BenchResult
+
+// BenchResult aggregates measured runs of the method, the number of points and evaluations
+// is the same in each run
+type BenchResult struct {
+	Method       string    `json:"method"`
+	Name         string    `json:"name"`
+	Runs         int       `json:"runs"`
+	Points       int       `json:"points"`
+	Evals        int       `json:"evals"` // evaluations of f(x,y)
+	Wall         BenchStat `json:"wall_ns"`
+	PointsPerSec BenchStat `json:"points_per_sec"`
+	EvalsPerSec  BenchStat `json:"evals_per_sec"`
+	Allocs       BenchStat `json:"allocs"` // heap allocations
+	Bytes        BenchStat `json:"bytes"`  // allocated bytes
+}
+
+// BenchStat is the aggregate of the metric over runs
+type BenchStat struct {
+	Min    float64 `json:"min"`
+	Median float64 `json:"median"`
+	Max    float64 `json:"max"`
+}
+
+// benchRun is the measurement of the single run
+type benchRun struct {
+	wall          time.Duration
+	points, evals int
+	allocs, bytes uint64
+}
+
+// Bench solves the problem with each method once to warm up and then repeat times, measuring each run,
+// points are drawn to the drawer, that only counts them, so the cost of the solver is measured,
+// all methods are measured, if none are set
+func Bench(prob Problem, repeat int) (BenchReport, error) {
+	if repeat < 1 {
+		return nil, errors.Errorf("number of runs must be positive, got %d", repeat)
+	}
+	req := prob.Request()
+	if len(req.Methods) == 0 {
+		req.Methods = solver.Methods()
+	}
+	for _, m := range req.Methods {
+		if m == ExactMethod {
+			return nil, errors.New("exact solution can't be measured, it is not a method")
+		}
+	}
+	// the number of steps is up to the user, as the problem is solved locally
+	p, err := req.Prepare(Limits{MaxSteps: math.MaxInt32})
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid problem")
+	}
+	f, _ := expr.Parse2(req.F, req.Params, "x", "y") // already parsed by prepare
+
+	res := make(BenchReport, 0, len(p.Methods))
+	for _, method := range p.Methods {
+		slvr := solver.Meter(Builder(method), f)
+		d := solver.DrawerFunc(func(num.Point) error { return nil })
+		run := func() (benchRun, error) {
+			runtime.GC()
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			err := slvr.Solve(p.StepOf(method), req.X0, req.Y0, req.XEnd, d)
+			runtime.ReadMemStats(&after)
+			if err != nil {
+				return benchRun{}, errors.Wrapf(err, "failed to solve with %s", method)
+			}
+			cost := slvr.Stats()
+			return benchRun{wall: cost.Took, points: cost.Points, evals: cost.Evals,
+				allocs: after.Mallocs - before.Mallocs, bytes: after.TotalAlloc - before.TotalAlloc}, nil
+		}
+
+		if _, err := run(); err != nil {
+			return nil, err
+		}
+		runs := make([]benchRun, repeat)
+		for i := range runs {
+			if runs[i], err = run(); err != nil {
+				return nil, err
+			}
+		}
+		res = append(res, aggregate(method, slvr.Name(), runs))
+	}
+	return res, nil
+}
+
+// aggregate makes the result of the method from its runs
+func aggregate(method, name string, runs []benchRun) BenchResult {
+	res := BenchResult{Method: method, Name: name, Runs: len(runs), Points: runs[0].points, Evals: runs[0].evals}
+	stat := func(metric func(r benchRun) float64) BenchStat {
+		vals := make([]float64, len(runs))
+		for i, r := range runs {
+			vals[i] = metric(r)
+		}
+		sort.Float64s(vals)
+		median := vals[len(vals)/2]
+		if len(vals)%2 == 0 {
+			median = (vals[len(vals)/2-1] + median) / 2
+		}
+		return BenchStat{Min: vals[0], Median: median, Max: vals[len(vals)-1]}
+	}
+	res.Wall = stat(func(r benchRun) float64 { return float64(r.wall) })
+	// the clock might not tick during the tiny run
+	secs := func(r benchRun) float64 { return math.Max(r.wall.Seconds(), 1e-9) }
+	res.PointsPerSec = stat(func(r benchRun) float64 { return float64(r.points) / secs(r) })
+	res.EvalsPerSec = stat(func(r benchRun) float64 { return float64(r.evals) / secs(r) })
+	res.Allocs = stat(func(r benchRun) float64 { return float64(r.allocs) })
+	res.Bytes = stat(func(r benchRun) float64 { return float64(r.bytes) })
+	return res
+}
+
+// Write writes the report in the format of the output, rows of the table are min, median and max
+// of metrics of each method, rates have three significant digits, unless the precision is set
+func (rep BenchReport) Write(wr io.Writer, out report.Output) error {
+	return out.Write(wr, rep)
+}
+
+// Table returns the header and the rows with min, median and max of metrics of each method
+func (rep BenchReport) Table(prec int) (header []string, rows [][]string) {
+	if prec == 0 {
+		prec = 3
+	}
+	header = []string{"method", "stat", "wall", "points/s", "evals/s", "allocs", "bytes"}
+	for _, res := range rep {
+		for _, s := range []struct {
+			name string
+			val  func(st BenchStat) float64
+		}{
+			{"min", func(st BenchStat) float64 { return st.Min }},
+			{"median", func(st BenchStat) float64 { return st.Median }},
+			{"max", func(st BenchStat) float64 { return st.Max }},
+		} {
+			rows = append(rows, []string{res.Method, s.name, time.Duration(s.val(res.Wall)).String(),
+				formatDigits(s.val(res.PointsPerSec), prec), formatDigits(s.val(res.EvalsPerSec), prec),
+				strconv.FormatFloat(s.val(res.Allocs), 'f', -1, 64), strconv.FormatFloat(s.val(res.Bytes), 'f', -1, 64)})
+		}
+	}
+	return header, rows
+}
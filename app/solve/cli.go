@@ -0,0 +1,248 @@
+package solve
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/Semior001/decompract/app/num/graph"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/report"
+	"github.com/pkg/errors"
+)
+
+// Problem is the initial value problem to solve without the server, fields are the same as in the solve request
+type Problem struct {
+	Name        string             // name of the problem in reports, e.g. the name of the preset
+	Description string             // describes the problem of the preset
+	F           string             // f(x,y) = y'
+	Exact       string             // y(x,c), the exact solution
+	C           string             // C(x0,y0), the constant for the exact solution
+	Params      map[string]float64 // named constants of formulas
+	X0          float64
+	Y0          float64
+	XEnd        float64
+	N           int            // number of steps
+	Step        float64        // step size, used if n is not set
+	NByMethod   map[string]int // numbers of steps by methods instead of n and step, each method has its own grid
+	Methods     []string
+	// RefineTol refines grids of methods, doubling n until successive solutions differ by less than it,
+	// at most RefineDoublings times, or up to the limit of steps, if it is zero, grids are not refined, if RefineTol is zero
+	RefineTol       float64
+	RefineDoublings int
+}
+
+// Request returns the solve request of the problem
+func (prob Problem) Request() Request {
+	req := Request{F: prob.F, Exact: prob.Exact, C: prob.C, Params: prob.Params, X0: prob.X0, Y0: prob.Y0,
+		XEnd: prob.XEnd, N: prob.N, Step: prob.Step, NByMethod: prob.NByMethod, Methods: prob.Methods}
+	if prob.RefineTol != 0 {
+		req.AutoRefine = &autoRefine{Tol: prob.RefineTol, MaxDoublings: prob.RefineDoublings}
+	}
+	return req
+}
+
+// LoadProblem reads the problem document from the file, json files are recognized by the extension,
+// the rest are read as yaml, fields unknown to the document are rejected
+func LoadProblem(path string) (Problem, report.Output, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Problem{}, report.Output{}, errors.Wrap(err, "failed to read problem file")
+	}
+
+	doc := Document{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&doc)
+	} else {
+		doc, err = DecodeYAML(bytes.NewReader(data))
+	}
+	if err != nil {
+		return Problem{}, report.Output{}, errors.Wrapf(err, "failed to decode %s", path)
+	}
+	if doc.Save {
+		return Problem{}, report.Output{}, errors.Errorf("save is not supported in %s, results are saved only by the server", path)
+	}
+
+	req := doc.Request
+	prob := Problem{F: req.F, Exact: req.Exact, C: req.C, Params: req.Params, X0: req.X0, Y0: req.Y0, XEnd: req.XEnd,
+		N: req.N, Step: req.Step, NByMethod: req.NByMethod, Methods: req.Methods}
+	if req.AutoRefine != nil {
+		prob.RefineTol, prob.RefineDoublings = req.AutoRefine.Tol, req.AutoRefine.MaxDoublings
+	}
+	return prob, doc.Output, nil
+}
+
+// Solve validates and solves the problem under the limits, as the solve request does, and writes
+// the solution to wr in the format of the output, unlike the request, the failure of any method is returned
+func Solve(ctx context.Context, prob Problem, l Limits, out report.Output, wr io.Writer) error {
+	if err := out.Validate(); err != nil {
+		return err
+	}
+	p, err := prob.Request().Prepare(l.Effective())
+	if err != nil {
+		return errors.Wrap(err, "invalid problem")
+	}
+
+	resp, err := p.Solve(ctx)
+	if err != nil {
+		return err
+	}
+	lines := resp.Lines
+	if resp.Exact != nil {
+		lines = append(lines, *resp.Exact)
+	}
+	for _, line := range lines {
+		if line.Error != nil {
+			return errors.New(line.Error.Error)
+		}
+	}
+
+	return out.Write(wr, resp)
+}
+
+// Comparison is the report of methods, compared on the problem
+type Comparison struct {
+	resp CompareResp
+}
+
+// Compare validates the problem under the limits and compares methods, as the compare request does,
+// all methods are compared, if none are set, failed methods are reported by Err. Methods are solved
+// by workers at once, GOMAXPROCS if it is not positive, the single worker solves them one after another,
+// the report is the same in both cases
+func Compare(ctx context.Context, prob Problem, l Limits, workers int) (Comparison, error) {
+	cmp, err := PrepareComparison(prob.Request(), l.Effective())
+	if err != nil {
+		return Comparison{}, errors.Wrap(err, "invalid problem")
+	}
+	cmp.workers = workers
+	resp, err := cmp.Run(ctx)
+	if err != nil {
+		return Comparison{}, err
+	}
+	return Comparison{resp: resp}, nil
+}
+
+// Err returns the error of the first failed method, nil if all methods succeeded
+func (c Comparison) Err() error {
+	for _, row := range c.resp.Rows {
+		if row.Error != nil {
+			return errors.New(row.Error.Error)
+		}
+	}
+	return nil
+}
+
+// Write writes the report in the format of the output
+func (c Comparison) Write(wr io.Writer, out report.Output) error {
+	return out.Write(wr, c.resp)
+}
+
+// Chart renders the chart of global errors of methods by x
+func (c Comparison) Chart(img graph.Image) ([]byte, error) {
+	return c.resp.Chart(graph.Plotter{}, img)
+}
+
+// Refinement is the report of refinements of grids of methods on the problem
+type Refinement struct {
+	resp refinementResp
+}
+
+// Refine validates the problem under the limits and solves it with each method, doubling the number of steps
+// from n of the problem, as the solve request with auto_refine does, all methods are refined, if none are set,
+// the tolerance and doublings are RefineTol and RefineDoublings of the problem, methods, that failed
+// or are not converged, are reported by Err
+func Refine(ctx context.Context, prob Problem, l Limits) (Refinement, error) {
+	if prob.RefineTol == 0 {
+		return Refinement{}, errors.New("tolerance of the refinement is not set")
+	}
+	if len(prob.Methods) == 0 {
+		prob.Methods = solver.Methods()
+	}
+	p, err := prob.Request().Prepare(l.Effective())
+	if err != nil {
+		return Refinement{}, errors.Wrap(err, "invalid problem")
+	}
+	resp, err := p.Solve(ctx)
+	if err != nil {
+		return Refinement{}, err
+	}
+	return Refinement{resp: newRefinementResp(prob.RefineTol, resp)}, nil
+}
+
+// Err returns the error of the first failed method, or ErrNotConverged of the first method, that is not converged,
+// nil if all methods are converged
+func (r Refinement) Err() error {
+	for _, line := range r.resp.Lines {
+		if line.Error != nil {
+			return errors.New(line.Error.Error)
+		}
+		if ref := line.Refine; ref != nil && !ref.Converged {
+			return errors.Wrapf(solver.ErrNotConverged, "%s has max difference %g with n=%d, tolerance is %g",
+				line.Method, ref.Diffs[len(ref.Diffs)-1], ref.N, r.resp.Tol)
+		}
+	}
+	return nil
+}
+
+// Write writes the report in the format of the output
+func (r Refinement) Write(wr io.Writer, out report.Output) error {
+	return out.Write(wr, r.resp)
+}
+
+// SweepRange is the numbers of steps of the error sweep, from From to To, each of them or doubling
+// the number from row to row, if the sweep is geometric
+type SweepRange struct {
+	From      int
+	To        int
+	Geometric bool
+}
+
+// ErrorSweep is the report of errors of methods by the number of steps, solved over the range
+type ErrorSweep struct {
+	resp ErrorsResp
+}
+
+// SweepErrors validates the problem under the limits and solves it with each method and number of steps in the range,
+// as the errors request does, all methods are swept, if none are set, the reference is the exact solution,
+// if it is set, or the fine solution by rk4, failed methods are reported by Err. Solutions with each method
+// and number of steps are made by workers at once, as in Compare, if the context is done, the report
+// of completed solutions is returned along with the error of the context
+func SweepErrors(ctx context.Context, prob Problem, rng SweepRange, l Limits, workers int) (ErrorSweep, error) {
+	req := SweepReq{Request: prob.Request(), N0: rng.From, N1: rng.To, Geometric: rng.Geometric}
+	if len(req.Methods) == 0 {
+		req.Methods = solver.Methods()
+	}
+	sw, err := req.Prepare(l.Effective())
+	if err != nil {
+		return ErrorSweep{}, errors.Wrap(err, "invalid problem")
+	}
+	sw.Workers = workers
+	resp, err := sw.Run(ctx)
+	return ErrorSweep{resp: resp}, err
+}
+
+// Err returns the error of the first method, that failed with each number of steps, nil if all methods succeeded
+func (e ErrorSweep) Err() error {
+	for _, line := range e.resp.Lines {
+		if line.Error != nil {
+			return errors.New(line.Error.Error)
+		}
+	}
+	return nil
+}
+
+// Write writes the report in the format of the output
+func (e ErrorSweep) Write(wr io.Writer, out report.Output) error {
+	return out.Write(wr, e.resp)
+}
+
+// Chart renders the log-log chart of max errors of methods by the number of steps
+func (e ErrorSweep) Chart(img graph.Image) ([]byte, error) {
+	return e.resp.Chart(graph.Plotter{}, img)
+}
@@ -0,0 +1,200 @@
+package solve
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/analyzer"
+	"github.com/Semior001/decompract/app/num/graph"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/pkg/errors"
+)
+
+// CompareResp is the report of methods, compared on the problem, errors are measured against
+// the exact solution, if it is set, or against the fine solution by referenceMethod
+type CompareResp struct {
+	Reference   string       `json:"reference"`
+	Step        float64      `json:"step"`                   // step of methods, or the least one, if grids differ
+	GridsDiffer bool         `json:"grids_differ,omitempty"` // methods have own steps in rows
+	Rows        []CompareRow `json:"rows"`
+	Took        string       `json:"took"`
+
+	errLines []num.Line // global errors of methods by x, drawn on the chart
+}
+
+// CompareRow describes the accuracy and the cost of the method on the problem
+type CompareRow struct {
+	Method string              `json:"method"`
+	Name   string              `json:"name"`
+	Step   float64             `json:"step,omitempty"` // step of the method, if grids of methods differ
+	MaxGTE float64             `json:"max_gte"`        // max of global truncation errors at the nodes
+	Mean   float64             `json:"mean"`           // mean of global errors at the nodes
+	RMS    float64             `json:"rms"`            // sqrt(mean(gte^2)), the root mean square of errors
+	L2     float64             `json:"l2"`             // sqrt(step * sum(gte^2)), the discrete L2 norm of errors
+	Evals  int                 `json:"evals"`          // number of evaluations of f(x,y)
+	Error  *rest.ErrorResponse `json:"error,omitempty"`
+	Took   string              `json:"took"`
+}
+
+// PreparedComparison is the validated problem of the compare request, ready to run
+type PreparedComparison struct {
+	Problem Prepared
+	sw      Sweep // sweep with the single n, gives the reference of the problem
+	workers int   // methods, compared at once, GOMAXPROCS if not positive
+}
+
+// PrepareComparison validates the problem under the limits, all methods are compared, if none are requested,
+// the exact solution can't be compared, as it is the reference, if it is set
+func PrepareComparison(req Request, l Limits) (PreparedComparison, error) {
+	if len(req.Methods) == 0 {
+		req.Methods = solver.Methods()
+	}
+	if req.AutoRefine != nil {
+		return PreparedComparison{}, rest.ValidationError{{Field: "auto_refine", Msg: "is not supported by comparisons"}}
+	}
+	p, err := req.Prepare(l)
+	if err != nil {
+		return PreparedComparison{}, err
+	}
+	// the reference is refined from the same number of steps, or from the largest one of methods
+	n := req.N
+	if n == 0 {
+		n = int(math.Ceil(req.Steps()))
+	}
+	ref := req
+	ref.NByMethod = nil
+	sw, err := SweepReq{Request: ref, N0: n, N1: n}.Prepare(Limits{MaxSteps: l.MaxSteps, MaxSweep: 1})
+	if err != nil {
+		return PreparedComparison{}, err
+	}
+	return PreparedComparison{Problem: p, sw: sw}, nil
+}
+
+// Run solves the problem with each method by workers of the comparison and compares the solutions with
+// the reference, each method has its own collector and row, so the report doesn't depend on the order,
+// methods are completed in, the failure of the method is reported in its row, the comparison fails
+// only if all methods fail
+func (cmp PreparedComparison) Run(ctx context.Context) (CompareResp, error) {
+	st := time.Now()
+	resp := CompareResp{Reference: ExactMethod, Step: cmp.Problem.Step, GridsDiffer: cmp.Problem.steps != nil}
+	if cmp.sw.exact == nil {
+		resp.Reference = referenceMethod
+	}
+
+	ref, err := cmp.sw.Reference(ctx)
+	if err != nil {
+		return CompareResp{}, err
+	}
+
+	resp.Rows = make([]CompareRow, len(cmp.Problem.Methods))
+	resp.errLines = make([]num.Line, len(cmp.Problem.Methods))
+	err = ForEach(ctx, len(cmp.Problem.Methods), cmp.workers, func(ctx context.Context, i int) (err error) {
+		resp.Rows[i], resp.errLines[i], err = cmp.compareWith(ctx, cmp.Problem.Methods[i], ref)
+		return err
+	})
+	if err != nil {
+		return CompareResp{}, err
+	}
+
+	if failed := resp.failed(); len(failed) == len(resp.Rows) {
+		return CompareResp{}, errors.Errorf("all methods failed, %s", resp.Rows[0].Error.Error)
+	}
+	resp.Took = time.Since(st).String()
+	return resp, nil
+}
+
+// compareWith solves the problem with the method, counting evaluations of f, and measures its errors
+func (cmp PreparedComparison) compareWith(ctx context.Context, method string,
+	ref func(x float64) (float64, error)) (CompareRow, num.Line, error) {
+	st := time.Now()
+	evals := &solver.CountingFunc{F: cmp.sw.F}
+	slvr := Builder(method)(evals.Eval)
+	row := CompareRow{Method: method, Name: slvr.Name()}
+	fail := func(err error, details string) (CompareRow, num.Line, error) {
+		be := rest.NewErrorResponse(err, details, rest.ErrInternal)
+		row.Error, row.Evals, row.Took = &be, evals.Calls(), time.Since(st).String()
+		return row, num.Line{}, nil
+	}
+
+	x0, y0, xEnd := cmp.Problem.Req.X0, cmp.Problem.Req.Y0, cmp.Problem.Req.XEnd
+	step := cmp.Problem.StepOf(method)
+	c := Collector(step, x0, xEnd)
+	err := slvr.Solve(step, x0, y0, xEnd, WithRequest(ctx, c))
+	if err == nil {
+		err = Solved(c)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return CompareRow{}, num.Line{}, &TimeoutError{Method: method, XReached: LastX(c.Points, x0)}
+	}
+	if ctx.Err() != nil {
+		return CompareRow{}, num.Line{}, ctx.Err()
+	}
+	if err != nil {
+		return fail(errors.Wrapf(err, "failed to solve with %s", method), "failed to solve")
+	}
+
+	gte, err := analyzer.Global(c.Points, ref)
+	if err != nil {
+		return fail(errors.Wrapf(err, "failed to calculate errors of %s", method), "solution diverged")
+	}
+	sum, sumSq := 0.0, 0.0
+	for _, e := range gte {
+		row.MaxGTE = math.Max(row.MaxGTE, e.Y)
+		sum += e.Y
+		sumSq += e.Y * e.Y
+	}
+	if len(gte) > 0 {
+		row.Mean, row.RMS = sum/float64(len(gte)), math.Sqrt(sumSq/float64(len(gte)))
+	}
+	row.L2 = math.Sqrt(step * sumSq)
+	if cmp.Problem.steps != nil {
+		row.Step = step
+	}
+	row.Evals, row.Took = evals.Calls(), time.Since(st).String()
+	return row, num.Line{Name: slvr.Name(), Points: gte}, nil
+}
+
+// failed returns the names of failed methods
+func (resp CompareResp) failed() []string {
+	var res []string
+	for _, row := range resp.Rows {
+		if row.Error != nil {
+			res = append(res, row.Method)
+		}
+	}
+	return res
+}
+
+// Table returns the header and the rows of the report, errors are formatted in the scientific notation
+// with prec significant digits, four by default, metrics of failed methods are left as dashes
+func (resp CompareResp) Table(prec int) (header []string, rows [][]string) {
+	if prec == 0 {
+		prec = 4
+	}
+	header = []string{"method", "max_gte", "mean", "rms", "l2", "evals", "took"}
+	for _, row := range resp.Rows {
+		errs := []string{"-", "-", "-", "-"}
+		if row.Error == nil {
+			for i, v := range []float64{row.MaxGTE, row.Mean, row.RMS, row.L2} {
+				errs[i] = strconv.FormatFloat(v, 'e', prec-1, 64)
+			}
+		}
+		rows = append(rows, append(append([]string{row.Method}, errs...), strconv.Itoa(row.Evals), row.Took))
+	}
+	return header, rows
+}
+
+// Chart plots the global errors of methods by x, failed methods are skipped
+func (resp CompareResp) Chart(pl graph.Plotter, img graph.Image) ([]byte, error) {
+	var lines []num.Line
+	for i, row := range resp.Rows {
+		if row.Error == nil {
+			lines = append(lines, resp.errLines[i])
+		}
+	}
+	return pl.PlotImage("Global errors", "X", "GTE", lines, img)
+}
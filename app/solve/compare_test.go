@@ -0,0 +1,47 @@
+package solve
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare_Workers(t *testing.T) {
+	prob := Presets["canonical"]
+	prob.N, prob.Methods = 1000, []string{"rk4", "euler", "ieuler", "euler"}
+	seq, err := Compare(context.Background(), prob, Limits{}, 1)
+	require.NoError(t, err)
+	for _, workers := range []int{0, 2, 4} {
+		par, err := Compare(context.Background(), prob, Limits{}, workers)
+		require.NoError(t, err)
+		require.Len(t, par.resp.Rows, 4)
+		for i, row := range par.resp.Rows {
+			expected := seq.resp.Rows[i]
+			assert.Equal(t, prob.Methods[i], row.Method, "rows are in the order of methods")
+			expected.Took, row.Took = "", ""
+			assert.Equal(t, expected, row, "workers=%d", workers)
+			assert.Equal(t, seq.resp.errLines[i], par.resp.errLines[i])
+		}
+	}
+}
+
+// BenchmarkCompare compares four methods with 1e5 steps each one after another and by GOMAXPROCS workers
+func BenchmarkCompare(b *testing.B) {
+	prob := Presets["canonical"]
+	prob.N, prob.Methods = 100000, []string{"euler", "ieuler", "rk4", "rk4"}
+	for _, bb := range []struct {
+		name    string
+		workers int
+	}{{"sequential", 1}, {"parallel", 0}} {
+		b.Run(bb.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := Compare(context.Background(), prob, Limits{MaxSteps: prob.N}, bb.workers); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package solve
+
+import (
+	"math"
+	"sync/atomic"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/solver"
+)
+
+// JobProgress tracks x, reached by each method of the job, values are kept as bits of floats,
+// so drawers of methods update them without locks
+type JobProgress struct {
+	X0, XEnd float64
+	Reached  map[string]*uint64 // method -> bits of the last drawn x, the map is not changed after it is made
+}
+
+// Wrap wraps the drawer of the method to track the reached x, the drawer is returned as is, if nothing is tracked
+func (jp *JobProgress) Wrap(method string, d solver.Drawer) solver.Drawer {
+	if jp == nil {
+		return d
+	}
+	bits, ok := jp.Reached[method]
+	if !ok {
+		return d
+	}
+	return solver.WithObserver(d, func(p num.Point, err error) {
+		if err == nil {
+			atomic.StoreUint64(bits, math.Float64bits(p.X))
+		}
+	})
+}
+
+// Value returns the mean share of the interval, covered by methods, methods, that are refined, are not tracked,
+// so they count as not started until the job is done
+func (jp *JobProgress) Value() float64 {
+	if jp == nil || len(jp.Reached) == 0 || jp.XEnd == jp.X0 {
+		return 0
+	}
+	sum := 0.0
+	for _, bits := range jp.Reached {
+		x := math.Float64frombits(atomic.LoadUint64(bits))
+		sum += math.Max(0, math.Min(1, (x-jp.X0)/(jp.XEnd-jp.X0)))
+	}
+	return sum / float64(len(jp.Reached))
+}
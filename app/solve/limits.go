@@ -0,0 +1,136 @@
+package solve
+
+import (
+	"math"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/solver"
+)
+
+// default limits of steps of the solve request and the job and of problems in the batch
+const (
+	DefaultMaxSteps    = 10000
+	DefaultMaxJobSteps = 1000000
+	DefaultMaxBatch    = 100
+)
+
+// Limits restricts the resources, consumed by solve requests, zero values of steps, batch, sweep and job steps
+// are replaced by defaults, the rest of limits are disabled with zero values
+type Limits struct {
+	MaxSteps      int     `json:"max_steps"`      // steps of the single solve
+	MaxWidth      float64 `json:"max_width"`      // width of the interval, |x_end - x0|
+	MaxBatch      int     `json:"max_batch"`      // problems in the batch request
+	MaxConcurrent int     `json:"max_concurrent"` // lines, solved by the server at once, each method of the request is a line
+	MaxPoints     int     `json:"max_points"`     // points of the line in the response, longer lines are downsampled
+	MaxSweep      int     `json:"max_sweep"`      // values of n in the error sweep
+	MaxPerClient  int     `json:"max_per_client"` // computational requests of the single client in flight at once
+	MaxJobSteps   int     `json:"max_job_steps"`  // steps of the single solve of the job, at least max_steps
+}
+
+// Effective returns the limits with defaults in place of zero steps, batch, sweep and job steps
+// and disabled negative limits
+func (l Limits) Effective() Limits {
+	if l.MaxSteps <= 0 {
+		l.MaxSteps = DefaultMaxSteps
+	}
+	if l.MaxBatch <= 0 {
+		l.MaxBatch = DefaultMaxBatch
+	}
+	if l.MaxSweep <= 0 {
+		l.MaxSweep = DefaultMaxSweep
+	}
+	if l.MaxJobSteps <= 0 {
+		l.MaxJobSteps = DefaultMaxJobSteps
+	}
+	if l.MaxJobSteps < l.MaxSteps {
+		l.MaxJobSteps = l.MaxSteps
+	}
+	if l.MaxWidth < 0 {
+		l.MaxWidth = 0
+	}
+	if l.MaxConcurrent < 0 {
+		l.MaxConcurrent = 0
+	}
+	if l.MaxPoints < 0 {
+		l.MaxPoints = 0
+	}
+	if l.MaxPerClient < 0 {
+		l.MaxPerClient = 0
+	}
+	if l.MaxPoints == 1 {
+		l.MaxPoints = 2 // both ends of the interval are kept
+	}
+	return l
+}
+
+// Lines returns the number of lines of the problem, including the exact solution
+func (p Prepared) Lines() int {
+	if p.Exact != nil {
+		return len(p.Solvers) + 1
+	}
+	return len(p.Solvers)
+}
+
+// stride returns the distance between indexes of the kept points to keep at most max
+// of count points, including both ends
+func stride(count, max int) int {
+	if max <= 0 || count <= max {
+		return 1
+	}
+	return int(math.Ceil(float64(count-1) / float64(max-1)))
+}
+
+// Downsample keeps at most max points of the line, evenly spaced by indexes, the ends of the line are kept
+func Downsample(pts []num.Point, max int) []num.Point {
+	k := stride(len(pts), max)
+	if k == 1 {
+		return pts
+	}
+	res := make([]num.Point, 0, max)
+	for i := 0; i < len(pts); i += k {
+		res = append(res, pts[i])
+	}
+	if (len(pts)-1)%k != 0 {
+		res = append(res, pts[len(pts)-1])
+	}
+	return res
+}
+
+// thinning drops points, passed to the drawer, to keep at most max of approximately count points,
+// the last point is passed only by flush
+type thinning struct {
+	next    solver.Drawer
+	k       int
+	i       int
+	last    num.Point
+	pending bool
+}
+
+// thin returns the drawer, that downsamples the points of the method, as Downsample does
+func (p Prepared) thin(method string, d solver.Drawer) *thinning {
+	n, err := solver.StepsCount(p.StepOf(method), p.Req.X0, p.Req.XEnd)
+	if err != nil {
+		n = 0 // the solver refuses the step, so nothing is thinned
+	}
+	return &thinning{next: d, k: stride(n, p.maxPoints)}
+}
+
+// Draw passes each k-th point to the wrapped drawer
+func (t *thinning) Draw(pt num.Point) error {
+	defer func() { t.i++ }()
+	if t.i%t.k == 0 {
+		t.pending = false
+		return t.next.Draw(pt)
+	}
+	t.last, t.pending = pt, true
+	return nil
+}
+
+// flush passes the last point, if it is dropped
+func (t *thinning) flush() error {
+	if !t.pending {
+		return nil
+	}
+	t.pending = false
+	return t.next.Draw(t.last)
+}
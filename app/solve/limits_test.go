@@ -0,0 +1,44 @@
+package solve
+
+import (
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownsample(t *testing.T) {
+	line := func(n int) []num.Point {
+		pts := make([]num.Point, n)
+		for i := range pts {
+			pts[i] = num.Point{X: float64(i)}
+		}
+		return pts
+	}
+	xs := func(pts []num.Point) (res []float64) {
+		for _, pt := range pts {
+			res = append(res, pt.X)
+		}
+		return res
+	}
+
+	assert.Equal(t, []float64{0, 1, 2}, xs(Downsample(line(3), 0)), "unlimited")
+	assert.Equal(t, []float64{0, 1, 2}, xs(Downsample(line(3), 5)), "short line")
+	assert.Equal(t, []float64{0, 5, 10}, xs(Downsample(line(11), 3)))
+	assert.Equal(t, []float64{0, 4, 8, 10}, xs(Downsample(line(11), 4)), "the end is kept")
+	assert.Equal(t, []float64{0, 10}, xs(Downsample(line(11), 2)))
+
+	// thinning gives the same points, as downsample
+	var got []num.Point
+	d := (&thinning{k: stride(11, 4), next: solver.DrawerFunc(func(pt num.Point) error {
+		got = append(got, pt)
+		return nil
+	})})
+	for _, pt := range line(11) {
+		require.NoError(t, d.Draw(pt))
+	}
+	require.NoError(t, d.flush())
+	assert.Equal(t, []float64{0, 4, 8, 10}, xs(got))
+}
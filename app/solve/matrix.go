@@ -0,0 +1,160 @@
+package solve
+
+import (
+	"context"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/report"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/pkg/errors"
+)
+
+// Matrix is the report of methods, compared on each of problems, the cell of the method and the problem
+// is compared on its own, so the failed cell has the error and the rest of cells are not affected by it
+type Matrix struct {
+	N        int            `json:"n,omitempty"` // number of steps of each problem, steps of problems if zero
+	Methods  []string       `json:"methods"`
+	Problems []string       `json:"problems"`
+	Cells    [][]MatrixCell `json:"cells"` // cells by methods, then by problems
+	Took     string         `json:"took"`
+}
+
+// MatrixCell describes the accuracy and the cost of the method on the problem
+type MatrixCell struct {
+	Reference string              `json:"reference,omitempty"` // exact or the method of the fine solution
+	MaxGTE    float64             `json:"max_gte"`             // max of global truncation errors at the nodes
+	Evals     int                 `json:"evals"`               // number of evaluations of f(x,y)
+	Error     *rest.ErrorResponse `json:"error,omitempty"`
+	Took      string              `json:"took"`
+}
+
+// CompareAll compares each of methods on each of problems with n steps, or with steps of problems, if n is zero,
+// as the compare command does, without limits of steps. All methods are compared, if none are set, cells
+// are compared by GOMAXPROCS workers at once, failed cells are reported by Err of the matrix
+func CompareAll(presets []Problem, methods []string, n int) (*Matrix, error) {
+	return CompareAllUnder(context.Background(), presets, methods, n, Limits{MaxSteps: math.MaxInt32}, 0)
+}
+
+// CompareAllUnder compares cells of the matrix under the limits by workers, GOMAXPROCS if not positive,
+// only the end of the context stops the comparison
+func CompareAllUnder(ctx context.Context, probs []Problem, names []string, n int, l Limits, workers int) (*Matrix, error) {
+	st := time.Now()
+	if len(probs) == 0 {
+		return nil, errors.New("no problems to compare")
+	}
+	if len(names) == 0 {
+		names = solver.Methods()
+	}
+	for _, name := range names {
+		if _, ok := solver.Lookup(name); !ok {
+			return nil, errors.Errorf("unknown method %q, must be one of %s", name, strings.Join(solver.Methods(), ", "))
+		}
+	}
+	if n < 0 {
+		return nil, errors.Errorf("n must not be negative, got %d", n)
+	}
+
+	m := &Matrix{N: n, Methods: names, Problems: make([]string, len(probs)), Cells: make([][]MatrixCell, len(names))}
+	for j, prob := range probs {
+		m.Problems[j] = prob.Name
+		if m.Problems[j] == "" {
+			m.Problems[j] = prob.F
+		}
+	}
+	for i := range m.Cells {
+		m.Cells[i] = make([]MatrixCell, len(probs))
+	}
+
+	err := ForEach(ctx, len(names)*len(probs), workers, func(ctx context.Context, k int) (err error) {
+		i, j := k/len(probs), k%len(probs)
+		m.Cells[i][j], err = compareCell(ctx, probs[j], names[i], n, l)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	m.Took = time.Since(st).String()
+	return m, nil
+}
+
+// compareCell compares the method on the problem, failures of the problem or of the method are reported
+// in the cell, only the end of the context is returned
+func compareCell(ctx context.Context, prob Problem, method string, n int, l Limits) (MatrixCell, error) {
+	st := time.Now()
+	fail := func(err error, details string, code rest.ErrCode) MatrixCell {
+		be := rest.NewErrorResponse(err, details, code)
+		return MatrixCell{Error: &be, Took: time.Since(st).String()}
+	}
+
+	prob.Methods = []string{method}
+	if n > 0 {
+		prob.N, prob.Step, prob.NByMethod = n, 0, nil
+	}
+	cmp, err := PrepareComparison(prob.Request(), l)
+	if err != nil {
+		return fail(errors.Wrap(err, "invalid problem"), "invalid problem", rest.ErrBadRequest), nil
+	}
+
+	cell := MatrixCell{Reference: ExactMethod}
+	if cmp.sw.exact == nil {
+		cell.Reference = referenceMethod
+	}
+	ref, err := cmp.sw.Reference(ctx)
+	if ctx.Err() != nil {
+		return MatrixCell{}, ctx.Err()
+	}
+	if err != nil {
+		return fail(err, "failed to solve the reference", rest.ErrInternal), nil
+	}
+
+	row, _, err := cmp.compareWith(ctx, method, ref)
+	if err != nil {
+		return MatrixCell{}, err
+	}
+	cell.MaxGTE, cell.Evals, cell.Error, cell.Took = row.MaxGTE, row.Evals, row.Error, row.Took
+	return cell, nil
+}
+
+// Err returns the error of the first failed cell, nil if all cells succeeded
+func (m *Matrix) Err() error {
+	for i, cells := range m.Cells {
+		for j, cell := range cells {
+			if cell.Error != nil {
+				return errors.Errorf("%s on %s: %s", m.Methods[i], m.Problems[j], cell.Error.Error)
+			}
+		}
+	}
+	return nil
+}
+
+// Write writes the matrix in the format of the output, the markdown table has rows of methods
+// and columns of problems
+func (m *Matrix) Write(wr io.Writer, out report.Output) error {
+	return out.Write(wr, m)
+}
+
+// Table returns the header and the rows of methods, cells have the max of global errors in the scientific
+// notation with prec significant digits, four by default, and the time, failed cells have the error
+func (m *Matrix) Table(prec int) (header []string, rows [][]string) {
+	if prec == 0 {
+		prec = 4
+	}
+	header = append([]string{"method"}, m.Problems...)
+	for i, cells := range m.Cells {
+		row := []string{m.Methods[i]}
+		for _, cell := range cells {
+			if cell.Error != nil {
+				row = append(row, "failed: "+cell.Error.Error)
+				continue
+			}
+			row = append(row, strconv.FormatFloat(cell.MaxGTE, 'e', prec-1, 64)+" in "+cell.Took)
+		}
+		rows = append(rows, row)
+	}
+	return header, rows
+}
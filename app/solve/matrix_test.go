@@ -0,0 +1,103 @@
+package solve
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/report"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareAll(t *testing.T) {
+	// flaky fails beyond x=5, so it fails only on stiff-decay, solved on [0, 10]
+	solver.Register("flaky", func(f solver.Func) solver.Interface {
+		return &solver.Euler{F: func(x, y float64) (float64, error) {
+			if x > 5 {
+				return 0, errors.New("flaky")
+			}
+			return f(x, y)
+		}}
+	})
+	defer solver.Unregister("flaky")
+
+	probs, err := PresetProblems("canonical", "stiff-decay")
+	require.NoError(t, err)
+	names := []string{"euler", "flaky", "rk4"}
+	m, err := CompareAll(probs, names, 0)
+	require.NoError(t, err)
+	assert.Equal(t, names, m.Methods)
+	assert.Equal(t, []string{"canonical", "stiff-decay"}, m.Problems)
+	assert.NotEmpty(t, m.Took)
+	require.Len(t, m.Cells, 3)
+
+	for i, method := range names {
+		require.Len(t, m.Cells[i], 2)
+		for j, prob := range probs {
+			cell := m.Cells[i][j]
+			assert.NotEmpty(t, cell.Took)
+			if method == "flaky" && prob.Name == "stiff-decay" {
+				require.NotNil(t, cell.Error)
+				assert.Contains(t, cell.Error.Error, "flaky")
+				continue
+			}
+			require.Nil(t, cell.Error, "%s on %s", method, prob.Name)
+			assert.Equal(t, "exact", cell.Reference)
+
+			// the cell is the same as the comparison of the method alone
+			prob.Methods = []string{method}
+			cmp, err := Compare(context.Background(), prob, Limits{MaxSteps: math.MaxInt32}, 1)
+			require.NoError(t, err)
+			require.Len(t, cmp.resp.Rows, 1)
+			assert.Equal(t, cmp.resp.Rows[0].MaxGTE, cell.MaxGTE, "%s on %s", method, prob.Name)
+			assert.Equal(t, cmp.resp.Rows[0].Evals, cell.Evals, "%s on %s", method, prob.Name)
+		}
+	}
+	assert.Equal(t, m.Cells[0][0].MaxGTE, m.Cells[1][0].MaxGTE, "flaky is euler before x=5")
+	assert.EqualError(t, m.Err(), "flaky on stiff-decay: "+m.Cells[1][1].Error.Error)
+
+	// the markdown table has rows of methods and columns of problems
+	buf := &bytes.Buffer{}
+	require.NoError(t, m.Write(buf, report.Output{Format: report.FormatMarkdown}))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 5)
+	assert.Regexp(t, `^\| method +\| canonical +\| stiff-decay +\|$`, lines[0])
+	assert.Regexp(t, `^\| euler +\| 1\.253e-01 in \S+ +\| \d\.\d{3}e-\d\d in \S+ +\|$`, lines[2])
+	assert.Regexp(t, `^\| flaky +\| 1\.253e-01 in \S+ +\| failed: .*flaky.* \|$`, lines[3])
+
+	buf.Reset()
+	require.NoError(t, m.Write(buf, report.Output{Format: report.FormatJSON}))
+	res := Matrix{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &res))
+	assert.Equal(t, m.Cells[2], res.Cells[2])
+}
+
+func TestCompareAll_Errors(t *testing.T) {
+	probs, err := PresetProblems()
+	require.NoError(t, err)
+	assert.Equal(t, "canonical", probs[0].Name, "presets are sorted by names")
+	_, err = PresetProblems("canonical", "unknown")
+	assert.EqualError(t, err, `unknown preset "unknown", available: canonical, dahlquist, logistic, stiff-decay`)
+
+	_, err = CompareAll(nil, nil, 10)
+	assert.EqualError(t, err, "no problems to compare")
+	_, err = CompareAll(probs, []string{"rk5"}, 10)
+	assert.EqualError(t, err, `unknown method "rk5", must be one of ab4, abm4, bdf, beuler, dopri5, euler, ieuler, rk4, rkf45, `+
+		`switching, taylor2, trapezoid`)
+	_, err = CompareAll(probs, nil, -1)
+	assert.EqualError(t, err, "n must not be negative, got -1")
+
+	// the invalid problem fails its cells only
+	m, err := CompareAll([]Problem{probs[0], {F: "y", X0: 1, XEnd: 1, N: 10}}, []string{"rk4"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"canonical", "y"}, m.Problems, "problems without names are named by f")
+	assert.Nil(t, m.Cells[0][0].Error)
+	require.NotNil(t, m.Cells[0][1].Error)
+	assert.Contains(t, m.Cells[0][1].Error.Error, "invalid problem")
+}
@@ -0,0 +1,9 @@
+package solve
+
+import "github.com/Semior001/decompract/app/rest"
+
+// formatDigits formats the number with prec significant digits, or in the shortest representation
+// without the loss of precision, if prec is zero
+func formatDigits(v float64, prec int) string {
+	return rest.NumberFormat{Digits: prec}.Format(v)
+}
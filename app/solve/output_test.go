@@ -0,0 +1,114 @@
+package solve
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/report"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutput_Write(t *testing.T) {
+	be := rest.NewErrorResponse(assert.AnError, "failed to solve", rest.ErrInternal)
+	resp := CompareResp{Reference: ExactMethod, Step: 0.1, Took: "1ms", Rows: []CompareRow{
+		{Method: "euler", Name: "Euler", MaxGTE: 0.12345678, Mean: 0.0456789, RMS: 0.0654321, L2: 0.0123456, Evals: 11, Took: "10µs"},
+		{Method: "rk4", Name: "Runge-Kutta | 4", MaxGTE: 1.5e-7, Mean: 5e-8, RMS: 7.5e-8, L2: 2.25e-8, Evals: 44, Took: "20µs"},
+		{Method: "ieuler", Name: "Improved Euler", Evals: 3, Error: &be, Took: "5µs"},
+	}}
+	write := func(out report.Output) string {
+		buf := &bytes.Buffer{}
+		require.NoError(t, out.Write(buf, resp))
+		return buf.String()
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		rows, err := csv.NewReader(strings.NewReader(write(report.Output{Format: report.FormatCSV}))).ReadAll()
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{
+			{"method", "max_gte", "mean", "rms", "l2", "evals", "took"},
+			{"euler", "1.235e-01", "4.568e-02", "6.543e-02", "1.235e-02", "11", "10µs"},
+			{"rk4", "1.500e-07", "5.000e-08", "7.500e-08", "2.250e-08", "44", "20µs"},
+			{"ieuler", "-", "-", "-", "-", "3", "5µs"},
+		}, rows)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		actual := write(report.Output{Format: report.FormatJSON, Precision: 2})
+		var decoded CompareResp
+		require.NoError(t, json.Unmarshal([]byte(actual), &decoded))
+		assert.Equal(t, resp, decoded, "numbers are not rounded in json")
+		assert.Equal(t, actual, write(report.Output{Format: report.FormatJSON}), "the same report is encoded the same way")
+	})
+
+	t.Run("table", func(t *testing.T) {
+		lines := strings.Split(strings.TrimSuffix(write(report.Output{Format: report.FormatTable, Precision: 2}), "\n"), "\n")
+		assert.Equal(t, []string{
+			"method  max_gte  mean     rms      l2       evals  took",
+			"euler   1.2e-01  4.6e-02  6.5e-02  1.2e-02  11     10µs",
+			"rk4     1.5e-07  5.0e-08  7.5e-08  2.2e-08  44     20µs",
+			"ieuler  -        -        -        -        3      5µs",
+		}, lines)
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		assert.Equal(t, "| method | max_gte   | mean      | rms       | l2        | evals | took |\n"+
+			"| ------ | --------- | --------- | --------- | --------- | ----- | ---- |\n"+
+			"| euler  | 1.235e-01 | 4.568e-02 | 6.543e-02 | 1.235e-02 | 11    | 10µs |\n"+
+			"| rk4    | 1.500e-07 | 5.000e-08 | 7.500e-08 | 2.250e-08 | 44    | 20µs |\n"+
+			"| ieuler | -         | -         | -         | -         | 3     | 5µs  |\n", write(report.Output{Format: report.FormatMarkdown}))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		err := report.Output{Format: "xml"}.Write(&bytes.Buffer{}, resp)
+		assert.EqualError(t, err, `unknown format "xml", must be one of csv, json, table, markdown`)
+		err = report.Output{Format: report.FormatTable, Precision: -1}.Write(&bytes.Buffer{}, resp)
+		assert.EqualError(t, err, "precision must not be negative, got -1")
+	})
+}
+
+func TestSolveResp_TablePrecision(t *testing.T) {
+	resp := Response{Lines: []Line{{Method: "rk4", Points: []num.Point{{X: 0, Y: 1}, {X: 1.0 / 3, Y: 2.0 / 3}}}}}
+	_, rows := resp.Table(0)
+	assert.Equal(t, [][]string{{"0", "1"}, {"0.3333333333333333", "0.6666666666666666"}}, rows)
+	_, rows = resp.Table(3)
+	assert.Equal(t, [][]string{{"0", "1"}, {"0.333", "0.667"}}, rows)
+}
+
+func TestOutput_WriteStats(t *testing.T) {
+	be := rest.NewErrorResponse(assert.AnError, "failed to solve", rest.ErrInternal)
+	resp := Response{Lines: []Line{
+		{Method: "euler", Points: []num.Point{{X: 0, Y: 1}, {X: 1, Y: 0.5}}, Took: "10µs", Stats: &statsResp{Points: 2,
+			Min: &num.Point{X: 1, Y: 0.5}, Max: &num.Point{X: 0, Y: 1}, Final: &num.Point{X: 1, Y: 0.5}, Evals: 1}},
+		{Method: "rk4", Points: []num.Point{}, Error: &be, Took: "5µs"},
+	}, Exact: &Line{Method: ExactMethod, Points: []num.Point{{X: 0, Y: 1}, {X: 1, Y: 1.0 / 3}}, Took: "1µs",
+		Stats: &statsResp{Points: 2, Max: &num.Point{X: 0, Y: 1}, Warnings: []string{"a", "b"}}}}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, report.Output{Format: report.FormatTable, Precision: 2, Stats: true}.Write(buf, resp))
+	assert.Equal(t, []string{
+		"x  euler  exact",
+		"0  1      1",
+		"1  0.5    0.33",
+		"",
+		"method  points  min_x  min  max_x  max  final_x  final  evals  took  warnings",
+		"euler   2       1      0.5  0      1    1        0.5    1      10µs  ",
+		"exact   2                   0      1                    0      1µs   a; b",
+	}, strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n"))
+
+	buf.Reset()
+	require.NoError(t, report.Output{Format: report.FormatTable, Precision: 2}.Write(buf, resp))
+	assert.Equal(t, "x  euler  exact\n0  1      1\n1  0.5    0.33\n", buf.String(), "stats are not written by default")
+
+	buf.Reset()
+	require.NoError(t, report.Output{Format: report.FormatTable, Stats: true}.Write(buf, CompareResp{}))
+	assert.Equal(t, "method  max_gte  mean  rms  l2  evals  took\n", buf.String(), "reports without summary are written as is")
+
+	err := report.Output{Format: report.FormatCSV, Stats: true}.Write(&bytes.Buffer{}, resp)
+	assert.EqualError(t, err, "stats are not supported in csv format, as it has the single table")
+}
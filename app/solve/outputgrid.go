@@ -0,0 +1,122 @@
+package solve
+
+import (
+	"math"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/interp"
+	"github.com/pkg/errors"
+)
+
+// maxOutputPoints is the maximal number of x of the output grid, max_points limits it further, if it is set
+const maxOutputPoints = 10000
+
+// OutputGrid is the grid of x, at which lines are given in the response independently of internal steps
+// of methods, either the number of evenly spaced x or x themselves are set, solutions are interpolated at them
+// by the kind, as the value request does, the exact solution is evaluated at x directly
+type OutputGrid struct {
+	Points int       `json:"points,omitempty" yaml:"points,omitempty"` // evenly spaced x from x0 to x_end, both included
+	X      []float64 `json:"x,omitempty" yaml:"x,omitempty"`           // x in the direction from x0 to x_end
+	Interp string    `json:"interp,omitempty" yaml:"interp,omitempty"` // linear, cubic or hermite, cubic if empty
+}
+
+// kind returns the kind of the interpolation
+func (og OutputGrid) kind() string {
+	if og.Interp == "" {
+		return interp.KindCubic
+	}
+	return og.Interp
+}
+
+// validate checks the grid against the interval of the request and the limits, it returns x of the grid,
+// nil, if the grid is invalid
+func (og OutputGrid) validate(req Request, l Limits, invalid func(field, msg string, args ...interface{})) []float64 {
+	most := maxOutputPoints
+	if l.MaxPoints > 0 && l.MaxPoints < most {
+		most = l.MaxPoints
+	}
+	switch og.kind() {
+	case interp.KindLinear, interp.KindCubic, interp.KindHermite:
+	default:
+		invalid("output_grid", "interp must be %s, %s or %s, got %q",
+			interp.KindLinear, interp.KindCubic, interp.KindHermite, og.Interp)
+		return nil
+	}
+	switch {
+	case req.Warm || req.Sensitivity || req.Estimates:
+		invalid("output_grid", "must not be set together with warm, sensitivity or estimates, as they follow steps")
+		return nil
+	case !IsFinite(req.X0) || !IsFinite(req.XEnd):
+		return nil // the interval is reported with x0 and x_end
+	case req.X0 == req.XEnd:
+		invalid("output_grid", "requires x_end to differ from x0")
+		return nil
+	case og.Points != 0 && len(og.X) > 0:
+		invalid("output_grid", "points and x must not be set together")
+		return nil
+	case og.Points == 0 && len(og.X) == 0:
+		invalid("output_grid", "either points or x must be set")
+		return nil
+	case og.Points != 0 && (og.Points < 2 || og.Points > most):
+		invalid("output_grid", "points must be between 2 and %d, got %d", most, og.Points)
+		return nil
+	case len(og.X) > most:
+		invalid("output_grid", "x must have at most %d values, got %d", most, len(og.X))
+		return nil
+	}
+
+	if og.Points != 0 {
+		xs := make([]float64, og.Points)
+		h := (req.XEnd - req.X0) / float64(og.Points-1)
+		for i := range xs {
+			xs[i] = req.X0 + float64(i)*h
+		}
+		xs[len(xs)-1] = req.XEnd // exactly the end of the interval regardless of the round-off
+		return xs
+	}
+
+	// the interval of the backward solve is from x_end to x0
+	lo, hi := math.Min(req.X0, req.XEnd), math.Max(req.X0, req.XEnd)
+	dir := math.Copysign(1, req.XEnd-req.X0)
+	for i, x := range og.X {
+		switch {
+		case !(x >= lo && x <= hi):
+			invalid("output_grid", "x=%v is out of the interval [%v, %v] of the problem", x, lo, hi)
+			return nil
+		case i > 0 && !((x-og.X[i-1])*dir > 0):
+			invalid("output_grid", "x must go from x0 to x_end without repeats, %v follows %v", x, og.X[i-1])
+			return nil
+		}
+	}
+	return og.X
+}
+
+// output returns points of the solution by the method in the response, they are downsampled by max_points,
+// or interpolated at x of the output grid, if it is set
+func (p Prepared) output(method string, pts []num.Point) ([]num.Point, error) {
+	if p.outX == nil {
+		return Downsample(pts, p.maxPoints), nil
+	}
+	at, err := p.interpolate(Line{Method: method, Points: pts}, p.Req.OutputGrid.kind())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to interpolate %s at the output grid", method)
+	}
+	res := make([]num.Point, len(p.outX))
+	for i, x := range p.outX {
+		y, err := at(x)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to evaluate %s at x=%v of the output grid", method, x)
+		}
+		res[i] = num.Point{X: x, Y: y}
+	}
+	return res, nil
+}
+
+// shown returns the number of points of the line, that are shown of all points of the solution, lines
+// at the output grid are not downsampled, so all points count as shown
+func (p Prepared) shown(points, all int) int {
+	if p.outX != nil {
+		return all
+	}
+	return points
+}
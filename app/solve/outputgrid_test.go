@@ -0,0 +1,58 @@
+package solve
+
+import (
+	"testing"
+
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputGrid_Validate(t *testing.T) {
+	base := func(og OutputGrid) Request {
+		return Request{F: "x", X0: 0, Y0: 1, XEnd: 2, N: 10, Methods: []string{"rk4"}, OutputGrid: &og}
+	}
+	tbl := []struct {
+		name string
+		req  Request
+		msg  string
+	}{
+		{"no x", base(OutputGrid{}), "either points or x must be set"},
+		{"both", base(OutputGrid{Points: 3, X: []float64{1}}), "points and x must not be set together"},
+		{"single point", base(OutputGrid{Points: 1}), "points must be between 2 and 5, got 1"},
+		{"too many points", base(OutputGrid{Points: 6}), "points must be between 2 and 5, got 6"},
+		{"too many x", base(OutputGrid{X: []float64{0, 0.1, 0.2, 0.3, 0.4, 0.5}}), "x must have at most 5 values, got 6"},
+		{"out of interval", base(OutputGrid{X: []float64{1, 3}}), "x=3 is out of the interval [0, 2] of the problem"},
+		{"repeated", base(OutputGrid{X: []float64{1, 1}}), "x must go from x0 to x_end without repeats, 1 follows 1"},
+		{"unknown interp", base(OutputGrid{Points: 3, Interp: "quintic"}),
+			`interp must be linear, cubic or hermite, got "quintic"`},
+		{"estimates", func() Request { r := base(OutputGrid{Points: 3}); r.Estimates = true; return r }(),
+			"must not be set together with warm, sensitivity or estimates, as they follow steps"},
+		{"empty interval", func() Request {
+			r := base(OutputGrid{Points: 3})
+			r.XEnd, r.N, r.Step = 0, 0, 0.1
+			return r
+		}(), "requires x_end to differ from x0"},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.req.Prepare(Limits{MaxSteps: 100, MaxPoints: 5})
+			require.Error(t, err)
+			ve := rest.ValidationError{}
+			require.True(t, errors.As(err, &ve))
+			assert.Contains(t, ve, rest.FieldError{Field: "output_grid", Msg: tt.msg})
+		})
+	}
+
+	p, err := base(OutputGrid{Points: 3}).Prepare(Limits{MaxSteps: 100})
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0, 1, 2}, p.outX)
+
+	// the grid changes the response, so it is the part of the cache key
+	l := Limits{MaxSteps: 100}
+	assert.NotEqual(t, base(OutputGrid{Points: 3}).CacheKey(l), base(OutputGrid{Points: 4}).CacheKey(l))
+	noGrid := base(OutputGrid{})
+	noGrid.OutputGrid = nil
+	assert.NotEqual(t, noGrid.CacheKey(l), base(OutputGrid{Points: 3}).CacheKey(l))
+}
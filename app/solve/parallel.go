@@ -0,0 +1,53 @@
+package solve
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// ForEach calls fn with each index from 0 to n-1 by at most workers goroutines at once, GOMAXPROCS of them
+// if workers is not positive, the single worker makes calls one after another, the first error cancels
+// the context of the rest of calls and is returned, fn must only touch the state of its index
+func ForEach(ctx context.Context, n, workers int, fn func(ctx context.Context, i int) error) error {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers == 1 {
+		for i := 0; i < n; i++ {
+			if err := fn(ctx, i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// workers take indices in order, so calls are started in the order of indices, even if the goroutine
+	// of the worker is scheduled late
+	if workers > n {
+		workers = n
+	}
+	g, gctx := errgroup.WithContext(ctx)
+	var next int64 = -1
+	for w := 0; w < workers; w++ {
+		g.Go(func() error {
+			for gctx.Err() == nil {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= n {
+					return nil
+				}
+				if err := fn(gctx, i); err != nil {
+					return err
+				}
+			}
+			// calls, that are not started, are left to the error of the group or of the parent context
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
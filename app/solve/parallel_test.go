@@ -0,0 +1,76 @@
+package solve
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForEach(t *testing.T) {
+	for _, workers := range []int{0, 1, 2, 8} {
+		res := make([]int, 20)
+		var running, maxRunning int32
+		err := ForEach(context.Background(), len(res), workers, func(_ context.Context, i int) error {
+			cur := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				prev := atomic.LoadInt32(&maxRunning)
+				if cur <= prev || atomic.CompareAndSwapInt32(&maxRunning, prev, cur) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			res[i] = i * i
+			return nil
+		})
+		require.NoError(t, err)
+		for i, v := range res {
+			assert.Equal(t, i*i, v, "workers=%d", workers)
+		}
+		if workers > 0 {
+			assert.LessOrEqual(t, int(maxRunning), workers, "no more than workers calls at once")
+		}
+		if workers == 1 {
+			assert.Equal(t, int32(1), maxRunning, "calls are made one after another")
+		}
+	}
+}
+
+func TestForEach_Errors(t *testing.T) {
+	for _, workers := range []int{1, 4} {
+		var calls int32
+		err := ForEach(context.Background(), 100, workers, func(ctx context.Context, i int) error {
+			atomic.AddInt32(&calls, 1)
+			if i == 2 {
+				return errors.New("failed")
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(10 * time.Millisecond):
+				return nil
+			}
+		})
+		assert.EqualError(t, err, "failed", "workers=%d", workers)
+		if workers == 1 {
+			assert.Equal(t, int32(3), calls, "calls after the failure are not made")
+			continue
+		}
+		assert.Less(t, int(calls), 100, "calls after the failure are not started")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var calls int32
+	err := ForEach(ctx, 10, 2, func(context.Context, int) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	})
+	assert.Equal(t, context.Canceled, err, "the done context is reported")
+	assert.Zero(t, calls)
+}
@@ -0,0 +1,120 @@
+package solve
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/pkg/errors"
+)
+
+// maxPipeLine is the maximal length of the line with the problem in the input of Pipe
+const maxPipeLine = 1 << 20
+
+// PipeOpts configures Pipe
+type PipeOpts struct {
+	Workers     int  // number of problems, solved concurrently, one if not positive
+	ErrorsFatal bool // stop at the first problem, that fails, after writing its error
+	Limits      Limits
+}
+
+// pipeItem is the result of the problem from the line of the input
+type pipeItem struct {
+	Line int `json:"line"`
+	Item
+}
+
+// Pipe reads problems from rd as newline-delimited json, as in the solve request, and writes the result or
+// the error of each problem as the line of json to wr, in the order of input, blank lines are skipped.
+// Problems are solved concurrently by workers, but at most workers problems are read ahead of the written
+// result, so the output is never buffered, slow writer stops reading. Pipe fails if the input or
+// the output fails, or, with ErrorsFatal, with the error of the first failed problem
+func Pipe(ctx context.Context, rd io.Reader, wr io.Writer, opts PipeOpts) error {
+	workers := opts.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	l := opts.Limits.Effective()
+
+	// each slot is taken by the problem from reading until its result is written
+	slots := make(chan struct{}, workers)
+	pending := make(chan chan pipeItem, workers) // results in the order of input
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(pending)
+		sc := bufio.NewScanner(rd)
+		sc.Buffer(make([]byte, 0, 64*1024), maxPipeLine)
+		for line := 1; sc.Scan(); line++ {
+			if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+				continue
+			}
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				readErr <- ctx.Err()
+				return
+			}
+			res := make(chan pipeItem, 1)
+			pending <- res // never blocks, as there are not more pending results than slots
+			data := append([]byte(nil), sc.Bytes()...)
+			go func(line int) { res <- pipeItem{Line: line, Item: solvePiped(ctx, data, l)} }(line)
+		}
+		readErr <- errors.Wrap(sc.Err(), "failed to read problems")
+	}()
+
+	enc := json.NewEncoder(wr)
+	for res := range pending {
+		item := <-res
+		if err := enc.Encode(item); err != nil {
+			return errors.Wrap(err, "failed to write result")
+		}
+		<-slots
+		if opts.ErrorsFatal && item.Error != nil {
+			// the reader might be blocked by the input, so it is left to stop by the context
+			return errors.Errorf("problem at line %d failed: %s", item.Line, item.Error.Error)
+		}
+	}
+	return <-readErr
+}
+
+// solvePiped decodes and solves the problem from the line under the limits, unknown fields of the problem
+// are rejected, as in problem files, the problem fails as in the batch of the server without the store
+func solvePiped(ctx context.Context, data []byte, l Limits) Item {
+	st := time.Now()
+	fail := func(err error, details string, code rest.ErrCode) Item {
+		be := rest.NewErrorResponse(err, details, code)
+		return Item{Error: &be, Took: time.Since(st).String()}
+	}
+
+	req := Request{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		return fail(err, "failed to decode problem", rest.ErrDecode)
+	}
+	p, err := req.Prepare(l)
+	if err != nil {
+		return fail(err, "invalid solve request", rest.ErrBadRequest)
+	}
+	if req.Save {
+		return fail(errors.New("results can't be saved"), "saving of results is disabled", rest.ErrBadRequest)
+	}
+	if req.Warm {
+		return fail(errors.New("warm start is not supported in batches"), "invalid solve request", rest.ErrBadRequest)
+	}
+
+	resp, err := p.Solve(ctx)
+	if err != nil {
+		return fail(err, "failed to solve", rest.ErrInternal)
+	}
+	if req.Errors {
+		resp.Errors = resp.LocalErrors()
+	}
+	return Item{Result: &resp, Took: time.Since(st).String()}
+}
@@ -0,0 +1,105 @@
+package solve
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/pkg/errors"
+)
+
+// Presets are the named problems, ready to solve or compare
+var Presets = map[string]Problem{
+	// the variant of the computational practicum, the ui is made for
+	"canonical": {Description: "the variant of the computational practicum",
+		F: "y*y*exp(x) - 2*y", Exact: "exp(-x) / (c*exp(x) + 1)", C: "(exp(-x0) - y0) / (y0 * exp(x0))",
+		X0: -4, Y0: 1, XEnd: 4, N: 30},
+	// the stiff problem, the solution is quickly attracted to the slow one near cos(x), explicit methods
+	// are stable only with h < 2.785/k for rk4 and h < 2/k for euler and ieuler
+	"stiff-decay": {Description: "the stiff problem, attracted to the slow solution near cos(x), explicit methods " +
+		"are stable only with small steps",
+		F: "-k*(y - cos(x))", Exact: "(k^2*cos(x) + k*sin(x)) / (k^2 + 1) + c*exp(-k*x)",
+		C: "(y0 - (k^2*cos(x0) + k*sin(x0)) / (k^2 + 1)) * exp(k*x0)", Params: map[string]float64{"k": 50},
+		X0: 0, Y0: 0, XEnd: 10, N: 300},
+	"logistic": {Description: "the logistic growth with the rate r up to the capacity k",
+		F: "r*y*(1 - y/k)", Exact: "k / (1 + c*exp(-r*x))", C: "(k/y0 - 1) * exp(r*x0)",
+		Params: map[string]float64{"r": 1, "k": 10}, X0: 0, Y0: 1, XEnd: 10, N: 100},
+	// the test equation of Dahlquist, explicit methods are stable only with h*|lambda| below the bound of the method,
+	// it is 2 for euler
+	"dahlquist": {Description: "the stiff test equation of Dahlquist, explicit methods are stable only with h*|lambda| " +
+		"below the bound of the method",
+		F: "lambda*y", Exact: "c*exp(lambda*x)", C: "y0*exp(-lambda*x0)", Params: map[string]float64{"lambda": -100},
+		X0: 0, Y0: 1, XEnd: 1, N: 100},
+}
+
+// PresetProblems returns the presets by names with names set, all of them sorted by names, if none are set
+func PresetProblems(names ...string) ([]Problem, error) {
+	all := PresetNames(Presets)
+	if len(names) == 0 {
+		names = all
+	}
+	res := make([]Problem, 0, len(names))
+	for _, name := range names {
+		prob, ok := Presets[name]
+		if !ok {
+			return nil, errors.Errorf("unknown preset %q, available: %s", name, strings.Join(all, ", "))
+		}
+		prob.Name = name
+		res = append(res, prob)
+	}
+	return res, nil
+}
+
+// PresetNames returns sorted names of presets
+func PresetNames(presets map[string]Problem) []string {
+	res := make([]string, 0, len(presets))
+	for name := range presets {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// WithPreset returns the request with the problem of its preset, f, exact, c, x0, y0 and x_end are taken from
+// the preset, params of the request override params of the preset, n and step of the preset are used, unless
+// the request sets steps, the request without the preset is returned as is
+func (req Request) WithPreset() (Request, error) {
+	if req.Preset == "" {
+		return req, nil
+	}
+	prob, ok := Presets[req.Preset]
+	if !ok {
+		return Request{}, rest.ValidationError{{Field: "preset", Msg: fmt.Sprintf("unknown preset %q, available: %s",
+			req.Preset, strings.Join(PresetNames(Presets), ", "))}}
+	}
+	if req.F != "" || req.Exact != "" || req.C != "" || req.Linear != nil || req.CBracket != nil {
+		return Request{}, rest.ValidationError{{Field: "preset",
+			Msg: "must not be set together with f, exact, c, linear or c_bracket, as the preset sets the problem"}}
+	}
+
+	res := prob.Request()
+	res.Params = MergeParams(prob.Params, req.Params)
+	res.Methods, res.Save, res.Sensitivity, res.DFDY = req.Methods, req.Save, req.Sensitivity, req.DFDY
+	res.Warm, res.AutoRefine, res.Errors, res.Estimates = req.Warm, req.AutoRefine, req.Errors, req.Estimates
+	res.OutputGrid = req.OutputGrid
+	if req.N != 0 || req.Step != 0 || len(req.NByMethod) > 0 {
+		res.N, res.Step, res.NByMethod = req.N, req.Step, req.NByMethod
+	}
+	return res, nil
+}
+
+// MergeParams returns params of the preset, overridden by params of the request
+func MergeParams(preset, req map[string]float64) map[string]float64 {
+	if len(preset) == 0 && len(req) == 0 {
+		return nil
+	}
+	res := make(map[string]float64, len(preset)+len(req))
+	for name, v := range preset {
+		res[name] = v
+	}
+	for name, v := range req {
+		res[name] = v
+	}
+	return res
+}
@@ -0,0 +1,64 @@
+package solve
+
+import (
+	"strconv"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/rest"
+)
+
+// refinementResp is the report of refinements of grids of methods with the tolerance, the exact solution
+// is not refined, so it is not reported
+type refinementResp struct {
+	Tol   float64          `json:"tol"`
+	Lines []refinementLine `json:"lines"`
+}
+
+// refinementLine describes the refinement of the grid of the method
+type refinementLine struct {
+	Method string              `json:"method"`
+	Name   string              `json:"name"`
+	Refine *RefineResp         `json:"refine,omitempty"`
+	Final  *num.Point          `json:"final,omitempty"` // the last point of the finest solution
+	Error  *rest.ErrorResponse `json:"error,omitempty"`
+	Took   string              `json:"took"`
+}
+
+// newRefinementResp makes the report of refined lines of the solution
+func newRefinementResp(tol float64, resp Response) refinementResp {
+	res := refinementResp{Tol: tol, Lines: []refinementLine{}}
+	for _, line := range resp.Lines {
+		if line.Method == ExactMethod {
+			continue
+		}
+		rl := refinementLine{Method: line.Method, Name: line.Name, Refine: line.Refine, Error: line.Error, Took: line.Took}
+		if line.Stats != nil {
+			rl.Final = line.Stats.Final
+		}
+		res.Lines = append(res.Lines, rl)
+	}
+	return res
+}
+
+// Table returns rows of levels of each method with the number of steps and the max difference from the previous
+// level in the scientific notation with prec significant digits, four by default, the first level has no difference,
+// the failed method has the single row with its error
+func (resp refinementResp) Table(prec int) (header []string, rows [][]string) {
+	if prec == 0 {
+		prec = 4
+	}
+	header = []string{"method", "n", "max_diff"}
+	for _, line := range resp.Lines {
+		if line.Error != nil || line.Refine == nil {
+			rows = append(rows, []string{line.Method, "-", "failed"})
+			continue
+		}
+		n := line.Refine.N >> line.Refine.Doublings
+		rows = append(rows, []string{line.Method, strconv.Itoa(n), "-"})
+		for _, diff := range line.Refine.Diffs {
+			n *= 2
+			rows = append(rows, []string{line.Method, strconv.Itoa(n), strconv.FormatFloat(diff, 'e', prec-1, 64)})
+		}
+	}
+	return header, rows
+}
@@ -0,0 +1,42 @@
+package solve
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefine(t *testing.T) {
+	prob := Presets["canonical"]
+	prob.Methods, prob.RefineTol, prob.RefineDoublings = []string{"rk4", "euler"}, 1e-6, 5
+	ref, err := Refine(context.Background(), prob, Limits{MaxSteps: 10000})
+	require.NoError(t, err)
+	err = ref.Err()
+	assert.True(t, errors.Is(err, solver.ErrNotConverged), err)
+	assert.Contains(t, err.Error(), "euler has max difference")
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, ref.Write(buf, report.Output{Format: report.FormatTable}))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 1+4+6, "the header, rk4 levels and euler levels")
+	assert.Regexp(t, `^method +n +max_diff$`, lines[0])
+	assert.Regexp(t, `^rk4 +30 +-$`, lines[1])
+	assert.Regexp(t, `^rk4 +240 +9\.\d{3}e-07$`, lines[4])
+	assert.Regexp(t, `^euler +960 +\S+e-03$`, lines[10])
+
+	prob.Methods = []string{"rk4"}
+	ref, err = Refine(context.Background(), prob, Limits{MaxSteps: 10000})
+	require.NoError(t, err)
+	assert.NoError(t, ref.Err())
+
+	prob.RefineTol = 0
+	_, err = Refine(context.Background(), prob, Limits{MaxSteps: 10000})
+	assert.EqualError(t, err, "tolerance of the refinement is not set")
+}
@@ -0,0 +1,278 @@
+package solve
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/pkg/errors"
+)
+
+// RecordedRun is the solve request with every point, drawn by each method, with full precision, warnings
+// and errors of methods, recorded to replay the run, e.g. in tests
+type RecordedRun struct {
+	Request    Request        `json:"request"`
+	Step       float64        `json:"step"`
+	Lines      []RecordedLine `json:"lines"`           // lines of methods in the order of the request, the exact one is the last
+	Error      string         `json:"error,omitempty"` // the failure of the whole request, e.g. the timeout
+	RecordedAt time.Time      `json:"recorded_at"`
+}
+
+// RecordedLine is the run of the single method, points are the ones passed to the drawer before downsampling,
+// including the failed one, not finite coordinates are kept as "NaN", "+Inf" and "-Inf"
+type RecordedLine struct {
+	Method   string      `json:"method"`
+	Points   []num.Point `json:"-"`
+	Warnings []string    `json:"warnings,omitempty"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// recordedLineJSON is the json form of the line, points are pairs of coordinates
+type recordedLineJSON struct {
+	Method   string             `json:"method"`
+	Points   [][2]recordedFloat `json:"points"`
+	Warnings []string           `json:"warnings,omitempty"`
+	Error    string             `json:"error,omitempty"`
+}
+
+// MarshalJSON encodes points as pairs of coordinates, not finite ones are encoded as strings
+func (l RecordedLine) MarshalJSON() ([]byte, error) {
+	res := recordedLineJSON{Method: l.Method, Points: make([][2]recordedFloat, len(l.Points)), Warnings: l.Warnings,
+		Error: l.Error}
+	for i, p := range l.Points {
+		res.Points[i] = [2]recordedFloat{recordedFloat(p.X), recordedFloat(p.Y)}
+	}
+	return json.Marshal(res)
+}
+
+// UnmarshalJSON decodes the line, encoded by MarshalJSON
+func (l *RecordedLine) UnmarshalJSON(data []byte) error {
+	res := recordedLineJSON{}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	*l = RecordedLine{Method: res.Method, Warnings: res.Warnings, Error: res.Error, Points: make([]num.Point, len(res.Points))}
+	for i, p := range res.Points {
+		l.Points[i] = num.Point{X: float64(p[0]), Y: float64(p[1])}
+	}
+	return nil
+}
+
+// recordedFloat is the number of the recorded run, it is encoded in the shortest form, that is decoded into
+// the same number, not finite numbers are encoded as strings, as json has no literals for them
+type recordedFloat float64
+
+// MarshalJSON encodes the number
+func (f recordedFloat) MarshalJSON() ([]byte, error) {
+	v := float64(f)
+	switch {
+	case math.IsNaN(v):
+		return []byte(`"NaN"`), nil
+	case math.IsInf(v, 1):
+		return []byte(`"+Inf"`), nil
+	case math.IsInf(v, -1):
+		return []byte(`"-Inf"`), nil
+	}
+	return strconv.AppendFloat(nil, v, 'g', -1, 64), nil
+}
+
+// UnmarshalJSON decodes the number or the string of the not finite one
+func (f *recordedFloat) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		if s != "NaN" && s != "+Inf" && s != "-Inf" {
+			return errors.Errorf("unexpected number %q", s)
+		}
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return errors.Wrapf(err, "unexpected number %s", data)
+	}
+	*f = recordedFloat(v)
+	return nil
+}
+
+// Replay loads the run, recorded to the file
+func Replay(path string) (*RecordedRun, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read recorded run")
+	}
+	run := &RecordedRun{}
+	if err = json.Unmarshal(data, run); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode %s", path)
+	}
+	return run, nil
+}
+
+// Rerun solves the request of the run under the limits once again and records the new run,
+// the invalid request is returned as the error, as well as the failure of the context
+func (run *RecordedRun) Rerun(ctx context.Context, l Limits) (*RecordedRun, error) {
+	p, err := run.Request.Prepare(l.Effective())
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid recorded request")
+	}
+	p.Recorder = NewRecorder()
+	resp, err := p.Solve(ctx)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return p.Recorder.Run(p, resp, err), nil
+}
+
+// Divergence is the first difference between two runs, Index is the index of the point, -1 if runs differ
+// not in points, e.g. in errors of the method
+type Divergence struct {
+	Method    string
+	Index     int
+	Want, Got num.Point // points of the recorded run and of the other one, if the runs differ in them
+	Msg       string
+}
+
+// String describes the divergence
+func (d Divergence) String() string {
+	if d.Index < 0 {
+		return fmt.Sprintf("%s: %s", d.Method, d.Msg)
+	}
+	return fmt.Sprintf("%s: point #%d: %s", d.Method, d.Index, d.Msg)
+}
+
+// Diff compares runs point by point and returns the first divergence, coordinates of points might differ
+// by tol at most, not finite coordinates must be the same, nil is returned, if runs are the same
+func (run *RecordedRun) Diff(other *RecordedRun, tol float64) *Divergence {
+	if run.Error != other.Error {
+		return &Divergence{Index: -1, Msg: fmt.Sprintf("error of the request %q differs from %q", other.Error, run.Error)}
+	}
+	for i, want := range run.Lines {
+		if i >= len(other.Lines) || other.Lines[i].Method != want.Method {
+			return &Divergence{Method: want.Method, Index: -1, Msg: "line is missing"}
+		}
+		got := other.Lines[i]
+		for j := 0; j < len(want.Points) && j < len(got.Points); j++ {
+			if !sameFloat(want.Points[j].X, got.Points[j].X, tol) || !sameFloat(want.Points[j].Y, got.Points[j].Y, tol) {
+				return &Divergence{Method: want.Method, Index: j, Want: want.Points[j], Got: got.Points[j],
+					Msg: fmt.Sprintf("%v differs from %v by more than %g", got.Points[j], want.Points[j], tol)}
+			}
+		}
+		if len(want.Points) != len(got.Points) {
+			j := len(want.Points)
+			if len(got.Points) < j {
+				j = len(got.Points)
+			}
+			return &Divergence{Method: want.Method, Index: j,
+				Msg: fmt.Sprintf("%d points differ from %d recorded ones", len(got.Points), len(want.Points))}
+		}
+		if want.Error != got.Error {
+			return &Divergence{Method: want.Method, Index: -1, Msg: fmt.Sprintf("error %q differs from %q", got.Error, want.Error)}
+		}
+	}
+	if len(other.Lines) > len(run.Lines) {
+		return &Divergence{Method: other.Lines[len(run.Lines)].Method, Index: -1, Msg: "line is not recorded"}
+	}
+	return nil
+}
+
+// Verify solves the request of the run once again and compares the new run with the recorded one,
+// the first divergence is returned, nil if runs are the same up to tol
+func (run *RecordedRun) Verify(ctx context.Context, l Limits, tol float64) (*Divergence, error) {
+	other, err := run.Rerun(ctx, l)
+	if err != nil {
+		return nil, err
+	}
+	return run.Diff(other, tol), nil
+}
+
+// sameFloat checks whether numbers differ by tol at most, not finite numbers must be the same
+func sameFloat(a, b, tol float64) bool {
+	if !IsFinite(a) || !IsFinite(b) {
+		return a == b || math.IsNaN(a) && math.IsNaN(b)
+	}
+	return math.Abs(a-b) <= tol
+}
+
+// Recorder records points of each method of the problem, methods are solved concurrently
+type Recorder struct {
+	lock  sync.Mutex
+	recs  map[string]*solver.Recording
+	lines map[string]RecordedLine
+}
+
+// NewRecorder makes the recorder without recorded lines
+func NewRecorder() *Recorder {
+	return &Recorder{recs: map[string]*solver.Recording{}, lines: map[string]RecordedLine{}}
+}
+
+// wrap wraps the drawer of the method to record its points, the drawer is returned as is, if nothing is recorded
+func (rr *Recorder) wrap(method string, d solver.Drawer) solver.Drawer {
+	if rr == nil {
+		return d
+	}
+	rec, d := solver.WithRecorder(d)
+	rr.lock.Lock()
+	defer rr.lock.Unlock()
+	rr.recs[method] = rec
+	return d
+}
+
+// done records the result of the method, the line is its response, if it succeeded
+func (rr *Recorder) done(method string, line Line, err error) {
+	if rr == nil {
+		return
+	}
+	rr.lock.Lock()
+	defer rr.lock.Unlock()
+	res := RecordedLine{Method: method}
+	if rec, ok := rr.recs[method]; ok {
+		res.Points = rec.Points
+	}
+	switch {
+	case err != nil:
+		res.Error = err.Error()
+	case line.Stats != nil:
+		res.Warnings = line.Stats.Warnings
+	}
+	rr.lines[method] = res
+}
+
+// Run makes the recorded run of the problem, the error is the failure of the whole request
+func (rr *Recorder) Run(p Prepared, resp Response, err error) *RecordedRun {
+	rr.lock.Lock()
+	defer rr.lock.Unlock()
+	res := &RecordedRun{Request: p.Req, Step: p.Step, RecordedAt: time.Now().UTC()}
+	if err != nil {
+		res.Error = err.Error()
+	}
+	_ = p.Each(func(method string, _ solver.Interface) error {
+		line, ok := rr.lines[method]
+		if !ok {
+			line = RecordedLine{Method: method, Error: "method is not solved"}
+		}
+		res.Lines = append(res.Lines, line)
+		return nil
+	})
+	return res
+}
+
+// Failed checks whether the request or any of its methods failed
+func (run *RecordedRun) Failed() bool {
+	if run.Error != "" {
+		return true
+	}
+	for _, line := range run.Lines {
+		if line.Error != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package solve
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordedRun_Diff(t *testing.T) {
+	pts := []num.Point{{X: 0, Y: 1}, {X: 1, Y: math.NaN()}, {X: 2, Y: math.Inf(1)}}
+	run := &RecordedRun{Lines: []RecordedLine{{Method: "euler", Points: pts}}}
+	same := &RecordedRun{Lines: []RecordedLine{{Method: "euler", Points: append([]num.Point{}, pts...)}}}
+	assert.Nil(t, run.Diff(same, 0), "not finite values are the same")
+
+	tbl := []struct {
+		other *RecordedRun
+		div   Divergence
+	}{
+		{&RecordedRun{Error: "timeout"}, Divergence{Index: -1, Msg: `error of the request "timeout" differs from ""`}},
+		{&RecordedRun{}, Divergence{Method: "euler", Index: -1, Msg: "line is missing"}},
+		{&RecordedRun{Lines: []RecordedLine{{Method: "euler", Points: pts[:2]}}},
+			Divergence{Method: "euler", Index: 2, Msg: "2 points differ from 3 recorded ones"}},
+		{&RecordedRun{Lines: []RecordedLine{{Method: "euler", Points: []num.Point{pts[0], {X: 1, Y: 0}, pts[2]}}}},
+			Divergence{Method: "euler", Index: 1, Want: pts[1], Got: num.Point{X: 1, Y: 0},
+				Msg: "(1.0000, 0.0000) differs from (1.0000, NaN) by more than 0"}},
+		{&RecordedRun{Lines: []RecordedLine{{Method: "euler", Points: pts, Error: "failed"}}},
+			Divergence{Method: "euler", Index: -1, Msg: `error "failed" differs from ""`}},
+		{&RecordedRun{Lines: []RecordedLine{{Method: "euler", Points: pts}, {Method: "rk4"}}},
+			Divergence{Method: "rk4", Index: -1, Msg: "line is not recorded"}},
+	}
+	for _, tt := range tbl {
+		div := run.Diff(tt.other, 0)
+		require.NotNil(t, div, tt.div.Msg)
+		if math.IsNaN(tt.div.Want.Y) {
+			assert.True(t, math.IsNaN(div.Want.Y))
+			div.Want, tt.div.Want = num.Point{}, num.Point{}
+		}
+		assert.Equal(t, tt.div, *div)
+	}
+}
+
+func TestRecordedLine_JSON(t *testing.T) {
+	line := RecordedLine{Method: "rk4", Points: []num.Point{{X: 0.1, Y: 1.0 / 3}, {X: 1e-300, Y: math.Inf(-1)},
+		{X: 2, Y: math.NaN()}, {X: 3, Y: math.Inf(1)}}, Warnings: []string{"warning"}}
+	data, err := json.Marshal(line)
+	require.NoError(t, err)
+	assert.Equal(t, `{"method":"rk4","points":[[0.1,0.3333333333333333],[1e-300,"-Inf"],[2,"NaN"],[3,"+Inf"]],`+
+		`"warnings":["warning"]}`, string(data))
+
+	res := RecordedLine{}
+	require.NoError(t, json.Unmarshal(data, &res))
+	assert.Equal(t, line.Points[:2], res.Points[:2])
+	assert.True(t, math.IsNaN(res.Points[2].Y))
+	assert.True(t, math.IsInf(res.Points[3].Y, 1))
+	assert.Equal(t, line.Warnings, res.Warnings)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"points":[[1,"Infinity"]]}`), &res))
+	assert.Error(t, json.Unmarshal([]byte(`{"points":[[1,true]]}`), &res))
+}
@@ -0,0 +1,101 @@
+package solve
+
+import (
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Semior001/decompract/app/num"
+)
+
+// ErrorTable lists the absolute differences between the method and the exact solution
+type ErrorTable struct {
+	Method string      `json:"method"`
+	Name   string      `json:"name"`
+	Points []num.Point `json:"points"`
+}
+
+// Query returns the query of GET requests with the parameters of the request,
+// spaces in formulas are encoded as %20, as the plus sign is not treated as a space
+func (req Request) Query() string {
+	q := url.Values{"f": {req.F}, "x0": {FormatFloat(req.X0)}, "y0": {FormatFloat(req.Y0)},
+		"x1": {FormatFloat(req.XEnd)}, "method": req.Methods}
+	if req.Exact != "" {
+		q.Set("exact", req.Exact)
+	}
+	if req.C != "" {
+		q.Set("c", req.C)
+	}
+	if cb := req.CBracket; cb != nil {
+		q.Set("c_bracket", FormatFloat(cb.Lo)+":"+FormatFloat(cb.Hi)+":"+FormatFloat(cb.Tol))
+	}
+	if req.Linear != nil {
+		q.Set("p", req.Linear.P)
+		q.Set("q", req.Linear.Q)
+	}
+	if req.N != 0 {
+		q.Set("n", strconv.Itoa(req.N))
+	}
+	if req.Step != 0 {
+		q.Set("step", FormatFloat(req.Step))
+	}
+	for _, name := range req.nByMethodNames() {
+		q.Add("n_by_method", name+":"+strconv.Itoa(req.NByMethod[name]))
+	}
+	for _, name := range ParamNames(req.Params) {
+		q.Add("param", name+":"+FormatFloat(req.Params[name]))
+	}
+	return strings.ReplaceAll(q.Encode(), "+", "%20")
+}
+
+// LocalErrors calculates the absolute differences between each method and the exact solution,
+// the exact solution is either listed in lines or set separately, if grids of methods differ, the method
+// is compared at its nodes, that are nodes of the exact solution too, with the least step
+func (resp Response) LocalErrors() []ErrorTable {
+	exact := resp.Exact
+	for i := range resp.Lines {
+		if resp.Lines[i].Method == ExactMethod && resp.Lines[i].Error == nil {
+			exact = &resp.Lines[i]
+		}
+	}
+	if exact == nil || exact.Error != nil {
+		return []ErrorTable{}
+	}
+
+	res := []ErrorTable{}
+	for _, line := range resp.Lines {
+		if line.Error != nil || line.Method == ExactMethod {
+			continue
+		}
+		et := ErrorTable{Method: line.Method, Name: line.Name, Points: []num.Point{}}
+		if resp.GridsDiffer {
+			for _, pt := range line.Points {
+				if y, ok := nodeY(exact.Points, pt.X, resp.Step); ok {
+					et.Points = append(et.Points, num.Point{X: pt.X, Y: math.Abs(pt.Y - y)})
+				}
+			}
+			res = append(res, et)
+			continue
+		}
+		for i, pt := range line.Points {
+			if i >= len(exact.Points) {
+				break
+			}
+			et.Points = append(et.Points, num.Point{X: pt.X, Y: math.Abs(pt.Y - exact.Points[i].Y)})
+		}
+		res = append(res, et)
+	}
+	return res
+}
+
+// nodeY returns y of the node at x of points, sorted by x with the step, nodes, that are closer to x
+// than the thousandth of the step, are the same
+func nodeY(pts []num.Point, x, step float64) (float64, bool) {
+	i := sort.Search(len(pts), func(i int) bool { return pts[i].X >= x-step/1000 })
+	if i == len(pts) || math.Abs(pts[i].X-x) > step/1000 {
+		return 0, false
+	}
+	return pts[i].Y, true
+}
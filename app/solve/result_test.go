@@ -0,0 +1,26 @@
+package solve
+
+import (
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSolveResp_LocalErrorsGridsDiffer(t *testing.T) {
+	resp := Response{Step: 0.25, GridsDiffer: true,
+		Exact: &Line{Method: ExactMethod, Points: []num.Point{{X: 0, Y: 1}, {X: 0.25, Y: 2}, {X: 0.5, Y: 3},
+			{X: 0.75, Y: 4}, {X: 1, Y: 5}}},
+		Lines: []Line{
+			{Method: "euler", Step: 0.25, Points: []num.Point{{X: 0, Y: 1}, {X: 0.25, Y: 2.5}, {X: 0.5, Y: 3},
+				{X: 0.75, Y: 3}, {X: 1, Y: 5}}},
+			{Method: "rk4", Step: 0.5, Points: []num.Point{{X: 0, Y: 1}, {X: 0.5, Y: 3.5}, {X: 1 + 1e-15, Y: 4}}},
+		}}
+	res := resp.LocalErrors()
+	require.Len(t, res, 2)
+	assert.Equal(t, []num.Point{{X: 0, Y: 0}, {X: 0.25, Y: 0.5}, {X: 0.5, Y: 0}, {X: 0.75, Y: 1}, {X: 1, Y: 0}},
+		res[0].Points)
+	assert.Equal(t, []num.Point{{X: 0, Y: 0}, {X: 0.5, Y: 0.5}, {X: 1 + 1e-15, Y: 1}}, res[1].Points,
+		"nodes are matched by x, not by index")
+}
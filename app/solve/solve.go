@@ -0,0 +1,1030 @@
+// Package solve validates initial value problems, solves them with methods of the solver registry and
+// builds reports of solutions, comparisons, error sweeps and benchmarks, shared by the REST API and cli commands.
+package solve
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/expr"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/report"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+	"gopkg.in/yaml.v3"
+)
+
+// Builder returns the builder of the solver of the validated method from the registry of solvers,
+// names of methods in requests are names of registered methods
+func Builder(method string) solver.Builder {
+	b, _ := solver.Lookup(method)
+	return b
+}
+
+// Request describes the initial value problem to solve
+type Request struct {
+	F         string             `json:"f" yaml:"f"`                               // f(x,y) = y'
+	Exact     string             `json:"exact,omitempty" yaml:"exact,omitempty"`   // y(x,c), the exact solution
+	C         string             `json:"c,omitempty" yaml:"c,omitempty"`           // C(x0,y0), the constant for the exact solution
+	Params    map[string]float64 `json:"params,omitempty" yaml:"params,omitempty"` // named constants of formulas
+	X0        float64            `json:"x0" yaml:"x0"`
+	Y0        float64            `json:"y0" yaml:"y0"`
+	XEnd      float64            `json:"x_end" yaml:"x_end"`
+	N         int                `json:"n,omitempty" yaml:"n,omitempty"`                     // number of steps
+	Step      float64            `json:"step,omitempty" yaml:"step,omitempty"`               // step size, used if n is not set
+	NByMethod map[string]int     `json:"n_by_method,omitempty" yaml:"n_by_method,omitempty"` // steps of methods instead of n
+	Methods   []string           `json:"methods" yaml:"methods"`
+	Save      bool               `json:"save,omitempty" yaml:"save,omitempty"` // save the result to share it by id
+	// Sensitivity adds dy/dy0 to lines of methods, by finite differences or by the variational equation with DFDY
+	Sensitivity bool   `json:"sensitivity,omitempty" yaml:"sensitivity,omitempty"`
+	DFDY        string `json:"dfdy,omitempty" yaml:"dfdy,omitempty"` // df/dy(x,y), the partial derivative of f by y
+	// Estimates adds estimates of local errors of steps of methods by the step doubling, without the exact solution
+	Estimates bool `json:"estimates,omitempty" yaml:"estimates,omitempty"`
+	// Warm resumes the previous warm solve of the session, if only x_end moves further from x0 in the same direction,
+	// lines have only new points then
+	Warm bool `json:"warm,omitempty" yaml:"warm,omitempty"`
+	// AutoRefine doubles n of each method, until successive solutions converge, lines are the finest solutions
+	AutoRefine *autoRefine `json:"auto_refine,omitempty" yaml:"auto_refine,omitempty"`
+	// Errors adds local errors of methods against the exact solution to the response
+	Errors bool `json:"errors,omitempty" yaml:"errors,omitempty"`
+	// Linear derives the exact solution of y' + p(x)y = q(x) instead of exact and c, f is q - p*y, if it is not set
+	Linear *LinearForm `json:"linear,omitempty" yaml:"linear,omitempty"`
+	// CBracket finds the constant of the exact solution numerically over the bracket instead of c
+	CBracket *CBracket `json:"c_bracket,omitempty" yaml:"c_bracket,omitempty"`
+	// Preset is the id of the built-in problem, that sets f, exact, c, the interval and the initial value
+	Preset string `json:"preset,omitempty" yaml:"preset,omitempty"`
+	// OutputGrid gives lines at its x instead of points of internal steps, solutions are interpolated at them
+	OutputGrid *OutputGrid `json:"output_grid,omitempty" yaml:"output_grid,omitempty"`
+}
+
+// CBracket is the bracket of the constant of the exact solution, where exact(x0, c) - y0 changes its sign
+type CBracket struct {
+	Lo  float64 `json:"lo" yaml:"lo"`
+	Hi  float64 `json:"hi" yaml:"hi"`
+	Tol float64 `json:"tol,omitempty" yaml:"tol,omitempty"` // of the residual, defaultCTol, if zero
+}
+
+// defaultCTol is the default tolerance of the residual of the numeric constant of the exact solution
+const defaultCTol = 1e-10
+
+// LinearForm is the linear first order equation y' + p(x)y = q(x)
+type LinearForm struct {
+	P string `json:"p" yaml:"p"`
+	Q string `json:"q" yaml:"q"`
+}
+
+// F returns y' = q(x) - p(x)y
+func (lf LinearForm) F() string { return "(" + lf.Q + ") - (" + lf.P + ")*y" }
+
+// quadSteps is the number of steps of the quadrature of the exact solution of the linear equation per step of the grid
+const quadSteps = 4
+
+// autoRefine doubles the number of steps of each method, until the max difference of successive solutions
+// at nodes of the coarser one drops below the tolerance
+type autoRefine struct {
+	Tol          float64 `json:"tol" yaml:"tol"`
+	MaxDoublings int     `json:"max_doublings,omitempty" yaml:"max_doublings,omitempty"` // up to max_steps, if zero
+}
+
+// Document is the document of the problem, accepted by the solve request in yaml and by the solve
+// command, output options are used only by the command
+type Document struct {
+	Request `yaml:",inline"`
+	Output  report.Output `json:"output,omitempty" yaml:"output,omitempty"`
+}
+
+// ParamName matches the valid name of the parameter
+var ParamName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Response describes the solutions of the initial value problem
+type Response struct {
+	Step        float64 `json:"step"`                   // step of lines, or of the exact solution, if grids differ
+	GridsDiffer bool    `json:"grids_differ,omitempty"` // methods have own steps in lines, so lines don't share x
+	Lines       []Line  `json:"lines"`
+	Exact       *Line   `json:"exact,omitempty"`
+	Took        string  `json:"took"`         // total duration of solving
+	ID          string  `json:"id,omitempty"` // id of the saved result
+	// Append tells, that lines continue the previous warm solve of the session, their points replace
+	// its points from the node AppendFrom on
+	Append     bool `json:"append,omitempty"`
+	AppendFrom int  `json:"append_from,omitempty"`
+	// Errors are local errors of methods against the exact solution, if requested
+	Errors []ErrorTable `json:"errors,omitempty"`
+}
+
+// Line describes the solution by a particular method
+type Line struct {
+	Method          string              `json:"method"`
+	Name            string              `json:"name"`
+	Step            float64             `json:"step,omitempty"` // step of the method, if grids of methods differ
+	Points          []num.Point         `json:"points"`
+	Discontinuities []solver.Bracket    `json:"discontinuities,omitempty"` // steps with poles of the exact solution
+	Sensitivity     []num.Point         `json:"sensitivity,omitempty"`     // dy/dy0 at the points, if requested
+	Estimates       []num.Point         `json:"estimates,omitempty"`       // estimated local errors at the points, if requested
+	Stats           *statsResp          `json:"stats,omitempty"`           // summary of the solution, unless it failed
+	Refine          *RefineResp         `json:"refine,omitempty"`          // refinement of the grid, if requested
+	Error           *rest.ErrorResponse `json:"error,omitempty"`           // describes why the method failed
+	Took            string              `json:"took"`
+}
+
+// statsResp summarizes the solution by the method, it is made from all points of the solution, before downsampling,
+// extrema are taken over finite values of y, so they are omitted, if there are none, as well as the final point,
+// if nothing is solved or its y is not finite, the wall time of the solution is the took of the line
+type statsResp struct {
+	Points   int        `json:"points"`             // number of points of the solution, before downsampling
+	Min      *num.Point `json:"min,omitempty"`      // the point of the least y, the first one of equal ones
+	Max      *num.Point `json:"max,omitempty"`      // the point of the largest y, the first one of equal ones
+	Final    *num.Point `json:"final,omitempty"`    // the last point of the solution
+	Evals    int        `json:"evals"`              // evaluations of f(x,y), zero for the exact solution
+	Warnings []string   `json:"warnings,omitempty"` // e.g. downsampling or discontinuities of the solution
+}
+
+// RefineResp describes the refinement of the grid of the method, the line is the solution on the finest grid
+type RefineResp struct {
+	N         int       `json:"n"` // number of steps of the finest grid
+	Doublings int       `json:"doublings"`
+	Diffs     []float64 `json:"diffs"` // max differences of levels from previous ones at nodes of previous ones
+	Converged bool      `json:"converged"`
+}
+
+// ExactMethod is the name of the exact solution in the list of methods
+const ExactMethod = "exact"
+
+// Prepared is the parsed solve request, ready to solve
+type Prepared struct {
+	Req  Request
+	Step float64 // step of all methods, the least of steps of methods, if they differ
+	// steps are steps of methods by their names, if they are solved on their own grids, nil otherwise,
+	// the exact solution has the step of the problem
+	steps   map[string]float64
+	Methods []string
+	Solvers []solver.Interface
+	Exact   solver.Interface // exact solution, that is not listed in methods
+	// exactSolver is the exact solution, listed in methods or not, solvers might be wrapped by metrics,
+	// so it is kept to report its discontinuities
+	exactSolver *solver.Exact
+	f, dfdy     solver.Func // f(x,y) and df/dy(x,y) for the sensitivity, dfdy is nil, if it is not set
+	fy          solver.Func // df/dy(x,y) of f, differentiated automatically, e.g. for the stiffness
+	// evals count evaluations of f by solvers, aligned with them, nil for the exact solution
+	evals    []*solver.CountingFunc
+	Recorder *Recorder    // records points of methods to replay the run, nil if the run is not recorded
+	Warm     *WarmRun     // resumes methods from the previous solve of the session, nil if the request is not warm
+	Progress *JobProgress // tracks x, reached by methods of the job, nil if the problem is not solved by the job
+	// doublings are the most doublings of n of methods to refine their grids, zero if grids are not refined
+	doublings int
+
+	// finite lines fail at not finite values with the step error, as json can't encode them
+	finite bool
+
+	maxPoints int // points of the line in the response, longer lines are downsampled, unlimited if zero
+	// outX are x of the output grid, lines are interpolated at them, nil if lines are given at internal steps
+	outX []float64
+}
+
+// Prepare validates the request, parses its formulas and instantiates requested solvers,
+// in case of invalid request, rest.ValidationError with all invalid fields is returned
+func (req Request) Prepare(l Limits) (Prepared, error) {
+	req, err := req.WithPreset()
+	if err != nil {
+		return Prepared{}, err
+	}
+	if req.Linear != nil && strings.TrimSpace(req.F) == "" {
+		req.F = req.Linear.F()
+	}
+	p := Prepared{Req: req, Step: req.Step, maxPoints: l.MaxPoints}
+	var errs rest.ValidationError
+	invalid := func(field, msg string, args ...interface{}) {
+		errs = append(errs, rest.FieldError{Field: field, Msg: fmt.Sprintf(msg, args...)})
+	}
+	invalidFormula := func(field, what string, err error) {
+		errs = append(errs, formulaError(field, what, err))
+	}
+
+	if !IsFinite(req.X0) {
+		invalid("x0", "must be finite")
+	}
+	if !IsFinite(req.Y0) {
+		invalid("y0", "must be finite")
+	}
+	if !IsFinite(req.XEnd) {
+		invalid("x_end", "must be finite")
+	} else if req.X0 == req.XEnd && (req.N != 0 || len(req.NByMethod) > 0) {
+		// the empty interval is solved as the single point with the step, but it can't be split into n steps
+		invalid("x_end", "must differ from x0, the interval of zero length can't be split into n steps")
+	} else if width := math.Abs(req.XEnd - req.X0); l.MaxWidth > 0 && width > l.MaxWidth {
+		invalid("x_end", "gives the interval of width %v, more than max_width=%v", width, l.MaxWidth)
+	}
+
+	switch {
+	case len(req.NByMethod) > 0 && (req.N != 0 || req.Step != 0):
+		invalid("n_by_method", "must not be set together with n or step")
+	case len(req.NByMethod) > 0:
+		p.Step, p.steps = req.methodSteps(l, invalid)
+	case req.N != 0 && req.Step != 0:
+		invalid("step", "must not be set together with n")
+	case req.N != 0:
+		if req.N < 1 || req.N > l.MaxSteps {
+			invalid("n", "must be between 1 and max_steps=%d, got %d", l.MaxSteps, req.N)
+			break
+		}
+		step, err := num.CalculateStepSize(req.N, req.X0, req.XEnd)
+		if errors.Is(err, num.ErrBadStep) {
+			invalid("n", "gives the step %v, the interval is too wide", (req.XEnd-req.X0)/float64(req.N))
+			break
+		}
+		if err != nil {
+			break // infinite bounds and the empty interval are reported with x0 and x_end
+		}
+		p.Step = step
+	case req.Step != 0:
+		if num.CheckStep(req.Step) != nil {
+			invalid("step", "must be positive")
+			break
+		}
+		if req.Steps() > float64(l.MaxSteps) {
+			invalid("step", "gives %.0f steps, more than max_steps=%d", math.Ceil(req.Steps()), l.MaxSteps)
+		}
+	default:
+		invalid("n", "either n or step must be set")
+	}
+	switch {
+	case req.Warm && req.Step == 0:
+		invalid("warm", "must be set with step, as n changes the step with x_end")
+	case req.Warm && req.Save:
+		invalid("warm", "must not be set together with save, as the result has only new points")
+	case req.Warm && req.Sensitivity:
+		invalid("warm", "must not be set together with sensitivity")
+	case req.Warm && req.Estimates:
+		invalid("warm", "must not be set together with estimates")
+	}
+	if req.Estimates && req.AutoRefine != nil {
+		invalid("estimates", "must not be set together with auto_refine")
+	}
+	if req.AutoRefine != nil {
+		p.doublings = req.AutoRefine.validate(req, l, invalid)
+	}
+	if req.OutputGrid != nil {
+		p.outX = req.OutputGrid.validate(req, l, invalid)
+	}
+
+	for _, name := range ParamNames(req.Params) {
+		switch {
+		case !ParamName.MatchString(name):
+			invalid("params", "%q is not a valid name", name)
+		case IsReserved(name):
+			invalid("params", "%q is reserved", name)
+		case !IsFinite(req.Params[name]):
+			invalid("params", "%s must be finite", name)
+		}
+	}
+
+	fxy, err := expr.Parse2(req.F, req.Params, "x", "y")
+	if err != nil {
+		invalidFormula("f", "f(x,y)", err)
+	}
+	// no method makes the first step, if the initial value is outside the domain of f
+	if fxy != nil && IsFinite(req.X0) && IsFinite(req.Y0) {
+		if v, _ := fxy(req.X0, req.Y0); !IsFinite(v) {
+			invalid("y0", "is outside the domain of f, f(x0,y0) = %v", v)
+		}
+	}
+	p.f = fxy
+	if fxy != nil {
+		p.fy, _ = expr.Derivative2(req.F, req.Params, "y", "x", "y") // f is parsed already
+	}
+	if req.DFDY != "" {
+		if !req.Sensitivity {
+			invalid("dfdy", "is used only with sensitivity")
+		}
+		if p.dfdy, err = expr.Parse2(req.DFDY, req.Params, "x", "y"); err != nil {
+			invalidFormula("dfdy", "df/dy(x,y)", err)
+		}
+	}
+
+	if len(req.Methods) == 0 {
+		invalid("methods", "must not be empty")
+	}
+	exactIdx := -1
+	for _, m := range req.Methods {
+		if m == ExactMethod {
+			// the exact solver is made below, its place is reserved to keep the order of methods
+			exactIdx = len(p.Solvers)
+			p.Methods = append(p.Methods, m)
+			p.Solvers = append(p.Solvers, nil)
+			p.evals = append(p.evals, nil)
+			continue
+		}
+		newSolver, ok := solver.Lookup(m)
+		if !ok {
+			invalid("methods", "unknown method %q", m)
+			continue
+		}
+		if _, known := MethodTraits[m]; req.Estimates && !known {
+			invalid("estimates", "the order of %q is unknown, errors are estimated only for euler, ieuler and rk4", m)
+		}
+		cnt := &solver.CountingFunc{F: fxy}
+		p.Methods = append(p.Methods, m)
+		p.Solvers = append(p.Solvers, newSolver(cnt.Eval))
+		p.evals = append(p.evals, cnt)
+	}
+
+	switch {
+	case req.Linear != nil && (req.Exact != "" || req.C != "" || req.CBracket != nil):
+		invalid("linear", "must not be set together with exact and c")
+	case req.Linear != nil:
+		p.Exact, p.exactSolver = req.Linear.exact(req.Params, req.X0, p.Step, invalid)
+	case req.Exact != "" || req.C != "" || req.CBracket != nil:
+		yxc, err := expr.Parse2(req.Exact, req.Params, "x", "c")
+		if err != nil {
+			invalidFormula("exact", "y(x,c)", err)
+		}
+		var c func(x0, y0 float64) (float64, error)
+		switch {
+		case req.CBracket != nil && req.C != "":
+			invalid("c_bracket", "must not be set together with c")
+		case req.CBracket != nil:
+			c = req.CBracket.constant(yxc, invalid)
+		default:
+			if c, err = expr.Parse2(req.C, req.Params, "x0", "y0"); err != nil {
+				invalidFormula("c", "c(x0,y0)", err)
+			}
+		}
+		exact := &solver.Exact{F: yxc, C: c}
+		// the constant is calculated once more by the solver, but the incompatible y0 is the mistake of the request
+		if c != nil && IsFinite(req.X0) && IsFinite(req.Y0) {
+			cv, err := exact.Constant(req.X0, req.Y0)
+			var serr *solver.StepError
+			switch {
+			case errors.Is(err, solver.ErrConstant):
+				invalid("y0", "is incompatible with the exact solution, c(x0,y0) is not finite")
+			case req.CBracket != nil && errors.As(err, &serr):
+				invalid("c_bracket", "can't find c: %v", serr.Err)
+			case err == nil && yxc != nil:
+				if y, _ := yxc(req.X0, cv); !IsFinite(y) {
+					invalid("y0", "is outside the domain of the exact solution, y(x0,c) = %v", y)
+				}
+			}
+		}
+		p.Exact, p.exactSolver = exact, exact
+	}
+
+	if req.Errors && p.exactSolver == nil {
+		invalid("errors", "require exact and c or linear")
+	}
+	if exactIdx >= 0 {
+		if p.Exact == nil {
+			invalid("methods", "exact solution requires exact and c or linear")
+		}
+		for i, m := range p.Methods {
+			if m == ExactMethod {
+				p.Solvers[i] = p.Exact
+			}
+		}
+		p.Exact = nil
+	}
+
+	if len(errs) > 0 {
+		return Prepared{}, errs
+	}
+	return p, nil
+}
+
+// formulaError describes the formula of the field, that can't be parsed, with the column of the syntax error
+func formulaError(field, what string, err error) rest.FieldError {
+	fe := rest.FieldError{Field: field, Msg: fmt.Sprintf("can't parse %s: %v", what, err)}
+	var se *expr.SyntaxError
+	if errors.As(err, &se) {
+		fe.Column = se.Pos
+	}
+	return fe
+}
+
+// constant makes the numeric constant of the exact solution, nil, if the bracket is invalid or the solution
+// is not parsed
+func (cb CBracket) constant(yxc func(x, c float64) (float64, error),
+	invalid func(field, msg string, args ...interface{})) func(x0, y0 float64) (float64, error) {
+	tol := cb.Tol
+	if tol == 0 {
+		tol = defaultCTol
+	}
+	switch {
+	case !IsFinite(cb.Lo) || !IsFinite(cb.Hi) || !(cb.Lo < cb.Hi):
+		invalid("c_bracket", "must be finite with lo < hi, got [%v, %v]", cb.Lo, cb.Hi)
+		return nil
+	case !(tol > 0) || math.IsInf(tol, 1):
+		invalid("c_bracket", "tol must be positive and finite, got %v", tol)
+		return nil
+	case yxc == nil:
+		return nil
+	}
+	return solver.NumericC(yxc, cb.Lo, cb.Hi, tol)
+}
+
+// exact parses p and q and makes the exact solution from x0 with the quadrature by quadSteps per step,
+// the solution is nil, if formulas are invalid or the step is not known
+func (lf LinearForm) exact(params map[string]float64, x0, step float64,
+	invalid func(field, msg string, args ...interface{})) (solver.Interface, *solver.Exact) {
+	px, err := expr.Parse(lf.P, params, "x")
+	if err != nil {
+		invalid("linear", "can't parse p(x): %v", err)
+	}
+	qx, errQ := expr.Parse(lf.Q, params, "x")
+	if errQ != nil {
+		invalid("linear", "can't parse q(x): %v", errQ)
+	}
+	if err != nil || errQ != nil || !(step > 0) {
+		return nil, nil
+	}
+	exact := solver.ExactLinear(func(x float64) (float64, error) { return px(x) },
+		func(x float64) (float64, error) { return qx(x) }, x0, step/quadSteps)
+	return exact, exact
+}
+
+// validate validates the refinement of the request under the limits and returns the most doublings of n,
+// they are limited by max_steps, unless they are set
+func (ar autoRefine) validate(req Request, l Limits, invalid func(field, msg string, args ...interface{})) int {
+	switch {
+	case req.N < 1:
+		invalid("auto_refine", "must be set with n, the initial number of steps")
+		return 0
+	case req.Warm || req.Sensitivity:
+		invalid("auto_refine", "must not be set together with warm or sensitivity")
+		return 0
+	case !(ar.Tol > 0) || math.IsInf(ar.Tol, 1):
+		invalid("auto_refine", "tol must be positive and finite, got %v", ar.Tol)
+		return 0
+	case ar.MaxDoublings < 0:
+		invalid("auto_refine", "max_doublings must not be negative, got %d", ar.MaxDoublings)
+		return 0
+	}
+	most := 0
+	for n := req.N; n <= l.MaxSteps/2; n *= 2 {
+		most++
+	}
+	switch {
+	case most == 0:
+		invalid("auto_refine", "n=%d can't be doubled under max_steps=%d", req.N, l.MaxSteps)
+	case ar.MaxDoublings > most:
+		invalid("auto_refine", "max_doublings=%d gives more steps than max_steps=%d, at most %d doublings are allowed",
+			ar.MaxDoublings, l.MaxSteps, most)
+	case ar.MaxDoublings > 0:
+		return ar.MaxDoublings
+	}
+	return most
+}
+
+// methodSteps validates numbers of steps of methods under the limits, each of them on its own, and returns
+// the least of steps, which is the step of the exact solution, and steps by methods, each requested method,
+// but the exact solution, must have the number of steps
+func (req Request) methodSteps(l Limits, invalid func(field, msg string, args ...interface{})) (float64, map[string]float64) {
+	requested := map[string]bool{}
+	for _, m := range req.Methods {
+		requested[m] = true
+		if _, ok := req.NByMethod[m]; !ok && m != ExactMethod {
+			invalid("n_by_method", "%s has no number of steps", m)
+		}
+	}
+
+	var least float64
+	steps := map[string]float64{}
+	for _, name := range req.nByMethodNames() {
+		n := req.NByMethod[name]
+		switch {
+		case name == ExactMethod:
+			invalid("n_by_method", "exact solution has no steps, it is drawn with the least step of methods")
+			continue
+		case !requested[name]:
+			invalid("n_by_method", "%s is not requested in methods", name)
+			continue
+		case n < 1 || n > l.MaxSteps:
+			invalid("n_by_method", "%s: must be between 1 and max_steps=%d, got %d", name, l.MaxSteps, n)
+			continue
+		}
+		step, err := num.CalculateStepSize(n, req.X0, req.XEnd)
+		if errors.Is(err, num.ErrBadStep) {
+			invalid("n_by_method", "%s: gives the step %v, the interval is too wide", name, (req.XEnd-req.X0)/float64(n))
+			continue
+		}
+		if err != nil {
+			continue // infinite bounds, the empty or reversed interval are reported with x0 and x_end
+		}
+		steps[name] = step
+		if least == 0 || step < least {
+			least = step
+		}
+	}
+	return least, steps
+}
+
+// nByMethodNames returns the sorted names of methods with numbers of steps
+func (req Request) nByMethodNames() []string {
+	names := make([]string, 0, len(req.NByMethod))
+	for name := range req.NByMethod {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StepOf returns the step of the method
+func (p Prepared) StepOf(method string) float64 {
+	if step, ok := p.steps[method]; ok {
+		return step
+	}
+	return p.Step
+}
+
+// Collector makes the collector of the solution with the step, its points are preallocated, so the collector
+// doesn't grow during the solution, and checked by solved, the collector of invalid arguments expects nothing,
+// as the solver refuses them anyway
+func Collector(step, x0, xEnd float64) *solver.Collector {
+	return collectorFrom(0, step, x0, xEnd)
+}
+
+// collectorFrom makes the collector of the solution, that starts at the node of the grid
+func collectorFrom(node int, step, x0, xEnd float64) *solver.Collector {
+	c := &solver.Collector{}
+	if n, err := solver.StepsCount(step, x0, xEnd); err == nil {
+		c.Expect(n - node)
+	}
+	return c
+}
+
+// exactCollector collects points of the solution along with its discontinuities, only the exact solver finds them
+type exactCollector struct {
+	*solver.Collector
+	found []solver.Bracket
+}
+
+// DrawDiscontinuities keeps discontinuities of the solution
+func (c *exactCollector) DrawDiscontinuities(b []solver.Bracket) error {
+	c.found = b
+	return nil
+}
+
+// Solved checks, that the solution, collected by the collector, has all points of the grid,
+// the mismatch is the internal error of the solver
+func Solved(c *solver.Collector) error {
+	return errors.Wrap(c.Check(), "solver lost points")
+}
+
+// Steps returns the number of steps, requested either by n or by the step size, or the largest
+// of numbers of steps of methods
+func (req Request) Steps() float64 {
+	if len(req.NByMethod) > 0 {
+		n := 0
+		for _, v := range req.NByMethod {
+			if v > n {
+				n = v
+			}
+		}
+		return float64(n)
+	}
+	if req.N != 0 || req.Step == 0 {
+		return float64(req.N)
+	}
+	return math.Abs(req.XEnd-req.X0) / req.Step
+}
+
+// CacheKey returns the canonical hash of the request, solved under the given limits
+func (req Request) CacheKey(l Limits) string {
+	h := sha256.New()
+	// maps are printed with sorted keys
+	_, _ = fmt.Fprintf(h, "%q %q %q %v %v %v %v %d %v %q %d %d", strings.TrimSpace(req.F), strings.TrimSpace(req.Exact),
+		strings.TrimSpace(req.C), req.Params, req.X0, req.Y0, req.XEnd, req.N, req.Step, req.Methods, l.MaxSteps, l.MaxPoints)
+	if req.Sensitivity {
+		_, _ = fmt.Fprintf(h, " sensitivity %q", strings.TrimSpace(req.DFDY))
+	}
+	if req.Estimates {
+		_, _ = fmt.Fprint(h, " estimates")
+	}
+	if len(req.NByMethod) > 0 {
+		_, _ = fmt.Fprintf(h, " n_by_method %v", req.NByMethod)
+	}
+	if req.AutoRefine != nil {
+		_, _ = fmt.Fprintf(h, " auto_refine %v %d", req.AutoRefine.Tol, req.AutoRefine.MaxDoublings)
+	}
+	if req.CBracket != nil {
+		_, _ = fmt.Fprintf(h, " c_bracket %v %v %v", req.CBracket.Lo, req.CBracket.Hi, req.CBracket.Tol)
+	}
+	if req.Linear != nil {
+		_, _ = fmt.Fprintf(h, " linear %q %q", strings.TrimSpace(req.Linear.P), strings.TrimSpace(req.Linear.Q))
+	}
+	if req.Preset != "" {
+		_, _ = fmt.Fprintf(h, " preset %q", req.Preset)
+	}
+	if og := req.OutputGrid; og != nil {
+		_, _ = fmt.Fprintf(h, " output_grid %d %v %q", og.Points, og.X, og.Interp)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ParamNames returns the sorted names of parameters
+func ParamNames(params map[string]float64) []string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsReserved checks whether the name is taken by the variable or the function of formulas
+func IsReserved(name string) bool {
+	switch name {
+	case "x", "y", "c", "x0", "y0":
+		return true
+	}
+	return expr.IsFunc(name)
+}
+
+// IsFinite checks whether the number is neither NaN nor infinite
+func IsFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// Each calls fn for each requested method in order, the exact solution,
+// if it is not listed in methods, goes last
+func (p Prepared) Each(fn func(method string, slvr solver.Interface) error) error {
+	for i, slvr := range p.Solvers {
+		if err := fn(p.Methods[i], slvr); err != nil {
+			return err
+		}
+	}
+	if p.Exact != nil {
+		return fn(ExactMethod, p.Exact)
+	}
+	return nil
+}
+
+// Solve the problem with all requested methods concurrently, the failure of the method is reported
+// in its line, the problem fails only if all methods fail or the context is done
+func (p Prepared) Solve(ctx context.Context) (Response, error) {
+	st := time.Now()
+
+	var names []string
+	var slvrs []solver.Interface
+	_ = p.Each(func(method string, slvr solver.Interface) error {
+		names, slvrs = append(names, method), append(slvrs, slvr)
+		return nil
+	})
+
+	lines := make([]Line, len(slvrs))
+	errs := make([]error, len(slvrs))
+	g, gctx := errgroup.WithContext(ctx)
+	for i := range slvrs {
+		i := i
+		g.Go(func() error {
+			var evals *solver.CountingFunc
+			if i < len(p.evals) {
+				evals = p.evals[i]
+			}
+			line, err := p.solveWith(gctx, names[i], slvrs[i], evals)
+			p.Recorder.done(names[i], line, err)
+			var te *TimeoutError
+			if errors.As(err, &te) {
+				return err
+			}
+			if err != nil {
+				be := rest.NewErrorResponse(err, "failed to solve", rest.ErrInternal)
+				line = Line{Method: names[i], Name: slvrs[i].Name(), Points: []num.Point{}, Error: &be, Took: line.Took}
+			}
+			lines[i], errs[i] = line, err
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return Response{}, err
+	}
+
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	if failed > 0 && failed == len(errs) {
+		return Response{}, errs[0]
+	}
+
+	// lines share x of the output grid, even if methods are solved on their own grids
+	resp := Response{Step: p.Step, GridsDiffer: p.gridsDiffer() && p.outX == nil, Lines: lines}
+	resp.AppendFrom = p.Warm.Start()
+	resp.Append = resp.AppendFrom > 0
+	if p.Exact != nil {
+		resp.Lines, resp.Exact = lines[:len(lines)-1], &lines[len(lines)-1]
+	}
+	resp.Took = time.Since(st).String()
+	return resp, nil
+}
+
+// FailNonFinite makes methods fail at the singularity of f with the step error and lines fail at not finite y,
+// instead of drawing not finite points, the problem must be freshly prepared, as its counters of evaluations are wrapped
+func (p *Prepared) FailNonFinite() {
+	for _, evals := range p.evals {
+		if evals != nil {
+			evals.F = solver.FiniteFunc(evals.F)
+		}
+	}
+	p.finite = true
+}
+
+// solveWith solves the problem with the solver of the method and summarizes the solution, evals counts
+// evaluations of f by the solver, nil if it doesn't evaluate f
+func (p Prepared) solveWith(ctx context.Context, method string, slvr solver.Interface,
+	evals *solver.CountingFunc) (Line, error) {
+	if p.doublings > 0 && method != ExactMethod {
+		return p.refineWith(ctx, method, evals)
+	}
+	st := time.Now()
+	step := p.StepOf(method)
+	c := collectorFrom(p.Warm.Start(), step, p.Req.X0, p.Req.XEnd)
+	calls := 0
+	if evals != nil {
+		calls = evals.Calls()
+	}
+	ec := &exactCollector{Collector: c}
+	stats, d := solver.WithStats(WithRequest(ctx, ec))
+	if p.finite {
+		d = solver.WithFinite(d, 0)
+	}
+	d = p.Recorder.wrap(method, d)
+	d = p.Progress.Wrap(method, d)
+	err := p.Warm.solve(slvr, method, step, p.Req.X0, p.Req.Y0, p.Req.XEnd, d)
+	if err == nil {
+		err = Solved(c)
+	}
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			te := &TimeoutError{Method: method, XReached: p.Req.X0}
+			if len(c.Points) > 0 {
+				te.XReached = c.Points[len(c.Points)-1].X
+			}
+			return Line{}, te
+		}
+		return Line{Took: time.Since(st).String()}, errors.Wrapf(err, "failed to solve with %s", method)
+	}
+	p.Warm.keep(method)
+	pts, err := p.output(method, c.Points)
+	if err != nil {
+		return Line{Took: time.Since(st).String()}, err
+	}
+	line := Line{Method: method, Name: slvr.Name(), Points: pts}
+	if p.gridsDiffer() {
+		line.Step = step
+	}
+	line.Discontinuities = ec.found
+	line.Stats = summarize(stats, p.shown(len(line.Points), stats.Points), len(line.Discontinuities))
+	if warn := p.stabilityWarning(method, step); warn != "" {
+		line.Stats.Warnings = append(line.Stats.Warnings, warn)
+	}
+	if evals != nil {
+		line.Stats.Evals = evals.Calls() - calls
+	}
+	if p.Req.Sensitivity && method != ExactMethod {
+		sens, err := p.sensitivity(method)
+		if err != nil {
+			return Line{Took: time.Since(st).String()}, errors.Wrapf(err, "failed to calculate sensitivity of %s", method)
+		}
+		line.Sensitivity = Downsample(sens, p.maxPoints)
+	}
+	if p.Req.Estimates && method != ExactMethod {
+		est, err := solver.EstimateLocal(Builder(method)(p.f), MethodTraits[method].Order, c.Points)
+		if err != nil {
+			return Line{Took: time.Since(st).String()}, errors.Wrapf(err, "failed to estimate errors of %s", method)
+		}
+		line.Estimates = Downsample(est, p.maxPoints)
+	}
+	line.Took = time.Since(st).String()
+	return line, nil
+}
+
+// refineWith solves the problem with the method on grids of doubled numbers of steps, until they converge,
+// the line is the solution on the finest grid, the solution, that is not converged, is not failed,
+// but warned, evals counts evaluations of f on all grids
+func (p Prepared) refineWith(ctx context.Context, method string, evals *solver.CountingFunc) (Line, error) {
+	st := time.Now()
+	f := p.f
+	if evals != nil {
+		f = evals.Eval
+	}
+	calls := 0
+	if evals != nil {
+		calls = evals.Calls()
+	}
+	// the refinement is stopped by the context at the next evaluation of f
+	fctx := func(x, y float64) (float64, error) {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		return f(x, y)
+	}
+	ar := p.Req.AutoRefine
+	res, err := solver.Refine(Builder(method), fctx, p.Req.X0, p.Req.Y0, p.Req.XEnd, p.Req.N, ar.Tol, p.doublings)
+	if err != nil && !errors.Is(err, solver.ErrNotConverged) {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return Line{}, &TimeoutError{Method: method, XReached: p.Req.X0}
+		}
+		return Line{Took: time.Since(st).String()}, errors.Wrapf(err, "failed to refine %s", method)
+	}
+
+	stats, d := solver.WithStats(solver.DrawerFunc(func(num.Point) error { return nil }))
+	for _, pt := range res.Line.Points {
+		_ = d.Draw(pt)
+	}
+	step, _ := num.CalculateStepSize(res.N, p.Req.X0, p.Req.XEnd) // the step is checked by the refinement
+	pts, oerr := p.output(method, res.Line.Points)
+	if oerr != nil {
+		return Line{Took: time.Since(st).String()}, oerr
+	}
+	line := Line{Method: method, Name: res.Line.Name, Step: step, Points: pts}
+	line.Refine = &RefineResp{N: res.N, Doublings: res.Doublings, Diffs: res.Diffs, Converged: err == nil}
+	line.Stats = summarize(stats, p.shown(len(line.Points), stats.Points), 0)
+	if err != nil {
+		line.Stats.Warnings = append(line.Stats.Warnings, fmt.Sprintf("not converged to tol=%v after %d doublings",
+			ar.Tol, res.Doublings))
+	}
+	if evals != nil {
+		line.Stats.Evals = evals.Calls() - calls
+	}
+	line.Took = time.Since(st).String()
+	return line, nil
+}
+
+// gridsDiffer checks whether methods are solved on their own grids
+func (p Prepared) gridsDiffer() bool {
+	return p.steps != nil || p.doublings > 0
+}
+
+// summarize makes the summary of the solution from its stats, the solution is downsampled to the given
+// number of points and has the given number of discontinuities
+func summarize(st *solver.Stats, downsampled, discontinuities int) *statsResp {
+	res := &statsResp{Points: st.Points}
+	if st.HasExtrema() {
+		min, max := st.Min, st.Max
+		res.Min, res.Max = &min, &max
+	}
+	if final := st.Final; st.Points > 0 && IsFinite(final.Y) {
+		res.Final = &final
+	}
+	if st.NonFinite > 0 {
+		res.Warnings = append(res.Warnings, fmt.Sprintf("%d points have not finite y, they are skipped by min and max",
+			st.NonFinite))
+	}
+	if downsampled < st.Points {
+		res.Warnings = append(res.Warnings, fmt.Sprintf("points are downsampled from %d to %d by max_points",
+			st.Points, downsampled))
+	}
+	if discontinuities > 0 {
+		res.Warnings = append(res.Warnings, fmt.Sprintf("solution has %d discontinuities", discontinuities))
+	}
+	return res
+}
+
+// sensitivity calculates dy/dy0 of the solution by the method, the variational equation is solved
+// by Runge-Kutta's method for any method, as it needs the system solver
+func (p Prepared) sensitivity(method string) ([]num.Point, error) {
+	if p.dfdy != nil {
+		return solver.Variational(p.f, p.dfdy, p.StepOf(method), p.Req.X0, p.Req.Y0, p.Req.XEnd)
+	}
+	return solver.Sensitivity(Builder(method), p.f, p.StepOf(method), p.Req.X0, p.Req.Y0, p.Req.XEnd, 0)
+}
+
+// DecodeYAML decodes the problem document in yaml, fields unknown to the document are rejected
+func DecodeYAML(rd io.Reader) (Document, error) {
+	doc := Document{}
+	dec := yaml.NewDecoder(rd)
+	dec.KnownFields(true)
+	if err := dec.Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return Document{}, errors.New("document is empty")
+		}
+		return Document{}, err
+	}
+	return doc, nil
+}
+
+// DecodeJSON decodes the problem document from json, as DecodeYAML does from yaml,
+// fields unknown to the document are rejected
+func DecodeJSON(rd io.Reader) (Document, error) {
+	doc := Document{}
+	dec := json.NewDecoder(rd)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return Document{}, errors.New("request is empty")
+		}
+		return Document{}, err
+	}
+	return doc, nil
+}
+
+// WithRequest wraps the drawer to stop solving, as soon as the context is done,
+// and to tag logs of the solver with the id of the request
+func WithRequest(ctx context.Context, d solver.Drawer) solver.Drawer {
+	return solver.WithLogger(solver.WithContext(ctx, d), rest.CtxLogger(ctx))
+}
+
+// WriteCSV writes the x column and the column of y values for each line, including the exact solution,
+// failed lines are skipped, all lines share the grid of the longest one, so the value of the line
+// is written in the row of its step, and the missing values are left empty, lines on their own grids
+// are written as rows of the method, x and y
+func (resp Response) WriteCSV(wr io.Writer) error {
+	header, rows := resp.Table(0)
+	return report.WriteCSV(wr, header, rows)
+}
+
+// Table returns the header and the rows of the solution with the x column and the column of y values for each line,
+// failed lines are skipped, all lines share the grid of the longest one, numbers are formatted with prec
+// significant digits, or without the loss of precision, if prec is zero, if grids of methods differ, x isn't
+// shared, so each point is the row of the method, x and y
+func (resp Response) Table(prec int) (header []string, rows [][]string) {
+	var lines []Line
+	for _, line := range resp.Lines {
+		if line.Error == nil {
+			lines = append(lines, line)
+		}
+	}
+	if resp.Exact != nil && resp.Exact.Error == nil {
+		lines = append(lines, *resp.Exact)
+	}
+
+	if resp.GridsDiffer {
+		header = []string{"method", "x", "y"}
+		for _, line := range lines {
+			for _, pt := range line.Points {
+				rows = append(rows, []string{line.Method, formatDigits(pt.X, prec), formatDigits(pt.Y, prec)})
+			}
+		}
+		return header, rows
+	}
+
+	header = []string{"x"}
+	var grid []num.Point
+	for _, line := range lines {
+		header = append(header, line.Method)
+		if len(line.Points) > len(grid) {
+			grid = line.Points
+		}
+	}
+
+	for i, pt := range grid {
+		row := []string{formatDigits(pt.X, prec)}
+		for _, line := range lines {
+			y := ""
+			if i < len(line.Points) {
+				y = formatDigits(line.Points[i].Y, prec)
+			}
+			row = append(row, y)
+		}
+		rows = append(rows, row)
+	}
+	return header, rows
+}
+
+// Summary returns the header and the rows of stats of lines, including the exact solution, failed lines
+// are skipped, missing values are left empty, numbers are formatted as in the table
+func (resp Response) Summary(prec int) (header []string, rows [][]string) {
+	lines := resp.Lines
+	if resp.Exact != nil {
+		lines = append(lines[:len(lines):len(lines)], *resp.Exact)
+	}
+
+	header = []string{"method", "points", "min_x", "min", "max_x", "max", "final_x", "final", "evals", "took", "warnings"}
+	point := func(p *num.Point) []string {
+		if p == nil {
+			return []string{"", ""}
+		}
+		return []string{formatDigits(p.X, prec), formatDigits(p.Y, prec)}
+	}
+	for _, line := range lines {
+		if line.Error != nil || line.Stats == nil {
+			continue
+		}
+		st := line.Stats
+		row := []string{line.Method, strconv.Itoa(st.Points)}
+		row = append(row, point(st.Min)...)
+		row = append(row, point(st.Max)...)
+		row = append(row, point(st.Final)...)
+		row = append(row, strconv.Itoa(st.Evals), line.Took, strings.Join(st.Warnings, "; "))
+		rows = append(rows, row)
+	}
+	return header, rows
+}
+
+// FormatFloat formats the number in the shortest representation without the loss of precision
+func FormatFloat(v float64) string {
+	return rest.NumberFormat{}.Format(v)
+}
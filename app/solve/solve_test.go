@@ -0,0 +1,40 @@
+package solve
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSolveResp_WriteCSV(t *testing.T) {
+	// lines of different lengths share the grid of the longest one
+	resp := Response{
+		Lines: []Line{
+			{Method: "a", Points: []num.Point{{X: 0, Y: 1}}},
+			{Method: "failed", Error: &rest.ErrorResponse{Error: "failed"}},
+			{Method: "b", Points: []num.Point{{X: 0, Y: 2}, {X: 0.5, Y: 3}}},
+		},
+		Exact: &Line{Method: "exact", Points: []num.Point{{X: 0, Y: 4}, {X: 0.5, Y: 5}}},
+	}
+	sb := &strings.Builder{}
+	require.NoError(t, resp.WriteCSV(sb))
+	assert.Equal(t, "x,a,b,exact\n0,1,2,4\n0.5,,3,5\n", sb.String())
+}
+
+func TestSummarize(t *testing.T) {
+	assert.Equal(t, &statsResp{}, summarize(&solver.Stats{}, 0, 0), "nothing is solved")
+
+	st, d := solver.WithStats(&solver.Collector{})
+	for _, p := range []num.Point{{X: 0, Y: 1}, {X: 1, Y: math.Inf(1)}, {X: 2, Y: math.NaN()}} {
+		require.NoError(t, d.Draw(p))
+	}
+	assert.Equal(t, &statsResp{Points: 3, Min: &num.Point{X: 0, Y: 1}, Max: &num.Point{X: 0, Y: 1},
+		Warnings: []string{"2 points have not finite y, they are skipped by min and max",
+			"solution has 1 discontinuities"}}, summarize(st, 3, 1), "the final point is not finite")
+}
@@ -0,0 +1,83 @@
+package solve
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/Semior001/decompract/app/num/solver"
+)
+
+// StabilityCheck is the step hλ, checked against the stability region of the method
+type StabilityCheck struct {
+	Z       [2]float64 `json:"z"` // real and imaginary parts of hλ
+	AbsR    float64    `json:"abs_r"`
+	Stable  bool       `json:"stable"`
+	Warning string     `json:"warning,omitempty"`
+}
+
+// CheckStability checks the step h with λ against the stability region of the method, the step is warned,
+// if it is unstable, while the exact solution decays, for growing solutions |R(hλ)| > 1 follows the growth
+func CheckStability(method string, h float64, lambda complex128) (*StabilityCheck, error) {
+	z := complex(h, 0) * lambda
+	stable, abs, err := solver.Stable(method, z)
+	if err != nil {
+		return nil, err
+	}
+	res := &StabilityCheck{Z: [2]float64{real(z), imag(z)}, AbsR: abs, Stable: stable}
+	if stable || real(z) >= 0 {
+		return res, nil
+	}
+	msg := []string{fmt.Sprintf("hλ = %s is outside of the stability region of %s, |R(hλ)| = %.3g, errors grow "+
+		"by each step, while the solution decays", formatComplex(z), method, abs)}
+	if imag(z) == 0 {
+		interval, err := solver.StabilityInterval(method)
+		if err != nil {
+			return nil, err
+		}
+		msg = append(msg, fmt.Sprintf("the step must be less than %.3g", interval/math.Abs(real(lambda))))
+	}
+	res.Warning = strings.Join(msg, ", ")
+	return res, nil
+}
+
+// stabilityWarning checks the step of the method against its stability region with λ = df/dy at the initial
+// point, df/dy is taken from the request or found by the automatic differentiation of f, the warning is empty,
+// if the step is stable, or the method, or df/dy at the point is unknown
+func (p Prepared) stabilityWarning(method string, step float64) string {
+	if _, err := solver.StabilityFunc(method); err != nil {
+		return ""
+	}
+	dfdy := p.dfdy
+	if dfdy == nil {
+		dfdy = p.fy
+	}
+	if dfdy == nil {
+		return ""
+	}
+	lambda, err := dfdy(p.Req.X0, p.Req.Y0)
+	if err != nil {
+		return ""
+	}
+	if !IsFinite(lambda) {
+		return ""
+	}
+	what := "df/dy"
+	if p.Req.XEnd < p.Req.X0 {
+		// the backward step is -h, so h*λ in the stability region is h*(-df/dy)
+		lambda, what = -lambda, "-df/dy of the backward solve"
+	}
+	c, err := CheckStability(method, step, complex(lambda, 0))
+	if err != nil || c.Warning == "" {
+		return ""
+	}
+	return fmt.Sprintf("λ = %s = %.3g at x0: %s", what, lambda, c.Warning)
+}
+
+// formatComplex formats z with 3 significant digits of its parts, the imaginary part is omitted, if it is zero
+func formatComplex(z complex128) string {
+	if imag(z) == 0 {
+		return fmt.Sprintf("%.3g", real(z))
+	}
+	return fmt.Sprintf("%.3g%+.3gi", real(z), imag(z))
+}
@@ -0,0 +1,68 @@
+package solve
+
+import (
+	"context"
+	"time"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/pkg/errors"
+)
+
+// PointEvent is the data of the point event in the stream
+type PointEvent struct {
+	Method string  `json:"method"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+}
+
+// StreamSummary is the data of the terminal event in the stream
+type StreamSummary struct {
+	Lines []LineSummary `json:"lines"`
+	Took  string        `json:"took"`
+}
+
+// LineSummary describes the streamed solution by a particular method
+type LineSummary struct {
+	Method string `json:"method"`
+	Name   string `json:"name"`
+	Points int    `json:"points"`
+	Took   string `json:"took"`
+}
+
+// Stream solves the problem with all requested methods and sends the points to the stream,
+// solving stops as soon as the context is done
+func (p Prepared) Stream(ctx context.Context, sw EventWriter) (StreamSummary, error) {
+	st := time.Now()
+	res := StreamSummary{}
+	err := p.Each(func(method string, slvr solver.Interface) error {
+		lst := time.Now()
+		cnt := 0
+		d := p.thin(method, solver.DrawerFunc(func(pt num.Point) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			cnt++
+			return sw.Event("point", PointEvent{Method: method, X: pt.X, Y: pt.Y})
+		}))
+		if err := slvr.Solve(p.StepOf(method), p.Req.X0, p.Req.Y0, p.Req.XEnd, solver.WithLogger(d, rest.CtxLogger(ctx))); err != nil {
+			return errors.Wrapf(err, "failed to solve with %s", method)
+		}
+		if err := d.flush(); err != nil {
+			return err
+		}
+		res.Lines = append(res.Lines, LineSummary{Method: method, Name: slvr.Name(), Points: cnt, Took: time.Since(lst).String()})
+		return nil
+	})
+	if err != nil {
+		return StreamSummary{}, err
+	}
+	res.Took = time.Since(st).String()
+	return res, nil
+}
+
+// EventWriter writes the event with the json-encoded data to the stream and flushes it
+type EventWriter interface {
+	Event(name string, data interface{}) error
+}
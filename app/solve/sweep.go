@@ -0,0 +1,441 @@
+package solve
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/analyzer"
+	"github.com/Semior001/decompract/app/num/expr"
+	"github.com/Semior001/decompract/app/num/graph"
+	"github.com/Semior001/decompract/app/num/interp"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/pkg/errors"
+)
+
+// DefaultMaxSweep is the default maximal number of values of n in the error sweep
+const DefaultMaxSweep = 200
+
+// referenceMethod solves the reference solution, if the exact one is not given
+const referenceMethod = "rk4"
+
+// refineFactor is the number of steps of the reference solution per step of the finest grid of the sweep
+const refineFactor = 8
+
+// exactMemoSize is the number of values of the exact solution, cached by the reference of the sweep,
+// methods of the sweep are solved on the same nodes with each n, so the exact value at each node is reused
+const exactMemoSize = 1 << 16
+
+// gteFloor is the error, below which the error of the method is dominated by rounding,
+// such errors are not used to fit the order of convergence
+const gteFloor = 1e-12
+
+// SweepReq describes the problem to solve with each number of steps from n0 to n1,
+// or with n0 doubled until n1, if the sweep is geometric
+type SweepReq struct {
+	Request
+	N0        int
+	N1        int
+	Geometric bool
+}
+
+// ErrorsResp describes the global truncation errors of methods by the number of steps
+type ErrorsResp struct {
+	Reference string    `json:"reference"` // exact or the method of the reference solution
+	Lines     []GTELine `json:"lines"`
+	Took      string    `json:"took"`
+}
+
+// GTELine describes the errors of the particular method
+type GTELine struct {
+	Method   string              `json:"method"`
+	Name     string              `json:"name"`
+	Points   []GTEPoint          `json:"points"`
+	Order    *float64            `json:"order,omitempty"`    // fitted order of convergence, missing if it can't be fitted
+	Failures []GTEFailure        `json:"failures,omitempty"` // numbers of steps, the method failed with
+	Error    *rest.ErrorResponse `json:"error,omitempty"`    // describes why the method failed with each n
+	Took     string              `json:"took"`
+}
+
+// GTEPoint is the max global truncation error of the solution with n steps
+type GTEPoint struct {
+	N     int      `json:"n"`
+	H     float64  `json:"h"` // step size
+	GTE   float64  `json:"gte"`
+	Order *float64 `json:"order,omitempty"` // observed order from the previous point, missing if errors are negligible
+}
+
+// GTEFailure describes why the method failed with n steps
+type GTEFailure struct {
+	N     int                `json:"n"`
+	Error rest.ErrorResponse `json:"error"`
+}
+
+// Sweep is the parsed sweep request, ready to run
+type Sweep struct {
+	Req     SweepReq
+	Problem Prepared
+	F       solver.Func   // f(x,y) of the problem
+	exact   *solver.Exact // exact solution, the reference is solved with referenceMethod, if nil
+	Workers int           // solutions, solved at once, GOMAXPROCS if not positive
+	memo    int           // values of the exact solution, cached by the reference, none if not positive
+}
+
+// Prepare validates the request, in case of invalid request, rest.ValidationError with all invalid fields is returned
+func (req SweepReq) Prepare(l Limits) (Sweep, error) {
+	// the number of steps is validated here, as it is swept
+	sr := req.Request
+	sr.N, sr.Step, sr.NByMethod, sr.AutoRefine = 1, 0, nil, nil
+	p, err := sr.Prepare(l)
+	var errs rest.ValidationError
+	if err != nil && !errors.As(err, &errs) {
+		return Sweep{}, err
+	}
+	invalid := func(field, msg string, args ...interface{}) {
+		errs = append(errs, rest.FieldError{Field: field, Msg: fmt.Sprintf(msg, args...)})
+	}
+
+	switch {
+	case len(req.NByMethod) > 0:
+		invalid("n_by_method", "must not be set, as n is swept")
+	case req.AutoRefine != nil:
+		invalid("auto_refine", "must not be set, as n is swept")
+	case req.N0 < 1:
+		invalid("n0", "must be positive, got %d", req.N0)
+	case req.N1 < req.N0:
+		invalid("n1", "must not be less than n0=%d, got %d", req.N0, req.N1)
+	case req.N1 > l.MaxSteps:
+		invalid("n1", "must not be more than max_steps=%d, got %d", l.MaxSteps, req.N1)
+	case len(req.ns()) > l.MaxSweep:
+		invalid("n1", "gives %d values of n, more than max_sweep=%d", len(req.ns()), l.MaxSweep)
+	}
+	for _, m := range req.Methods {
+		if m == ExactMethod {
+			invalid("methods", "exact solution is the reference, it has no errors")
+		}
+	}
+
+	if len(errs) > 0 {
+		return Sweep{}, errs
+	}
+
+	sw := Sweep{Req: req, Problem: p, memo: exactMemoSize}
+	sw.F, _ = expr.Parse2(req.F, req.Params, "x", "y") // already parsed by the solve request
+	if p.Exact != nil {
+		sw.exact = p.Exact.(*solver.Exact)
+	}
+	return sw, nil
+}
+
+// CacheKey returns the canonical hash of the request, it differs from keys of solve requests
+func (req SweepReq) CacheKey() string {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "errors %q %q %q %v %v %v %v %q %d %d %v", strings.TrimSpace(req.F), strings.TrimSpace(req.Exact),
+		strings.TrimSpace(req.C), req.Params, req.X0, req.Y0, req.XEnd, req.Methods, req.N0, req.N1, req.Geometric)
+	if req.CBracket != nil {
+		_, _ = fmt.Fprintf(h, " c_bracket %v %v %v", req.CBracket.Lo, req.CBracket.Hi, req.CBracket.Tol)
+	}
+	if req.Linear != nil {
+		_, _ = fmt.Fprintf(h, " linear %q %q", strings.TrimSpace(req.Linear.P), strings.TrimSpace(req.Linear.Q))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ns returns the numbers of steps of the sweep in the ascending order
+func (req SweepReq) ns() []int {
+	var res []int
+	for n := req.N0; n >= 1 && n <= req.N1; n++ {
+		res = append(res, n)
+		if req.Geometric {
+			n = 2*n - 1
+		}
+	}
+	return res
+}
+
+// Run solves the problem with each method and each number of steps by workers of the sweep, each solution
+// is the separate task with its own collector and result, so lines are assembled in the order of n, whatever
+// the order of completion is, failures of the method with particular n are reported in its line, the sweep
+// fails only if all methods fail with each n. If the context is done, the rest of solutions are not started
+// and the error of the context is returned along with the lines of completed solutions
+func (sw Sweep) Run(ctx context.Context) (ErrorsResp, error) {
+	st := time.Now()
+	resp := ErrorsResp{Reference: ExactMethod}
+	if sw.exact == nil {
+		resp.Reference = referenceMethod
+	}
+
+	ref, err := sw.Reference(ctx)
+	if err != nil {
+		return ErrorsResp{}, err
+	}
+
+	// tasks are interleaved by methods, so lines are filled from the least n evenly
+	ns, methods := sw.Req.ns(), len(sw.Problem.Solvers)
+	results := make([]sweepResult, len(ns)*methods)
+	err = ForEach(ctx, len(results), sw.Workers, func(ctx context.Context, k int) (err error) {
+		i, n := k%methods, ns[k/methods]
+		results[k], err = sw.solveAt(ctx, sw.Problem.Methods[i], sw.Problem.Solvers[i], n, ref)
+		return err
+	})
+
+	resp.Lines = make([]GTELine, methods)
+	failed := 0
+	for i := range resp.Lines {
+		lineResults := make([]sweepResult, 0, len(ns))
+		for k := i; k < len(results); k += methods {
+			lineResults = append(lineResults, results[k])
+		}
+		resp.Lines[i] = sw.line(sw.Problem.Methods[i], sw.Problem.Solvers[i].Name(), lineResults, err == nil)
+		if resp.Lines[i].Error != nil {
+			failed++
+		}
+	}
+	resp.Took = time.Since(st).String()
+	if err != nil {
+		return resp, err
+	}
+
+	if failed == len(resp.Lines) {
+		return ErrorsResp{}, errors.Errorf("all methods failed with each n, %s", resp.Lines[0].Error.Error)
+	}
+	return resp, nil
+}
+
+// Reference returns the function, that gives the reference value of the solution at x, either from
+// the exact solution or from the fine solution by referenceMethod, interpolated between its nodes
+func (sw Sweep) Reference(ctx context.Context) (func(x float64) (float64, error), error) {
+	x0, y0, xEnd := sw.Req.X0, sw.Req.Y0, sw.Req.XEnd
+	if sw.exact != nil {
+		c, err := sw.exact.Constant(x0, y0)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to calculate constant of the reference")
+		}
+		if sw.memo < 1 {
+			return func(x float64) (float64, error) { return sw.exact.F(x, c) }, nil
+		}
+		return sw.exact.Memo(c, sw.memo).At, nil
+	}
+
+	step, err := num.CalculateStepSize(refineFactor*sw.Req.N1, x0, xEnd)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to calculate step of the reference")
+	}
+	c := &solver.Collector{}
+	// nodes of the reference, as well as of methods, are on the grid, so the reference ends exactly at x_end
+	if err = Builder(referenceMethod)(sw.F).Solve(step, x0, y0, xEnd, WithRequest(ctx, c)); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, &TimeoutError{Method: referenceMethod, XReached: LastX(c.Points, x0)}
+		}
+		return nil, errors.Wrapf(err, "failed to solve the reference with %s", referenceMethod)
+	}
+	slopes := make([]float64, len(c.Points))
+	for i, pt := range c.Points {
+		var err error
+		if slopes[i], err = sw.F(pt.X, pt.Y); err != nil || !IsFinite(pt.Y) || !IsFinite(slopes[i]) {
+			return nil, errors.Errorf("reference solution by %s diverged at x=%.4f", referenceMethod, pt.X)
+		}
+	}
+	h, err := interp.NewHermite(c.Points, slopes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to interpolate the reference solution")
+	}
+	lo, hi := h.Range()
+	return func(x float64) (float64, error) {
+		// x of the method, as well as of the reference, might drift out of the interval by the rounding error
+		return h.At(math.Max(lo, math.Min(hi, x)))
+	}, nil
+}
+
+// sweepResult is the outcome of the solution of the sweep with n steps, done is false, if it is not solved
+type sweepResult struct {
+	done    bool
+	pt      GTEPoint
+	failure *GTEFailure
+	took    time.Duration
+}
+
+// solveAt solves the problem with the method and n steps and measures the max error, only the done context
+// fails the solution, the failure with n is reported in the result
+func (sw Sweep) solveAt(ctx context.Context, method string, slvr solver.Interface, n int,
+	ref func(x float64) (float64, error)) (sweepResult, error) {
+	st := time.Now()
+	fail := func(err error, details string) (sweepResult, error) {
+		return sweepResult{done: true, took: time.Since(st),
+			failure: &GTEFailure{N: n, Error: rest.NewErrorResponse(err, details, rest.ErrInternal)}}, nil
+	}
+
+	h, err := num.CalculateStepSize(n, sw.Req.X0, sw.Req.XEnd)
+	if err != nil {
+		return fail(err, "invalid number of steps")
+	}
+	c := Collector(h, sw.Req.X0, sw.Req.XEnd)
+	err = slvr.Solve(h, sw.Req.X0, sw.Req.Y0, sw.Req.XEnd, WithRequest(ctx, c))
+	if err == nil {
+		err = Solved(c)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return sweepResult{}, &TimeoutError{Method: method, XReached: LastX(c.Points, sw.Req.X0)}
+	}
+	if ctx.Err() != nil {
+		return sweepResult{}, ctx.Err()
+	}
+	if err != nil {
+		return fail(errors.Wrapf(err, "failed to solve with %s and n=%d", method, n), "failed to solve")
+	}
+	gte, err := maxError(c.Points, ref)
+	if err != nil {
+		return fail(errors.Wrapf(err, "failed to calculate error of %s with n=%d", method, n), "solution diverged")
+	}
+	return sweepResult{done: true, pt: GTEPoint{N: n, H: h, GTE: gte}, took: time.Since(st)}, nil
+}
+
+// line assembles the line of the method from results of its solutions in the order of n, solutions, that
+// are not done, are skipped, the method fails, if the sweep is complete and each of solutions failed,
+// the time of the line is the total time of its solutions
+func (sw Sweep) line(method, name string, results []sweepResult, complete bool) GTELine {
+	line := GTELine{Method: method, Name: name, Points: []GTEPoint{}}
+	var took time.Duration
+	for _, res := range results {
+		if !res.done {
+			continue
+		}
+		took += res.took
+		if res.failure != nil {
+			line.Failures = append(line.Failures, *res.failure)
+			continue
+		}
+		pt := res.pt
+		if len(line.Points) > 0 {
+			if order, ok := observedOrder(line.Points[len(line.Points)-1], pt); ok {
+				pt.Order = &order
+			}
+		}
+		line.Points = append(line.Points, pt)
+	}
+
+	if complete && len(line.Points) == 0 {
+		be := rest.NewErrorResponse(errors.Errorf("%s failed with each n from %d to %d", method, sw.Req.N0, sw.Req.N1),
+			"failed to calculate errors", rest.ErrInternal)
+		line.Error = &be
+	}
+	if order, ok := fitOrder(line.Points); ok {
+		line.Order = &order
+	}
+	line.Took = took.String()
+	return line
+}
+
+// maxError returns the max absolute difference between the points of the solution and the reference
+func maxError(pts []num.Point, ref func(x float64) (float64, error)) (float64, error) {
+	errs, err := analyzer.Global(pts, ref)
+	if err != nil {
+		return 0, err
+	}
+	res := 0.0
+	for _, e := range errs {
+		res = math.Max(res, e.Y)
+	}
+	return res, nil
+}
+
+// fitOrder fits log(gte) = log(C) - order*log(n) by least squares, points with errors below gteFloor
+// are skipped, false is returned, if less than two points are left
+func fitOrder(pts []GTEPoint) (float64, bool) {
+	var sx, sy, sxx, sxy float64
+	k := 0
+	for _, pt := range pts {
+		if pt.GTE <= gteFloor {
+			continue
+		}
+		x, y := math.Log(float64(pt.N)), math.Log(pt.GTE)
+		sx, sy, sxx, sxy = sx+x, sy+y, sxx+x*x, sxy+x*y
+		k++
+	}
+	if k < 2 {
+		return 0, false
+	}
+	slope := (float64(k)*sxy - sx*sy) / (float64(k)*sxx - sx*sx)
+	return -slope, true
+}
+
+// Table returns the header and the rows of errors of each method by the number of steps with observed orders,
+// rows of failed numbers of steps and orders, that can't be observed, are left as dashes, errors are formatted
+// in the scientific notation with prec significant digits, four by default, as well as orders
+func (resp ErrorsResp) Table(prec int) (header []string, rows [][]string) {
+	if prec == 0 {
+		prec = 4
+	}
+	header = []string{"method", "n", "h", "max_gte", "order"}
+	for _, line := range resp.Lines {
+		pts, fails := line.Points, line.Failures
+		for len(pts) > 0 || len(fails) > 0 {
+			if len(pts) == 0 || (len(fails) > 0 && fails[0].N < pts[0].N) {
+				rows = append(rows, []string{line.Method, strconv.Itoa(fails[0].N), "-", "-", "-"})
+				fails = fails[1:]
+				continue
+			}
+			pt := pts[0]
+			order := "-"
+			if pt.Order != nil {
+				order = strconv.FormatFloat(*pt.Order, 'f', prec-1, 64)
+			}
+			rows = append(rows, []string{line.Method, strconv.Itoa(pt.N), formatDigits(pt.H, prec),
+				strconv.FormatFloat(pt.GTE, 'e', prec-1, 64), order})
+			pts = pts[1:]
+		}
+	}
+	return header, rows
+}
+
+// Chart plots max errors of methods by the number of steps on logarithmic axes, so the slope of the line
+// is the order of convergence, errors below gteFloor and failed methods are skipped
+func (resp ErrorsResp) Chart(pl graph.Plotter, img graph.Image) ([]byte, error) {
+	lines := resp.GTELines()
+	if len(lines) == 0 {
+		return nil, errors.New("no errors above the rounding error to plot")
+	}
+	return pl.PlotLogLog("Max global errors", "N", "GTE", lines, img)
+}
+
+// GTELines returns max errors of methods by the number of steps, errors below gteFloor are skipped,
+// as well as methods without errors above it
+func (resp ErrorsResp) GTELines() []num.Line {
+	var lines []num.Line
+	for _, line := range resp.Lines {
+		l := num.Line{Name: line.Name}
+		for _, pt := range line.Points {
+			if pt.GTE > gteFloor {
+				l.Points = append(l.Points, num.Point{X: float64(pt.N), Y: pt.GTE})
+			}
+		}
+		if len(l.Points) > 0 {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
+// observedOrder returns the order of convergence, observed between errors with two numbers of steps,
+// false is returned, if any of errors is below gteFloor
+func observedOrder(prev, pt GTEPoint) (float64, bool) {
+	if prev.GTE <= gteFloor || pt.GTE <= gteFloor {
+		return 0, false
+	}
+	return math.Log(prev.GTE/pt.GTE) / math.Log(float64(pt.N)/float64(prev.N)), true
+}
+
+// LastX returns x of the last point, x0 if there are no points
+func LastX(pts []num.Point, x0 float64) float64 {
+	if len(pts) == 0 {
+		return x0
+	}
+	return pts[len(pts)-1].X
+}
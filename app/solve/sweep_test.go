@@ -0,0 +1,159 @@
+package solve
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFitOrder(t *testing.T) {
+	order, ok := fitOrder([]GTEPoint{{N: 10, GTE: 1e-2}, {N: 100, GTE: 1e-4}, {N: 1000, GTE: 1e-6}})
+	require.True(t, ok)
+	assert.InDelta(t, 2, order, 1e-9)
+
+	_, ok = fitOrder([]GTEPoint{{N: 10, GTE: 1e-2}, {N: 100, GTE: 0}})
+	assert.False(t, ok, "errors below the floor are skipped")
+	_, ok = fitOrder(nil)
+	assert.False(t, ok)
+}
+
+func TestSweepReq_Ns(t *testing.T) {
+	assert.Equal(t, []int{10, 11, 12}, SweepReq{N0: 10, N1: 12}.ns())
+	assert.Equal(t, []int{10, 20, 40, 80}, SweepReq{N0: 10, N1: 100, Geometric: true}.ns())
+	assert.Equal(t, []int{1, 2, 4, 8}, SweepReq{N0: 1, N1: 8, Geometric: true}.ns())
+	assert.Empty(t, SweepReq{N0: 0, N1: 8, Geometric: true}.ns())
+}
+
+func TestObservedOrder(t *testing.T) {
+	order, ok := observedOrder(GTEPoint{N: 10, GTE: 1.6e-3}, GTEPoint{N: 20, GTE: 1e-4})
+	require.True(t, ok)
+	assert.InDelta(t, 4, order, 1e-9)
+	_, ok = observedOrder(GTEPoint{N: 10, GTE: 1e-3}, GTEPoint{N: 20, GTE: 0})
+	assert.False(t, ok, "errors below the floor give no order")
+}
+
+func TestErrorsResp_Table(t *testing.T) {
+	order := 1.0
+	be := rest.NewErrorResponse(assert.AnError, "solution diverged", rest.ErrInternal)
+	resp := ErrorsResp{Lines: []GTELine{{Method: "euler",
+		Points:   []GTEPoint{{N: 20, H: 0.05, GTE: 0.02}, {N: 40, H: 0.025, GTE: 0.01, Order: &order}},
+		Failures: []GTEFailure{{N: 10, Error: be}, {N: 80, Error: be}}}}}
+	header, rows := resp.Table(0)
+	assert.Equal(t, []string{"method", "n", "h", "max_gte", "order"}, header)
+	assert.Equal(t, [][]string{
+		{"euler", "10", "-", "-", "-"},
+		{"euler", "20", "0.05", "2.000e-02", "-"},
+		{"euler", "40", "0.025", "1.000e-02", "1.000"},
+		{"euler", "80", "-", "-", "-"},
+	}, rows, "failed numbers of steps are in the order of n")
+}
+
+// canonicalSweep returns the sweep of the canonical problem over n from n0 to n1
+func canonicalSweep(t testing.TB, n0, n1, workers int, methods ...string) Sweep {
+	req := Presets["canonical"].Request()
+	req.Methods = methods
+	sw, err := SweepReq{Request: req, N0: n0, N1: n1}.Prepare(Limits{MaxSteps: 100000, MaxSweep: 100000})
+	require.NoError(t, err)
+	sw.Workers = workers
+	return sw
+}
+
+func TestSweep_Workers(t *testing.T) {
+	seq, err := canonicalSweep(t, 10, 60, 1, "euler", "rk4").Run(context.Background())
+	require.NoError(t, err)
+	for _, workers := range []int{0, 3, 8} {
+		par, err := canonicalSweep(t, 10, 60, workers, "euler", "rk4").Run(context.Background())
+		require.NoError(t, err)
+		require.Len(t, par.Lines, 2)
+		for i, line := range par.Lines {
+			require.Len(t, line.Points, 51)
+			for j, pt := range line.Points {
+				assert.Equal(t, 10+j, pt.N, "points are sorted by n")
+			}
+			expected := seq.Lines[i]
+			expected.Took, line.Took = "", ""
+			assert.Equal(t, expected, line, "workers=%d", workers)
+		}
+	}
+}
+
+func TestSweep_Cancel(t *testing.T) {
+	sw := canonicalSweep(t, 10, 5000, 2, "euler", "ieuler", "rk4")
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	st := time.Now()
+	resp, err := sw.Run(ctx)
+	// the whole sweep solves more than 10^7 steps by each method
+	assert.Less(t, time.Since(st).Seconds(), 1.0, "the sweep stops promptly")
+	require.Equal(t, context.Canceled, err)
+	require.Len(t, resp.Lines, 3)
+	for _, line := range resp.Lines {
+		require.NotEmpty(t, line.Points, "completed solutions of %s are returned", line.Method)
+		assert.Less(t, len(line.Points), 4991)
+		// solutions, that are in flight at the moment of cancellation, are lost, the rest are in the order of n
+		assert.Equal(t, 10, line.Points[0].N)
+		for j := 1; j < len(line.Points); j++ {
+			assert.Greater(t, line.Points[j].N, line.Points[j-1].N)
+		}
+		assert.Nil(t, line.Error, "the line of the incomplete sweep is not failed")
+	}
+}
+
+func BenchmarkSweep(b *testing.B) {
+	for _, bb := range []struct {
+		name    string
+		workers int
+	}{{"sequential", 1}, {"parallel", 0}} {
+		b.Run(bb.name, func(b *testing.B) {
+			sw := canonicalSweep(b, 10, 200, bb.workers, "euler", "ieuler", "rk4")
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := sw.Run(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestSweep_ExactMemo(t *testing.T) {
+	cached, err := canonicalSweep(t, 10, 80, 0, "euler", "ieuler", "rk4").Run(context.Background())
+	require.NoError(t, err)
+	sw := canonicalSweep(t, 10, 80, 0, "euler", "ieuler", "rk4")
+	sw.memo = 0
+	uncached, err := sw.Run(context.Background())
+	require.NoError(t, err)
+
+	cached.Took, uncached.Took = "", ""
+	for i := range cached.Lines {
+		cached.Lines[i].Took, uncached.Lines[i].Took = "", ""
+	}
+	assert.Equal(t, uncached, cached, "cached values are the same bit for bit")
+}
+
+func BenchmarkSweep_ExactMemo(b *testing.B) {
+	for _, bb := range []struct {
+		name string
+		memo int
+	}{{"uncached", 0}, {"cached", exactMemoSize}} {
+		b.Run(bb.name, func(b *testing.B) {
+			sw := canonicalSweep(b, 10, 200, 1, "euler", "ieuler", "rk4")
+			sw.memo = bb.memo
+			evals := &solver.CountingFunc{F: sw.exact.F}
+			sw.exact = &solver.Exact{F: evals.Eval, C: sw.exact.C}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := sw.Run(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(evals.Calls())/float64(b.N), "exact_evals/op")
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package solve
+
+import (
+	"fmt"
+)
+
+// TimeoutError is returned, when the deadline of the request is exceeded during the solve
+type TimeoutError struct {
+	Method   string
+	XReached float64 // the last x, the solution is calculated for
+}
+
+// Error returns the method and the x, it timed out at
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("solve with %s timed out at x=%.4f", e.Method, e.XReached)
+}
@@ -0,0 +1,68 @@
+package solve
+
+import (
+	"github.com/Semior001/decompract/app/num/interp"
+	"github.com/Semior001/decompract/app/rest"
+	"github.com/pkg/errors"
+)
+
+// ValueLine contains values of the solution by the method at each x, the value, that is not finite, is null
+type ValueLine struct {
+	Method string              `json:"method"`
+	Name   string              `json:"name"`
+	Y      []*float64          `json:"y,omitempty"`
+	Error  *rest.ErrorResponse `json:"error,omitempty"` // describes why the method failed
+}
+
+// ValueOf evaluates the solution of the line at each x, lines of methods are interpolated by the kind,
+// the exact solution is evaluated directly, the failure of the interpolation fails the line
+func (p Prepared) ValueOf(line Line, xs []float64, kind string) ValueLine {
+	res := ValueLine{Method: line.Method, Name: line.Name, Error: line.Error}
+	if line.Error != nil {
+		return res
+	}
+
+	at, err := p.interpolate(line, kind)
+	if err != nil {
+		er := rest.NewErrorResponse(errors.Wrapf(err, "failed to interpolate %s", line.Method),
+			"the solution is not interpolated", rest.ErrInternal)
+		res.Error = &er
+		return res
+	}
+	res.Y = make([]*float64, len(xs))
+	for i, x := range xs {
+		y, err := at(x)
+		if err != nil {
+			er := rest.NewErrorResponse(errors.Wrapf(err, "failed to evaluate %s at x=%v", line.Method, x),
+				"the solution is not evaluated", rest.ErrInternal)
+			return ValueLine{Method: line.Method, Name: line.Name, Error: &er}
+		}
+		if IsFinite(y) {
+			res.Y[i] = &y
+		}
+	}
+	return res
+}
+
+// interpolate returns the function of the solution of the line, the exact solution is its formula
+func (p Prepared) interpolate(line Line, kind string) (interp.Func, error) {
+	if line.Method == ExactMethod && p.exactSolver != nil {
+		c, err := p.exactSolver.Constant(p.Req.X0, p.Req.Y0)
+		if err != nil {
+			return nil, err
+		}
+		return func(x float64) (float64, error) { return p.exactSolver.F(x, c) }, nil
+	}
+	if kind != interp.KindHermite {
+		return interp.AsFunc(line.Points, kind)
+	}
+	slopes := make([]float64, len(line.Points))
+	for i, pt := range line.Points {
+		v, err := p.f(pt.X, pt.Y)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to calculate the slope at x=%v", pt.X)
+		}
+		slopes[i] = v
+	}
+	return interp.HermiteFunc(line.Points, slopes)
+}
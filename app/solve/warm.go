@@ -0,0 +1,94 @@
+package solve
+
+import (
+	"sync"
+
+	"github.com/Semior001/decompract/app/num/solver"
+)
+
+// WarmRun resumes methods of the problem from checkpoints of the previous solve and keeps checkpoints
+// of the new one, methods are solved concurrently
+type WarmRun struct {
+	node int                          // the node, lines start at, zero if the problem is solved from x0
+	from map[string]solver.Checkpoint // checkpoints at the node, nil if the problem is solved from x0
+	lock sync.Mutex
+	Last map[string]solver.Checkpoint // the last checkpoints of solutions, kept, once methods succeed
+	Next map[string]solver.Checkpoint
+}
+
+// WarmKey returns the hash of the request without x_end, the request of the same key with the longer
+// interval continues the solution of the shorter one
+func (req Request) WarmKey(l Limits) string {
+	req.XEnd = 0
+	return req.CacheKey(l)
+}
+
+// Resume sets checkpoints to resume the problem from, if each resumable method has them at the same node
+// and lines of the longer interval are not downsampled, otherwise the problem is solved from x0
+func (wr *WarmRun) Resume(p Prepared, checkpoints map[string]solver.Checkpoint) {
+	n, err := solver.StepsCount(p.Step, p.Req.X0, p.Req.XEnd)
+	if err != nil || p.maxPoints > 0 && n > p.maxPoints {
+		return
+	}
+	node := -1
+	for i, slvr := range p.Solvers {
+		if _, ok := slvr.(solver.Resumer); !ok {
+			continue
+		}
+		cp, ok := checkpoints[p.Methods[i]]
+		if !ok || node >= 0 && cp.Node != node {
+			return
+		}
+		node = cp.Node
+	}
+	if node <= 0 {
+		return // nothing to resume, or the previous interval had no steps
+	}
+	wr.node, wr.from = node, checkpoints
+}
+
+// Start returns the node, lines start at
+func (wr *WarmRun) Start() int {
+	if wr == nil {
+		return 0
+	}
+	return wr.node
+}
+
+// solve solves the problem with the solver from the node of the run, the resumer continues from
+// its checkpoint and checkpoints its solution, the rest of solvers solve from x0 and points before the node
+// are dropped
+func (wr *WarmRun) solve(slvr solver.Interface, method string, step, x0, y0, xEnd float64, d solver.Drawer) error {
+	r, resumable := slvr.(solver.Resumer)
+	if wr != nil && resumable {
+		var last solver.Checkpoint
+		d = solver.WithCheckpoints(d, x0, step, 1, func(cp solver.Checkpoint) error {
+			last = cp
+			return nil
+		})
+		defer func() {
+			wr.lock.Lock()
+			defer wr.lock.Unlock()
+			wr.Last[method] = last
+		}()
+	}
+	if wr.Start() == 0 {
+		return slvr.Solve(step, x0, y0, xEnd, d)
+	}
+	if resumable {
+		return r.Resume(wr.from[method], xEnd, d)
+	}
+	return slvr.Solve(step, x0, y0, xEnd, solver.WithSkip(d, wr.node))
+}
+
+// keep keeps the last checkpoint of the solution by the resumable solver, that succeeded
+func (wr *WarmRun) keep(method string) {
+	if wr == nil {
+		return
+	}
+	wr.lock.Lock()
+	defer wr.lock.Unlock()
+	if cp, ok := wr.Last[method]; ok && cp.Node > 0 {
+		wr.Next[method] = cp
+	}
+}
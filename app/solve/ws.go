@@ -0,0 +1,65 @@
+package solve
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/Semior001/decompract/app/num"
+	"github.com/Semior001/decompract/app/num/solver"
+	"github.com/pkg/errors"
+)
+
+// BatchSize is the number of points in the single batch of Batches
+const BatchSize = 100
+
+// Batches solves the problem with all requested methods in order and passes the points
+// to the send function in batches along with the percent of the request, solved up to the last point of the batch,
+// solving stops as soon as the context is done
+func (p Prepared) Batches(ctx context.Context,
+	send func(method string, pts []num.Point, progress float64) error) (StreamSummary, error) {
+	st := time.Now()
+	res := StreamSummary{}
+	total := 0
+	_ = p.Each(func(string, solver.Interface) error { total++; return nil })
+	// progress is the share of solved methods and of the interval, solved by the current one
+	progress := func(pt num.Point) float64 {
+		share := 1.0
+		if width := p.Req.XEnd - p.Req.X0; width > 0 {
+			share = math.Min(math.Max((pt.X-p.Req.X0)/width, 0), 1)
+		}
+		return 100 * (float64(len(res.Lines)) + share) / float64(total)
+	}
+	err := p.Each(func(method string, slvr solver.Interface) error {
+		lst := time.Now()
+		cnt := 0
+		batch := make([]num.Point, 0, BatchSize)
+		d := p.thin(method, solver.DrawerFunc(func(pt num.Point) error {
+			cnt++
+			if batch = append(batch, pt); len(batch) < BatchSize {
+				return nil
+			}
+			err := send(method, batch, progress(pt))
+			batch = make([]num.Point, 0, BatchSize)
+			return err
+		}))
+		if err := slvr.Solve(p.StepOf(method), p.Req.X0, p.Req.Y0, p.Req.XEnd, WithRequest(ctx, d)); err != nil {
+			return errors.Wrapf(err, "failed to solve with %s", method)
+		}
+		if err := d.flush(); err != nil {
+			return err
+		}
+		if len(batch) > 0 {
+			if err := send(method, batch, progress(batch[len(batch)-1])); err != nil {
+				return err
+			}
+		}
+		res.Lines = append(res.Lines, LineSummary{Method: method, Name: slvr.Name(), Points: cnt, Took: time.Since(lst).String()})
+		return nil
+	})
+	if err != nil {
+		return StreamSummary{}, err
+	}
+	res.Took = time.Since(st).String()
+	return res, nil
+}
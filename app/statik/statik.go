@@ -1,12 +0,0 @@
-// Code generated by statik. DO NOT EDIT.
-
-package statik
-
-import (
-	"github.com/rakyll/statik/fs"
-)
-
-func init() {
-	data := "PK\x03\x04\x14\x00\x08\x00\x08\x00AbfQ\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00	\x00	\x00blank.gifUT\x05\x00\x01\xfb>\xa5_\x14\xc9\xb1\x11@P\x0c\x00\xd0\x97\x9cE\xf4\x06\xe0\xf4\x9c\xde\x02F\xb2Q\xd6R\xc5\xfd\xfa\x9d\xd7\xb1nO\x08o\xebnT\x15\xe6o\n\xd2\x02\xc3e\xdea\xff\x03\x00\x00\xff\xffPK\x07\x08\xde7\xe4\xf05\x00\x00\x001\x00\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00CbfQ\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\n\x00	\x00bottom.pngUT\x05\x00\x01\xfe>\xa5_\x00\xaf\x01P\xfe\x89PNG\x0d\n\x1a\n\x00\x00\x00\x0dIHDR\x00\x00\x03\x02\x00\x00\x00\n\x08\x06\x00\x00\x00\x07\x07\xe9H\x00\x00\x00\x04gAMA\x00\x00\xd6\xd8\xd4OX2\x00\x00\x00\x19tEXtSoftware\x00Adobe ImageReadyq\xc9e<\x00\x00\x01AIDATx\xda\xec\xddQO\x830\x18\x05\xd02\xeb\xfc\xff?W\x19TH\xda\xe4\xb3\x96e>\x0cM<'\xb9\x01\x06|\xcf\xbd\x1bdS)%M\x9b\x94\xd2\x9e\xcb\x96\x97\x9a\xbc\xe5\xb5\xe6\xba\xe5\xadn\xaf\xe1\\\xbb\xf6R\xef\xdf\x03\x00\x00<G\xa9Y\xb7,5\xf3\x96\xdb\x96\x8f\x9a\xf7\xba\x9d\xc3\xb9v\xed~_\xd9\xe5\xc1\xe0\xb5.\xe8\x97\xc1\xe2~\xad\x83rW\x02.\xf5\xbc\"\x00\x00\x00\xcf-\x02m]\x1e\xcb\xc0\xadf\xae%\xe0\x16\n@\xbb\xb6\xc4A\xb9\x1b8*\x03\xb1y,\xa1\x00\xc4\x120)\x02\x00\x00pZ\x11(\x832\x10\x0b\xc1\xa8\x04\xc4\xa4|0\xbc\x0d\xee\x8f\x97A\x01\xf0k\x00\x00\x00\x9c_\x06\xd6;\x85`T\x02\xbe\xc8w\x86\xa6\xae5\xb4\xe1\xb1\x04\xc4\x00\x00\x00\xe7\x95\x81~\xad>J9*\x03\xb9\x1b6\x85\xfd\xf6xP\xe9\x8e\xfb\x17\x83c	P\x08\x00\x00\xe0\xb9\x05\xa0\xdf/\x83\x05\x7f\xff8\xd0\xb7\xfb\xf3\x03\xc3\xa7\xb0=z\x89X	\x00\x00\x80\xf3\xcb@\xea\x16\xfbeP\x00\xcah\xc8\xd1\xa3A\xd3As\xf0\xed?\x00\x00\xfc\xddb\xf0\xc8\xfe\xb0\x08\xa4{\xad\xe1\xe0s\x85\x00\x00\x00~\xb7\x00\xfc\xf8\xfc\xb4\xff\xa1\x18\x00\x00\xf0\xbf|\n0\x00\x94%\x99\x85X\x0c\x06\x0c\x00\x00\x00\x00IEND\xaeB`\x82\x01\x00\x00\xff\xffPK\x07\x08kI\xa2e\xb9\x01\x00\x00\xaf\x01\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00CbfQ\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x0c\x00	\x00iepngfix.htcUT\x05\x00\x01\xfe>\xa5_\xc4U\xe1o\xdb\xb6\x13\xfdn\xc0\xff\xc3\xabQD\xd2\xcf.\xe5\x04\xcdo@\x1cw\x08\xba$0\x96\xa4E\x9ba\x03\x92\x0c\xa0\xa9\x93\xc4E\"\x05\x92\xb2\x9d\xd5\xfd\xdf\x07Rr\x13w\xfd\xbe\x0f\x06\xac\xbb\xe3\xe3\xbbw\xef\xa4\xd3\xa6]VR\x9c\x08]7Z\x91r\xef\x86\x83]\x8c;\xc7E	Z\x91r\xf3\x91V\x8d\xd1\x0d\x19\xf7$J\xae\n\x1aA\xab>\x95\xe9\x0b\xb9\x89\x93\x11\xd2w\xc3\xc1ppj\x85\x91\x8d\x83{jh>r\xb4q\xe9_|\xc5\xbb\xe8(\x94\xa4)\x16\xe7\xc7\xecx\x8c\x8f7\x978\xab\x9a\x92\xe3Bn\xb0:d\xd3O\xef\xdf\x86\x82X$8\x9aN\xdf\xbe9\x9aN\x8fq\xa6\x8a\xd6\xe2\xb65j\xd9V\x15J\xe7\x9a\x934]\xaf\xd7\xcc\xad\xa5*\xa9\x92\x1b&t\xdd\x83\xdf\x96\xd2BZTR\x90\xb2\x94\xa1U\x19\x19\xb8\x92\xf0\xfe\xfd\x9b\xcb\x9b\xdfpu\xf9\xf1j\x82\x15\x19+\xb5\xc2\x11;\x846\xa8\xb8#\xc3\x02\xc2\x856\xc8\xc8qY\xd9	,\xd1\xc9\xeeJa\x88;\xb9\"\xa1\xebZ+\xcb\xb4)\xd2\xfe\x1a\x9bz\xd4\xf4\x88\x1d\xa6\x9e\xc73\x93\xba\xb5\x0eK\x02G\xc3]	\xa7\xc1\xb1\xac\xb8z\x84\xacyA\x0c\xb7%w\x91\x05\xaf\xaa\xc0Qh\x95\xcb\xa25\xdcyrO\xba\x85\"\xca\xd8p s\xc4^V\x9dw\xe7\x17u\x81\xf9\x1c\x91o/\x97\x8a\xb2(\xc1\x8a\x9b\x17ID\xe1?+d\x1e\xcd:V\xbe \xf7\x99_\xfeX\xf8\xebo\x0dW6\xd7\xa6f\xd7R\x18mu\xeeX\x18I\xc8^i\x9e\x91\xe9\xce\xe6\xad\x12\x81R.+\x17\xdb	\xead8\xf82\x1c\xc0\xf3\xf212\xf6.\x7fH\x86\x03\xf8(\x9eC\x8c\x14_V\x94a\x0e\x8b\x9f\xe1LK8A\xce+K3_\xe9\x01l\x82\xb5t\xe5\x1e\x12\xbe\xc0\x1a\xe1O\xcd`\xe5\xdfR\x15\xd7\xe4J\xedqj|\x1d\x0e\xc2\x8f*K;\x04\xeb\x9e*b\x1d\x82\xef\xb11\xba\x90\xd9I4\xce\xc7Ql\x8d\x98\x8f\xa2\xb1\x1dG\xa3\xc9K4\x1f\xac\xc7\xd1(\xf1m~\xdd\xeb\xb4\xf7v\xd7f\x9a\xe2\xcc\xda\xb6&,\xce\x7f\xf2\xfe\xfa\xf0+\xeb\xbb\x7f\x95^\x7f^\x9c#>\xbeg\xc7\xdb\xff\xdf\xb3$e\x8e\xac\x8b\x15_\xc9\x82;mXk\xc9\x9c\x15\xa4\\\x82\xed\xd6\xf7\x1c\x87\xf5\xc1\xc1\x01^\xa5\xf1\x92\x8b\xc7\xc2\xe8Ve[k\xc4\xeet\xa8`\xbb\xc5\xbb\xe15%I\x02C\xae5*L\xa4\x9bv\xd1\x8dZ\xb4\xc6\x90r\x9f\x83\x04\xcf\x80a\x8c\xd8n{m\xbeKt0\xc1X\xbc\xf07\x04C-\xae/\xa3oc\xf4\xc98\xbdg\x8d*^\xa72\xe9\xa8y\x96\xbe\xa2+	5{\xf7\xafe\xe6\xca\x80\xc5[\xa7#\xdf\xe6^\xbe$Y\x94\xee\xb9 `\xa1\xe7\xd8\x1f\x86\xcesK\xee\xf7\xf04F\xd4l\xa2\xe0\x96\xde\x7fFL\x10Y\xc1+\x8a\x92.\xdeye\xe7\xfe\x10\xf3\x06y\xe1\x10#\x98T\x19m>\xe4\xf1\xae,\xc1)\xa6I\x87\x19p\xf6<\xd5\x89{p\xd0\xab\xfcj\x8eHiE\xfb\xea\x84\x1c\xab\xb9\x13e\x9c\xfe\xd9\x9a\xea.\x1eE\x0f\xe3\x98\xfd/\xa8\x96\xdc%\xfe\xd1\x8b\xf7B2?:\x8b9>Qq\xbei\xd8\xeb\xc3\xd9\x7f\xa0\xe4\x0f=\x81]\x93/\xd5\x9e \x12F7;\xa9\xc3+\x1c\x95T\x8f\xc8\xe5\x86u\x85\xda \xf6m)\xcc1\x9dA\xe1\x14\xa2\x94Uv\xa33\xb2\xac\"U\xb8r\x065\x1e\xf7\x1cC\xaf\xdf\n\xee\xd4\x03\x0b|\x12\xfc \xc8\x1ame\xd8\xc99\"CUx\x0bG\xdf\xcdxo\x88a\x93\xfb\x05\x0e6?M\xbb\x8f\x90\xff\xc8\xa5\xff\xfa\xf2\xfd\x13\x00\x00\xff\xffPK\x07\x08#{\xea}\x9a\x03\x00\x00\x0c\x07\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00o\x1agQ\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\n\x00	\x00index.htmlUT\x05\x00\x01B\x12\xa6_\xbc\x97\xcf\x93\xda6\x14\xc7\xcf\xf0W\xbc\xeaR\xd8\x022lH\x97\xd4\xf6!\xb0iw&M3-\x99&\xa7\x1da\x0b\xac\xac,9\xd63\xd8\xff}G\xb2\xcd\x9a\xa6\x07h&\x9c,\xc9\xef\xfb}\xfa\xe8\x87-\xf9?\xac\xfeX\xae?\xbd\xbf\x87\x04S	\xef?\xbc~\xfb\xb0\x042\xa6\xf4\xef\xdb%\xa5\xab\xf5\n>\xfe\xb6\xfe\xfd-L'\x1e\xacs\xa6\x8c@\xa1\x15\x93\x94\xde\xbf#@\x12\xc4\xec\x15\xa5\x87\xc3ar\xb8\x9d\xe8|G\xd7\x7f\xd2\xd2zM\xad\xb8)\x8e\xb1\xa3\x9c\xc4\x18\x93\xb0\xef\xbb\x84e*\x95	\xfe\xc3f\xbaX,j\xb5\x8b\xe5,\x0e\xfb=?\xe5\xc8\xc0\x06\x8f\xf9\x97B\xec\x03\xb2\xd4\n\xb9\xc2\xf1\xba\xca8\x81\xa8\xae\x05\x04y\x89\xd4\x8a\x7f\x81(a\xb9\xe1\x18|X\xbf\x19\xdf\x11k\x82\x02%\x0fW\xf7K\x9d\xbe\xcfY\x84>\xad[\xfa=_\n\xf5\x049\x97\x011XIn\x12\xce\x91\x00V\x19o<#c\x08$9\xdf\x06d/\xf8a\xe2\xea)\x8f\x05\x0b\x08\x93\xd2\xf9\x9b(\x17\x19vU\x9f\xd9\x9e\xd5\xad\x04L\x1e5\xda\xcf\x86\x84>\xad\xdb\xc3~\xdf\xa75\xa5\xbf\xd1q\x05\"\x0eH\xca\x84z\xb45\x02a\xbf\xdf\xf3E\xbas\xed\xa8\xb3\xc6\x07u6\xc9\xd4\x8e\x00\x93\x18\x10\x97=\x16{\x17\xb4\xd5y\xfah\x07\x84	\xc5sb\x0dz~2\x0d}v\x82\xceB\x9f&\xd3\xd0\xbe\xb4\x8ag\xe9|\xee\xdd\x11\x88$3& ,\xcb\x94\xc0\\\x13\x80\x94c\xa2\xe3\x80d\xda \x01\x16\xd9I\x0d\x08\xb5\xb9{u\xf6F\xe3\\b^\xf3	\xad\xea\x88\x9e\x9f\xccN:\x90\xcc\x9a\xf6,\\\x89\xed\x96\xe7\\\xa1`\x12\xee\xbf\x14\xcc\xca\x0c,u\x9a\x15\xc8\xea\xd5\x03N&\xa2\"\x1d\xc1'.M\xc2\x10V\x85ybRT|?\x82\xd7\xd3\xc5\xd8{\xe1\xd3\xec\xe8\xfa\x00\x83\xaf\x03\x87\x90\xb0=\x87\x9dF`\n\xee0\x81=\xcb\x05S8\x82C\"\xa2\x04\x84y\xd55\xd9\x0e\xcaQ5\x84\x00\xaa\x9b\xea\x86\x97\xd9\xa0\x1c\xc2\x18f7U7\xa8\x1a\x94\xa3\xc8\x06\xd9\x80q9\x04\n\x83\xa8\x8d\xfe	\xa6\xc3n\xf0rPz#\xa8<\x1b?\xa8\x05\x9e\xf5\xb4-\x14\x06\x95\x077\xce\xa7\xf4\x86'\xba\xca7\xc5&\xf4|j\x1f\xc1\xb4\xfb\xaa<y5>\x19\x85\x8f\x10\xc0\xb1\xc1\xa7\xb1\xd8\xd7\xa5BB\x13#\x85\x9b|)\x1e\x7f&M[\xcf\x97l\xc3e;\xa3\xdd\xc9\x84\xad\xce\x03\xb2-+\xd2\x8c\xcd\x08\x984\x1a\x9e\x94>(`\x06\xaa\x1f\xc1\xa7N\xdez\xb59mY\xa8\xac@\x97\x8fK\x9er\x856\xa9b)\xaf=\xdb\x8c\xcdK\xb0\xbb\xc8m\xb3\"\xednG\x02)+%W;L\x022\x9b\xcf	\xec\x99,x@\x08m\x93\x1eI{>\x95\xe2+\xd4\xbb\xb3Q\xab2\"\xcd\x0c\xff\x1b\x15\x13\x0e\xbcd\x11\x82\xd1\xb2\xb0\x9a\xcb\xd0\xefZt\x9b\xe3j\xe8\x8b\xb3\xd1\xdb~.H\xbdj+o8\xb2\xdfZ\x83'\x1b\x06\x14\xe71\x8f\xad\xe6\x9b\x86c\xd1\x0e\xc7\x15\x07cz\xf6`\x94\x1e	\x1f\x94p\xdf\xa8\x12\x06'{nx\x19\xe8\xb4\x05-\xbd\xefGz\x829;\x1b\xb3\xea`V0\xa8\xbe\x01sv\\\xde\xd7\xc2\xbc=\x1b\xb3|\xe4*&\xe1\xbd\x8a\x85\xda\xd9\xf9\xfcx!\xdc\xedq\x0e\x9d\xd3u\xf8^\x9c\xcd\xa7H\xf8\xaeH7<\x07\xbd\x05\x83<30xw!\xe2\x8b\x16Q]	o~>^*,\xa1[\x9c\xa9P\xf5\xf2\xbc\x8cn~\xa4\xb3^\xd7\x01|y\x01 +\x8f\x80\xac\xfc?\x80/\x9f\x01Y\xf9\xfd\x00\xfb\xc7Oj\x93aS jeH\x1b_\xf7\xacN\x91\x888\xe6\xea\xf8\xc7\xb7'E\x11\x1fs\xd4GO\xdaXv\x99\x0c\xdb\xf37:O\xc9i\x92\xc7\xba\xcb\xb5\xb7)6\xa9\xc0\xd6\xbb\xad5\xd6\x7f5U\xda\xed\xb9+\x14n8}j;\xe3J\xcf\x07i\x8d\xee\x00\xdd\xeb\xf5~\xe5\x8a\xe7\x0cy\x0c\x9b\n|\xd6\xdc\x04:\xb7\x97,\xc9\xac\x81\xbd\xc2\x900\xb3]\xb5G\xec	\xac\x13\xa6\x9e\xa0\xd2\xc5\x08f\xde\xccs\xffHw\x01r\x87\xee]m\xabs3\xb21\xc0\xa4\xd4\x07\x1eC\xca\x015\xc4\x1a\x94F\xd8\x14B\xc6\xee\xac\xf1\xe1\x01^\x0d\xfb\xcfSp|\xb6\x17\x84\x8dF\xd4isG\xa8+\xa7\xd7\x04\x9f\xdak\x85}\xdaN\x84\xff\x04\x00\x00\xff\xffPK\x07\x08LF\x14QN\x04\x00\x00\x06\x0e\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\x16\x1cgQ\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00	\x00	\x00plot.htmlUT\x05\x00\x01]\x15\xa6_\xac\x94ok\xdb:\x14\xc6\xdf\xe7S\x9c\xabK\xa1\x17\xe2\xc8\xb9\x0d\xa57\x96\x0dwi:\x06[WX\x06\xcdK\xc5>\xb6\xb5\xc9\xb2'\x9f\xb8\xf6B\xbe\xfb\xf0\x9f\x14w\x046\xb6\xe5\x8dtt\x8e~\xcf\x89\xf5H\xe2\xaf\xdb\xf7\xab\xcd\xf6a\x0d)e:\x98\x88\xd3\x802\n&\x00\x00\"C\x92`d\x86>\xab\x14>\x15\xb9%\x06an\x08\x0d\xf9\xecIE\x94\xfa\x11V*D\xa7\x0b\x18\x1foL\x89\n\x07\xbf\xecU\xe5\xb3U\xbf\xc9\xd94\x05\x8e\x10\x845\xf1V\xd7\x830\x95\xb6D\xf2?n\xee\x9c\x9bg\x10)\xd2\x18|\xc8\xf5\x9eTn\x04\xef\xe3\x89\xe0}\x97b\x97GM0\x11\x91\xaa\xa0\xa4Fc\x8ft\xa4V\x89YB\x88\x86\xd0z\x10\xe7\x86\x9cXfJ7K\xf8\xdf*\xa9\xa7PJS:%Z\x15\x0f\xf9R}\xc5%\xcco\x8a\xdac\x83z:?Q\x8b\xbcTm\x07K\xb0\xa8%\xa9\n=\x08s\x9d\xdb%\xfc\xbd\x88w\xbb\xe8\xda\x83L\xdaD\x19\x87\xf2b	\xee\xec_\xcc<\x16\xdc\xaeWy\xf6`eH\x82\xa7\xf3\x13\xf6\xeag\xb0\xd7\xdd\xef<v\x8b\xbaL%\xc1\xed\xbe\xfc,\xb5j\xb0\x9a\xc2\xab\xf9\x7f\x8e\xbb\x10<\xbd\x1ad\x8a \xbe\xac\xa7\xcd?\xe0\xc3\xe10\xbb\xab\x9b\xe3\xd1\x83\xe6\xb2\x9e\x86\xc3\xd2\xb6\x0e\xdb\xa5\xd5e-\xca\xfd.p\x05o\x87i3\x8e\x86\xd2U\xed6\xee\xf1(x\xf1\x0c\x7f\xb1\xa9\xab*\xac2\x14\x03\xbb\x98-b\x06\xb3G\xb7\x13\xfcQ\xd9\xb6+{<KX\x9b\xa8M\xde\xf7M\xdcw\xf3\x8e\x97)3\"\xce\xee\xdf)3J\xca\xfaeR\xd6\xe3\xd6%\xa4\x16c\x9fq\x16\xac[\x7f\x8049\xa5h!\x92$\x05\x97\xad\xbd\"U\x05\x13Ar\xa7\x11z\x9f\xb3\xb9\xeb^\xb0\xd3\xc1u\x06s\x06\x1f\xff\xba\xd1\xe0\x9c\x01\xce\x1d\xf8p\x1bl?\xe9\x83(\x10*K\xbek\xcf\x86>k\xff\xc7Re2A\xfe\xa9H\xbc\x9d,\xf1z1=\x1cf\xa7{T\xbe\xc9\x92\xe3\x91\x81\xd4\xe4\xb3\xf2\xb4\x08\x85\xce\x89\x05\x82S\xf4{2o7\xeb\xb1\x80&\xfcc\xe8\xd7/\xd1\xc9\x19\xb4\xe0\xedg\x12\xbc;\xbcv2\xbc\x11\xdd;\x13|\x0b\x00\x00\xff\xffPK\x07\x084\xe7\xb3\xaa4\x02\x00\x00\xf6\x04\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00CbfQ\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\n\x00	\x00shadow.gifUT\x05\x00\x01\xfe>\xa5_r\xf7t\xb3\xb0Ldg`bh`d8s\xe6\xcc\xff\xff\xff\x15\x7f\xb202002\xe80000\x80d\x18\x98X[\xf8\x17J\xb32X\x03\x02\x00\x00\xff\xffPK\x07\x08\xc3\x86\xc1\xa33\x00\x00\x00.\x00\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00CbfQ\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x07\x00	\x00top.pngUT\x05\x00\x01\xfe>\xa5_\x00\xa1\x01^\xfe\x89PNG\x0d\n\x1a\n\x00\x00\x00\x0dIHDR\x00\x00\x03\x02\x00\x00\x00\n\x08\x06\x00\x00\x00\x07\x07\xe9H\x00\x00\x00\x04gAMA\x00\x00\xd6\xd8\xd4OX2\x00\x00\x00\x19tEXtSoftware\x00Adobe ImageReadyq\xc9e<\x00\x00\x013IDATx\xda\xec\xdb\xebn\xc20\x0c\x06\xd0\xa4\x14\xf6\xfe\xaf;\xc6\xb2\x15\xb5\x93\xeb^\xb4!\x95M\xda9\x92I \xe5\xbf?\x1cjk\xad\x00\x00\x00\xffK?\xbc\xd4Z\xf7\x9e\xa9\x0f\x9e\x01\x00\x00\xc7j\x8f\x9c\x0d\xc3\x80\xfe\x1b\xcd}]\xd9W\x81\x00\x00\x00\xfeL\x00h?\x0d\x06\xfdFC\xbf\xb6\xc6\xda\x0b\x05\x00\x00\xc0\xf3\xc2@\\c\xd5t\x16\xdf\xcf\x82\xc0^\xf3\xdf\xa5u+\x14\x00\x00\x00\xcf\x0d\x01\xb1\xde\xd3\x9aC\xc0W\x18\xd8\x9b\x08\xc4\xe6\x7f\xa8S\xd8\xe7P \x0c\x00\x00\xc0\xef\x84\x80\xd8\xfc\x0fu\x1b{\xf3\x1c\n6'\x02%\x85\x80.\x85\x80>\x85\x81\xae,'\x04\x00\x00\xc0\xf1A\xa0\xa5\xe6?\x87\x80\x18\x06J\x99O\x08V\x83@\xbe\x0et\n!\xe0\x9c\x02\xc1\xa9,\xa7\x03\x00\x00\xc0\xf1A \xfe\xda\x7f\x1b\xeb-\x04\x80)\x0cL\xcf\xd7\xb0\x9f\x05\x81|\xc5'N\x02\xce+\x95'\x04\xd3w\x00\x00\x80c\xe5\x100\x05\x80\xebX55\xfe\xf9\x0f\xc4\xf7\xcf\xd7&\x02\xf9:\xd0\xd0\xf8_>\xebe\\/e>!\x88a\xc0T\x00\x00\x00\x8e\xd3\xcar\x12p\x1d\xc3\xc0\xeb\xd8\x9bo\x85\x80\xa9\xee>\x04\x18\x00gz`\x82$L\n\x02\x00\x00\x00\x00IEND\xaeB`\x82\x01\x00\x00\xff\xffPK\x07\x08Q\xf1\xa7\xdc\xab\x01\x00\x00\xa1\x01\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00CbfQ\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x08\x00	\x00view.cssUT\x05\x00\x01\xfe>\xa5_\xdcZ_o\xe3\xb8\x11\x7f\x8e>\x05\xbbA\x80^\xba\xd6Zv\xec\xc4\xce\xd3\xdd\xee\xe6z\xc0\x16(pE_\x8a\"\xa0E\xda\"\"\x89*E'\xce\x19\xfb\xdd\x0b\xfe\xd5\x90\xa2\xbd\xe9\xb5\x87\xa2\xb5os\x91D\xce\x0c\x7f\xf3\x7f\x94\x0d'\xaf\xd91\xbb\xd8\xe0\xf2i'\xf8\xbe%\xeb\xcb\xad\xfa\xdcg\x17[\xde\xca\xc9\x167\xac~]\xbf\xfb\xb2/\x19\xc1\xe8G\x81[B\xdf\xbdG\x7f\xc1\x15o\xf0{\xf4\xbd`\xb8~\x8f\xfeJ\x05\xc1-~\x8fz\xdc\xf6\x93\x9e\n\xe6)\xf4\xec\x17\xba\xee\x1b\\\xd7\xf7\xd9E\x83\xc5\x8e\xb5\xeb\xbb\xee\x80\xa6\xa8Xv\x87\xfb\xecB\xd2\x83\x9c\xe0\x9a\xed\xdauI[I\xc5}\xf65\xcb.\xb7\\4\x8f%o%f-\x15	!\xef\xb3\x8b\x0d\x17\x84\x8au\xd1\x1dP\xcfkF\xd0eY\x96\x03\x9b)\xc2{\xc9C\x165\xdd\xca\xfb\xec\xe2\x85\x11Y\xad\x977S%\x82b'y\xa7x\x10\xd6w5~]oj^>\xddg\x17\x15e\xbbJ\xae\x0b\xbd\xce\xd1UW\x9a4\x9a\x0e\xa4\x16\x9e\xd4\x96si$\x0e\xb8\\\x945\xc5b\xbd\xe1\xb2R\x17\xbc\xe6b}\xb9\xd2\x9f4\n\xd1\xf6\x0e\x13\xc2\xda\xddd\xc3\xa5\xe4\xcd\x1a\x15\x0b}{\x00\x19\xdd-\xae\xa0\x04\x08\x1fO\xf0!\xb4\xe4\x02K\xc6\xdb5jyK=\x94\x03\xf1\xee\x80\x08\x97\x92\x12\xe4\xf7*\xca\xe6\xf9\x9b\x91\xf2\x1a\x88AR\xda\xcdq\xd7\xb5L\n\xae\xc8\xd9\x0d3\x05\xad\xfe1\x1d\x8e\xbc\x9e\xa2\xa9\xbe\xa9\xb7~\xb8\xbe\xbe\xbeF_\xf8\x8e\xa3\x9fi\xa9\x0e\x81\x90\xbau\xfd!\xab\x8a\xd0N&\xf6\xf4\x84\xaa/\x10K\xfd\xca\xda\x89\x959`\x95\xc0\xc8B\xa4o\xb3\x96\xd0V\xae'w\xd3\xa99\xa9\x12\xa9*\x10V\x9c\xcf\xa02\xbd\nyZ\xa5\xf2g*\xb65\x7fYW\x8c\x10\xda\xea\x03f\xac\xd9\xe9s\xd0\n?3.\xd6{Q\xff\xbe\xec\xfb\x0f\x8cv\xedn\xcb\x0ey%\xcb\xef\x06\xf37\xe2\xa9\x8d\x06\x9a\x07.\x1a\x0f\x8d\x01&\x80\x1a:v\xe0\xd7\xbf\xd6\xad\xbff\x03\x03T3`\xf9\xc5\x95\xd76\xda\xd7\x9e\xb9\xdel1\xa9Y/'\xbd|\xad\xe9D\xbev\xd4\x81\x0d4\x05Uc\xe8\x9a\xad\x8e\xb0\xe1\x18#\x9f p\xd3\x1d\xd0B\xd9Ww@+\xe3S\xbcgZ\xc5\x82\xd6X\xb2g\n\xa9\xae\xf1\xd6\xfaq\xe4\xb9\xadT&\xf0.\x7fw\x7fR\xe1\x8a\xed3\xeb\xd9\x86\xd5L\xbeB\xed\xe6\x9b\xbd\x94\xbc\xed\x7f[\xea#\xbaix&\x92w\xd6e\xbff\xd95\xaadS#\x00\xaa3\xdf+\xf8\x1c\xf2H.\xb0\x04\x10a\xcfP3\xac\xadYK'V\x82\x01g\xb7\xce:\xeb\xcd\xcd\x0d\x0c 7:W\xc4\xd1\xe0\x0e\xc4\x11E\xa2\xefp;\xa2\xb1\xad9\x96.\xe2\xff\x8b\x04	{\xce\xd5\xce\xf1\x01b\xc2\xc6$o\xee\xaeF\xfb\x85\xc2\xe6\x0c\x01\xfd\xfc,\x05\xc5\x02\xe5\x0d%l\xdf\x0cn\x15\x9b\xff\xc0\xec\xcc\xda\\\x9bCl\x17!\x11T\xe3\x0d\xad\x136	@\x8d\x0diph\xe3RZ\xc7\xd6,VA.\x009L\x99\xdd<\xa1\xc2\xff\xaa\x009\xc1\x92v\xac|\xb2n\xb9\x17=\x17\xeb\x8e3\x95\x8e\xd1\xefX\xd3q!q+c\x03p>\xb0\x84\xcc\xf2\x826:\xd8D\xa66\xc4\xb0\xa5e\x9f\xebJ\x87\xd0\xbe\x14\xacS\xc1H\xc7\xfe \x1d\xc3ll*\x9c\xa4o\x87\x0e6X\xfc\x14\x15\xb4I\xf3\xfa[Y\xe3\xbe\xff{\"\x80\xa6V\xa3j6\xe8\xc6\x9e\x1e\x04\xf4\xa5\x0e\xe8\xfa\xc6\x8b\xcbr\xd3P\x90\xf9\xa9C\xa3.\xcc\x0e\xab\xc5U\xa4\xcbbnr(8\xd5\x0cj\xb0\x12\xf0\x14>)Z\xed\xe6\xbdI\x88\x8f\x1bA\xf1\x13\x80XG\xc0\x11\xbe <\xaeR\xd5\x17\xb4%\x05D\xb4J\x13\xd5g\xb5\xda^\xdd^\x05\x164$E%\xdb\x96\x89^F2\xa9\x03\x84F\x07d\x9a\x86O \xdbi\x92Ox|T\xcd\xa3\\\\\x9cP\xddy\x05\x04\xf8G,\"}\xea\xda4\xd4^X\xd0\xfd`\x92\x8a-XX\xdb\xed\xa5M4\x8f\xaa\xf0R\xd4|\xb1\xa4s_M\x83\x10~\x0b\xd06E'H\x87H\xd3\x8b\xce<\x03\xc7\x99\xe8\xf0\xb9^\x04\"\xfd\xa46\xf5\x08\xb7\x04}Qq\xc9	\xa7\x83T\x9e\xf4XW\xbe\xd8\x885\x9b\xcd\xceG\xacE\x0c\xfb\xed\x18\xf6\x85\x96\x13&\xab\xc2\x8a\xa9Bf\xde\xbf6\x1b\x1e\xd7V\xc5	\xf7\xf9\x9aYh\x1d\xa6QS\x85T\xad\x99\xe7\x1f\xcc\x7f\xac\xc1;\xda\x7f\xe8+L\xf8K\xbec\xdb\xef\x90\xa0\x1d\xc5rr@\x92w\xa3\xb6\x014b\x84\x90\xe1\xb1\xf6\x10\xd8\xa5\xcd\xd5wxn\xc0?\xb3\xc09\xa9}|[\xaa\xef\x00\xf3|>\xbfO\x94\x96\x89\x12pf:\x0b\x0f\xc3\x96\xd5\x14\xd4\x0d\xbf\x86\x90B\x12\x0b\x8as\xf7\xcb\xff#\xac\xff\xa19\xc0\x18O\x1b\x1eW\xc66{Z\xd3R\xe6\xe6\x7fo\xd6K\x11\xb5\x8b\x85O\xbb\xff\xd3j\xd0\xad\xa0\xb6\xd2r/\x04mK=\xaa\x01\x83\x02[?zc.+Z>m\xf8!\x91\xcd}\x912\x1f\xd5HE~\xa3\xaa\x03\x07\xe6\xd2\x80\x87`\xf6*\\\xda6|\x04&\x8c\xffvLLx-+\xceJ:\xaa\xe9O\xc7R\xdfO\x9e\xe4:)\xf2\xc5\x826\x9a\xf3l\x01\x13\xf6\xda4\x05\x0b\x98\xb1\xa7	\x93\x04\xe5R\"&\x14>&\\\xafuJ\x9f\x94\x15\xab\xc9\x1ftS\xf4\x0d:\xc3\xde\xbc\xc7[,\x18\x1a\xf9B\x98\xba\x92\xb5\xc1`\xa9@]\xbaK\x1f:\x82\xd9\"<X\xfa\xa9\xd9\x1aw\x13\x8b\x10\x94S\x8f\xcd\xe6\x1a\x8b\x1d\x1d\x1eF>\x1e=\xf5}\x8a\x0f\xa7^0\xab\xccE\xbe\xb0U\xac_2\xf0\xf7s\x96x\x89gcW\xcc\xdc\n\x93\xe2?\x0b\xc1\x85\xcb\xeb\x97T]=6\xb4\xef\xb1\xd9tv\xe4h+FA\xc9X\x01\xda\xec\x82\x02\x11V\x8c\x82Ec'\x1d\x02n\xba\x13\xe3\xd0\x08\xc0P\xccG\xc9d\x90\xc3>=L\xa7\xba\x88\x80\xf6\xb2\x00\xe1\xf2\x16\xd8:\xe8K\xc6\x94U\x85\x03\x08\x8f\xa8N\xe3z0\xbf\xb3\xe0&\x08\xa1^\n\xde\xee\xb2\xe4\x80\xee\xe1\xe1\xd3\xc3\xa7\x07\x9fy\x0c\xa40\xd5F\xbdZ\xae\xe9\x9f\xa3\x15\xba\xc7\xc9@\xfe\xf9\xfb\x8f?|\xfcx&V\xfb\x05\xf6\x98s\xef\xa7\x81(\xa0q\x85ZH\xd6\xe2\xdd }\xa2\xcb5g\x87(w\x87d\x8d\x08p_\x04u\xb8\xa0\xff\xd83A\xc9 \x8d\xa5\xa9\x9bV[\xa0\x8e\xe8y\x97\xf8q\xcf\x08UQ\xd4T\xbe\xdaC\xd0\x1f\xd9\xae\xaa\xf5\x84\xc1\xb8\x8a;|\xe5\xee\x1bvcml\xb7\xdb\xdb\x12\x00\x96\xef<\xf9\x91\x83-\xd479\xd6\xa7K\xf5\x8d'\x01\xa0\xbb0\xa1_'\xe2D\x1a\x185.v\xde\xe3M\xec\xae;\xe8~$\x1e\x0b\xe2M\xcf\xeb\xbd\xd4\xd3_\xd5[\xa5\xe7nn\x803S\\~\xd1#\xe2\x83\x92#}l\xe4\x03\x1bT\xf2\"\x98\xe8@\\c\xc8\x80\x00\xbe\x0f\x02C\xcbJuIo\xdc\x93\xb7\xfc\x11\x08\x16m\x82\xadtl\xc8\xd1N02\xf4\xfdnj\xdd\xd0\x87\xab\xa5\xe3\x06\xda\x1a!\xfay_\x96\xb4\xef\xd1\x9fL\x00qSl=4\xe8\xed\xb3\xc1\x7f\x90u \xab\xe0\xa0\x16D+\xfd2\xa1\x93\xa8\xb0\x03{\x10`\x91\x89\xb0\x99\x1fH8\xda\xd5\x0c\x1d3\x84\x10\x82\xa3\x0eu\x1dO;\xfc=\xebJ-\x17\x0d\xae\xcd}go\x9d\xd4\xfc\xe7\xf0\x80\x7f\xc6}\xff\xc2\x05\xb1'\xdb\xd7yg\xef\x1c\xc1^\x9d\x16\x96Zlp\xd3\xbd\xa3\x19\x1e\x8c\x8f\xa48y\x92\xa8\x9a\xd9\xd3\x84\x19\"\x16~\xc3k2\x12]\xbf\xe7\xf2\xbd\xfa@sh#\x0dT\x00\x99\xdb\xa8:A\x08\x19\xab\x98\xdf9\x91\xbf%\xb1\x0e\xa7\x9anT\xf0\x85\x9c\xe2:\x08!\x14\x8c`\x1c\xbb\xf1)U\x92\xaa\xd9c\x89;YV\xf8\x08wjs\xf41\x15.\xd33\xcac\x18I\x8dF\xd1\xe7fC	\xa1\xc4\xbc}Q:\xcdr\xaa\xee\xa1\xe8M\xe60*@\x9e\x80[)y\xf7\x1e\xb9\x0b\xa3f\x7f]\x15\xc0%\xc7[GL\x0c\xe2h\x98\xff\xfa\x85\\\xea\x05P\x05v\x96\x17\"`3\x8f%\xe4\xaa\x8f\x90Nn=\xe6\x98\xf2+\xb8~{\xe2\x85\x9f\xf1N\xd3\xbfi\xb8\x8d\x7f|\xc45m	\x16\xe6\xcd\xde\xe8\xf3!#\xec9/\xdd\xa2#\xf2\x11\x1b\xf979H\x0f\xbd\xdd\x12\x8975E\xc7,\x9c\xea\xdeg'^!\x0f6\x86\n]M\x1b\xd7A\xa6\x08\x02Y\x0b\x99\xba\xf0\xdfn\x92\x03a\xed\xd8\n\x1dQ\x96@U9h\xe6\xe3\xdb\xac;\xe8;\xe6\x13\xd1i\xf13:f\xa9$\x1b\x82SQLP\xae+I\xc5\x14\xba\x0b\xd2\xfeb\xa9\xa7t\x1c\x80\xe1\xde\xb4\x06pAYM\xd3\xe7Z\x9dX\x02\xf5S\xf0\x17%C\x88\xb1\x95\xda\x91\xd5\xf9?\xe9\xd4#\x92\x04\xbf\xb6\xb8\xa1\xfd\x98\xa6\xd2\x1b\x9c/\xbc\x8d\x9e\"\xa6i\x9d~a\xae-(PP\x12\xb8\x00\xa3\x14\xab\x17J\x9fhK\x147\xb7v\xb9\\\x9e@New\x1a\x1er\x12\xc0\xe5.\xf4\xb9\xbdt\xaeU\x1c\x11\xc4\xa5\xf2\xa2\x13\x04I1\xdb\x16+\x0f_Hr\xe6\\\xda\xbcj5Q\x14\xd0\xdfp\xf2\xaa\xf5\xa2\x88\xdb\xee\xcft\x0e\x8e\xbc\x1e\x9bB\xc8\xec\xa4#`\x01\xfe\xe9\xdc\x91`\x90sYQ\xd1\xf0VV\xe8\x08}Z\xd7\x8b\x8e\xdbf\xb3y\x03\x81\x9c{u\x0cP\x9a\x9d\xa30\x93\xbf\xb4\xe8xF\xda\xa0\xd9@ \xf6\x8c\xa3KB\xe1F:\xc1_\xac\xce%	}\x1c]><<\x14\xdf?|{\x9f\x91\xf3\xad[%\x01V\x06\x0d\x08\xfe\x0b\xc4W\x86\x17\xe4\xe7\xc1^F\x05\xe5\x98\xd9`\x81\xd0\xcc\x02i\x17\xb3\xd5\xac\x08Y\x8cP\x87f\x98\xe0b\xc6\x11\x94\xa4\xe3\x9e\xcb\x0fPIwww\xf7o;\xbf\x0b\\	\x0c\xb4\x91\xbf\x01\x84\xb7\xc5\x00\xb7Zr\xebV\x89\x93\xb8\xed\x06\xb3PO\x9fV\x9f\x1f>\xce\xd2\x06CX\xaf\x8cZ\x89\xe0H\xacV\xab8\xbd\x9a\xb5\xb4\xe9\xe4kI\xebZ\xc9\x00\x9a\x0e\x04\xffD!\xb9\xc9F\xfdD\x813,W%\x07\xca\xd5O\xbb\xfa\x9b\xe9h\xb1XF\x81/EQJ\xd6\x8d\xd2\x83\x8eA\xc1\xd6\xb8'\x07\xd3[h\x1e.\x05z\x0b\x99\x8f\x8d\xcf\xf0M\xc5\xec5\xba\xc4x\x9b\xb4\xbb\xe9\xcd\xf6TbMDq\xc3\"\x15\xc5\xd7\xe8\xf2\xf6\xb6\xfcF\xc0\x06ED\xc9\x9b\x0dW\xf1\xcc\xd7XC[\x1c\xe9Kc\xa1\xc11%\xa4\xfe\xd5\x16\x90z\x14\x9b8\x96\xce\x96g\xb3\xbdV\x05\x88\xdez4\xeb\xfalU\xe1\xa6%\xceu\x13\xf1\xfe\xd4\x83\xc9O\x9f\xb7\xec\x80\x8eI+:\x83l\x9a\x08<dr\xb9SvxT\x90\x99\x13\xf6\xe9\xf6:-\x9e29\xfd\xe7y\x89\x82$x\x9c*\xd3\x12a\xe2k\x96\xfd3\x00\x00\xff\xffPK\x07\x086\xe9[\xc4\x8d\n\x00\x00\x16*\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00CbfQ\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x07\x00	\x00view.jsUT\x05\x00\x01\xfe>\xa5_\x84U\xebr\xdb\xb6\x12~\x15\x1d\xcci\xb8\x88`\xd8\x90l]\xc2\xa0\x19\xdbq\x13\xa7\x8e\xd3\xdc\x9a\xa4\x92\xc2\x81HP\x84E\x91\x0c	YV\xb8|\xf7\x0e)\xc9\xb2;\xd3\xe9?\x00\xdf\xe2\xbb\xecr\x08}\xabb\x08\x97\x89oM\x9a@\xc6\x14\xf3\xd9\x9ci\x96\xd3R\xcb\xfbs\x9f\x96\xb9\xb6\xcb<\x01\xff\xb9z\xe18\xcf4d*/\xf4eb\xc1?T\x94\xd26\x80/\xfd_\x14\xfd\xb5{\xf2\xe2\xa3\xcdM2\xe3a\x9e.\xce#\x95\x9f\xa7\x81\x06\xbf\xdd\x19\xd2g>\xb7\xe9\x06\x85n\x8f\xd2\xca5!\xfc\xcfqx\xae\xb3X\xf9\x1a\x0e\xbf\x1f\xb2\x0dNi\xb9\x8aL\xac\xc1?8\xa0\xf9H\x83O'r>\xf2'\x88\xf5\xda\x9d\xcb\xd1\xbd=\xbd\xb3\xd7\xcaGzRM\xdc\x07\xd6w\x903\x1e\xaf\xdaN\xe5\xfaRT\xee\x9e\xd9\x84P\x93\xd2Lf\xf7&\x12\xbdj}\xd0\xb3\x8b\xbb\x0c\x9c\xf1x\xea\xb4k\xc1v\xb3d\xce\xcc\xa1\xac\xb9\xe1n%\xb3\n\x9c.\xf4\xf9WZ\xf6G\xe4\x03i\xab\x89Tn\x7fD~6\xcb\x1e\xec\xcf\xa1\xcf\xc5\x9cVn\x9f\x7f\x05\"4a\xbb*Z]\x94}.\x86@~\x12\xa6\x988\xa1U\xa7u#_\xb7\xfe\x04\xea\xf6Z\nh\xd9ii\xf9\x83\x8b\x00\x88P\x84\xba\xdd:t\n\x9a\x91?\x08u;\xadky\x06?\x18yJ\x18\x11\xc7\x0d\x0e\x9a\x8b\xces)\x8e(\"\\KI\x08\xa5\xa5\xbf\xb9\xc1\x02ZU\xc7\x0f\xae$\x84\xbas0\xf2\xc85\xcf\x8f\xf9\xd45\xed6-\xbbp<2\x13\xfe\x9b\x94D\xcc\x08\xe2~\x17>\xda\xf9\x84\x96\xcdNL\x9b\xb89P\xd7\x87[~\xc2O\x18	\x1b1\xb7\xc1\xdf\xfd+|\xc3_\xc2\x0d\x9f\xb2#V\x17\xd6\xed\xf8\x8f\x0b\x1b\xbdA\x83\xa7{\x8cVU\xd5i\x9dK\xd1\xe7\xa2\xc7E\x17\xea\xde\x9c\xca\x1f\xfc-\x10!\x9a\xbe\x9c\xf3\xdf\x81\xbc!\xb4-\xeav\x9c\x8e\x8e&\x8c\xbciH\xb7\xd8\xe5c\xecr\xd3\xad^+\x07Z\xce\xa1\xd3\xdat\xe9f\xd7\xa5\x14nj/{\x03\xbd\xd6\x19,\xd8\x9a\xad\xea\xa1\xddIXKI\x9e\x92'O\x16\xfc\x1b}\xb1\xe0\xdf\x9e-\xf8[X\xd7\xc6^m'\xbc\x92+.\x16p8\x1e\x1f\x1c\xce\x18\x19\x8f\xc7\xe3\x83f\xaaW\xf2uK\xc4@\xe0;\xd6\x87\x05%\xedU\x9b@\xb3\xc6\xff\xd3\xa6&q\xf7\xa6\xeev\xa6\x12y72\x13\xb7\x0bW\\\xdc@\xc2\x07\x94\x96\xaf\xb80\x90\xd0\xaa\x12\x11\xbc\xa2U\xaf\x95B\xc6>\xd5c\xce\xf8\xa0\xf6\x1a\xc9\x8c\x0f\xf8_@Z\x0d\xf1g\xf9\x89[\xa0\x0f\xe8\xa3\xfd\x97\x11\xd5\x99-P)?\xd32\xe2/\xc10A]spPU\x19\x1f\xc8\x88\x7flh\xean\xf8\x10\xb3%{_\xdf\x8a7BC\x19\xef\x85\xba\xf0\xbe>\xfb\"\x97\xffP\x1b\xee\xd5\x86\xf7j_h9|\xa4V\x0dGC>\x9d\xc8\xa5\x1b\xf3\x81\x1c\xeet/\xcaz\xbb\xac*\x87\xf5:l\xd0c\x0e\xe2\xad\xca\xd1\x84\xa8c\xbd\xd0\x89-0S\xb9N\xecu\x1ah\xdc\xfd.pe\x92 ]\xa1\x1f\xab\xa2\xb8V\x0b\x8d\x9e\xe8\xa1I\x8c5*6?5\xc6:\x99\xd9\x08U\x10\x9c\xdf\x97\xd8|Y\xd7adfQlf\x91\xd5\x01\xa2'\x8e\x10u\xec\xa9<Wk\x0c\xd3\x1c=\xd1E\xaf\x87\x9e\x8f\xb9^\xa4\xb7zO\xe0i\x0cR\x7fY\xbb\xc2B\x85*7^\xae\x0bm\x11m\xfa9\xcbt~\xae\x8aZ\xe1\x18md\n\xf4\x06\xe8\x0d\xd1\xebc\x9c\xaa\x00\xbdc\x9ci{\xb1\x0du\xb6~@\xdb\xc5\"\x8b\x8d\xafQ\xc7\x85F\xbb\xce4z\n\x13\xbd\xc2\xd0\xe4:L\xef\xb6jh\x92@\xdf\xbd\x0b\xd1\x9b>\xe6\xfa\xa4f\x1b\xa6\x0e\xa6I\x98\xfa\xcb\x02\x93\xd4\x9b-M\xa0c\x93\xe8\x02=q\x82\xfaV'\xd6k\xbc\xdc\xa4&A/DO\x08<m\x82{\xa2\x8f\xcaZ\xe5G\x17u\x19\xaa8nLY<>9\xc2i\x1a\xac1\x0d\xc3B\xdb/&\xb0\x11\xda\xf4*]m\xf3>\x90	U\x1d`Y\xe8\xfctV\xb3$\xea\xd6\xcc\x94MsL\x93i\xbc\xcc\xeby4\xfcW\xa6\xb0:\xd19.\x94I\xbc\x0d}\xe2\xc7\xc6\x9fchb\xfd \xdd\xd9\xfa2\xc04il\xe7*0)\xfa\x91\xf6\xe7\xd3\xf4\x0e7?v\xcc\x96E\x84V\x17v\x93\x107O\x02n_\x89\xddg\xe4\xf0&\x0e8\xe8Pv\xc4\xca\x8a\xd2\xbf\x03\x00\x00\xff\xffPK\x07\x08\x92\xfbm\xc8Z\x04\x00\x00T\x07\x00\x00PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00AbfQ\xde7\xe4\xf05\x00\x00\x001\x00\x00\x00	\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xb6\x81\x00\x00\x00\x00blank.gifUT\x05\x00\x01\xfb>\xa5_PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00CbfQkI\xa2e\xb9\x01\x00\x00\xaf\x01\x00\x00\n\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xb6\x81u\x00\x00\x00bottom.pngUT\x05\x00\x01\xfe>\xa5_PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00CbfQ#{\xea}\x9a\x03\x00\x00\x0c\x07\x00\x00\x0c\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xb6\x81o\x02\x00\x00iepngfix.htcUT\x05\x00\x01\xfe>\xa5_PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00o\x1agQLF\x14QN\x04\x00\x00\x06\x0e\x00\x00\n\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xb6\x81L\x06\x00\x00index.htmlUT\x05\x00\x01B\x12\xa6_PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\x16\x1cgQ4\xe7\xb3\xaa4\x02\x00\x00\xf6\x04\x00\x00	\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\xdb\n\x00\x00plot.htmlUT\x05\x00\x01]\x15\xa6_PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00CbfQ\xc3\x86\xc1\xa33\x00\x00\x00.\x00\x00\x00\n\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xb6\x81O\x0d\x00\x00shadow.gifUT\x05\x00\x01\xfe>\xa5_PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00CbfQQ\xf1\xa7\xdc\xab\x01\x00\x00\xa1\x01\x00\x00\x07\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xb6\x81\xc3\x0d\x00\x00top.pngUT\x05\x00\x01\xfe>\xa5_PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00CbfQ6\xe9[\xc4\x8d\n\x00\x00\x16*\x00\x00\x08\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xb6\x81\xac\x0f\x00\x00view.cssUT\x05\x00\x01\xfe>\xa5_PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00CbfQ\x92\xfbm\xc8Z\x04\x00\x00T\x07\x00\x00\x07\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xb6\x81x\x1a\x00\x00view.jsUT\x05\x00\x01\xfe>\xa5_PK\x05\x06\x00\x00\x00\x00	\x00	\x00A\x02\x00\x00\x10\x1f\x00\x00\x00\x00"
-	fs.Register(data)
-}
@@ -0,0 +1,156 @@
+package store
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	resultsBucket = []byte("results") // id -> result in json, the sequence is the number of results
+	indexBucket   = []byte("index")   // time of creation + id -> id, to find the oldest results
+)
+
+// Bolt keeps results in the bolt database file, the oldest results are evicted,
+// when the number of results exceeds the limit
+type Bolt struct {
+	MaxEntries int           // maximal number of results, unlimited if zero
+	TTL        time.Duration // time to live of the result, unlimited if zero
+
+	db  *bolt.DB
+	now func() time.Time
+}
+
+// NewBolt opens or creates the database file
+func NewBolt(path string, maxEntries int, ttl time.Duration) (*Bolt, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open bolt db %s", path)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{resultsBucket, indexBucket} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return errors.Wrapf(err, "failed to create bucket %s", b)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &Bolt{MaxEntries: maxEntries, TTL: ttl, db: db}, nil
+}
+
+// Put saves the result under the new id, evicting the oldest result, if the store is full
+func (b *Bolt) Put(r Result) (Result, error) {
+	r, err := prepare(r, b.timeNow(), b.TTL)
+	if err != nil {
+		return Result{}, err
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return Result{}, errors.Wrap(err, "failed to marshal result")
+	}
+
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		results := tx.Bucket(resultsBucket)
+		if err := results.Put([]byte(r.ID), data); err != nil {
+			return errors.Wrapf(err, "failed to put result %s", r.ID)
+		}
+		if err := tx.Bucket(indexBucket).Put(indexKey(r), []byte(r.ID)); err != nil {
+			return errors.Wrapf(err, "failed to index result %s", r.ID)
+		}
+		if _, err := results.NextSequence(); err != nil {
+			return errors.Wrap(err, "failed to count results")
+		}
+		return b.evict(tx)
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	return r, nil
+}
+
+// Get returns the result by its id, if it is present and not expired
+func (b *Bolt) Get(id string) (r Result, err error) {
+	err = b.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(resultsBucket).Get([]byte(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		if err := json.Unmarshal(data, &r); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal result %s", id)
+		}
+		return nil
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	// expired results are removed on the next put
+	if r.expired(b.timeNow()) {
+		return Result{}, ErrNotFound
+	}
+	return r, nil
+}
+
+// Close closes the database file
+func (b *Bolt) Close() error {
+	return errors.Wrap(b.db.Close(), "failed to close bolt db")
+}
+
+// evict removes the expired results and the oldest ones over the limit,
+// all results live for the same time, so the oldest results expire first
+func (b *Bolt) evict(tx *bolt.Tx) error {
+	results, index := tx.Bucket(resultsBucket), tx.Bucket(indexBucket)
+	cnt := int(results.Sequence())
+	now := b.timeNow()
+
+	c := index.Cursor()
+	for k, id := c.First(); k != nil; k, id = c.First() {
+		over := b.MaxEntries > 0 && cnt > b.MaxEntries
+		if !over && !b.expiredKey(k, now) {
+			return nil
+		}
+		if err := results.Delete(id); err != nil {
+			return errors.Wrapf(err, "failed to delete result %s", id)
+		}
+		if err := c.Delete(); err != nil {
+			return errors.Wrapf(err, "failed to delete index of result %s", id)
+		}
+		cnt--
+		if err := results.SetSequence(uint64(cnt)); err != nil {
+			return errors.Wrap(err, "failed to count results")
+		}
+	}
+	return nil
+}
+
+// expiredKey checks whether the result of the index key is expired
+func (b *Bolt) expiredKey(k []byte, now time.Time) bool {
+	if b.TTL <= 0 {
+		return false
+	}
+	created := time.Unix(0, int64(binary.BigEndian.Uint64(k[:8])))
+	return !now.Before(created.Add(b.TTL))
+}
+
+// indexKey orders results by the time of creation
+func indexKey(r Result) []byte {
+	k := make([]byte, 8, 8+len(r.ID))
+	binary.BigEndian.PutUint64(k, uint64(r.CreatedAt.UnixNano()))
+	return append(k, r.ID...)
+}
+
+func (b *Bolt) timeNow() time.Time {
+	if b.now != nil {
+		return b.now()
+	}
+	return time.Now()
+}
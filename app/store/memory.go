@@ -0,0 +1,83 @@
+package store
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Memory keeps results in memory, the oldest results are evicted, when the number of results
+// exceeds the limit, it is safe for concurrent use
+type Memory struct {
+	MaxEntries int           // maximal number of results, unlimited if zero
+	TTL        time.Duration // time to live of the result, unlimited if zero
+
+	mu      sync.Mutex
+	ll      *list.List // results from the oldest to the newest
+	results map[string]*list.Element
+	now     func() time.Time
+}
+
+// Put saves the result under the new id, evicting the oldest result, if the store is full
+func (m *Memory) Put(r Result) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.results == nil {
+		m.results = map[string]*list.Element{}
+		m.ll = list.New()
+	}
+
+	r, err := prepare(r, m.timeNow(), m.TTL)
+	if err != nil {
+		return Result{}, err
+	}
+
+	m.results[r.ID] = m.ll.PushBack(r)
+	m.evict()
+	return r, nil
+}
+
+// Get returns the result by its id, if it is present and not expired
+func (m *Memory) Get(id string) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.results[id]
+	if !ok {
+		return Result{}, ErrNotFound
+	}
+	r := el.Value.(Result)
+	if r.expired(m.timeNow()) {
+		m.remove(el)
+		return Result{}, ErrNotFound
+	}
+	return r, nil
+}
+
+// Close does nothing, results are lost with the process
+func (m *Memory) Close() error { return nil }
+
+// evict removes the expired results and the oldest ones over the limit,
+// all results live for the same time, so the oldest results expire first
+func (m *Memory) evict() {
+	now := m.timeNow()
+	for el := m.ll.Front(); el != nil; el = m.ll.Front() {
+		if !el.Value.(Result).expired(now) && (m.MaxEntries <= 0 || m.ll.Len() <= m.MaxEntries) {
+			return
+		}
+		m.remove(el)
+	}
+}
+
+func (m *Memory) remove(el *list.Element) {
+	m.ll.Remove(el)
+	delete(m.results, el.Value.(Result).ID)
+}
+
+func (m *Memory) timeNow() time.Time {
+	if m.now != nil {
+		return m.now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,274 @@
+package store
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/binary"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Run is the record of the solve request in the history of the session, the request
+// and the summary of its solution are kept in json as is
+type Run struct {
+	ID        string          `json:"id"`
+	Session   string          `json:"session"`
+	Request   json.RawMessage `json:"request"`
+	Summary   json.RawMessage `json:"summary"`
+	Took      time.Duration   `json:"took"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Runs defines methods to keep the history of solves
+type Runs interface {
+	// AddRun records the run under the new id and returns the recorded run
+	AddRun(r Run) (Run, error)
+	// Run returns the run by its id or ErrNotFound
+	Run(id string) (Run, error)
+	// Runs returns up to limit runs of the session from the newest to the oldest, all of them if limit is zero
+	Runs(session string, limit int) ([]Run, error)
+	Close() error
+}
+
+// MemoryRuns keeps runs in memory, the oldest runs are evicted, when the number of runs
+// exceeds the limit, it is safe for concurrent use
+type MemoryRuns struct {
+	MaxEntries int // maximal number of runs of all sessions, unlimited if zero
+
+	mu   sync.Mutex
+	ll   *list.List // runs from the oldest to the newest
+	runs map[string]*list.Element
+	now  func() time.Time
+}
+
+// AddRun records the run under the new id, evicting the oldest run, if the store is full
+func (m *MemoryRuns) AddRun(r Run) (Run, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.runs == nil {
+		m.runs = map[string]*list.Element{}
+		m.ll = list.New()
+	}
+
+	id, err := newID()
+	if err != nil {
+		return Run{}, err
+	}
+	r.ID, r.CreatedAt = id, timeNow(m.now)
+
+	m.runs[r.ID] = m.ll.PushBack(r)
+	for m.MaxEntries > 0 && m.ll.Len() > m.MaxEntries {
+		el := m.ll.Front()
+		m.ll.Remove(el)
+		delete(m.runs, el.Value.(Run).ID)
+	}
+	return r, nil
+}
+
+// Run returns the run by its id
+func (m *MemoryRuns) Run(id string) (Run, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.runs[id]
+	if !ok {
+		return Run{}, ErrNotFound
+	}
+	return el.Value.(Run), nil
+}
+
+// Runs returns up to limit runs of the session from the newest to the oldest
+func (m *MemoryRuns) Runs(session string, limit int) ([]Run, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	res := []Run{}
+	if m.ll == nil {
+		return res, nil
+	}
+	for el := m.ll.Back(); el != nil && (limit <= 0 || len(res) < limit); el = el.Prev() {
+		if r := el.Value.(Run); r.Session == session {
+			res = append(res, r)
+		}
+	}
+	return res, nil
+}
+
+// Close does nothing, runs are lost with the process
+func (m *MemoryRuns) Close() error { return nil }
+
+var (
+	runsBucket        = []byte("runs")         // id -> run in json, the sequence is the number of runs
+	runsIndexBucket   = []byte("runs_index")   // time of creation + id -> id, to find the oldest runs
+	sessionRunsBucket = []byte("session_runs") // session + 0 + time of creation + id -> id
+)
+
+// BoltRuns keeps runs in the bolt database file, the oldest runs are evicted,
+// when the number of runs exceeds the limit
+type BoltRuns struct {
+	MaxEntries int // maximal number of runs of all sessions, unlimited if zero
+
+	db  *bolt.DB
+	now func() time.Time
+}
+
+// NewBoltRuns opens or creates the database file
+func NewBoltRuns(path string, maxEntries int) (*BoltRuns, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open bolt db %s", path)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{runsBucket, runsIndexBucket, sessionRunsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return errors.Wrapf(err, "failed to create bucket %s", b)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &BoltRuns{MaxEntries: maxEntries, db: db}, nil
+}
+
+// AddRun records the run under the new id, evicting the oldest run, if the store is full
+func (b *BoltRuns) AddRun(r Run) (Run, error) {
+	id, err := newID()
+	if err != nil {
+		return Run{}, err
+	}
+	r.ID, r.CreatedAt = id, timeNow(b.now)
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return Run{}, errors.Wrap(err, "failed to marshal run")
+	}
+
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		runs := tx.Bucket(runsBucket)
+		if err := runs.Put([]byte(r.ID), data); err != nil {
+			return errors.Wrapf(err, "failed to put run %s", r.ID)
+		}
+		if err := tx.Bucket(runsIndexBucket).Put(runKey(nil, r), []byte(r.ID)); err != nil {
+			return errors.Wrapf(err, "failed to index run %s", r.ID)
+		}
+		if err := tx.Bucket(sessionRunsBucket).Put(runKey(sessionPrefix(r.Session), r), []byte(r.ID)); err != nil {
+			return errors.Wrapf(err, "failed to index run %s of session", r.ID)
+		}
+		if _, err := runs.NextSequence(); err != nil {
+			return errors.Wrap(err, "failed to count runs")
+		}
+		return b.evict(tx)
+	})
+	if err != nil {
+		return Run{}, err
+	}
+	return r, nil
+}
+
+// Run returns the run by its id
+func (b *BoltRuns) Run(id string) (r Run, err error) {
+	err = b.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(runsBucket).Get([]byte(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		return errors.Wrapf(json.Unmarshal(data, &r), "failed to unmarshal run %s", id)
+	})
+	if err != nil {
+		return Run{}, err
+	}
+	return r, nil
+}
+
+// Runs returns up to limit runs of the session from the newest to the oldest
+func (b *BoltRuns) Runs(session string, limit int) ([]Run, error) {
+	res := []Run{}
+	err := b.db.View(func(tx *bolt.Tx) error {
+		runs := tx.Bucket(runsBucket)
+		prefix := sessionPrefix(session)
+		c := tx.Bucket(sessionRunsBucket).Cursor()
+
+		// seeks past the last key of the session and steps back
+		end := append(append([]byte{}, prefix[:len(prefix)-1]...), 1)
+		k, id := c.Seek(end)
+		if k == nil {
+			k, id = c.Last()
+		} else {
+			k, id = c.Prev()
+		}
+		for ; k != nil && bytes.HasPrefix(k, prefix) && (limit <= 0 || len(res) < limit); k, id = c.Prev() {
+			r := Run{}
+			if err := json.Unmarshal(runs.Get(id), &r); err != nil {
+				return errors.Wrapf(err, "failed to unmarshal run %s", id)
+			}
+			res = append(res, r)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Close closes the database file
+func (b *BoltRuns) Close() error {
+	return errors.Wrap(b.db.Close(), "failed to close bolt db")
+}
+
+// evict removes the oldest runs over the limit along with their keys in the index of sessions
+func (b *BoltRuns) evict(tx *bolt.Tx) error {
+	runs, sessions := tx.Bucket(runsBucket), tx.Bucket(sessionRunsBucket)
+	cnt := int(runs.Sequence())
+	if b.MaxEntries <= 0 || cnt <= b.MaxEntries {
+		return nil
+	}
+
+	c := tx.Bucket(runsIndexBucket).Cursor()
+	for k, id := c.First(); k != nil && cnt > b.MaxEntries; k, id = c.First() {
+		r := Run{}
+		if err := json.Unmarshal(runs.Get(id), &r); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal run %s", id)
+		}
+		if err := sessions.Delete(runKey(sessionPrefix(r.Session), r)); err != nil {
+			return errors.Wrapf(err, "failed to delete index of run %s of session", id)
+		}
+		if err := runs.Delete(id); err != nil {
+			return errors.Wrapf(err, "failed to delete run %s", id)
+		}
+		if err := c.Delete(); err != nil {
+			return errors.Wrapf(err, "failed to delete index of run %s", id)
+		}
+		cnt--
+	}
+	return errors.Wrap(runs.SetSequence(uint64(cnt)), "failed to count runs")
+}
+
+// sessionPrefix is the prefix of keys of runs of the session, the session is terminated by zero,
+// so the session is not the prefix of the other one
+func sessionPrefix(session string) []byte {
+	return append([]byte(session), 0)
+}
+
+// runKey orders runs by the time of creation after the prefix
+func runKey(prefix []byte, r Run) []byte {
+	k := make([]byte, len(prefix)+8, len(prefix)+8+len(r.ID))
+	copy(k, prefix)
+	binary.BigEndian.PutUint64(k[len(prefix):], uint64(r.CreatedAt.UnixNano()))
+	return append(k, r.ID...)
+}
+
+func timeNow(now func() time.Time) time.Time {
+	if now != nil {
+		return now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,59 @@
+// Package store provides storages of solved problems, that are shared by their ids.
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNotFound is returned, when there is no result with the given id or it is expired
+var ErrNotFound = errors.New("result not found")
+
+// Result is the solved problem, the request and its solution are kept in json as is
+type Result struct {
+	ID        string          `json:"id"`
+	Request   json.RawMessage `json:"request"`
+	Response  json.RawMessage `json:"response"`
+	CreatedAt time.Time       `json:"created_at"`
+	ExpiresAt time.Time       `json:"expires_at,omitempty"` // zero if the result never expires
+}
+
+// Interface defines methods to save and load results
+type Interface interface {
+	// Put saves the result under the new id and returns the saved result
+	Put(r Result) (Result, error)
+	// Get returns the result by its id or ErrNotFound
+	Get(id string) (Result, error)
+	Close() error
+}
+
+// newID generates the random id of the result
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "failed to generate id")
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// prepare sets the id and the time of creation and expiration of the result
+func prepare(r Result, now time.Time, ttl time.Duration) (Result, error) {
+	id, err := newID()
+	if err != nil {
+		return Result{}, err
+	}
+	r.ID, r.CreatedAt, r.ExpiresAt = id, now, time.Time{}
+	if ttl > 0 {
+		r.ExpiresAt = now.Add(ttl)
+	}
+	return r, nil
+}
+
+// expired checks whether the result is expired at the given time
+func (r Result) expired(now time.Time) bool {
+	return !r.ExpiresAt.IsZero() && !now.Before(r.ExpiresAt)
+}
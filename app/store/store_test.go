@@ -0,0 +1,228 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+// tempDir creates the temporary directory, removed after the test
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "decompract-store")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	return dir
+}
+
+// clock is the time source, controlled by the test
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func newResult(i int) Result {
+	return Result{Request: json.RawMessage(fmt.Sprintf(`{"n":%d}`, i)), Response: json.RawMessage(`{"lines":[]}`)}
+}
+
+// testStore runs the common checks of the store
+func testStore(t *testing.T, s Interface, clk *clock) {
+	saved, err := s.Put(newResult(1))
+	require.NoError(t, err)
+	assert.Len(t, saved.ID, 16)
+	assert.Equal(t, clk.t, saved.CreatedAt)
+	assert.Equal(t, clk.t.Add(time.Hour), saved.ExpiresAt)
+
+	res, err := s.Get(saved.ID)
+	require.NoError(t, err)
+	assert.Equal(t, saved.ID, res.ID)
+	assert.JSONEq(t, `{"n":1}`, string(res.Request))
+	assert.JSONEq(t, `{"lines":[]}`, string(res.Response))
+	assert.True(t, saved.CreatedAt.Equal(res.CreatedAt))
+
+	_, err = s.Get("unknown")
+	assert.Equal(t, ErrNotFound, err)
+
+	other, err := s.Put(newResult(2))
+	require.NoError(t, err)
+	assert.NotEqual(t, saved.ID, other.ID)
+
+	// the oldest result is evicted over the limit of 3 results
+	clk.t = clk.t.Add(time.Minute)
+	var ids []string
+	for i := 3; i <= 5; i++ {
+		r, err := s.Put(newResult(i))
+		require.NoError(t, err)
+		ids = append(ids, r.ID)
+	}
+	_, err = s.Get(saved.ID)
+	assert.Equal(t, ErrNotFound, err, "the oldest result must be evicted")
+	_, err = s.Get(other.ID)
+	assert.Equal(t, ErrNotFound, err, "the oldest result must be evicted")
+	for _, id := range ids {
+		_, err = s.Get(id)
+		assert.NoError(t, err)
+	}
+
+	// results expire
+	clk.t = clk.t.Add(time.Hour)
+	_, err = s.Get(ids[0])
+	assert.Equal(t, ErrNotFound, err, "expired result must not be returned")
+}
+
+func TestMemory(t *testing.T) {
+	clk := &clock{t: time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)}
+	testStore(t, &Memory{MaxEntries: 3, TTL: time.Hour, now: clk.now}, clk)
+}
+
+func TestMemory_Unlimited(t *testing.T) {
+	m := &Memory{}
+	var ids []string
+	for i := 0; i < 100; i++ {
+		r, err := m.Put(newResult(i))
+		require.NoError(t, err)
+		assert.True(t, r.ExpiresAt.IsZero())
+		ids = append(ids, r.ID)
+	}
+	for _, id := range ids {
+		_, err := m.Get(id)
+		assert.NoError(t, err)
+	}
+}
+
+func TestBolt(t *testing.T) {
+	clk := &clock{t: time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)}
+	b, err := NewBolt(filepath.Join(tempDir(t), "results.db"), 3, time.Hour)
+	require.NoError(t, err)
+	defer b.Close()
+	b.now = clk.now
+	testStore(t, b, clk)
+}
+
+func TestBolt_Reopen(t *testing.T) {
+	path := filepath.Join(tempDir(t), "results.db")
+
+	b, err := NewBolt(path, 10, time.Hour)
+	require.NoError(t, err)
+	saved, err := b.Put(newResult(1))
+	require.NoError(t, err)
+	require.NoError(t, b.Close())
+
+	b, err = NewBolt(path, 10, time.Hour)
+	require.NoError(t, err)
+	defer b.Close()
+	res, err := b.Get(saved.ID)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"n":1}`, string(res.Request))
+	assert.True(t, saved.ExpiresAt.Equal(res.ExpiresAt))
+
+	// expired results are removed from the file on the next put
+	b.now = func() time.Time { return saved.CreatedAt.Add(2 * time.Hour) }
+	_, err = b.Put(newResult(2))
+	require.NoError(t, err)
+	err = b.db.View(func(tx *bolt.Tx) error {
+		assert.Nil(t, tx.Bucket(resultsBucket).Get([]byte(saved.ID)))
+		assert.Equal(t, 1, tx.Bucket(indexBucket).Stats().KeyN)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestNewBolt_Error(t *testing.T) {
+	_, err := NewBolt(filepath.Join(tempDir(t), "no", "such", "dir", "results.db"), 10, time.Hour)
+	assert.Error(t, err)
+}
+
+func newRun(session string, i int) Run {
+	return Run{Session: session, Request: json.RawMessage(fmt.Sprintf(`{"n":%d}`, i)),
+		Summary: json.RawMessage(`{"lines":[]}`), Took: time.Duration(i) * time.Millisecond}
+}
+
+// testRuns runs the common checks of the store of runs, limited by 3 runs
+func testRuns(t *testing.T, s Runs, clk *clock) {
+	runs, err := s.Runs("a", 0)
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+
+	first, err := s.AddRun(newRun("a", 1))
+	require.NoError(t, err)
+	assert.Len(t, first.ID, 16)
+	assert.Equal(t, clk.t, first.CreatedAt)
+
+	clk.t = clk.t.Add(time.Minute)
+	_, err = s.AddRun(newRun("ab", 2))
+	require.NoError(t, err)
+	clk.t = clk.t.Add(time.Minute)
+	third, err := s.AddRun(newRun("a", 3))
+	require.NoError(t, err)
+
+	r, err := s.Run(first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "a", r.Session)
+	assert.JSONEq(t, `{"n":1}`, string(r.Request))
+	assert.JSONEq(t, `{"lines":[]}`, string(r.Summary))
+	assert.Equal(t, time.Millisecond, r.Took)
+
+	_, err = s.Run("unknown")
+	assert.Equal(t, ErrNotFound, err)
+
+	// runs of the session are listed from the newest, the other session with the same prefix is not listed
+	runs, err = s.Runs("a", 0)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, third.ID, runs[0].ID)
+	assert.Equal(t, first.ID, runs[1].ID)
+	runs, err = s.Runs("a", 1)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, third.ID, runs[0].ID)
+	runs, err = s.Runs("ab", 0)
+	require.NoError(t, err)
+	assert.Len(t, runs, 1)
+
+	// the oldest run is evicted over the limit
+	clk.t = clk.t.Add(time.Minute)
+	_, err = s.AddRun(newRun("a", 4))
+	require.NoError(t, err)
+	_, err = s.Run(first.ID)
+	assert.Equal(t, ErrNotFound, err)
+	runs, err = s.Runs("a", 0)
+	require.NoError(t, err)
+	assert.Len(t, runs, 2)
+}
+
+func TestMemoryRuns(t *testing.T) {
+	clk := &clock{t: time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)}
+	testRuns(t, &MemoryRuns{MaxEntries: 3, now: clk.now}, clk)
+}
+
+func TestBoltRuns(t *testing.T) {
+	clk := &clock{t: time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)}
+	path := filepath.Join(tempDir(t), "runs.db")
+	b, err := NewBoltRuns(path, 3)
+	require.NoError(t, err)
+	b.now = clk.now
+	testRuns(t, b, clk)
+	require.NoError(t, b.Close())
+
+	// runs survive the restart
+	b, err = NewBoltRuns(path, 3)
+	require.NoError(t, err)
+	defer b.Close()
+	runs, err := b.Runs("a", 0)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.JSONEq(t, `{"n":4}`, string(runs[0].Request))
+	err = b.db.View(func(tx *bolt.Tx) error {
+		assert.Equal(t, 3, tx.Bucket(runsIndexBucket).Stats().KeyN)
+		assert.Equal(t, 3, tx.Bucket(sessionRunsBucket).Stats().KeyN)
+		return nil
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,294 @@
+// Package web provides html templates and static assets of the ui, embedded into the binary
+package web
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/go-pkgz/lgr"
+	"github.com/pkg/errors"
+)
+
+//go:embed static templates
+var embedded embed.FS
+
+// StaticPrefix is the path, static assets are served under
+const StaticPrefix = "/static/"
+
+// Templates lists the templates, required by the ui
+var Templates = []string{"index.html", "plot.html", "result.html"}
+
+// hashLen is the number of hex digits of the content hash in names of assets
+const hashLen = 8
+
+// Assets keeps parsed templates and static assets with content hashes in their names
+type Assets struct {
+	static    fs.FS
+	templates map[string]*template.Template
+	hashed    map[string]string // name of the asset to its hashed name
+	names     map[string]string // hashed name of the asset to its name
+
+	dev *reloader // reloads assets of the development mode on changes of their files, nil for the rest
+}
+
+// New parses the templates and hashes the static assets, placed in "templates" and "static"
+// directories of the root, files, missing in the root, are taken from the embedded copies,
+// only embedded copies are used, if the root is empty
+func New(root string) (*Assets, error) {
+	var fsys fs.FS = embedded
+	if root != "" {
+		fsys = overlay{top: os.DirFS(root), bottom: embedded}
+	}
+	return parse(fsys, describe(root))
+}
+
+// Dev reads the templates and static assets only from the root on the disk, without embedded copies,
+// they are read again, once any of their files is changed, so the ui is developed without restarts
+func Dev(root string) (*Assets, error) {
+	rl := &reloader{root: root}
+	if _, err := rl.current(); err != nil {
+		return nil, err
+	}
+	return &Assets{dev: rl}, nil
+}
+
+// parse parses the templates and hashes the static assets of the file system, the description
+// of the file system is used in errors
+func parse(fsys fs.FS, where string) (*Assets, error) {
+	static, err := fs.Sub(fsys, "static")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open static assets")
+	}
+	res := &Assets{static: static, templates: map[string]*template.Template{},
+		hashed: map[string]string{}, names: map[string]string{}}
+	if err = res.hash(); err != nil {
+		return nil, errors.Wrap(err, "failed to hash static assets")
+	}
+	for _, name := range Templates {
+		b, err := fs.ReadFile(fsys, path.Join("templates", name))
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, errors.Errorf("template %q is not found in %s", name, where)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read template %q", name)
+		}
+		tmpl, err := template.New(name).Funcs(template.FuncMap{"asset": res.URL}).Parse(string(b))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse template %q", name)
+		}
+		res.templates[name] = tmpl
+	}
+	return res, nil
+}
+
+// Embedded returns the assets, embedded into the binary
+func Embedded() *Assets {
+	res, err := New("")
+	if err != nil {
+		// embedded copies are checked by tests
+		panic(err)
+	}
+	return res
+}
+
+func describe(root string) string {
+	if root == "" {
+		return "embedded assets"
+	}
+	return root + " and embedded assets"
+}
+
+// hash calculates content hashes of static assets
+func (a *Assets) hash() error {
+	return fs.WalkDir(a.static, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := fs.ReadFile(a.static, name)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", name)
+		}
+		sum := sha256.Sum256(b)
+		ext := path.Ext(name)
+		hashed := strings.TrimSuffix(name, ext) + "." + hex.EncodeToString(sum[:])[:hashLen] + ext
+		a.hashed[name], a.names[hashed] = hashed, name
+		return nil
+	})
+}
+
+// URL returns the path of the static asset with the hash of its content in the name
+func (a *Assets) URL(name string) (string, error) {
+	cur, err := a.current()
+	if err != nil {
+		return "", err
+	}
+	hashed, ok := cur.hashed[name]
+	if !ok {
+		return "", errors.Errorf("asset %q is not found", name)
+	}
+	return StaticPrefix + hashed, nil
+}
+
+// Render executes the template with the given data
+func (a *Assets) Render(w io.Writer, name string, data interface{}) error {
+	cur, err := a.current()
+	if err != nil {
+		return err
+	}
+	tmpl, ok := cur.templates[name]
+	if !ok {
+		return errors.Errorf("template %q is not found", name)
+	}
+	// the response is not written partially, if the template fails
+	buf := &bytes.Buffer{}
+	if err = tmpl.Execute(buf, data); err != nil {
+		return errors.Wrapf(err, "failed to execute template %q", name)
+	}
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// Handler serves static assets under StaticPrefix, hashed names are cached forever,
+// plain names are served as well, but revalidated on each request
+func (a *Assets) Handler() http.Handler {
+	if a.dev != nil {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cur, err := a.current()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			cur.Handler().ServeHTTP(w, r)
+		})
+	}
+	files := http.FileServer(http.FS(a.static))
+	return http.StripPrefix(StaticPrefix, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Path
+		if orig, ok := a.names[name]; ok {
+			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+			r.URL.Path = orig
+			files.ServeHTTP(w, r)
+			return
+		}
+		if _, ok := a.hashed[name]; ok {
+			w.Header().Set("Cache-Control", "no-cache")
+			files.ServeHTTP(w, r)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+}
+
+// current returns the assets of the development mode, reloaded, if they are changed, or the assets themselves
+func (a *Assets) current() (*Assets, error) {
+	if a.dev == nil {
+		return a, nil
+	}
+	return a.dev.current()
+}
+
+// reloader keeps assets of the root, until any of their files is changed
+type reloader struct {
+	root string
+
+	lock   sync.Mutex
+	stamp  string // names, sizes and modification times of files of the loaded assets
+	assets *Assets
+}
+
+// current returns the assets, they are loaded again, if their files are changed since the last load,
+// the failed load is retried on the next call
+func (rl *reloader) current() (*Assets, error) {
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+	stamp, err := rl.stampOf()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to check web assets in %s", rl.root)
+	}
+	if rl.assets != nil && stamp == rl.stamp {
+		return rl.assets, nil
+	}
+	res, err := parse(os.DirFS(rl.root), rl.root)
+	if err != nil {
+		return nil, err
+	}
+	if rl.assets != nil {
+		log.Printf("[INFO] web assets in %s are changed, reloaded", rl.root)
+	}
+	rl.assets, rl.stamp = res, stamp
+	return res, nil
+}
+
+// stampOf describes files of the root by their names, sizes and modification times
+func (rl *reloader) stampOf() (string, error) {
+	sb := strings.Builder{}
+	err := fs.WalkDir(os.DirFS(rl.root), ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&sb, "%s:%d:%d\n", name, fi.Size(), fi.ModTime().UnixNano())
+		return nil
+	})
+	return sb.String(), err
+}
+
+// overlay takes files from the top file system, falling back to the bottom one
+type overlay struct {
+	top, bottom fs.FS
+}
+
+// Open opens the file of the top file system, if it exists
+func (o overlay) Open(name string) (fs.File, error) {
+	f, err := o.top.Open(name)
+	if err == nil {
+		return f, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return o.bottom.Open(name)
+}
+
+// ReadDir lists the entries of both file systems, entries of the top one take precedence
+func (o overlay) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries := map[string]fs.DirEntry{}
+	found := false
+	for _, fsys := range []fs.FS{o.bottom, o.top} {
+		list, err := fs.ReadDir(fsys, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+		for _, e := range list {
+			entries[e.Name()] = e
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	res := make([]fs.DirEntry, 0, len(entries))
+	for _, e := range entries {
+		res = append(res, e)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name() < res[j].Name() })
+	return res, nil
+}
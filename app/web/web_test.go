@@ -0,0 +1,163 @@
+package web
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	a, err := New("")
+	require.NoError(t, err)
+	for _, name := range Templates {
+		assert.Contains(t, a.templates, name)
+	}
+
+	u, err := a.URL("view.css")
+	require.NoError(t, err)
+	assert.Regexp(t, `^/static/view\.[0-9a-f]{8}\.css$`, u)
+	_, err = a.URL("unknown.css")
+	assert.EqualError(t, err, `asset "unknown.css" is not found`)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, a.Render(buf, "index.html", nil))
+	assert.Contains(t, buf.String(), `href="`+u+`"`)
+	assert.EqualError(t, a.Render(buf, "unknown.html", nil), `template "unknown.html" is not found`)
+}
+
+func TestNew_Override(t *testing.T) {
+	root := prepRoot(t)
+	writeFile(t, filepath.Join(root, "templates", "index.html"), `<p>dev {{asset "view.css"}} {{asset "dev.js"}}</p>`)
+	writeFile(t, filepath.Join(root, "static", "view.css"), `body {color: red}`)
+	writeFile(t, filepath.Join(root, "static", "dev.js"), `alert(1)`)
+
+	a, err := New(root)
+	require.NoError(t, err)
+	emb := Embedded()
+	assert.NotEqual(t, emb.hashed["view.css"], a.hashed["view.css"], "hash must follow the content")
+	assert.Equal(t, emb.hashed["top.png"], a.hashed["top.png"], "missing files are taken from embedded")
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, a.Render(buf, "index.html", nil))
+	assert.Equal(t, "<p>dev /static/"+a.hashed["view.css"]+" /static/"+a.hashed["dev.js"]+"</p>", buf.String())
+
+	buf.Reset()
+	require.NoError(t, a.Render(buf, "plot.html", nil), "missing templates are taken from embedded")
+	assert.Contains(t, buf.String(), "Enter another data")
+}
+
+func TestNew_Errors(t *testing.T) {
+	root := prepRoot(t)
+	writeFile(t, filepath.Join(root, "templates", "result.html"), `{{if}}`)
+	_, err := New(root)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `failed to parse template "result.html"`)
+
+	defer func(tmpls []string) { Templates = tmpls }(Templates)
+	Templates = append(Templates, "missing.html")
+	_, err = New("")
+	assert.EqualError(t, err, `template "missing.html" is not found in embedded assets`)
+	_, err = New(t.Name())
+	assert.EqualError(t, err, `template "missing.html" is not found in TestNew_Errors and embedded assets`)
+}
+
+func TestDev(t *testing.T) {
+	root := prepRoot(t)
+	_, err := Dev(root)
+	assert.EqualError(t, err, `template "index.html" is not found in `+root, "embedded copies are not used")
+
+	for _, name := range Templates {
+		writeFile(t, filepath.Join(root, "templates", name), `<p>{{asset "view.css"}}</p>`)
+	}
+	writeFile(t, filepath.Join(root, "static", "view.css"), `body {color: red}`)
+	a, err := Dev(root)
+	require.NoError(t, err)
+	red, err := a.URL("view.css")
+	require.NoError(t, err)
+
+	// the template and the asset are changed, the size makes the change visible regardless of the resolution of mtime
+	writeFile(t, filepath.Join(root, "templates", "index.html"), `<p>changed {{asset "view.css"}}</p>`)
+	writeFile(t, filepath.Join(root, "static", "view.css"), `body {color: green}`)
+	green, err := a.URL("view.css")
+	require.NoError(t, err)
+	assert.NotEqual(t, red, green, "hash must follow the changed content")
+	buf := &bytes.Buffer{}
+	require.NoError(t, a.Render(buf, "index.html", nil))
+	assert.Equal(t, "<p>changed "+green+"</p>", buf.String())
+
+	ts := httptest.NewServer(a.Handler())
+	defer ts.Close()
+	resp, err := http.Get(ts.URL + green)
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "body {color: green}", string(body))
+
+	// the broken template fails until it is fixed
+	writeFile(t, filepath.Join(root, "templates", "plot.html"), `{{if}}`)
+	err = a.Render(buf, "index.html", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `failed to parse template "plot.html"`)
+	resp, err = http.Get(ts.URL + green)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	writeFile(t, filepath.Join(root, "templates", "plot.html"), `<p>plot</p>`)
+	buf.Reset()
+	require.NoError(t, a.Render(buf, "plot.html", nil))
+	assert.Equal(t, "<p>plot</p>", buf.String())
+}
+
+func TestAssets_Handler(t *testing.T) {
+	a := Embedded()
+	ts := httptest.NewServer(a.Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/static/" + a.hashed["view.css"])
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "public, max-age=31536000, immutable", resp.Header.Get("Cache-Control"))
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/css")
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	css, err := embedded.ReadFile("static/view.css")
+	require.NoError(t, err)
+	assert.Equal(t, css, body)
+
+	resp, err = http.Get(ts.URL + "/static/view.css")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+
+	for _, path := range []string{"/static/view.0123abcd.css", "/static/", "/static/unknown.css"} {
+		resp, err = http.Get(ts.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode, path)
+	}
+}
+
+func prepRoot(t *testing.T) string {
+	root, err := ioutil.TempDir("", "decompract-web")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(root) })
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "templates"), 0o750))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "static"), 0o750))
+	return root
+}
+
+func writeFile(t *testing.T, name, content string) {
+	require.NoError(t, ioutil.WriteFile(name, []byte(content), 0o600))
+}